@@ -0,0 +1,258 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/kazegusuri/claude-companion/logger"
+	"github.com/kazegusuri/claude-companion/narrator"
+	"github.com/kazegusuri/claude-companion/speech"
+)
+
+// watcher is an event source that can be started and stopped once
+type watcher interface {
+	Start() error
+	Stop()
+}
+
+// watcherGroup runs watchers built by factories, so they can be restarted with fresh instances
+type watcherGroup struct {
+	mu        sync.Mutex
+	factories []func() (watcher, error)
+	running   []watcher
+}
+
+// Add adds a watcher factory; the watcher is created when the group starts
+func (g *watcherGroup) Add(factory func() (watcher, error)) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.factories = append(g.factories, factory)
+}
+
+// Start creates and starts every watcher. If one fails, the started ones are stopped.
+func (g *watcherGroup) Start() error {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return g.start()
+}
+
+// start starts every watcher; g.mu must be held
+func (g *watcherGroup) start() error {
+	for _, factory := range g.factories {
+		w, err := factory()
+		if err == nil {
+			err = w.Start()
+		}
+		if err != nil {
+			g.stop()
+			return err
+		}
+		g.running = append(g.running, w)
+	}
+	return nil
+}
+
+// Stop stops the running watchers
+func (g *watcherGroup) Stop() {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.stop()
+}
+
+// stop stops the running watchers in reverse order; g.mu must be held
+func (g *watcherGroup) stop() {
+	for i := len(g.running) - 1; i >= 0; i-- {
+		g.running[i].Stop()
+	}
+	g.running = nil
+}
+
+// Restart stops the running watchers and starts new ones
+func (g *watcherGroup) Restart() error {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.stop()
+	return g.start()
+}
+
+// narratorConfigFile is the narrator rules file in use. It is reloaded when it
+// changes, and can be switched to another file at runtime.
+type narratorConfigFile struct {
+	mu      sync.Mutex
+	path    string
+	apply   func(config *narrator.NarratorConfig)
+	watcher *narrator.ConfigWatcher
+}
+
+// newNarratorConfigFile creates a narrator config file that passes loaded configs to apply
+func newNarratorConfigFile(path string, apply func(config *narrator.NarratorConfig)) *narratorConfigFile {
+	return &narratorConfigFile{path: path, apply: apply}
+}
+
+// Watch starts reloading the file when it changes
+func (c *narratorConfigFile) Watch() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.watch()
+}
+
+// watch replaces the watcher with one for the current path; c.mu must be held
+func (c *narratorConfigFile) watch() {
+	if c.watcher != nil {
+		c.watcher.Stop()
+		c.watcher = nil
+	}
+	if c.path == "" {
+		return
+	}
+	watcher := narrator.NewConfigWatcher(c.path, c.apply)
+	if err := watcher.Start(); err != nil {
+		logger.LogWarning("Narrator config hot-reload is disabled: %v", err)
+		return
+	}
+	c.watcher = watcher
+}
+
+// Reload loads the file again
+func (c *narratorConfigFile) Reload() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.path == "" {
+		return nil
+	}
+	config, err := narrator.LoadNarratorConfig(c.path)
+	if err != nil {
+		return err
+	}
+	c.apply(config)
+	return nil
+}
+
+// Switch loads another file and watches it instead of the current one
+func (c *narratorConfigFile) Switch(path string) error {
+	config, err := narrator.LoadNarratorConfig(path)
+	if err != nil {
+		return err
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.apply(config)
+	c.path = path
+	c.watch()
+	logger.LogInfo("Switched narrator config: %s", path)
+	return nil
+}
+
+// Stop stops reloading the file
+func (c *narratorConfigFile) Stop() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.watcher != nil {
+		c.watcher.Stop()
+		c.watcher = nil
+	}
+}
+
+// companionAdmin carries out the admin API requests of the HTTP server
+type companionAdmin struct {
+	watchers       *watcherGroup
+	reload         func() error
+	narratorConfig *narratorConfigFile
+	voice          *narrator.VoiceNarrator // nil without voice
+	synthesizer    *speech.VoiceVox        // nil without voice
+	done           chan struct{}
+	once           sync.Once
+}
+
+// newCompanionAdmin creates the admin of a companion with its watchers and configuration reloader
+func newCompanionAdmin(watchers *watcherGroup, reload func() error, narratorConfig *narratorConfigFile) *companionAdmin {
+	return &companionAdmin{watchers: watchers, reload: reload, narratorConfig: narratorConfig, done: make(chan struct{})}
+}
+
+// SetVoice lets the admin control voice narration and the parameters of the synthesizer
+func (a *companionAdmin) SetVoice(voice *narrator.VoiceNarrator, synthesizer *speech.VoiceVox) {
+	a.voice = voice
+	a.synthesizer = synthesizer
+}
+
+// Reload reloads the narrator and project configuration
+func (a *companionAdmin) Reload() error {
+	logger.LogInfo("Reloading configuration")
+	return a.reload()
+}
+
+// RestartWatchers stops and restarts the watchers
+func (a *companionAdmin) RestartWatchers() error {
+	logger.LogInfo("Restarting watchers")
+	if err := a.watchers.Restart(); err != nil {
+		return errors.Join(errors.New("watchers are stopped"), err)
+	}
+	return nil
+}
+
+// Shutdown asks the companion to shut down
+func (a *companionAdmin) Shutdown() {
+	a.once.Do(func() { close(a.done) })
+}
+
+// Done is closed when a shutdown is requested
+func (a *companionAdmin) Done() <-chan struct{} {
+	return a.done
+}
+
+// SetMuted stops or resumes speaking narrations
+func (a *companionAdmin) SetMuted(muted bool) error {
+	if a.voice == nil {
+		return errors.New("voice narration is not enabled")
+	}
+	a.voice.SetMuted(muted)
+	if muted {
+		logger.LogInfo("Voice muted")
+	} else {
+		logger.LogInfo("Voice unmuted")
+	}
+	return nil
+}
+
+// SetSpeaker speaks every session with a speaker; nil goes back to --speaker-map
+func (a *companionAdmin) SetSpeaker(id *int) error {
+	if a.voice == nil {
+		return errors.New("voice narration is not enabled")
+	}
+	a.voice.SetSpeaker(id)
+	if id == nil {
+		logger.LogInfo("Speaker reset to the default")
+	} else {
+		logger.LogInfo("Speaker set to %d", *id)
+	}
+	return nil
+}
+
+// SetNarratorConfig switches to another narrator rules file
+func (a *companionAdmin) SetNarratorConfig(path string) error {
+	if err := a.narratorConfig.Switch(path); err != nil {
+		return fmt.Errorf("failed to switch narrator config: %w", err)
+	}
+	return nil
+}
+
+// SetVoiceParameters changes voice parameters by name. Either all of them change or,
+// when one is invalid, none does.
+func (a *companionAdmin) SetVoiceParameters(params map[string]float64) error {
+	if a.synthesizer == nil {
+		return errors.New("voice narration is not enabled")
+	}
+	previous := a.synthesizer.VoiceParameters()
+	for name, value := range params {
+		if err := a.synthesizer.SetVoiceParameter(name, value); err != nil {
+			for name, value := range previous {
+				a.synthesizer.SetVoiceParameter(name, value)
+			}
+			return err
+		}
+	}
+	current := a.synthesizer.VoiceParameters()
+	logger.LogInfo("Voice set to speed %g, pitch %g, volume %g, intonation %g", current["speed"], current["pitch"], current["volume"], current["intonation"])
+	return nil
+}
@@ -0,0 +1,208 @@
+// Package archive moves inactive sessions out of the projects roots, optionally
+// compressing them, and keeps an index of them so they can still be found
+package archive
+
+import (
+	"compress/gzip"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/kazegusuri/claude-companion/event"
+)
+
+// DefaultDir is the directory sessions are archived in by default
+const DefaultDir = "~/.claude-companion/archive"
+
+// indexFile is the name of the index in the archive directory
+const indexFile = "index.json"
+
+// Entry is an archived session
+type Entry struct {
+	Project      string    `json:"project"`
+	Session      string    `json:"session"`
+	Path         string    `json:"path"`     // Archived transcript
+	Original     string    `json:"original"` // Where the transcript was before it was archived
+	Size         int64     `json:"size"`     // Size of the transcript before compression
+	ArchivedSize int64     `json:"archivedSize"`
+	Compressed   bool      `json:"compressed"`
+	LastActive   time.Time `json:"lastActive"`
+	Archived     time.Time `json:"archived"`
+}
+
+// indexFileFormat is the file format of the index
+type indexFileFormat struct {
+	Sessions []Entry `json:"sessions"`
+}
+
+// Index lists the sessions in an archive directory
+type Index struct {
+	dir     string
+	entries []Entry
+}
+
+// LoadIndex reads the index of an archive directory. A missing index is empty.
+func LoadIndex(dir string) (*Index, error) {
+	index := &Index{dir: dir}
+	data, err := os.ReadFile(filepath.Join(dir, indexFile))
+	if errors.Is(err, os.ErrNotExist) {
+		return index, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read archive index: %w", err)
+	}
+	var file indexFileFormat
+	if err := json.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("failed to parse archive index %s: %w", filepath.Join(dir, indexFile), err)
+	}
+	index.entries = file.Sessions
+	return index, nil
+}
+
+// Save writes the index to the archive directory
+func (i *Index) Save() error {
+	data, err := json.MarshalIndent(indexFileFormat{Sessions: i.Entries()}, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode archive index: %w", err)
+	}
+	if err := os.MkdirAll(i.dir, 0o755); err != nil {
+		return fmt.Errorf("failed to create archive directory: %w", err)
+	}
+	// Write a temporary file and rename it so a crash never leaves a partial index
+	path := filepath.Join(i.dir, indexFile)
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o600); err != nil {
+		return fmt.Errorf("failed to write archive index: %w", err)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		return fmt.Errorf("failed to write archive index: %w", err)
+	}
+	return nil
+}
+
+// Add records an archived session, replacing an earlier entry of the same session
+func (i *Index) Add(entry Entry) {
+	for n := range i.entries {
+		if i.entries[n].Project == entry.Project && i.entries[n].Session == entry.Session {
+			i.entries[n] = entry
+			return
+		}
+	}
+	i.entries = append(i.entries, entry)
+}
+
+// Entries returns the archived sessions, most recently active first
+func (i *Index) Entries() []Entry {
+	entries := append([]Entry{}, i.entries...)
+	sort.Slice(entries, func(a, b int) bool {
+		if !entries[a].LastActive.Equal(entries[b].LastActive) {
+			return entries[a].LastActive.After(entries[b].LastActive)
+		}
+		return entries[a].Path < entries[b].Path
+	})
+	return entries
+}
+
+// Archive moves a transcript to <dir>/<project>/<session>.jsonl, or .jsonl.gz when
+// compressed, and returns its index entry. The archived copy keeps the modification
+// time of the transcript, which is when the session was last active.
+func Archive(path, dir string, compress bool, now time.Time) (*Entry, error) {
+	// The index is read from other working directories
+	path, err := filepath.Abs(path)
+	if err != nil {
+		return nil, err
+	}
+	if dir, err = filepath.Abs(dir); err != nil {
+		return nil, err
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to stat transcript: %w", err)
+	}
+	project := filepath.Base(filepath.Dir(path))
+	session := event.SessionName(path)
+	ext := event.TranscriptExt
+	if compress {
+		ext = event.CompressedTranscriptExt
+	}
+	dest := filepath.Join(dir, project, session+ext)
+	if _, err := os.Stat(dest); err == nil {
+		return nil, fmt.Errorf("%s is already archived", dest)
+	}
+	if err := os.MkdirAll(filepath.Dir(dest), 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create archive directory: %w", err)
+	}
+
+	// Copy to a temporary file first so a failure never leaves a partial archive
+	tmp := dest + ".tmp"
+	if err := copyTranscript(path, tmp, compress); err != nil {
+		os.Remove(tmp)
+		return nil, err
+	}
+	if err := os.Chtimes(tmp, info.ModTime(), info.ModTime()); err != nil {
+		os.Remove(tmp)
+		return nil, fmt.Errorf("failed to keep modification time: %w", err)
+	}
+	archived, err := os.Stat(tmp)
+	if err != nil {
+		os.Remove(tmp)
+		return nil, fmt.Errorf("failed to stat archived transcript: %w", err)
+	}
+	if err := os.Rename(tmp, dest); err != nil {
+		os.Remove(tmp)
+		return nil, fmt.Errorf("failed to write archived transcript: %w", err)
+	}
+	if err := os.Remove(path); err != nil {
+		return nil, fmt.Errorf("failed to remove archived transcript: %w", err)
+	}
+
+	return &Entry{
+		Project:      project,
+		Session:      session,
+		Path:         dest,
+		Original:     path,
+		Size:         info.Size(),
+		ArchivedSize: archived.Size(),
+		Compressed:   compress,
+		LastActive:   info.ModTime(),
+		Archived:     now,
+	}, nil
+}
+
+// copyTranscript copies a transcript to dest, gzipping it if compress is set
+func copyTranscript(path, dest string, compress bool) error {
+	in, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("failed to open file: %w", err)
+	}
+	defer in.Close()
+	out, err := os.OpenFile(dest, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0o600)
+	if err != nil {
+		return fmt.Errorf("failed to create archived transcript: %w", err)
+	}
+
+	var w io.Writer = out
+	var gz *gzip.Writer
+	if compress {
+		gz = gzip.NewWriter(out)
+		w = gz
+	}
+	_, err = io.Copy(w, in)
+	if gz != nil {
+		if cerr := gz.Close(); err == nil {
+			err = cerr
+		}
+	}
+	if cerr := out.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		return fmt.Errorf("failed to write archived transcript: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,113 @@
+package archive
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/kazegusuri/claude-companion/event"
+)
+
+func TestArchive(t *testing.T) {
+	root := t.TempDir()
+	dir := filepath.Join(t.TempDir(), "archive")
+	content := `{"type":"user","uuid":"u1","message":{"role":"user","content":"hello"}}` + "\n"
+	lastActive := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	now := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+
+	for _, tt := range []struct {
+		session  string
+		compress bool
+		wantPath string
+	}{
+		{"s1", false, filepath.Join(dir, "app", "s1.jsonl")},
+		{"s2", true, filepath.Join(dir, "app", "s2.jsonl.gz")},
+	} {
+		path := filepath.Join(root, "app", tt.session+".jsonl")
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.Chtimes(path, lastActive, lastActive); err != nil {
+			t.Fatal(err)
+		}
+
+		entry, err := Archive(path, dir, tt.compress, now)
+		if err != nil {
+			t.Fatalf("Archive(%s) error = %v", tt.session, err)
+		}
+		if entry.Path != tt.wantPath || entry.Project != "app" || entry.Session != tt.session || entry.Compressed != tt.compress {
+			t.Errorf("Archive(%s) = %+v", tt.session, entry)
+		}
+		if entry.Size != int64(len(content)) || !entry.LastActive.Equal(lastActive) || !entry.Archived.Equal(now) {
+			t.Errorf("Archive(%s) size and times = %+v", tt.session, entry)
+		}
+		if _, err := os.Stat(path); !os.IsNotExist(err) {
+			t.Errorf("transcript %s still exists after archiving: %v", path, err)
+		}
+		info, err := os.Stat(entry.Path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !info.ModTime().Equal(lastActive) {
+			t.Errorf("archived modification time = %v, want %v", info.ModTime(), lastActive)
+		}
+
+		r, err := event.OpenTranscript(entry.Path)
+		if err != nil {
+			t.Fatalf("OpenTranscript(%s) error = %v", entry.Path, err)
+		}
+		data, err := io.ReadAll(r)
+		r.Close()
+		if err != nil || string(data) != content {
+			t.Errorf("archived content = %q, %v, want %q", data, err, content)
+		}
+	}
+
+	// A session archived already is not overwritten
+	path := filepath.Join(root, "app", "s1.jsonl")
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Archive(path, dir, false, now); err == nil {
+		t.Error("Archive() of an archived session succeeded")
+	}
+	if _, err := os.Stat(path); err != nil {
+		t.Errorf("transcript removed after a failed archive: %v", err)
+	}
+}
+
+func TestIndex(t *testing.T) {
+	dir := t.TempDir()
+	index, err := LoadIndex(dir)
+	if err != nil {
+		t.Fatalf("LoadIndex() of a missing index error = %v", err)
+	}
+	if len(index.Entries()) != 0 {
+		t.Errorf("Entries() of a missing index = %v", index.Entries())
+	}
+
+	day := func(d int) time.Time { return time.Date(2026, 1, d, 0, 0, 0, 0, time.UTC) }
+	index.Add(Entry{Project: "app", Session: "s1", Path: "/a/app/s1.jsonl", LastActive: day(1)})
+	index.Add(Entry{Project: "app", Session: "s2", Path: "/a/app/s2.jsonl", LastActive: day(3)})
+	index.Add(Entry{Project: "app", Session: "s1", Path: "/a/app/s1.jsonl.gz", LastActive: day(5), Compressed: true})
+	if err := index.Save(); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+
+	reloaded, err := LoadIndex(dir)
+	if err != nil {
+		t.Fatalf("LoadIndex() error = %v", err)
+	}
+	entries := reloaded.Entries()
+	if len(entries) != 2 {
+		t.Fatalf("Entries() = %+v, want 2 sessions", entries)
+	}
+	if entries[0].Path != "/a/app/s1.jsonl.gz" || !entries[0].Compressed || entries[1].Session != "s2" {
+		t.Errorf("Entries() = %+v, want the replaced s1 first", entries)
+	}
+}
@@ -86,11 +86,12 @@ func main() {
 		}
 
 		// Parse audio duration
-		if duration, err := speech.ParseWAVDuration(audioData); err == nil {
-			meta.Duration = duration
-			fmt.Printf("Duration: %v\n", duration)
+		if format, err := speech.ParseWAV(audioData); err == nil {
+			meta.Duration = format.Duration
+			meta.Format = format
+			fmt.Printf("Duration: %v (%s)\n", format.Duration, format)
 		} else {
-			log.Printf("Warning: Failed to parse WAV duration: %v", err)
+			log.Printf("Warning: Failed to parse WAV: %v", err)
 		}
 
 		if err := player.Play(audioData, meta); err != nil {
@@ -0,0 +1,241 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/kazegusuri/claude-companion/event"
+	"github.com/kazegusuri/claude-companion/logger"
+	"github.com/kazegusuri/claude-companion/server"
+	"github.com/kazegusuri/claude-companion/usage"
+	"github.com/spf13/pflag"
+)
+
+// ctlSessions is the ctl command listing the sessions known to the companion
+const ctlSessions = "sessions"
+
+// runCtl controls a running companion: it sends an admin action, or lists sessions
+func runCtl(args []string) int {
+	fs := pflag.NewFlagSet("ctl", pflag.ContinueOnError)
+	var serverAddr string
+	var serverCA string
+	var token string
+	var controlSocket string
+	var timeout time.Duration
+	fs.StringVar(&serverAddr, "server-addr", "127.0.0.1:8765", "Address of the companion HTTP server, as host:port or an http:// or https:// URL")
+	fs.StringVar(&serverCA, "server-ca", "", "PEM CA certificate to verify the HTTPS companion server with, such as its --server-tls-cert; implies https")
+	fs.StringVar(&token, "token", os.Getenv("CLAUDE_COMPANION_TOKEN"), "Admin API token for the companion server (can also use CLAUDE_COMPANION_TOKEN env var)")
+	fs.StringVar(&controlSocket, "control-socket", "", "Control socket of the companion to use instead of the HTTP server")
+	fs.DurationVar(&timeout, "timeout", 30*time.Second, "Timeout for the request")
+	fs.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage: claude-companion ctl [flags] %s [value]\n", actionNames())
+		fmt.Fprintln(os.Stderr, "  speaker takes a speaker ID or default; narrator-config takes the path of a narrator config;")
+		fmt.Fprintln(os.Stderr, "  voice takes NAME=VALUE pairs of speed, pitch, volume and intonation, such as speed=1.2,pitch=0.05")
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args); err != nil {
+		if err == pflag.ErrHelp {
+			return 0
+		}
+		return 2
+	}
+	if fs.NArg() < 1 {
+		fs.Usage()
+		return 2
+	}
+	command := fs.Arg(0)
+	action := server.AdminAction(command)
+	switch {
+	case command == ctlSessions && fs.NArg() == 1:
+	case slices.Contains(server.AdminActions, action) && fs.NArg() == 1 && !action.TakesValue():
+	case slices.Contains(server.AdminActions, action) && fs.NArg() == 2 && action.TakesValue():
+	default:
+		fs.Usage()
+		return 2
+	}
+
+	client, baseURL, err := companionClient(serverAddr, serverCA, controlSocket)
+	if err != nil {
+		logger.LogError("%v", err)
+		return 2
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	if command == ctlSessions {
+		if err := listSessions(ctx, client, baseURL, token); err != nil {
+			logger.LogError("%v", err)
+			return 1
+		}
+		return 0
+	}
+
+	value := fs.Arg(1)
+	if action == server.AdminNarratorConfig {
+		// The companion may run in another directory
+		if abs, err := filepath.Abs(value); err == nil {
+			value = abs
+		}
+	}
+	resp, err := sendAdminAction(ctx, client, baseURL, token, action, value)
+	if err != nil {
+		logger.LogError("%v", err)
+		return 1
+	}
+	fmt.Printf("%s: %s\n", resp.Action, resp.Status)
+	return 0
+}
+
+// actionNames lists the ctl commands for the usage message
+func actionNames() string {
+	names := make([]string, 0, len(server.AdminActions)+1)
+	for _, action := range server.AdminActions {
+		names = append(names, string(action))
+	}
+	names = append(names, ctlSessions)
+	return strings.Join(names, "|")
+}
+
+// companionClient returns a client and base URL for the companion API, over the
+// control socket if one is given and the HTTP server otherwise
+func companionClient(serverAddr, serverCA, controlSocket string) (*http.Client, string, error) {
+	if controlSocket == "" {
+		return serverClient(serverAddr, serverCA)
+	}
+	path, err := usage.ExpandHome(controlSocket)
+	if err != nil {
+		return nil, "", fmt.Errorf("invalid --control-socket: %w", err)
+	}
+	client := &http.Client{Transport: &http.Transport{
+		DialContext: func(ctx context.Context, _, _ string) (net.Conn, error) {
+			var d net.Dialer
+			return d.DialContext(ctx, "unix", path)
+		},
+	}}
+	return client, "http://companion", nil
+}
+
+// serverClient returns a client and base URL for the companion HTTP server. serverAddr
+// is a host:port, or a URL to choose the scheme. A CA certificate implies https and
+// verifies the server with it, so a self-signed --server-tls-cert can be trusted.
+func serverClient(serverAddr, serverCA string) (*http.Client, string, error) {
+	baseURL := strings.TrimSuffix(serverAddr, "/")
+	if !strings.Contains(baseURL, "://") {
+		scheme := "http"
+		if serverCA != "" {
+			scheme = "https"
+		}
+		baseURL = scheme + "://" + baseURL
+	}
+	if serverCA == "" {
+		return http.DefaultClient, baseURL, nil
+	}
+
+	path, err := usage.ExpandHome(serverCA)
+	if err != nil {
+		return nil, "", fmt.Errorf("invalid --server-ca: %w", err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to read --server-ca: %w", err)
+	}
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(data) {
+		return nil, "", fmt.Errorf("invalid --server-ca: no PEM certificates in %s", path)
+	}
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.TLSClientConfig = &tls.Config{RootCAs: pool}
+	return &http.Client{Transport: transport}, baseURL, nil
+}
+
+// listSessions prints the sessions known to the companion, most recently seen first
+func listSessions(ctx context.Context, client *http.Client, baseURL, token string) error {
+	req, err := http.NewRequestWithContext(ctx, "GET", baseURL+"/api/sessions", nil)
+	if err != nil {
+		return err
+	}
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to reach the companion: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("failed to list sessions: %s", resp.Status)
+	}
+	var list struct {
+		Sessions []event.SessionState `json:"sessions"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&list); err != nil {
+		return fmt.Errorf("failed to decode response: %w", err)
+	}
+	for _, s := range list.Sessions {
+		fmt.Printf("%s\t%s\t%s\t%s\n", s.ID, s.LastSeen.Local().Format("2006-01-02 15:04:05"), s.Project, s.CWD)
+	}
+	return nil
+}
+
+// adminResult is the response of the admin API
+type adminResult struct {
+	Action string `json:"action"`
+	Status string `json:"status"`
+	Error  string `json:"error"`
+}
+
+// sendAdminAction posts an admin action, with its value if it takes one, to the companion
+func sendAdminAction(ctx context.Context, client *http.Client, baseURL, token string, action server.AdminAction, value string) (adminResult, error) {
+	var result adminResult
+	var body io.Reader
+	if action.TakesValue() {
+		data, err := json.Marshal(server.AdminRequest{Value: value})
+		if err != nil {
+			return result, err
+		}
+		body = bytes.NewReader(data)
+	}
+	req, err := http.NewRequestWithContext(ctx, "POST", fmt.Sprintf("%s/api/admin/%s", baseURL, action), body)
+	if err != nil {
+		return result, err
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return result, fmt.Errorf("failed to reach the companion: %w", err)
+	}
+	defer resp.Body.Close()
+	if strings.HasPrefix(resp.Header.Get("Content-Type"), "application/json") {
+		if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+			return result, fmt.Errorf("failed to decode response: %w", err)
+		}
+	}
+	switch {
+	case result.Error != "":
+		return result, fmt.Errorf("%s failed: %s", action, result.Error)
+	case resp.StatusCode == http.StatusNotFound:
+		// The HTTP server only serves the admin API with an admin token configured
+		return result, fmt.Errorf("%s failed: %s (the companion needs an admin --server-token, or use --control-socket)", action, resp.Status)
+	case resp.StatusCode >= 300:
+		return result, fmt.Errorf("%s failed: %s", action, resp.Status)
+	}
+	return result, nil
+}
@@ -0,0 +1,95 @@
+package main
+
+import (
+	"context"
+	"encoding/pem"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestServerClient(t *testing.T) {
+	tests := []struct {
+		name       string
+		serverAddr string
+		serverCA   string
+		want       string
+	}{
+		{name: "address", serverAddr: "127.0.0.1:8765", want: "http://127.0.0.1:8765"},
+		{name: "http URL", serverAddr: "http://companion.local:8765/", want: "http://companion.local:8765"},
+		{name: "https URL", serverAddr: "https://companion.local:8765", want: "https://companion.local:8765"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, got, err := serverClient(tt.serverAddr, tt.serverCA)
+			if err != nil {
+				t.Fatalf("serverClient() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("serverClient(%q) base URL = %q, want %q", tt.serverAddr, got, tt.want)
+			}
+		})
+	}
+
+	if _, _, err := serverClient("127.0.0.1:8765", filepath.Join(t.TempDir(), "missing.pem")); err == nil {
+		t.Errorf("serverClient() with a missing CA file succeeded")
+	}
+}
+
+func TestServerClient_TLS(t *testing.T) {
+	var paths []string
+	ts := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer admin-token" {
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+		paths = append(paths, r.URL.Path)
+		switch r.URL.Path {
+		case "/api/hooks":
+			w.WriteHeader(http.StatusAccepted)
+		default:
+			w.Header().Set("Content-Type", "application/json")
+			w.Write([]byte(`{"voice":"on","queued":2}`))
+		}
+	}))
+	defer ts.Close()
+
+	// The test server's self-signed certificate, as --server-tls-cert would be
+	ca := filepath.Join(t.TempDir(), "ca.pem")
+	cert := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: ts.Certificate().Raw})
+	if err := os.WriteFile(ca, cert, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	addr := strings.TrimPrefix(ts.URL, "https://")
+
+	client, baseURL, err := serverClient(addr, ca)
+	if err != nil {
+		t.Fatalf("serverClient() error = %v", err)
+	}
+	if baseURL != ts.URL {
+		t.Errorf("base URL = %q, want %q", baseURL, ts.URL)
+	}
+	ctx := context.Background()
+	if err := sendHook(ctx, client, baseURL, "admin-token", []byte(`{"session_id":"s1"}`)); err != nil {
+		t.Errorf("sendHook() error = %v", err)
+	}
+	status, err := fetchSessionStatus(ctx, client, baseURL, "admin-token", "s1")
+	if err != nil || status.Voice != "on" {
+		t.Errorf("fetchSessionStatus() = %+v, %v; want voice on", status, err)
+	}
+	if got := strings.Join(paths, ","); got != "/api/hooks,/api/sessions/s1/status" {
+		t.Errorf("requested paths = %s", got)
+	}
+
+	// Without the CA the certificate is not trusted
+	client, baseURL, err = serverClient(ts.URL, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := sendHook(ctx, client, baseURL, "admin-token", []byte(`{}`)); err == nil {
+		t.Errorf("sendHook() without --server-ca succeeded, want a certificate error")
+	}
+}
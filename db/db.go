@@ -0,0 +1,109 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	_ "modernc.org/sqlite"
+)
+
+// schema contains the statements to create the database schema
+var schema = []string{
+	`CREATE TABLE IF NOT EXISTS events (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		uuid TEXT NOT NULL DEFAULT '',
+		session_id TEXT NOT NULL DEFAULT '',
+		project TEXT NOT NULL DEFAULT '',
+		session TEXT NOT NULL DEFAULT '',
+		cwd TEXT NOT NULL DEFAULT '',
+		event_type TEXT NOT NULL,
+		subtype TEXT NOT NULL DEFAULT '',
+		model TEXT NOT NULL DEFAULT '',
+		input_tokens INTEGER NOT NULL DEFAULT 0,
+		output_tokens INTEGER NOT NULL DEFAULT 0,
+		cache_read_input_tokens INTEGER NOT NULL DEFAULT 0,
+		cache_creation_input_tokens INTEGER NOT NULL DEFAULT 0,
+		is_sidechain INTEGER NOT NULL DEFAULT 0,
+		timestamp DATETIME NOT NULL,
+		created_at DATETIME NOT NULL
+	)`,
+	`CREATE INDEX IF NOT EXISTS idx_events_session_id ON events(session_id)`,
+	`CREATE INDEX IF NOT EXISTS idx_events_project ON events(project)`,
+	`CREATE INDEX IF NOT EXISTS idx_events_timestamp ON events(timestamp)`,
+	`CREATE TABLE IF NOT EXISTS event_tools (
+		event_id INTEGER NOT NULL REFERENCES events(id) ON DELETE CASCADE,
+		tool_use_id TEXT NOT NULL DEFAULT '',
+		tool_name TEXT NOT NULL
+	)`,
+	`CREATE INDEX IF NOT EXISTS idx_event_tools_event_id ON event_tools(event_id)`,
+	`CREATE INDEX IF NOT EXISTS idx_event_tools_tool_name ON event_tools(tool_name)`,
+	`CREATE TABLE IF NOT EXISTS metric_snapshots (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		project TEXT NOT NULL DEFAULT '',
+		ts INTEGER NOT NULL,
+		interval_seconds INTEGER NOT NULL,
+		events INTEGER NOT NULL DEFAULT 0,
+		tokens INTEGER NOT NULL DEFAULT 0,
+		cost REAL NOT NULL DEFAULT 0
+	)`,
+	`CREATE INDEX IF NOT EXISTS idx_metric_snapshots_ts ON metric_snapshots(ts)`,
+	`CREATE INDEX IF NOT EXISTS idx_metric_snapshots_project ON metric_snapshots(project, ts)`,
+}
+
+// DB is the SQLite database used by the companion
+type DB struct {
+	db   *sql.DB
+	path string
+}
+
+// Open opens (and creates if needed) the SQLite database at path
+func Open(path string) (*DB, error) {
+	// Expand home directory if needed
+	if strings.HasPrefix(path, "~/") {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return nil, err
+		}
+		path = filepath.Join(home, path[2:])
+	}
+
+	if dir := filepath.Dir(path); dir != "" {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			return nil, fmt.Errorf("failed to create database directory: %w", err)
+		}
+	}
+
+	dsn := fmt.Sprintf("file:%s?_pragma=journal_mode(WAL)&_pragma=busy_timeout(5000)&_pragma=foreign_keys(1)", path)
+	sqlDB, err := sql.Open("sqlite", dsn)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open database: %w", err)
+	}
+
+	for _, stmt := range schema {
+		if _, err := sqlDB.Exec(stmt); err != nil {
+			sqlDB.Close()
+			return nil, fmt.Errorf("failed to initialize schema: %w", err)
+		}
+	}
+
+	return &DB{db: sqlDB, path: path}, nil
+}
+
+// Path returns the path of the database file
+func (d *DB) Path() string {
+	return d.path
+}
+
+// Ping checks the database can still be used
+func (d *DB) Ping(ctx context.Context) error {
+	return d.db.PingContext(ctx)
+}
+
+// Close closes the database
+func (d *DB) Close() error {
+	return d.db.Close()
+}
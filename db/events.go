@@ -0,0 +1,272 @@
+package db
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/kazegusuri/claude-companion/event"
+)
+
+// ToolUse represents a tool invocation contained in an event
+type ToolUse struct {
+	ToolUseID string
+	ToolName  string
+}
+
+// EventRecord represents a stored event
+type EventRecord struct {
+	ID                       int64
+	UUID                     string
+	SessionID                string
+	Project                  string
+	Session                  string
+	CWD                      string
+	EventType                string
+	Subtype                  string
+	Model                    string
+	InputTokens              int
+	OutputTokens             int
+	CacheReadInputTokens     int
+	CacheCreationInputTokens int
+	IsSidechain              bool
+	Timestamp                time.Time
+	Tools                    []ToolUse
+}
+
+// EventFilter filters events returned by ListEvents
+type EventFilter struct {
+	SessionID string
+	Project   string
+	EventType string
+	ToolName  string
+	Since     time.Time
+	Until     time.Time
+	Limit     int
+}
+
+// NewEventRecord converts a parsed event into a record
+func NewEventRecord(ev event.Event) *EventRecord {
+	record := &EventRecord{
+		EventType: string(ev.Type()),
+	}
+
+	var base *event.BaseEvent
+	switch e := ev.(type) {
+	case *event.UserMessage:
+		base = &e.BaseEvent
+	case *event.AssistantMessage:
+		base = &e.BaseEvent
+		record.Model = e.Message.Model
+		record.InputTokens = e.Message.Usage.InputTokens
+		record.OutputTokens = e.Message.Usage.OutputTokens
+		record.CacheReadInputTokens = e.Message.Usage.CacheReadInputTokens
+		record.CacheCreationInputTokens = e.Message.Usage.CacheCreationInputTokens
+		for _, content := range e.Message.Content {
+			if content.Type == "tool_use" {
+				record.Tools = append(record.Tools, ToolUse{ToolUseID: content.ID, ToolName: content.Name})
+			}
+		}
+	case *event.SystemMessage:
+		base = &e.BaseEvent
+		record.Subtype = e.Level
+	case *event.HookEvent:
+		base = &e.BaseEvent
+		record.Subtype = e.HookEventType
+	case *event.TaskCompletionMessage:
+		base = &e.BaseEvent
+		record.Subtype = e.TaskInfo.SubagentType
+	case *event.ToolSLABreachMessage:
+		base = &e.BaseEvent
+		record.Subtype = e.ToolName
+	case *event.SessionSummaryMessage:
+		base = &e.BaseEvent
+		record.Subtype = e.Reason
+	case *event.CatchUpMessage:
+		base = &e.BaseEvent
+	case *event.BudgetAlertMessage:
+		base = &e.BaseEvent
+		record.Subtype = e.Scope + "_" + e.Metric
+	case *event.ContextAlertMessage:
+		base = &e.BaseEvent
+		record.Subtype = e.Model
+	case *event.SecurityAlertMessage:
+		base = &e.BaseEvent
+		record.Subtype = e.Rule
+	case *event.BaseEvent:
+		base = e
+	case *event.SummaryEvent:
+		record.UUID = e.LeafUUID
+	case *event.NotificationEvent:
+		record.SessionID = e.SessionID
+		record.CWD = e.CWD
+		record.Subtype = e.HookEventName
+	}
+
+	if base != nil {
+		record.UUID = base.UUID
+		record.SessionID = base.SessionID
+		record.CWD = base.CWD
+		record.IsSidechain = base.IsSidechain
+		record.Timestamp = base.Timestamp
+		if base.Session != nil {
+			record.Project = base.Session.Project
+			record.Session = base.Session.Session
+		}
+	}
+
+	if record.Timestamp.IsZero() {
+		record.Timestamp = time.Now()
+	}
+
+	return record
+}
+
+// RecordEvent stores a parsed event
+func (d *DB) RecordEvent(ev event.Event) error {
+	_, err := d.InsertEvent(context.Background(), NewEventRecord(ev))
+	return err
+}
+
+// InsertEvent stores an event record and returns its ID
+func (d *DB) InsertEvent(ctx context.Context, record *EventRecord) (int64, error) {
+	tx, err := d.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	result, err := tx.ExecContext(ctx, `INSERT INTO events (
+		uuid, session_id, project, session, cwd, event_type, subtype, model,
+		input_tokens, output_tokens, cache_read_input_tokens, cache_creation_input_tokens,
+		is_sidechain, timestamp, created_at
+	) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		record.UUID, record.SessionID, record.Project, record.Session, record.CWD,
+		record.EventType, record.Subtype, record.Model,
+		record.InputTokens, record.OutputTokens, record.CacheReadInputTokens, record.CacheCreationInputTokens,
+		record.IsSidechain, record.Timestamp.UTC(), time.Now().UTC(),
+	)
+	if err != nil {
+		return 0, fmt.Errorf("failed to insert event: %w", err)
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return 0, fmt.Errorf("failed to get event id: %w", err)
+	}
+
+	for _, tool := range record.Tools {
+		if _, err := tx.ExecContext(ctx, `INSERT INTO event_tools (event_id, tool_use_id, tool_name) VALUES (?, ?, ?)`,
+			id, tool.ToolUseID, tool.ToolName); err != nil {
+			return 0, fmt.Errorf("failed to insert event tool: %w", err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("failed to commit event: %w", err)
+	}
+
+	record.ID = id
+	return id, nil
+}
+
+// ListEvents returns events matching the filter ordered by timestamp
+func (d *DB) ListEvents(ctx context.Context, filter EventFilter) ([]*EventRecord, error) {
+	var conditions []string
+	var args []interface{}
+
+	if filter.SessionID != "" {
+		conditions = append(conditions, "e.session_id = ?")
+		args = append(args, filter.SessionID)
+	}
+	if filter.Project != "" {
+		conditions = append(conditions, "e.project = ?")
+		args = append(args, filter.Project)
+	}
+	if filter.EventType != "" {
+		conditions = append(conditions, "e.event_type = ?")
+		args = append(args, filter.EventType)
+	}
+	if filter.ToolName != "" {
+		conditions = append(conditions, "EXISTS (SELECT 1 FROM event_tools t WHERE t.event_id = e.id AND t.tool_name = ?)")
+		args = append(args, filter.ToolName)
+	}
+	if !filter.Since.IsZero() {
+		conditions = append(conditions, "e.timestamp >= ?")
+		args = append(args, filter.Since.UTC())
+	}
+	if !filter.Until.IsZero() {
+		conditions = append(conditions, "e.timestamp < ?")
+		args = append(args, filter.Until.UTC())
+	}
+
+	query := `SELECT e.id, e.uuid, e.session_id, e.project, e.session, e.cwd, e.event_type, e.subtype, e.model,
+		e.input_tokens, e.output_tokens, e.cache_read_input_tokens, e.cache_creation_input_tokens,
+		e.is_sidechain, e.timestamp FROM events e`
+	if len(conditions) > 0 {
+		query += " WHERE " + strings.Join(conditions, " AND ")
+	}
+	query += " ORDER BY e.timestamp, e.id"
+	if filter.Limit > 0 {
+		query += fmt.Sprintf(" LIMIT %d", filter.Limit)
+	}
+
+	rows, err := d.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query events: %w", err)
+	}
+	defer rows.Close()
+
+	var records []*EventRecord
+	byID := make(map[int64]*EventRecord)
+	for rows.Next() {
+		record := &EventRecord{}
+		if err := rows.Scan(&record.ID, &record.UUID, &record.SessionID, &record.Project, &record.Session, &record.CWD,
+			&record.EventType, &record.Subtype, &record.Model,
+			&record.InputTokens, &record.OutputTokens, &record.CacheReadInputTokens, &record.CacheCreationInputTokens,
+			&record.IsSidechain, &record.Timestamp); err != nil {
+			return nil, fmt.Errorf("failed to scan event: %w", err)
+		}
+		records = append(records, record)
+		byID[record.ID] = record
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	if len(records) == 0 {
+		return records, nil
+	}
+
+	if err := d.loadTools(ctx, byID); err != nil {
+		return nil, err
+	}
+	return records, nil
+}
+
+// loadTools loads tool uses for the given events
+func (d *DB) loadTools(ctx context.Context, byID map[int64]*EventRecord) error {
+	ids := make([]string, 0, len(byID))
+	for id := range byID {
+		ids = append(ids, fmt.Sprintf("%d", id))
+	}
+
+	rows, err := d.db.QueryContext(ctx, "SELECT event_id, tool_use_id, tool_name FROM event_tools WHERE event_id IN ("+strings.Join(ids, ",")+") ORDER BY rowid")
+	if err != nil {
+		return fmt.Errorf("failed to query event tools: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var eventID int64
+		var tool ToolUse
+		if err := rows.Scan(&eventID, &tool.ToolUseID, &tool.ToolName); err != nil {
+			return fmt.Errorf("failed to scan event tool: %w", err)
+		}
+		if record, ok := byID[eventID]; ok {
+			record.Tools = append(record.Tools, tool)
+		}
+	}
+	return rows.Err()
+}
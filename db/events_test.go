@@ -0,0 +1,84 @@
+package db
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/kazegusuri/claude-companion/event"
+)
+
+func TestRecordEvent(t *testing.T) {
+	d, err := Open(filepath.Join(t.TempDir(), "companion.db"))
+	if err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+	defer d.Close()
+
+	ts := time.Date(2025, 1, 26, 10, 0, 0, 0, time.UTC)
+	session := &event.Session{Project: "myproject", Session: "abc"}
+
+	events := []event.Event{
+		&event.UserMessage{
+			BaseEvent: event.BaseEvent{TypeString: "user", UUID: "u1", SessionID: "s1", Session: session, Timestamp: ts},
+		},
+		&event.AssistantMessage{
+			BaseEvent: event.BaseEvent{TypeString: "assistant", UUID: "a1", SessionID: "s1", Session: session, Timestamp: ts.Add(time.Second)},
+			Message: event.AssistantMessageContent{
+				Model: "claude-opus-4",
+				Content: []event.AssistantContent{
+					{Type: "tool_use", ID: "toolu_1", Name: "Bash"},
+					{Type: "tool_use", ID: "toolu_2", Name: "Read"},
+				},
+				Usage: event.Usage{InputTokens: 10, OutputTokens: 20, CacheReadInputTokens: 30, CacheCreationInputTokens: 40},
+			},
+		},
+		&event.NotificationEvent{SessionID: "s2", HookEventName: "Notification"},
+	}
+
+	for _, ev := range events {
+		if err := d.RecordEvent(ev); err != nil {
+			t.Fatalf("RecordEvent() error = %v", err)
+		}
+	}
+
+	ctx := context.Background()
+
+	all, err := d.ListEvents(ctx, EventFilter{})
+	if err != nil {
+		t.Fatalf("ListEvents() error = %v", err)
+	}
+	if len(all) != 3 {
+		t.Fatalf("ListEvents() returned %d events, want 3", len(all))
+	}
+
+	bash, err := d.ListEvents(ctx, EventFilter{ToolName: "Bash"})
+	if err != nil {
+		t.Fatalf("ListEvents() error = %v", err)
+	}
+	if len(bash) != 1 {
+		t.Fatalf("ListEvents(Bash) returned %d events, want 1", len(bash))
+	}
+	got := bash[0]
+	if got.UUID != "a1" || got.Project != "myproject" || got.Session != "abc" || got.Model != "claude-opus-4" {
+		t.Errorf("unexpected record: %+v", got)
+	}
+	if got.InputTokens != 10 || got.OutputTokens != 20 || got.CacheReadInputTokens != 30 || got.CacheCreationInputTokens != 40 {
+		t.Errorf("unexpected token usage: %+v", got)
+	}
+	if !got.Timestamp.Equal(ts.Add(time.Second)) {
+		t.Errorf("Timestamp = %v, want %v", got.Timestamp, ts.Add(time.Second))
+	}
+	if len(got.Tools) != 2 || got.Tools[0].ToolName != "Bash" || got.Tools[1].ToolUseID != "toolu_2" {
+		t.Errorf("unexpected tools: %+v", got.Tools)
+	}
+
+	notifications, err := d.ListEvents(ctx, EventFilter{EventType: event.EventTypeNotification})
+	if err != nil {
+		t.Fatalf("ListEvents() error = %v", err)
+	}
+	if len(notifications) != 1 || notifications[0].Subtype != "Notification" || notifications[0].SessionID != "s2" {
+		t.Errorf("unexpected notification records: %+v", notifications)
+	}
+}
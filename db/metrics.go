@@ -0,0 +1,280 @@
+package db
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/kazegusuri/claude-companion/event"
+	"github.com/kazegusuri/claude-companion/logger"
+	"github.com/kazegusuri/claude-companion/usage"
+)
+
+// MetricsRetention is how long metric snapshots are kept
+const MetricsRetention = 90 * 24 * time.Hour
+
+// Metric names of the metrics history
+const (
+	MetricEvents = "events"
+	MetricTokens = "tokens"
+	MetricCost   = "cost"
+)
+
+// MetricSnapshot is the activity of a project during one snapshot interval
+type MetricSnapshot struct {
+	Project  string
+	Time     time.Time // End of the interval
+	Interval time.Duration
+	Events   int64
+	Tokens   int64
+	Cost     float64
+}
+
+// MetricsQuery selects the metrics history returned by MetricsHistory
+type MetricsQuery struct {
+	Metric  string // MetricEvents, MetricTokens or MetricCost
+	Project string // Empty for all projects
+	Since   time.Time
+	Until   time.Time     // Zero for now
+	Step    time.Duration // Bucket size
+}
+
+// MetricPoint is the total of a metric for a project in one bucket
+type MetricPoint struct {
+	Time    time.Time `json:"time"` // Start of the bucket
+	Project string    `json:"project"`
+	Value   float64   `json:"value"`
+}
+
+// InsertMetricSnapshots stores metric snapshots
+func (d *DB) InsertMetricSnapshots(ctx context.Context, snapshots []MetricSnapshot) error {
+	if len(snapshots) == 0 {
+		return nil
+	}
+
+	tx, err := d.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	for _, s := range snapshots {
+		if _, err := tx.ExecContext(ctx,
+			`INSERT INTO metric_snapshots (project, ts, interval_seconds, events, tokens, cost) VALUES (?, ?, ?, ?, ?, ?)`,
+			s.Project, s.Time.Unix(), int64(s.Interval/time.Second), s.Events, s.Tokens, s.Cost); err != nil {
+			return fmt.Errorf("failed to insert metric snapshot: %w", err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit metric snapshots: %w", err)
+	}
+	return nil
+}
+
+// PruneMetricSnapshots deletes snapshots taken before the given time
+func (d *DB) PruneMetricSnapshots(ctx context.Context, before time.Time) error {
+	if _, err := d.db.ExecContext(ctx, `DELETE FROM metric_snapshots WHERE ts < ?`, before.Unix()); err != nil {
+		return fmt.Errorf("failed to prune metric snapshots: %w", err)
+	}
+	return nil
+}
+
+// MetricsHistory returns the totals of a metric per project and bucket, ordered by
+// time and project. Buckets are aligned to the local time zone so daily buckets
+// start at local midnight.
+func (d *DB) MetricsHistory(ctx context.Context, q MetricsQuery) ([]MetricPoint, error) {
+	var column string
+	switch q.Metric {
+	case MetricEvents, MetricTokens, MetricCost:
+		column = q.Metric
+	default:
+		return nil, fmt.Errorf("unknown metric: %q", q.Metric)
+	}
+	step := int64(q.Step / time.Second)
+	if step <= 0 {
+		return nil, fmt.Errorf("invalid step: %v", q.Step)
+	}
+
+	until := q.Until
+	if until.IsZero() {
+		until = time.Now()
+	}
+	_, offset := until.Zone()
+
+	conditions := []string{"ts > ?", "ts <= ?"}
+	args := []interface{}{offset, step, step, offset, q.Since.Unix(), until.Unix()}
+	if q.Project != "" {
+		conditions = append(conditions, "project = ?")
+		args = append(args, q.Project)
+	}
+
+	// Snapshots are stamped with the end of their interval, so (ts - 1) puts a
+	// snapshot ending exactly on a bucket boundary into the bucket it covers
+	query := `SELECT ((ts - 1 + ?) / ?) * ? - ? AS bucket, project, SUM(` + column + `) FROM metric_snapshots
+		WHERE ` + strings.Join(conditions, " AND ") + `
+		GROUP BY bucket, project ORDER BY bucket, project`
+
+	rows, err := d.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query metrics history: %w", err)
+	}
+	defer rows.Close()
+
+	var points []MetricPoint
+	for rows.Next() {
+		var bucket int64
+		var p MetricPoint
+		if err := rows.Scan(&bucket, &p.Project, &p.Value); err != nil {
+			return nil, fmt.Errorf("failed to scan metric point: %w", err)
+		}
+		p.Time = time.Unix(bucket, 0).In(until.Location())
+		points = append(points, p)
+	}
+	return points, rows.Err()
+}
+
+// metricCounter accumulates the activity of a project between snapshots
+type metricCounter struct {
+	events int64
+	tokens usage.Tokens
+	cost   float64
+}
+
+// MetricsRecorder counts events, tokens and estimated cost per project and
+// periodically stores them as snapshots
+type MetricsRecorder struct {
+	db  *DB
+	now func() time.Time
+
+	mu       sync.Mutex
+	counters map[string]*metricCounter
+	seen     map[string]time.Time // Assistant message keys counted recently
+	last     time.Time
+
+	done chan struct{}
+	wg   sync.WaitGroup
+}
+
+// NewMetricsRecorder creates a recorder that stores snapshots in d
+func NewMetricsRecorder(d *DB) *MetricsRecorder {
+	return &MetricsRecorder{
+		db:       d,
+		now:      time.Now,
+		counters: make(map[string]*metricCounter),
+		seen:     make(map[string]time.Time),
+		last:     time.Now(),
+	}
+}
+
+// HandleEvent implements event.EventSink
+func (r *MetricsRecorder) HandleEvent(ev event.Event, formatted string) {
+	project := ""
+	if session := event.SessionOf(ev); session != nil {
+		project = session.Project
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	c, ok := r.counters[project]
+	if !ok {
+		c = &metricCounter{}
+		r.counters[project] = c
+	}
+	c.events++
+
+	msg, ok := ev.(*event.AssistantMessage)
+	if !ok || msg.Message.Usage == (event.Usage{}) {
+		return
+	}
+	// Claude Code writes one line per content block with the same usage
+	if msg.Message.ID != "" {
+		key := msg.Message.ID + ":" + msg.RequestID
+		if _, ok := r.seen[key]; ok {
+			return
+		}
+		r.seen[key] = r.now()
+	}
+	var t usage.Tokens
+	t.Add(msg.Message.Usage)
+	c.tokens.Merge(t)
+	c.cost += usage.EstimateCost(msg.Message.Model, t)
+}
+
+// Snapshot stores the activity since the previous snapshot and resets the counters.
+// Projects without activity are not stored.
+func (r *MetricsRecorder) Snapshot(ctx context.Context) error {
+	r.mu.Lock()
+	now := r.now()
+	interval := now.Sub(r.last)
+	r.last = now
+
+	projects := make([]string, 0, len(r.counters))
+	for project := range r.counters {
+		projects = append(projects, project)
+	}
+	sort.Strings(projects)
+	snapshots := make([]MetricSnapshot, 0, len(projects))
+	for _, project := range projects {
+		c := r.counters[project]
+		snapshots = append(snapshots, MetricSnapshot{
+			Project:  project,
+			Time:     now,
+			Interval: interval,
+			Events:   c.events,
+			Tokens:   c.tokens.Total(),
+			Cost:     c.cost,
+		})
+	}
+	r.counters = make(map[string]*metricCounter)
+
+	// Duplicate lines of a message arrive within moments of each other
+	for key, t := range r.seen {
+		if now.Sub(t) > 10*time.Minute {
+			delete(r.seen, key)
+		}
+	}
+	r.mu.Unlock()
+
+	return r.db.InsertMetricSnapshots(ctx, snapshots)
+}
+
+// Start takes a snapshot every interval until Stop is called
+func (r *MetricsRecorder) Start(interval time.Duration) {
+	r.done = make(chan struct{})
+	r.wg.Add(1)
+	go func() {
+		defer r.wg.Done()
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				ctx := context.Background()
+				if err := r.Snapshot(ctx); err != nil {
+					logger.LogError("Error storing metric snapshot: %v", err)
+				}
+				if err := r.db.PruneMetricSnapshots(ctx, r.now().Add(-MetricsRetention)); err != nil {
+					logger.LogError("Error pruning metric snapshots: %v", err)
+				}
+			case <-r.done:
+				return
+			}
+		}
+	}()
+}
+
+// Stop stops the periodic snapshots and stores the activity since the last one
+func (r *MetricsRecorder) Stop() {
+	if r.done != nil {
+		close(r.done)
+		r.wg.Wait()
+	}
+	if err := r.Snapshot(context.Background()); err != nil {
+		logger.LogError("Error storing metric snapshot: %v", err)
+	}
+}
@@ -0,0 +1,179 @@
+package db
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/kazegusuri/claude-companion/event"
+)
+
+func TestMetricsRecorder(t *testing.T) {
+	d, err := Open(filepath.Join(t.TempDir(), "companion.db"))
+	if err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+	defer d.Close()
+
+	start := time.Date(2025, 1, 26, 10, 0, 0, 0, time.UTC)
+	now := start
+	recorder := NewMetricsRecorder(d)
+	recorder.now = func() time.Time { return now }
+	recorder.last = start
+
+	alpha := &event.Session{Project: "alpha", Session: "s1"}
+	beta := &event.Session{Project: "beta", Session: "s2"}
+	assistant := func(session *event.Session, id string) event.Event {
+		return &event.AssistantMessage{
+			BaseEvent: event.BaseEvent{TypeString: "assistant", Session: session},
+			RequestID: "req_" + id,
+			Message: event.AssistantMessageContent{
+				ID:    id,
+				Model: "claude-sonnet-4",
+				Usage: event.Usage{InputTokens: 1000000, OutputTokens: 100},
+			},
+		}
+	}
+
+	// The second line of msg_1 repeats its usage and must not be counted twice
+	recorder.HandleEvent(assistant(alpha, "msg_1"), "")
+	recorder.HandleEvent(assistant(alpha, "msg_1"), "")
+	recorder.HandleEvent(&event.UserMessage{BaseEvent: event.BaseEvent{TypeString: "user", Session: beta}}, "")
+
+	now = start.Add(time.Minute)
+	if err := recorder.Snapshot(context.Background()); err != nil {
+		t.Fatalf("Snapshot() error = %v", err)
+	}
+
+	recorder.HandleEvent(assistant(alpha, "msg_2"), "")
+	now = start.Add(2 * time.Minute)
+	if err := recorder.Snapshot(context.Background()); err != nil {
+		t.Fatalf("Snapshot() error = %v", err)
+	}
+
+	ctx := context.Background()
+	query := func(metric, project string, step time.Duration) []MetricPoint {
+		t.Helper()
+		points, err := d.MetricsHistory(ctx, MetricsQuery{
+			Metric:  metric,
+			Project: project,
+			Since:   start.Add(-time.Hour),
+			Until:   start.Add(time.Hour),
+			Step:    step,
+		})
+		if err != nil {
+			t.Fatalf("MetricsHistory() error = %v", err)
+		}
+		return points
+	}
+
+	tests := []struct {
+		name    string
+		metric  string
+		project string
+		step    time.Duration
+		want    []MetricPoint
+	}{
+		{
+			name:   "events per minute",
+			metric: MetricEvents,
+			step:   time.Minute,
+			want: []MetricPoint{
+				{Time: start, Project: "alpha", Value: 2},
+				{Time: start, Project: "beta", Value: 1},
+				{Time: start.Add(time.Minute), Project: "alpha", Value: 1},
+			},
+		},
+		{
+			name:    "tokens per hour of a project",
+			metric:  MetricTokens,
+			project: "alpha",
+			step:    time.Hour,
+			want: []MetricPoint{
+				{Time: start, Project: "alpha", Value: 2000200},
+			},
+		},
+		{
+			name:    "cost per hour",
+			metric:  MetricCost,
+			project: "beta",
+			step:    time.Hour,
+			want: []MetricPoint{
+				{Time: start, Project: "beta", Value: 0},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := query(tt.metric, tt.project, tt.step)
+			if diff := cmp.Diff(tt.want, got, cmp.Comparer(func(a, b time.Time) bool { return a.Equal(b) })); diff != "" {
+				t.Errorf("MetricsHistory() mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+
+	cost := query(MetricCost, "alpha", time.Hour)
+	if len(cost) != 1 || cost[0].Value <= 0 {
+		t.Errorf("MetricsHistory(cost) = %+v, want a positive cost for alpha", cost)
+	}
+}
+
+func TestMetricsHistoryErrors(t *testing.T) {
+	d, err := Open(filepath.Join(t.TempDir(), "companion.db"))
+	if err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+	defer d.Close()
+
+	tests := []struct {
+		name  string
+		query MetricsQuery
+	}{
+		{name: "unknown metric", query: MetricsQuery{Metric: "id", Step: time.Minute}},
+		{name: "invalid step", query: MetricsQuery{Metric: MetricEvents}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := d.MetricsHistory(context.Background(), tt.query); err == nil {
+				t.Error("MetricsHistory() error = nil, want error")
+			}
+		})
+	}
+}
+
+func TestPruneMetricSnapshots(t *testing.T) {
+	d, err := Open(filepath.Join(t.TempDir(), "companion.db"))
+	if err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+	defer d.Close()
+
+	ctx := context.Background()
+	now := time.Date(2025, 1, 26, 10, 0, 0, 0, time.UTC)
+	snapshots := []MetricSnapshot{
+		{Project: "old", Time: now.Add(-MetricsRetention - time.Hour), Interval: time.Minute, Events: 1},
+		{Project: "new", Time: now, Interval: time.Minute, Events: 1},
+	}
+	if err := d.InsertMetricSnapshots(ctx, snapshots); err != nil {
+		t.Fatalf("InsertMetricSnapshots() error = %v", err)
+	}
+	if err := d.PruneMetricSnapshots(ctx, now.Add(-MetricsRetention)); err != nil {
+		t.Fatalf("PruneMetricSnapshots() error = %v", err)
+	}
+
+	points, err := d.MetricsHistory(ctx, MetricsQuery{
+		Metric: MetricEvents,
+		Since:  now.Add(-2 * MetricsRetention),
+		Until:  now,
+		Step:   24 * time.Hour,
+	})
+	if err != nil {
+		t.Fatalf("MetricsHistory() error = %v", err)
+	}
+	if len(points) != 1 || points[0].Project != "new" {
+		t.Errorf("MetricsHistory() = %+v, want only the new snapshot", points)
+	}
+}
@@ -0,0 +1,135 @@
+package main
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"os/signal"
+	"path/filepath"
+	"syscall"
+	"time"
+
+	"github.com/kazegusuri/claude-companion/demo"
+	"github.com/kazegusuri/claude-companion/logger"
+	"github.com/kazegusuri/claude-companion/narrator"
+	"github.com/spf13/pflag"
+)
+
+// demoStartupDelay is how long the companion gets to start watching before the session begins
+const demoStartupDelay = 1500 * time.Millisecond
+
+// runDemo plays a scripted session into a temporary projects root and notification log
+// while the companion watches them, so the console, voice and server can be tried
+// without running Claude Code. Arguments after -- are passed to the companion.
+func runDemo(args []string) int {
+	fs := pflag.NewFlagSet("demo", pflag.ContinueOnError)
+	var speed float64
+	var langCode string
+	fs.Float64Var(&speed, "speed", 1, "Playback speed multiplier (2 plays the session twice as fast)")
+	fs.StringVar(&langCode, "lang", "ja", "Language of the session and its narration: ja or en")
+	if err := fs.Parse(args); err != nil {
+		if err == pflag.ErrHelp {
+			return 0
+		}
+		return 2
+	}
+	lang, err := narrator.ParseLanguage(langCode)
+	if err != nil {
+		logger.LogError("%v", err)
+		return 2
+	}
+	if speed <= 0 {
+		logger.LogError("--speed must be positive")
+		return 2
+	}
+
+	return playWithCompanion("demo", lang, speed, fs.Args(), func(projectDir string) *demo.Session {
+		return demo.Script(demo.DemoCWD, projectDir, lang)
+	})
+}
+
+// playWithCompanion starts the companion on a temporary projects root and notification
+// log and plays the session built for the project directory into them. companionArgs
+// are passed to the companion.
+func playWithCompanion(name string, lang narrator.Language, speed float64, companionArgs []string, build func(projectDir string) *demo.Session) int {
+	dir, err := os.MkdirTemp("", "claude-companion-"+name+"-")
+	if err != nil {
+		logger.LogError("Failed to create a session directory: %v", err)
+		return 1
+	}
+	defer os.RemoveAll(dir)
+
+	root := filepath.Join(dir, "projects")
+	projectDir := filepath.Join(root, demo.ProjectName(demo.DemoCWD))
+	if err := os.MkdirAll(projectDir, 0o755); err != nil {
+		logger.LogError("Failed to create a session directory: %v", err)
+		return 1
+	}
+	notifications, err := os.Create(filepath.Join(dir, "notification.log"))
+	if err != nil {
+		logger.LogError("Failed to create the notification log: %v", err)
+		return 1
+	}
+	defer notifications.Close()
+
+	exe, err := os.Executable()
+	if err != nil {
+		logger.LogError("Failed to find the companion executable: %v", err)
+		return 1
+	}
+	cmd := exec.Command(exe, append([]string{
+		"--projects-root", root,
+		"--notification-log", notifications.Name(),
+		"--lang", string(lang),
+	}, companionArgs...)...)
+	cmd.Stdin, cmd.Stdout, cmd.Stderr = os.Stdin, os.Stdout, os.Stderr
+	if err := cmd.Start(); err != nil {
+		logger.LogError("Failed to start the companion: %v", err)
+		return 1
+	}
+
+	// Stop the session on Ctrl+C and make sure the companion stops too
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	sigChan := make(chan os.Signal, 1)
+	signal.Notify(sigChan, os.Interrupt, syscall.SIGTERM)
+	defer signal.Stop(sigChan)
+	go func() {
+		sig := <-sigChan
+		cancel()
+		cmd.Process.Signal(sig)
+	}()
+
+	done := make(chan error, 1)
+	go func() { done <- cmd.Wait() }()
+
+	session := build(projectDir)
+	if err := playDemo(ctx, session, notifications, speed); err != nil && ctx.Err() == nil {
+		logger.LogError("Session failed: %v", err)
+		cmd.Process.Signal(os.Interrupt)
+	} else if ctx.Err() == nil {
+		logger.LogInfo("Session finished; press Ctrl+C to exit")
+	}
+
+	if err := <-done; err != nil && ctx.Err() == nil {
+		logger.LogError("Companion exited: %v", err)
+		return 1
+	}
+	return 0
+}
+
+// playDemo creates the session transcript once the companion is watching and plays the session
+func playDemo(ctx context.Context, session *demo.Session, notifications *os.File, speed float64) error {
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-time.After(demoStartupDelay):
+	}
+
+	transcript, err := os.Create(session.TranscriptPath())
+	if err != nil {
+		return err
+	}
+	defer transcript.Close()
+	return demo.Play(ctx, session.Steps, transcript, notifications, speed)
+}
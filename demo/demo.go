@@ -0,0 +1,265 @@
+package demo
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/kazegusuri/claude-companion/event"
+	"github.com/kazegusuri/claude-companion/narrator"
+)
+
+// DemoCWD is the working directory of the demo session
+const DemoCWD = "/home/user/demo-app"
+
+// ProjectName returns the projects directory name Claude Code uses for cwd
+func ProjectName(cwd string) string {
+	return strings.ReplaceAll(cwd, "/", "-")
+}
+
+// Step is a line written to the transcript or the notification log after a delay
+type Step struct {
+	Delay        time.Duration
+	Transcript   map[string]interface{}   // Transcript line without a timestamp
+	Notification *event.NotificationEvent // Hook notification
+}
+
+// Session is a scripted session
+type Session struct {
+	ID    string
+	CWD   string
+	Steps []Step
+
+	transcriptPath string
+	parent         string
+	messages       int
+	lang           narrator.Language
+}
+
+// NewSession creates an empty session whose transcript is written under dir
+func NewSession(cwd, dir string, lang narrator.Language) *Session {
+	id := uuid.NewString()
+	return &Session{
+		ID:             id,
+		CWD:            cwd,
+		transcriptPath: filepath.Join(dir, id+".jsonl"),
+		// The first line of a new session has no parent, which the handler treats as a
+		// resumed session's replay; start from a parent so every line is shown
+		parent: uuid.NewString(),
+		lang:   lang,
+	}
+}
+
+// TranscriptPath returns the path of the session transcript
+func (s *Session) TranscriptPath() string {
+	return s.transcriptPath
+}
+
+// text picks the Japanese or English text for the session language
+func (s *Session) text(ja, en string) string {
+	if s.lang == narrator.LanguageEnglish {
+		return en
+	}
+	return ja
+}
+
+// line appends a transcript line chained to the previous one
+func (s *Session) line(delay time.Duration, fields map[string]interface{}) {
+	id := uuid.NewString()
+	fields["uuid"] = id
+	fields["parentUuid"] = s.parent
+	fields["sessionId"] = s.ID
+	fields["cwd"] = s.CWD
+	fields["isSidechain"] = false
+	fields["userType"] = "external"
+	fields["version"] = "1.0.0"
+	fields["gitBranch"] = "main"
+	s.parent = id
+	s.Steps = append(s.Steps, Step{Delay: delay, Transcript: fields})
+}
+
+// notify appends a hook notification
+func (s *Session) notify(delay time.Duration, n event.NotificationEvent) {
+	n.SessionID = s.ID
+	n.TranscriptPath = s.transcriptPath
+	n.CWD = s.CWD
+	s.Steps = append(s.Steps, Step{Delay: delay, Notification: &n})
+}
+
+// User appends a user prompt
+func (s *Session) User(delay time.Duration, prompt string) {
+	s.line(delay, map[string]interface{}{
+		"type":    "user",
+		"message": map[string]interface{}{"role": "user", "content": prompt},
+	})
+}
+
+// assistant appends an assistant message with a single content block
+func (s *Session) assistant(delay time.Duration, content map[string]interface{}, outputTokens int) {
+	s.messages++
+	s.line(delay, map[string]interface{}{
+		"type":      "assistant",
+		"requestId": fmt.Sprintf("req_demo_%d", s.messages),
+		"message": map[string]interface{}{
+			"id":          fmt.Sprintf("msg_demo_%d", s.messages),
+			"type":        "message",
+			"role":        "assistant",
+			"model":       "claude-sonnet-4-20250514",
+			"content":     []interface{}{content},
+			"stop_reason": nil,
+			"usage": map[string]interface{}{
+				"input_tokens":                4,
+				"cache_creation_input_tokens": 1200,
+				"cache_read_input_tokens":     15000 + 800*s.messages,
+				"output_tokens":               outputTokens,
+			},
+		},
+	})
+}
+
+// Thinking appends an assistant thinking block
+func (s *Session) Thinking(delay time.Duration, thinking string) {
+	s.assistant(delay, map[string]interface{}{"type": "thinking", "thinking": thinking}, 120)
+}
+
+// Text appends an assistant text block
+func (s *Session) Text(delay time.Duration, text string) {
+	s.assistant(delay, map[string]interface{}{"type": "text", "text": text}, 80+len(text)/4)
+}
+
+// ToolUse appends a tool use followed by its PreToolUse hook and returns the tool use ID
+func (s *Session) ToolUse(delay time.Duration, name string, input map[string]interface{}) string {
+	id := "toolu_demo_" + strings.ReplaceAll(uuid.NewString(), "-", "")[:20]
+	s.assistant(delay, map[string]interface{}{"type": "tool_use", "id": id, "name": name, "input": input}, 60)
+	s.notify(0, event.NotificationEvent{HookEventName: "PreToolUse", ToolName: name, ToolInput: input, ToolUseID: id})
+	return id
+}
+
+// ToolResult appends the result of a tool use
+func (s *Session) ToolResult(delay time.Duration, toolUseID, content string, isError bool) {
+	s.line(delay, map[string]interface{}{
+		"type": "user",
+		"message": map[string]interface{}{
+			"role": "user",
+			"content": []interface{}{map[string]interface{}{
+				"type":        "tool_result",
+				"tool_use_id": toolUseID,
+				"content":     content,
+				"is_error":    isError,
+			}},
+		},
+	})
+}
+
+// Notify appends a Notification hook, such as a permission prompt
+func (s *Session) Notify(delay time.Duration, message string) {
+	s.notify(delay, event.NotificationEvent{HookEventName: "Notification", Message: message})
+}
+
+// todos builds the TodoWrite input from contents and their statuses
+func todos(items ...string) map[string]interface{} {
+	var list []interface{}
+	for i := 0; i+1 < len(items); i += 2 {
+		list = append(list, map[string]interface{}{
+			"id":       fmt.Sprintf("%d", i/2+1),
+			"content":  items[i],
+			"status":   items[i+1],
+			"priority": "high",
+		})
+	}
+	return map[string]interface{}{"todos": list}
+}
+
+// Script builds a session that fixes a failing test: it thinks, plans with a todo
+// list, runs commands, reads and edits a file behind a permission prompt, and reports.
+func Script(cwd, dir string, lang narrator.Language) *Session {
+	s := NewSession(cwd, dir, lang)
+	testTodo := s.text("失敗しているテストを確認する", "Check the failing test")
+	fixTodo := s.text("ログイン処理のバグを修正する", "Fix the bug in the login handler")
+	verifyTodo := s.text("テストが通ることを確認する", "Verify that the tests pass")
+
+	s.notify(0, event.NotificationEvent{HookEventName: "SessionStart", Source: "startup"})
+	s.User(2*time.Second, s.text("ログインのテストが失敗しているので直してください", "The login test is failing. Please fix it."))
+	s.Thinking(3*time.Second, s.text(
+		"ユーザーはログインのテストの修正を求めている。\nまずテストを実行して、どのアサーションが失敗しているかを確認しよう。",
+		"The user wants the login test fixed.\nLet me run the tests first to see which assertion fails."))
+	id := s.ToolUse(2*time.Second, "TodoWrite", todos(testTodo, "in_progress", fixTodo, "pending", verifyTodo, "pending"))
+	s.ToolResult(time.Second, id, "Todos have been modified successfully", false)
+
+	id = s.ToolUse(2*time.Second, "Bash", map[string]interface{}{"command": "go test ./auth/...", "description": "Run the auth tests"})
+	s.ToolResult(3*time.Second, id, "--- FAIL: TestLogin (0.00s)\n    login_test.go:42: expired session was accepted\nFAIL\tdemo-app/auth\t0.012s", true)
+
+	id = s.ToolUse(2*time.Second, "Read", map[string]interface{}{"file_path": cwd + "/auth/login.go"})
+	s.ToolResult(time.Second, id, "func validSession(s *Session) bool {\n\treturn s.ExpiresAt.After(s.CreatedAt)\n}", false)
+	s.Text(3*time.Second, s.text(
+		"原因が分かりました。セッションの有効期限を現在時刻ではなく作成時刻と比較しています。\n```go\nreturn s.ExpiresAt.After(time.Now())\n```\nに修正します。",
+		"Found it: the session expiry is compared with its creation time instead of the current time.\n```go\nreturn s.ExpiresAt.After(time.Now())\n```\nI'll fix that."))
+	id = s.ToolUse(2*time.Second, "TodoWrite", todos(testTodo, "completed", fixTodo, "in_progress", verifyTodo, "pending"))
+	s.ToolResult(time.Second, id, "Todos have been modified successfully", false)
+
+	id = s.ToolUse(2*time.Second, "Edit", map[string]interface{}{
+		"file_path":  cwd + "/auth/login.go",
+		"old_string": "return s.ExpiresAt.After(s.CreatedAt)",
+		"new_string": "return s.ExpiresAt.After(time.Now())",
+	})
+	s.Notify(time.Second, "Claude needs your permission to use Edit")
+	s.ToolResult(5*time.Second, id, "The file "+cwd+"/auth/login.go has been updated.", false)
+
+	id = s.ToolUse(2*time.Second, "TodoWrite", todos(testTodo, "completed", fixTodo, "completed", verifyTodo, "in_progress"))
+	s.ToolResult(time.Second, id, "Todos have been modified successfully", false)
+	id = s.ToolUse(2*time.Second, "Bash", map[string]interface{}{"command": "go test ./...", "description": "Run all tests"})
+	s.ToolResult(4*time.Second, id, "ok  \tdemo-app/auth\t0.015s\nok  \tdemo-app/server\t0.041s", false)
+	id = s.ToolUse(2*time.Second, "TodoWrite", todos(testTodo, "completed", fixTodo, "completed", verifyTodo, "completed"))
+	s.ToolResult(time.Second, id, "Todos have been modified successfully", false)
+
+	s.Text(3*time.Second, s.text(
+		"修正が完了しました。期限切れのセッションが拒否されるようになり、すべてのテストが通っています。",
+		"Done. Expired sessions are now rejected and all tests pass."))
+	s.notify(time.Second, event.NotificationEvent{HookEventName: "Stop"})
+	return s
+}
+
+// Play writes the steps with their delays divided by speed. Transcript lines are
+// stamped with the time they are written.
+func Play(ctx context.Context, steps []Step, transcript, notifications io.Writer, speed float64) error {
+	if speed <= 0 {
+		speed = 1
+	}
+	for _, step := range steps {
+		if step.Delay > 0 {
+			timer := time.NewTimer(time.Duration(float64(step.Delay) / speed))
+			select {
+			case <-ctx.Done():
+				timer.Stop()
+				return ctx.Err()
+			case <-timer.C:
+			}
+		}
+
+		var w io.Writer
+		var value interface{}
+		switch {
+		case step.Transcript != nil:
+			step.Transcript["timestamp"] = time.Now().UTC().Format(time.RFC3339Nano)
+			w, value = transcript, step.Transcript
+		case step.Notification != nil:
+			step.Notification.LoggedAt = time.Now().Format(time.RFC3339Nano)
+			w, value = notifications, step.Notification
+		default:
+			continue
+		}
+		data, err := json.Marshal(value)
+		if err != nil {
+			return fmt.Errorf("failed to encode step: %w", err)
+		}
+		if _, err := w.Write(append(data, '\n')); err != nil {
+			return fmt.Errorf("failed to write step: %w", err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,97 @@
+package demo
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/kazegusuri/claude-companion/event"
+	"github.com/kazegusuri/claude-companion/narrator"
+)
+
+func TestProjectName(t *testing.T) {
+	if got := ProjectName("/home/user/demo-app"); got != "-home-user-demo-app" {
+		t.Errorf("ProjectName() = %q", got)
+	}
+}
+
+func TestScript(t *testing.T) {
+	session := Script(DemoCWD, "/projects/"+ProjectName(DemoCWD), narrator.LanguageEnglish)
+
+	var transcript, notifications bytes.Buffer
+	if err := Play(context.Background(), session.Steps, &transcript, &notifications, 1e6); err != nil {
+		t.Fatalf("Play() error = %v", err)
+	}
+
+	parser := event.NewParserWithPath(session.TranscriptPath())
+	toolUses := make(map[string]bool)
+	results := 0
+	scanner := bufio.NewScanner(&transcript)
+	for scanner.Scan() {
+		ev, err := parser.Parse(scanner.Text())
+		if err != nil {
+			t.Fatalf("Parse() error = %v", err)
+		}
+		base := event.BaseOf(ev)
+		// Lines without a parent are buffered as a resumed session's replay
+		if base.ParentUUID == nil || base.Timestamp.IsZero() {
+			t.Errorf("line %s has no parent or timestamp", base.UUID)
+		}
+		switch e := ev.(type) {
+		case *event.AssistantMessage:
+			for _, content := range e.Message.Content {
+				if content.Type == "tool_use" {
+					toolUses[content.ID] = true
+				}
+			}
+		case *event.UserMessage:
+			if items, ok := e.Message.Content.([]interface{}); ok {
+				id := items[0].(map[string]interface{})["tool_use_id"].(string)
+				if !toolUses[id] {
+					t.Errorf("tool result %s has no tool use", id)
+				}
+				results++
+			}
+		}
+	}
+	if results != len(toolUses) || results == 0 {
+		t.Errorf("%d tool results for %d tool uses", results, len(toolUses))
+	}
+
+	permission := false
+	scanner = bufio.NewScanner(&notifications)
+	for scanner.Scan() {
+		var n event.NotificationEvent
+		if err := json.Unmarshal(scanner.Bytes(), &n); err != nil {
+			t.Fatalf("notification is not JSON: %v", err)
+		}
+		if n.SessionID != session.ID || n.TranscriptPath != session.TranscriptPath() {
+			t.Errorf("notification %s is not for the session", n.HookEventName)
+		}
+		permission = permission || event.IsPermissionRequest(&n)
+	}
+	if !permission {
+		t.Errorf("the session has no permission prompt")
+	}
+}
+
+func TestPlayCanceled(t *testing.T) {
+	session := NewSession(DemoCWD, "/projects", narrator.LanguageJapanese)
+	session.User(0, "first")
+	session.User(time.Hour, "second")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	var transcript bytes.Buffer
+	err := Play(ctx, session.Steps, &transcript, &bytes.Buffer{}, 1)
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Play() error = %v, want the context error", err)
+	}
+	if lines := bytes.Count(transcript.Bytes(), []byte("\n")); lines != 1 {
+		t.Errorf("Play() wrote %d lines before the cancel, want 1", lines)
+	}
+}
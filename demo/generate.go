@@ -0,0 +1,123 @@
+package demo
+
+import (
+	"fmt"
+	"math/rand"
+	"time"
+
+	"github.com/kazegusuri/claude-companion/event"
+	"github.com/kazegusuri/claude-companion/narrator"
+)
+
+// GenerateOptions controls a generated session
+type GenerateOptions struct {
+	Seed    int64 // Same seed, same session
+	Prompts int   // User prompts in the session
+}
+
+// generatedTask is a request the generated user makes, with the files it touches
+type generatedTask struct {
+	ja, en string
+	files  []string
+}
+
+// generatedTasks are the requests a generated session picks from
+var generatedTasks = []generatedTask{
+	{"ログインのテストが失敗しているので直してください", "The login test is failing. Please fix it.", []string{"auth/login.go", "auth/login_test.go"}},
+	{"APIのレスポンスにページングを追加してください", "Add pagination to the API responses.", []string{"server/handler.go", "server/pagination.go"}},
+	{"設定ファイルの読み込みをリファクタリングしてください", "Refactor how the config file is loaded.", []string{"config/config.go", "main.go"}},
+	{"READMEにインストール手順を書いてください", "Write installation steps in the README.", []string{"README.md"}},
+	{"メモリリークの原因を調べてください", "Find out what leaks memory.", []string{"cache/cache.go", "server/session.go"}},
+}
+
+// generatedCommands are the commands a generated session runs, with their output
+var generatedCommands = []struct {
+	command, description, output string
+	failure                      string // Output when the command fails
+}{
+	{"go test ./...", "Run all tests", "ok  \tdemo-app/auth\t0.015s\nok  \tdemo-app/server\t0.041s", "--- FAIL: TestLogin (0.00s)\nFAIL\tdemo-app/auth\t0.012s"},
+	{"go build ./...", "Build the project", "", "server/handler.go:42:2: undefined: paginate"},
+	{"go vet ./...", "Vet the code", "", "config/config.go:17:2: unreachable code"},
+	{"git status --short", "Show changed files", " M auth/login.go", ""},
+	{"npm run lint", "Lint the frontend", "✨ Done in 2.31s.", "error  'user' is assigned a value but never used"},
+}
+
+// Generate builds a random but plausible session from a seed: prompts, thinking,
+// todo lists, searches, reads, edits behind permission prompts, commands that
+// sometimes fail, a compaction and replies, with the hooks Claude Code would send
+func Generate(cwd, dir string, lang narrator.Language, opts GenerateOptions) *Session {
+	r := rand.New(rand.NewSource(opts.Seed))
+	s := NewSession(cwd, dir, lang)
+	prompts := max(opts.Prompts, 1)
+
+	s.notify(0, event.NotificationEvent{HookEventName: "SessionStart", Source: "startup"})
+	for i := 0; i < prompts; i++ {
+		task := generatedTasks[r.Intn(len(generatedTasks))]
+		s.User(2*time.Second, s.text(task.ja, task.en))
+		if r.Intn(2) == 0 {
+			s.Thinking(jitter(r, 3*time.Second), s.text(
+				"まず関連するコードを確認して、変更が必要な箇所を特定しよう。",
+				"Let me look at the related code first and find what needs to change."))
+		}
+		s.generateWork(r, cwd, task)
+		s.Text(jitter(r, 3*time.Second), s.text("対応が完了しました。", "Done."))
+		s.notify(time.Second, event.NotificationEvent{HookEventName: "Stop"})
+
+		// Long sessions get compacted
+		if i == prompts/2 && prompts > 2 {
+			s.notify(2*time.Second, event.NotificationEvent{HookEventName: "PreCompact", Trigger: "auto"})
+			s.notify(5*time.Second, event.NotificationEvent{HookEventName: "SessionStart", Source: "compact"})
+		}
+	}
+	return s
+}
+
+// generateWork appends the tool uses of one task
+func (s *Session) generateWork(r *rand.Rand, cwd string, task generatedTask) {
+	todoA := s.text("関連するコードを確認する", "Review the related code")
+	todoB := s.text("変更を実装する", "Implement the change")
+	id := s.ToolUse(jitter(r, 2*time.Second), "TodoWrite", todos(todoA, "in_progress", todoB, "pending"))
+	s.ToolResult(time.Second, id, "Todos have been modified successfully", false)
+
+	if r.Intn(2) == 0 {
+		id = s.ToolUse(jitter(r, 2*time.Second), "Grep", map[string]interface{}{"pattern": "func ", "path": cwd})
+		s.ToolResult(time.Second, id, fmt.Sprintf("Found %d files", 2+r.Intn(8)), false)
+	}
+	for _, file := range task.files {
+		id = s.ToolUse(jitter(r, 2*time.Second), "Read", map[string]interface{}{"file_path": cwd + "/" + file})
+		s.ToolResult(time.Second, id, "package main\n", false)
+	}
+
+	id = s.ToolUse(jitter(r, 2*time.Second), "TodoWrite", todos(todoA, "completed", todoB, "in_progress"))
+	s.ToolResult(time.Second, id, "Todos have been modified successfully", false)
+	for _, file := range task.files {
+		path := cwd + "/" + file
+		id = s.ToolUse(jitter(r, 2*time.Second), "Edit", map[string]interface{}{
+			"file_path":  path,
+			"old_string": "return nil",
+			"new_string": "return err",
+		})
+		if r.Intn(3) == 0 {
+			s.Notify(time.Second, "Claude needs your permission to use Edit")
+		}
+		s.ToolResult(jitter(r, 2*time.Second), id, "The file "+path+" has been updated.", false)
+	}
+
+	for n := 1 + r.Intn(2); n > 0; n-- {
+		c := generatedCommands[r.Intn(len(generatedCommands))]
+		id = s.ToolUse(jitter(r, 2*time.Second), "Bash", map[string]interface{}{"command": c.command, "description": c.description})
+		if c.failure != "" && r.Intn(4) == 0 {
+			s.ToolResult(jitter(r, 3*time.Second), id, c.failure, true)
+		} else {
+			s.ToolResult(jitter(r, 3*time.Second), id, c.output, false)
+		}
+	}
+
+	id = s.ToolUse(jitter(r, 2*time.Second), "TodoWrite", todos(todoA, "completed", todoB, "completed"))
+	s.ToolResult(time.Second, id, "Todos have been modified successfully", false)
+}
+
+// jitter returns a delay between half and one and a half times d
+func jitter(r *rand.Rand, d time.Duration) time.Duration {
+	return d/2 + time.Duration(r.Int63n(int64(d)))
+}
@@ -0,0 +1,69 @@
+package demo
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/kazegusuri/claude-companion/event"
+	"github.com/kazegusuri/claude-companion/narrator"
+)
+
+// stepNames summarizes the steps of a session by transcript line type, tool or hook
+func stepNames(s *Session) []string {
+	var names []string
+	for _, step := range s.Steps {
+		switch {
+		case step.Notification != nil:
+			names = append(names, step.Notification.HookEventName)
+		case step.Transcript["type"] == "assistant":
+			content := step.Transcript["message"].(map[string]interface{})["content"].([]interface{})[0].(map[string]interface{})
+			if name, ok := content["name"].(string); ok {
+				names = append(names, name)
+			} else {
+				names = append(names, content["type"].(string))
+			}
+		default:
+			names = append(names, step.Transcript["type"].(string))
+		}
+	}
+	return names
+}
+
+func TestGenerate(t *testing.T) {
+	opts := GenerateOptions{Seed: 42, Prompts: 4}
+	session := Generate(DemoCWD, "/projects", narrator.LanguageJapanese, opts)
+	names := stepNames(session)
+
+	// The same seed generates the same session
+	again := stepNames(Generate(DemoCWD, "/projects", narrator.LanguageJapanese, opts))
+	if len(again) != len(names) {
+		t.Fatalf("sessions of the same seed differ: %d and %d steps", len(names), len(again))
+	}
+	for i := range names {
+		if names[i] != again[i] {
+			t.Fatalf("sessions of the same seed differ at step %d: %s and %s", i, names[i], again[i])
+		}
+	}
+
+	counts := make(map[string]int)
+	for _, name := range names {
+		counts[name]++
+	}
+	if counts["Stop"] != opts.Prompts || counts["PreCompact"] != 1 || counts["PreToolUse"] == 0 {
+		t.Errorf("unexpected steps: %v", counts)
+	}
+
+	var transcript bytes.Buffer
+	if err := Play(context.Background(), session.Steps, &transcript, &bytes.Buffer{}, 1e9); err != nil {
+		t.Fatalf("Play() error = %v", err)
+	}
+	parser := event.NewParserWithPath(session.TranscriptPath())
+	scanner := bufio.NewScanner(&transcript)
+	for scanner.Scan() {
+		if _, err := parser.Parse(scanner.Text()); err != nil {
+			t.Fatalf("Parse() error = %v", err)
+		}
+	}
+}
@@ -0,0 +1,117 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/kazegusuri/claude-companion/digest"
+	"github.com/kazegusuri/claude-companion/logger"
+	"github.com/spf13/pflag"
+)
+
+// runDigest aggregates the sessions of all projects in the last hours into a Markdown
+// report of projects, tokens and cost, top tools and notable errors. It can be run
+// from cron to get a daily digest in a file or in Slack.
+func runDigest(args []string) int {
+	fs := pflag.NewFlagSet("digest", pflag.ContinueOnError)
+	var projectsRoots []string
+	var hours int
+	var output string
+	var slackWebhook string
+	var jsonOutput bool
+	fs.StringSliceVar(&projectsRoots, "projects-root", []string{"~/.claude/projects"}, "Root directory for projects (repeatable or comma-separated; LABEL=PATH is accepted)")
+	fs.IntVar(&hours, "hours", 24, "Include sessions active in the last N hours")
+	fs.StringVarP(&output, "output", "o", "", "Write the digest to this file instead of stdout")
+	fs.StringVar(&slackWebhook, "slack-webhook", os.Getenv("CLAUDE_COMPANION_SLACK_WEBHOOK"), "Post the digest to this Slack incoming webhook URL (can also use CLAUDE_COMPANION_SLACK_WEBHOOK env var)")
+	fs.BoolVar(&jsonOutput, "json", false, "Print the digest as JSON instead of Markdown")
+	if err := fs.Parse(args); err != nil {
+		if err == pflag.ErrHelp {
+			return 0
+		}
+		return 2
+	}
+	if hours <= 0 {
+		logger.LogError("--hours must be positive")
+		return 2
+	}
+
+	paths, err := transcriptPaths("", projectsRoots, "", "")
+	if err != nil {
+		logger.LogError("%v", err)
+		return 2
+	}
+	until := time.Now()
+	since := until.Add(-time.Duration(hours) * time.Hour)
+	builder := digest.NewBuilder(since, until)
+	for _, path := range paths {
+		// Transcripts are append-only, so one untouched since the window started has nothing in it
+		if info, err := os.Stat(path); err != nil || info.ModTime().Before(since) {
+			continue
+		}
+		if err := builder.AddFile(path); err != nil {
+			logger.LogWarning("Skipping %s: %v", path, err)
+		}
+	}
+	d := builder.Digest()
+
+	var report bytes.Buffer
+	if jsonOutput {
+		enc := json.NewEncoder(&report)
+		enc.SetIndent("", "  ")
+		err = enc.Encode(d)
+	} else {
+		err = d.WriteMarkdown(&report)
+	}
+	if err != nil {
+		logger.LogError("Failed to write the digest: %v", err)
+		return 1
+	}
+
+	if output != "" {
+		if err := os.WriteFile(output, report.Bytes(), 0o644); err != nil {
+			logger.LogError("Failed to write %s: %v", output, err)
+			return 1
+		}
+	} else if slackWebhook == "" {
+		os.Stdout.Write(report.Bytes())
+	}
+	if slackWebhook != "" {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+		if err := postSlack(ctx, slackWebhook, report.String()); err != nil {
+			logger.LogError("%v", err)
+			return 1
+		}
+	}
+	return 0
+}
+
+// postSlack posts a message to a Slack incoming webhook
+func postSlack(ctx context.Context, webhook, text string) error {
+	payload, err := json.Marshal(map[string]string{"text": text})
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, "POST", webhook, bytes.NewReader(payload))
+	if err != nil {
+		return fmt.Errorf("invalid Slack webhook: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to post to Slack: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("Slack rejected the digest: %s: %s", resp.Status, bytes.TrimSpace(body))
+	}
+	return nil
+}
@@ -0,0 +1,310 @@
+package digest
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/kazegusuri/claude-companion/event"
+	"github.com/kazegusuri/claude-companion/usage"
+)
+
+// maxLineSize is the maximum size of a transcript line
+const maxLineSize = 10 * 1024 * 1024
+
+const (
+	// maxTools is the number of tools listed in a digest
+	maxTools = 10
+	// maxErrors is the number of errors listed in a digest
+	maxErrors = 10
+	// maxErrorLength is the length an error message is truncated to
+	maxErrorLength = 120
+)
+
+// Project is the activity of a project in the digest window
+type Project struct {
+	Name     string       `json:"name"`
+	Sessions int          `json:"sessions"`
+	Tokens   usage.Tokens `json:"tokens"`
+	Cost     float64      `json:"cost"`
+	LastSeen time.Time    `json:"lastSeen"`
+}
+
+// ToolCount is how many times a tool was used
+type ToolCount struct {
+	Name  string `json:"name"`
+	Count int    `json:"count"`
+}
+
+// Error is a failed tool call or API error
+type Error struct {
+	Time    time.Time `json:"time"`
+	Project string    `json:"project"`
+	Tool    string    `json:"tool,omitempty"` // Empty for API errors
+	Message string    `json:"message"`
+}
+
+// Digest summarizes the sessions of all projects in a time window
+type Digest struct {
+	Since    time.Time    `json:"since"`
+	Until    time.Time    `json:"until"`
+	Projects []Project    `json:"projects"`
+	Tokens   usage.Tokens `json:"tokens"`
+	Cost     float64      `json:"cost"`
+	Tools    []ToolCount  `json:"tools"`
+	Errors   []Error      `json:"errors"`
+}
+
+// projectActivity accumulates the activity of a project
+type projectActivity struct {
+	name     string
+	sessions int
+	models   usage.ModelUsage
+	lastSeen time.Time
+}
+
+// Builder reads transcripts and aggregates the activity in a time window
+type Builder struct {
+	since, until time.Time
+	projects     map[string]*projectActivity
+	tools        map[string]int
+	errors       []Error
+}
+
+// NewBuilder creates a builder of the digest of events in [since, until)
+func NewBuilder(since, until time.Time) *Builder {
+	return &Builder{
+		since:    since,
+		until:    until,
+		projects: make(map[string]*projectActivity),
+		tools:    make(map[string]int),
+	}
+}
+
+// AddFile adds the events of a session transcript
+func (b *Builder) AddFile(path string) error {
+	file, err := event.OpenTranscript(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	return b.Add(file, path)
+}
+
+// Add adds the events of a transcript read from r; path names the project and session
+func (b *Builder) Add(r io.Reader, path string) error {
+	project := filepath.Base(filepath.Dir(path))
+	sessionUsage := usage.NewSessionUsage(project, event.SessionName(path))
+	toolNames := make(map[string]string) // tool_use ID -> tool name
+	active := false
+
+	parser := event.NewParserWithPath(path)
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), maxLineSize)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+		ev, err := parser.Parse(line)
+		if err != nil {
+			continue // Broken lines are reported by fsck
+		}
+		base := event.BaseOf(ev)
+		if base == nil || !b.inWindow(base.Timestamp) {
+			continue
+		}
+		if base.CWD != "" {
+			project = base.CWD
+		}
+		active = true
+
+		switch e := ev.(type) {
+		case *event.AssistantMessage:
+			sessionUsage.AddMessage(e)
+			if e.IsApiErrorMessage {
+				b.addError(e.Timestamp, project, "", assistantText(e))
+			}
+			for _, content := range e.Message.Content {
+				if content.Type == "tool_use" {
+					b.tools[content.Name]++
+					toolNames[content.ID] = content.Name
+				}
+			}
+		case *event.UserMessage:
+			items, _ := e.Message.Content.([]interface{})
+			for _, item := range items {
+				m, ok := item.(map[string]interface{})
+				if !ok || m["type"] != "tool_result" || m["is_error"] != true {
+					continue
+				}
+				id, _ := m["tool_use_id"].(string)
+				b.addError(e.Timestamp, project, toolNames[id], resultText(m["content"]))
+			}
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("error reading file: %w", err)
+	}
+	if !active {
+		return nil
+	}
+
+	p, ok := b.projects[project]
+	if !ok {
+		p = &projectActivity{name: project, models: make(usage.ModelUsage)}
+		b.projects[project] = p
+	}
+	p.sessions++
+	p.models.Merge(sessionUsage.Models)
+	if sessionUsage.LastSeen.After(p.lastSeen) {
+		p.lastSeen = sessionUsage.LastSeen
+	}
+	return nil
+}
+
+// inWindow reports whether t is in the digest window
+func (b *Builder) inWindow(t time.Time) bool {
+	return !t.IsZero() && !t.Before(b.since) && t.Before(b.until)
+}
+
+// addError records an error, keeping the first line of its message
+func (b *Builder) addError(t time.Time, project, tool, message string) {
+	message, _, _ = strings.Cut(strings.TrimSpace(message), "\n")
+	if r := []rune(message); len(r) > maxErrorLength {
+		message = string(r[:maxErrorLength]) + "…"
+	}
+	if message == "" {
+		message = "(no message)"
+	}
+	b.errors = append(b.errors, Error{Time: t, Project: project, Tool: tool, Message: message})
+}
+
+// assistantText returns the text of an assistant message
+func assistantText(msg *event.AssistantMessage) string {
+	for _, content := range msg.Message.Content {
+		if content.Type == "text" {
+			return content.Text
+		}
+	}
+	return ""
+}
+
+// resultText returns the text of a tool_result content, which is a string or a list of blocks
+func resultText(content interface{}) string {
+	switch c := content.(type) {
+	case string:
+		return c
+	case []interface{}:
+		for _, item := range c {
+			if m, ok := item.(map[string]interface{}); ok {
+				if text, ok := m["text"].(string); ok {
+					return text
+				}
+			}
+		}
+	}
+	return ""
+}
+
+// Digest returns the aggregated activity: projects by cost, the most used tools and
+// the latest errors
+func (b *Builder) Digest() *Digest {
+	d := &Digest{Since: b.since, Until: b.until}
+	all := make(usage.ModelUsage)
+	for _, p := range b.projects {
+		all.Merge(p.models)
+		d.Projects = append(d.Projects, Project{
+			Name:     p.name,
+			Sessions: p.sessions,
+			Tokens:   p.models.Tokens(),
+			Cost:     p.models.Cost(),
+			LastSeen: p.lastSeen,
+		})
+	}
+	d.Tokens = all.Tokens()
+	d.Cost = all.Cost()
+	sort.Slice(d.Projects, func(i, j int) bool {
+		if d.Projects[i].Cost != d.Projects[j].Cost {
+			return d.Projects[i].Cost > d.Projects[j].Cost
+		}
+		return d.Projects[i].Name < d.Projects[j].Name
+	})
+
+	for name, count := range b.tools {
+		d.Tools = append(d.Tools, ToolCount{Name: name, Count: count})
+	}
+	sort.Slice(d.Tools, func(i, j int) bool {
+		if d.Tools[i].Count != d.Tools[j].Count {
+			return d.Tools[i].Count > d.Tools[j].Count
+		}
+		return d.Tools[i].Name < d.Tools[j].Name
+	})
+	if len(d.Tools) > maxTools {
+		d.Tools = d.Tools[:maxTools]
+	}
+
+	d.Errors = append(d.Errors, b.errors...)
+	sort.SliceStable(d.Errors, func(i, j int) bool { return d.Errors[i].Time.After(d.Errors[j].Time) })
+	if len(d.Errors) > maxErrors {
+		d.Errors = d.Errors[:maxErrors]
+	}
+	return d
+}
+
+// WriteMarkdown writes the digest as a Markdown report
+func (d *Digest) WriteMarkdown(w io.Writer) error {
+	var b strings.Builder
+	fmt.Fprintf(&b, "# Claude Code digest: %s – %s\n\n", d.Since.Local().Format("2006-01-02 15:04"), d.Until.Local().Format("2006-01-02 15:04"))
+	if len(d.Projects) == 0 {
+		b.WriteString("No sessions in this period.\n")
+		_, err := io.WriteString(w, b.String())
+		return err
+	}
+
+	sessions := 0
+	for _, p := range d.Projects {
+		sessions += p.Sessions
+	}
+	fmt.Fprintf(&b, "**%d sessions** in **%d projects**, %d tokens, $%.2f estimated\n\n", sessions, len(d.Projects), d.Tokens.Total(), d.Cost)
+
+	b.WriteString("## Projects\n\n")
+	b.WriteString("| Project | Sessions | Tokens | Cost (USD) | Last active |\n")
+	b.WriteString("|---|---:|---:|---:|---|\n")
+	for _, p := range d.Projects {
+		lastSeen := "-"
+		if !p.LastSeen.IsZero() {
+			lastSeen = p.LastSeen.Local().Format("01-02 15:04")
+		}
+		fmt.Fprintf(&b, "| %s | %d | %d | $%.2f | %s |\n", markdownCell(p.Name), p.Sessions, p.Tokens.Total(), p.Cost, lastSeen)
+	}
+
+	if len(d.Tools) > 0 {
+		b.WriteString("\n## Top tools\n\n")
+		for i, t := range d.Tools {
+			fmt.Fprintf(&b, "%d. %s (%d)\n", i+1, t.Name, t.Count)
+		}
+	}
+
+	if len(d.Errors) > 0 {
+		b.WriteString("\n## Notable errors\n\n")
+		for _, e := range d.Errors {
+			source := "API"
+			if e.Tool != "" {
+				source = e.Tool
+			}
+			fmt.Fprintf(&b, "- %s `%s` %s: %s\n", e.Time.Local().Format("01-02 15:04"), source, filepath.Base(e.Project), e.Message)
+		}
+	}
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// markdownCell escapes the pipes of a table cell
+func markdownCell(s string) string {
+	return strings.ReplaceAll(s, "|", `\|`)
+}
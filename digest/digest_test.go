@@ -0,0 +1,93 @@
+package digest
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+const transcript = `{"type":"user","uuid":"u0","cwd":"/home/user/app","timestamp":"2025-01-01T08:00:00Z","message":{"role":"user","content":"Yesterday's work"}}
+{"type":"assistant","uuid":"a0","cwd":"/home/user/app","timestamp":"2025-01-01T08:00:05Z","requestId":"r0","message":{"id":"m0","model":"claude-sonnet-4-20250514","content":[{"type":"tool_use","id":"toolu_0","name":"Grep","input":{"pattern":"x"}}],"usage":{"input_tokens":1000,"output_tokens":1000}}}
+{"type":"user","uuid":"u1","cwd":"/home/user/app","timestamp":"2025-01-02T10:00:00Z","message":{"role":"user","content":"Fix the build"}}
+{"type":"assistant","uuid":"a1","cwd":"/home/user/app","timestamp":"2025-01-02T10:00:05Z","requestId":"r1","message":{"id":"m1","model":"claude-sonnet-4-20250514","content":[{"type":"tool_use","id":"toolu_1","name":"Bash","input":{"command":"go build ./..."}}],"usage":{"input_tokens":10,"output_tokens":20}}}
+{"type":"assistant","uuid":"a2","cwd":"/home/user/app","timestamp":"2025-01-02T10:00:06Z","requestId":"r1","message":{"id":"m1","model":"claude-sonnet-4-20250514","content":[{"type":"tool_use","id":"toolu_2","name":"Bash","input":{"command":"go vet ./..."}}],"usage":{"input_tokens":10,"output_tokens":20}}}
+{"type":"user","uuid":"u2","cwd":"/home/user/app","timestamp":"2025-01-02T10:00:10Z","message":{"role":"user","content":[{"type":"tool_result","tool_use_id":"toolu_1","is_error":true,"content":"main.go:3:1: syntax error\nexit status 1"}]}}
+{"type":"assistant","uuid":"a3","cwd":"/home/user/app","timestamp":"2025-01-02T10:01:00Z","requestId":"r2","isApiErrorMessage":true,"message":{"id":"m2","model":"<synthetic>","content":[{"type":"text","text":"API Error: 529 overloaded"}],"usage":{"input_tokens":0,"output_tokens":0}}}
+{"type":"assistant","uuid":"a4","cwd":"/home/user/app","timestamp":"2025-01-02T10:02:00Z","requestId":"r3","message":{"id":"m3","model":"claude-sonnet-4-20250514","content":[{"type":"tool_use","id":"toolu_3","name":"Read","input":{"file_path":"/home/user/app/main.go"}}],"usage":{"input_tokens":5,"output_tokens":5}}}
+{broken
+`
+
+func TestBuilder(t *testing.T) {
+	since := time.Date(2025, 1, 2, 0, 0, 0, 0, time.UTC)
+	b := NewBuilder(since, since.Add(24*time.Hour))
+	if err := b.Add(strings.NewReader(transcript), "/p/-home-user-app/s1.jsonl"); err != nil {
+		t.Fatal(err)
+	}
+	// A session entirely outside of the window is not counted
+	if err := b.Add(strings.NewReader(strings.SplitAfter(transcript, "\n")[0]), "/p/-home-user-app/s0.jsonl"); err != nil {
+		t.Fatal(err)
+	}
+	d := b.Digest()
+
+	if len(d.Projects) != 1 {
+		t.Fatalf("got %d projects, want 1", len(d.Projects))
+	}
+	p := d.Projects[0]
+	if p.Name != "/home/user/app" || p.Sessions != 1 {
+		t.Errorf("project = %q with %d sessions, want /home/user/app with 1", p.Name, p.Sessions)
+	}
+	// m1 is counted once, and yesterday's m0 is out of the window
+	if got := d.Tokens.Total(); got != 40 {
+		t.Errorf("total tokens = %d, want 40", got)
+	}
+	if d.Cost <= 0 {
+		t.Errorf("cost = %v, want > 0", d.Cost)
+	}
+
+	wantTools := []ToolCount{{Name: "Bash", Count: 2}, {Name: "Read", Count: 1}}
+	if diff := cmp.Diff(wantTools, d.Tools); diff != "" {
+		t.Errorf("tools mismatch (-want +got):\n%s", diff)
+	}
+
+	wantErrors := []Error{
+		{Time: time.Date(2025, 1, 2, 10, 1, 0, 0, time.UTC), Project: "/home/user/app", Message: "API Error: 529 overloaded"},
+		{Time: time.Date(2025, 1, 2, 10, 0, 10, 0, time.UTC), Project: "/home/user/app", Tool: "Bash", Message: "main.go:3:1: syntax error"},
+	}
+	if diff := cmp.Diff(wantErrors, d.Errors); diff != "" {
+		t.Errorf("errors mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestWriteMarkdown(t *testing.T) {
+	since := time.Date(2025, 1, 2, 0, 0, 0, 0, time.UTC)
+	b := NewBuilder(since, since.Add(24*time.Hour))
+	if err := b.Add(strings.NewReader(transcript), "/p/-home-user-app/s1.jsonl"); err != nil {
+		t.Fatal(err)
+	}
+	var out bytes.Buffer
+	if err := b.Digest().WriteMarkdown(&out); err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{
+		"**1 sessions** in **1 projects**, 40 tokens",
+		"| /home/user/app | 1 | 40 |",
+		"## Top tools\n\n1. Bash (2)\n2. Read (1)\n",
+		"`Bash` app: main.go:3:1: syntax error",
+		"`API` app: API Error: 529 overloaded",
+	} {
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("markdown does not contain %q:\n%s", want, out.String())
+		}
+	}
+
+	out.Reset()
+	if err := NewBuilder(since, since.Add(time.Hour)).Digest().WriteMarkdown(&out); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(out.String(), "No sessions in this period.") {
+		t.Errorf("empty digest = %q", out.String())
+	}
+}
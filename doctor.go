@@ -0,0 +1,175 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/kazegusuri/claude-companion/logger"
+	"github.com/kazegusuri/claude-companion/narrator"
+	"github.com/kazegusuri/claude-companion/server"
+	"github.com/spf13/pflag"
+)
+
+// runDoctor prints the health report of a running companion. It exits with 1 if a
+// check failed or the companion cannot be reached.
+func runDoctor(args []string) int {
+	fs := pflag.NewFlagSet("doctor", pflag.ContinueOnError)
+	var serverAddr string
+	var serverCA string
+	var token string
+	var controlSocket string
+	var timeout time.Duration
+	var jsonOutput bool
+	fs.StringVar(&serverAddr, "server-addr", "127.0.0.1:8765", "Address of the companion HTTP server, as host:port or an http:// or https:// URL")
+	fs.StringVar(&serverCA, "server-ca", "", "PEM CA certificate to verify the HTTPS companion server with, such as its --server-tls-cert; implies https")
+	fs.StringVar(&token, "token", os.Getenv("CLAUDE_COMPANION_TOKEN"), "API token for the companion server (can also use CLAUDE_COMPANION_TOKEN env var)")
+	fs.StringVar(&controlSocket, "control-socket", "", "Control socket of the companion to use instead of the HTTP server")
+	fs.DurationVar(&timeout, "timeout", 30*time.Second, "Timeout for the request")
+	fs.BoolVar(&jsonOutput, "json", false, "Print the report as JSON")
+	fs.Usage = func() {
+		fmt.Fprintln(os.Stderr, "Usage: claude-companion doctor [flags]")
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args); err != nil {
+		if err == pflag.ErrHelp {
+			return 0
+		}
+		return 2
+	}
+	if fs.NArg() > 0 {
+		fs.Usage()
+		return 2
+	}
+
+	client, baseURL, err := companionClient(serverAddr, serverCA, controlSocket)
+	if err != nil {
+		logger.LogError("%v", err)
+		return 2
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	report, err := fetchHealth(ctx, client, baseURL, token)
+	if err != nil {
+		logger.LogError("%v", err)
+		return 1
+	}
+
+	if jsonOutput {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		enc.Encode(report)
+	} else {
+		printHealth(report, time.Now())
+	}
+	if report.Status != server.HealthOK {
+		return 1
+	}
+	return 0
+}
+
+// fetchHealth gets the health report of the companion, which answers 503 when degraded
+func fetchHealth(ctx context.Context, client *http.Client, baseURL, token string) (server.HealthReport, error) {
+	var report server.HealthReport
+	req, err := http.NewRequestWithContext(ctx, "GET", baseURL+"/api/health", nil)
+	if err != nil {
+		return report, err
+	}
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return report, fmt.Errorf("failed to reach the companion: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusServiceUnavailable {
+		return report, fmt.Errorf("failed to get the health report: %s", resp.Status)
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&report); err != nil {
+		return report, fmt.Errorf("failed to decode response: %w", err)
+	}
+	return report, nil
+}
+
+// printHealth prints a health report for people
+func printHealth(report server.HealthReport, now time.Time) {
+	fmt.Printf("Status: %s (up %s)\n", report.Status, now.Sub(report.StartedAt).Round(time.Second))
+
+	fmt.Println("\nChecks:")
+	if len(report.Checks) == 0 {
+		fmt.Println("  (none)")
+	}
+	for _, c := range report.Checks {
+		state, message := "ok", c.Detail
+		if !c.OK {
+			state, message = "FAIL", c.Error
+			if c.Detail != "" {
+				message = c.Detail + ": " + c.Error
+			}
+		}
+		fmt.Printf("  %-4s  %-12s %6dms  %s\n", state, c.Name, c.LatencyMillis, message)
+	}
+
+	if w := report.Watchers; w != nil {
+		fmt.Printf("\nWatchers: %d root(s), %d file(s) tailed, %d bytes behind\n", len(w.Roots), w.TailedFiles, w.LagBytes)
+		for _, root := range w.Roots {
+			fmt.Printf("  %-8s %s\n", root.Mode, root.Path)
+		}
+	}
+
+	if len(report.Queues) > 0 {
+		names := make([]string, 0, len(report.Queues))
+		for name := range report.Queues {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		queues := make([]string, len(names))
+		for i, name := range names {
+			queues[i] = fmt.Sprintf("%s=%d", name, report.Queues[name])
+		}
+		fmt.Printf("\nQueues: %s\n", strings.Join(queues, " "))
+	}
+
+	fmt.Printf("\nSessions: %d\n", len(report.Sessions))
+	for _, s := range report.Sessions {
+		fmt.Printf("  %s  %s ago  %s\n", s.ID, now.Sub(s.LastEvent).Round(time.Second), s.Project)
+	}
+}
+
+// aiHealthCheck checks the providers of the AI narrator. It fails when none of them
+// answered its last request, reporting the quota errors that are often the cause.
+func aiHealthCheck(health func() []narrator.ProviderHealth) server.HealthCheck {
+	return func(ctx context.Context) (string, error) {
+		providers := health()
+		if len(providers) == 0 {
+			return "", fmt.Errorf("no AI provider is configured")
+		}
+		var states []string
+		var lastError string
+		working := 0
+		for _, p := range providers {
+			state := "ok"
+			if !p.Healthy || p.Failures > 0 {
+				state = fmt.Sprintf("%d failure(s)", p.Failures)
+				lastError = fmt.Sprintf("%s: %s", p.Name, p.LastError)
+			} else {
+				working++
+			}
+			if p.QuotaErrors > 0 {
+				state += fmt.Sprintf(", %d quota error(s)", p.QuotaErrors)
+			}
+			states = append(states, fmt.Sprintf("%s %s", p.Name, state))
+		}
+		detail := strings.Join(states, "; ")
+		if working == 0 {
+			return detail, fmt.Errorf("no AI provider is answering (%s)", lastError)
+		}
+		return detail, nil
+	}
+}
@@ -0,0 +1,73 @@
+package event
+
+import (
+	"strings"
+)
+
+// accessibleReplacer replaces emojis with bracketed text labels for screen readers and braille displays.
+// Longer sequences come first so that headers and todo items get specific labels.
+var accessibleReplacer = strings.NewReplacer(
+	// Headers already name the event, so the emoji and name collapse into one label
+	"👤 USER", "[USER]",
+	"🤖 ASSISTANT", "[ASSISTANT]",
+	"📣 SYSTEM", "[SYSTEM]",
+	"🪝 HOOK", "[HOOK]",
+	"📋 [SUMMARY]", "[SUMMARY]",
+	"⏱️ SLA BREACH", "[SLA BREACH]",
+	"📊 SESSION SUMMARY", "[SESSION SUMMARY]",
+	"💸 BUDGET", "[BUDGET]",
+	"🧠 CONTEXT", "[CONTEXT]",
+	"🚨 SECURITY", "[SECURITY]",
+
+	// Todo items
+	". ✅ ", ". [DONE] ",
+	". 🔄 ", ". [IN PROGRESS] ",
+	". ⏳ ", ". [PENDING] ",
+
+	// Tool fallbacks are followed by two spaces for alignment
+	"✏️  ", "[WRITE] ",
+	"✂️  ", "[EDIT] ",
+	"🖥️  ", "[COMMAND] ",
+	"🏷️  ", "[LEVEL] ",
+
+	"💬", "[NARRATION]",
+	"📝", "[TEXT]",
+	"💰", "[TOKENS]",
+	"📁", "[FILES]",
+	"📄", "[READ]",
+	"🔍", "[SEARCH]",
+	"🌐", "[WEB]",
+	"🤖", "[AGENT]",
+	"🔧", "[TOOL]",
+	"🎯", "[COMMAND]",
+	"📤", "[OUTPUT]",
+	"📟", "[COMMAND]",
+	"📂", "[DIRECTORY]",
+	"🌳", "[BRANCH]",
+	"🗜️", "[COMPACT]",
+	"🚀", "[SESSION START]",
+	"🔔", "[NOTIFICATION]",
+	"🔐", "[PERMISSION]",
+	"⏳", "[WAITING]",
+	"🔄", "[IN PROGRESS]",
+	"✅", "[OK]",
+	"❌", "[ERROR]",
+	"⚠️", "[WARNING]",
+	"ℹ️", "[INFO]",
+	"🐛", "[DEBUG]",
+
+	// Screen-reader-friendly phrasing
+	"↳ ", "detail: ",
+	"more lines)", "more lines not shown)",
+	"\ufe0f", "", // Drop leftover variation selectors
+)
+
+// accessibleText converts formatted output to its accessible form
+func accessibleText(text string) string {
+	return accessibleReplacer.Replace(text)
+}
+
+// SetAccessible enables or disables emoji-free accessible output
+func (f *Formatter) SetAccessible(enabled bool) {
+	f.accessible = enabled
+}
@@ -0,0 +1,76 @@
+package event
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/kazegusuri/claude-companion/narrator"
+)
+
+func TestAccessibleText(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  string
+	}{
+		{
+			name:  "user header",
+			input: "[15:04:05] 👤 USER:\n  💬 Hello\n",
+			want:  "[15:04:05] [USER]:\n  [NARRATION] Hello\n",
+		},
+		{
+			name:  "tool fallback",
+			input: "  ✏️  Writing file: main.go (id: toolu_1)\n",
+			want:  "  [WRITE] Writing file: main.go (id: toolu_1)\n",
+		},
+		{
+			name:  "todo items",
+			input: "  ✅ Updating todo list\n    1. ✅ done\n    2. 🔄 doing\n    3. ⏳ todo\n",
+			want:  "  [OK] Updating todo list\n    1. [DONE] done\n    2. [IN PROGRESS] doing\n    3. [PENDING] todo\n",
+		},
+		{
+			name:  "system warning",
+			input: "[15:04:05] 📣 SYSTEM [warning]:\n  ⚠️ Rate limit\n",
+			want:  "[15:04:05] [SYSTEM] [warning]:\n  [WARNING] Rate limit\n",
+		},
+		{
+			name:  "truncated lines",
+			input: "  ... (3 more lines)\n",
+			want:  "  ... (3 more lines not shown)\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := accessibleText(tt.input); got != tt.want {
+				t.Errorf("accessibleText() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFormatterAccessible(t *testing.T) {
+	f := NewFormatter(narrator.NewNoOpNarrator())
+	f.SetAccessible(true)
+
+	msg := &AssistantMessage{
+		BaseEvent: BaseEvent{Timestamp: time.Date(2025, 1, 1, 15, 4, 5, 0, time.UTC)},
+	}
+	msg.Message.Model = "claude-sonnet-4"
+	msg.Message.Content = []AssistantContent{{Type: "tool_use", ID: "toolu_1", Name: "Bash", Input: map[string]interface{}{"command": "make test"}}}
+
+	got, err := f.Format(msg)
+	if err != nil {
+		t.Fatalf("Format() error = %v", err)
+	}
+	if !strings.Contains(got, "[ASSISTANT] (claude-sonnet-4):") || !strings.Contains(got, "[COMMAND] Running command: make test") {
+		t.Errorf("Format() = %q, want text labels", got)
+	}
+	for _, r := range got {
+		if r >= 0x1F300 || (r >= 0x2600 && r <= 0x27BF) {
+			t.Errorf("Format() = %q, contains emoji %q", got, r)
+			break
+		}
+	}
+}
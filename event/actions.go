@@ -0,0 +1,360 @@
+package event
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"os/exec"
+	"path"
+	"regexp"
+	"strings"
+	"sync"
+	"text/template"
+	"time"
+
+	"github.com/kazegusuri/claude-companion/logger"
+	"gopkg.in/yaml.v3"
+)
+
+// defaultActionTimeout bounds how long an action command or webhook may run
+const defaultActionTimeout = 30 * time.Second
+
+// maxRunningActions bounds the actions running at once; matches beyond it are skipped
+const maxRunningActions = 8
+
+// ActionRule is a rule of an actions file: when an event matches all of its
+// conditions, it runs a command, posts to a webhook, or both
+type ActionRule struct {
+	Name string `yaml:"name"`
+
+	// Conditions; those left empty match anything
+	Events []string          `yaml:"events"` // Event kinds
+	Tools  []string          `yaml:"tools"`  // Tool names or glob patterns, matched against the tool uses of assistant messages
+	Input  map[string]string `yaml:"input"`  // Regular expression per tool input field, such as command or file_path
+	Text   string            `yaml:"text"`   // Regular expression over the text of the event
+
+	// Actions, rendered from Go templates with an ActionEvent
+	Command []string      `yaml:"command"` // Program and arguments, run without a shell
+	Webhook string        `yaml:"webhook"` // URL to POST to
+	Body    string        `yaml:"body"`    // Webhook body; the ActionEvent as JSON by default
+	Timeout time.Duration `yaml:"timeout"` // Limit of the command or webhook; 30s by default
+}
+
+// ActionEvent is what a rule matched, and the data of its templates
+type ActionEvent struct {
+	Action  string                 `json:"action"` // Name of the rule
+	Kind    string                 `json:"kind"`
+	Time    time.Time              `json:"time"`
+	Project string                 `json:"project,omitempty"`
+	Session string                 `json:"session,omitempty"`
+	Tool    string                 `json:"tool,omitempty"`
+	ToolID  string                 `json:"toolId,omitempty"`
+	Input   map[string]interface{} `json:"input,omitempty"`
+	Text    string                 `json:"text,omitempty"`
+}
+
+// action is a rule with its patterns and templates compiled
+type action struct {
+	ActionRule
+	events  map[string]bool
+	input   map[string]*regexp.Regexp
+	text    *regexp.Regexp
+	command []*template.Template
+	webhook *template.Template
+	body    *template.Template
+}
+
+// Actions runs commands and webhooks for the events that match its rules. Actions
+// run in the background so that slow commands do not hold up the console.
+type Actions struct {
+	actions []*action
+	client  *http.Client
+	running chan struct{} // Semaphore of the actions running
+	wg      sync.WaitGroup
+}
+
+// LoadActions loads an actions file
+func LoadActions(path string) (*Actions, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read actions: %w", err)
+	}
+	return ParseActions(data)
+}
+
+// ParseActions parses an actions file, a YAML document with a list of rules under actions
+func ParseActions(data []byte) (*Actions, error) {
+	var file struct {
+		Actions []ActionRule `yaml:"actions"`
+	}
+	if err := yaml.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("failed to parse actions: %w", err)
+	}
+	a := &Actions{client: &http.Client{}, running: make(chan struct{}, maxRunningActions)}
+	for i, rule := range file.Actions {
+		if rule.Name == "" {
+			rule.Name = fmt.Sprintf("action %d", i+1)
+		}
+		compiled, err := compileAction(rule)
+		if err != nil {
+			return nil, fmt.Errorf("invalid action %q: %w", rule.Name, err)
+		}
+		a.actions = append(a.actions, compiled)
+	}
+	return a, nil
+}
+
+// compileAction validates a rule and compiles its patterns and templates
+func compileAction(rule ActionRule) (*action, error) {
+	if len(rule.Command) == 0 && rule.Webhook == "" {
+		return nil, fmt.Errorf("no command or webhook to run")
+	}
+	if rule.Timeout <= 0 {
+		rule.Timeout = defaultActionTimeout
+	}
+	a := &action{ActionRule: rule, events: make(map[string]bool), input: make(map[string]*regexp.Regexp)}
+	for _, kind := range rule.Events {
+		if !isEventKind(kind) {
+			return nil, fmt.Errorf("invalid event kind: %s (must be one of %s)", kind, strings.Join(EventKinds, ", "))
+		}
+		a.events[kind] = true
+	}
+	for _, pattern := range rule.Tools {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return nil, fmt.Errorf("invalid tool pattern %q: %w", pattern, err)
+		}
+	}
+	for field, pattern := range rule.Input {
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return nil, fmt.Errorf("invalid input pattern for %s: %w", field, err)
+		}
+		a.input[field] = re
+	}
+	if rule.Text != "" {
+		re, err := regexp.Compile(rule.Text)
+		if err != nil {
+			return nil, fmt.Errorf("invalid text pattern: %w", err)
+		}
+		a.text = re
+	}
+
+	var err error
+	parse := func(name, text string) *template.Template {
+		if err != nil || text == "" {
+			return nil
+		}
+		var tmpl *template.Template
+		tmpl, err = template.New(name).Funcs(themeFuncs).Option("missingkey=zero").Parse(text)
+		if err != nil {
+			err = fmt.Errorf("invalid %s template: %w", name, err)
+		}
+		return tmpl
+	}
+	for _, arg := range rule.Command {
+		a.command = append(a.command, parse("command", arg))
+	}
+	a.webhook = parse("webhook", rule.Webhook)
+	a.body = parse("body", rule.Body)
+	if err != nil {
+		return nil, err
+	}
+	return a, nil
+}
+
+// Len returns the number of rules; 0 for nil actions
+func (a *Actions) Len() int {
+	if a == nil {
+		return 0
+	}
+	return len(a.actions)
+}
+
+// Observe runs the actions whose rules an event matches. Rules with tools or input
+// conditions are matched against each tool use of an assistant message, and other
+// rules against the event as a whole.
+func (a *Actions) Observe(event Event) {
+	if len(a.actions) == 0 {
+		return
+	}
+	whole, toolUses := actionEventsOf(event)
+	for _, act := range a.actions {
+		candidates := []ActionEvent{whole}
+		if len(act.Tools) > 0 || len(act.Input) > 0 {
+			candidates = toolUses
+		}
+		for _, candidate := range candidates {
+			if act.matches(candidate) {
+				candidate.Action = act.Name
+				a.run(act, candidate)
+			}
+		}
+	}
+}
+
+// actionEventsOf returns the data of an event as a whole, and of each of its tool uses
+func actionEventsOf(event Event) (ActionEvent, []ActionEvent) {
+	whole := ActionEvent{Kind: KindOf(event)}
+	if session := SessionOf(event); session != nil {
+		whole.Project = session.Project
+		whole.Session = session.Session
+	}
+	if base := BaseOf(event); base != nil {
+		whole.Time = base.Timestamp
+	}
+
+	var toolUses []ActionEvent
+	switch e := event.(type) {
+	case *AssistantMessage:
+		var texts []string
+		for _, content := range e.Message.Content {
+			switch content.Type {
+			case "text":
+				texts = append(texts, content.Text)
+			case "tool_use":
+				toolUse := whole
+				toolUse.Tool = content.Name
+				toolUse.ToolID = content.ID
+				toolUse.Input, _ = content.Input.(map[string]interface{})
+				toolUses = append(toolUses, toolUse)
+			}
+		}
+		whole.Text = strings.Join(texts, "\n")
+	case *UserMessage:
+		whole.Text = userText(e)
+	case *SystemMessage:
+		whole.Text = e.Content
+	case *HookEvent:
+		whole.Text = e.Content
+	case *NotificationEvent:
+		whole.Time = notificationTime(e)
+		whole.Text = e.Message
+	}
+	return whole, toolUses
+}
+
+// matches reports whether an event meets all the conditions of the rule
+func (act *action) matches(e ActionEvent) bool {
+	if len(act.events) > 0 && !act.events[e.Kind] {
+		return false
+	}
+	if len(act.Tools) > 0 && !matchAny(act.Tools, e.Tool) {
+		return false
+	}
+	for field, re := range act.input {
+		value, ok := e.Input[field]
+		if !ok {
+			return false
+		}
+		s, ok := value.(string)
+		if !ok {
+			s = fmt.Sprint(value)
+		}
+		if !re.MatchString(s) {
+			return false
+		}
+	}
+	return act.text == nil || act.text.MatchString(e.Text)
+}
+
+// run starts the command and the webhook of a rule in the background
+func (a *Actions) run(act *action, e ActionEvent) {
+	select {
+	case a.running <- struct{}{}:
+	default:
+		logger.LogWarning("Skipping action %s: %d actions are already running", act.Name, maxRunningActions)
+		return
+	}
+	a.wg.Add(1)
+	go func() {
+		defer func() {
+			<-a.running
+			a.wg.Done()
+		}()
+		ctx, cancel := context.WithTimeout(context.Background(), act.Timeout)
+		defer cancel()
+		if len(act.command) > 0 {
+			if err := act.runCommand(ctx, e); err != nil {
+				logger.LogWarning("Action %s failed: %v", act.Name, err)
+			}
+		}
+		if act.webhook != nil {
+			if err := act.post(ctx, a.client, e); err != nil {
+				logger.LogWarning("Action %s failed: %v", act.Name, err)
+			}
+		}
+	}()
+}
+
+// Wait waits for the running actions to finish
+func (a *Actions) Wait() {
+	a.wg.Wait()
+}
+
+// renderAction executes a template with an event
+func renderAction(tmpl *template.Template, e ActionEvent) (string, error) {
+	var out bytes.Buffer
+	if err := tmpl.Execute(&out, e); err != nil {
+		return "", err
+	}
+	return out.String(), nil
+}
+
+// runCommand runs the command of a rule, with each argument rendered from the event
+func (act *action) runCommand(ctx context.Context, e ActionEvent) error {
+	args := make([]string, len(act.command))
+	for i, tmpl := range act.command {
+		arg, err := renderAction(tmpl, e)
+		if err != nil {
+			return err
+		}
+		args[i] = arg
+	}
+	cmd := exec.CommandContext(ctx, args[0], args[1:]...)
+	output, err := cmd.CombinedOutput()
+	if len(output) > 0 {
+		logger.LogDebug("Action %s: %s", act.Name, bytes.TrimSpace(output))
+	}
+	if err != nil {
+		return fmt.Errorf("command %s: %w", args[0], err)
+	}
+	return nil
+}
+
+// post posts the event, or the rendered body, to the webhook of a rule
+func (act *action) post(ctx context.Context, client *http.Client, e ActionEvent) error {
+	url, err := renderAction(act.webhook, e)
+	if err != nil {
+		return err
+	}
+	var body []byte
+	if act.body != nil {
+		rendered, err := renderAction(act.body, e)
+		if err != nil {
+			return err
+		}
+		body = []byte(rendered)
+	} else if body, err = json.Marshal(e); err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, "POST", url, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("invalid webhook: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to post to the webhook: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("webhook rejected the event: %s: %s", resp.Status, bytes.TrimSpace(msg))
+	}
+	return nil
+}
@@ -0,0 +1,129 @@
+package event
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestParseActions(t *testing.T) {
+	tests := []struct {
+		name    string
+		yaml    string
+		wantErr string
+	}{
+		{name: "command", yaml: "actions:\n- tools: [Bash]\n  input: {command: 'rm\\s+-rf'}\n  command: [echo, '{{.Input.command}}']\n"},
+		{name: "webhook", yaml: "actions:\n- events: [notification]\n  webhook: https://example.com/hook\n  timeout: 5s\n"},
+		{name: "nothing to run", yaml: "actions:\n- name: empty\n  tools: [Bash]\n", wantErr: `invalid action "empty": no command or webhook`},
+		{name: "event kind", yaml: "actions:\n- events: [tool]\n  command: [true]\n", wantErr: "invalid event kind: tool"},
+		{name: "tool pattern", yaml: "actions:\n- tools: ['[']\n  command: [true]\n", wantErr: "invalid tool pattern"},
+		{name: "input pattern", yaml: "actions:\n- input: {command: '('}\n  command: [true]\n", wantErr: "invalid input pattern for command"},
+		{name: "template", yaml: "actions:\n- command: [echo, '{{.Tool']\n", wantErr: "invalid command template"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ParseActions([]byte(tt.yaml))
+			if tt.wantErr == "" && err != nil {
+				t.Fatalf("ParseActions() error = %v", err)
+			}
+			if tt.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tt.wantErr)) {
+				t.Fatalf("ParseActions() error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestActions_Command(t *testing.T) {
+	out := filepath.Join(t.TempDir(), "out.txt")
+	actions, err := ParseActions([]byte(`actions:
+- name: rm
+  tools: [Bash]
+  input:
+    command: 'rm\s+-rf'
+  command: [sh, -c, 'printf "%s\n" "$1" >> ` + out + `', sh, '{{.Action}} {{.Project}}/{{.Session}} {{.ToolID}}: {{.Input.command}}']
+- name: cleanup
+  events: [assistant]
+  text: '(?i)clean'
+  command: [sh, -c, 'printf "%s\n" "$1" >> ` + out + `', sh, '{{.Action}}: {{.Text}}']
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	text := AssistantContent{Type: "text", Text: "Cleaning up the build directory."}
+	actions.Observe(assistantMessage("s1", time.Now(), text, toolUseContent("toolu_1", "Bash", map[string]interface{}{"command": "ls build"})))
+	actions.Wait()
+	actions.Observe(assistantMessage("s1", time.Now(), text, toolUseContent("toolu_2", "Bash", map[string]interface{}{"command": "rm -rf build"})))
+	actions.Wait()
+
+	data, err := os.ReadFile(out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// The actions of one event run concurrently
+	got := strings.Split(strings.TrimSpace(string(data)), "\n")
+	sort.Strings(got)
+	want := []string{
+		"cleanup: Cleaning up the build directory.",
+		"cleanup: Cleaning up the build directory.",
+		"rm app/s1 toolu_2: rm -rf build",
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("commands mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestActions_Webhook(t *testing.T) {
+	var mu sync.Mutex
+	var requests []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var e ActionEvent
+		if err := json.NewDecoder(r.Body).Decode(&e); err != nil {
+			t.Errorf("webhook body: %v", err)
+		}
+		mu.Lock()
+		defer mu.Unlock()
+		requests = append(requests, r.URL.Path+" "+e.Input["command"].(string))
+	}))
+	defer srv.Close()
+
+	actions, err := ParseActions([]byte(`actions:
+- tools: [Bash]
+  webhook: ` + srv.URL + `/{{.Tool | lower}}
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	actions.Observe(assistantMessage("s1", time.Now(), toolUseContent("toolu_1", "Bash", map[string]interface{}{"command": "go test ./..."})))
+	actions.Observe(&UserMessage{BaseEvent: BaseEvent{TypeString: "user"}, Message: UserMessageContent{Role: "user", Content: "run the tests"}})
+	actions.Wait()
+
+	if diff := cmp.Diff([]string{"/bash go test ./..."}, requests); diff != "" {
+		t.Errorf("webhook requests mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestActionEventsOf(t *testing.T) {
+	at := time.Date(2025, 1, 1, 10, 0, 0, 0, time.UTC)
+	whole, toolUses := actionEventsOf(assistantMessage("s1", at,
+		AssistantContent{Type: "text", Text: "Cleaning up the build directory."},
+		toolUseContent("toolu_1", "Bash", map[string]interface{}{"command": "rm -rf build"}),
+	))
+	want := ActionEvent{Kind: KindAssistant, Time: at, Project: "app", Session: "s1", Text: "Cleaning up the build directory."}
+	if diff := cmp.Diff(want, whole); diff != "" {
+		t.Errorf("whole event mismatch (-want +got):\n%s", diff)
+	}
+	want.Text, want.Tool, want.ToolID, want.Input = "", "Bash", "toolu_1", map[string]interface{}{"command": "rm -rf build"}
+	if diff := cmp.Diff([]ActionEvent{want}, toolUses); diff != "" {
+		t.Errorf("tool uses mismatch (-want +got):\n%s", diff)
+	}
+}
@@ -0,0 +1,163 @@
+package event
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// AttachmentSource is the source of an image or document content block
+type AttachmentSource struct {
+	Type      string `json:"type"` // base64 or url
+	MediaType string `json:"media_type,omitempty"`
+	Data      string `json:"data,omitempty"`
+	URL       string `json:"url,omitempty"`
+}
+
+// Attachment is an image or document attached to a message
+type Attachment struct {
+	Kind      string // image or document
+	MediaType string
+	URL       string // For attachments referenced by URL instead of embedded
+	data      []byte
+}
+
+// isAttachment reports whether a content block type is an attachment
+func isAttachment(blockType string) bool {
+	return blockType == "image" || blockType == "document"
+}
+
+// newAttachment decodes an attachment of the given kind from its source
+func newAttachment(kind string, source *AttachmentSource) *Attachment {
+	a := &Attachment{Kind: kind}
+	if source == nil {
+		return a
+	}
+	a.MediaType = source.MediaType
+	a.URL = source.URL
+	if source.Type == "base64" {
+		// Undecodable data is shown with an unknown size rather than dropped
+		a.data, _ = base64.StdEncoding.DecodeString(source.Data)
+	}
+	return a
+}
+
+// attachmentOfBlock returns the attachment of a content block decoded as a map, or nil
+func attachmentOfBlock(block map[string]interface{}) *Attachment {
+	kind, _ := block["type"].(string)
+	if !isAttachment(kind) {
+		return nil
+	}
+	var source *AttachmentSource
+	if m, ok := block["source"].(map[string]interface{}); ok {
+		source = &AttachmentSource{}
+		source.Type, _ = m["type"].(string)
+		source.MediaType, _ = m["media_type"].(string)
+		source.Data, _ = m["data"].(string)
+		source.URL, _ = m["url"].(string)
+	}
+	return newAttachment(kind, source)
+}
+
+// attachmentsOf returns the attachments in a list of content blocks, such as the
+// content of a tool result
+func attachmentsOf(content interface{}) []*Attachment {
+	blocks, ok := content.([]interface{})
+	if !ok {
+		return nil
+	}
+	var attachments []*Attachment
+	for _, item := range blocks {
+		if block, ok := item.(map[string]interface{}); ok {
+			if a := attachmentOfBlock(block); a != nil {
+				attachments = append(attachments, a)
+			}
+		}
+	}
+	return attachments
+}
+
+// Size returns the size of the attachment in bytes, or 0 if it is not embedded
+func (a *Attachment) Size() int {
+	return len(a.data)
+}
+
+// Format returns the subtype of the media type, e.g. png for image/png
+func (a *Attachment) Format() string {
+	_, format, ok := strings.Cut(a.MediaType, "/")
+	if !ok {
+		return ""
+	}
+	return format
+}
+
+// String describes the attachment, e.g. [image: 1.2MB png]
+func (a *Attachment) String() string {
+	var parts []string
+	if a.URL != "" {
+		parts = append(parts, a.URL)
+	}
+	if len(a.data) > 0 {
+		parts = append(parts, FormatBytes(int64(len(a.data))))
+	}
+	if format := a.Format(); format != "" {
+		parts = append(parts, format)
+	}
+	if len(parts) == 0 {
+		return "[" + a.Kind + "]"
+	}
+	return fmt.Sprintf("[%s: %s]", a.Kind, strings.Join(parts, " "))
+}
+
+// extension returns the file extension the attachment is saved with
+func (a *Attachment) extension() string {
+	switch format := a.Format(); format {
+	case "jpeg":
+		return ".jpg"
+	case "plain":
+		return ".txt"
+	case "":
+		return ".bin"
+	default:
+		return "." + format
+	}
+}
+
+// Save writes an embedded attachment to dir, named after the hash of its content so
+// the same attachment is saved once, and returns its path
+func (a *Attachment) Save(dir string) (string, error) {
+	if len(a.data) == 0 {
+		return "", errors.New("attachment is not embedded")
+	}
+	sum := sha256.Sum256(a.data)
+	path := filepath.Join(dir, hex.EncodeToString(sum[:8])+a.extension())
+	if _, err := os.Stat(path); err == nil {
+		return path, nil
+	}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return "", fmt.Errorf("failed to create attachment directory: %w", err)
+	}
+	if err := os.WriteFile(path, a.data, 0o600); err != nil {
+		return "", fmt.Errorf("failed to save attachment: %w", err)
+	}
+	return path, nil
+}
+
+// FormatBytes formats a size in bytes for humans, e.g. 1.2MB
+func FormatBytes(n int64) string {
+	switch {
+	case n >= 1<<30:
+		return fmt.Sprintf("%.1fGB", float64(n)/(1<<30))
+	case n >= 1<<20:
+		return fmt.Sprintf("%.1fMB", float64(n)/(1<<20))
+	case n >= 1<<10:
+		return fmt.Sprintf("%.1fKB", float64(n)/(1<<10))
+	default:
+		return fmt.Sprintf("%dB", n)
+	}
+}
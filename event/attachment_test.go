@@ -0,0 +1,125 @@
+package event
+
+import (
+	"bytes"
+	"encoding/base64"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestAttachment_String(t *testing.T) {
+	tests := []struct {
+		name   string
+		kind   string
+		source *AttachmentSource
+		want   string
+	}{
+		{
+			name:   "embedded image",
+			kind:   "image",
+			source: &AttachmentSource{Type: "base64", MediaType: "image/png", Data: base64.StdEncoding.EncodeToString(make([]byte, 1258291))},
+			want:   "[image: 1.2MB png]",
+		},
+		{
+			name:   "small document",
+			kind:   "document",
+			source: &AttachmentSource{Type: "base64", MediaType: "application/pdf", Data: base64.StdEncoding.EncodeToString(make([]byte, 300))},
+			want:   "[document: 300B pdf]",
+		},
+		{
+			name:   "image by URL",
+			kind:   "image",
+			source: &AttachmentSource{Type: "url", URL: "https://example.com/a.jpg"},
+			want:   "[image: https://example.com/a.jpg]",
+		},
+		{
+			name: "no source",
+			kind: "image",
+			want: "[image]",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := newAttachment(tt.kind, tt.source).String(); got != tt.want {
+				t.Errorf("String() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAttachment_Save(t *testing.T) {
+	dir := t.TempDir()
+	data := []byte("\x89PNG fake image")
+	a := newAttachment("image", &AttachmentSource{Type: "base64", MediaType: "image/jpeg", Data: base64.StdEncoding.EncodeToString(data)})
+	path, err := a.Save(dir)
+	if err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+	if filepath.Dir(path) != dir || filepath.Ext(path) != ".jpg" {
+		t.Errorf("Save() path = %s", path)
+	}
+	if got, err := os.ReadFile(path); err != nil || !bytes.Equal(got, data) {
+		t.Errorf("saved content = %q, %v", got, err)
+	}
+	// The same attachment is saved once
+	if again, err := a.Save(dir); err != nil || again != path {
+		t.Errorf("Save() again = %s, %v, want %s", again, err, path)
+	}
+
+	if _, err := newAttachment("image", &AttachmentSource{Type: "url", URL: "https://example.com/a.png"}).Save(dir); err == nil {
+		t.Error("Save() of an attachment by URL succeeded")
+	}
+}
+
+func TestFormatterAttachments(t *testing.T) {
+	dir := t.TempDir()
+	formatter := NewFormatter(&mockNarrator{})
+	formatter.SetAttachmentDir(dir)
+	png := base64.StdEncoding.EncodeToString([]byte("png data"))
+	image := map[string]interface{}{
+		"type":   "image",
+		"source": map[string]interface{}{"type": "base64", "media_type": "image/png", "data": png},
+	}
+	session := &Session{Project: "app", Session: "s1"}
+
+	user := &UserMessage{
+		BaseEvent: BaseEvent{Session: session},
+		Message: UserMessageContent{Role: "user", Content: []interface{}{
+			map[string]interface{}{"type": "text", "text": "what is this?"},
+			image,
+			map[string]interface{}{"type": "tool_result", "tool_use_id": "toolu_1", "content": []interface{}{image}},
+		}},
+	}
+	output, err := formatter.Format(user)
+	if err != nil {
+		t.Fatalf("Format() error = %v", err)
+	}
+	if n := strings.Count(output, "🖼️ [image: 8B png] → "+filepath.Join(dir, "app", "s1")); n != 2 {
+		t.Errorf("Format() shows %d saved images, want 2:\n%s", n, output)
+	}
+	if strings.Contains(output, png) {
+		t.Errorf("Format() shows the image data:\n%s", output)
+	}
+	if files, _ := filepath.Glob(filepath.Join(dir, "app", "s1", "*.png")); len(files) != 1 {
+		t.Errorf("saved files = %v, want 1", files)
+	}
+
+	assistant := &AssistantMessage{
+		BaseEvent: BaseEvent{Session: session},
+		Message: AssistantMessageContent{Content: []AssistantContent{
+			{Type: "document", Source: &AttachmentSource{Type: "url", URL: "https://example.com/spec.pdf", MediaType: "application/pdf"}},
+		}},
+	}
+	output, err = formatter.Format(assistant)
+	if err != nil {
+		t.Fatalf("Format() error = %v", err)
+	}
+	if !strings.Contains(output, "📎 [document: https://example.com/spec.pdf pdf]\n") {
+		t.Errorf("Format() = %q, want the document", output)
+	}
+	if got := formatter.Narrations(); len(got) != 0 {
+		t.Errorf("Narrations() = %v, want attachments not narrated", got)
+	}
+}
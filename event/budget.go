@@ -0,0 +1,219 @@
+package event
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Budget thresholds, as fractions of a budget, that raise an alert
+var budgetThresholds = []float64{0.8, 1.0}
+
+// Budget scopes
+const (
+	BudgetSession = "session"
+	BudgetDay     = "day"
+)
+
+// Budget metrics
+const (
+	BudgetTokens = "tokens"
+	BudgetCost   = "cost"
+)
+
+// Budget is the token and cost budget of each session and of each day; zero fields
+// have no budget. Tokens include cache reads and writes, and costs are estimated in USD.
+type Budget struct {
+	SessionTokens int64
+	SessionCost   float64
+	DayTokens     int64
+	DayCost       float64
+}
+
+// Enabled reports whether any budget is set
+func (b Budget) Enabled() bool {
+	return b.SessionTokens > 0 || b.SessionCost > 0 || b.DayTokens > 0 || b.DayCost > 0
+}
+
+// ParseTokenCount parses a token count such as 2000000, 500k or 1.5M
+func ParseTokenCount(value string) (int64, error) {
+	s := strings.TrimSpace(value)
+	unit := 1.0
+	switch {
+	case strings.HasSuffix(s, "k"), strings.HasSuffix(s, "K"):
+		unit = 1e3
+	case strings.HasSuffix(s, "m"), strings.HasSuffix(s, "M"):
+		unit = 1e6
+	}
+	if unit > 1 {
+		s = s[:len(s)-1]
+	}
+	n, err := strconv.ParseFloat(s, 64)
+	if err != nil || n < 0 {
+		return 0, fmt.Errorf("invalid token count %q (expected a number such as 2000000, 500k or 2M)", value)
+	}
+	return int64(n * unit), nil
+}
+
+// CostFunc estimates the cost in USD of the token usage of a model
+type CostFunc func(model string, usage Usage) float64
+
+// SessionUsageFunc adds the usage of an assistant message to the usage of its session
+// and returns the tokens and estimated cost in USD the session used so far, including
+// the usage recorded before the companion started. Messages are counted once.
+type SessionUsageFunc func(msg *AssistantMessage) (tokens int64, cost float64)
+
+// budgetUsage is the usage counted against a budget
+type budgetUsage struct {
+	tokens  int64
+	cost    float64
+	seen    map[string]bool // Messages counted, by message and request ID; days only
+	alerted map[string]bool // Thresholds reported, by metric and threshold
+}
+
+// BudgetTracker reports when the token usage of a session or of a local day crosses
+// 80% and 100% of a budget. Each threshold is reported once per session, or once per
+// day. The usage of sessions comes from a SessionUsageFunc; the usage of days is
+// added up from assistant messages by the day they were written.
+type BudgetTracker struct {
+	budget       Budget
+	sessionUsage SessionUsageFunc // nil counts no session usage
+	cost         CostFunc         // nil counts no cost
+
+	mu       sync.Mutex
+	sessions map[string]*budgetUsage // key: project/session
+	day      string                  // Latest day with usage, as YYYY-MM-DD
+	days     map[string]*budgetUsage // key: YYYY-MM-DD
+}
+
+// NewBudgetTracker creates a tracker of budget, taking the usage of sessions from
+// sessions and estimating the costs of days with cost
+func NewBudgetTracker(budget Budget, sessions SessionUsageFunc, cost CostFunc) *BudgetTracker {
+	return &BudgetTracker{
+		budget:       budget,
+		sessionUsage: sessions,
+		cost:         cost,
+		sessions:     make(map[string]*budgetUsage),
+		days:         make(map[string]*budgetUsage),
+	}
+}
+
+// Observe adds the usage of an assistant message and returns an alert for each
+// threshold it crossed. Messages written again with the same message and request
+// ID are counted once.
+func (t *BudgetTracker) Observe(msg *AssistantMessage) []*BudgetAlertMessage {
+	if msg.Session == nil || msg.Message.Usage == (Usage{}) {
+		return nil
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	var alerts []*BudgetAlertMessage
+	if t.sessionUsage != nil && (t.budget.SessionTokens > 0 || t.budget.SessionCost > 0) {
+		key := msg.Session.Project + "/" + msg.Session.Session
+		session, ok := t.sessions[key]
+		if !ok {
+			session = &budgetUsage{}
+			t.sessions[key] = session
+		}
+		session.tokens, session.cost = t.sessionUsage(msg)
+		alerts = append(alerts, session.check(msg, BudgetSession, t.budget.SessionTokens, t.budget.SessionCost)...)
+	}
+	if t.budget.DayTokens > 0 || t.budget.DayCost > 0 {
+		alerts = append(alerts, t.observeDay(msg)...)
+	}
+	return alerts
+}
+
+// observeDay adds the usage of a message to the day it was written. Messages of
+// earlier days, such as those of other sessions caught up on, are added to their
+// own day, and only the latest day raises alerts.
+func (t *BudgetTracker) observeDay(msg *AssistantMessage) []*BudgetAlertMessage {
+	timestamp := msg.Timestamp
+	if timestamp.IsZero() {
+		timestamp = time.Now()
+	}
+	day := timestamp.Local().Format("2006-01-02")
+	if day > t.day {
+		t.day = day
+		// Keep the previous day for messages written just before midnight
+		previous := timestamp.Local().AddDate(0, 0, -1).Format("2006-01-02")
+		for d := range t.days {
+			if d < previous {
+				delete(t.days, d)
+			}
+		}
+	}
+	today, ok := t.days[day]
+	if !ok {
+		today = &budgetUsage{seen: make(map[string]bool)}
+		t.days[day] = today
+	}
+	if msg.Message.ID != "" {
+		key := msg.Message.ID + ":" + msg.RequestID
+		if today.seen[key] {
+			return nil
+		}
+		today.seen[key] = true
+	}
+
+	u := msg.Message.Usage
+	today.tokens += int64(u.InputTokens + u.OutputTokens + u.CacheReadInputTokens + u.CacheCreationInputTokens)
+	if t.cost != nil {
+		today.cost += t.cost(msg.Message.Model, u)
+	}
+	if day != t.day {
+		return nil
+	}
+	return today.check(msg, BudgetDay, t.budget.DayTokens, t.budget.DayCost)
+}
+
+// check returns an alert for each of the token and cost limits of a scope whose
+// threshold the usage crossed for the first time
+func (u *budgetUsage) check(msg *AssistantMessage, scope string, tokenLimit int64, costLimit float64) []*BudgetAlertMessage {
+	var alerts []*BudgetAlertMessage
+	if alert := u.checkMetric(msg, scope, BudgetTokens, float64(u.tokens), float64(tokenLimit)); alert != nil {
+		alerts = append(alerts, alert)
+	}
+	if alert := u.checkMetric(msg, scope, BudgetCost, u.cost, costLimit); alert != nil {
+		alerts = append(alerts, alert)
+	}
+	return alerts
+}
+
+// checkMetric returns an alert for the highest threshold of a metric that used reached
+// and that was not reported yet
+func (u *budgetUsage) checkMetric(msg *AssistantMessage, scope, metric string, used, limit float64) *BudgetAlertMessage {
+	if limit <= 0 {
+		return nil
+	}
+	var crossed float64
+	for _, threshold := range budgetThresholds {
+		key := fmt.Sprintf("%s:%g", metric, threshold)
+		if used >= limit*threshold && !u.alerted[key] {
+			if u.alerted == nil {
+				u.alerted = make(map[string]bool)
+			}
+			u.alerted[key] = true
+			crossed = threshold
+		}
+	}
+	if crossed == 0 {
+		return nil
+	}
+
+	alert := &BudgetAlertMessage{
+		BaseEvent: msg.BaseEvent,
+		Scope:     scope,
+		Metric:    metric,
+		Used:      used,
+		Limit:     limit,
+		Threshold: crossed,
+	}
+	alert.TypeString = "budget_alert"
+	alert.UUID = ""
+	return alert
+}
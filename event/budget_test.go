@@ -0,0 +1,160 @@
+package event
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestParseTokenCount(t *testing.T) {
+	tests := []struct {
+		value   string
+		want    int64
+		wantErr bool
+	}{
+		{value: "2000000", want: 2000000},
+		{value: "500k", want: 500000},
+		{value: "2M", want: 2000000},
+		{value: "1.5m", want: 1500000},
+		{value: "M", wantErr: true},
+		{value: "-1k", wantErr: true},
+		{value: "2G", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			got, err := ParseTokenCount(tt.value)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseTokenCount(%q) error = %v, wantErr %v", tt.value, err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("ParseTokenCount(%q) = %d, want %d", tt.value, got, tt.want)
+			}
+		})
+	}
+}
+
+// budgetAlerts summarizes alerts as scope/metric@threshold
+func budgetAlerts(alerts []*BudgetAlertMessage) []string {
+	var got []string
+	for _, a := range alerts {
+		got = append(got, fmt.Sprintf("%s/%s@%g", a.Scope, a.Metric, a.Threshold))
+	}
+	return got
+}
+
+// sessionUsage adds up the input tokens of each session, counting messages once and
+// starting each session with the tokens it used before
+func sessionUsage(before map[string]int64) SessionUsageFunc {
+	seen := make(map[string]bool)
+	used := make(map[string]int64)
+	for session, tokens := range before {
+		used[session] = tokens
+	}
+	return func(msg *AssistantMessage) (int64, float64) {
+		if !seen[msg.Message.ID] {
+			seen[msg.Message.ID] = true
+			used[msg.Session.Session] += int64(msg.Message.Usage.InputTokens)
+		}
+		return used[msg.Session.Session], 0
+	}
+}
+
+func TestBudgetTracker(t *testing.T) {
+	day := time.Date(2025, 1, 1, 10, 0, 0, 0, time.Local)
+	cost := func(model string, u Usage) float64 { return float64(u.InputTokens) / 1000 } // $1 per thousand tokens
+	tracker := NewBudgetTracker(Budget{SessionTokens: 1000, DayCost: 2}, sessionUsage(nil), cost)
+
+	steps := []struct {
+		name    string
+		session string
+		id      string
+		tokens  int
+		at      time.Time
+		want    []string
+	}{
+		{name: "under 80%", session: "s1", id: "m1", tokens: 700, at: day, want: nil},
+		{name: "session at 80%", session: "s1", id: "m2", tokens: 100, at: day, want: []string{"session/tokens@0.8"}},
+		{name: "counted once", session: "s1", id: "m2", tokens: 100, at: day, want: nil},
+		{name: "session at 100% and day at 80%", session: "s1", id: "m3", tokens: 900, at: day, want: []string{"session/tokens@1", "day/cost@0.8"}},
+		{name: "other session", session: "s2", id: "m4", tokens: 300, at: day, want: []string{"day/cost@1"}},
+		{name: "reported once", session: "s1", id: "m5", tokens: 500, at: day, want: nil},
+		{name: "next day", session: "s2", id: "m6", tokens: 1700, at: day.AddDate(0, 0, 1), want: []string{"session/tokens@1", "day/cost@0.8"}},
+	}
+	for _, step := range steps {
+		msg := assistantMessage(step.session, step.at)
+		msg.Message.ID, msg.Message.Usage.InputTokens = step.id, step.tokens
+		if diff := cmp.Diff(step.want, budgetAlerts(tracker.Observe(msg))); diff != "" {
+			t.Errorf("%s: alerts mismatch (-want +got):\n%s", step.name, diff)
+		}
+	}
+}
+
+func TestBudgetTracker_SessionUsage(t *testing.T) {
+	// Usage from before startup counts against the session budget
+	tracker := NewBudgetTracker(Budget{SessionTokens: 1000}, sessionUsage(map[string]int64{"s1": 700}), nil)
+	msg := assistantMessage("s1", time.Date(2025, 1, 1, 10, 0, 0, 0, time.Local))
+	msg.Message.ID, msg.Message.Usage.InputTokens = "m1", 100
+	if diff := cmp.Diff([]string{"session/tokens@0.8"}, budgetAlerts(tracker.Observe(msg))); diff != "" {
+		t.Errorf("alerts mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestBudgetTracker_DaysOutOfOrder(t *testing.T) {
+	today := time.Date(2025, 1, 2, 10, 0, 0, 0, time.Local)
+	yesterday := today.AddDate(0, 0, -1)
+	tracker := NewBudgetTracker(Budget{DayTokens: 1000}, nil, nil)
+
+	steps := []struct {
+		name    string
+		session string
+		id      string
+		tokens  int
+		at      time.Time
+		want    []string
+	}{
+		{name: "today at 80%", session: "s1", id: "m1", tokens: 800, at: today, want: []string{"day/tokens@0.8"}},
+		{name: "yesterday, caught up", session: "s2", id: "m2", tokens: 900, at: yesterday, want: nil},
+		{name: "today again", session: "s1", id: "m3", tokens: 100, at: today, want: nil},
+		{name: "today at 100%", session: "s1", id: "m4", tokens: 100, at: today, want: []string{"day/tokens@1"}},
+		{name: "yesterday over its budget", session: "s2", id: "m5", tokens: 200, at: yesterday, want: nil},
+		{name: "next day", session: "s1", id: "m6", tokens: 800, at: today.AddDate(0, 0, 1), want: []string{"day/tokens@0.8"}},
+	}
+	for _, step := range steps {
+		msg := assistantMessage(step.session, step.at)
+		msg.Message.ID, msg.Message.Usage.InputTokens = step.id, step.tokens
+		if diff := cmp.Diff(step.want, budgetAlerts(tracker.Observe(msg))); diff != "" {
+			t.Errorf("%s: alerts mismatch (-want +got):\n%s", step.name, diff)
+		}
+	}
+}
+
+func TestHandler_BudgetAlert(t *testing.T) {
+	handler := NewHandler(&mockNarrator{}, false)
+	handler.SetBudget(Budget{SessionTokens: 1000}, sessionUsage(nil), nil)
+	sink := &recordingSink{}
+	handler.AddSink(sink)
+
+	msg := assistantMessage("s1", time.Now())
+	msg.Message.ID, msg.Message.Usage.InputTokens = "m1", 1200
+	captureOutput(t, func() {
+		handler.processEvent(msg)
+	})
+
+	var alert *BudgetAlertMessage
+	for _, ev := range sink.events {
+		if a, ok := ev.(*BudgetAlertMessage); ok {
+			alert = a
+		}
+	}
+	if alert == nil {
+		t.Fatalf("no budget alert in %d events", len(sink.events))
+	}
+	if alert.Scope != BudgetSession || alert.Metric != BudgetTokens || alert.Used != 1200 || alert.Threshold != 1 {
+		t.Errorf("alert = %+v, want session tokens at 100%%", alert)
+	}
+	if _, ok := sink.events[0].(*AssistantMessage); !ok {
+		t.Errorf("first event = %T, want the assistant message before its alert", sink.events[0])
+	}
+}
@@ -0,0 +1,46 @@
+package event
+
+import "time"
+
+// catchUp accumulates the events a transcript got while the companion was not
+// running, to report them as one CatchUpMessage instead of replaying them
+type catchUp struct {
+	end      int64 // Size of the transcript when tailing resumed
+	events   int
+	activity sessionActivity
+	now      func() time.Time
+}
+
+// newCatchUp creates a catch-up of the events up to offset end
+func newCatchUp(end int64) *catchUp {
+	return &catchUp{end: end, activity: sessionActivity{seen: make(map[string]bool)}, now: time.Now}
+}
+
+// record adds an event read while catching up
+func (c *catchUp) record(event Event) {
+	c.events++
+	c.activity.record(event)
+}
+
+// message returns the summary of the events caught up on, or nil if there were none
+func (c *catchUp) message() *CatchUpMessage {
+	if c.events == 0 {
+		return nil
+	}
+	a := &c.activity
+	msg := &CatchUpMessage{
+		BaseEvent:      a.last,
+		Events:         c.events,
+		FilesEdited:    a.files,
+		Commands:       a.commands,
+		Tokens:         a.tokens,
+		TasksCompleted: a.tasks,
+	}
+	msg.TypeString = "catch_up"
+	msg.UUID = ""
+	msg.Timestamp = c.now()
+	if !a.start.IsZero() {
+		msg.Duration = a.end.Sub(a.start)
+	}
+	return msg
+}
@@ -0,0 +1,291 @@
+package event
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/mattn/go-runewidth"
+)
+
+// Console roles colored besides the headers of each event kind
+const (
+	ColorError = "error" // Lines reporting an error
+	ColorCode  = "code"  // Code blocks
+	ColorDim   = "dim"   // Details, token usage and truncation notes
+)
+
+// Palette maps console roles to color names: event kinds color the header of their
+// events, and ColorError, ColorCode and ColorDim the lines of those roles
+type Palette map[string]string
+
+// DefaultPalette is the palette of the console unless the theme changes it
+var DefaultPalette = Palette{
+	KindUser:           "cyan",
+	KindAssistant:      "green",
+	KindSystem:         "yellow",
+	KindHook:           "gray",
+	KindSummary:        "magenta",
+	KindNotification:   "yellow",
+	KindTaskCompletion: "green",
+	KindToolSLABreach:  "red",
+	KindSessionSummary: "magenta",
+	KindCatchUp:        "gray",
+	KindBudgetAlert:    "red",
+	KindContextAlert:   "yellow",
+	KindSecurityAlert:  "red",
+	ColorError:         "red",
+	ColorCode:          "blue",
+	ColorDim:           "gray",
+}
+
+// colorNone turns off the color of a role in a theme
+const colorNone = "none"
+
+// isColorRole reports whether a palette may have a color for role
+func isColorRole(role string) bool {
+	if role == ColorError || role == ColorCode || role == ColorDim {
+		return true
+	}
+	for _, kind := range EventKinds {
+		if kind == role {
+			return true
+		}
+	}
+	return false
+}
+
+// parsePalette validates the colors of a theme file
+func parsePalette(colors map[string]string) (Palette, error) {
+	palette := make(Palette, len(colors))
+	for role, name := range colors {
+		if !isColorRole(role) {
+			return nil, fmt.Errorf("invalid theme color role %q (must be an event kind, %s, %s or %s)", role, ColorError, ColorCode, ColorDim)
+		}
+		if _, ok := themeColors[name]; !ok && name != colorNone {
+			return nil, fmt.Errorf("invalid theme color %q for %s", name, role)
+		}
+		palette[role] = name
+	}
+	return palette, nil
+}
+
+// Palette returns base with the colors of the theme applied
+func (t *Theme) Palette(base Palette) Palette {
+	palette := make(Palette, len(base))
+	for role, name := range base {
+		palette[role] = name
+	}
+	if t == nil {
+		return palette
+	}
+	for role, name := range t.colors {
+		if name == colorNone {
+			delete(palette, role)
+		} else {
+			palette[role] = name
+		}
+	}
+	return palette
+}
+
+// SetColors colors the console output with palette; nil prints it without colors,
+// removing those of theme templates and hook output
+func (f *Formatter) SetColors(palette Palette) {
+	f.palette = palette
+}
+
+// SetWrap wraps lines wider than the terminal, and truncates those of code blocks
+func (f *Formatter) SetWrap(enabled bool) {
+	f.wrap = enabled
+}
+
+// finish applies the console settings to the formatted output of an event. indent is
+// the width of what the caller prefixes each line with. The output without colors
+// and wrapping is kept for the sinks.
+func (f *Formatter) finish(kind, output string, indent int) string {
+	if f.accessible {
+		output = accessibleText(output)
+	}
+	f.plain = stripANSI(output)
+	if f.palette == nil {
+		output = f.plain
+	} else {
+		output = paint(output, kind, f.palette)
+	}
+	if f.wrap && f.width != nil {
+		if width := f.width() - indent; width > minDetailWidth {
+			output = fitLines(output, width)
+		}
+	}
+	return output
+}
+
+// isFence reports whether a line opens or closes a code block
+func isFence(line string) bool {
+	return strings.HasPrefix(strings.TrimSpace(stripANSI(line)), "```")
+}
+
+// paint colors the header of an event with the color of its kind, and its error,
+// code and dim lines with theirs
+func paint(output, kind string, palette Palette) string {
+	var b strings.Builder
+	inCode := false
+	for i, line := range strings.SplitAfter(output, "\n") {
+		text := strings.TrimSuffix(line, "\n")
+		plain := strings.TrimSpace(stripANSI(text))
+		role := ""
+		switch {
+		case isFence(text):
+			inCode = !inCode
+			role = ColorCode
+		case inCode:
+			role = ColorCode
+		case i == 0:
+			role = kind
+		case strings.Contains(plain, "❌") || strings.Contains(plain, "[ERROR]"):
+			role = ColorError
+		case strings.HasPrefix(plain, "... (") || strings.HasPrefix(plain, "↳ ") || strings.HasPrefix(plain, "detail: "),
+			strings.HasPrefix(plain, "💰") || strings.HasPrefix(plain, "[TOKENS]"):
+			role = ColorDim
+		}
+		code, ok := themeColors[palette[role]]
+		if !ok || plain == "" {
+			b.WriteString(line)
+			continue
+		}
+		b.WriteString("\x1b[" + code + "m" + text + "\x1b[0m" + line[len(text):])
+	}
+	return b.String()
+}
+
+// consoleToken is an escape sequence or a character of a console line
+type consoleToken struct {
+	text  string
+	width int
+}
+
+// consoleTokens splits a line into characters and zero-width ANSI escape sequences
+func consoleTokens(line string) []consoleToken {
+	var tokens []consoleToken
+	for i := 0; i < len(line); {
+		if loc := ansiPattern.FindStringIndex(line[i:]); loc != nil && loc[0] == 0 {
+			tokens = append(tokens, consoleToken{text: line[i : i+loc[1]]})
+			i += loc[1]
+			continue
+		}
+		r, size := utf8.DecodeRuneInString(line[i:])
+		tokens = append(tokens, consoleToken{text: line[i : i+size], width: runewidth.RuneWidth(r)})
+		i += size
+	}
+	return tokens
+}
+
+// fitLines wraps the lines of output wider than width at spaces, indenting the
+// continuation lines. Lines of code blocks are truncated to keep their layout.
+func fitLines(output string, width int) string {
+	var b strings.Builder
+	inCode := false
+	for _, line := range strings.SplitAfter(output, "\n") {
+		text := strings.TrimSuffix(line, "\n")
+		fence := isFence(text)
+		if fence {
+			inCode = !inCode
+		}
+		switch {
+		case runewidth.StringWidth(stripANSI(text)) <= width:
+			b.WriteString(line)
+		case inCode && !fence:
+			b.WriteString(truncateLine(text, width) + line[len(text):])
+		default:
+			b.WriteString(wrapLine(text, width) + line[len(text):])
+		}
+	}
+	return b.String()
+}
+
+// truncateLine cuts a line to width, marking the cut with an ellipsis
+func truncateLine(line string, width int) string {
+	var b strings.Builder
+	used := 0
+	colored := false
+	for _, t := range consoleTokens(line) {
+		if t.width == 0 {
+			b.WriteString(t.text)
+			colored = colored || strings.HasPrefix(t.text, "\x1b")
+			continue
+		}
+		if used+t.width > width-1 {
+			break
+		}
+		b.WriteString(t.text)
+		used += t.width
+	}
+	b.WriteString("…")
+	if colored {
+		b.WriteString("\x1b[0m")
+	}
+	return b.String()
+}
+
+// wrapLine breaks a line into lines no wider than width, after the last space or
+// before the last wide character that fits, or within a word longer than the line.
+// Continuation lines are indented two more columns than the line.
+func wrapLine(line string, width int) string {
+	plain := stripANSI(line)
+	margin := len(plain) - len(strings.TrimLeft(plain, " "))
+	indent := strings.Repeat(" ", min(margin+2, width/2))
+
+	var lines []string
+	var cur []consoleToken
+	used := 0
+	start := 0       // Tokens of cur that are the indent of a continuation line
+	brk := -1        // Index in cur where the next line can start
+	hasText := false // cur has text after its indent, so it can be broken
+	for _, t := range consoleTokens(line) {
+		if hasText && t.width > 1 {
+			brk = len(cur)
+		}
+		if t.width > 0 && used+t.width > width {
+			var rest []consoleToken
+			if brk > start {
+				rest = append(rest, cur[brk:]...)
+				cur = cur[:brk]
+			}
+			lines = append(lines, joinTokens(cur))
+			cur, brk, hasText = nil, -1, false
+			for _, r := range indent {
+				cur = append(cur, consoleToken{text: string(r), width: 1})
+			}
+			start, used = len(cur), len(indent)
+			for _, r := range rest {
+				cur = append(cur, r)
+				used += r.width
+				hasText = hasText || (r.width > 0 && r.text != " ")
+			}
+			if t.text == " " && !hasText {
+				continue
+			}
+		}
+		cur = append(cur, t)
+		used += t.width
+		if t.text == " " {
+			if hasText {
+				brk = len(cur)
+			}
+		} else if t.width > 0 {
+			hasText = true
+		}
+	}
+	lines = append(lines, joinTokens(cur))
+	return strings.Join(lines, "\n")
+}
+
+// joinTokens returns the text of tokens without trailing spaces
+func joinTokens(tokens []consoleToken) string {
+	var b strings.Builder
+	for _, t := range tokens {
+		b.WriteString(t.text)
+	}
+	return strings.TrimRight(b.String(), " ")
+}
@@ -0,0 +1,189 @@
+package event
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/kazegusuri/claude-companion/narrator"
+	"github.com/mattn/go-runewidth"
+)
+
+func TestPaint(t *testing.T) {
+	palette := Palette{KindUser: "cyan", ColorError: "red", ColorCode: "blue", ColorDim: "gray"}
+	output := "[15:04:05] 👤 USER:\n  ❌ Tool Result: toolu_1\n    ```\n    x := 1\n    ```\n  ... (3 more lines)\n  plain\n\n"
+	want := "\x1b[36m[15:04:05] 👤 USER:\x1b[0m\n" +
+		"\x1b[31m  ❌ Tool Result: toolu_1\x1b[0m\n" +
+		"\x1b[34m    ```\x1b[0m\n" +
+		"\x1b[34m    x := 1\x1b[0m\n" +
+		"\x1b[34m    ```\x1b[0m\n" +
+		"\x1b[90m  ... (3 more lines)\x1b[0m\n" +
+		"  plain\n\n"
+	if got := paint(output, KindUser, palette); got != want {
+		t.Errorf("paint() = %q, want %q", got, want)
+	}
+}
+
+func TestFitLines(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		width int
+		want  string
+	}{
+		{
+			name:  "short lines",
+			input: "  💬 short\n",
+			width: 20,
+			want:  "  💬 short\n",
+		},
+		{
+			name:  "wrapped at spaces",
+			input: "  🖥️  Running command: go test ./... -run TestSomething\n",
+			width: 24,
+			want:  "  🖥️  Running command:\n    go test ./... -run\n    TestSomething\n",
+		},
+		{
+			name:  "long word",
+			input: "  /very/long/path/without/spaces\n",
+			width: 20,
+			want:  "  /very/long/path/wi\n    thout/spaces\n",
+		},
+		{
+			name:  "wide characters",
+			input: "  💬 日本語のとても長いナレーションです\n",
+			width: 20,
+			want:  "  💬 日本語のとても\n    長いナレーション\n    です\n",
+		},
+		{
+			name:  "code truncated",
+			input: "    ```\n    fmt.Println(\"a long line of code\")\n    ```\n",
+			width: 20,
+			want:  "    ```\n    fmt.Println(\"a …\n    ```\n",
+		},
+		{
+			name:  "colors kept",
+			input: "\x1b[32mgreen words that wrap\x1b[0m\n",
+			width: 12,
+			want:  "\x1b[32mgreen words\n  that wrap\x1b[0m\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := fitLines(tt.input, tt.width)
+			if got != tt.want {
+				t.Errorf("fitLines() = %q, want %q", got, tt.want)
+			}
+			for _, line := range strings.Split(got, "\n") {
+				if w := runewidth.StringWidth(stripANSI(line)); w > tt.width {
+					t.Errorf("line %q is %d columns wide, want at most %d", line, w, tt.width)
+				}
+			}
+		})
+	}
+}
+
+func TestFormatterColorsAndWrap(t *testing.T) {
+	msg := &AssistantMessage{BaseEvent: BaseEvent{TypeString: "assistant", Timestamp: time.Date(2025, 1, 1, 15, 4, 5, 0, time.UTC)}}
+	msg.Message.Model = "claude-sonnet-4"
+	msg.Message.Content = []AssistantContent{{Type: "tool_use", ID: "toolu_1", Name: "Bash", Input: map[string]interface{}{"command": "go test ./... -run 'TestFormatterColorsAndWrap' -count 1 -v"}}}
+
+	f := NewFormatter(narrator.NewNoOpNarrator())
+	got, _ := f.Format(msg)
+	if strings.Contains(got, "\x1b[") || !strings.Contains(got, "-count 1 -v (id: toolu_1)") {
+		t.Errorf("Format() without colors or wrapping = %q", got)
+	}
+
+	f.SetLayout(LayoutDefault, func() int { return 50 })
+	f.SetColors(DefaultPalette)
+	f.SetWrap(true)
+	got, _ = f.Format(msg)
+	if !strings.HasPrefix(got, "\x1b[32m[15:04:05] 🤖 ASSISTANT (claude-sonnet-4):\x1b[0m\n") {
+		t.Errorf("Format() = %q, want a green header", got)
+	}
+	for _, line := range strings.Split(got, "\n") {
+		if w := runewidth.StringWidth(stripANSI(line)); w > 50 {
+			t.Errorf("Format() line %q is %d columns wide, want at most 50", line, w)
+		}
+	}
+
+	// Subagent lines are prefixed, so they are wrapped narrower
+	f.SetColors(nil)
+	got, _ = f.FormatSidechain(msg, "Explore")
+	for _, line := range strings.Split(got, "\n") {
+		if w := runewidth.StringWidth(line); w > 50 {
+			t.Errorf("FormatSidechain() line %q is %d columns wide, want at most 50", line, w)
+		}
+	}
+}
+
+func TestHandler_SinksGetPlainText(t *testing.T) {
+	handler := NewHandler(&mockNarrator{}, false)
+	handler.SetLayout(LayoutDefault, func() int { return 50 })
+	handler.SetColors(DefaultPalette)
+	handler.SetWrap(true)
+	sink := &recordingSink{}
+	handler.AddSink(sink)
+
+	const text = "The tests pass now, and the console wraps this sentence to fit its width."
+	msg := assistantMessage("s1", time.Date(2025, 1, 1, 15, 4, 5, 0, time.UTC), AssistantContent{Type: "text", Text: text})
+	output := captureOutput(t, func() {
+		handler.processEvent(msg)
+	})
+
+	if !strings.Contains(output, "\x1b[") || strings.Contains(output, text) {
+		t.Errorf("console output %q is not colored and wrapped", output)
+	}
+	if len(sink.formatted) != 1 {
+		t.Fatalf("sink received %d events, want 1", len(sink.formatted))
+	}
+	got := sink.formatted[0]
+	if strings.Contains(got, "\x1b[") {
+		t.Errorf("sink text %q has color codes", got)
+	}
+	if !strings.HasPrefix(got, "[15:04:05] 🤖 ASSISTANT (claude-sonnet-4):\n") || !strings.Contains(got, text) {
+		t.Errorf("sink text = %q, want the header and the unwrapped text", got)
+	}
+
+	// Subagent output keeps its prefix
+	f := handler.formatter.(*Formatter)
+	if _, err := f.FormatSidechain(msg, "Explore"); err != nil {
+		t.Fatal(err)
+	}
+	if got := f.Plain(); !strings.HasPrefix(got, "  ↳ [Explore] [15:04:05]") || strings.Contains(got, "\x1b[") {
+		t.Errorf("Plain() after FormatSidechain() = %q", got)
+	}
+}
+
+func TestThemeColors(t *testing.T) {
+	theme, err := ParseTheme([]byte("colors:\n  user: magenta\n  hook: none\n"))
+	if err != nil {
+		t.Fatalf("ParseTheme() error = %v", err)
+	}
+	palette := theme.Palette(DefaultPalette)
+	if palette[KindUser] != "magenta" || palette[KindAssistant] != DefaultPalette[KindAssistant] {
+		t.Errorf("Palette() = %v, want user magenta and the default for the rest", palette)
+	}
+	if _, ok := palette[KindHook]; ok {
+		t.Errorf("Palette() = %v, want no hook color", palette)
+	}
+	if DefaultPalette[KindHook] == "" {
+		t.Error("Palette() changed DefaultPalette")
+	}
+
+	// The default theme documents the default palette
+	theme, err = LoadTheme("default")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff(DefaultPalette, theme.Palette(nil)); diff != "" {
+		t.Errorf("default theme colors mismatch (-want +got):\n%s", diff)
+	}
+
+	for _, data := range []string{"colors:\n  tool: red\n", "colors:\n  user: purple\n"} {
+		if _, err := ParseTheme([]byte(data)); err == nil {
+			t.Errorf("ParseTheme(%q) error = nil, want error", data)
+		}
+	}
+}
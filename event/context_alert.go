@@ -0,0 +1,108 @@
+package event
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// ParseContextThresholds parses context usage percentages such as 70, 85 and 95,
+// returning them sorted
+func ParseContextThresholds(values []string) ([]int, error) {
+	thresholds := make([]int, 0, len(values))
+	for _, value := range values {
+		percent, err := strconv.Atoi(strings.TrimSuffix(strings.TrimSpace(value), "%"))
+		if err != nil || percent <= 0 || percent > 100 {
+			return nil, fmt.Errorf("invalid context threshold %q: must be a percentage from 1 to 100", value)
+		}
+		thresholds = append(thresholds, percent)
+	}
+	sort.Ints(thresholds)
+	return thresholds, nil
+}
+
+// ContextTracker follows how full the context window of each session is and reports
+// when it crosses a threshold, ahead of the compaction Claude Code does once it is
+// full. The context is the prompt of the latest assistant message: its input, cache
+// read and cache creation tokens. Each threshold is reported once until the context
+// shrinks below it again, as it does when compacted.
+type ContextTracker struct {
+	thresholds []int
+	window     func(model string) int // Context window of a model in tokens, 0 if unknown
+
+	mu        sync.Mutex
+	announced map[string]map[int]bool // Thresholds announced since the context last shrank, by session ID
+}
+
+// NewContextTracker creates a tracker announcing the thresholds, as percentages of the
+// context window that window returns for the model of a message
+func NewContextTracker(thresholds []int, window func(model string) int) *ContextTracker {
+	return &ContextTracker{
+		thresholds: thresholds,
+		window:     window,
+		announced:  make(map[string]map[int]bool),
+	}
+}
+
+// Observe updates the context of the session of an assistant message and returns an
+// alert if it crossed a threshold. Crossing several at once reports the highest.
+func (t *ContextTracker) Observe(msg *AssistantMessage) *ContextAlertMessage {
+	id := SessionIDOf(msg)
+	u := msg.Message.Usage
+	tokens := u.InputTokens + u.CacheReadInputTokens + u.CacheCreationInputTokens
+	if id == "" || tokens == 0 {
+		return nil
+	}
+	window := t.window(msg.Message.Model)
+	if window <= 0 {
+		return nil
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	announced, ok := t.announced[id]
+	if !ok {
+		announced = make(map[int]bool)
+		t.announced[id] = announced
+	}
+
+	percent := tokens * 100 / window
+	crossed := 0
+	for _, threshold := range t.thresholds {
+		if percent < threshold {
+			// Announce it again once the context grows back after a compaction
+			delete(announced, threshold)
+			continue
+		}
+		if !announced[threshold] {
+			announced[threshold] = true
+			crossed = threshold
+		}
+	}
+	if crossed == 0 {
+		return nil
+	}
+
+	alert := &ContextAlertMessage{
+		BaseEvent: msg.BaseEvent,
+		Threshold: crossed,
+		Percent:   percent,
+		Tokens:    tokens,
+		Window:    window,
+		Model:     msg.Message.Model,
+	}
+	alert.TypeString = "context_alert"
+	alert.UUID = ""
+	return alert
+}
+
+// Compacted forgets the context of a session that is being compacted, so that its
+// thresholds are announced again as the new context fills up
+func (t *ContextTracker) Compacted(sessionID string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	delete(t.announced, sessionID)
+}
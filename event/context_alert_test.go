@@ -0,0 +1,109 @@
+package event
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestParseContextThresholds(t *testing.T) {
+	tests := []struct {
+		name    string
+		values  []string
+		want    []int
+		wantErr bool
+	}{
+		{name: "empty", want: []int{}},
+		{name: "sorted", values: []string{"95", "70%", "85"}, want: []int{70, 85, 95}},
+		{name: "zero", values: []string{"0"}, wantErr: true},
+		{name: "over 100", values: []string{"120"}, wantErr: true},
+		{name: "not a number", values: []string{"high"}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseContextThresholds(tt.values)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseContextThresholds() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if diff := cmp.Diff(tt.want, got); !tt.wantErr && diff != "" {
+				t.Errorf("ParseContextThresholds() mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestContextTracker(t *testing.T) {
+	tracker := NewContextTracker([]int{70, 85, 95}, func(model string) int { return 1000 })
+
+	steps := []struct {
+		name    string
+		session string
+		tokens  int
+		want    int // Threshold reported, 0 for none
+	}{
+		{name: "under 70%", session: "s1", tokens: 500, want: 0},
+		{name: "70%", session: "s1", tokens: 720, want: 70},
+		{name: "still over 70%", session: "s1", tokens: 760, want: 0},
+		{name: "past 85% and 95% at once", session: "s1", tokens: 960, want: 95},
+		{name: "other session", session: "s2", tokens: 900, want: 85},
+		{name: "compacted", session: "s1", tokens: 300, want: 0},
+		{name: "filled again", session: "s1", tokens: 710, want: 70},
+	}
+	for _, step := range steps {
+		got := 0
+		msg := assistantMessage(step.session, time.Now())
+		msg.Message.Usage = Usage{InputTokens: 10, CacheReadInputTokens: step.tokens - 10}
+		if alert := tracker.Observe(msg); alert != nil {
+			got = alert.Threshold
+			if alert.Percent != step.tokens/10 || alert.Window != 1000 {
+				t.Errorf("%s: alert = %+v, want %d%% of 1000 tokens", step.name, alert, step.tokens/10)
+			}
+		}
+		if got != step.want {
+			t.Errorf("%s: threshold = %d, want %d", step.name, got, step.want)
+		}
+	}
+
+	tracker.Compacted("s2")
+	msg := assistantMessage("s2", time.Now())
+	msg.Message.Usage = Usage{InputTokens: 10, CacheReadInputTokens: 890}
+	if alert := tracker.Observe(msg); alert == nil || alert.Threshold != 85 {
+		t.Errorf("after PreCompact, alert = %+v, want 85%% again", alert)
+	}
+
+	unknown := NewContextTracker([]int{70}, func(model string) int { return 0 })
+	if alert := unknown.Observe(msg); alert != nil {
+		t.Errorf("unknown model alert = %+v, want none", alert)
+	}
+}
+
+func TestHandler_ContextAlert(t *testing.T) {
+	handler := NewHandler(&mockNarrator{}, false)
+	handler.SetContextAlerts([]int{70, 85, 95}, func(model string) int { return 1000 })
+	sink := &recordingSink{}
+	handler.AddSink(sink)
+
+	msg := assistantMessage("s1", time.Now())
+	msg.Message.Usage = Usage{InputTokens: 10, CacheReadInputTokens: 860}
+	captureOutput(t, func() {
+		handler.processEvent(msg)
+	})
+
+	if len(sink.events) != 2 {
+		t.Fatalf("sink received %d events, want the message and its alert", len(sink.events))
+	}
+	alert, ok := sink.events[1].(*ContextAlertMessage)
+	if !ok {
+		t.Fatalf("second event = %T, want *ContextAlertMessage", sink.events[1])
+	}
+	if alert.Threshold != 85 || alert.Percent != 87 {
+		t.Errorf("alert = %+v, want 87%% crossing 85%%", alert)
+	}
+	for _, want := range []string{"CONTEXT: 87% of the context window in use (870 of 1000 tokens, claude-sonnet-4)", "コンテキストが87%になりました"} {
+		if !strings.Contains(sink.formatted[1], want) {
+			t.Errorf("formatted alert = %q, want it to contain %q", sink.formatted[1], want)
+		}
+	}
+}
@@ -0,0 +1,72 @@
+package event
+
+import (
+	"sync"
+	"time"
+)
+
+// NarrationDedupeTTL is how long a tool use narration is remembered for deduplication.
+// PreToolUse hooks fire shortly before the tool_use line is written to the transcript.
+const NarrationDedupeTTL = 10 * time.Second
+
+// narrationDeduper remembers narrations by tool use ID so the same tool use
+// reported by a hook and by the transcript is narrated only once
+type narrationDeduper struct {
+	mu      sync.Mutex
+	ttl     time.Duration
+	now     func() time.Time
+	entries map[string]dedupeEntry
+}
+
+// dedupeEntry is a remembered narration
+type dedupeEntry struct {
+	text    string
+	expires time.Time
+}
+
+// newNarrationDeduper creates a deduper that remembers narrations for ttl
+func newNarrationDeduper(ttl time.Duration) *narrationDeduper {
+	return &narrationDeduper{
+		ttl:     ttl,
+		now:     time.Now,
+		entries: make(map[string]dedupeEntry),
+	}
+}
+
+// lookup returns the narration remembered for a tool use ID
+func (d *narrationDeduper) lookup(toolUseID string) (string, bool) {
+	if d == nil || toolUseID == "" {
+		return "", false
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	entry, ok := d.entries[toolUseID]
+	if !ok {
+		return "", false
+	}
+	if !d.now().Before(entry.expires) {
+		delete(d.entries, toolUseID)
+		return "", false
+	}
+	return entry.text, true
+}
+
+// store remembers the narration of a tool use ID and drops expired entries
+func (d *narrationDeduper) store(toolUseID, text string) {
+	if d == nil || toolUseID == "" {
+		return
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	now := d.now()
+	for id, entry := range d.entries {
+		if !now.Before(entry.expires) {
+			delete(d.entries, id)
+		}
+	}
+	d.entries[toolUseID] = dedupeEntry{text: text, expires: now.Add(d.ttl)}
+}
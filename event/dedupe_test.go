@@ -0,0 +1,109 @@
+package event
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/kazegusuri/claude-companion/narrator"
+)
+
+// countingNarrator counts tool use narrations
+type countingNarrator struct {
+	narrator.NoOpNarrator
+	toolUses int
+}
+
+func (n *countingNarrator) NarrateToolUse(toolName string, input map[string]interface{}) (string, bool) {
+	n.toolUses++
+	return "narrate-" + toolName, false
+}
+
+func TestFormatterToolUseDedupe(t *testing.T) {
+	hook := func(id string) *NotificationEvent {
+		return &NotificationEvent{
+			HookEventName: "PreToolUse",
+			ToolName:      "Bash",
+			ToolInput:     map[string]interface{}{"command": "make test"},
+			ToolUseID:     id,
+		}
+	}
+	transcript := func(id string) *AssistantMessage {
+		msg := &AssistantMessage{}
+		msg.Message.Content = []AssistantContent{{
+			Type:  "tool_use",
+			ID:    id,
+			Name:  "Bash",
+			Input: map[string]interface{}{"command": "make test"},
+		}}
+		return msg
+	}
+
+	tests := []struct {
+		name          string
+		events        []Event
+		advance       time.Duration // time between events
+		wantNarrated  int
+		wantNarration int // outputs containing the narration
+	}{
+		{
+			name:          "hook then transcript",
+			events:        []Event{hook("toolu_1"), transcript("toolu_1")},
+			wantNarrated:  1,
+			wantNarration: 2,
+		},
+		{
+			name:          "transcript then hook",
+			events:        []Event{transcript("toolu_1"), hook("toolu_1")},
+			wantNarrated:  1,
+			wantNarration: 2,
+		},
+		{
+			name:          "different tool uses",
+			events:        []Event{hook("toolu_1"), transcript("toolu_2")},
+			wantNarrated:  2,
+			wantNarration: 2,
+		},
+		{
+			name:          "without tool use IDs",
+			events:        []Event{hook(""), transcript("")},
+			wantNarrated:  2,
+			wantNarration: 2,
+		},
+		{
+			name:          "after the window",
+			events:        []Event{hook("toolu_1"), transcript("toolu_1")},
+			advance:       NarrationDedupeTTL,
+			wantNarrated:  2,
+			wantNarration: 2,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			n := &countingNarrator{}
+			f := NewFormatter(n)
+			now := time.Now()
+			f.dedupe.now = func() time.Time { return now }
+
+			narrations := 0
+			for _, ev := range tt.events {
+				output, err := f.Format(ev)
+				if err != nil {
+					t.Fatalf("Format() error = %v", err)
+				}
+				if strings.Contains(output, "💬 narrate-Bash") {
+					narrations++
+				}
+				now = now.Add(tt.advance)
+			}
+
+			if n.toolUses != tt.wantNarrated {
+				t.Errorf("narrated %d times, want %d", n.toolUses, tt.wantNarrated)
+			}
+			if narrations != tt.wantNarration {
+				t.Errorf("narration shown %d times, want %d", narrations, tt.wantNarration)
+			}
+		})
+	}
+}
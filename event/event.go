@@ -1,6 +1,7 @@
 package event
 
 import (
+	"encoding/json"
 	"fmt"
 	"path/filepath"
 	"regexp"
@@ -34,6 +35,7 @@ const (
 type Session struct {
 	Project string `json:"project"`
 	Session string `json:"session"`
+	Root    string `json:"root,omitempty"` // Label of the projects root the log file belongs to
 }
 
 // BaseEvent contains common fields for all event types
@@ -49,6 +51,17 @@ type BaseEvent struct {
 	UUID        string    `json:"uuid"`
 	Timestamp   time.Time `json:"timestamp"`
 	TypeString  string    `json:"type"`
+
+	// Schema is the name of the transcript schema the line was parsed with (see SchemaFor)
+	Schema string `json:"-"`
+	// Extra are the fields of the line the event type does not decode, such as ones
+	// added by a newer Claude Code, kept so they are not lost
+	Extra map[string]json.RawMessage `json:"-"`
+
+	// Priority is assigned by the Handler (see ScorePriority)
+	Priority int `json:"-"`
+	// Narrations are the narrator outputs the Handler formatted the event with
+	Narrations []string `json:"-"`
 }
 
 // Type returns the event type
@@ -56,6 +69,68 @@ func (e *BaseEvent) Type() Type {
 	return Type(e.TypeString)
 }
 
+// BaseOf returns the BaseEvent of an event, or nil if the event has none
+func BaseOf(event Event) *BaseEvent {
+	switch e := event.(type) {
+	case *UserMessage:
+		return &e.BaseEvent
+	case *AssistantMessage:
+		return &e.BaseEvent
+	case *SystemMessage:
+		return &e.BaseEvent
+	case *HookEvent:
+		return &e.BaseEvent
+	case *TaskCompletionMessage:
+		return &e.BaseEvent
+	case *ToolSLABreachMessage:
+		return &e.BaseEvent
+	case *SessionSummaryMessage:
+		return &e.BaseEvent
+	case *CatchUpMessage:
+		return &e.BaseEvent
+	case *BudgetAlertMessage:
+		return &e.BaseEvent
+	case *ContextAlertMessage:
+		return &e.BaseEvent
+	case *SecurityAlertMessage:
+		return &e.BaseEvent
+	case *BaseEvent:
+		return e
+	default:
+		return nil
+	}
+}
+
+// SessionIDOf returns the Claude session ID of an event, or an empty string if unknown
+func SessionIDOf(event Event) string {
+	if e, ok := event.(*NotificationEvent); ok {
+		return e.SessionID
+	}
+	if base := BaseOf(event); base != nil {
+		if base.SessionID != "" {
+			return base.SessionID
+		}
+		if base.Session != nil {
+			return base.Session.Session
+		}
+	}
+	return ""
+}
+
+// SessionOf returns the project and session of an event, or nil if unknown
+func SessionOf(event Event) *Session {
+	if e, ok := event.(*NotificationEvent); ok {
+		if e.TranscriptPath == "" {
+			return nil
+		}
+		return extractSessionFromPath(e.TranscriptPath)
+	}
+	if base := BaseOf(event); base != nil {
+		return base.Session
+	}
+	return nil
+}
+
 // UserMessageContent represents the content of a user message
 type UserMessageContent struct {
 	Role    string      `json:"role"`
@@ -70,12 +145,13 @@ type UserMessage struct {
 
 // AssistantContent represents a content item in an assistant message
 type AssistantContent struct {
-	Type     string      `json:"type"`
-	Text     string      `json:"text,omitempty"`
-	ID       string      `json:"id,omitempty"`
-	Name     string      `json:"name,omitempty"`
-	Input    interface{} `json:"input,omitempty"`
-	Thinking string      `json:"thinking,omitempty"`
+	Type     string            `json:"type"`
+	Text     string            `json:"text,omitempty"`
+	ID       string            `json:"id,omitempty"`
+	Name     string            `json:"name,omitempty"`
+	Input    interface{}       `json:"input,omitempty"`
+	Thinking string            `json:"thinking,omitempty"`
+	Source   *AttachmentSource `json:"source,omitempty"` // For image and document content
 }
 
 // Usage represents token usage information
@@ -139,7 +215,29 @@ type NotificationEvent struct {
 	Message            string `json:"message"`
 	Trigger            string `json:"trigger"`
 	CustomInstructions string `json:"custom_instructions"`
-	Source             string `json:"source"` // For SessionStart events: startup, clear, resume
+	Source             string `json:"source"`              // For SessionStart events: startup, clear, resume
+	Reason             string `json:"reason,omitempty"`    // For SessionEnd events: clear, logout, prompt_input_exit, other
+	LoggedAt           string `json:"timestamp,omitempty"` // Optional time written by the hook, with or without a time zone
+
+	// PreToolUse and PostToolUse events
+	ToolName     string                 `json:"tool_name,omitempty"`
+	ToolInput    map[string]interface{} `json:"tool_input,omitempty"`
+	ToolUseID    string                 `json:"tool_use_id,omitempty"`
+	ToolResponse interface{}            `json:"tool_response,omitempty"` // PostToolUse only; its shape depends on the tool
+
+	// Stop events
+	StopHookActive bool `json:"stop_hook_active,omitempty"`
+
+	// Elapsed is how long the tool of a PostToolUse event ran, measured from its
+	// PreToolUse by the Handler; zero if that was not seen
+	Elapsed time.Duration `json:"-"`
+
+	// Timestamp is LoggedAt in local time, or the time the line was read
+	Timestamp time.Time `json:"-"`
+	// Priority is assigned by the Handler (see ScorePriority)
+	Priority int `json:"-"`
+	// Narrations are the narrator outputs the Handler formatted the event with
+	Narrations []string `json:"-"`
 }
 
 // Type returns the event type
@@ -150,7 +248,8 @@ func (e *NotificationEvent) Type() Type {
 // TaskCompletionMessage represents the completion of a Task tool execution
 type TaskCompletionMessage struct {
 	BaseEvent
-	TaskInfo TaskInfo
+	TaskInfo  TaskInfo
+	Sidechain *SidechainRun // What the subagent did; nil unless sidechains are shown
 }
 
 // Type returns the event type
@@ -158,6 +257,100 @@ func (e *TaskCompletionMessage) Type() Type {
 	return Type("task_completion")
 }
 
+// ToolSLABreachMessage reports a tool that took longer than its expected maximum duration
+type ToolSLABreachMessage struct {
+	BaseEvent
+	ToolName  string
+	ToolUseID string
+	Elapsed   time.Duration
+	Limit     time.Duration
+}
+
+// Type returns the event type
+func (e *ToolSLABreachMessage) Type() Type {
+	return Type("tool_sla_breach")
+}
+
+// SessionSummaryMessage summarizes what a session did once it ended
+type SessionSummaryMessage struct {
+	BaseEvent
+	Reason         string // "idle", or the reason of the SessionEnd hook
+	Duration       time.Duration
+	FilesEdited    []string
+	Commands       int
+	Tokens         int64
+	TasksCompleted int
+}
+
+// Type returns the event type
+func (e *SessionSummaryMessage) Type() Type {
+	return Type("session_summary")
+}
+
+// CatchUpMessage summarizes the events written to a transcript while the companion
+// was not running, read when tailing resumes from the saved offset
+type CatchUpMessage struct {
+	BaseEvent
+	Events         int
+	Duration       time.Duration // From the first to the last event caught up on
+	FilesEdited    []string
+	Commands       int
+	Tokens         int64
+	TasksCompleted int
+}
+
+// Type returns the event type
+func (e *CatchUpMessage) Type() Type {
+	return Type("catch_up")
+}
+
+// BudgetAlertMessage reports that the usage of a session or of the day crossed a
+// threshold of its token or cost budget
+type BudgetAlertMessage struct {
+	BaseEvent
+	Scope     string  // BudgetSession or BudgetDay
+	Metric    string  // BudgetTokens or BudgetCost
+	Used      float64 // Tokens, or estimated cost in USD
+	Limit     float64
+	Threshold float64 // Fraction of the budget crossed, 0.8 or 1
+}
+
+// Type returns the event type
+func (e *BudgetAlertMessage) Type() Type {
+	return Type("budget_alert")
+}
+
+// ContextAlertMessage reports that the context of a session crossed a threshold of
+// its context window, ahead of its compaction
+type ContextAlertMessage struct {
+	BaseEvent
+	Threshold int // Percentage crossed
+	Percent   int // Percentage of the context window in use
+	Tokens    int
+	Window    int
+	Model     string
+}
+
+// Type returns the event type
+func (e *ContextAlertMessage) Type() Type {
+	return Type("context_alert")
+}
+
+// SecurityAlertMessage reports a risky tool use that a security rule flagged
+type SecurityAlertMessage struct {
+	BaseEvent
+	Rule      string // Name of the rule
+	Reason    string // Why the rule flags the tool use
+	ToolName  string
+	ToolUseID string
+	Target    string // Input value the rule matched, such as the command or the file path
+}
+
+// Type returns the event type
+func (e *SecurityAlertMessage) Type() Type {
+	return Type("security_alert")
+}
+
 // HookEvent represents a hook execution event from Claude
 type HookEvent struct {
 	BaseEvent
@@ -200,26 +393,23 @@ func (h *HookEvent) ParseHookContent() error {
 	return fmt.Errorf("unable to parse hook content: %s", cleanContent)
 }
 
+// ansiPattern matches the ANSI escape codes of colors and text styles
+var ansiPattern = regexp.MustCompile(`\x1b\[[0-9;]*m`)
+
 // stripANSI removes ANSI escape codes from a string
 func stripANSI(str string) string {
-	ansiRegex := regexp.MustCompile(`\x1b\[[0-9;]*m`)
-	return ansiRegex.ReplaceAllString(str, "")
+	return ansiPattern.ReplaceAllString(str, "")
 }
 
 // extractSessionFromPath extracts project and session information from a log file path
-// Expected format: {project}/{session}.jsonl
+// Expected format: {project}/{session}.jsonl or {project}/{session}.jsonl.gz
 func extractSessionFromPath(path string) *Session {
 	// Clean the path
 	cleanPath := filepath.Clean(path)
 
-	// Extract the directory and filename
+	// Extract the directory and the filename without its extension
 	dir := filepath.Dir(cleanPath)
-	filename := filepath.Base(cleanPath)
-
-	// Remove .jsonl extension if present
-	if strings.HasSuffix(filename, ".jsonl") {
-		filename = strings.TrimSuffix(filename, ".jsonl")
-	}
+	filename := SessionName(cleanPath)
 
 	// Extract project name from the parent directory
 	projectDir := filepath.Base(dir)
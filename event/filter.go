@@ -0,0 +1,205 @@
+package event
+
+import (
+	"fmt"
+	"path"
+	"strings"
+)
+
+// Event kinds that --include-events and --exclude-events accept
+const (
+	KindUser           = "user"
+	KindAssistant      = "assistant"
+	KindSystem         = "system"
+	KindHook           = "hook" // Hook lines in the transcript and hook notifications other than Notification
+	KindSummary        = "summary"
+	KindNotification   = "notification" // Permission requests and idle notifications
+	KindTaskCompletion = "task_completion"
+	KindToolSLABreach  = "tool_sla_breach"
+	KindSessionSummary = "session_summary"
+	KindCatchUp        = "catch_up"
+	KindBudgetAlert    = "budget_alert"
+	KindContextAlert   = "context_alert"
+	KindSecurityAlert  = "security_alert"
+)
+
+// EventKinds lists the event kinds in documentation order
+var EventKinds = []string{KindUser, KindAssistant, KindSystem, KindHook, KindSummary, KindNotification, KindTaskCompletion, KindToolSLABreach, KindSessionSummary, KindCatchUp, KindBudgetAlert, KindContextAlert, KindSecurityAlert}
+
+// maxDroppedToolUses bounds the tool use IDs remembered to drop their results
+const maxDroppedToolUses = 10000
+
+// EventFilter decides which events are shown and narrated by kind and by tool name.
+// Tool filters apply to tool uses, their PreToolUse hooks and their results; other
+// events are only filtered by kind. It is used from the handler's goroutine only.
+type EventFilter struct {
+	includeKinds map[string]bool
+	excludeKinds map[string]bool
+	includeTools []string
+	excludeTools []string
+
+	// Tool use IDs whose tool was filtered out, to drop their results too
+	dropped map[string]struct{}
+}
+
+// NewEventFilter creates a filter. Empty include lists allow everything; tool names
+// may be glob patterns such as mcp__github__*.
+func NewEventFilter(includeKinds, excludeKinds, includeTools, excludeTools []string) (*EventFilter, error) {
+	f := &EventFilter{
+		includeKinds: make(map[string]bool),
+		excludeKinds: make(map[string]bool),
+		dropped:      make(map[string]struct{}),
+	}
+	for _, kinds := range []struct {
+		names []string
+		set   map[string]bool
+	}{{includeKinds, f.includeKinds}, {excludeKinds, f.excludeKinds}} {
+		for _, name := range kinds.names {
+			if !isEventKind(name) {
+				return nil, fmt.Errorf("invalid event kind: %s (must be one of %s)", name, strings.Join(EventKinds, ", "))
+			}
+			kinds.set[name] = true
+		}
+	}
+	for _, pattern := range append(append([]string{}, includeTools...), excludeTools...) {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return nil, fmt.Errorf("invalid tool pattern %q: %w", pattern, err)
+		}
+	}
+	f.includeTools = includeTools
+	f.excludeTools = excludeTools
+	return f, nil
+}
+
+// isEventKind reports whether name is a known event kind
+func isEventKind(name string) bool {
+	for _, kind := range EventKinds {
+		if kind == name {
+			return true
+		}
+	}
+	return false
+}
+
+// KindOf returns the filter kind of an event
+func KindOf(event Event) string {
+	switch e := event.(type) {
+	case *HookEvent:
+		return KindHook
+	case *NotificationEvent:
+		if e.HookEventName == "Notification" {
+			return KindNotification
+		}
+		return KindHook
+	}
+	return string(event.Type())
+}
+
+// Enabled reports whether the filter removes anything
+func (f *EventFilter) Enabled() bool {
+	return len(f.includeKinds) > 0 || len(f.excludeKinds) > 0 || len(f.includeTools) > 0 || len(f.excludeTools) > 0
+}
+
+// Apply returns the event to process, or false if it is filtered out. An assistant
+// message with several content blocks keeps only the allowed tool uses, so the
+// returned event may be a copy of the original.
+func (f *EventFilter) Apply(event Event) (Event, bool) {
+	kind := KindOf(event)
+	if (len(f.includeKinds) > 0 && !f.includeKinds[kind]) || f.excludeKinds[kind] {
+		return nil, false
+	}
+	if len(f.includeTools) == 0 && len(f.excludeTools) == 0 {
+		return event, true
+	}
+
+	switch e := event.(type) {
+	case *AssistantMessage:
+		kept := make([]AssistantContent, 0, len(e.Message.Content))
+		for _, content := range e.Message.Content {
+			if content.Type == "tool_use" && !f.toolAllowed(content.Name) {
+				f.drop(content.ID)
+				continue
+			}
+			kept = append(kept, content)
+		}
+		if len(kept) == len(e.Message.Content) {
+			return event, true
+		}
+		if len(kept) == 0 {
+			return nil, false
+		}
+		filtered := *e
+		filtered.Message.Content = kept
+		return &filtered, true
+	case *NotificationEvent:
+		if (e.HookEventName == "PreToolUse" || e.HookEventName == "PostToolUse") && !f.toolAllowed(e.ToolName) {
+			return nil, false
+		}
+	case *UserMessage:
+		items, ok := e.Message.Content.([]interface{})
+		if !ok {
+			return event, true
+		}
+		kept := make([]interface{}, 0, len(items))
+		for _, item := range items {
+			if m, ok := item.(map[string]interface{}); ok && m["type"] == "tool_result" {
+				if id, _ := m["tool_use_id"].(string); f.wasDropped(id) {
+					continue
+				}
+			}
+			kept = append(kept, item)
+		}
+		if len(kept) == len(items) {
+			return event, true
+		}
+		if len(kept) == 0 {
+			return nil, false
+		}
+		filtered := *e
+		filtered.Message.Content = kept
+		return &filtered, true
+	case *ToolSLABreachMessage:
+		if !f.toolAllowed(e.ToolName) {
+			return nil, false
+		}
+	}
+	return event, true
+}
+
+// toolAllowed reports whether a tool passes the tool filters
+func (f *EventFilter) toolAllowed(name string) bool {
+	if len(f.includeTools) > 0 && !matchAny(f.includeTools, name) {
+		return false
+	}
+	return !matchAny(f.excludeTools, name)
+}
+
+// matchAny reports whether name matches any of the glob patterns
+func matchAny(patterns []string, name string) bool {
+	for _, pattern := range patterns {
+		if ok, _ := path.Match(pattern, name); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// drop remembers a filtered tool use so its result is filtered too
+func (f *EventFilter) drop(toolUseID string) {
+	if toolUseID == "" {
+		return
+	}
+	if len(f.dropped) >= maxDroppedToolUses {
+		f.dropped = make(map[string]struct{})
+	}
+	f.dropped[toolUseID] = struct{}{}
+}
+
+// wasDropped reports whether a tool use was filtered out, forgetting it since a result comes once
+func (f *EventFilter) wasDropped(toolUseID string) bool {
+	if _, ok := f.dropped[toolUseID]; !ok {
+		return false
+	}
+	delete(f.dropped, toolUseID)
+	return true
+}
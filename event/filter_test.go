@@ -0,0 +1,110 @@
+package event
+
+import (
+	"testing"
+	"time"
+)
+
+func TestNewEventFilter(t *testing.T) {
+	tests := []struct {
+		name                       string
+		includeKinds, excludeKinds []string
+		includeTools               []string
+		wantErr                    bool
+	}{
+		{name: "empty"},
+		{name: "kinds", includeKinds: []string{"assistant", "notification"}, excludeKinds: []string{"hook"}},
+		{name: "unknown kind", excludeKinds: []string{"hooks"}, wantErr: true},
+		{name: "invalid pattern", includeTools: []string{"mcp__["}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewEventFilter(tt.includeKinds, tt.excludeKinds, tt.includeTools, nil)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("NewEventFilter() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestEventFilter_Kinds(t *testing.T) {
+	hook := &HookEvent{BaseEvent: BaseEvent{TypeString: "system"}}
+	preToolUse := &NotificationEvent{HookEventName: "PreToolUse", ToolName: "Bash"}
+	permission := &NotificationEvent{HookEventName: "Notification", Message: "Claude needs your permission to use Bash"}
+	system := &SystemMessage{BaseEvent: BaseEvent{TypeString: "system"}}
+	user := &UserMessage{BaseEvent: BaseEvent{TypeString: "user"}, Message: UserMessageContent{Content: "hello"}}
+
+	tests := []struct {
+		name         string
+		include      []string
+		exclude      []string
+		ev           Event
+		wantIncluded bool
+	}{
+		{name: "no filter", ev: hook, wantIncluded: true},
+		{name: "excluded hook line", exclude: []string{"system", "hook"}, ev: hook},
+		{name: "excluded hook notification", exclude: []string{"hook"}, ev: preToolUse},
+		{name: "permission is a notification", exclude: []string{"hook"}, ev: permission, wantIncluded: true},
+		{name: "excluded system", exclude: []string{"system"}, ev: system},
+		{name: "included kind", include: []string{"user"}, ev: user, wantIncluded: true},
+		{name: "not included kind", include: []string{"assistant"}, ev: user},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f, err := NewEventFilter(tt.include, tt.exclude, nil, nil)
+			if err != nil {
+				t.Fatalf("NewEventFilter() error = %v", err)
+			}
+			if _, ok := f.Apply(tt.ev); ok != tt.wantIncluded {
+				t.Errorf("Apply() = %v, want %v", ok, tt.wantIncluded)
+			}
+		})
+	}
+}
+
+func TestEventFilter_Tools(t *testing.T) {
+	now := time.Now()
+	f, err := NewEventFilter(nil, nil, []string{"Bash", "Edit", "mcp__github__*"}, []string{"mcp__github__delete_*"})
+	if err != nil {
+		t.Fatalf("NewEventFilter() error = %v", err)
+	}
+
+	for _, tt := range []struct {
+		tool string
+		want bool
+	}{
+		{"Bash", true},
+		{"Read", false},
+		{"mcp__github__create_pr", true},
+		{"mcp__github__delete_branch", false},
+	} {
+		if _, ok := f.Apply(assistantMessage("s1", now, toolUseContent("id-"+tt.tool, tt.tool, nil))); ok != tt.want {
+			t.Errorf("Apply(tool use of %s) = %v, want %v", tt.tool, ok, tt.want)
+		}
+		if _, ok := f.Apply(&NotificationEvent{HookEventName: "PreToolUse", ToolName: tt.tool}); ok != tt.want {
+			t.Errorf("Apply(PreToolUse of %s) = %v, want %v", tt.tool, ok, tt.want)
+		}
+		if _, ok := f.Apply(toolResult("id-"+tt.tool, now)); ok != tt.want {
+			t.Errorf("Apply(tool result of %s) = %v, want %v", tt.tool, ok, tt.want)
+		}
+	}
+
+	// Text is kept while filtered tool uses are removed from the message
+	msg := &AssistantMessage{
+		BaseEvent: BaseEvent{TypeString: "assistant"},
+		Message: AssistantMessageContent{Content: []AssistantContent{
+			{Type: "text", Text: "Let me look"},
+			{Type: "tool_use", ID: "read-1", Name: "Read"},
+		}},
+	}
+	got, ok := f.Apply(msg)
+	if !ok {
+		t.Fatalf("Apply() dropped a message with text")
+	}
+	if content := got.(*AssistantMessage).Message.Content; len(content) != 1 || content[0].Type != "text" {
+		t.Errorf("Apply() content = %+v, want the text only", content)
+	}
+	if len(msg.Message.Content) != 2 {
+		t.Errorf("Apply() modified the original message")
+	}
+}
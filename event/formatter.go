@@ -9,7 +9,10 @@ import (
 	"strings"
 	"time"
 
+	"github.com/kazegusuri/claude-companion/logger"
 	"github.com/kazegusuri/claude-companion/narrator"
+	"github.com/kazegusuri/claude-companion/notify"
+	"github.com/mattn/go-runewidth"
 )
 
 // EventMeta contains metadata about the event context
@@ -24,15 +27,34 @@ type Formatter struct {
 	debugMode      bool
 	fileOperations []string
 	currentTool    string
+	layout         Layout
+	width          func() int
+	accessible     bool
+	notifier       notify.Notifier
+	notifyMuted    bool // Desktop notifications are turned off for the current project
+	dedupe         *narrationDeduper
+	recorder       *narrationRecorder // Wraps narrator; nil without a narrator
+	attachmentDir  string             // Where attachments are saved; empty to not save them
+	theme          *Theme             // nil uses the built-in format
+	content        string             // Content lines of the assistant message formatted last, for the theme
+	palette        Palette            // Console colors; nil prints without colors
+	wrap           bool               // Fit lines to the terminal width
+	plain          string             // Output of the event formatted last, without colors or wrapping
 }
 
 // NewFormatter creates a new Formatter instance
 func NewFormatter(narrator narrator.Narrator) *Formatter {
-	return &Formatter{
+	f := &Formatter{
 		narrator:       narrator,
 		debugMode:      false,
 		fileOperations: make([]string, 0),
+		dedupe:         newNarrationDeduper(NarrationDedupeTTL),
 	}
+	if narrator != nil {
+		f.recorder = &narrationRecorder{Narrator: narrator}
+		f.narrator = f.recorder
+	}
+	return f
 }
 
 // SetDebugMode enables or disables debug mode
@@ -40,8 +62,97 @@ func (f *Formatter) SetDebugMode(enabled bool) {
 	f.debugMode = enabled
 }
 
+// SetNotifier sets the notifier for permission requests and task completions
+func (f *Formatter) SetNotifier(notifier notify.Notifier) {
+	f.notifier = notifier
+}
+
+// SetAttachmentDir saves the images and documents attached to messages under dir,
+// in a directory per project and session
+func (f *Formatter) SetAttachmentDir(dir string) {
+	f.attachmentDir = dir
+}
+
+// SetTheme renders the console output with the templates of theme; nil uses the built-in format
+func (f *Formatter) SetTheme(theme *Theme) {
+	f.theme = theme
+}
+
+// SetNotifyMuted turns desktop notifications off for the events formatted next
+func (f *Formatter) SetNotifyMuted(muted bool) {
+	f.notifyMuted = muted
+}
+
 // Format formats an event for display
 func (f *Formatter) Format(event Event) (string, error) {
+	return f.formatConsole(event, 0)
+}
+
+// formatConsole formats an event for a console whose lines the caller prefixes with
+// indent columns
+func (f *Formatter) formatConsole(event Event, indent int) (string, error) {
+	if f.recorder != nil {
+		f.recorder.narrations = nil
+	}
+	f.content = ""
+	output, err := f.format(event)
+	if err != nil {
+		return output, err
+	}
+	output = f.theme.renderEvent(event, output, f.content)
+	return f.finish(KindOf(event), output, indent), nil
+}
+
+// FormatSidechain formats an event of a Task subagent, indented and attributed to
+// the subagent. Its narrations are shown but not spoken.
+func (f *Formatter) FormatSidechain(event Event, agent string) (string, error) {
+	if f.recorder != nil {
+		voiced := f.recorder.Narrator
+		f.recorder.Narrator = narrator.Unvoiced(voiced)
+		defer func() { f.recorder.Narrator = voiced }()
+	}
+	first, rest := "  ↳ ", "  │ "
+	if f.accessible {
+		first, rest = "  > ", "  | "
+	}
+	output, err := f.formatConsole(event, runewidth.StringWidth(first+"["+agent+"] "))
+	if err != nil || output == "" {
+		return output, err
+	}
+
+	f.plain = indentLines(f.plain, first+"["+agent+"] ", rest)
+	return indentLines(output, first+"["+agent+"] ", rest), nil
+}
+
+// indentLines prefixes the first line of output with first and the others with rest
+func indentLines(output, first, rest string) string {
+	var b strings.Builder
+	for i, line := range strings.SplitAfter(strings.TrimSuffix(output, "\n"), "\n") {
+		if i == 0 {
+			b.WriteString(first + line)
+		} else {
+			b.WriteString(rest + line)
+		}
+	}
+	return b.String() + "\n"
+}
+
+// Plain returns the output of the last Format call without colors and unwrapped, for
+// clients that render it themselves
+func (f *Formatter) Plain() string {
+	return f.plain
+}
+
+// Narrations returns the narrations produced by the last Format call. Narrations
+// reused for a tool use reported twice are not included, as they are not spoken again.
+func (f *Formatter) Narrations() []string {
+	if f.recorder == nil {
+		return nil
+	}
+	return f.recorder.narrations
+}
+
+func (f *Formatter) format(event Event) (string, error) {
 	switch e := event.(type) {
 	case *UserMessage:
 		return f.formatUserMessage(e)
@@ -57,6 +168,18 @@ func (f *Formatter) Format(event Event) (string, error) {
 		return f.formatNotificationEvent(e)
 	case *TaskCompletionMessage:
 		return f.formatTaskCompletionMessage(e)
+	case *ToolSLABreachMessage:
+		return f.formatToolSLABreachMessage(e)
+	case *SessionSummaryMessage:
+		return f.formatSessionSummaryMessage(e)
+	case *CatchUpMessage:
+		return f.formatCatchUpMessage(e)
+	case *BudgetAlertMessage:
+		return f.formatBudgetAlertMessage(e)
+	case *ContextAlertMessage:
+		return f.formatContextAlertMessage(e)
+	case *SecurityAlertMessage:
+		return f.formatSecurityAlertMessage(e)
 	case *BaseEvent:
 		return f.formatUnknownEvent(e)
 	default:
@@ -132,6 +255,11 @@ func (f *Formatter) formatUserMessage(event *UserMessage) (string, error) {
 						}
 						resultLine := fmt.Sprintf("  %s Tool Result: %v", emoji, toolID)
 						output.WriteString(resultLine + "\n")
+						for _, a := range attachmentsOf(contentMap["content"]) {
+							output.WriteString(f.formatAttachment(a, event.Session, "    "))
+						}
+					case "image", "document":
+						output.WriteString(f.formatAttachment(attachmentOfBlock(contentMap), event.Session, "  "))
 					}
 				}
 			}
@@ -162,7 +290,14 @@ func (f *Formatter) formatAssistantMessage(event *AssistantMessage) (string, err
 			header += fmt.Sprintf(" [Stop: %s]", *event.Message.StopReason)
 		}
 	}
-	output.WriteString(header + "\n")
+	// In two-column layout token usage is shown next to the header
+	showUsage := event.Message.Usage.OutputTokens > 0
+	if showUsage && f.twoColumn() {
+		output.WriteString(f.withDetail(header, usageDetail(event.Message.Usage)))
+		showUsage = false
+	} else {
+		output.WriteString(header + "\n")
+	}
 
 	// Check if this is an API error message
 	if event.IsApiErrorMessage {
@@ -215,6 +350,13 @@ func (f *Formatter) formatAssistantMessage(event *AssistantMessage) (string, err
 
 	// Track if we have any content to show summary for
 	hasContent := false
+	contentStart := output.Len()
+
+	// Speak the tool uses of the message as one narration; the console still shows each
+	if batcher := f.toolBatcher(); batcher != nil && countToolUses(event.Message.Content) > 1 {
+		batcher.BeginToolBatch()
+		defer batcher.EndToolBatch()
+	}
 
 	for i := range event.Message.Content {
 		content := &event.Message.Content[i]
@@ -226,6 +368,8 @@ func (f *Formatter) formatAssistantMessage(event *AssistantMessage) (string, err
 		case "thinking":
 			formatted := f.FormatAssistantText(content.Thinking, true)
 			output.WriteString(formatted)
+		case "image", "document":
+			output.WriteString(f.formatAttachment(newAttachment(content.Type, content.Source), event.Session, "  "))
 		case "tool_use":
 			// Convert input to map[string]interface{} for formatter
 			inputMap := make(map[string]interface{})
@@ -256,6 +400,8 @@ func (f *Formatter) formatAssistantMessage(event *AssistantMessage) (string, err
 		}
 	}
 
+	f.content = output.String()[contentStart:]
+
 	// Show file operations summary first if we had any content
 	if hasContent {
 		summary := f.GetFileSummary()
@@ -267,7 +413,7 @@ func (f *Formatter) formatAssistantMessage(event *AssistantMessage) (string, err
 	}
 
 	// Add token usage at the end if present
-	if event.Message.Usage.OutputTokens > 0 {
+	if showUsage {
 		output.WriteString(fmt.Sprintf("  💰 Tokens: input=%d, output=%d, cache_read=%d, cache_creation=%d\n",
 			event.Message.Usage.InputTokens,
 			event.Message.Usage.OutputTokens,
@@ -283,6 +429,28 @@ func (f *Formatter) formatAssistantMessage(event *AssistantMessage) (string, err
 	return result, nil
 }
 
+// formatAttachment formats an attachment line, saving the attachment if an attachment
+// directory is set. Attachments are not narrated.
+func (f *Formatter) formatAttachment(a *Attachment, session *Session, indent string) string {
+	emoji := "🖼️"
+	if a.Kind == "document" {
+		emoji = "📎"
+	}
+	line := fmt.Sprintf("%s%s %s", indent, emoji, a)
+	if f.attachmentDir != "" && a.Size() > 0 {
+		dir := f.attachmentDir
+		if session != nil {
+			dir = filepath.Join(dir, session.Project, session.Session)
+		}
+		if path, err := a.Save(dir); err != nil {
+			logger.LogWarning("Failed to save attachment: %v", err)
+		} else {
+			line += " → " + path
+		}
+	}
+	return line + "\n"
+}
+
 func (f *Formatter) formatHookEvent(event *HookEvent) (string, error) {
 	if event.IsMeta && !f.debugMode {
 		return "", nil // Skip meta messages unless in debug mode
@@ -386,6 +554,10 @@ func (f *Formatter) formatNotificationEvent(event *NotificationEvent) (string, e
 		output.WriteString(f.formatSessionStartEvent(event))
 	case "Notification":
 		output.WriteString(f.formatGeneralNotificationEvent(event))
+	case "PreToolUse":
+		output.WriteString(f.formatPreToolUseEvent(event))
+	case "PostToolUse":
+		output.WriteString(f.formatPostToolUseEvent(event))
 	default:
 		// Return empty string for unknown event types
 		return "", nil
@@ -408,7 +580,7 @@ func (f *Formatter) formatPreCompactEvent(event *NotificationEvent) string {
 	formattedMessage, _ := f.narrator.NarrateNotification(narrator.NotificationTypeCompact)
 
 	// Build header with optional debug info
-	header := fmt.Sprintf("[%s] %s %s", timeNow().Format("15:04:05"), emoji, event.HookEventName)
+	header := fmt.Sprintf("[%s] %s %s", notificationTime(event).Format("15:04:05"), emoji, event.HookEventName)
 	if f.debugMode && len(event.SessionID) >= 8 {
 		header += fmt.Sprintf(" [Session: %s]", event.SessionID[:8])
 	}
@@ -430,6 +602,66 @@ func (f *Formatter) formatPreCompactEvent(event *NotificationEvent) string {
 	return output.String()
 }
 
+// formatPreToolUseEvent formats PreToolUse events
+func (f *Formatter) formatPreToolUseEvent(event *NotificationEvent) string {
+	if event.ToolName == "" {
+		return ""
+	}
+
+	var output strings.Builder
+	header := fmt.Sprintf("[%s] 🔧 %s", notificationTime(event).Format("15:04:05"), event.HookEventName)
+	if f.debugMode && len(event.SessionID) >= 8 {
+		header += fmt.Sprintf(" [Session: %s]", event.SessionID[:8])
+	}
+	header += fmt.Sprintf(": %s\n", event.ToolName)
+	output.WriteString(header)
+
+	if f.debugMode {
+		output.WriteString(fmt.Sprintf("  [DEBUG] Tool use: %s\n", event.ToolUseID))
+		output.WriteString(fmt.Sprintf("  [DEBUG] CWD: %s\n", event.CWD))
+	}
+
+	narration := f.narrateToolUse(event.ToolUseID, event.ToolName, event.ToolInput)
+	v := f.toolVerbosity(event.ToolName)
+	if narration = v.Narration(v.Console, narration); narration != "" {
+		output.WriteString(fmt.Sprintf("  💬 %s\n", narration))
+	}
+
+	return output.String()
+}
+
+// formatPostToolUseEvent formats PostToolUse events with how long the tool ran. Only
+// tools that ran for at least MinNarratedToolDuration are narrated.
+func (f *Formatter) formatPostToolUseEvent(event *NotificationEvent) string {
+	if event.ToolName == "" {
+		return ""
+	}
+
+	var output strings.Builder
+	header := fmt.Sprintf("[%s] ✅ %s", notificationTime(event).Format("15:04:05"), event.HookEventName)
+	if f.debugMode && len(event.SessionID) >= 8 {
+		header += fmt.Sprintf(" [Session: %s]", event.SessionID[:8])
+	}
+	header += ": " + event.ToolName
+	if event.Elapsed > 0 {
+		header += fmt.Sprintf(" (%s)", event.Elapsed.Round(time.Second))
+	}
+	output.WriteString(header + "\n")
+
+	if f.debugMode {
+		output.WriteString(fmt.Sprintf("  [DEBUG] Tool use: %s\n", event.ToolUseID))
+		output.WriteString(fmt.Sprintf("  [DEBUG] CWD: %s\n", event.CWD))
+	}
+
+	if event.Elapsed >= MinNarratedToolDuration {
+		if narration, _ := f.narrator.NarrateToolDuration(event.ToolName, event.Elapsed); narration != "" {
+			output.WriteString(fmt.Sprintf("  💬 %s\n", narration))
+		}
+	}
+
+	return output.String()
+}
+
 // formatSessionStartEvent formats SessionStart events
 func (f *Formatter) formatSessionStartEvent(event *NotificationEvent) string {
 	var output strings.Builder
@@ -452,7 +684,7 @@ func (f *Formatter) formatSessionStartEvent(event *NotificationEvent) string {
 	formattedMessage, _ := f.narrator.NarrateNotification(notificationType)
 
 	// Build header with optional debug info
-	header := fmt.Sprintf("[%s] %s %s", timeNow().Format("15:04:05"), emoji, event.HookEventName)
+	header := fmt.Sprintf("[%s] %s %s", notificationTime(event).Format("15:04:05"), emoji, event.HookEventName)
 	if f.debugMode && len(event.SessionID) >= 8 {
 		header += fmt.Sprintf(" [Session: %s]", event.SessionID[:8])
 	}
@@ -506,7 +738,7 @@ func (f *Formatter) formatGeneralNotificationEvent(event *NotificationEvent) str
 	}
 
 	// Build header with optional debug info
-	header := fmt.Sprintf("[%s] %s %s", timeNow().Format("15:04:05"), emoji, event.HookEventName)
+	header := fmt.Sprintf("[%s] %s %s", notificationTime(event).Format("15:04:05"), emoji, event.HookEventName)
 	if f.debugMode && len(event.SessionID) >= 8 {
 		header += fmt.Sprintf(" [Session: %s]", event.SessionID[:8])
 	}
@@ -526,6 +758,9 @@ func (f *Formatter) formatGeneralNotificationEvent(event *NotificationEvent) str
 		narration, _ := f.narrator.NarrateToolUsePermission(displayToolName)
 		if narration != "" {
 			output.WriteString(fmt.Sprintf("  💬 %s\n", narration))
+			f.notify("Permission request", narration)
+		} else {
+			f.notify("Permission request", formattedMessage)
 		}
 	} else if event.Message != "" {
 		// Use NarrateText for other notifications
@@ -538,10 +773,17 @@ func (f *Formatter) formatGeneralNotificationEvent(event *NotificationEvent) str
 	return output.String()
 }
 
+// permissionPrefix starts the message of a permission request notification
+const permissionPrefix = "Claude needs your permission to use "
+
+// IsPermissionRequest reports whether an event is a notification asking for tool permission
+func IsPermissionRequest(event Event) bool {
+	e, ok := event.(*NotificationEvent)
+	return ok && e.HookEventName == "Notification" && hasPrefix(e.Message, permissionPrefix)
+}
+
 // parsePermissionMessage parses permission messages to extract tool/MCP information
 func (f *Formatter) parsePermissionMessage(message string) (isPermission bool, toolName string, mcpName string, operation string) {
-	const permissionPrefix = "Claude needs your permission to use "
-
 	if !hasPrefix(message, permissionPrefix) {
 		return false, "", "", ""
 	}
@@ -650,10 +892,203 @@ func (f *Formatter) formatTaskCompletionMessage(event *TaskCompletionMessage) (s
 	output.WriteString(fmt.Sprintf("[%s] 💬 %s\n",
 		event.Timestamp.Format("15:04:05"),
 		narration))
+	f.notify("Task completed", narration)
+
+	if run := event.Sidechain; run != nil {
+		uses := "tool uses"
+		if run.ToolUses == 1 {
+			uses = "tool use"
+		}
+		output.WriteString(fmt.Sprintf("  ↳ %s: %d %s in %s\n", run.Agent(), run.ToolUses, uses, run.Elapsed().Round(time.Second)))
+		if summary, _ := f.narrator.NarrateSidechainSummary(run.Agent(), run.ToolUses, run.Elapsed()); summary != "" {
+			output.WriteString(fmt.Sprintf("  💬 %s\n", summary))
+		}
+	}
 
 	return output.String(), nil
 }
 
+// formatToolSLABreachMessage formats a tool that took longer than expected
+func (f *Formatter) formatToolSLABreachMessage(event *ToolSLABreachMessage) (string, error) {
+	var output strings.Builder
+	output.WriteString(fmt.Sprintf("[%s] ⏱️ SLA BREACH: %s took %s (expected at most %s, id: %s)\n",
+		event.Timestamp.Format("15:04:05"),
+		event.ToolName,
+		event.Elapsed.Round(time.Second),
+		event.Limit,
+		event.ToolUseID))
+
+	narration, _ := f.narrator.NarrateToolSLABreach(event.ToolName, event.Elapsed, event.Limit)
+	if narration != "" {
+		output.WriteString(fmt.Sprintf("  💬 %s\n", narration))
+	}
+	f.notify("Tool took too long", narration)
+
+	return output.String(), nil
+}
+
+// maxSummaryFiles is the number of edited files listed in a session summary
+const maxSummaryFiles = 5
+
+// formatSessionSummaryMessage formats the summary of a session that ended
+func (f *Formatter) formatSessionSummaryMessage(event *SessionSummaryMessage) (string, error) {
+	var output strings.Builder
+	output.WriteString(fmt.Sprintf("[%s] 📊 SESSION SUMMARY (%s): %s, %d files edited, %d commands, %d tasks, %d tokens\n",
+		event.Timestamp.Format("15:04:05"),
+		event.Reason,
+		event.Duration.Round(time.Second),
+		len(event.FilesEdited),
+		event.Commands,
+		event.TasksCompleted,
+		event.Tokens))
+
+	if len(event.FilesEdited) > 0 {
+		output.WriteString(fmt.Sprintf("  📁 %s\n", summaryFiles(event.FilesEdited)))
+	}
+
+	narration, _ := f.narrator.NarrateSessionSummary(narrator.SessionSummary{
+		Duration:       event.Duration,
+		FilesEdited:    len(event.FilesEdited),
+		Commands:       event.Commands,
+		Tokens:         event.Tokens,
+		TasksCompleted: event.TasksCompleted,
+	})
+	if narration != "" {
+		output.WriteString(fmt.Sprintf("  💬 %s\n", narration))
+	}
+
+	return output.String(), nil
+}
+
+// summaryFiles lists the names of the first edited files of a summary
+func summaryFiles(paths []string) string {
+	files := make([]string, 0, maxSummaryFiles)
+	for i, path := range paths {
+		if i == maxSummaryFiles {
+			files = append(files, fmt.Sprintf("and %d more", len(paths)-i))
+			break
+		}
+		files = append(files, filepath.Base(path))
+	}
+	return strings.Join(files, ", ")
+}
+
+// formatCatchUpMessage formats the events a transcript got while the companion was not running
+func (f *Formatter) formatCatchUpMessage(event *CatchUpMessage) (string, error) {
+	var output strings.Builder
+	output.WriteString(fmt.Sprintf("[%s] ⏩ CATCH-UP: %d events while stopped over %s, %d files edited, %d commands, %d tasks, %d tokens\n",
+		event.Timestamp.Format("15:04:05"),
+		event.Events,
+		event.Duration.Round(time.Second),
+		len(event.FilesEdited),
+		event.Commands,
+		event.TasksCompleted,
+		event.Tokens))
+	if len(event.FilesEdited) > 0 {
+		output.WriteString(fmt.Sprintf("  📁 %s\n", summaryFiles(event.FilesEdited)))
+	}
+	return output.String(), nil
+}
+
+// formatBudgetAlertMessage formats usage that crossed a threshold of its budget
+func (f *Formatter) formatBudgetAlertMessage(event *BudgetAlertMessage) (string, error) {
+	var output strings.Builder
+	output.WriteString(fmt.Sprintf("[%s] 💸 BUDGET: %s %s at %d%% (%s of %s)\n",
+		event.Timestamp.Format("15:04:05"),
+		event.Scope,
+		event.Metric,
+		int(event.Used*100/event.Limit),
+		budgetAmount(event.Metric, event.Used),
+		budgetAmount(event.Metric, event.Limit)))
+
+	narration, _ := f.narrator.NarrateBudgetAlert(narrator.BudgetAlert{
+		Scope:   event.Scope,
+		Metric:  event.Metric,
+		Used:    event.Used,
+		Limit:   event.Limit,
+		Percent: int(event.Used * 100 / event.Limit),
+	})
+	if narration != "" {
+		output.WriteString(fmt.Sprintf("  💬 %s\n", narration))
+	}
+	f.notify("Budget alert", narration)
+
+	return output.String(), nil
+}
+
+// budgetAmount formats a token count or a cost in USD
+func budgetAmount(metric string, amount float64) string {
+	if metric == BudgetCost {
+		return fmt.Sprintf("$%.2f", amount)
+	}
+	return fmt.Sprintf("%d tokens", int64(amount))
+}
+
+// formatContextAlertMessage formats a context that is filling up before its compaction
+func (f *Formatter) formatContextAlertMessage(event *ContextAlertMessage) (string, error) {
+	var output strings.Builder
+	output.WriteString(fmt.Sprintf("[%s] 🧠 CONTEXT: %d%% of the context window in use (%d of %d tokens, %s)\n",
+		event.Timestamp.Format("15:04:05"),
+		event.Percent,
+		event.Tokens,
+		event.Window,
+		event.Model))
+
+	narration, _ := f.narrator.NarrateContextAlert(event.Threshold, event.Percent)
+	if narration != "" {
+		output.WriteString(fmt.Sprintf("  💬 %s\n", narration))
+	}
+
+	return output.String(), nil
+}
+
+// formatSecurityAlertMessage formats a risky tool use that a security rule flagged
+func (f *Formatter) formatSecurityAlertMessage(event *SecurityAlertMessage) (string, error) {
+	var output strings.Builder
+	output.WriteString(fmt.Sprintf("[%s] 🚨 SECURITY: %s in %s (id: %s)\n",
+		event.Timestamp.Format("15:04:05"),
+		event.Rule,
+		event.ToolName,
+		event.ToolUseID))
+	if event.Target != "" {
+		output.WriteString(fmt.Sprintf("  🎯 %s\n", event.Target))
+	}
+	if event.Reason != "" {
+		output.WriteString(fmt.Sprintf("  ⚠️ %s\n", event.Reason))
+	}
+
+	narration, _ := f.narrator.NarrateSecurityAlert(narrator.SecurityAlert{
+		Rule:     event.Rule,
+		Reason:   event.Reason,
+		ToolName: event.ToolName,
+		Target:   event.Target,
+	})
+	if narration != "" {
+		output.WriteString(fmt.Sprintf("  💬 %s\n", narration))
+	}
+	f.notify("Security alert", narration)
+
+	return output.String(), nil
+}
+
+// notify sends a desktop notification if a notifier is set
+func (f *Formatter) notify(title, body string) {
+	if f.notifier == nil || f.notifyMuted || body == "" {
+		return
+	}
+	if err := f.notifier.Notify("Claude Companion: "+title, body); err != nil {
+		logger.LogError("Failed to send desktop notification: %v", err)
+	}
+}
+
+// notificationTime returns the time of a notification, or the current time if it has none
+func notificationTime(event *NotificationEvent) time.Time {
+	if event.Timestamp.IsZero() {
+		return timeNow()
+	}
+	return event.Timestamp
+}
+
 // timeNow is a helper function to get current time (for testing)
 var timeNow = time.Now
 
@@ -662,6 +1097,8 @@ const (
 	MaxMainTextLines = 30
 	// MaxCodePreviewLines is the maximum number of lines to show in code block preview
 	MaxCodePreviewLines = 5
+	// MinNarratedToolDuration is how long a tool must run for its completion to be narrated
+	MinNarratedToolDuration = 10 * time.Second
 	// MaxNormalTextLines is the maximum number of lines to show for normal text without code blocks
 	MaxNormalTextLines = 30
 )
@@ -715,12 +1152,51 @@ func (f *Formatter) ExtractCodeBlocks(text string) []CodeBlock {
 	return blocks
 }
 
-// FormatToolUse formats tool usage for companion display
+// toolBatcher returns the narrator that can speak several tool uses as one, or nil
+func (f *Formatter) toolBatcher() narrator.ToolBatcher {
+	if f.recorder == nil {
+		return nil
+	}
+	batcher, _ := f.recorder.Narrator.(narrator.ToolBatcher)
+	return batcher
+}
+
+// countToolUses counts the tool uses in message content
+func countToolUses(content []AssistantContent) int {
+	n := 0
+	for _, c := range content {
+		if c.Type == "tool_use" {
+			n++
+		}
+	}
+	return n
+}
+
+// narrateToolUse narrates a tool use once per tool use ID. When the hook and the
+// transcript both report the same tool use, the later one reuses the first narration
+// without narrating (and speaking) it again.
+func (f *Formatter) narrateToolUse(toolUseID, toolName string, input map[string]interface{}) string {
+	if narration, ok := f.dedupe.lookup(toolUseID); ok {
+		return narration
+	}
+	narration, _ := f.narrator.NarrateToolUse(toolName, input)
+	f.dedupe.store(toolUseID, narration)
+	return narration
+}
+
+// toolVerbosity returns how much of a tool is narrated on each output
+func (f *Formatter) toolVerbosity(toolName string) narrator.ToolVerbosity {
+	if f.recorder == nil {
+		return narrator.DetailedVerbosity
+	}
+	return narrator.VerbosityOf(f.recorder.Narrator, toolName)
+}
+
+// FormatToolUse formats tool usage for companion display. A tool whose console
+// verbosity is short shows its short message only, and a silent one nothing.
 func (f *Formatter) FormatToolUse(toolName string, meta EventMeta, input map[string]interface{}) string {
 	f.currentTool = toolName
 
-	var output strings.Builder
-
 	// Create a copy of input for potential modifications
 	modifiedInput := make(map[string]interface{})
 	for k, v := range input {
@@ -735,9 +1211,32 @@ func (f *Formatter) FormatToolUse(toolName string, meta EventMeta, input map[str
 	}
 
 	// Use narrator with potentially modified input
-	narration, _ := f.narrator.NarrateToolUse(toolName, modifiedInput)
+	narration := f.narrateToolUse(meta.ToolID, toolName, modifiedInput)
+	v := f.toolVerbosity(toolName)
+	if v.Console == narrator.VerbositySilent {
+		return ""
+	}
+	builtin := f.formatToolUse(toolName, meta, input, narration, v)
+	return f.theme.render(ThemeToolUse, ThemeItem{
+		Kind:      ThemeToolUse,
+		Default:   builtin,
+		Narration: narration,
+		Tool:      toolName,
+		ToolID:    meta.ToolID,
+		Target:    toolTarget(meta, input),
+		Input:     input,
+	}, builtin)
+}
+
+// formatToolUse is the built-in format of a tool use
+func (f *Formatter) formatToolUse(toolName string, meta EventMeta, input map[string]interface{}, narration string, v narrator.ToolVerbosity) string {
+	var output strings.Builder
+	if v.Console == narrator.VerbosityShort {
+		return fmt.Sprintf("  💬 %s\n", v.Narration(v.Console, narration))
+	}
 	if narration != "" {
-		output.WriteString(fmt.Sprintf("  💬 %s", narration))
+		line := fmt.Sprintf("  💬 %s", narration)
+		output.WriteString(strings.TrimSuffix(f.withDetail(line, toolDetail(toolName, meta, input)), "\n"))
 		// Track file operations for summary
 		if toolName == "Read" || toolName == "Write" || toolName == "Edit" || toolName == "MultiEdit" {
 			if path, ok := input["file_path"].(string); ok {
@@ -848,7 +1347,13 @@ func (f *Formatter) FormatToolUse(toolName string, meta EventMeta, input map[str
 
 	// Show detailed input for debugging (optional)
 	if len(input) > 0 && toolName != "TodoWrite" {
-		output.WriteString(fmt.Sprintf(" (id: %s)", meta.ToolID))
+		if f.twoColumn() {
+			line := output.String()
+			output.Reset()
+			output.WriteString(strings.TrimSuffix(f.withDetail(line, meta.ToolID), "\n"))
+		} else {
+			output.WriteString(fmt.Sprintf(" (id: %s)", meta.ToolID))
+		}
 	}
 
 	return output.String() + "\n"
@@ -876,9 +1381,11 @@ func (f *Formatter) FormatAssistantText(text string, isThinking bool) string {
 		}
 	}
 
-	// Narrate the text
+	// Narrate the text; thinking may not be narrated at all under the thinking policy
 	narrated, _ := f.narrator.NarrateText(processedText, isThinking)
-	output.WriteString(fmt.Sprintf("  💬 %s\n", narrated))
+	if narrated != "" || !isThinking {
+		output.WriteString(fmt.Sprintf("  💬 %s\n", narrated))
+	}
 
 	// Show the main text (only if multiple lines)
 	lines := strings.Split(strings.TrimSpace(processedText), "\n")
@@ -933,7 +1440,12 @@ func (f *Formatter) FormatAssistantText(text string, isThinking bool) string {
 		}
 	}
 
-	return output.String()
+	kind := ThemeText
+	if isThinking {
+		kind = ThemeThinking
+	}
+	builtin := output.String()
+	return f.theme.render(kind, ThemeItem{Kind: kind, Default: builtin, Text: processedText, Narration: narrated}, builtin)
 }
 
 // GetFileSummary returns a summary of file operations performed
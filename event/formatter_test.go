@@ -204,3 +204,199 @@ func TestExtractCodeBlocks(t *testing.T) {
 		})
 	}
 }
+
+// recordingNotifier records the notifications it is asked to show
+type recordingNotifier struct {
+	titles []string
+	bodies []string
+}
+
+func (n *recordingNotifier) Notify(title, body string) error {
+	n.titles = append(n.titles, title)
+	n.bodies = append(n.bodies, body)
+	return nil
+}
+
+func TestFormatterDesktopNotifications(t *testing.T) {
+	tests := []struct {
+		name      string
+		event     Event
+		wantTitle string
+		wantBody  string
+	}{
+		{
+			name: "permission request",
+			event: &NotificationEvent{
+				HookEventName: "Notification",
+				Message:       "Claude needs your permission to use Bash",
+			},
+			wantTitle: "Claude Companion: Permission request",
+			wantBody:  "mock-permission-Bash",
+		},
+		{
+			name: "task completion",
+			event: &TaskCompletionMessage{
+				TaskInfo: TaskInfo{Description: "run tests", SubagentType: "tester"},
+			},
+			wantTitle: "Claude Companion: Task completed",
+			wantBody:  "tester agentがタスク「run tests」を完了しました",
+		},
+		{
+			name: "other notification",
+			event: &NotificationEvent{
+				HookEventName: "Notification",
+				Message:       "Claude is waiting for your input",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			notifier := &recordingNotifier{}
+			formatter := NewFormatter(&mockNarrator{})
+			formatter.SetNotifier(notifier)
+
+			if _, err := formatter.Format(tt.event); err != nil {
+				t.Fatalf("Format() error = %v", err)
+			}
+
+			if tt.wantTitle == "" {
+				if len(notifier.titles) != 0 {
+					t.Errorf("unexpected notifications: %v", notifier.titles)
+				}
+				return
+			}
+			if len(notifier.titles) != 1 {
+				t.Fatalf("got %d notifications, want 1", len(notifier.titles))
+			}
+			if notifier.titles[0] != tt.wantTitle || notifier.bodies[0] != tt.wantBody {
+				t.Errorf("got %q / %q, want %q / %q", notifier.titles[0], notifier.bodies[0], tt.wantTitle, tt.wantBody)
+			}
+		})
+	}
+}
+
+func TestFormatterNarrations(t *testing.T) {
+	formatter := NewFormatter(&mockNarrator{})
+	permission := &NotificationEvent{
+		HookEventName: "Notification",
+		Message:       "Claude needs your permission to use Bash",
+	}
+	if _, err := formatter.Format(permission); err != nil {
+		t.Fatalf("Format() error = %v", err)
+	}
+	if got := formatter.Narrations(); len(got) != 1 || got[0] != "mock-permission-Bash" {
+		t.Errorf("Narrations() = %v, want [mock-permission-Bash]", got)
+	}
+
+	// Each Format call starts over
+	summary := &SummaryEvent{Summary: "done"}
+	if _, err := formatter.Format(summary); err != nil {
+		t.Fatalf("Format() error = %v", err)
+	}
+	if got := formatter.Narrations(); len(got) != 0 {
+		t.Errorf("Narrations() = %v, want none", got)
+	}
+}
+
+func TestFormatToolUseVerbosity(t *testing.T) {
+	formatter := NewFormatter(narrator.NewRuleBasedNarrator(&narrator.NarratorConfig{
+		Rules: map[string]narrator.ToolRules{
+			"Read":      {Verbosity: &narrator.VerbosityRule{Console: narrator.VerbositySilent}},
+			"Grep":      {Verbosity: &narrator.VerbosityRule{Console: narrator.VerbosityShort, Stream: narrator.VerbositySilent}},
+			"TodoWrite": {Verbosity: &narrator.VerbosityRule{Stream: narrator.VerbosityShort}},
+		},
+	}))
+	todos := []interface{}{map[string]interface{}{"content": "Write tests", "status": "pending"}}
+	tests := []struct {
+		name           string
+		tool           string
+		input          map[string]interface{}
+		want           string
+		wantNarrations []string
+	}{
+		{"silent on console", "Read", map[string]interface{}{"file_path": "/src/main.go"}, "", []string{"Goファイル「main.go」を読み込みます"}},
+		{"short on console", "Grep", map[string]interface{}{"pattern": "TODO"}, "  💬 ファイルを検索します\n", nil},
+		{"short on stream", "TodoWrite", map[string]interface{}{"todos": todos}, "    1. ⏳ Write tests", []string{"TODOリストを更新します"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			formatter.recorder.narrations = nil
+			got := formatter.FormatToolUse(tt.tool, EventMeta{}, tt.input)
+			if tt.want == "" && got != "" || !strings.Contains(got, tt.want) {
+				t.Errorf("FormatToolUse() = %q, want %q", got, tt.want)
+			}
+			if narrations := formatter.Narrations(); strings.Join(narrations, "|") != strings.Join(tt.wantNarrations, "|") {
+				t.Errorf("Narrations() = %v, want %v", narrations, tt.wantNarrations)
+			}
+		})
+	}
+}
+
+// batchingNarrator records the tool uses narrated inside and outside of tool batches
+type batchingNarrator struct {
+	mockNarrator
+	batching bool
+	calls    []string
+}
+
+func (n *batchingNarrator) BeginToolBatch() {
+	n.batching = true
+	n.calls = append(n.calls, "begin")
+}
+
+func (n *batchingNarrator) EndToolBatch() {
+	n.batching = false
+	n.calls = append(n.calls, "end")
+}
+
+func (n *batchingNarrator) NarrateToolUse(toolName string, input map[string]interface{}) (string, bool) {
+	n.calls = append(n.calls, toolName)
+	return n.mockNarrator.NarrateToolUse(toolName, input)
+}
+
+func TestFormatterBatchesToolUses(t *testing.T) {
+	tests := []struct {
+		name      string
+		content   []AssistantContent
+		wantCalls []string
+	}{
+		{
+			name: "several tool uses",
+			content: []AssistantContent{
+				{Type: "text", Text: "Let me look"},
+				{Type: "tool_use", ID: "t1", Name: "Read", Input: map[string]interface{}{"file_path": "/a.go"}},
+				{Type: "tool_use", ID: "t2", Name: "Read", Input: map[string]interface{}{"file_path": "/b.go"}},
+				{Type: "tool_use", ID: "t3", Name: "Bash", Input: map[string]interface{}{"command": "go test"}},
+			},
+			wantCalls: []string{"begin", "Read", "Read", "Bash", "end"},
+		},
+		{
+			name: "single tool use",
+			content: []AssistantContent{
+				{Type: "tool_use", ID: "t4", Name: "Read", Input: map[string]interface{}{"file_path": "/a.go"}},
+			},
+			wantCalls: []string{"Read"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			n := &batchingNarrator{}
+			formatter := NewFormatter(n)
+			event := &AssistantMessage{}
+			event.Message.Model = "claude"
+			event.Message.Content = tt.content
+			output, err := formatter.Format(event)
+			if err != nil {
+				t.Fatalf("Format() error = %v", err)
+			}
+			if strings.Join(n.calls, ",") != strings.Join(tt.wantCalls, ",") {
+				t.Errorf("calls = %v, want %v", n.calls, tt.wantCalls)
+			}
+			// The console still shows every tool use
+			if got, want := strings.Count(output, "mock-narrate-"), countToolUses(tt.content); got != want {
+				t.Errorf("output has %d tool narrations, want %d:\n%s", got, want, output)
+			}
+		})
+	}
+}
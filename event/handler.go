@@ -2,11 +2,13 @@ package event
 
 import (
 	"fmt"
+	"strings"
 	"sync"
 	"time"
 
 	"github.com/kazegusuri/claude-companion/logger"
 	"github.com/kazegusuri/claude-companion/narrator"
+	"github.com/kazegusuri/claude-companion/notify"
 )
 
 // BufferInfo holds information about buffered events for a session
@@ -23,6 +25,16 @@ type FormatterInterface interface {
 	SetDebugMode(debug bool)
 }
 
+// EventRecorder persists parsed events
+type EventRecorder interface {
+	RecordEvent(event Event) error
+}
+
+// EventSink receives processed events together with their formatted output
+type EventSink interface {
+	HandleEvent(event Event, formatted string)
+}
+
 // Handler processes events from multiple sources
 type Handler struct {
 	narrator    narrator.Narrator
@@ -32,6 +44,23 @@ type Handler struct {
 	wg          sync.WaitGroup
 	done        chan struct{}
 	taskTracker *TaskTracker
+	slaTracker  *ToolSLATracker
+	sidechains  *SidechainTracker  // nil unless sidechains are shown
+	summaries   *SessionSummarizer // nil unless sessions are summarized
+	budgets     *BudgetTracker     // nil unless budgets are set
+	contexts    *ContextTracker    // nil unless context alerts are set
+	actions     *Actions           // nil unless an actions file is loaded
+	security    *SecurityWatchdog  // nil unless risky tool uses are flagged
+	redactor    *Redactor          // nil unless secrets are redacted
+	durations   *ToolDurationTracker
+	sequencer   *Sequencer
+	scorer      narrator.PriorityScorer
+	recorder    EventRecorder
+	filter      *EventFilter
+	sessions    *SessionRegistry
+	projects    *ProjectConfigs
+	project     *ProjectConfig // Config of the project of the event being processed
+	sinks       []EventSink
 
 	// Buffering support
 	bufferMutex sync.Mutex
@@ -51,20 +80,184 @@ func NewHandler(narrator narrator.Narrator, debugMode bool) *Handler {
 		eventChan:   make(chan Event, 100),
 		done:        make(chan struct{}),
 		taskTracker: taskTracker,
+		durations:   NewToolDurationTracker(),
+		sequencer:   NewSequencer(time.Local),
+		scorer:      newDefaultPriorityScorer(),
 		buffers:     make(map[string]*BufferInfo),
 	}
 }
 
+// SetLayout sets the console output layout
+func (h *Handler) SetLayout(layout Layout, width func() int) {
+	if f, ok := h.formatter.(*Formatter); ok {
+		f.SetLayout(layout, width)
+	}
+}
+
+// SetColors colors the console output with palette; nil prints it without colors
+func (h *Handler) SetColors(palette Palette) {
+	if f, ok := h.formatter.(*Formatter); ok {
+		f.SetColors(palette)
+	}
+}
+
+// SetWrap fits the console output to the terminal width
+func (h *Handler) SetWrap(enabled bool) {
+	if f, ok := h.formatter.(*Formatter); ok {
+		f.SetWrap(enabled)
+	}
+}
+
+// SetAccessible enables or disables emoji-free accessible console output
+func (h *Handler) SetAccessible(enabled bool) {
+	if f, ok := h.formatter.(*Formatter); ok {
+		f.SetAccessible(enabled)
+	}
+}
+
+// SetTheme renders console output with the templates of theme; nil keeps the built-in format
+func (h *Handler) SetTheme(theme *Theme) {
+	if f, ok := h.formatter.(*Formatter); ok {
+		f.SetTheme(theme)
+	}
+}
+
+// SetAttachmentDir saves the images and documents attached to messages under dir
+func (h *Handler) SetAttachmentDir(dir string) {
+	if f, ok := h.formatter.(*Formatter); ok {
+		f.SetAttachmentDir(dir)
+	}
+}
+
+// SetNotifier sets the notifier for permission requests and task completions
+func (h *Handler) SetNotifier(notifier notify.Notifier) {
+	if f, ok := h.formatter.(*Formatter); ok {
+		f.SetNotifier(notifier)
+	}
+}
+
+// SetPriorityScorer sets the scorer that assigns a priority to each event
+func (h *Handler) SetPriorityScorer(scorer narrator.PriorityScorer) {
+	h.scorer = scorer
+}
+
+// SetEventRecorder sets the recorder that every parsed event is written to
+func (h *Handler) SetEventRecorder(recorder EventRecorder) {
+	h.recorder = recorder
+}
+
+// SetToolSLAs sets the expected maximum duration per tool name.
+// A tool result that arrives later than that emits a ToolSLABreachMessage.
+func (h *Handler) SetToolSLAs(limits map[string]time.Duration) {
+	if len(limits) == 0 {
+		h.slaTracker = nil
+		return
+	}
+	h.slaTracker = NewToolSLATracker(limits)
+}
+
+// SetShowSidechains shows the events of Task subagents indented under their Task,
+// attributed to the subagent, instead of ignoring them. They are not spoken; the
+// completion of the Task narrates a summary of what the subagent did.
+func (h *Handler) SetShowSidechains(enabled bool) {
+	if !enabled {
+		h.sidechains = nil
+		return
+	}
+	h.sidechains = NewSidechainTracker(h.taskTracker)
+}
+
+// SetSessionSummary summarizes each session when it ends, on the SessionEnd hook or
+// once it has been idle for idle. Zero turns the summaries off.
+func (h *Handler) SetSessionSummary(idle time.Duration) {
+	if h.summaries != nil {
+		h.summaries.Stop()
+		h.summaries = nil
+	}
+	if idle <= 0 {
+		return
+	}
+	h.summaries = NewSessionSummarizer(idle, func(summary *SessionSummaryMessage) {
+		h.SendEvent(summary)
+	})
+}
+
+// SetBudget alerts when the usage of a session or of the day reaches 80% and 100%
+// of budget, taking the usage of sessions from sessions and estimating the costs of
+// days with cost. A budget without limits turns the alerts off.
+func (h *Handler) SetBudget(budget Budget, sessions SessionUsageFunc, cost CostFunc) {
+	if !budget.Enabled() {
+		h.budgets = nil
+		return
+	}
+	h.budgets = NewBudgetTracker(budget, sessions, cost)
+}
+
+// SetContextAlerts announces when the context of a session reaches each of thresholds,
+// percentages of the context window that window returns for a model. No thresholds
+// turn the alerts off.
+func (h *Handler) SetContextAlerts(thresholds []int, window func(model string) int) {
+	if len(thresholds) == 0 {
+		h.contexts = nil
+		return
+	}
+	h.contexts = NewContextTracker(thresholds, window)
+}
+
+// SetActions runs the commands and webhooks of the rules of actions for the events
+// that match them, whether or not the events are shown
+func (h *Handler) SetActions(actions *Actions) {
+	h.actions = actions
+}
+
+// SetSecurityWatchdog flags the risky tool uses that the rules of watchdog match,
+// including those of subagents, with a security alert; nil turns the alerts off
+func (h *Handler) SetSecurityWatchdog(watchdog *SecurityWatchdog) {
+	h.security = watchdog
+}
+
+// SetRedactor replaces the secrets of every event with redactor before the event is
+// recorded, shown, narrated or sent to the sinks; nil keeps events as they are
+func (h *Handler) SetRedactor(redactor *Redactor) {
+	h.redactor = redactor
+}
+
+// SetEventFilter sets the filter that decides which events are shown and narrated
+func (h *Handler) SetEventFilter(filter *EventFilter) {
+	h.filter = filter
+}
+
+// SetSessionRegistry sets the registry that tracks every session seen. Sessions whose
+// SessionStart hook reported a fresh start are not treated as a resumed session's replay.
+func (h *Handler) SetSessionRegistry(sessions *SessionRegistry) {
+	h.sessions = sessions
+}
+
+// SetProjectConfigs sets the resolver of per-project configs, which override the
+// narrator rules, voice, filters and notifications for the events of a project
+func (h *Handler) SetProjectConfigs(projects *ProjectConfigs) {
+	h.projects = projects
+}
+
+// AddSink adds a sink that receives every formatted event
+func (h *Handler) AddSink(sink EventSink) {
+	h.sinks = append(h.sinks, sink)
+}
+
 // Start begins processing events
 func (h *Handler) Start() {
 	h.wg.Add(1)
 	go h.processEvents()
 }
 
-// Stop stops the event handler
+// Stop stops the event handler after processing the queued events. The event
+// channel is left open, so events sent while stopping are discarded rather than
+// sent on a closed channel.
 func (h *Handler) Stop() {
+	if h.summaries != nil {
+		h.summaries.Stop()
+	}
 	close(h.done)
-	close(h.eventChan)
 	h.wg.Wait()
 }
 
@@ -77,25 +270,24 @@ func (h *Handler) SendEvent(event Event) {
 	}
 }
 
+// QueueSize returns the number of events waiting to be processed
+func (h *Handler) QueueSize() int {
+	return len(h.eventChan)
+}
+
 // processEvents processes events from the channel
 func (h *Handler) processEvents() {
 	defer h.wg.Done()
 
 	for {
 		select {
-		case event, ok := <-h.eventChan:
-			if !ok {
-				return
-			}
+		case event := <-h.eventChan:
 			h.processEvent(event)
 		case <-h.done:
 			// Drain remaining events
 			for {
 				select {
-				case event, ok := <-h.eventChan:
-					if !ok {
-						return
-					}
+				case event := <-h.eventChan:
 					h.processEvent(event)
 				default:
 					return
@@ -107,82 +299,150 @@ func (h *Handler) processEvents() {
 
 // processEvent processes a single event based on its type
 func (h *Handler) processEvent(event Event) {
+	// Redact secrets before the event leaves the handler in any form
+	if h.redactor != nil {
+		h.redactor.RedactEvent(event)
+	}
+
+	// Persist every parsed event before any filtering
+	if h.recorder != nil {
+		if err := h.recorder.RecordEvent(event); err != nil {
+			logger.LogError("Error recording event: %v", err)
+		}
+	}
+	if h.sessions != nil {
+		h.sessions.Observe(event)
+	}
+
 	// Check if event should be buffered or if it releases buffered events
 	if h.handleBuffering(event) {
 		return // Event was buffered or handled
 	}
 
-	// Check if the event should be ignored (sidechain events)
-	switch e := event.(type) {
-	case *UserMessage:
-		if e.IsSidechain {
-			if h.debugMode {
-				logger.LogInfo("Ignoring sidechain UserMessage")
+	// Actions also run for the events of subagents and for filtered events
+	if h.actions != nil {
+		h.actions.Observe(event)
+	}
+	// Alert on risky tool uses after the message that made them, subagents' included
+	if alerts := h.checkSecurity(event); len(alerts) > 0 {
+		defer func() {
+			for _, alert := range alerts {
+				h.processEvent(alert)
 			}
+		}()
+	}
+
+	// Sidechain events come from the subagents of Task tool uses; they are ignored
+	// unless sidechains are shown
+	var sidechain *SidechainRun
+	if isSidechain(event) {
+		if h.sidechains == nil {
+			logger.LogDebug("Ignoring sidechain %T", event)
 			return
 		}
-	case *AssistantMessage:
-		if e.IsSidechain {
-			if h.debugMode {
-				logger.LogInfo("Ignoring sidechain AssistantMessage")
+		sidechain = h.sidechains.Attribute(event)
+	} else {
+		if h.summaries != nil {
+			// Summarize the session after the event that ended it
+			if summary := h.summaries.Observe(event); summary != nil {
+				defer h.processEvent(summary)
 			}
-			return
 		}
-	case *SystemMessage:
-		if e.IsSidechain {
-			if h.debugMode {
-				logger.LogInfo("Ignoring sidechain SystemMessage")
+		// Count usage against the budgets whether or not the message is shown
+		if msg, ok := event.(*AssistantMessage); ok && h.budgets != nil {
+			if alerts := h.budgets.Observe(msg); len(alerts) > 0 {
+				defer func() {
+					for _, alert := range alerts {
+						h.processEvent(alert)
+					}
+				}()
 			}
-			return
 		}
-	case *HookEvent:
-		if e.IsSidechain {
-			if h.debugMode {
-				logger.LogInfo("Ignoring sidechain HookEvent")
-			}
-			return
+		// Announce a filling context after the message that filled it
+		if alert := h.observeContext(event); alert != nil {
+			defer h.processEvent(alert)
 		}
-	case *BaseEvent:
-		if e.IsSidechain {
-			if h.debugMode {
-				logger.LogInfo("Ignoring sidechain BaseEvent")
-			}
-			return
+	}
+
+	// Drop filtered events before they are formatted and narrated
+	h.project = h.resolveProject(event)
+	event, ok := h.applyFilter(event)
+	if !ok {
+		return
+	}
+
+	// Normalize time zones and keep displayed times monotonic across sources
+	if h.sequencer != nil {
+		if stamp, ok := h.sequencer.Apply(event); ok && stamp.Regressed && h.debugMode {
+			logger.LogInfo("Timestamp of %T regressed; ordered by arrival (seq %d)", event, stamp.Seq)
 		}
 	}
 
+	if isSidechain(event) {
+		h.emitSidechain(event, sidechain)
+		return
+	}
+
+	h.setNarratorSession(event)
+	h.noteUserInteraction(event)
+	h.assignPriority(event)
+
 	switch e := event.(type) {
 	case *NotificationEvent:
+		// Pair tool hooks to report how long tools ran
+		switch e.HookEventName {
+		case "PreToolUse":
+			h.durations.Start(e)
+		case "PostToolUse":
+			if elapsed, ok := h.durations.Finish(e); ok {
+				e.Elapsed = elapsed
+			}
+		}
 		// Process notification events
 		output, err := h.formatter.Format(e)
 		if err != nil {
 			logger.LogError("Error formatting NotificationEvent: %v", err)
 			return
 		}
-		if output != "" {
-			fmt.Print(output)
-		}
+		h.emit(e, output)
 	case *AssistantMessage:
 		// Track Task tool uses
 		h.trackTaskToolUses(e)
+		if h.slaTracker != nil {
+			h.slaTracker.Start(e)
+		}
 		// Format and display
 		output, err := h.formatter.Format(e)
 		if err != nil {
 			logger.LogError("Error formatting AssistantMessage: %v", err)
 			return
 		}
-		if output != "" {
-			fmt.Print(output)
-		}
+		h.emit(e, output)
 	case *UserMessage:
 		// Check if this is a Task result and create TaskCompletionMessage
-		if taskCompletion := h.checkTaskResultFromUser(e); taskCompletion != nil {
+		if taskCompletion := h.checkTaskResultFromUser(e); taskCompletion != nil && h.allowed(taskCompletion) {
+			h.assignPriority(taskCompletion)
 			// Process the task completion event
 			output, err := h.formatter.Format(taskCompletion)
 			if err != nil {
 				logger.LogError("Error formatting TaskCompletionMessage: %v", err)
-			} else if output != "" {
-				fmt.Print(output)
+			} else {
+				h.emit(taskCompletion, output)
+			}
+		}
+		// Report tools whose results arrived later than expected
+		if h.slaTracker != nil {
+			for _, breach := range h.slaTracker.Finish(e) {
+				if !h.allowed(breach) {
+					continue
+				}
+				h.assignPriority(breach)
+				output, err := h.formatter.Format(breach)
+				if err != nil {
+					logger.LogError("Error formatting ToolSLABreachMessage: %v", err)
+					continue
+				}
+				h.emit(breach, output)
 			}
 		}
 		// Normal formatting
@@ -191,26 +451,191 @@ func (h *Handler) processEvent(event Event) {
 			logger.LogError("Error formatting UserMessage: %v", err)
 			return
 		}
-		if output != "" {
-			fmt.Print(output)
-		}
-	case *SystemMessage, *HookEvent, *SummaryEvent, *BaseEvent, *TaskCompletionMessage:
+		h.emit(e, output)
+	case *SystemMessage, *HookEvent, *SummaryEvent, *BaseEvent, *TaskCompletionMessage, *SessionSummaryMessage, *CatchUpMessage, *BudgetAlertMessage, *ContextAlertMessage, *SecurityAlertMessage:
 		// Format and display parsed events
 		output, err := h.formatter.Format(e)
 		if err != nil {
 			logger.LogError("Error formatting %T: %v", e, err)
 			return
 		}
-		if output != "" {
-			fmt.Print(output)
+		h.emit(e, output)
+	default:
+		logger.LogDebug("Unknown event type: %T", event)
+	}
+}
+
+// observeContext follows the context of the session of an event and returns an alert
+// when it crosses a threshold. A PreCompact hook starts the session's context over.
+func (h *Handler) observeContext(event Event) *ContextAlertMessage {
+	if h.contexts == nil {
+		return nil
+	}
+	switch e := event.(type) {
+	case *AssistantMessage:
+		return h.contexts.Observe(e)
+	case *NotificationEvent:
+		if e.HookEventName == "PreCompact" {
+			h.contexts.Compacted(e.SessionID)
+		}
+	}
+	return nil
+}
+
+// checkSecurity returns an alert for each risky tool use of an assistant message,
+// applying the rules of the project of its working directory
+func (h *Handler) checkSecurity(event Event) []*SecurityAlertMessage {
+	msg, ok := event.(*AssistantMessage)
+	if !ok || h.security == nil {
+		return nil
+	}
+	cwd := msg.CWD
+	if cwd == "" && h.sessions != nil {
+		if state, ok := h.sessions.GetSession(SessionIDOf(event)); ok {
+			cwd = state.CWD
+		}
+	}
+	return h.security.Check(msg, cwd)
+}
+
+// applyFilter returns the event to process, or false if the event filter or the
+// current project's filter drops it
+func (h *Handler) applyFilter(event Event) (Event, bool) {
+	for _, filter := range []*EventFilter{h.filter, h.project.Filter()} {
+		if filter == nil {
+			continue
+		}
+		var ok bool
+		if event, ok = filter.Apply(event); !ok {
+			return nil, false
 		}
+	}
+	return event, true
+}
+
+// resolveProject returns the project config for the working directory of an event,
+// taken from the session registry for events that do not carry one
+func (h *Handler) resolveProject(event Event) *ProjectConfig {
+	if h.projects == nil {
+		return nil
+	}
+	var cwd string
+	if e, ok := event.(*NotificationEvent); ok {
+		cwd = e.CWD
+	} else if base := BaseOf(event); base != nil {
+		cwd = base.CWD
+	}
+	if cwd == "" && h.sessions != nil {
+		if state, ok := h.sessions.GetSession(SessionIDOf(event)); ok {
+			cwd = state.CWD
+		}
+	}
+	return h.projects.For(cwd)
+}
+
+// allowed reports whether an event created by the handler passes the event filter
+func (h *Handler) allowed(event Event) bool {
+	_, ok := h.applyFilter(event)
+	return ok
+}
+
+// setNarratorSession tells a session-aware narrator which session the next narrations
+// belong to, and applies the overrides of the session's project
+func (h *Handler) setNarratorSession(event Event) {
+	if pa, ok := h.narrator.(narrator.ProjectAware); ok {
+		pa.SetProjectOverrides(h.project.Overrides())
+	}
+	if f, ok := h.formatter.(*Formatter); ok {
+		f.SetNotifyMuted(!h.project.DesktopNotify())
+	}
+
+	sa, ok := h.narrator.(narrator.SessionAware)
+	if !ok {
+		return
+	}
+	if session := SessionOf(event); session != nil {
+		sa.SetSession(session.Project, session.Session)
+	} else {
+		sa.SetSession("", "")
+	}
+}
+
+// noteUserInteraction tells an interaction-aware narrator that the user sent a prompt
+// to the event's session
+func (h *Handler) noteUserInteraction(event Event) {
+	ia, ok := h.narrator.(narrator.InteractionAware)
+	if !ok {
+		return
+	}
+	var cwd string
+	switch e := event.(type) {
+	case *UserMessage:
+		prompt, _ := e.Message.Content.(string)
+		if strings.TrimSpace(prompt) == "" {
+			return // Tool results
+		}
+		cwd = e.CWD
+	case *NotificationEvent:
+		if e.HookEventName != "UserPromptSubmit" {
+			return
+		}
+		cwd = e.CWD
 	default:
-		if h.debugMode {
-			logger.LogWarning("Unknown event type: %T", event)
+		return
+	}
+	if session := SessionOf(event); session != nil {
+		ia.UserInteraction(session.Project, session.Session, cwd)
+	}
+}
+
+// assignPriority scores an event and stores the priority on it for the sinks
+func (h *Handler) assignPriority(event Event) {
+	if h.scorer == nil {
+		return
+	}
+	if p := priorityOf(event); p != nil {
+		*p = ScorePriority(event, h.scorer)
+	}
+}
+
+// emit prints the formatted output of an event and dispatches it to the sinks. The
+// sinks get the output without the colors and line breaks fitted to the console.
+func (h *Handler) emit(event Event, output string) {
+	if output == "" {
+		return
+	}
+	fmt.Print(output)
+	plain := output
+	if f, ok := h.formatter.(*Formatter); ok {
+		if p := narrationsOf(event); p != nil {
+			*p = f.Narrations()
 		}
+		plain = f.Plain()
+	}
+	for _, sink := range h.sinks {
+		sink.HandleEvent(event, plain)
 	}
 }
 
+// emitSidechain prints a subagent event indented under its Task, without speaking it
+func (h *Handler) emitSidechain(event Event, run *SidechainRun) {
+	f, ok := h.formatter.(*Formatter)
+	if !ok {
+		return
+	}
+	h.assignPriority(event)
+	agent := defaultSubagentType
+	if run != nil {
+		agent = run.Agent()
+	}
+	output, err := f.FormatSidechain(event, agent)
+	if err != nil {
+		logger.LogError("Error formatting sidechain %T: %v", event, err)
+		return
+	}
+	h.emit(event, output)
+}
+
 // trackTaskToolUses tracks Task tool uses from AssistantMessage
 func (h *Handler) trackTaskToolUses(msg *AssistantMessage) {
 	for _, content := range msg.Message.Content {
@@ -219,6 +644,7 @@ func (h *Handler) trackTaskToolUses(msg *AssistantMessage) {
 			if inputMap, ok := content.Input.(map[string]interface{}); ok {
 				description := ""
 				subagentType := ""
+				prompt := ""
 
 				if desc, ok := inputMap["description"].(string); ok {
 					description = desc
@@ -226,14 +652,15 @@ func (h *Handler) trackTaskToolUses(msg *AssistantMessage) {
 				if agent, ok := inputMap["subagent_type"].(string); ok {
 					subagentType = agent
 				}
+				if p, ok := inputMap["prompt"].(string); ok {
+					prompt = strings.TrimSpace(p)
+				}
 
 				// Track the Task execution
-				h.taskTracker.TrackTask(content.ID, description, subagentType)
+				h.taskTracker.TrackTask(content.ID, description, subagentType, prompt)
 
-				if h.debugMode {
-					logger.LogInfo("Tracking Task: ID=%s, Description=%s, Agent=%s",
-						content.ID, description, subagentType)
-				}
+				logger.LogDebug("Tracking Task: ID=%s, Description=%s, Agent=%s",
+					content.ID, description, subagentType)
 			}
 		}
 	}
@@ -261,12 +688,13 @@ func (h *Handler) checkTaskResultFromUser(msg *UserMessage) *TaskCompletionMessa
 							BaseEvent: msg.BaseEvent, // Use BaseEvent from UserMessage
 							TaskInfo:  taskInfo,
 						}
-
-						if h.debugMode {
-							logger.LogInfo("Task completed: ID=%s, Description=%s, Agent=%s",
-								toolUseID, taskInfo.Description, taskInfo.SubagentType)
+						if h.sidechains != nil {
+							taskCompletion.Sidechain, _ = h.sidechains.Finish(toolUseID)
 						}
 
+						logger.LogDebug("Task completed: ID=%s, Description=%s, Agent=%s",
+							toolUseID, taskInfo.Description, taskInfo.SubagentType)
+
 						return taskCompletion
 					}
 				}
@@ -314,13 +742,11 @@ func (h *Handler) handleBuffering(event Event) bool {
 	}
 
 	// Check if we need to buffer this event
-	if baseEvent != nil && !baseEvent.IsSidechain && baseEvent.Session != nil && baseEvent.ParentUUID == nil {
+	if baseEvent != nil && !baseEvent.IsSidechain && baseEvent.Session != nil && baseEvent.ParentUUID == nil && !h.startedFresh(event) {
 		h.bufferMutex.Lock()
 		defer h.bufferMutex.Unlock()
 
-		if h.debugMode {
-			logger.LogInfo("Buffering event (ParentUUID==nil) for session: %s, type: %T", sessionName, event)
-		}
+		logger.LogDebug("Buffering event (ParentUUID==nil) for session: %s, type: %T", sessionName, event)
 
 		// Check if we already have a buffer for this session
 		if buffer, exists := h.buffers[sessionName]; exists {
@@ -357,6 +783,12 @@ func (h *Handler) handleBuffering(event Event) bool {
 	return false
 }
 
+// startedFresh reports whether the event's session started from scratch, so a line
+// without a parent is its first prompt rather than a resumed session's replay
+func (h *Handler) startedFresh(event Event) bool {
+	return h.sessions != nil && h.sessions.StartedFresh(SessionIDOf(event))
+}
+
 // releaseBuffer releases buffered events for a session
 func (h *Handler) releaseBuffer(sessionName string, reason string) {
 	h.bufferMutex.Lock()
@@ -372,10 +804,8 @@ func (h *Handler) releaseBuffer(sessionName string, reason string) {
 		buffer.timer.Stop()
 	}
 
-	if h.debugMode {
-		logger.LogInfo("Releasing buffer for session %s: %s (events: %d, duration: %v)",
-			sessionName, reason, len(buffer.events), time.Since(buffer.startTime))
-	}
+	logger.LogDebug("Releasing buffer for session %s: %s (events: %d, duration: %v)",
+		sessionName, reason, len(buffer.events), time.Since(buffer.startTime))
 
 	// Remove buffer and discard buffered events
 	delete(h.buffers, sessionName)
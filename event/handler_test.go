@@ -44,6 +44,34 @@ func (m *mockNarrator) NarrateAPIError(statusCode int, errorType string, message
 	return fmt.Sprintf("APIエラー %d: %s", statusCode, message), false
 }
 
+func (m *mockNarrator) NarrateToolSLABreach(toolName string, elapsed, limit time.Duration) (string, bool) {
+	return fmt.Sprintf("%sが%vかかりました", toolName, elapsed), false
+}
+
+func (m *mockNarrator) NarrateToolDuration(toolName string, elapsed time.Duration) (string, bool) {
+	return fmt.Sprintf("%sが%vで完了しました", toolName, elapsed), false
+}
+
+func (m *mockNarrator) NarrateSessionSummary(summary narrator.SessionSummary) (string, bool) {
+	return fmt.Sprintf("セッションが終わりました: %d files, %d commands", summary.FilesEdited, summary.Commands), false
+}
+
+func (m *mockNarrator) NarrateBudgetAlert(alert narrator.BudgetAlert) (string, bool) {
+	return fmt.Sprintf("%s %sの予算の%d%%に達しました", alert.Scope, alert.Metric, alert.Percent), false
+}
+
+func (m *mockNarrator) NarrateContextAlert(threshold, percent int) (string, bool) {
+	return fmt.Sprintf("コンテキストが%d%%になりました", percent), false
+}
+
+func (m *mockNarrator) NarrateSecurityAlert(alert narrator.SecurityAlert) (string, bool) {
+	return fmt.Sprintf("警告です。%sを検出しました", alert.Rule), false
+}
+
+func (m *mockNarrator) NarrateSidechainSummary(subagentType string, toolUses int, elapsed time.Duration) (string, bool) {
+	return fmt.Sprintf("%sがツールを%d回使いました", subagentType, toolUses), false
+}
+
 // captureOutput captures printed output during test
 func captureOutput(t *testing.T, f func()) string {
 	// Create a pipe to capture output
@@ -79,6 +107,24 @@ func captureOutput(t *testing.T, f func()) string {
 	return output
 }
 
+// assistantMessage creates an assistant message of a session in project app that
+// continues the conversation. Tests set any other fields they need on the result.
+func assistantMessage(session string, at time.Time, content ...AssistantContent) *AssistantMessage {
+	parent := "parent"
+	return &AssistantMessage{
+		BaseEvent: BaseEvent{TypeString: "assistant", ParentUUID: &parent, Timestamp: at, Session: &Session{Project: "app", Session: session}},
+		Message: AssistantMessageContent{
+			Model:   "claude-sonnet-4",
+			Content: content,
+		},
+	}
+}
+
+// toolUseContent creates the content of an assistant message using a tool
+func toolUseContent(id, name string, input map[string]interface{}) AssistantContent {
+	return AssistantContent{Type: "tool_use", ID: id, Name: name, Input: input}
+}
+
 func TestHandler_IgnoreSidechainEvents(t *testing.T) {
 	// Create handler with mock narrator
 	handler := NewHandler(&mockNarrator{}, false)
@@ -0,0 +1,158 @@
+package event
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Kinds of transcript integrity issues
+const (
+	IssueBrokenJSON          = "broken-json"
+	IssueDuplicateUUID       = "duplicate-uuid"
+	IssueOrphanedParent      = "orphaned-parent"
+	IssueTimestampRegression = "timestamp-regression"
+)
+
+// IntegrityIssue is a structural problem found on a transcript line
+type IntegrityIssue struct {
+	Line   int    `json:"line"`
+	Kind   string `json:"kind"`
+	Detail string `json:"detail"`
+}
+
+// IntegrityReport is the result of checking a transcript
+type IntegrityReport struct {
+	Path           string           `json:"path"`
+	Lines          int              `json:"lines"`
+	Events         int              `json:"events"` // Lines with a UUID
+	Types          map[string]int   `json:"types"`
+	FirstTimestamp time.Time        `json:"firstTimestamp,omitzero"`
+	LastTimestamp  time.Time        `json:"lastTimestamp,omitzero"`
+	Removed        int              `json:"removed"` // Lines left out of the cleaned copy
+	Issues         []IntegrityIssue `json:"issues"`
+}
+
+// Count returns the number of issues of a kind
+func (r *IntegrityReport) Count(kind string) int {
+	n := 0
+	for _, issue := range r.Issues {
+		if issue.Kind == kind {
+			n++
+		}
+	}
+	return n
+}
+
+// integrityLine is the part of a transcript line the integrity check needs
+type integrityLine struct {
+	Type       string    `json:"type"`
+	UUID       string    `json:"uuid"`
+	ParentUUID *string   `json:"parentUuid"`
+	Timestamp  time.Time `json:"timestamp"`
+}
+
+// CheckTranscript scans a transcript for broken JSON lines, duplicate UUIDs, parents
+// that do not exist in the file and timestamps that go backwards. If clean is not nil,
+// a copy without the broken lines and the later duplicates is written to it; orphans
+// and regressions are kept since dropping them would lose conversation content.
+func CheckTranscript(r io.Reader, clean io.Writer) (*IntegrityReport, error) {
+	report := &IntegrityReport{Types: make(map[string]int)}
+	seen := make(map[string]int) // UUID to the line it first appeared on
+	type parentRef struct {
+		line   int
+		parent string
+	}
+	var parents []parentRef
+	var last time.Time
+
+	reader := bufio.NewReader(r)
+	for lineNum := 1; ; lineNum++ {
+		raw, err := reader.ReadString('\n')
+		if err != nil && !errors.Is(err, io.EOF) {
+			return nil, fmt.Errorf("error reading transcript: %w", err)
+		}
+		if raw == "" && errors.Is(err, io.EOF) {
+			break
+		}
+		report.Lines++
+
+		keep := true
+		if text := strings.TrimSpace(raw); text != "" {
+			var line integrityLine
+			if jsonErr := json.Unmarshal([]byte(text), &line); jsonErr != nil {
+				report.Issues = append(report.Issues, IntegrityIssue{Line: lineNum, Kind: IssueBrokenJSON, Detail: jsonErr.Error()})
+				keep = false
+			} else {
+				report.Types[line.Type]++
+				if line.UUID != "" {
+					if first, ok := seen[line.UUID]; ok {
+						report.Issues = append(report.Issues, IntegrityIssue{
+							Line:   lineNum,
+							Kind:   IssueDuplicateUUID,
+							Detail: fmt.Sprintf("uuid %s first appeared on line %d", line.UUID, first),
+						})
+						keep = false
+					} else {
+						seen[line.UUID] = lineNum
+						report.Events++
+					}
+				}
+				if line.ParentUUID != nil && *line.ParentUUID != "" {
+					parents = append(parents, parentRef{line: lineNum, parent: *line.ParentUUID})
+				}
+				if !line.Timestamp.IsZero() {
+					if !last.IsZero() && line.Timestamp.Before(last) {
+						report.Issues = append(report.Issues, IntegrityIssue{
+							Line:   lineNum,
+							Kind:   IssueTimestampRegression,
+							Detail: fmt.Sprintf("%s is %s before the previous line", line.Timestamp.Format(time.RFC3339Nano), last.Sub(line.Timestamp)),
+						})
+					} else {
+						last = line.Timestamp
+					}
+					if report.FirstTimestamp.IsZero() || line.Timestamp.Before(report.FirstTimestamp) {
+						report.FirstTimestamp = line.Timestamp
+					}
+					if line.Timestamp.After(report.LastTimestamp) {
+						report.LastTimestamp = line.Timestamp
+					}
+				}
+			}
+		}
+
+		if !keep {
+			report.Removed++
+		} else if clean != nil {
+			if !strings.HasSuffix(raw, "\n") {
+				raw += "\n"
+			}
+			if _, err := io.WriteString(clean, raw); err != nil {
+				return nil, fmt.Errorf("error writing cleaned transcript: %w", err)
+			}
+		}
+
+		if errors.Is(err, io.EOF) {
+			break
+		}
+	}
+
+	// A parent can only be resolved once the whole file has been read
+	for _, ref := range parents {
+		if _, ok := seen[ref.parent]; !ok {
+			report.Issues = append(report.Issues, IntegrityIssue{
+				Line:   ref.line,
+				Kind:   IssueOrphanedParent,
+				Detail: fmt.Sprintf("parent %s is not in the transcript", ref.parent),
+			})
+		}
+	}
+	sort.SliceStable(report.Issues, func(i, j int) bool { return report.Issues[i].Line < report.Issues[j].Line })
+
+	return report, nil
+}
@@ -0,0 +1,114 @@
+package event
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestCheckTranscript(t *testing.T) {
+	tests := []struct {
+		name        string
+		lines       []string
+		wantIssues  []string // kind@line
+		wantEvents  int
+		wantRemoved int
+		wantClean   []string
+	}{
+		{
+			name: "healthy transcript",
+			lines: []string{
+				`{"type":"user","uuid":"a","parentUuid":null,"timestamp":"2025-01-01T00:00:00Z"}`,
+				`{"type":"assistant","uuid":"b","parentUuid":"a","timestamp":"2025-01-01T00:00:01Z"}`,
+				`{"type":"summary","summary":"Fix tests","leafUuid":"b"}`,
+			},
+			wantEvents: 2,
+		},
+		{
+			name: "broken line and duplicate are removed",
+			lines: []string{
+				`{"type":"user","uuid":"a","parentUuid":null,"timestamp":"2025-01-01T00:00:00Z"}`,
+				`{"type":"assistant","uuid":"b","parentUu`,
+				`{"type":"assistant","uuid":"b","parentUuid":"a","timestamp":"2025-01-01T00:00:01Z"}`,
+				`{"type":"assistant","uuid":"b","parentUuid":"a","timestamp":"2025-01-01T00:00:01Z"}`,
+			},
+			wantIssues:  []string{"broken-json@2", "duplicate-uuid@4"},
+			wantEvents:  2,
+			wantRemoved: 2,
+			wantClean: []string{
+				`{"type":"user","uuid":"a","parentUuid":null,"timestamp":"2025-01-01T00:00:00Z"}`,
+				`{"type":"assistant","uuid":"b","parentUuid":"a","timestamp":"2025-01-01T00:00:01Z"}`,
+			},
+		},
+		{
+			name: "orphan and regression are reported but kept",
+			lines: []string{
+				`{"type":"user","uuid":"a","parentUuid":"missing","timestamp":"2025-01-01T00:00:05Z"}`,
+				`{"type":"assistant","uuid":"b","parentUuid":"a","timestamp":"2025-01-01T00:00:01Z"}`,
+			},
+			wantIssues: []string{"orphaned-parent@1", "timestamp-regression@2"},
+			wantEvents: 2,
+		},
+		{
+			name: "parent defined later is not an orphan",
+			lines: []string{
+				`{"type":"assistant","uuid":"b","parentUuid":"a"}`,
+				`{"type":"user","uuid":"a","parentUuid":null}`,
+			},
+			wantEvents: 2,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var clean strings.Builder
+			report, err := CheckTranscript(strings.NewReader(strings.Join(tt.lines, "\n")), &clean)
+			if err != nil {
+				t.Fatalf("CheckTranscript() error = %v", err)
+			}
+
+			var issues []string
+			for _, issue := range report.Issues {
+				issues = append(issues, fmt.Sprintf("%s@%d", issue.Kind, issue.Line))
+			}
+			if diff := cmp.Diff(tt.wantIssues, issues); diff != "" {
+				t.Errorf("issues mismatch (-want +got):\n%s", diff)
+			}
+			if report.Lines != len(tt.lines) || report.Events != tt.wantEvents || report.Removed != tt.wantRemoved {
+				t.Errorf("lines, events, removed = %d, %d, %d, want %d, %d, %d",
+					report.Lines, report.Events, report.Removed, len(tt.lines), tt.wantEvents, tt.wantRemoved)
+			}
+
+			wantClean := tt.wantClean
+			if wantClean == nil {
+				wantClean = tt.lines
+			}
+			if diff := cmp.Diff(strings.Join(wantClean, "\n")+"\n", clean.String()); diff != "" {
+				t.Errorf("cleaned copy mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestCheckTranscriptTimestamps(t *testing.T) {
+	input := `{"type":"user","uuid":"a","timestamp":"2025-01-01T00:00:05Z"}
+{"type":"user","uuid":"b","timestamp":"2025-01-01T00:00:01Z"}
+{"type":"user","uuid":"c","timestamp":"2025-01-01T00:00:09Z"}
+`
+	report, err := CheckTranscript(strings.NewReader(input), nil)
+	if err != nil {
+		t.Fatalf("CheckTranscript() error = %v", err)
+	}
+	if want := time.Date(2025, 1, 1, 0, 0, 1, 0, time.UTC); !report.FirstTimestamp.Equal(want) {
+		t.Errorf("FirstTimestamp = %v, want %v", report.FirstTimestamp, want)
+	}
+	if want := time.Date(2025, 1, 1, 0, 0, 9, 0, time.UTC); !report.LastTimestamp.Equal(want) {
+		t.Errorf("LastTimestamp = %v, want %v", report.LastTimestamp, want)
+	}
+	if report.Types["user"] != 3 {
+		t.Errorf("Types = %v, want 3 user lines", report.Types)
+	}
+}
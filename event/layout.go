@@ -0,0 +1,113 @@
+package event
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/mattn/go-runewidth"
+)
+
+// Layout describes how formatted output is arranged on the console
+type Layout int
+
+const (
+	// LayoutDefault prints narration and details as separate lines
+	LayoutDefault Layout = iota
+	// LayoutTwoColumn prints narration on the left and raw details right-aligned
+	LayoutTwoColumn
+)
+
+// MinTwoColumnWidth is the terminal width below which the two-column layout collapses
+const MinTwoColumnWidth = 100
+
+// minDetailWidth is the minimum space kept for the detail column before collapsing
+const minDetailWidth = 16
+
+// ParseLayout parses a layout name
+func ParseLayout(name string) (Layout, error) {
+	switch name {
+	case "", "default":
+		return LayoutDefault, nil
+	case "two-column":
+		return LayoutTwoColumn, nil
+	default:
+		return LayoutDefault, fmt.Errorf("unknown layout %q (expected default or two-column)", name)
+	}
+}
+
+// SetLayout sets the output layout and a function returning the current terminal width
+func (f *Formatter) SetLayout(layout Layout, width func() int) {
+	f.layout = layout
+	f.width = width
+}
+
+// twoColumn reports whether details are shown in a separate column
+func (f *Formatter) twoColumn() bool {
+	return f.layout == LayoutTwoColumn
+}
+
+// withDetail returns a line with the detail right-aligned in the second column.
+// On narrow terminals the detail is placed on its own indented line instead.
+// In the default layout the detail is omitted.
+func (f *Formatter) withDetail(line, detail string) string {
+	if !f.twoColumn() || detail == "" {
+		return line + "\n"
+	}
+	// Measure the text as it will be printed
+	if f.accessible {
+		line = accessibleText(line)
+		detail = accessibleText(detail)
+	}
+
+	width := 0
+	if f.width != nil {
+		width = f.width()
+	}
+
+	lineWidth := runewidth.StringWidth(line)
+	available := width - lineWidth - 2
+	if width < MinTwoColumnWidth || strings.Contains(line, "\n") || available < minDetailWidth {
+		return line + "\n    ↳ " + detail + "\n"
+	}
+
+	detail = runewidth.Truncate(detail, available, "…")
+	padding := width - lineWidth - runewidth.StringWidth(detail)
+	return line + strings.Repeat(" ", padding) + detail + "\n"
+}
+
+// toolDetail returns the raw detail of a tool use shown in the second column
+func toolDetail(toolName string, meta EventMeta, input map[string]interface{}) string {
+	parts := []string{toolName}
+	if target := toolTarget(meta, input); target != "" {
+		parts = append(parts, target)
+	}
+	if meta.ToolID != "" {
+		parts = append(parts, meta.ToolID)
+	}
+	return strings.Join(parts, " · ")
+}
+
+// toolTarget returns the file, command, pattern or URL a tool use works on, on one
+// line and relative to the working directory
+func toolTarget(meta EventMeta, input map[string]interface{}) string {
+	var target string
+	for _, key := range []string{"file_path", "notebook_path", "path", "command", "pattern", "url", "description"} {
+		if v, ok := input[key].(string); ok && v != "" {
+			target = v
+			break
+		}
+	}
+	if i := strings.IndexByte(target, '\n'); i >= 0 {
+		target = target[:i] + "…"
+	}
+	if target == "" {
+		return ""
+	}
+	return toRelativePath(meta.CWD, target)
+}
+
+// usageDetail returns the token usage shown in the second column
+func usageDetail(usage Usage) string {
+	return fmt.Sprintf("in=%d out=%d cache_read=%d cache_creation=%d",
+		usage.InputTokens, usage.OutputTokens, usage.CacheReadInputTokens, usage.CacheCreationInputTokens)
+}
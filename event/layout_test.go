@@ -0,0 +1,115 @@
+package event
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/kazegusuri/claude-companion/narrator"
+	"github.com/mattn/go-runewidth"
+)
+
+func TestFormatterWithDetail(t *testing.T) {
+	tests := []struct {
+		name   string
+		layout Layout
+		width  int
+		line   string
+		detail string
+		want   string
+	}{
+		{
+			name:   "default_layout_omits_detail",
+			layout: LayoutDefault,
+			width:  120,
+			line:   "  💬 ファイルを読み込みます",
+			detail: "Read · main.go",
+			want:   "  💬 ファイルを読み込みます\n",
+		},
+		{
+			name:   "narrow_terminal_collapses",
+			layout: LayoutTwoColumn,
+			width:  80,
+			line:   "  💬 ファイルを読み込みます",
+			detail: "Read · main.go",
+			want:   "  💬 ファイルを読み込みます\n    ↳ Read · main.go\n",
+		},
+		{
+			name:   "empty_detail",
+			layout: LayoutTwoColumn,
+			width:  120,
+			line:   "  💬 hello",
+			detail: "",
+			want:   "  💬 hello\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := &Formatter{}
+			f.SetLayout(tt.layout, func() int { return tt.width })
+			if got := f.withDetail(tt.line, tt.detail); got != tt.want {
+				t.Errorf("withDetail() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFormatterWithDetailWide(t *testing.T) {
+	f := &Formatter{}
+	f.SetLayout(LayoutTwoColumn, func() int { return 120 })
+
+	line := "  💬 ファイルを読み込みます"
+	got := f.withDetail(line, "Read · main.go · toolu_123")
+	got = strings.TrimSuffix(got, "\n")
+
+	if !strings.HasPrefix(got, line) || !strings.HasSuffix(got, "Read · main.go · toolu_123") {
+		t.Errorf("withDetail() = %q, want narration followed by right-aligned detail", got)
+	}
+	if w := runewidth.StringWidth(got); w != 120 {
+		t.Errorf("line width = %d, want 120", w)
+	}
+
+	// Long details are truncated to fit
+	got = strings.TrimSuffix(f.withDetail(line, strings.Repeat("x", 200)), "\n")
+	if w := runewidth.StringWidth(got); w != 120 {
+		t.Errorf("truncated line width = %d, want 120", w)
+	}
+}
+
+func TestParseLayout(t *testing.T) {
+	tests := []struct {
+		name    string
+		want    Layout
+		wantErr bool
+	}{
+		{name: "", want: LayoutDefault},
+		{name: "default", want: LayoutDefault},
+		{name: "two-column", want: LayoutTwoColumn},
+		{name: "three-column", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseLayout(tt.name)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseLayout(%q) error = %v, wantErr %v", tt.name, err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("ParseLayout(%q) = %v, want %v", tt.name, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFormatToolUseTwoColumn(t *testing.T) {
+	f := NewFormatter(narrator.NewNoOpNarrator())
+	f.SetLayout(LayoutTwoColumn, func() int { return 120 })
+
+	got := f.FormatToolUse("Bash", EventMeta{ToolID: "toolu_123"}, map[string]interface{}{"command": "make test"})
+	if strings.Contains(got, "(id: toolu_123)") {
+		t.Errorf("FormatToolUse() = %q, tool ID should be moved to the detail column", got)
+	}
+	if !strings.HasSuffix(got, "toolu_123\n") {
+		t.Errorf("FormatToolUse() = %q, want tool ID right-aligned", got)
+	}
+}
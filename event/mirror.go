@@ -0,0 +1,201 @@
+package event
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/kazegusuri/claude-companion/logger"
+)
+
+// mirrorMaxOpen is the number of session logs kept open; the least recently written
+// one is closed to open another
+const mirrorMaxOpen = 32
+
+// FsyncPolicy is when a Mirror flushes the session logs to disk
+type FsyncPolicy struct {
+	Always   bool          // After every event
+	Interval time.Duration // Periodically, unless Always; 0 leaves it to the OS
+}
+
+// ParseFsyncPolicy parses never, always, or the interval of periodic flushes such as 5s
+func ParseFsyncPolicy(value string) (FsyncPolicy, error) {
+	switch value {
+	case "", "never":
+		return FsyncPolicy{}, nil
+	case "always":
+		return FsyncPolicy{Always: true}, nil
+	}
+	interval, err := time.ParseDuration(value)
+	if err != nil || interval <= 0 {
+		return FsyncPolicy{}, fmt.Errorf("invalid fsync policy %q (expected never, always or an interval such as 5s)", value)
+	}
+	return FsyncPolicy{Interval: interval}, nil
+}
+
+// Mirror writes the formatted output of each session to DIR/{project}/{session}.log
+// besides the console, so a session can be reviewed on its own later. Logs are
+// rotated like --log-file. It implements EventSink.
+type Mirror struct {
+	dir        string
+	maxSize    int64 // 0 never rotates
+	maxBackups int
+	fsync      FsyncPolicy
+
+	mu     sync.Mutex
+	files  map[string]*mirrorFile // By path
+	failed map[string]bool        // Paths whose failure was reported
+	done   chan struct{}
+	wg     sync.WaitGroup
+}
+
+// mirrorFile is an open session log
+type mirrorFile struct {
+	file      *logger.RotatingFile
+	lastWrite time.Time
+	dirty     bool // Written since the last flush
+}
+
+// NewMirror creates a mirror writing session logs under dir, rotated once they grow
+// past maxSize bytes keeping maxBackups old files. Call Start to flush them by the
+// fsync policy, and Stop to close them.
+func NewMirror(dir string, maxSize int64, maxBackups int, fsync FsyncPolicy) *Mirror {
+	return &Mirror{
+		dir:        dir,
+		maxSize:    maxSize,
+		maxBackups: maxBackups,
+		fsync:      fsync,
+		files:      make(map[string]*mirrorFile),
+		failed:     make(map[string]bool),
+		done:       make(chan struct{}),
+	}
+}
+
+// Start flushes the session logs periodically if the fsync policy has an interval
+func (m *Mirror) Start() {
+	if m.fsync.Always || m.fsync.Interval <= 0 {
+		return
+	}
+	m.wg.Add(1)
+	go func() {
+		defer m.wg.Done()
+		ticker := time.NewTicker(m.fsync.Interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-m.done:
+				return
+			case <-ticker.C:
+				m.mu.Lock()
+				for path, f := range m.files {
+					m.sync(path, f)
+				}
+				m.mu.Unlock()
+			}
+		}
+	}()
+}
+
+// Stop flushes and closes the session logs
+func (m *Mirror) Stop() {
+	close(m.done)
+	m.wg.Wait()
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for path, f := range m.files {
+		m.sync(path, f)
+		f.file.Close()
+		delete(m.files, path)
+	}
+}
+
+// HandleEvent implements EventSink. Events of no session, such as hook notifications
+// without a transcript, are not mirrored.
+func (m *Mirror) HandleEvent(event Event, formatted string) {
+	session := SessionOf(event)
+	if session == nil || formatted == "" {
+		return
+	}
+	path := m.Path(session)
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	f, err := m.open(path)
+	if err != nil {
+		m.report(path, err)
+		return
+	}
+	if _, err := f.file.Write([]byte(stripANSI(formatted))); err != nil {
+		m.report(path, err)
+		return
+	}
+	f.lastWrite, f.dirty = time.Now(), true
+	if m.fsync.Always {
+		m.sync(path, f)
+	}
+}
+
+// Path returns the log file of a session
+func (m *Mirror) Path(session *Session) string {
+	return filepath.Join(m.dir, mirrorName(session.Project), mirrorName(session.Session)+".log")
+}
+
+// mirrorName makes a project or session name safe to use as a file name
+func mirrorName(name string) string {
+	name = strings.NewReplacer("/", "_", `\`, "_").Replace(name)
+	if name == "" || name == "." || name == ".." {
+		return "unknown"
+	}
+	return name
+}
+
+// open returns the open log file at path, opening it and closing the least
+// recently written one if too many are open
+func (m *Mirror) open(path string) (*mirrorFile, error) {
+	if f, ok := m.files[path]; ok {
+		return f, nil
+	}
+	if len(m.files) >= mirrorMaxOpen {
+		var oldest string
+		for p, f := range m.files {
+			if oldest == "" || f.lastWrite.Before(m.files[oldest].lastWrite) {
+				oldest = p
+			}
+		}
+		m.sync(oldest, m.files[oldest])
+		m.files[oldest].file.Close()
+		delete(m.files, oldest)
+	}
+	file, err := logger.OpenRotatingFile(path, m.maxSize, m.maxBackups)
+	if err != nil {
+		return nil, err
+	}
+	f := &mirrorFile{file: file}
+	m.files[path] = f
+	delete(m.failed, path)
+	return f, nil
+}
+
+// sync flushes a log file written since its last flush
+func (m *Mirror) sync(path string, f *mirrorFile) {
+	if !f.dirty {
+		return
+	}
+	if err := f.file.Sync(); err != nil {
+		m.report(path, err)
+		return
+	}
+	f.dirty = false
+}
+
+// report warns of the first failure of a log file until it is opened again
+func (m *Mirror) report(path string, err error) {
+	if m.failed[path] {
+		logger.LogDebug("Failed to mirror to %s: %v", path, err)
+		return
+	}
+	m.failed[path] = true
+	logger.LogWarning("Failed to mirror to %s: %v", path, err)
+}
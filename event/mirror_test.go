@@ -0,0 +1,107 @@
+package event
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestMirror(t *testing.T) {
+	dir := t.TempDir()
+	m := NewMirror(dir, 64, 1, FsyncPolicy{Always: true})
+	m.Start()
+
+	s1 := &Session{Project: "-home-me-app", Session: "s1"}
+	s2 := &Session{Project: "-home-me-app", Session: "s2"}
+	m.HandleEvent(&UserMessage{BaseEvent: BaseEvent{Session: s1}}, "\x1b[36m[15:04:05] 👤 USER:\x1b[0m\n  💬 hello\n")
+	m.HandleEvent(&UserMessage{BaseEvent: BaseEvent{Session: s2}}, "s2 line\n")
+	m.HandleEvent(&NotificationEvent{HookEventName: "Notification"}, "no session\n")
+	m.HandleEvent(&UserMessage{BaseEvent: BaseEvent{Session: s1}}, "this line takes the log past its maximum size\n")
+	m.Stop()
+
+	read := func(path string) string {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return string(data)
+	}
+	s1Log := filepath.Join(dir, "-home-me-app", "s1.log")
+	if got := read(s1Log + ".1"); got != "[15:04:05] 👤 USER:\n  💬 hello\n" {
+		t.Errorf("rotated s1 log = %q, want the first event without colors", got)
+	}
+	if got := read(s1Log); got != "this line takes the log past its maximum size\n" {
+		t.Errorf("s1 log = %q", got)
+	}
+	if got := read(filepath.Join(dir, "-home-me-app", "s2.log")); got != "s2 line\n" {
+		t.Errorf("s2 log = %q", got)
+	}
+	entries, _ := os.ReadDir(dir)
+	if len(entries) != 1 {
+		t.Errorf("mirror dir has %d entries, want only the project", len(entries))
+	}
+}
+
+func TestMirror_ClosesLeastRecentlyWritten(t *testing.T) {
+	dir := t.TempDir()
+	m := NewMirror(dir, 0, 0, FsyncPolicy{Interval: time.Hour})
+	m.Start()
+
+	for i := 0; i <= mirrorMaxOpen; i++ {
+		session := &Session{Project: "app", Session: fmt.Sprintf("s%d", i)}
+		m.HandleEvent(&UserMessage{BaseEvent: BaseEvent{Session: session}}, "line\n")
+	}
+	if len(m.files) != mirrorMaxOpen {
+		t.Errorf("open logs = %d, want %d", len(m.files), mirrorMaxOpen)
+	}
+	if _, ok := m.files[m.Path(&Session{Project: "app", Session: "s0"})]; ok {
+		t.Error("the least recently written log is still open")
+	}
+
+	// A closed log is reopened for appending
+	m.HandleEvent(&UserMessage{BaseEvent: BaseEvent{Session: &Session{Project: "app", Session: "s0"}}}, "again\n")
+	m.Stop()
+	data, _ := os.ReadFile(filepath.Join(dir, "app", "s0.log"))
+	if string(data) != "line\nagain\n" {
+		t.Errorf("s0 log = %q", data)
+	}
+}
+
+func TestMirrorPath(t *testing.T) {
+	m := NewMirror("/logs", 0, 0, FsyncPolicy{})
+	tests := []struct {
+		session *Session
+		want    string
+	}{
+		{&Session{Project: "-home-me-app", Session: "abc"}, "/logs/-home-me-app/abc.log"},
+		{&Session{Project: "..", Session: "../x"}, "/logs/unknown/.._x.log"},
+		{&Session{}, "/logs/unknown/unknown.log"},
+	}
+	for _, tt := range tests {
+		if got := m.Path(tt.session); got != tt.want {
+			t.Errorf("Path(%+v) = %q, want %q", tt.session, got, tt.want)
+		}
+	}
+}
+
+func TestParseFsyncPolicy(t *testing.T) {
+	tests := []struct {
+		value   string
+		want    FsyncPolicy
+		wantErr bool
+	}{
+		{value: "never", want: FsyncPolicy{}},
+		{value: "always", want: FsyncPolicy{Always: true}},
+		{value: "5s", want: FsyncPolicy{Interval: 5 * time.Second}},
+		{value: "0s", wantErr: true},
+		{value: "sometimes", wantErr: true},
+	}
+	for _, tt := range tests {
+		got, err := ParseFsyncPolicy(tt.value)
+		if (err != nil) != tt.wantErr || got != tt.want {
+			t.Errorf("ParseFsyncPolicy(%q) = %+v, %v", tt.value, got, err)
+		}
+	}
+}
@@ -0,0 +1,100 @@
+package event
+
+import (
+	"time"
+
+	"github.com/kazegusuri/claude-companion/narrator"
+)
+
+// narrationRecorder wraps the formatter's narrator and keeps the narrations of the
+// event being formatted, so they can be handed to the sinks with the event
+type narrationRecorder struct {
+	narrator.Narrator
+	narrations []string
+}
+
+// record keeps a narration and passes it through
+func (r *narrationRecorder) record(text string, ok bool) (string, bool) {
+	if text != "" {
+		r.narrations = append(r.narrations, text)
+	}
+	return text, ok
+}
+
+// NarrateToolUse keeps the narration of a tool use as its stream verbosity sets: none
+// for a silent tool and the short message for a short one
+func (r *narrationRecorder) NarrateToolUse(toolName string, input map[string]interface{}) (string, bool) {
+	text, ok := r.Narrator.NarrateToolUse(toolName, input)
+	if text != "" {
+		v := narrator.VerbosityOf(r.Narrator, toolName)
+		r.record(v.Narration(v.Stream, text), ok)
+	}
+	return text, ok
+}
+
+func (r *narrationRecorder) NarrateToolUsePermission(toolName string) (string, bool) {
+	return r.record(r.Narrator.NarrateToolUsePermission(toolName))
+}
+
+func (r *narrationRecorder) NarrateText(text string, isThinking bool) (string, bool) {
+	return r.record(r.Narrator.NarrateText(text, isThinking))
+}
+
+func (r *narrationRecorder) NarrateNotification(notificationType narrator.NotificationType) (string, bool) {
+	return r.record(r.Narrator.NarrateNotification(notificationType))
+}
+
+func (r *narrationRecorder) NarrateTaskCompletion(description string, subagentType string) (string, bool) {
+	return r.record(r.Narrator.NarrateTaskCompletion(description, subagentType))
+}
+
+func (r *narrationRecorder) NarrateAPIError(statusCode int, errorType string, message string) (string, bool) {
+	return r.record(r.Narrator.NarrateAPIError(statusCode, errorType, message))
+}
+
+func (r *narrationRecorder) NarrateToolSLABreach(toolName string, elapsed, limit time.Duration) (string, bool) {
+	return r.record(r.Narrator.NarrateToolSLABreach(toolName, elapsed, limit))
+}
+
+func (r *narrationRecorder) NarrateSidechainSummary(subagentType string, toolUses int, elapsed time.Duration) (string, bool) {
+	return r.record(r.Narrator.NarrateSidechainSummary(subagentType, toolUses, elapsed))
+}
+
+func (r *narrationRecorder) NarrateSessionSummary(summary narrator.SessionSummary) (string, bool) {
+	return r.record(r.Narrator.NarrateSessionSummary(summary))
+}
+
+func (r *narrationRecorder) NarrateBudgetAlert(alert narrator.BudgetAlert) (string, bool) {
+	return r.record(r.Narrator.NarrateBudgetAlert(alert))
+}
+
+func (r *narrationRecorder) NarrateContextAlert(threshold, percent int) (string, bool) {
+	return r.record(r.Narrator.NarrateContextAlert(threshold, percent))
+}
+
+func (r *narrationRecorder) NarrateSecurityAlert(alert narrator.SecurityAlert) (string, bool) {
+	return r.record(r.Narrator.NarrateSecurityAlert(alert))
+}
+
+func (r *narrationRecorder) NarrateToolDuration(toolName string, elapsed time.Duration) (string, bool) {
+	return r.record(r.Narrator.NarrateToolDuration(toolName, elapsed))
+}
+
+// narrationsOf returns where the narrations of an event are stored, or nil if it has no place for them
+func narrationsOf(event Event) *[]string {
+	if e, ok := event.(*NotificationEvent); ok {
+		return &e.Narrations
+	}
+	if base := BaseOf(event); base != nil {
+		return &base.Narrations
+	}
+	return nil
+}
+
+// NarrationsOf returns the narrations produced while formatting an event
+func NarrationsOf(event Event) []string {
+	if p := narrationsOf(event); p != nil {
+		return *p
+	}
+	return nil
+}
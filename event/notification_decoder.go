@@ -0,0 +1,107 @@
+package event
+
+import (
+	"strings"
+	"time"
+
+	"github.com/kazegusuri/claude-companion/logger"
+)
+
+// maxHookPayloadSize bounds an unterminated payload, so a broken line cannot grow forever
+const maxHookPayloadSize = 1 << 20
+
+// hookPayload is one JSON object read from the notification log, with the text
+// written before it on its first line
+type hookPayload struct {
+	data   string
+	prefix string
+}
+
+// notificationDecoder splits the notification log into hook payloads. It accepts one
+// payload per line as written by the notification script, payloads spread over
+// several lines (pretty-printed with jq, for example) and lines that start with
+// something other than JSON, such as a timestamp written by a custom script.
+type notificationDecoder struct {
+	buf      strings.Builder
+	prefix   string
+	depth    int
+	inString bool
+	escaped  bool
+}
+
+// feed reads a line and returns the payloads it completes. Text outside of a JSON
+// object is skipped.
+func (d *notificationDecoder) feed(line string) []hookPayload {
+	var payloads []hookPayload
+	if d.depth > 0 && strings.HasPrefix(line, "{") {
+		// Payloads start at the beginning of a line, so the previous one was cut off
+		logger.LogDebug("Dropping an incomplete hook payload in the notification log")
+		d.reset()
+	}
+	start, begin := 0, 0 // start of the text outside of objects, start of the current object
+	for i := 0; i < len(line); i++ {
+		c := line[i]
+		if d.depth == 0 {
+			if c != '{' {
+				continue
+			}
+			d.prefix = strings.TrimSpace(line[start:i])
+			begin = i
+		}
+		switch {
+		case d.inString:
+			switch {
+			case d.escaped:
+				d.escaped = false
+			case c == '\\':
+				d.escaped = true
+			case c == '"':
+				d.inString = false
+			}
+		case c == '"':
+			d.inString = true
+		case c == '{':
+			d.depth++
+		case c == '}':
+			d.depth--
+			if d.depth == 0 {
+				d.buf.WriteString(line[begin : i+1])
+				payloads = append(payloads, hookPayload{data: d.buf.String(), prefix: d.prefix})
+				d.buf.Reset()
+				start = i + 1
+			}
+		}
+	}
+	if d.depth > 0 {
+		d.buf.WriteString(line[begin:])
+		if d.buf.Len() > maxHookPayloadSize {
+			logger.LogWarning("Dropping a hook payload larger than %d bytes", maxHookPayloadSize)
+			d.reset()
+		}
+	} else if rest := strings.TrimSpace(line[start:]); rest != "" {
+		logger.LogDebug("Skipping non-JSON text in the notification log: %q", rest)
+	}
+	return payloads
+}
+
+// reset drops a partially read payload
+func (d *notificationDecoder) reset() {
+	*d = notificationDecoder{}
+}
+
+// parseHookPayload parses a payload, using a timestamp written before it when the
+// payload has none of its own
+func parseHookPayload(p hookPayload) (*NotificationEvent, error) {
+	notificationEvent, err := ParseNotification([]byte(p.data))
+	if err != nil {
+		return nil, err
+	}
+	if notificationEvent.LoggedAt == "" && p.prefix != "" {
+		prefix := strings.Trim(p.prefix, "[]: \t")
+		if ts, err := ParseTimestamp(prefix, time.Local); err == nil {
+			notificationEvent.LoggedAt = prefix
+			notificationEvent.Timestamp = ts
+		}
+	}
+	return notificationEvent, nil
+}
@@ -0,0 +1,131 @@
+package event
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+)
+
+func TestProcessNotificationLines(t *testing.T) {
+	tests := []struct {
+		name  string
+		lines []string
+		want  []*NotificationEvent
+	}{
+		{
+			name: "pretty-printed payload",
+			lines: []string{
+				"{\n",
+				"  \"session_id\": \"abc\",\n",
+				"  \"hook_event_name\": \"PreToolUse\",\n",
+				"  \"tool_name\": \"Bash\",\n",
+				"  \"tool_input\": {\n",
+				"    \"command\": \"echo '}'\"\n",
+				"  }\n",
+				"}\n",
+			},
+			want: []*NotificationEvent{{
+				SessionID:     "abc",
+				HookEventName: "PreToolUse",
+				ToolName:      "Bash",
+				ToolInput:     map[string]interface{}{"command": "echo '}'"},
+			}},
+		},
+		{
+			name: "PostToolUse with tool response",
+			lines: []string{
+				`{"session_id":"abc","hook_event_name":"PostToolUse","tool_name":"Write","tool_use_id":"toolu_1","tool_input":{"file_path":"/tmp/a.go"},"tool_response":{"success":true}}` + "\n",
+			},
+			want: []*NotificationEvent{{
+				SessionID:     "abc",
+				HookEventName: "PostToolUse",
+				ToolName:      "Write",
+				ToolUseID:     "toolu_1",
+				ToolInput:     map[string]interface{}{"file_path": "/tmp/a.go"},
+				ToolResponse:  map[string]interface{}{"success": true},
+			}},
+		},
+		{
+			name: "Stop with stop_hook_active",
+			lines: []string{
+				`{"session_id":"abc","hook_event_name":"Stop","stop_hook_active":true}` + "\n",
+			},
+			want: []*NotificationEvent{{SessionID: "abc", HookEventName: "Stop", StopHookActive: true}},
+		},
+		{
+			name: "escaped quotes and braces in strings",
+			lines: []string{
+				`{"hook_event_name":"Notification","message":"say \"{hi}\""}` + "\n",
+			},
+			want: []*NotificationEvent{{HookEventName: "Notification", Message: `say "{hi}"`}},
+		},
+		{
+			name: "two payloads on one line",
+			lines: []string{
+				`{"hook_event_name":"Stop"}{"hook_event_name":"PreCompact","trigger":"manual"}` + "\n",
+			},
+			want: []*NotificationEvent{
+				{HookEventName: "Stop"},
+				{HookEventName: "PreCompact", Trigger: "manual"},
+			},
+		},
+		{
+			name: "non-JSON lines between payloads",
+			lines: []string{
+				"=== hook ===\n",
+				`{"hook_event_name":"SessionStart","source":"resume"}` + "\n",
+				"\n",
+			},
+			want: []*NotificationEvent{{HookEventName: "SessionStart", Source: "resume"}},
+		},
+		{
+			name: "truncated payload is dropped",
+			lines: []string{
+				`{"hook_event_name":"Notification","message":"cut` + "\n",
+				`{"hook_event_name":"Stop"}` + "\n",
+			},
+			want: []*NotificationEvent{{HookEventName: "Stop"}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockSender := NewMockEventSender()
+			watcher := &NotificationWatcher{filePath: "/test/path", eventSender: mockSender}
+			for _, line := range tt.lines {
+				watcher.processNotificationLine(line)
+			}
+
+			var got []*NotificationEvent
+			for _, e := range mockSender.GetEvents() {
+				got = append(got, e.(*NotificationEvent))
+			}
+			if diff := cmp.Diff(tt.want, got, cmpopts.IgnoreFields(NotificationEvent{}, "Timestamp")); diff != "" {
+				t.Errorf("events mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestProcessNotificationLineTimestampPrefix(t *testing.T) {
+	mockSender := NewMockEventSender()
+	watcher := &NotificationWatcher{filePath: "/test/path", eventSender: mockSender}
+	watcher.processNotificationLine(`[2025-01-26 19:00:00] {"hook_event_name":"Stop"}` + "\n")
+	watcher.processNotificationLine(`2025-01-26 19:00:00 {"hook_event_name":"Stop","timestamp":"2025-01-26T10:00:05Z"}` + "\n")
+
+	events := mockSender.GetEvents()
+	if len(events) != 2 {
+		t.Fatalf("got %d events, want 2", len(events))
+	}
+	want := time.Date(2025, 1, 26, 19, 0, 0, 0, time.Local)
+	if got := events[0].(*NotificationEvent).Timestamp; !got.Equal(want) {
+		t.Errorf("prefixed timestamp = %v, want %v", got, want)
+	}
+	// The payload's own timestamp wins over the prefix
+	want = time.Date(2025, 1, 26, 10, 0, 5, 0, time.UTC)
+	if got := events[1].(*NotificationEvent).Timestamp; !got.Equal(want) {
+		t.Errorf("payload timestamp = %v, want %v", got, want)
+	}
+}
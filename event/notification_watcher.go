@@ -22,6 +22,7 @@ type NotificationWatcher struct {
 	fileWatcher   *fsnotify.Watcher
 	watchingFile  bool
 	retryInterval time.Duration
+	decoder       notificationDecoder
 }
 
 // NewNotificationWatcher creates a new notification watcher
@@ -224,6 +225,7 @@ func (w *NotificationWatcher) retryWithInterval() error {
 // tailFile continuously reads new lines from the file
 func (w *NotificationWatcher) tailFile(file *os.File) error {
 	reader := bufio.NewReader(file)
+	var partial string // A line still being written
 
 	for {
 		select {
@@ -234,11 +236,13 @@ func (w *NotificationWatcher) tailFile(file *os.File) error {
 			if err != nil {
 				if err == io.EOF {
 					// No new data, wait a bit
+					partial += line
 					time.Sleep(100 * time.Millisecond)
 					continue
 				}
 				return fmt.Errorf("error reading line: %w", err)
 			}
+			line, partial = partial+line, ""
 
 			// Process the line
 			if len(line) > 0 {
@@ -248,15 +252,33 @@ func (w *NotificationWatcher) tailFile(file *os.File) error {
 	}
 }
 
-// processNotificationLine processes a single line from the notification log
+// processNotificationLine processes a single line from the notification log. A
+// payload spread over several lines is sent once its last line has been read.
 func (w *NotificationWatcher) processNotificationLine(line string) {
-	// Parse JSON notification event
+	for _, payload := range w.decoder.feed(line) {
+		notificationEvent, err := parseHookPayload(payload)
+		if err != nil {
+			logger.LogDebug("Skipping notification log entry: %v", err)
+			continue
+		}
+		w.eventSender.SendEvent(notificationEvent)
+	}
+}
+
+// ParseNotification parses a hook payload, as logged by the notification script or
+// sent by the hook subcommand
+func ParseNotification(data []byte) (*NotificationEvent, error) {
 	var notificationEvent NotificationEvent
-	if err := json.Unmarshal([]byte(line), &notificationEvent); err != nil {
-		// Send error through event handler if in debug mode
-		return
+	if err := json.Unmarshal(data, &notificationEvent); err != nil {
+		return nil, fmt.Errorf("invalid hook payload: %w", err)
 	}
 
-	// Send event to handler
-	w.eventSender.SendEvent(&notificationEvent)
+	// Hooks log without a transcript timestamp; use the logged time if any, otherwise the read time
+	notificationEvent.Timestamp = time.Now()
+	if notificationEvent.LoggedAt != "" {
+		if ts, err := ParseTimestamp(notificationEvent.LoggedAt, time.Local); err == nil {
+			notificationEvent.Timestamp = ts
+		}
+	}
+	return &notificationEvent, nil
 }
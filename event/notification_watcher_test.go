@@ -5,6 +5,7 @@ import (
 	"testing"
 
 	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
 )
 
 func TestProcessNotificationLine(t *testing.T) {
@@ -215,10 +216,13 @@ func TestProcessNotificationLine(t *testing.T) {
 				t.Fatalf("expected NotificationEvent, got %T", events[0])
 			}
 
-			// Compare events
-			if diff := cmp.Diff(tt.wantEvent, notificationEvent); diff != "" {
+			// Compare events; the read time is checked separately
+			if diff := cmp.Diff(tt.wantEvent, notificationEvent, cmpopts.IgnoreFields(NotificationEvent{}, "Timestamp")); diff != "" {
 				t.Errorf("NotificationEvent mismatch (-want +got):\n%s", diff)
 			}
+			if notificationEvent.Timestamp.IsZero() {
+				t.Error("expected the read time to be set")
+			}
 		})
 	}
 }
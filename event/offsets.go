@@ -0,0 +1,199 @@
+package event
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/kazegusuri/claude-companion/logger"
+)
+
+// fileOffset is how far a transcript has been read
+type fileOffset struct {
+	Path    string    `json:"path"`
+	Offset  int64     `json:"offset"`
+	Updated time.Time `json:"updated"`
+}
+
+// offsetStateFile is the file format of the persisted offsets
+type offsetStateFile struct {
+	Files []fileOffset `json:"files"`
+}
+
+// maxFileOffsets bounds the transcripts whose offsets are kept; the least recently read are dropped
+const maxFileOffsets = 1000
+
+// OffsetStore remembers how far each transcript has been read and persists it to a
+// JSON file, so tailing resumes where it stopped after a restart of the companion
+type OffsetStore struct {
+	path    string
+	mu      sync.Mutex
+	offsets map[string]*fileOffset // key: transcript path
+	dirty   bool
+	now     func() time.Time
+
+	done chan struct{}
+	wg   sync.WaitGroup
+}
+
+// NewOffsetStore creates an offset store persisted to path. An empty path keeps it in memory.
+func NewOffsetStore(path string) *OffsetStore {
+	return &OffsetStore{
+		path:    path,
+		offsets: make(map[string]*fileOffset),
+		now:     time.Now,
+	}
+}
+
+// Load reads the persisted offsets. A missing file is not an error.
+func (s *OffsetStore) Load() error {
+	if s.path == "" {
+		return nil
+	}
+	data, err := os.ReadFile(s.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read offset state: %w", err)
+	}
+	var file offsetStateFile
+	if err := json.Unmarshal(data, &file); err != nil {
+		return fmt.Errorf("failed to parse offset state %s: %w", s.path, err)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for i := range file.Files {
+		offset := file.Files[i]
+		if offset.Path != "" {
+			s.offsets[offset.Path] = &offset
+		}
+	}
+	return nil
+}
+
+// Save writes the offsets to the state file if they changed since the last save
+func (s *OffsetStore) Save() error {
+	if s.path == "" {
+		return nil
+	}
+	s.mu.Lock()
+	if !s.dirty {
+		s.mu.Unlock()
+		return nil
+	}
+	file := offsetStateFile{Files: make([]fileOffset, 0, len(s.offsets))}
+	for _, offset := range s.offsets {
+		file.Files = append(file.Files, *offset)
+	}
+	s.dirty = false
+	s.mu.Unlock()
+	sort.Slice(file.Files, func(i, j int) bool { return file.Files[i].Path < file.Files[j].Path })
+
+	data, err := json.MarshalIndent(file, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode offset state: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(s.path), 0o755); err != nil {
+		return fmt.Errorf("failed to create offset state directory: %w", err)
+	}
+	// Write a temporary file and rename it so a crash never leaves a partial file
+	tmp := s.path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o600); err != nil {
+		return fmt.Errorf("failed to write offset state: %w", err)
+	}
+	if err := os.Rename(tmp, s.path); err != nil {
+		return fmt.Errorf("failed to write offset state: %w", err)
+	}
+	return nil
+}
+
+// Start saves the offsets periodically in the background
+func (s *OffsetStore) Start(interval time.Duration) {
+	s.done = make(chan struct{})
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				if err := s.Save(); err != nil {
+					logger.LogError("Error saving offset state: %v", err)
+				}
+			case <-s.done:
+				return
+			}
+		}
+	}()
+}
+
+// Stop stops the periodic saves and saves the offsets one last time
+func (s *OffsetStore) Stop() {
+	if s.done != nil {
+		close(s.done)
+		s.wg.Wait()
+	}
+	if err := s.Save(); err != nil {
+		logger.LogError("Error saving offset state: %v", err)
+	}
+}
+
+// Offset returns how far a transcript has been read, or false if it never was
+func (s *OffsetStore) Offset(path string) (int64, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	offset, ok := s.offsets[path]
+	if !ok {
+		return 0, false
+	}
+	return offset.Offset, true
+}
+
+// Paths returns the transcripts that have an offset
+func (s *OffsetStore) Paths() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	paths := make([]string, 0, len(s.offsets))
+	for path := range s.offsets {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+	return paths
+}
+
+// SetOffset records how far a transcript has been read
+func (s *OffsetStore) SetOffset(path string, offset int64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	entry, ok := s.offsets[path]
+	if !ok {
+		s.evict()
+		entry = &fileOffset{Path: path}
+		s.offsets[path] = entry
+	}
+	entry.Offset = offset
+	entry.Updated = s.now()
+	s.dirty = true
+}
+
+// evict drops the least recently read transcript when the store has no room for another
+func (s *OffsetStore) evict() {
+	if len(s.offsets) < maxFileOffsets {
+		return
+	}
+	var oldest *fileOffset
+	for _, offset := range s.offsets {
+		if oldest == nil || offset.Updated.Before(oldest.Updated) {
+			oldest = offset
+		}
+	}
+	delete(s.offsets, oldest.Path)
+}
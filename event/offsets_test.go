@@ -0,0 +1,34 @@
+package event
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestOffsetStore_Persistence(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "state", "offsets.json")
+	store := NewOffsetStore(path)
+	store.SetOffset("/projects/app/s1.jsonl", 120)
+	store.SetOffset("/projects/app/s2.jsonl", 40)
+	store.SetOffset("/projects/app/s1.jsonl", 360)
+	if err := store.Save(); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+
+	reloaded := NewOffsetStore(path)
+	if err := reloaded.Load(); err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	for file, want := range map[string]int64{"/projects/app/s1.jsonl": 360, "/projects/app/s2.jsonl": 40} {
+		if got, ok := reloaded.Offset(file); !ok || got != want {
+			t.Errorf("Offset(%s) = %d, %v, want %d", file, got, ok, want)
+		}
+	}
+	if _, ok := reloaded.Offset("/projects/app/s3.jsonl"); ok {
+		t.Error("Offset() of an unknown file succeeded")
+	}
+
+	if err := NewOffsetStore(filepath.Join(t.TempDir(), "missing.json")).Load(); err != nil {
+		t.Errorf("Load() of a missing file error = %v", err)
+	}
+}
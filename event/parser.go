@@ -24,11 +24,34 @@ func NewParserWithPath(logPath string) *Parser {
 	}
 }
 
-// Parse parses a JSON line and returns the appropriate event type
+// SetRoot sets the projects root label of the parsed events
+func (p *Parser) SetRoot(root string) {
+	if p.session != nil {
+		p.session.Root = root
+	}
+}
+
+// Parse parses a JSON line and returns the appropriate event type. The line is first
+// upgraded to the current format by the schema of the Claude Code version that wrote it.
 func (p *Parser) Parse(line string) (Event, error) {
+	data := []byte(line)
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return nil, fmt.Errorf("failed to parse base event: %w", err)
+	}
+	version := rawString(fields["version"])
+	schema := SchemaFor(version)
+	if schema.upgrade != nil && schema.upgrade(fields) {
+		upgraded, err := json.Marshal(fields)
+		if err != nil {
+			return nil, fmt.Errorf("failed to upgrade %s event: %w", schema.Name, err)
+		}
+		data = upgraded
+	}
+
 	// First, parse to get the event type
 	var baseEvent BaseEvent
-	if err := json.Unmarshal([]byte(line), &baseEvent); err != nil {
+	if err := json.Unmarshal(data, &baseEvent); err != nil {
 		return nil, fmt.Errorf("failed to parse base event: %w", err)
 	}
 
@@ -36,17 +59,17 @@ func (p *Parser) Parse(line string) (Event, error) {
 	switch baseEvent.TypeString {
 	case EventTypeUser:
 		var event UserMessage
-		if err := json.Unmarshal([]byte(line), &event); err != nil {
+		if err := json.Unmarshal(data, &event); err != nil {
 			return nil, fmt.Errorf("failed to parse user message: %w", err)
 		}
-		event.Session = p.session
+		p.finish(&event.BaseEvent, &event, fields, schema)
 		return &event, nil
 	case EventTypeAssistant:
 		var event AssistantMessage
-		if err := json.Unmarshal([]byte(line), &event); err != nil {
+		if err := json.Unmarshal(data, &event); err != nil {
 			return nil, fmt.Errorf("failed to parse assistant message: %w", err)
 		}
-		event.Session = p.session
+		p.finish(&event.BaseEvent, &event, fields, schema)
 		return &event, nil
 	case EventTypeSystem:
 		// Check if it's a hook event by looking for hook-specific fields
@@ -55,14 +78,14 @@ func (p *Parser) Parse(line string) (Event, error) {
 			ToolUseID string `json:"toolUseID"`
 			Level     string `json:"level"`
 		}
-		if err := json.Unmarshal([]byte(line), &checkHook); err == nil {
+		if err := json.Unmarshal(data, &checkHook); err == nil {
 			// If it has ToolUseID and Level, and content matches hook pattern, it's likely a HookEvent
 			if checkHook.ToolUseID != "" && checkHook.Level != "" && checkHook.Content != "" {
 				var hookEvent HookEvent
-				if err := json.Unmarshal([]byte(line), &hookEvent); err == nil {
+				if err := json.Unmarshal(data, &hookEvent); err == nil {
 					// Try to parse the hook content
 					if err := hookEvent.ParseHookContent(); err == nil {
-						hookEvent.Session = p.session
+						p.finish(&hookEvent.BaseEvent, &hookEvent, fields, schema)
 						return &hookEvent, nil
 					}
 				}
@@ -71,21 +94,31 @@ func (p *Parser) Parse(line string) (Event, error) {
 
 		// Otherwise, parse as regular SystemMessage
 		var event SystemMessage
-		if err := json.Unmarshal([]byte(line), &event); err != nil {
+		if err := json.Unmarshal(data, &event); err != nil {
 			return nil, fmt.Errorf("failed to parse system message: %w", err)
 		}
-		event.Session = p.session
+		p.finish(&event.BaseEvent, &event, fields, schema)
 		return &event, nil
 	case EventTypeSummary:
 		var event SummaryEvent
-		if err := json.Unmarshal([]byte(line), &event); err != nil {
+		if err := json.Unmarshal(data, &event); err != nil {
 			return nil, fmt.Errorf("failed to parse summary event: %w", err)
 		}
 		// SummaryEvent doesn't have BaseEvent, so we don't set Session
 		return &event, nil
 	default:
 		// Return base event for unknown types
-		baseEvent.Session = p.session
+		p.finish(&baseEvent, &baseEvent, fields, schema)
 		return &baseEvent, nil
 	}
 }
+
+// finish sets the session and schema of a parsed event and keeps the fields it does not decode
+func (p *Parser) finish(base *BaseEvent, event any, fields map[string]json.RawMessage, schema *Schema) {
+	base.Session = p.session
+	base.Schema = schema.Name
+	base.Extra = unknownFields(fields, event)
+	if base.Extra != nil {
+		reportUnknownFields(base.TypeString, base.Version, base.Extra)
+	}
+}
@@ -961,3 +961,22 @@ func TestParserWithPath(t *testing.T) {
 		t.Errorf("Session.Session = %v, want test-session", userMsg.Session.Session)
 	}
 }
+
+func TestParserSetRoot(t *testing.T) {
+	parser := NewParserWithPath("/home/user/.claude/projects/test-project/test-session.jsonl")
+	parser.SetRoot("work")
+
+	input := `{"type":"user","timestamp":"2025-01-26T15:30:45Z","uuid":"123","message":{"role":"user","content":"Hello"}}`
+	event, err := parser.Parse(input)
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+
+	userMsg, ok := event.(*UserMessage)
+	if !ok {
+		t.Fatalf("Parse() returned wrong type: %T", event)
+	}
+	if userMsg.Session == nil || userMsg.Session.Root != "work" {
+		t.Errorf("Session = %+v, want Root work", userMsg.Session)
+	}
+}
@@ -0,0 +1,106 @@
+package event
+
+import (
+	"strings"
+
+	"github.com/kazegusuri/claude-companion/narrator"
+)
+
+// ScorePriority scores an event with the same rules used for its narration,
+// so the audio queue, sinks and streaming clients agree on what is urgent
+func ScorePriority(event Event, scorer narrator.PriorityScorer) int {
+	switch e := event.(type) {
+	case *AssistantMessage:
+		if e.IsApiErrorMessage {
+			return scorer.ScorePriority(narrator.PriorityInput{Type: narrator.NarrationTypeError})
+		}
+		score := narrator.PriorityRoutine
+		for _, content := range e.Message.Content {
+			var in narrator.PriorityInput
+			switch content.Type {
+			case "text":
+				in = narrator.PriorityInput{Type: narrator.NarrationTypeText, Text: content.Text}
+			case "tool_use":
+				in = narrator.PriorityInput{Type: narrator.NarrationTypeToolUse, ToolName: content.Name}
+				if strings.HasPrefix(content.Name, "mcp__") {
+					in.Type = narrator.NarrationTypeToolUseMCP
+				}
+			default:
+				continue
+			}
+			score = max(score, scorer.ScorePriority(in))
+		}
+		return score
+	case *UserMessage:
+		if hasToolError(e) {
+			return scorer.ScorePriority(narrator.PriorityInput{Type: narrator.NarrationTypeError})
+		}
+		return narrator.PriorityLow
+	case *SystemMessage:
+		if e.IsApiErrorMessage || e.Level == "error" {
+			return scorer.ScorePriority(narrator.PriorityInput{Type: narrator.NarrationTypeError, Text: e.Content})
+		}
+		return scorer.ScorePriority(narrator.PriorityInput{Type: narrator.NarrationTypeNotification, Text: e.Content})
+	case *HookEvent:
+		return scorer.ScorePriority(narrator.PriorityInput{Type: narrator.NarrationTypeNotification, Text: e.Content})
+	case *NotificationEvent:
+		switch e.HookEventName {
+		case "Notification":
+			return scorer.ScorePriority(narrator.PriorityInput{Type: narrator.NarrationTypeToolUsePermission, Text: e.Message})
+		case "PreToolUse", "PostToolUse":
+			in := narrator.PriorityInput{Type: narrator.NarrationTypeToolUse, ToolName: e.ToolName}
+			if strings.HasPrefix(e.ToolName, "mcp__") {
+				in.Type = narrator.NarrationTypeToolUseMCP
+			}
+			return scorer.ScorePriority(in)
+		}
+		return scorer.ScorePriority(narrator.PriorityInput{Type: narrator.NarrationTypeNotification, Text: e.Message})
+	case *TaskCompletionMessage, *SessionSummaryMessage, *CatchUpMessage, *ContextAlertMessage:
+		return scorer.ScorePriority(narrator.PriorityInput{Type: narrator.NarrationTypeNotification})
+	case *ToolSLABreachMessage:
+		return scorer.ScorePriority(narrator.PriorityInput{Type: narrator.NarrationTypeError, ToolName: e.ToolName})
+	case *BudgetAlertMessage:
+		return scorer.ScorePriority(narrator.PriorityInput{Type: narrator.NarrationTypeError})
+	case *SecurityAlertMessage:
+		return scorer.ScorePriority(narrator.PriorityInput{Type: narrator.NarrationTypeSecurity, ToolName: e.ToolName, Target: e.Target})
+	}
+	return narrator.PriorityLow
+}
+
+// PriorityOf returns the priority assigned to an event, or narrator.PriorityLow if it has none
+func PriorityOf(event Event) int {
+	if p := priorityOf(event); p != nil {
+		return *p
+	}
+	return narrator.PriorityLow
+}
+
+// priorityOf returns a pointer to the priority of an event, or nil if it has none
+func priorityOf(event Event) *int {
+	if e, ok := event.(*NotificationEvent); ok {
+		return &e.Priority
+	}
+	if base := BaseOf(event); base != nil {
+		return &base.Priority
+	}
+	return nil
+}
+
+// hasToolError reports whether a user message carries a failed tool result
+func hasToolError(msg *UserMessage) bool {
+	items, ok := msg.Message.Content.([]interface{})
+	if !ok {
+		return false
+	}
+	for _, item := range items {
+		if m, ok := item.(map[string]interface{}); ok && m["type"] == "tool_result" && m["is_error"] == true {
+			return true
+		}
+	}
+	return false
+}
+
+// newDefaultPriorityScorer creates the rule-based scorer used unless another one is set
+func newDefaultPriorityScorer() narrator.PriorityScorer {
+	return narrator.NewRulePriorityScorer()
+}
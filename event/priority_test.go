@@ -0,0 +1,100 @@
+package event
+
+import (
+	"testing"
+
+	"github.com/kazegusuri/claude-companion/narrator"
+)
+
+func TestScorePriority(t *testing.T) {
+	tests := []struct {
+		name  string
+		event Event
+		want  int
+	}{
+		{
+			name: "routine read",
+			event: &AssistantMessage{Message: AssistantMessageContent{Content: []AssistantContent{
+				{Type: "tool_use", Name: "Read"},
+			}}},
+			want: narrator.PriorityRoutine,
+		},
+		{
+			name: "highest content wins",
+			event: &AssistantMessage{Message: AssistantMessageContent{Content: []AssistantContent{
+				{Type: "tool_use", Name: "Read"},
+				{Type: "text", Text: "Should I also update the docs?"},
+			}}},
+			want: narrator.PriorityUrgent,
+		},
+		{
+			name:  "api error",
+			event: &AssistantMessage{IsApiErrorMessage: true},
+			want:  narrator.PriorityUrgent,
+		},
+		{
+			name: "failed tool result",
+			event: &UserMessage{Message: UserMessageContent{Content: []interface{}{
+				map[string]interface{}{"type": "tool_result", "tool_use_id": "t1", "is_error": true, "content": "exit status 1"},
+			}}},
+			want: narrator.PriorityUrgent,
+		},
+		{
+			name:  "user prompt",
+			event: &UserMessage{Message: UserMessageContent{Content: "hello"}},
+			want:  narrator.PriorityLow,
+		},
+		{
+			name:  "permission request",
+			event: &NotificationEvent{HookEventName: "Notification", Message: "Claude needs your permission to use Bash"},
+			want:  narrator.PriorityUrgent,
+		},
+		{
+			name:  "pre tool use",
+			event: &NotificationEvent{HookEventName: "PreToolUse", ToolName: "Glob"},
+			want:  narrator.PriorityRoutine,
+		},
+		{
+			name:  "stop",
+			event: &NotificationEvent{HookEventName: "Stop"},
+			want:  4,
+		},
+		{
+			name:  "summary",
+			event: &SummaryEvent{Summary: "done"},
+			want:  narrator.PriorityLow,
+		},
+	}
+
+	scorer := narrator.NewRulePriorityScorer()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ScorePriority(tt.event, scorer); got != tt.want {
+				t.Errorf("ScorePriority() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestHandlerAssignsPriority(t *testing.T) {
+	h := &Handler{scorer: narrator.NewRulePriorityScorer()}
+
+	msg := &AssistantMessage{Message: AssistantMessageContent{Content: []AssistantContent{
+		{Type: "text", Text: "The migration failed."},
+	}}}
+	h.assignPriority(msg)
+	if got := PriorityOf(msg); got != narrator.PriorityUrgent {
+		t.Errorf("PriorityOf(AssistantMessage) = %d, want %d", got, narrator.PriorityUrgent)
+	}
+
+	notification := &NotificationEvent{HookEventName: "PreToolUse", ToolName: "Edit"}
+	h.assignPriority(notification)
+	if got := PriorityOf(notification); got != 1 {
+		t.Errorf("PriorityOf(NotificationEvent) = %d, want 1", got)
+	}
+
+	// Events without a priority field fall back to low
+	if got := PriorityOf(&SummaryEvent{}); got != narrator.PriorityLow {
+		t.Errorf("PriorityOf(SummaryEvent) = %d, want %d", got, narrator.PriorityLow)
+	}
+}
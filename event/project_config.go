@@ -0,0 +1,213 @@
+package event
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/kazegusuri/claude-companion/logger"
+	"github.com/kazegusuri/claude-companion/narrator"
+	"gopkg.in/yaml.v3"
+)
+
+// ProjectConfigFile is the name of the per-project configuration file, looked up
+// from the working directory of a session up to the file system root
+const ProjectConfigFile = ".claude-companion.yaml"
+
+// projectConfigTTL is how long a resolved project config is used before its files are checked for changes
+const projectConfigTTL = 30 * time.Second
+
+// maxProjectConfigDirs bounds the working directories whose config is cached
+const maxProjectConfigDirs = 1000
+
+// ProjectConfig overrides the companion settings for the events of one project
+type ProjectConfig struct {
+	Narrator struct {
+		Config string `yaml:"config"` // Narrator rules JSON, relative to the config file
+	} `yaml:"narrator"`
+	Voice struct {
+		Speaker *int `yaml:"speaker"`
+		Mute    bool `yaml:"mute"`
+	} `yaml:"voice"`
+	Filters struct {
+		IncludeEvents []string `yaml:"includeEvents"`
+		ExcludeEvents []string `yaml:"excludeEvents"`
+		IncludeTools  []string `yaml:"includeTools"`
+		ExcludeTools  []string `yaml:"excludeTools"`
+	} `yaml:"filters"`
+	Notifications struct {
+		Desktop *bool `yaml:"desktop"`
+	} `yaml:"notifications"`
+
+	path      string
+	filter    *EventFilter
+	overrides *narrator.ProjectOverrides
+	modTimes  map[string]time.Time // Files the config was built from
+}
+
+// LoadProjectConfig loads a project config file and the narrator rules it refers to
+func LoadProjectConfig(path string) (*ProjectConfig, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read project config: %w", err)
+	}
+	c := &ProjectConfig{path: path, modTimes: make(map[string]time.Time)}
+	if err := yaml.Unmarshal(data, c); err != nil {
+		return nil, fmt.Errorf("failed to parse project config %s: %w", path, err)
+	}
+	c.recordModTime(path)
+
+	c.filter, err = NewEventFilter(c.Filters.IncludeEvents, c.Filters.ExcludeEvents, c.Filters.IncludeTools, c.Filters.ExcludeTools)
+	if err != nil {
+		return nil, fmt.Errorf("invalid filters in project config %s: %w", path, err)
+	}
+
+	c.overrides = &narrator.ProjectOverrides{Speaker: c.Voice.Speaker, Mute: c.Voice.Mute}
+	if c.Narrator.Config != "" {
+		configPath := c.Narrator.Config
+		if !filepath.IsAbs(configPath) {
+			configPath = filepath.Join(filepath.Dir(path), configPath)
+		}
+		c.overrides.Config, err = narrator.LoadNarratorConfig(configPath)
+		if err != nil {
+			return nil, fmt.Errorf("invalid narrator config in project config %s: %w", path, err)
+		}
+		c.recordModTime(configPath)
+	}
+	return c, nil
+}
+
+// recordModTime remembers the modification time of a file the config was built from
+func (c *ProjectConfig) recordModTime(path string) {
+	if info, err := os.Stat(path); err == nil {
+		c.modTimes[path] = info.ModTime()
+	}
+}
+
+// changed reports whether a file the config was built from changed or disappeared
+func (c *ProjectConfig) changed() bool {
+	for path, modTime := range c.modTimes {
+		info, err := os.Stat(path)
+		if err != nil || !info.ModTime().Equal(modTime) {
+			return true
+		}
+	}
+	return false
+}
+
+// Path returns the path of the config file
+func (c *ProjectConfig) Path() string {
+	if c == nil {
+		return ""
+	}
+	return c.path
+}
+
+// Overrides returns the narration settings the project overrides, or nil without a config
+func (c *ProjectConfig) Overrides() *narrator.ProjectOverrides {
+	if c == nil {
+		return nil
+	}
+	return c.overrides
+}
+
+// DesktopNotify reports whether desktop notifications are allowed for the project
+func (c *ProjectConfig) DesktopNotify() bool {
+	return c == nil || c.Notifications.Desktop == nil || *c.Notifications.Desktop
+}
+
+// Filter returns the project's event filter, or nil without a config
+func (c *ProjectConfig) Filter() *EventFilter {
+	if c == nil {
+		return nil
+	}
+	return c.filter
+}
+
+// projectConfigEntry is the config resolved for a working directory
+type projectConfigEntry struct {
+	config  *ProjectConfig // nil when no config file applies
+	checked time.Time
+}
+
+// ProjectConfigs resolves and caches the project config of each working directory.
+// Resolved configs are checked for changes every 30 seconds.
+type ProjectConfigs struct {
+	mu      sync.Mutex
+	entries map[string]*projectConfigEntry // key: working directory
+	now     func() time.Time
+}
+
+// NewProjectConfigs creates an empty project config resolver
+func NewProjectConfigs() *ProjectConfigs {
+	return &ProjectConfigs{
+		entries: make(map[string]*projectConfigEntry),
+		now:     time.Now,
+	}
+}
+
+// For returns the project config that applies to a working directory, or nil if none does
+func (p *ProjectConfigs) For(cwd string) *ProjectConfig {
+	if cwd == "" {
+		return nil
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	now := p.now()
+	entry, ok := p.entries[cwd]
+	if ok && now.Sub(entry.checked) < projectConfigTTL {
+		return entry.config
+	}
+	if !ok {
+		if len(p.entries) >= maxProjectConfigDirs {
+			p.entries = make(map[string]*projectConfigEntry)
+		}
+		entry = &projectConfigEntry{}
+		p.entries[cwd] = entry
+	}
+	entry.checked = now
+
+	path := findProjectConfig(cwd)
+	if entry.config != nil && entry.config.path == path && !entry.config.changed() {
+		return entry.config
+	}
+	entry.config = nil
+	if path == "" {
+		return nil
+	}
+	config, err := LoadProjectConfig(path)
+	if err != nil {
+		logger.LogWarning("Ignoring project config: %v", err)
+		return nil
+	}
+	entry.config = config
+	return config
+}
+
+// Reset forgets the resolved configs, so every project config is loaded again
+func (p *ProjectConfigs) Reset() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.entries = make(map[string]*projectConfigEntry)
+}
+
+// findProjectConfig returns the nearest project config file from dir up to the root, or ""
+func findProjectConfig(dir string) string {
+	dir = filepath.Clean(dir)
+	for {
+		path := filepath.Join(dir, ProjectConfigFile)
+		if info, err := os.Stat(path); err == nil && !info.IsDir() {
+			return path
+		} else if err != nil && !errors.Is(err, os.ErrNotExist) {
+			return ""
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return ""
+		}
+		dir = parent
+	}
+}
@@ -0,0 +1,113 @@
+package event
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// writeFile writes a test file, creating its directory
+func writeFile(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestProjectConfigs_For(t *testing.T) {
+	root := t.TempDir()
+	project := filepath.Join(root, "app")
+	writeFile(t, filepath.Join(project, ProjectConfigFile), `
+narrator:
+  config: narrator.json
+voice:
+  speaker: 3
+filters:
+  excludeTools: [Bash]
+notifications:
+  desktop: false
+`)
+	writeFile(t, filepath.Join(project, "narrator.json"), `{"messages": {"genericToolExecution": "{tool}を使います"}}`)
+	writeFile(t, filepath.Join(root, "broken", ProjectConfigFile), "filters:\n  includeEvents: [nope]\n")
+
+	now := time.Date(2025, 1, 26, 10, 0, 0, 0, time.UTC)
+	configs := NewProjectConfigs()
+	configs.now = func() time.Time { return now }
+
+	config := configs.For(filepath.Join(project, "src", "pkg"))
+	if config == nil {
+		t.Fatalf("For() found no config from a subdirectory")
+	}
+	if config.Path() != filepath.Join(project, ProjectConfigFile) {
+		t.Errorf("Path() = %s", config.Path())
+	}
+	o := config.Overrides()
+	if o.Speaker == nil || *o.Speaker != 3 || o.Mute || o.Config == nil || o.Config.Messages.GenericToolExecution != "{tool}を使います" {
+		t.Errorf("Overrides() = %+v", o)
+	}
+	if config.DesktopNotify() {
+		t.Errorf("DesktopNotify() = true, want false")
+	}
+
+	if got := configs.For(filepath.Join(root, "other")); got != nil {
+		t.Errorf("For() outside the project = %s, want none", got.Path())
+	}
+	if got := configs.For(filepath.Join(root, "broken")); got != nil {
+		t.Errorf("For() with an invalid config = %s, want none", got.Path())
+	}
+	var none *ProjectConfig
+	if none.Overrides() != nil || !none.DesktopNotify() || none.Filter() != nil {
+		t.Errorf("nil ProjectConfig should not override anything")
+	}
+
+	// Changes are picked up once the cached config expires
+	writeFile(t, filepath.Join(project, ProjectConfigFile), "voice:\n  mute: true\n")
+	os.Chtimes(filepath.Join(project, ProjectConfigFile), now, now.Add(time.Hour))
+	if got := configs.For(filepath.Join(project, "src", "pkg")); got != config {
+		t.Errorf("For() reloaded the config before it expired")
+	}
+	now = now.Add(projectConfigTTL)
+	if got := configs.For(filepath.Join(project, "src", "pkg")); got == config || !got.Overrides().Mute {
+		t.Errorf("For() did not reload the changed config")
+	}
+}
+
+func TestHandler_ProjectConfigFilter(t *testing.T) {
+	root := t.TempDir()
+	quiet := filepath.Join(root, "quiet")
+	writeFile(t, filepath.Join(quiet, ProjectConfigFile), "filters:\n  excludeEvents: [user]\n")
+
+	mockFormatter := &mockFormatterWithRecording{}
+	handler := &Handler{
+		narrator:    &mockNarrator{},
+		formatter:   mockFormatter,
+		eventChan:   make(chan Event, 100),
+		done:        make(chan struct{}),
+		taskTracker: NewTaskTracker(),
+		buffers:     make(map[string]*BufferInfo),
+	}
+	handler.SetSessionRegistry(NewSessionRegistry(""))
+	handler.SetProjectConfigs(NewProjectConfigs())
+
+	parent := "parent"
+	filtered := createTestUserMessage("quiet", &parent)
+	filtered.CWD = quiet
+	// No CWD on the event: the session's last known CWD is used
+	alsoFiltered := createTestUserMessage("quiet", &parent)
+	shown := createTestUserMessage("loud", &parent)
+	shown.CWD = filepath.Join(root, "loud")
+
+	captureOutput(t, func() {
+		handler.processEvent(filtered)
+		handler.processEvent(alsoFiltered)
+		handler.processEvent(shown)
+	})
+
+	if got := mockFormatter.getProcessedCount(); got != 1 {
+		t.Errorf("processed %d events, want only the event outside the filtered project", got)
+	}
+}
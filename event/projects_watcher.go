@@ -1,25 +1,53 @@
 package event
 
 import (
+	"fmt"
+	"io/fs"
 	"os"
 	"path/filepath"
 	"strings"
 	"sync"
+	"time"
 
 	"github.com/fsnotify/fsnotify"
 	"github.com/kazegusuri/claude-companion/logger"
 )
 
+// Watch modes of a projects root
+const (
+	WatchModeAuto     = "auto"     // fsnotify, or polling if it cannot be used
+	WatchModeFSNotify = "fsnotify" // File system notifications only
+	WatchModePoll     = "poll"     // Scan the root periodically, for NFS and WSL mounts
+)
+
+// DefaultPollInterval is how often a polled projects root is scanned by default
+const DefaultPollInterval = 2 * time.Second
+
+// ParseWatchMode parses the name of a watch mode
+func ParseWatchMode(name string) (string, error) {
+	switch name {
+	case WatchModeAuto, WatchModeFSNotify, WatchModePoll:
+		return name, nil
+	case "":
+		return WatchModeAuto, nil
+	default:
+		return "", fmt.Errorf("unknown watch mode %q (expected auto, fsnotify or poll)", name)
+	}
+}
+
 // ProjectsWatcher watches the ~/.claude/projects directory for changes
 type ProjectsWatcher struct {
 	rootPath       string
-	watcher        *fsnotify.Watcher
+	watcher        *fsnotify.Watcher // nil while polling
 	sessionManager *SessionFileManager
-	debugMode      bool
 	done           chan struct{}
 	wg             sync.WaitGroup
 	projectFilter  string
 	sessionFilter  string
+	offsets        *OffsetStore
+	mode           string
+	pollInterval   time.Duration
+	stats          *WatchStats
 }
 
 // NewProjectsWatcher creates a new projects watcher
@@ -33,19 +61,14 @@ func NewProjectsWatcher(rootPath string, handler *Handler) (*ProjectsWatcher, er
 		rootPath = filepath.Join(home, rootPath[2:])
 	}
 
-	watcher, err := fsnotify.NewWatcher()
-	if err != nil {
-		return nil, err
-	}
-
 	sessionManager := NewSessionFileManager(handler)
 
 	return &ProjectsWatcher{
 		rootPath:       rootPath,
-		watcher:        watcher,
 		sessionManager: sessionManager,
-		debugMode:      handler.debugMode,
 		done:           make(chan struct{}),
+		mode:           WatchModeAuto,
+		pollInterval:   DefaultPollInterval,
 	}, nil
 }
 
@@ -54,19 +77,65 @@ func (w *ProjectsWatcher) Start() error {
 	// Start session manager
 	w.sessionManager.Start()
 
-	// Add root directory and all subdirectories
-	if err := w.addDirectoryTree(w.rootPath); err != nil {
-		return err
+	mode := WatchModePoll
+	if w.mode != WatchModePoll {
+		err := w.startFSNotify()
+		switch {
+		case err == nil:
+			mode = WatchModeFSNotify
+		case w.mode == WatchModeFSNotify:
+			w.sessionManager.Stop()
+			return err
+		default:
+			logger.LogWarning("Polling %s every %s since file system notifications cannot be used: %v", w.rootPath, w.pollInterval, err)
+		}
 	}
+	if mode == WatchModePoll {
+		sizes := make(map[string]int64)
+		w.scan(sizes, false) // Files as they are at start are not written to yet
+		w.wg.Add(1)
+		go w.poll(sizes)
+	}
+	w.stats.setRoot(w.rootPath, mode)
+	w.resumeSessions()
 
-	// Start watching
-	w.wg.Add(1)
-	go w.watch()
+	logger.LogDebug("Started watching projects directory: %s", w.rootPath)
+	return nil
+}
+
+// SetLabel sets the label attached to the sessions of this projects root
+func (w *ProjectsWatcher) SetLabel(label string) {
+	w.sessionManager.SetRoot(label)
+}
+
+// SetOffsetStore makes session watchers resume from the offsets saved in offsets
+func (w *ProjectsWatcher) SetOffsetStore(offsets *OffsetStore) {
+	w.offsets = offsets
+	w.sessionManager.SetOffsetStore(offsets)
+}
 
-	if w.debugMode {
-		logger.LogInfo("Started watching projects directory: %s", w.rootPath)
+// SetWatchMode sets how the projects root is watched, and how often it is scanned when polled
+func (w *ProjectsWatcher) SetWatchMode(mode string, pollInterval time.Duration) {
+	w.mode = mode
+	if pollInterval > 0 {
+		w.pollInterval = pollInterval
 	}
-	return nil
+}
+
+// SetReadRate limits how many lines per second are read from each session file; zero is unlimited
+func (w *ProjectsWatcher) SetReadRate(linesPerSecond int) {
+	w.sessionManager.SetReadRate(linesPerSecond)
+}
+
+// SetWatchStats sets where the watchers report their status
+func (w *ProjectsWatcher) SetWatchStats(stats *WatchStats) {
+	w.stats = stats
+	w.sessionManager.SetWatchStats(stats)
+}
+
+// SetTap sets where session watchers forward the lines they read
+func (w *ProjectsWatcher) SetTap(tap *Tap) {
+	w.sessionManager.SetTap(tap)
 }
 
 // SetProjectFilter sets the project filter
@@ -82,11 +151,78 @@ func (w *ProjectsWatcher) SetSessionFilter(session string) {
 // Stop stops the watcher
 func (w *ProjectsWatcher) Stop() {
 	close(w.done)
-	w.watcher.Close()
+	if w.watcher != nil {
+		w.watcher.Close()
+	}
 	w.wg.Wait()
 	w.sessionManager.Stop()
 }
 
+// resumeSessions watches the session files that grew past their saved offset while
+// the companion was not running, to catch up on them without waiting for a write
+func (w *ProjectsWatcher) resumeSessions() {
+	if w.offsets == nil {
+		return
+	}
+	for _, path := range w.offsets.Paths() {
+		rel, err := filepath.Rel(w.rootPath, path)
+		if err != nil || strings.HasPrefix(rel, "..") || !w.shouldProcessFile(path) {
+			continue
+		}
+		info, err := os.Stat(path)
+		if err != nil {
+			continue
+		}
+		if offset, _ := w.offsets.Offset(path); info.Size() > offset {
+			if err := w.sessionManager.AddOrUpdateWatcher(path); err != nil {
+				logger.LogError("Error resuming watcher for file: %v", err)
+			}
+		}
+	}
+}
+
+// startFSNotify watches the projects root with file system notifications
+func (w *ProjectsWatcher) startFSNotify() error {
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return err
+	}
+	// The root itself must be watchable; subdirectories that are not are only logged
+	if err := watcher.Add(w.rootPath); err != nil {
+		watcher.Close()
+		return err
+	}
+	w.watcher = watcher
+	if err := w.addDirectoryTree(w.rootPath); err != nil {
+		w.watcher = nil
+		watcher.Close()
+		return err
+	}
+	w.wg.Add(1)
+	go w.watch()
+	return nil
+}
+
+// skipDir reports whether a directory found walking from root is not watched: a
+// hidden one other than .claude, or a project other than the project filter
+func (w *ProjectsWatcher) skipDir(path, root string) bool {
+	name := filepath.Base(path)
+	if strings.HasPrefix(name, ".") && name != ".claude" && path != root {
+		return true
+	}
+	if w.projectFilter != "" {
+		// Get the project name (first component of the path relative to the projects root)
+		rel, err := filepath.Rel(w.rootPath, path)
+		if err == nil && rel != "." {
+			parts := strings.Split(rel, string(filepath.Separator))
+			if len(parts) > 0 && parts[0] != w.projectFilter {
+				return true
+			}
+		}
+	}
+	return false
+}
+
 // addDirectoryTree recursively adds directories to the watcher
 func (w *ProjectsWatcher) addDirectoryTree(root string) error {
 	return filepath.Walk(root, func(path string, info os.FileInfo, err error) error {
@@ -101,32 +237,14 @@ func (w *ProjectsWatcher) addDirectoryTree(root string) error {
 
 		// Only watch directories
 		if info.IsDir() {
-			// Skip hidden directories (except .claude itself)
-			if strings.HasPrefix(info.Name(), ".") && info.Name() != ".claude" && path != root {
+			if w.skipDir(path, root) {
 				return filepath.SkipDir
 			}
 
-			// Apply project filter
-			if w.projectFilter != "" {
-				// Check if this directory is under the projects root
-				rel, err := filepath.Rel(w.rootPath, path)
-				if err == nil && rel != "." {
-					// Get the project name (first component of relative path)
-					parts := strings.Split(rel, string(filepath.Separator))
-					if len(parts) > 0 && parts[0] != w.projectFilter {
-						return filepath.SkipDir
-					}
-				}
-			}
-
 			if err := w.watcher.Add(path); err != nil {
-				if w.debugMode {
-					logger.LogError("Error adding directory to watcher: %s - %v", path, err)
-				}
+				logger.LogDebug("Error adding directory to watcher: %s - %v", path, err)
 			} else {
-				if w.debugMode {
-					logger.LogInfo("Watching directory: %s", path)
-				}
+				logger.LogDebug("Watching directory: %s", path)
 			}
 		}
 
@@ -158,6 +276,62 @@ func (w *ProjectsWatcher) watch() {
 	}
 }
 
+// poll scans the projects root every poll interval, handling session files that
+// appeared or changed size since the last scan as fsnotify events would be
+func (w *ProjectsWatcher) poll(sizes map[string]int64) {
+	defer w.wg.Done()
+
+	ticker := time.NewTicker(w.pollInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			w.scan(sizes, true)
+		case <-w.done:
+			return
+		}
+	}
+}
+
+// scan records the size of every session file, and with notify, watches those
+// that are new or changed since the last scan
+func (w *ProjectsWatcher) scan(sizes map[string]int64, notify bool) {
+	found := make(map[string]bool, len(sizes))
+	filepath.WalkDir(w.rootPath, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return nil // Skip what we can't access; the root may not exist yet
+		}
+		if d.IsDir() {
+			if w.skipDir(path, w.rootPath) {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !strings.HasSuffix(path, ".jsonl") || !w.shouldProcessFile(path) {
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil {
+			return nil
+		}
+		found[path] = true
+		size, known := sizes[path]
+		sizes[path] = info.Size()
+		if notify && (!known || size != info.Size()) {
+			logger.LogDebug("Session file changed: %s", path)
+			if err := w.sessionManager.AddOrUpdateWatcher(path); err != nil {
+				logger.LogError("Error updating watcher for file: %v", err)
+			}
+		}
+		return nil
+	})
+	for path := range sizes {
+		if !found[path] {
+			delete(sizes, path)
+		}
+	}
+}
+
 // shouldProcessFile checks if a file should be processed based on filters
 func (w *ProjectsWatcher) shouldProcessFile(path string) bool {
 	// Apply project filter
@@ -193,14 +367,8 @@ func (w *ProjectsWatcher) handleEvent(event fsnotify.Event) {
 		if event.Op&fsnotify.Create == fsnotify.Create {
 			if info, err := os.Stat(event.Name); err == nil && info.IsDir() {
 				// Check if we should watch this directory based on project filter
-				if w.projectFilter != "" {
-					rel, err := filepath.Rel(w.rootPath, event.Name)
-					if err == nil && rel != "." {
-						parts := strings.Split(rel, string(filepath.Separator))
-						if len(parts) > 0 && parts[0] != w.projectFilter {
-							return
-						}
-					}
+				if w.skipDir(event.Name, event.Name) {
+					return
 				}
 				if err := w.addDirectoryTree(event.Name); err != nil {
 					logger.LogError("Error adding new directory: %v", err)
@@ -218,25 +386,19 @@ func (w *ProjectsWatcher) handleEvent(event fsnotify.Event) {
 	// Handle .jsonl file events
 	switch {
 	case event.Op&fsnotify.Create == fsnotify.Create:
-		if w.debugMode {
-			logger.LogInfo("New session file created: %s", event.Name)
-		}
+		logger.LogDebug("New session file created: %s", event.Name)
 		if err := w.sessionManager.AddOrUpdateWatcher(event.Name); err != nil {
 			logger.LogError("Error creating watcher for new file: %v", err)
 		}
 
 	case event.Op&fsnotify.Write == fsnotify.Write:
-		if w.debugMode {
-			logger.LogInfo("Session file updated: %s", event.Name)
-		}
+		logger.LogDebug("Session file updated: %s", event.Name)
 		if err := w.sessionManager.AddOrUpdateWatcher(event.Name); err != nil {
 			logger.LogError("Error updating watcher for file: %v", err)
 		}
 
 	case event.Op&fsnotify.Remove == fsnotify.Remove:
-		if w.debugMode {
-			logger.LogInfo("Session file removed: %s", event.Name)
-		}
+		logger.LogDebug("Session file removed: %s", event.Name)
 		// The session manager will clean it up automatically on idle timeout
 	}
 }
@@ -85,3 +85,73 @@ func TestProjectsWatcherHomeExpansion(t *testing.T) {
 		t.Errorf("Home directory not expanded: %s", watcher.rootPath)
 	}
 }
+
+func TestProjectsWatcher_Polling(t *testing.T) {
+	root := t.TempDir()
+	path := filepath.Join(root, "-work", "s1.jsonl")
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, nil, 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	handler := NewHandler(narrator.NewNoOpNarrator(), false)
+	sink := &collectingSink{}
+	handler.AddSink(sink)
+	handler.Start()
+	defer handler.Stop()
+
+	stats := NewWatchStats()
+	watcher, err := NewProjectsWatcher(root, handler)
+	if err != nil {
+		t.Fatal(err)
+	}
+	watcher.SetWatchMode(WatchModePoll, 20*time.Millisecond)
+	watcher.SetWatchStats(stats)
+	if err := watcher.Start(); err != nil {
+		t.Fatal(err)
+	}
+	defer watcher.Stop()
+	if status := stats.Status(); len(status.Roots) != 1 || status.Roots[0].Mode != WatchModePoll {
+		t.Errorf("roots = %+v, want %s polled", status.Roots, root)
+	}
+
+	// A write found by a scan starts a session watcher, which reads the lines after it
+	line := `{"type":"user","uuid":"u1","parentUuid":"p","timestamp":"2025-01-01T10:00:00Z","message":{"role":"user","content":"hello"}}` + "\n"
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	f.WriteString("{}\n")
+	for i := 0; i < 100 && watcher.GetActiveWatcherCount() == 0; i++ {
+		time.Sleep(10 * time.Millisecond)
+	}
+	time.Sleep(200 * time.Millisecond) // Let the session watcher reach the end of the file
+	f.WriteString(line)
+	if user, ok := sink.wait(t, 1)[0].(*UserMessage); !ok || user.UUID != "u1" {
+		t.Errorf("event = %#v, want the user message", user)
+	}
+}
+
+func TestWatchStats_Status(t *testing.T) {
+	stats := NewWatchStats()
+	stats.setRoot("/projects", WatchModeFSNotify)
+	stats.read("/projects/app/s1.jsonl", 100, time.Time{})
+	stats.setSize("/projects/app/s1.jsonl", 100, 400)
+	stats.throttle("/projects/app/s1.jsonl")
+	stats.read("/projects/app/s2.jsonl", 50, time.Time{})
+	stats.read("/projects/app/s3.jsonl", 10, time.Time{})
+	stats.remove("/projects/app/s3.jsonl")
+
+	status := stats.Status()
+	if status.LagBytes != 300 || status.Throttled != 1 || len(status.Files) != 2 {
+		t.Fatalf("status = %+v, want 300 bytes behind over 2 files", status)
+	}
+	if f := status.Files[0]; f.Path != "/projects/app/s1.jsonl" || f.LagBytes != 300 || f.Lines != 1 {
+		t.Errorf("most behind file = %+v", f)
+	}
+	var nilStats *WatchStats
+	nilStats.read("/projects/app/s1.jsonl", 1, time.Time{}) // Ignored without stats
+}
@@ -0,0 +1,35 @@
+package event
+
+import "container/list"
+
+// maxRecentUUIDs is the number of event UUIDs a session watcher remembers to skip
+// lines it already handled when a transcript is rewritten
+const maxRecentUUIDs = 1000
+
+// recentUUIDs remembers the most recently seen UUIDs, forgetting the least recently
+// seen beyond its size. It is not safe for concurrent use.
+type recentUUIDs struct {
+	size  int
+	order *list.List // Front is the most recently seen
+	items map[string]*list.Element
+}
+
+// newRecentUUIDs creates a set remembering up to size UUIDs
+func newRecentUUIDs(size int) *recentUUIDs {
+	return &recentUUIDs{size: size, order: list.New(), items: make(map[string]*list.Element)}
+}
+
+// seen reports whether a UUID was seen before, and remembers it
+func (r *recentUUIDs) seen(uuid string) bool {
+	if elem, ok := r.items[uuid]; ok {
+		r.order.MoveToFront(elem)
+		return true
+	}
+	r.items[uuid] = r.order.PushFront(uuid)
+	if r.order.Len() > r.size {
+		oldest := r.order.Back()
+		r.order.Remove(oldest)
+		delete(r.items, oldest.Value.(string))
+	}
+	return false
+}
@@ -0,0 +1,316 @@
+package event
+
+import (
+	"fmt"
+	"math"
+	"os"
+	"regexp"
+	"strings"
+	"unicode"
+
+	"gopkg.in/yaml.v3"
+)
+
+// RedactedPlaceholder replaces the secrets found in events
+const RedactedPlaceholder = "[REDACTED]"
+
+// secretGroup is the name of the group of a redaction pattern that holds the secret
+const secretGroup = "secret"
+
+// Defaults of the entropy heuristic
+const (
+	defaultEntropyMinLength = 24
+	defaultEntropyThreshold = 4.0 // Bits per character
+)
+
+// RedactionRule is a pattern of secrets. When the pattern has a group named secret,
+// only that group is redacted, so that "password=hunter2" keeps "password=".
+type RedactionRule struct {
+	Name    string `yaml:"name"`
+	Pattern string `yaml:"pattern"`
+}
+
+// DefaultRedactionRules are the redaction rules built into the redactor
+var DefaultRedactionRules = []RedactionRule{
+	{Name: "private-key", Pattern: `-----BEGIN [A-Z ]*PRIVATE KEY-----[\s\S]*?-----END [A-Z ]*PRIVATE KEY-----`},
+	{Name: "aws-access-key", Pattern: `\b(AKIA|ASIA)[0-9A-Z]{16}\b`},
+	{Name: "github-token", Pattern: `\b(gh[pousr]_[A-Za-z0-9]{36,}|github_pat_[A-Za-z0-9_]{22,})\b`},
+	{Name: "api-key", Pattern: `\bsk-(ant-|proj-)?[A-Za-z0-9_-]{20,}`},
+	{Name: "slack-token", Pattern: `\bxox[abprs]-[A-Za-z0-9-]{10,}`},
+	{Name: "jwt", Pattern: `\beyJ[A-Za-z0-9_-]{10,}\.[A-Za-z0-9_-]{10,}\.[A-Za-z0-9_-]{10,}`},
+	{Name: "bearer", Pattern: `(?i)\bbearer\s+(?P<secret>[A-Za-z0-9._~+/=-]{16,})`},
+	{Name: "url-password", Pattern: `://[^\s:/@]+:(?P<secret>[^\s:/@]+)@`},
+	{Name: "assignment", Pattern: `(?i)\b(password|passwd|secret|token|api[_-]?key|access[_-]?key|client[_-]?secret)\b["']?\s*[:=]\s*["']?(?P<secret>[^\s"',;&]{6,})`},
+}
+
+// redactionRule is a rule with its pattern compiled
+type redactionRule struct {
+	re     *regexp.Regexp
+	secret int // Index of the secret group, 0 for the whole match
+}
+
+// Redactor replaces secrets such as API keys, tokens and passwords in events with
+// RedactedPlaceholder before they are stored, shown, narrated or broadcast. Secrets
+// are found with regular expressions, and, when enabled, long random-looking words
+// with an entropy heuristic.
+type Redactor struct {
+	rules     []*redactionRule
+	entropy   bool // Whether the entropy heuristic is enabled
+	minLength int
+	threshold float64
+	candidate *regexp.Regexp // Words the entropy heuristic considers
+}
+
+// NewRedactor creates a redactor with the built-in rules
+func NewRedactor() *Redactor {
+	r, err := ParseRedactionRules(nil)
+	if err != nil {
+		panic(err) // The built-in rules are valid
+	}
+	return r
+}
+
+// LoadRedactionRules loads a redaction rules file
+func LoadRedactionRules(path string) (*Redactor, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read redaction rules: %w", err)
+	}
+	return ParseRedactionRules(data)
+}
+
+// ParseRedactionRules parses a redaction rules file, a YAML document with rules added to
+// the built-in ones, the names of rules to turn off under disable and the settings of
+// the entropy heuristic, which is off unless enabled there. The heuristic also catches
+// identifiers such as tool use IDs and long test names, so it is opt-in.
+func ParseRedactionRules(data []byte) (*Redactor, error) {
+	var file struct {
+		Rules   []RedactionRule `yaml:"rules"`
+		Disable []string        `yaml:"disable"`
+		Entropy struct {
+			Enabled   bool    `yaml:"enabled"`
+			MinLength int     `yaml:"minLength"`
+			Threshold float64 `yaml:"threshold"`
+		} `yaml:"entropy"`
+	}
+	if err := yaml.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("failed to parse redaction rules: %w", err)
+	}
+
+	disabled := make(map[string]bool)
+	for _, name := range file.Disable {
+		disabled[name] = true
+	}
+	r := &Redactor{
+		entropy:   file.Entropy.Enabled,
+		minLength: defaultEntropyMinLength,
+		threshold: defaultEntropyThreshold,
+	}
+	if file.Entropy.MinLength < 0 || file.Entropy.Threshold < 0 {
+		return nil, fmt.Errorf("invalid entropy settings: minLength and threshold must be positive")
+	}
+	if file.Entropy.MinLength > 0 {
+		r.minLength = file.Entropy.MinLength
+	}
+	if file.Entropy.Threshold > 0 {
+		r.threshold = file.Entropy.Threshold
+	}
+	r.candidate = regexp.MustCompile(fmt.Sprintf(`[A-Za-z0-9_+=-]{%d,}`, r.minLength))
+
+	known := make(map[string]bool)
+	for i, rule := range append(DefaultRedactionRules, file.Rules...) {
+		if rule.Name == "" {
+			rule.Name = fmt.Sprintf("rule %d", i+1)
+		}
+		known[rule.Name] = true
+		if disabled[rule.Name] {
+			continue
+		}
+		if rule.Pattern == "" {
+			return nil, fmt.Errorf("invalid redaction rule %q: no pattern", rule.Name)
+		}
+		re, err := regexp.Compile(rule.Pattern)
+		if err != nil {
+			return nil, fmt.Errorf("invalid redaction rule %q: %w", rule.Name, err)
+		}
+		r.rules = append(r.rules, &redactionRule{re: re, secret: max(re.SubexpIndex(secretGroup), 0)})
+	}
+	for _, name := range file.Disable {
+		if !known[name] {
+			return nil, fmt.Errorf("cannot disable unknown redaction rule %q", name)
+		}
+	}
+	return r, nil
+}
+
+// Len returns the number of rules in use, the entropy heuristic included; 0 for a nil redactor
+func (r *Redactor) Len() int {
+	if r == nil {
+		return 0
+	}
+	if r.entropy {
+		return len(r.rules) + 1
+	}
+	return len(r.rules)
+}
+
+// EntropyEnabled reports whether the entropy heuristic is enabled; false for a nil redactor
+func (r *Redactor) EntropyEnabled() bool {
+	return r != nil && r.entropy
+}
+
+// Redact returns s with its secrets replaced by RedactedPlaceholder
+func (r *Redactor) Redact(s string) string {
+	if s == "" {
+		return s
+	}
+	for _, rule := range r.rules {
+		s = rule.redact(s)
+	}
+	if r.entropy {
+		s = r.candidate.ReplaceAllStringFunc(s, func(word string) string {
+			if looksRandom(word, r.threshold) {
+				return RedactedPlaceholder
+			}
+			return word
+		})
+	}
+	return s
+}
+
+// redact replaces the matches of the rule, or their secret group, in s
+func (rule *redactionRule) redact(s string) string {
+	if rule.secret == 0 {
+		return rule.re.ReplaceAllString(s, RedactedPlaceholder)
+	}
+	var b strings.Builder
+	last, replaced := 0, false
+	for _, m := range rule.re.FindAllStringSubmatchIndex(s, -1) {
+		start, end := m[2*rule.secret], m[2*rule.secret+1]
+		if start < 0 {
+			continue
+		}
+		b.WriteString(s[last:start])
+		b.WriteString(RedactedPlaceholder)
+		last, replaced = end, true
+	}
+	if !replaced {
+		return s
+	}
+	b.WriteString(s[last:])
+	return b.String()
+}
+
+// looksRandom reports whether a word mixes upper case letters, lower case letters and
+// digits with at least threshold bits of entropy per character, as keys and tokens do.
+// Identifiers, hex hashes and UUIDs lack a character class or the entropy.
+func looksRandom(word string, threshold float64) bool {
+	var upper, lower, digit bool
+	counts := make(map[rune]int)
+	for _, c := range word {
+		switch {
+		case unicode.IsUpper(c):
+			upper = true
+		case unicode.IsLower(c):
+			lower = true
+		case unicode.IsDigit(c):
+			digit = true
+		}
+		counts[c]++
+	}
+	if !upper || !lower || !digit {
+		return false
+	}
+	var entropy float64
+	n := float64(len(word))
+	for _, count := range counts {
+		p := float64(count) / n
+		entropy -= p * math.Log2(p)
+	}
+	return entropy >= threshold
+}
+
+// RedactEvent replaces the secrets in the text and the tool inputs and results of an
+// event, in place. IDs and types are kept, so that tool results still pair with
+// their tool uses.
+func (r *Redactor) RedactEvent(event Event) {
+	switch e := event.(type) {
+	case *UserMessage:
+		e.Message.Content = r.redactContent(e.Message.Content)
+	case *AssistantMessage:
+		for i := range e.Message.Content {
+			content := &e.Message.Content[i]
+			content.Text = r.Redact(content.Text)
+			content.Thinking = r.Redact(content.Thinking)
+			content.Input = r.redactValue(content.Input)
+		}
+	case *SystemMessage:
+		e.Content = r.Redact(e.Content)
+	case *HookEvent:
+		e.Content = r.Redact(e.Content)
+		e.HookCommand = r.Redact(e.HookCommand)
+		e.HookStatus = r.Redact(e.HookStatus)
+	case *SummaryEvent:
+		e.Summary = r.Redact(e.Summary)
+	case *NotificationEvent:
+		e.Message = r.Redact(e.Message)
+		e.CustomInstructions = r.Redact(e.CustomInstructions)
+		if e.ToolInput != nil {
+			e.ToolInput = r.redactValue(e.ToolInput).(map[string]interface{})
+		}
+		e.ToolResponse = r.redactValue(e.ToolResponse)
+	}
+}
+
+// contentFields are the fields of user message content blocks that hold text: the
+// text of text blocks and the content of tool results
+var contentFields = map[string]bool{"text": true, "content": true}
+
+// keptFields are the fields of tool inputs and results that are never redacted
+var keptFields = map[string]bool{"id": true, "tool_use_id": true, "type": true}
+
+// redactContent replaces the secrets in the content of a user message, a string or a
+// list of content blocks. Only the text and content fields of blocks are redacted.
+func (r *Redactor) redactContent(content interface{}) interface{} {
+	switch v := content.(type) {
+	case string:
+		return r.Redact(v)
+	case map[string]interface{}:
+		for key, item := range v {
+			if contentFields[key] {
+				v[key] = r.redactContent(item)
+			}
+		}
+		return v
+	case []interface{}:
+		for i, item := range v {
+			v[i] = r.redactContent(item)
+		}
+		return v
+	}
+	return content
+}
+
+// redactValue replaces the secrets in the strings of a decoded JSON value, such as a
+// tool input, except for IDs and types. The source of images and documents is kept,
+// as its data is not text.
+func (r *Redactor) redactValue(value interface{}) interface{} {
+	switch v := value.(type) {
+	case string:
+		return r.Redact(v)
+	case map[string]interface{}:
+		for key, item := range v {
+			if _, ok := item.(map[string]interface{}); (ok && key == "source") || keptFields[key] {
+				continue
+			}
+			v[key] = r.redactValue(item)
+		}
+		return v
+	case []interface{}:
+		for i, item := range v {
+			v[i] = r.redactValue(item)
+		}
+		return v
+	}
+	return value
+}
@@ -0,0 +1,185 @@
+package event
+
+import (
+	"encoding/json"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/kazegusuri/claude-companion/logger"
+)
+
+// Schema is a version of the Claude Code transcript format. Lines are upgraded to
+// the current format by the schema of the Claude Code version that wrote them before
+// they are decoded.
+type Schema struct {
+	Name       string
+	MinVersion string // Earliest Claude Code version writing it; empty for lines without a version
+	// upgrade rewrites the fields of a line to the current format and reports whether
+	// it changed them; nil if they already are
+	upgrade func(fields map[string]json.RawMessage) bool
+}
+
+// hookContentPattern matches the content of a system message reporting a hook run,
+// such as "Stop [/usr/local/bin/notify.sh] completed successfully"
+var hookContentPattern = regexp.MustCompile(`^(\x1b\[[0-9;]*m)*\w+(?::\w+)?(\x1b\[[0-9;]*m)*\s+\[[^\]]+\]\s+`)
+
+// schemas are the known transcript formats, oldest first
+var schemas = []*Schema{
+	{
+		// Lines without version, gitBranch and the hook fields of system messages
+		Name:    "legacy",
+		upgrade: upgradeLegacy,
+	},
+	{
+		Name:       "1.0",
+		MinVersion: "1.0.0",
+	},
+}
+
+// SchemaFor returns the schema of lines written by a Claude Code version: the newest
+// one the version is at least the minimum of. Versions newer than every schema use
+// the latest, keeping the fields it does not know in BaseEvent.Extra.
+func SchemaFor(version string) *Schema {
+	if version == "" {
+		return schemas[0]
+	}
+	for i := len(schemas) - 1; i > 0; i-- {
+		if compareVersions(version, schemas[i].MinVersion) >= 0 {
+			return schemas[i]
+		}
+	}
+	return schemas[0]
+}
+
+// upgradeLegacy marks the hook runs among system messages the way later versions do,
+// with the level and tool use ID the parser recognizes them by
+func upgradeLegacy(fields map[string]json.RawMessage) bool {
+	if rawString(fields["type"]) != EventTypeSystem || !hookContentPattern.MatchString(rawString(fields["content"])) {
+		return false
+	}
+	changed := false
+	if _, ok := fields["level"]; !ok {
+		fields["level"] = json.RawMessage(`"info"`)
+		changed = true
+	}
+	if _, ok := fields["toolUseID"]; !ok && fields["uuid"] != nil {
+		fields["toolUseID"] = fields["uuid"]
+		changed = true
+	}
+	return changed
+}
+
+// rawString returns a raw JSON string value, or an empty string if it is not one
+func rawString(raw json.RawMessage) string {
+	var s string
+	if json.Unmarshal(raw, &s) != nil {
+		return ""
+	}
+	return s
+}
+
+// compareVersions compares dotted version numbers such as 1.0.64, ignoring any
+// pre-release suffix; missing parts count as zero
+func compareVersions(a, b string) int {
+	pa, pb := versionParts(a), versionParts(b)
+	for i := 0; i < max(len(pa), len(pb)); i++ {
+		var x, y int
+		if i < len(pa) {
+			x = pa[i]
+		}
+		if i < len(pb) {
+			y = pb[i]
+		}
+		if x != y {
+			if x < y {
+				return -1
+			}
+			return 1
+		}
+	}
+	return 0
+}
+
+// versionParts returns the numbers of a dotted version
+func versionParts(v string) []int {
+	v, _, _ = strings.Cut(strings.TrimPrefix(v, "v"), "-")
+	var parts []int
+	for _, s := range strings.Split(v, ".") {
+		n, _ := strconv.Atoi(s)
+		parts = append(parts, n)
+	}
+	return parts
+}
+
+// knownFields caches the JSON fields each event type decodes, lowercased as
+// encoding/json matches them case-insensitively, by type
+var knownFields sync.Map // reflect.Type -> map[string]bool
+
+// fieldsOf returns the JSON field names a struct type decodes, including those of embedded structs
+func fieldsOf(t reflect.Type) map[string]bool {
+	if cached, ok := knownFields.Load(t); ok {
+		return cached.(map[string]bool)
+	}
+	fields := make(map[string]bool)
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		tag := f.Tag.Get("json")
+		if f.Anonymous && tag == "" && f.Type.Kind() == reflect.Struct {
+			for name := range fieldsOf(f.Type) {
+				fields[name] = true
+			}
+			continue
+		}
+		name, _, _ := strings.Cut(tag, ",")
+		if name == "-" || !f.IsExported() {
+			continue
+		}
+		if name == "" {
+			name = f.Name
+		}
+		fields[strings.ToLower(name)] = true
+	}
+	knownFields.Store(t, fields)
+	return fields
+}
+
+// unknownFields returns the fields of a line an event does not decode, or nil if there are none
+func unknownFields(fields map[string]json.RawMessage, event any) map[string]json.RawMessage {
+	known := fieldsOf(reflect.TypeOf(event).Elem())
+	var extra map[string]json.RawMessage
+	for name, value := range fields {
+		if known[strings.ToLower(name)] {
+			continue
+		}
+		if extra == nil {
+			extra = make(map[string]json.RawMessage)
+		}
+		extra[name] = value
+	}
+	return extra
+}
+
+// reportedFields are the unknown fields already logged, by event type and field
+var reportedFields sync.Map
+
+// reportUnknownFields logs the fields of an event type this version does not know, once each
+func reportUnknownFields(eventType, version string, extra map[string]json.RawMessage) {
+	var names []string
+	for name := range extra {
+		if _, seen := reportedFields.LoadOrStore(eventType+"."+name, true); !seen {
+			names = append(names, name)
+		}
+	}
+	if len(names) == 0 {
+		return
+	}
+	sort.Strings(names)
+	if version == "" {
+		version = "(no version)"
+	}
+	logger.LogDebug("Claude Code %s writes %s fields unknown to this version, kept as extra: %s", version, eventType, strings.Join(names, ", "))
+}
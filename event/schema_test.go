@@ -0,0 +1,144 @@
+package event
+
+import (
+	"bufio"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+)
+
+var updateSchema = flag.Bool("update", false, "Rewrite the golden files of the transcripts in testdata/schema")
+
+func TestSchemaFor(t *testing.T) {
+	tests := []struct {
+		version string
+		want    string
+	}{
+		{"", "legacy"},
+		{"0.2.9", "legacy"},
+		{"1.0.0", "1.0"},
+		{"1.0.67", "1.0"},
+		{"2.0.0-beta.1", "1.0"},
+	}
+	for _, tt := range tests {
+		if got := SchemaFor(tt.version).Name; got != tt.want {
+			t.Errorf("SchemaFor(%q) = %s, want %s", tt.version, got, tt.want)
+		}
+	}
+}
+
+func TestCompareVersions(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want int
+	}{
+		{"1.0.64", "1.0.64", 0},
+		{"1.0.9", "1.0.64", -1},
+		{"1.1", "1.0.99", 1},
+		{"v2.0.0-rc.1", "2.0", 0},
+	}
+	for _, tt := range tests {
+		if got := compareVersions(tt.a, tt.b); got != tt.want {
+			t.Errorf("compareVersions(%q, %q) = %d, want %d", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
+
+func TestParser_UnknownFields(t *testing.T) {
+	line := `{"type":"user","version":"9.0.0","uuid":"1","message":{"role":"user","content":"hi"},"newField":{"a":1},"TOOLUSERESULT":null}`
+	ev, err := NewParser().Parse(line)
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	base := BaseOf(ev)
+	if base.Schema != "1.0" || string(base.Extra["newField"]) != `{"a":1}` || len(base.Extra) != 2 {
+		t.Errorf("Parse() schema = %s, extra = %s, want the latest schema keeping newField and TOOLUSERESULT", base.Schema, base.Extra)
+	}
+}
+
+// TestParser_SchemaCorpus parses transcripts of several Claude Code versions in
+// testdata/schema and compares what was parsed with their golden files
+func TestParser_SchemaCorpus(t *testing.T) {
+	files, err := filepath.Glob(filepath.Join("testdata", "schema", "*.jsonl"))
+	if err != nil || len(files) == 0 {
+		t.Fatalf("no transcripts in testdata/schema: %v", err)
+	}
+	for _, file := range files {
+		t.Run(filepath.Base(file), func(t *testing.T) {
+			got := summarizeTranscript(t, file)
+			golden := strings.TrimSuffix(file, ".jsonl") + ".golden"
+			if *updateSchema {
+				if err := os.WriteFile(golden, []byte(got), 0o644); err != nil {
+					t.Fatal(err)
+				}
+				return
+			}
+			want, err := os.ReadFile(golden)
+			if err != nil {
+				t.Fatalf("%v (run with -update to create it)", err)
+			}
+			if got != string(want) {
+				t.Errorf("parsed %s differently than %s:\ngot:\n%s\nwant:\n%s", file, golden, got, want)
+			}
+		})
+	}
+}
+
+// summarizeTranscript parses each line of a transcript and describes the event it became
+func summarizeTranscript(t *testing.T, path string) string {
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	parser := NewParser()
+	var out strings.Builder
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(nil, 1<<20)
+	for line := 1; scanner.Scan(); line++ {
+		ev, err := parser.Parse(scanner.Text())
+		if err != nil {
+			t.Errorf("%s:%d: Parse() error = %v", path, line, err)
+			continue
+		}
+		fmt.Fprintf(&out, "%d: %T", line, ev)
+		if base := BaseOf(ev); base != nil {
+			fmt.Fprintf(&out, " schema=%s", base.Schema)
+			if base.GitBranch != "" {
+				fmt.Fprintf(&out, " branch=%s", base.GitBranch)
+			}
+			if len(base.Extra) > 0 {
+				names := make([]string, 0, len(base.Extra))
+				for name := range base.Extra {
+					names = append(names, name)
+				}
+				sort.Strings(names)
+				fmt.Fprintf(&out, " extra=%s", strings.Join(names, ","))
+			}
+		}
+		switch e := ev.(type) {
+		case *AssistantMessage:
+			var content []string
+			for _, c := range e.Message.Content {
+				content = append(content, c.Type+":"+c.Name)
+			}
+			fmt.Fprintf(&out, " content=%s apiError=%v", strings.Join(content, ","), e.IsApiErrorMessage)
+		case *HookEvent:
+			fmt.Fprintf(&out, " hook=%s status=%q", e.HookEventType, e.HookStatus)
+		case *SystemMessage:
+			fmt.Fprintf(&out, " compact=%v", e.IsCompactSummary)
+		case *SummaryEvent:
+			fmt.Fprintf(&out, " summary=%q", e.Summary)
+		}
+		out.WriteString("\n")
+	}
+	if err := scanner.Err(); err != nil {
+		t.Fatal(err)
+	}
+	return out.String()
+}
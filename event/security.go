@@ -0,0 +1,310 @@
+package event
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// SecurityRule is a condition on tool uses: deny rules flag the tool uses matching it
+// as risky, and allow rules exempt them
+type SecurityRule struct {
+	Name   string            `yaml:"name"`
+	Tools  []string          `yaml:"tools"`  // Tool names or glob patterns; empty matches any tool
+	Input  map[string]string `yaml:"input"`  // Regular expression per tool input field, such as command or file_path
+	Reason string            `yaml:"reason"` // Why a matching tool use is risky, shown in alerts
+}
+
+// SecurityProject holds the rules of the projects under a directory
+type SecurityProject struct {
+	Path  string         `yaml:"path"` // Working directory of the project; ~/ is the home directory
+	Allow []SecurityRule `yaml:"allow"`
+	Deny  []SecurityRule `yaml:"deny"`
+}
+
+// fileEditTools are the tools that write to a file_path
+var fileEditTools = []string{"Write", "Edit", "MultiEdit"}
+
+// DefaultSecurityRules are the deny rules built into the security watchdog
+var DefaultSecurityRules = []SecurityRule{
+	{
+		Name:   "rm -rf",
+		Tools:  []string{"Bash"},
+		Input:  map[string]string{"command": `(^|[;&|(]|\s)rm\s+(-[a-zA-Z]*([rR][a-zA-Z]*f|f[a-zA-Z]*[rR])|-[rR]\s+-f|-f\s+-[rR]|--recursive\s+--force|--force\s+--recursive)`},
+		Reason: "deletes files recursively without confirmation",
+	},
+	{
+		Name:   "pipe to shell",
+		Tools:  []string{"Bash"},
+		Input:  map[string]string{"command": `\b(curl|wget)\b[^|;&]*\|\s*(sudo\s+)?(ba|z|da)?sh\b`},
+		Reason: "runs a script downloaded from the network",
+	},
+	{
+		Name:   "force push",
+		Tools:  []string{"Bash"},
+		Input:  map[string]string{"command": `\bgit\s+push\b[^;&|]*\s(--force|-f\b|\+\S)`},
+		Reason: "overwrites the history of a remote branch",
+	},
+	{
+		Name:   "SSH key edit",
+		Tools:  fileEditTools,
+		Input:  map[string]string{"file_path": `(^|/)\.ssh/`},
+		Reason: "changes SSH keys or configuration",
+	},
+	{
+		Name:   "env file edit",
+		Tools:  fileEditTools,
+		Input:  map[string]string{"file_path": `(^|/)\.env(\.[^/]*)?$`},
+		Reason: "changes a file of secrets",
+	},
+}
+
+// defaultSecurityAllow are the allow rules built into the security watchdog
+var defaultSecurityAllow = []SecurityRule{
+	{
+		Name:  "env file template",
+		Tools: fileEditTools,
+		Input: map[string]string{"file_path": `(^|/)\.env\.(example|sample|template|dist)$`},
+	},
+}
+
+// securityRule is a rule with its patterns compiled
+type securityRule struct {
+	SecurityRule
+	fields []string // Input fields in sorted order
+	input  map[string]*regexp.Regexp
+}
+
+// securityRules are the compiled allow and deny rules of a scope
+type securityRules struct {
+	allow []*securityRule
+	deny  []*securityRule
+}
+
+// securityProject is a project with its rules compiled
+type securityProject struct {
+	dir   string
+	rules securityRules
+}
+
+// SecurityWatchdog flags risky tool uses, such as rm -rf, scripts piped from curl to a
+// shell, force pushes and edits to SSH keys or .env files. A tool use is flagged when
+// it matches a deny rule, built in or of its project, and no allow rule.
+type SecurityWatchdog struct {
+	rules    securityRules
+	projects []securityProject
+}
+
+// NewSecurityWatchdog creates a watchdog with the built-in rules
+func NewSecurityWatchdog() *SecurityWatchdog {
+	w, err := ParseSecurityRules(nil)
+	if err != nil {
+		panic(err) // The built-in rules are valid
+	}
+	return w
+}
+
+// LoadSecurityRules loads a security rules file, adding its rules to the built-in ones
+func LoadSecurityRules(path string) (*SecurityWatchdog, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read security rules: %w", err)
+	}
+	return ParseSecurityRules(data)
+}
+
+// ParseSecurityRules parses a security rules file, a YAML document with allow and deny
+// rules for every project and under projects for the projects under a directory.
+// Its rules are added to the built-in ones.
+func ParseSecurityRules(data []byte) (*SecurityWatchdog, error) {
+	var file struct {
+		Allow    []SecurityRule    `yaml:"allow"`
+		Deny     []SecurityRule    `yaml:"deny"`
+		Projects []SecurityProject `yaml:"projects"`
+	}
+	if err := yaml.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("failed to parse security rules: %w", err)
+	}
+
+	w := &SecurityWatchdog{}
+	var err error
+	if w.rules, err = compileSecurityRules(append(defaultSecurityAllow, file.Allow...), append(DefaultSecurityRules, file.Deny...)); err != nil {
+		return nil, err
+	}
+	for _, project := range file.Projects {
+		if project.Path == "" {
+			return nil, fmt.Errorf("security rules of a project without a path")
+		}
+		dir, err := expandHomeDir(project.Path)
+		if err != nil {
+			return nil, fmt.Errorf("invalid project path %s: %w", project.Path, err)
+		}
+		rules, err := compileSecurityRules(project.Allow, project.Deny)
+		if err != nil {
+			return nil, fmt.Errorf("invalid security rules of %s: %w", project.Path, err)
+		}
+		w.projects = append(w.projects, securityProject{dir: filepath.Clean(dir), rules: rules})
+	}
+	return w, nil
+}
+
+// compileSecurityRules compiles allow and deny rules
+func compileSecurityRules(allow, deny []SecurityRule) (securityRules, error) {
+	var rules securityRules
+	for _, list := range []struct {
+		kind     string
+		rules    []SecurityRule
+		compiled *[]*securityRule
+	}{{"allow", allow, &rules.allow}, {"deny", deny, &rules.deny}} {
+		for i, rule := range list.rules {
+			if rule.Name == "" {
+				rule.Name = fmt.Sprintf("%s rule %d", list.kind, i+1)
+			}
+			compiled, err := compileSecurityRule(rule)
+			if err != nil {
+				return securityRules{}, fmt.Errorf("invalid %s rule %q: %w", list.kind, rule.Name, err)
+			}
+			*list.compiled = append(*list.compiled, compiled)
+		}
+	}
+	return rules, nil
+}
+
+// compileSecurityRule validates a rule and compiles its patterns
+func compileSecurityRule(rule SecurityRule) (*securityRule, error) {
+	if len(rule.Tools) == 0 && len(rule.Input) == 0 {
+		return nil, fmt.Errorf("no tools or input to match")
+	}
+	for _, pattern := range rule.Tools {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return nil, fmt.Errorf("invalid tool pattern %q: %w", pattern, err)
+		}
+	}
+	r := &securityRule{SecurityRule: rule, input: make(map[string]*regexp.Regexp)}
+	for field, pattern := range rule.Input {
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return nil, fmt.Errorf("invalid input pattern for %s: %w", field, err)
+		}
+		r.input[field] = re
+		r.fields = append(r.fields, field)
+	}
+	sort.Strings(r.fields)
+	return r, nil
+}
+
+// expandHomeDir expands a leading ~/ to the user's home directory
+func expandHomeDir(dir string) (string, error) {
+	if !strings.HasPrefix(dir, "~/") {
+		return dir, nil
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(home, dir[2:]), nil
+}
+
+// Len returns the number of rules, built-in ones included; 0 for a nil watchdog
+func (w *SecurityWatchdog) Len() int {
+	if w == nil {
+		return 0
+	}
+	n := len(w.rules.allow) + len(w.rules.deny)
+	for _, project := range w.projects {
+		n += len(project.rules.allow) + len(project.rules.deny)
+	}
+	return n
+}
+
+// Check returns an alert for each risky tool use of an assistant message, applying
+// the rules of the projects that cwd is in
+func (w *SecurityWatchdog) Check(msg *AssistantMessage, cwd string) []*SecurityAlertMessage {
+	scopes := []securityRules{w.rules}
+	if cwd != "" {
+		cwd = filepath.Clean(cwd)
+		for _, project := range w.projects {
+			if cwd == project.dir || strings.HasPrefix(cwd, project.dir+string(filepath.Separator)) {
+				scopes = append(scopes, project.rules)
+			}
+		}
+	}
+
+	var alerts []*SecurityAlertMessage
+	for _, content := range msg.Message.Content {
+		if content.Type != "tool_use" {
+			continue
+		}
+		input, _ := content.Input.(map[string]interface{})
+		rule, target := matchSecurityRules(scopes, content.Name, input)
+		if rule == nil {
+			continue
+		}
+		alert := &SecurityAlertMessage{
+			BaseEvent: msg.BaseEvent,
+			Rule:      rule.Name,
+			Reason:    rule.Reason,
+			ToolName:  content.Name,
+			ToolUseID: content.ID,
+			Target:    target,
+		}
+		alert.TypeString = "security_alert"
+		alert.UUID = ""
+		// Alerts for the tool uses of subagents are shown like any other alert
+		alert.IsSidechain = false
+		alerts = append(alerts, alert)
+	}
+	return alerts
+}
+
+// matchSecurityRules returns the first deny rule that a tool use matches and the
+// input value it matched, or nil if none does or an allow rule matches
+func matchSecurityRules(scopes []securityRules, tool string, input map[string]interface{}) (*securityRule, string) {
+	for _, scope := range scopes {
+		for _, rule := range scope.allow {
+			if _, ok := rule.match(tool, input); ok {
+				return nil, ""
+			}
+		}
+	}
+	for _, scope := range scopes {
+		for _, rule := range scope.deny {
+			if target, ok := rule.match(tool, input); ok {
+				return rule, target
+			}
+		}
+	}
+	return nil, ""
+}
+
+// match reports whether a tool use meets all the conditions of the rule, and returns
+// the first input value it matched
+func (r *securityRule) match(tool string, input map[string]interface{}) (string, bool) {
+	if len(r.Tools) > 0 && !matchAny(r.Tools, tool) {
+		return "", false
+	}
+	var target string
+	for _, field := range r.fields {
+		value, ok := input[field]
+		if !ok {
+			return "", false
+		}
+		s, ok := value.(string)
+		if !ok {
+			s = fmt.Sprint(value)
+		}
+		if !r.input[field].MatchString(s) {
+			return "", false
+		}
+		if target == "" {
+			target = s
+		}
+	}
+	return target, true
+}
@@ -12,11 +12,15 @@ type SessionFileManager struct {
 	watchers map[string]*ManagedWatcher
 	mu       sync.RWMutex
 	handler  *Handler
+	root     string // Projects root label of the managed sessions
+	offsets  *OffsetStore
+	readRate int // Lines per second read from each file; zero is unlimited
+	stats    *WatchStats
+	tap      *Tap
 
 	// Configuration
 	idleTimeout   time.Duration
 	checkInterval time.Duration
-	debugMode     bool
 
 	done chan struct{}
 	wg   sync.WaitGroup
@@ -36,11 +40,35 @@ func NewSessionFileManager(handler *Handler) *SessionFileManager {
 		handler:       handler,
 		idleTimeout:   1 * time.Hour,   // Remove watchers after 1 hour of inactivity
 		checkInterval: 1 * time.Minute, // Check for idle watchers every minute
-		debugMode:     handler.debugMode,
 		done:          make(chan struct{}),
 	}
 }
 
+// SetRoot sets the projects root label of the managed sessions
+func (m *SessionFileManager) SetRoot(root string) {
+	m.root = root
+}
+
+// SetOffsetStore makes the managed watchers resume from the offsets saved in offsets
+func (m *SessionFileManager) SetOffsetStore(offsets *OffsetStore) {
+	m.offsets = offsets
+}
+
+// SetReadRate limits how many lines per second the managed watchers read from each file
+func (m *SessionFileManager) SetReadRate(linesPerSecond int) {
+	m.readRate = linesPerSecond
+}
+
+// SetWatchStats sets where the managed watchers report their status
+func (m *SessionFileManager) SetWatchStats(stats *WatchStats) {
+	m.stats = stats
+}
+
+// SetTap sets where the managed watchers forward the lines they read
+func (m *SessionFileManager) SetTap(tap *Tap) {
+	m.tap = tap
+}
+
 // Start begins the manager's cleanup routine
 func (m *SessionFileManager) Start() {
 	m.wg.Add(1)
@@ -70,14 +98,18 @@ func (m *SessionFileManager) AddOrUpdateWatcher(filePath string) error {
 	// Check if watcher already exists
 	if mw, exists := m.watchers[filePath]; exists {
 		mw.lastActivity = time.Now()
-		if m.debugMode {
-			logger.LogInfo("Updated activity time for watcher: %s", filePath)
-		}
+		mw.watcher.Notify()
+		logger.LogDebug("Updated activity time for watcher: %s", filePath)
 		return nil
 	}
 
 	// Create new watcher
 	watcher := NewSessionWatcher(filePath, m.handler)
+	watcher.SetRoot(m.root)
+	watcher.SetOffsetStore(m.offsets)
+	watcher.SetReadRate(m.readRate)
+	watcher.SetWatchStats(m.stats)
+	watcher.SetTap(m.tap)
 	if err := watcher.Start(); err != nil {
 		return err
 	}
@@ -88,9 +120,7 @@ func (m *SessionFileManager) AddOrUpdateWatcher(filePath string) error {
 		filePath:     filePath,
 	}
 
-	if m.debugMode {
-		logger.LogInfo("Created new session watcher for: %s", filePath)
-	}
+	logger.LogDebug("Created new session watcher for: %s", filePath)
 	return nil
 }
 
@@ -129,14 +159,12 @@ func (m *SessionFileManager) cleanupIdleWatchers() {
 		if mw, exists := m.watchers[path]; exists {
 			mw.watcher.Stop()
 			delete(m.watchers, path)
-			if m.debugMode {
-				logger.LogInfo("Removed idle session watcher for: %s", path)
-			}
+			logger.LogDebug("Removed idle session watcher for: %s", path)
 		}
 	}
 
-	if m.debugMode && len(toRemove) > 0 {
-		logger.LogInfo("Cleaned up %d idle watchers", len(toRemove))
+	if len(toRemove) > 0 {
+		logger.LogDebug("Cleaned up %d idle watchers", len(toRemove))
 	}
 }
 
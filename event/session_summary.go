@@ -0,0 +1,183 @@
+package event
+
+import (
+	"sync"
+	"time"
+)
+
+// editTools are the tools whose file paths count as files touched by a session
+var editTools = map[string]bool{"Edit": true, "MultiEdit": true, "Write": true, "NotebookEdit": true}
+
+// sessionActivity is what a session did since it started
+type sessionActivity struct {
+	last     BaseEvent // Latest event, for the session and working directory of the summary
+	start    time.Time
+	end      time.Time
+	files    []string
+	seen     map[string]bool // Edited files and counted message IDs
+	commands int
+	tokens   int64
+	tasks    int
+	timer    *time.Timer
+}
+
+// SessionSummarizer accumulates the activity of each session and summarizes it when
+// the session ends: on the SessionEnd hook, or once it has been idle for a while.
+// Claude Code sends the Stop hook after every response, so it does not end a session
+// by itself; a session that stops and stays quiet ends by inactivity.
+type SessionSummarizer struct {
+	idle     time.Duration
+	emit     func(*SessionSummaryMessage) // Receives the summaries of idle sessions
+	now      func() time.Time
+	mu       sync.Mutex
+	sessions map[string]*sessionActivity // key: session ID
+}
+
+// NewSessionSummarizer creates a summarizer that hands the summaries of sessions idle
+// for longer than idle to emit
+func NewSessionSummarizer(idle time.Duration, emit func(*SessionSummaryMessage)) *SessionSummarizer {
+	return &SessionSummarizer{
+		idle:     idle,
+		emit:     emit,
+		now:      time.Now,
+		sessions: make(map[string]*sessionActivity),
+	}
+}
+
+// Observe adds an event to the activity of its session. It returns the summary of
+// the session if the event ends it.
+func (s *SessionSummarizer) Observe(event Event) *SessionSummaryMessage {
+	id := SessionIDOf(event)
+	if id == "" {
+		return nil
+	}
+	if _, ok := event.(*SessionSummaryMessage); ok {
+		return nil
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if n, ok := event.(*NotificationEvent); ok && n.HookEventName == "SessionEnd" {
+		reason := n.Reason
+		if reason == "" {
+			reason = "ended"
+		}
+		return s.end(id, reason)
+	}
+
+	a := s.sessions[id]
+	if a == nil {
+		a = &sessionActivity{seen: make(map[string]bool)}
+		s.sessions[id] = a
+		a.timer = time.AfterFunc(s.idle, func() { s.expire(id) })
+	} else {
+		a.timer.Reset(s.idle)
+	}
+	a.record(event)
+	return nil
+}
+
+// record adds the timestamps, edits, commands, tokens and completed Tasks of an event
+func (a *sessionActivity) record(event Event) {
+	if base := BaseOf(event); base != nil {
+		a.last = *base
+		if !base.Timestamp.IsZero() {
+			if a.start.IsZero() {
+				a.start = base.Timestamp
+			}
+			a.end = base.Timestamp
+		}
+	}
+
+	switch e := event.(type) {
+	case *AssistantMessage:
+		// Claude Code writes a line per content block, each with the usage of the whole message
+		if id := e.Message.ID; id == "" || !a.seen["message:"+id] {
+			a.seen["message:"+id] = true
+			u := e.Message.Usage
+			a.tokens += int64(u.InputTokens + u.OutputTokens + u.CacheReadInputTokens + u.CacheCreationInputTokens)
+		}
+		for _, content := range e.Message.Content {
+			if content.Type != "tool_use" {
+				continue
+			}
+			input, _ := content.Input.(map[string]interface{})
+			switch {
+			case content.Name == "Task":
+				a.seen["task:"+content.ID] = true
+			case content.Name == "Bash":
+				a.commands++
+			case editTools[content.Name]:
+				path, _ := input["file_path"].(string)
+				if path == "" {
+					path, _ = input["notebook_path"].(string)
+				}
+				if path != "" && !a.seen["file:"+path] {
+					a.seen["file:"+path] = true
+					a.files = append(a.files, path)
+				}
+			}
+		}
+	case *UserMessage:
+		items, _ := e.Message.Content.([]interface{})
+		for _, item := range items {
+			m, ok := item.(map[string]interface{})
+			if !ok || m["type"] != "tool_result" {
+				continue
+			}
+			if id, _ := m["tool_use_id"].(string); a.seen["task:"+id] {
+				delete(a.seen, "task:"+id)
+				a.tasks++
+			}
+		}
+	}
+}
+
+// expire summarizes a session that has been idle
+func (s *SessionSummarizer) expire(id string) {
+	s.mu.Lock()
+	summary := s.end(id, "idle")
+	s.mu.Unlock()
+	if summary != nil && s.emit != nil {
+		s.emit(summary)
+	}
+}
+
+// end returns the summary of a session and forgets its activity; nil if the session
+// did nothing since it was last summarized
+func (s *SessionSummarizer) end(id, reason string) *SessionSummaryMessage {
+	a := s.sessions[id]
+	if a == nil {
+		return nil
+	}
+	a.timer.Stop()
+	delete(s.sessions, id)
+	if a.last.TypeString == "" {
+		return nil // Only hooks were seen
+	}
+
+	summary := &SessionSummaryMessage{
+		BaseEvent:      a.last,
+		Reason:         reason,
+		FilesEdited:    a.files,
+		Commands:       a.commands,
+		Tokens:         a.tokens,
+		TasksCompleted: a.tasks,
+	}
+	summary.TypeString = "session_summary"
+	summary.UUID = ""
+	summary.Timestamp = s.now()
+	if !a.start.IsZero() {
+		summary.Duration = a.end.Sub(a.start)
+	}
+	return summary
+}
+
+// Stop cancels the pending summaries of idle sessions
+func (s *SessionSummarizer) Stop() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, a := range s.sessions {
+		a.timer.Stop()
+	}
+}
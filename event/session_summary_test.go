@@ -0,0 +1,98 @@
+package event
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSessionSummarizer(t *testing.T) {
+	start := time.Date(2025, 1, 1, 10, 0, 0, 0, time.UTC)
+	s := NewSessionSummarizer(time.Hour, nil)
+	defer s.Stop()
+
+	uses := []struct {
+		session   string
+		messageID string
+		tool      string
+		input     map[string]interface{}
+		after     time.Duration
+	}{
+		{session: "s1", messageID: "m1", tool: "Edit", input: map[string]interface{}{"file_path": "/p/a.go"}},
+		// A second line of the same message carries the same usage
+		{session: "s1", messageID: "m1", tool: "Bash", input: map[string]interface{}{"command": "go test"}, after: time.Second},
+		{session: "s1", messageID: "m2", tool: "Write", input: map[string]interface{}{"file_path": "/p/a.go"}, after: time.Minute},
+		{session: "s1", messageID: "m3", tool: "Task", input: map[string]interface{}{"description": "find"}, after: 2 * time.Minute},
+		{session: "s2", messageID: "m4", tool: "Bash", input: map[string]interface{}{"command": "ls"}},
+	}
+	var events []Event
+	for _, u := range uses {
+		msg := assistantMessage(u.session, start.Add(u.after), toolUseContent("toolu_"+u.messageID+u.tool, u.tool, u.input))
+		msg.Message.ID, msg.Message.Usage = u.messageID, Usage{InputTokens: 100, OutputTokens: 50}
+		events = append(events, msg)
+	}
+	result := toolResult("toolu_m3Task", start.Add(3*time.Minute))
+	result.SessionID = "s1"
+	events = append(events, result)
+	for _, e := range events {
+		if summary := s.Observe(e); summary != nil {
+			t.Fatalf("Observe(%T) ended the session", e)
+		}
+	}
+
+	summary := s.Observe(&NotificationEvent{SessionID: "s1", HookEventName: "SessionEnd", Reason: "logout"})
+	if summary == nil {
+		t.Fatal("SessionEnd did not end the session")
+	}
+	if summary.Reason != "logout" || summary.Duration != 3*time.Minute || summary.Commands != 1 ||
+		summary.TasksCompleted != 1 || summary.Tokens != 450 || len(summary.FilesEdited) != 1 || summary.SessionID != "s1" {
+		t.Errorf("summary = %+v", summary)
+	}
+	// The activity starts over
+	if summary := s.Observe(&NotificationEvent{SessionID: "s1", HookEventName: "SessionEnd"}); summary != nil {
+		t.Errorf("a second SessionEnd returned %+v", summary)
+	}
+}
+
+func TestSessionSummarizerIdle(t *testing.T) {
+	summaries := make(chan *SessionSummaryMessage, 1)
+	s := NewSessionSummarizer(20*time.Millisecond, func(m *SessionSummaryMessage) { summaries <- m })
+	defer s.Stop()
+
+	s.Observe(assistantMessage("s1", time.Now(), toolUseContent("toolu_1", "Bash", map[string]interface{}{"command": "ls"})))
+	select {
+	case summary := <-summaries:
+		if summary.Reason != "idle" || summary.Commands != 1 {
+			t.Errorf("summary = %+v", summary)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("idle session was not summarized")
+	}
+}
+
+func TestHandler_SessionSummary(t *testing.T) {
+	handler := NewHandler(&mockNarrator{}, false)
+	handler.SetSessionSummary(time.Hour)
+	defer handler.summaries.Stop()
+	sink := &recordingSink{}
+	handler.AddSink(sink)
+
+	start := time.Date(2025, 1, 1, 10, 0, 0, 0, time.UTC)
+	captureOutput(t, func() {
+		handler.processEvent(assistantMessage("s1", start, toolUseContent("toolu_1", "Edit", map[string]interface{}{"file_path": "/p/a.go"})))
+		handler.processEvent(&NotificationEvent{SessionID: "s1", HookEventName: "SessionEnd", Reason: "clear", Timestamp: start.Add(time.Minute)})
+	})
+
+	last := len(sink.events) - 1
+	if last < 0 {
+		t.Fatal("no events")
+	}
+	if _, ok := sink.events[last].(*SessionSummaryMessage); !ok {
+		t.Fatalf("last event = %T, want *SessionSummaryMessage", sink.events[last])
+	}
+	for _, want := range []string{"SESSION SUMMARY (clear)", "1 files edited", "📁 a.go", "セッションが終わりました: 1 files, 0 commands"} {
+		if !strings.Contains(sink.formatted[last], want) {
+			t.Errorf("summary = %q, want it to contain %q", sink.formatted[last], want)
+		}
+	}
+}
@@ -5,16 +5,30 @@ import (
 	"fmt"
 	"io"
 	"os"
+	"strings"
 	"time"
 
 	"github.com/kazegusuri/claude-companion/logger"
 )
 
+// Idle waits of a session watcher between checks for new lines. The wait doubles
+// while the file stays the same; a write notification ends it early.
+const (
+	minIdleWait = 100 * time.Millisecond
+	maxIdleWait = time.Second
+)
+
 // SessionWatcher watches session log files
 type SessionWatcher struct {
 	filePath     string
 	eventHandler *Handler
 	parser       *Parser
+	offsets      *OffsetStore // Where tailing resumes after a restart; nil always starts at the end
+	seen         *recentUUIDs // Events handled, skipped when the file is read again
+	limiter      *lineLimiter // nil reads without a rate limit
+	stats        *WatchStats
+	tap          *Tap // nil forwards no lines
+	wake         chan struct{}
 	done         chan struct{}
 }
 
@@ -24,10 +38,47 @@ func NewSessionWatcher(filePath string, eventHandler *Handler) *SessionWatcher {
 		filePath:     filePath,
 		eventHandler: eventHandler,
 		parser:       NewParserWithPath(filePath),
+		seen:         newRecentUUIDs(maxRecentUUIDs),
+		wake:         make(chan struct{}, 1),
 		done:         make(chan struct{}),
 	}
 }
 
+// SetRoot sets the projects root label of the watched session
+func (w *SessionWatcher) SetRoot(root string) {
+	w.parser.SetRoot(root)
+}
+
+// SetOffsetStore makes tailing resume from the offset saved for the file, reporting
+// the events written while the companion was not running in a CatchUpMessage
+func (w *SessionWatcher) SetOffsetStore(offsets *OffsetStore) {
+	w.offsets = offsets
+}
+
+// SetReadRate limits how many lines per second are read, so a burst of lines does
+// not flood the handler; zero is unlimited
+func (w *SessionWatcher) SetReadRate(linesPerSecond int) {
+	w.limiter = newLineLimiter(linesPerSecond)
+}
+
+// SetWatchStats sets where the watcher reports how far it has read
+func (w *SessionWatcher) SetWatchStats(stats *WatchStats) {
+	w.stats = stats
+}
+
+// SetTap sets where every line read is forwarded before it is parsed
+func (w *SessionWatcher) SetTap(tap *Tap) {
+	w.tap = tap
+}
+
+// Notify wakes the watcher to read the file that was written to
+func (w *SessionWatcher) Notify() {
+	select {
+	case w.wake <- struct{}{}:
+	default:
+	}
+}
+
 // Start starts watching the session file
 func (w *SessionWatcher) Start() error {
 	go w.watch()
@@ -41,61 +92,215 @@ func (w *SessionWatcher) Stop() {
 
 // watch monitors the session file
 func (w *SessionWatcher) watch() {
+	defer w.stats.remove(w.filePath)
 	if err := w.tailFile(); err != nil {
 		logger.LogError("Error watching session file: %v", err)
 	}
 }
 
-// tailFile tails the session file
+// tailFile tails the session file. A line is handled once its newline is written;
+// when the file is replaced or truncated it is read again from the start, skipping
+// the events already handled.
 func (w *SessionWatcher) tailFile() error {
 	file, err := os.Open(w.filePath)
 	if err != nil {
 		return fmt.Errorf("failed to open file: %w", err)
 	}
-	defer file.Close()
+	defer func() {
+		file.Close()
+	}()
 
-	// Move to end of file
-	_, err = file.Seek(0, io.SeekEnd)
+	offset, catchUp, err := w.seekStart(file)
 	if err != nil {
-		return fmt.Errorf("failed to seek to end: %w", err)
+		return err
 	}
 
 	reader := bufio.NewReader(file)
+	var pending string // Start of a line whose newline is not written yet
+	idle := minIdleWait
 
 	for {
 		select {
 		case <-w.done:
 			return nil
 		default:
-			line, err := reader.ReadString('\n')
+			chunk, err := reader.ReadString('\n')
 			if err != nil {
-				if err == io.EOF {
-					// No new data, wait a bit
-					time.Sleep(100 * time.Millisecond)
-					continue
+				if err != io.EOF {
+					return fmt.Errorf("error reading line: %w", err)
 				}
-				return fmt.Errorf("error reading line: %w", err)
-			}
-
-			// Process the line
-			if len(line) > 0 {
-				// Parse the line into an event
-				event, err := w.parser.Parse(line)
+				pending += chunk
+				if catchUp != nil {
+					w.sendCatchUp(catchUp)
+					catchUp = nil
+				}
+				reopened, err := w.reopenIfReplaced(file, offset+int64(len(pending)))
 				if err != nil {
-					logger.LogError("Error parsing line: %v", err)
+					return err
+				}
+				if reopened != nil {
+					file.Close()
+					file = reopened
+					reader.Reset(file)
+					offset, pending = 0, ""
 					continue
 				}
-				w.eventHandler.SendEvent(event)
+				// No new data, wait a bit
+				if !w.waitForData(idle) {
+					return nil
+				}
+				idle = min(idle*2, maxIdleWait)
+				continue
 			}
+
+			idle = minIdleWait
+			line := pending + chunk
+			pending = ""
+			offset += int64(len(line))
+			if !w.handleLine(line, offset, catchUp) {
+				return nil
+			}
+			if catchUp != nil && offset >= catchUp.end {
+				w.sendCatchUp(catchUp)
+				catchUp = nil
+			}
+			if w.offsets != nil {
+				w.offsets.SetOffset(w.filePath, offset)
+			}
+		}
+	}
+}
+
+// handleLine sends the event of a line read up to offset, or adds it to the catch-up
+// while resuming. Events whose UUID was already handled are skipped. It returns false
+// if the watcher stopped while the read rate limit held the line back.
+func (w *SessionWatcher) handleLine(line string, offset int64, catchUp *catchUp) bool {
+	if strings.TrimSpace(line) == "" {
+		return true
+	}
+	w.tap.Send(w.parser.session, w.filePath, line)
+	event, err := w.parser.Parse(line)
+	if err != nil {
+		logger.LogError("Error parsing line: %v", err)
+		return true
+	}
+	var timestamp time.Time
+	if base := BaseOf(event); base != nil {
+		if base.UUID != "" && w.seen.seen(base.UUID) {
+			logger.LogDebug("Skipping event already handled: %s", base.UUID)
+			return true
+		}
+		timestamp = base.Timestamp // Read before the handler owns the event
+	}
+	if catchUp != nil {
+		catchUp.record(event)
+	} else {
+		if !w.throttle(offset) {
+			return false
 		}
+		w.eventHandler.SendEvent(event)
+	}
+	w.stats.read(w.filePath, offset, timestamp)
+	return true
+}
+
+// throttle waits until the read rate limit allows another line. It returns false if
+// the watcher stopped meanwhile.
+func (w *SessionWatcher) throttle(offset int64) bool {
+	wait := w.limiter.reserve(time.Now())
+	if wait <= 0 {
+		return true
+	}
+	w.stats.throttle(w.filePath)
+	if info, err := os.Stat(w.filePath); err == nil {
+		w.stats.setSize(w.filePath, offset, info.Size())
+	}
+	select {
+	case <-w.done:
+		return false
+	case <-time.After(wait):
+		return true
+	}
+}
+
+// waitForData waits up to idle for the file to be written to. It returns false if
+// the watcher stopped meanwhile.
+func (w *SessionWatcher) waitForData(idle time.Duration) bool {
+	timer := time.NewTimer(idle)
+	defer timer.Stop()
+	select {
+	case <-w.done:
+		return false
+	case <-w.wake:
+	case <-timer.C:
+	}
+	return true
+}
+
+// reopenIfReplaced opens the session file again when another file took its place
+// (rotation) or it became shorter than what was read (truncation), and returns nil
+// while it is the file being read
+func (w *SessionWatcher) reopenIfReplaced(file *os.File, read int64) (*os.File, error) {
+	info, err := os.Stat(w.filePath)
+	if err != nil {
+		return nil, nil // Removed or being replaced; check again later
+	}
+	current, err := file.Stat()
+	if err != nil {
+		return nil, fmt.Errorf("failed to stat file: %w", err)
+	}
+	w.stats.setSize(w.filePath, read, info.Size())
+	switch {
+	case !os.SameFile(info, current):
+		logger.LogDebug("Session file was replaced, reading it again: %s", w.filePath)
+	case info.Size() < read:
+		logger.LogDebug("Session file was truncated, reading it again: %s", w.filePath)
+	default:
+		return nil, nil
+	}
+	reopened, err := os.Open(w.filePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open file: %w", err)
+	}
+	return reopened, nil
+}
+
+// seekStart moves to where tailing starts: the saved offset if the file has one, or
+// the end of the file. It returns the offset and, when resuming, the catch-up of the
+// events written since.
+func (w *SessionWatcher) seekStart(file *os.File) (int64, *catchUp, error) {
+	end, err := file.Seek(0, io.SeekEnd)
+	if err != nil {
+		return 0, nil, fmt.Errorf("failed to seek to end: %w", err)
+	}
+	if w.offsets == nil {
+		return end, nil, nil
+	}
+	// Start at the end of files never read and of files rewritten shorter than their offset
+	offset, ok := w.offsets.Offset(w.filePath)
+	if !ok || offset >= end || offset < 0 {
+		w.offsets.SetOffset(w.filePath, end)
+		return end, nil, nil
+	}
+	if _, err := file.Seek(offset, io.SeekStart); err != nil {
+		return 0, nil, fmt.Errorf("failed to seek to offset %d: %w", offset, err)
+	}
+	logger.LogDebug("Resuming %s from offset %d of %d", w.filePath, offset, end)
+	return offset, newCatchUp(end), nil
+}
+
+// sendCatchUp sends the summary of the events caught up on, if there were any
+func (w *SessionWatcher) sendCatchUp(c *catchUp) {
+	if msg := c.message(); msg != nil {
+		w.eventHandler.SendEvent(msg)
 	}
 }
 
 // ReadFullFile reads the entire session file
 func (w *SessionWatcher) ReadFullFile() error {
-	file, err := os.Open(w.filePath)
+	file, err := OpenTranscript(w.filePath)
 	if err != nil {
-		return fmt.Errorf("failed to open file: %w", err)
+		return err
 	}
 	defer file.Close()
 
@@ -111,6 +316,7 @@ func (w *SessionWatcher) ReadFullFile() error {
 		lineNum++
 		line := scanner.Text()
 		if len(line) > 0 {
+			w.tap.Send(w.parser.session, w.filePath, line)
 			// Parse the line into an event
 			event, err := w.parser.Parse(line)
 			if err != nil {
@@ -128,3 +334,34 @@ func (w *SessionWatcher) ReadFullFile() error {
 	logger.LogInfo("Finished reading %d lines", lineNum)
 	return nil
 }
+
+// lineLimiter spaces the lines read from a file to a rate, allowing bursts of a
+// second's worth of lines
+type lineLimiter struct {
+	rate   float64 // Lines per second
+	tokens float64 // Lines that can be read now; negative while lines wait
+	last   time.Time
+}
+
+// newLineLimiter creates a limiter of linesPerSecond, or nil for no limit
+func newLineLimiter(linesPerSecond int) *lineLimiter {
+	if linesPerSecond <= 0 {
+		return nil
+	}
+	rate := float64(linesPerSecond)
+	return &lineLimiter{rate: rate, tokens: rate, last: time.Now()}
+}
+
+// reserve takes a line from the budget and returns how long to wait before reading it
+func (l *lineLimiter) reserve(now time.Time) time.Duration {
+	if l == nil {
+		return 0
+	}
+	l.tokens = min(l.tokens+now.Sub(l.last).Seconds()*l.rate, l.rate)
+	l.last = now
+	l.tokens--
+	if l.tokens >= 0 {
+		return 0
+	}
+	return time.Duration(-l.tokens / l.rate * float64(time.Second))
+}
@@ -0,0 +1,212 @@
+package event
+
+import (
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/kazegusuri/claude-companion/narrator"
+)
+
+// collectingSink records the events the handler emits
+type collectingSink struct {
+	mu     sync.Mutex
+	events []Event
+}
+
+func (s *collectingSink) HandleEvent(event Event, formatted string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.events = append(s.events, event)
+}
+
+// wait returns the emitted events once there are n of them
+func (s *collectingSink) wait(t *testing.T, n int) []Event {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for time.Now().Before(deadline) {
+		s.mu.Lock()
+		events := append([]Event{}, s.events...)
+		s.mu.Unlock()
+		if len(events) >= n {
+			return events
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	t.Fatalf("got fewer than %d events", n)
+	return nil
+}
+
+func TestSessionWatcher_ResumesFromOffset(t *testing.T) {
+	seen := `{"type":"user","uuid":"u1","parentUuid":"p","timestamp":"2025-01-01T10:00:00Z","message":{"role":"user","content":"Fix the build"}}` + "\n"
+	missed := `{"type":"assistant","uuid":"a1","parentUuid":"u1","timestamp":"2025-01-01T10:00:05Z","message":{"id":"m1","content":[{"type":"tool_use","id":"t1","name":"Bash","input":{"command":"go build ./..."}}]}}
+{"type":"assistant","uuid":"a2","parentUuid":"a1","timestamp":"2025-01-01T10:01:05Z","message":{"id":"m2","content":[{"type":"tool_use","id":"t2","name":"Edit","input":{"file_path":"/work/main.go"}}]}}
+`
+	live := `{"type":"assistant","uuid":"a3","parentUuid":"a2","timestamp":"2025-01-01T10:02:00Z","message":{"id":"m3","content":[{"type":"text","text":"Fixed."}]}}` + "\n"
+
+	path := filepath.Join(t.TempDir(), "-work", "s1.jsonl")
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(seen+missed), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	offsets := NewOffsetStore("")
+	offsets.SetOffset(path, int64(len(seen)))
+
+	handler := NewHandler(narrator.NewNoOpNarrator(), false)
+	sink := &collectingSink{}
+	handler.AddSink(sink)
+	handler.Start()
+	defer handler.Stop()
+
+	watcher := NewSessionWatcher(path, handler)
+	watcher.SetOffsetStore(offsets)
+	if err := watcher.Start(); err != nil {
+		t.Fatal(err)
+	}
+	defer watcher.Stop()
+
+	catchUp, ok := sink.wait(t, 1)[0].(*CatchUpMessage)
+	if !ok {
+		t.Fatalf("first event is not a catch-up")
+	}
+	if catchUp.Events != 2 || catchUp.Commands != 1 || len(catchUp.FilesEdited) != 1 || catchUp.Duration != time.Minute {
+		t.Errorf("catch-up = %d events, %d commands, files %v over %s, want 2 events, 1 command, 1 file over 1m",
+			catchUp.Events, catchUp.Commands, catchUp.FilesEdited, catchUp.Duration)
+	}
+
+	// Lines written after resuming are shown as usual
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.WriteString(live)
+	f.Close()
+	events := sink.wait(t, 2)
+	if am, ok := events[1].(*AssistantMessage); !ok || am.UUID != "a3" {
+		t.Errorf("second event = %#v, want the new assistant message", events[1])
+	}
+
+	// The offset is recorded right after the line is handed to the handler
+	want := int64(len(seen + missed + live))
+	for i := 0; i < 100; i++ {
+		if got, _ := offsets.Offset(path); got == want {
+			return
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	got, _ := offsets.Offset(path)
+	t.Errorf("offset = %d, want %d", got, want)
+}
+
+func TestSessionWatcher_PartialAndReplacedFiles(t *testing.T) {
+	line := func(uuid string) string {
+		return `{"type":"user","uuid":"` + uuid + `","parentUuid":"p","timestamp":"2025-01-01T10:00:00Z","message":{"role":"user","content":"hello"}}` + "\n"
+	}
+	path := filepath.Join(t.TempDir(), "-work", "s1.jsonl")
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, nil, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	appendTo := func(data string) {
+		t.Helper()
+		f, err := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer f.Close()
+		if _, err := f.WriteString(data); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	handler := NewHandler(narrator.NewNoOpNarrator(), false)
+	sink := &collectingSink{}
+	handler.AddSink(sink)
+	handler.Start()
+	defer handler.Stop()
+	watcher := NewSessionWatcher(path, handler)
+	if err := watcher.Start(); err != nil {
+		t.Fatal(err)
+	}
+	defer watcher.Stop()
+	time.Sleep(200 * time.Millisecond) // Let the watcher reach the end of the file
+
+	// A line written in two parts is handled once complete
+	first := line("u1")
+	appendTo(first[:40])
+	time.Sleep(300 * time.Millisecond)
+	appendTo(first[40:])
+	sink.wait(t, 1)
+
+	// A rewritten file is read again without repeating the events already shown
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, []byte(first+line("u2")), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		t.Fatal(err)
+	}
+	sink.wait(t, 2)
+
+	// So is a truncated one
+	if err := os.WriteFile(path, []byte(line("u3")), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	sink.wait(t, 3)
+	time.Sleep(300 * time.Millisecond) // Nothing more is emitted
+
+	sink.mu.Lock()
+	defer sink.mu.Unlock()
+	var uuids []string
+	for _, ev := range sink.events {
+		uuids = append(uuids, BaseOf(ev).UUID)
+	}
+	if len(sink.events) != 3 || uuids[0] != "u1" || uuids[1] != "u2" || uuids[2] != "u3" {
+		t.Errorf("events = %v, want [u1 u2 u3]", uuids)
+	}
+}
+
+func TestRecentUUIDs(t *testing.T) {
+	r := newRecentUUIDs(2)
+	for _, step := range []struct {
+		uuid string
+		want bool
+	}{
+		{"a", false}, {"b", false}, {"a", true}, {"c", false}, // b is the least recently seen
+		{"b", false}, {"a", false}, {"c", false},
+	} {
+		if got := r.seen(step.uuid); got != step.want {
+			t.Errorf("seen(%q) = %v, want %v", step.uuid, got, step.want)
+		}
+	}
+}
+
+func TestLineLimiter(t *testing.T) {
+	if newLineLimiter(0) != nil {
+		t.Error("newLineLimiter(0) is not nil, want no limit")
+	}
+	start := time.Now()
+	l := newLineLimiter(10)
+	l.last = start
+	// A second's worth of lines are read right away
+	for i := 0; i < 10; i++ {
+		if wait := l.reserve(start); wait != 0 {
+			t.Fatalf("line %d waits %s, want 0", i, wait)
+		}
+	}
+	if wait := l.reserve(start); wait != 100*time.Millisecond {
+		t.Errorf("11th line waits %s, want 100ms", wait)
+	}
+	if wait := l.reserve(start); wait != 200*time.Millisecond {
+		t.Errorf("12th line waits %s, want 200ms", wait)
+	}
+	if wait := l.reserve(start.Add(time.Second)); wait != 0 {
+		t.Errorf("line a second later waits %s, want 0", wait)
+	}
+}
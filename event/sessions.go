@@ -0,0 +1,246 @@
+package event
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/kazegusuri/claude-companion/logger"
+)
+
+// SessionState is what the companion knows about a Claude session
+type SessionState struct {
+	ID             string    `json:"id"`
+	Project        string    `json:"project,omitempty"`
+	Session        string    `json:"session,omitempty"` // Transcript file name without .jsonl
+	CWD            string    `json:"cwd,omitempty"`
+	TranscriptPath string    `json:"transcriptPath,omitempty"`
+	Source         string    `json:"source,omitempty"` // How the session last started: startup, clear or resume
+	FirstSeen      time.Time `json:"firstSeen"`
+	LastSeen       time.Time `json:"lastSeen"`
+	Events         int       `json:"events"`
+}
+
+// sessionStateFile is the file format of the persisted session registry
+type sessionStateFile struct {
+	Sessions []SessionState `json:"sessions"`
+}
+
+// maxSessionStates bounds the sessions kept in the registry; the least recently seen are dropped
+const maxSessionStates = 1000
+
+// SessionRegistry tracks the sessions seen by the handler and persists them to a
+// JSON file, so session knowledge survives a restart of the companion
+type SessionRegistry struct {
+	path     string
+	mu       sync.RWMutex
+	sessions map[string]*SessionState // key: session ID
+	dirty    bool
+	now      func() time.Time
+
+	done chan struct{}
+	wg   sync.WaitGroup
+}
+
+// NewSessionRegistry creates a registry persisted to path. An empty path keeps it in memory.
+func NewSessionRegistry(path string) *SessionRegistry {
+	return &SessionRegistry{
+		path:     path,
+		sessions: make(map[string]*SessionState),
+		now:      time.Now,
+	}
+}
+
+// Load reads the persisted sessions. A missing file is not an error.
+func (r *SessionRegistry) Load() error {
+	if r.path == "" {
+		return nil
+	}
+	data, err := os.ReadFile(r.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read session state: %w", err)
+	}
+	var file sessionStateFile
+	if err := json.Unmarshal(data, &file); err != nil {
+		return fmt.Errorf("failed to parse session state %s: %w", r.path, err)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for i := range file.Sessions {
+		state := file.Sessions[i]
+		if state.ID != "" {
+			r.sessions[state.ID] = &state
+		}
+	}
+	return nil
+}
+
+// Save writes the sessions to the state file if they changed since the last save
+func (r *SessionRegistry) Save() error {
+	if r.path == "" {
+		return nil
+	}
+	r.mu.Lock()
+	if !r.dirty {
+		r.mu.Unlock()
+		return nil
+	}
+	file := sessionStateFile{Sessions: r.list()}
+	r.dirty = false
+	r.mu.Unlock()
+
+	data, err := json.MarshalIndent(file, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode session state: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(r.path), 0o755); err != nil {
+		return fmt.Errorf("failed to create session state directory: %w", err)
+	}
+	// Write a temporary file and rename it so a crash never leaves a partial file
+	tmp := r.path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o600); err != nil {
+		return fmt.Errorf("failed to write session state: %w", err)
+	}
+	if err := os.Rename(tmp, r.path); err != nil {
+		return fmt.Errorf("failed to write session state: %w", err)
+	}
+	return nil
+}
+
+// Start saves the sessions periodically in the background
+func (r *SessionRegistry) Start(interval time.Duration) {
+	r.done = make(chan struct{})
+	r.wg.Add(1)
+	go func() {
+		defer r.wg.Done()
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				if err := r.Save(); err != nil {
+					logger.LogError("Error saving session state: %v", err)
+				}
+			case <-r.done:
+				return
+			}
+		}
+	}()
+}
+
+// Stop stops the periodic saves and saves the sessions one last time
+func (r *SessionRegistry) Stop() {
+	if r.done != nil {
+		close(r.done)
+		r.wg.Wait()
+	}
+	if err := r.Save(); err != nil {
+		logger.LogError("Error saving session state: %v", err)
+	}
+}
+
+// Observe updates the session of an event
+func (r *SessionRegistry) Observe(event Event) {
+	id := SessionIDOf(event)
+	if id == "" {
+		return
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	now := r.now()
+	state, ok := r.sessions[id]
+	if !ok {
+		state = &SessionState{ID: id, FirstSeen: now, LastSeen: now}
+		r.evict()
+		r.sessions[id] = state
+	}
+	state.LastSeen = now
+	state.Events++
+	if session := SessionOf(event); session != nil {
+		state.Project = session.Project
+		state.Session = session.Session
+	}
+	switch e := event.(type) {
+	case *NotificationEvent:
+		if e.CWD != "" {
+			state.CWD = e.CWD
+		}
+		if e.TranscriptPath != "" {
+			state.TranscriptPath = e.TranscriptPath
+		}
+		if e.HookEventName == "SessionStart" && e.Source != "" {
+			state.Source = e.Source
+		}
+	default:
+		if base := BaseOf(event); base != nil && base.CWD != "" {
+			state.CWD = base.CWD
+		}
+	}
+	r.dirty = true
+}
+
+// evict drops the least recently seen session when the registry has no room for another
+func (r *SessionRegistry) evict() {
+	if len(r.sessions) < maxSessionStates {
+		return
+	}
+	var oldest *SessionState
+	for _, state := range r.sessions {
+		if oldest == nil || state.LastSeen.Before(oldest.LastSeen) {
+			oldest = state
+		}
+	}
+	delete(r.sessions, oldest.ID)
+}
+
+// StartedFresh reports whether the session's SessionStart hook said it started
+// from scratch (startup or clear) rather than resuming another session
+func (r *SessionRegistry) StartedFresh(id string) bool {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	state, ok := r.sessions[id]
+	return ok && (state.Source == "startup" || state.Source == "clear")
+}
+
+// ListSessions returns the known sessions, most recently seen first
+func (r *SessionRegistry) ListSessions() []SessionState {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.list()
+}
+
+// list returns copies of the sessions, most recently seen first; r.mu must be held
+func (r *SessionRegistry) list() []SessionState {
+	sessions := make([]SessionState, 0, len(r.sessions))
+	for _, state := range r.sessions {
+		sessions = append(sessions, *state)
+	}
+	sort.Slice(sessions, func(i, j int) bool {
+		if !sessions[i].LastSeen.Equal(sessions[j].LastSeen) {
+			return sessions[i].LastSeen.After(sessions[j].LastSeen)
+		}
+		return sessions[i].ID < sessions[j].ID
+	})
+	return sessions
+}
+
+// GetSession returns a session by its ID
+func (r *SessionRegistry) GetSession(id string) (SessionState, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	state, ok := r.sessions[id]
+	if !ok {
+		return SessionState{}, false
+	}
+	return *state, true
+}
@@ -0,0 +1,111 @@
+package event
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestSessionRegistry_Observe(t *testing.T) {
+	registry := NewSessionRegistry("")
+	now := time.Date(2025, 1, 26, 10, 0, 0, 0, time.UTC)
+	registry.now = func() time.Time { return now }
+
+	registry.Observe(&NotificationEvent{
+		SessionID:      "s1",
+		HookEventName:  "SessionStart",
+		Source:         "startup",
+		CWD:            "/home/user/app",
+		TranscriptPath: "/home/user/.claude/projects/-home-user-app/s1.jsonl",
+	})
+	now = now.Add(time.Minute)
+	registry.Observe(&UserMessage{BaseEvent: BaseEvent{SessionID: "s1", CWD: "/home/user/app/sub"}})
+	registry.Observe(&UserMessage{BaseEvent: BaseEvent{SessionID: "s2"}})
+	registry.Observe(&SummaryEvent{})
+
+	want := SessionState{
+		ID:             "s1",
+		Project:        "-home-user-app",
+		Session:        "s1",
+		CWD:            "/home/user/app/sub",
+		TranscriptPath: "/home/user/.claude/projects/-home-user-app/s1.jsonl",
+		Source:         "startup",
+		FirstSeen:      time.Date(2025, 1, 26, 10, 0, 0, 0, time.UTC),
+		LastSeen:       time.Date(2025, 1, 26, 10, 1, 0, 0, time.UTC),
+		Events:         2,
+	}
+	got, ok := registry.GetSession("s1")
+	if !ok {
+		t.Fatalf("GetSession() found no session")
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("GetSession() mismatch (-want +got):\n%s", diff)
+	}
+	if sessions := registry.ListSessions(); len(sessions) != 2 {
+		t.Errorf("ListSessions() = %d sessions, want 2", len(sessions))
+	}
+	if !registry.StartedFresh("s1") || registry.StartedFresh("s2") {
+		t.Errorf("StartedFresh() should only report the session with a startup hook")
+	}
+}
+
+func TestSessionRegistry_Persistence(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "state", "sessions.json")
+	registry := NewSessionRegistry(path)
+	registry.Observe(&NotificationEvent{SessionID: "s1", HookEventName: "SessionStart", Source: "resume"})
+	registry.Observe(&UserMessage{BaseEvent: BaseEvent{SessionID: "s2", CWD: "/work"}})
+	if err := registry.Save(); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+
+	reloaded := NewSessionRegistry(path)
+	if err := reloaded.Load(); err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if diff := cmp.Diff(registry.ListSessions(), reloaded.ListSessions()); diff != "" {
+		t.Errorf("reloaded sessions mismatch (-want +got):\n%s", diff)
+	}
+
+	if err := NewSessionRegistry(filepath.Join(t.TempDir(), "missing.json")).Load(); err != nil {
+		t.Errorf("Load() of a missing file error = %v", err)
+	}
+}
+
+func TestHandler_FreshSessionIsNotBuffered(t *testing.T) {
+	mockFormatter := &mockFormatterWithRecording{}
+	handler := &Handler{
+		narrator:    &mockNarrator{},
+		formatter:   mockFormatter,
+		eventChan:   make(chan Event, 100),
+		done:        make(chan struct{}),
+		taskTracker: NewTaskTracker(),
+		buffers:     make(map[string]*BufferInfo),
+	}
+	handler.SetSessionRegistry(NewSessionRegistry(""))
+
+	fresh := createTestUserMessage("fresh", nil)
+	fresh.SessionID = "fresh"
+	resumed := createTestUserMessage("resumed", nil)
+	resumed.SessionID = "resumed"
+
+	captureOutput(t, func() {
+		handler.processEvent(&NotificationEvent{SessionID: "fresh", HookEventName: "SessionStart", Source: "startup"})
+		handler.processEvent(fresh)
+		handler.processEvent(resumed)
+	})
+
+	handler.bufferMutex.Lock()
+	defer handler.bufferMutex.Unlock()
+	if _, ok := handler.buffers["fresh"]; ok {
+		t.Errorf("the first prompt of a fresh session was buffered")
+	}
+	if _, ok := handler.buffers["resumed"]; !ok {
+		t.Errorf("a line without a parent in an unknown session was not buffered")
+	}
+	// The SessionStart notification and the fresh session's prompt
+	if got := mockFormatter.getProcessedCount(); got != 2 {
+		t.Errorf("processed %d events, want 2", got)
+	}
+}
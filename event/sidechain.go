@@ -0,0 +1,130 @@
+package event
+
+import (
+	"strings"
+	"sync"
+	"time"
+)
+
+// defaultSubagentType is the subagent Claude Code runs when a Task names none
+const defaultSubagentType = "general-purpose"
+
+// SidechainRun is what the subagent of a Task did
+type SidechainRun struct {
+	Task     TaskInfo
+	ToolUses int
+	Start    time.Time // Time of the first event of the subagent
+	End      time.Time // Time of the last event of the subagent
+}
+
+// Agent returns the subagent type of the run
+func (r *SidechainRun) Agent() string {
+	if r.Task.SubagentType == "" {
+		return defaultSubagentType
+	}
+	return r.Task.SubagentType
+}
+
+// Elapsed returns the time between the first and the last event of the run
+func (r *SidechainRun) Elapsed() time.Duration {
+	if r.Start.IsZero() || r.End.Before(r.Start) {
+		return 0
+	}
+	return r.End.Sub(r.Start)
+}
+
+// SidechainTracker attributes sidechain events, which Claude Code writes for the
+// subagents started by the Task tool, to their Task and accumulates what each
+// subagent did
+type SidechainTracker struct {
+	tasks  *TaskTracker
+	chains map[string]string        // key: event UUID, value: Task tool_use_id
+	runs   map[string]*SidechainRun // key: Task tool_use_id
+	mu     sync.Mutex
+}
+
+// NewSidechainTracker creates a tracker that finds the Tasks of sidechains in tasks
+func NewSidechainTracker(tasks *TaskTracker) *SidechainTracker {
+	return &SidechainTracker{
+		tasks:  tasks,
+		chains: make(map[string]string),
+		runs:   make(map[string]*SidechainRun),
+	}
+}
+
+// Attribute records a sidechain event and returns the run it belongs to: the run of
+// its parent event, or for the first event of a chain, the Task that was given its
+// prompt. It returns nil if no Task is running.
+func (t *SidechainTracker) Attribute(event Event) *SidechainRun {
+	base := BaseOf(event)
+	if base == nil {
+		return nil
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	var run *SidechainRun
+	if base.ParentUUID != nil {
+		run = t.runs[t.chains[*base.ParentUUID]]
+	}
+	if run == nil {
+		task, ok := t.tasks.FindTask(sidechainPrompt(event))
+		if !ok {
+			return nil
+		}
+		if run = t.runs[task.ToolUseID]; run == nil {
+			run = &SidechainRun{Task: task, Start: base.Timestamp}
+			t.runs[task.ToolUseID] = run
+		}
+	}
+	if base.UUID != "" {
+		t.chains[base.UUID] = run.Task.ToolUseID
+	}
+	if !base.Timestamp.IsZero() {
+		run.End = base.Timestamp
+	}
+	if msg, ok := event.(*AssistantMessage); ok {
+		for _, content := range msg.Message.Content {
+			if content.Type == "tool_use" {
+				run.ToolUses++
+			}
+		}
+	}
+	return run
+}
+
+// Finish returns the run of a Task that completed and forgets it
+func (t *SidechainTracker) Finish(toolUseID string) (*SidechainRun, bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	run, ok := t.runs[toolUseID]
+	if !ok {
+		return nil, false
+	}
+	delete(t.runs, toolUseID)
+	for uuid, id := range t.chains {
+		if id == toolUseID {
+			delete(t.chains, uuid)
+		}
+	}
+	return run, true
+}
+
+// sidechainPrompt returns the prompt of the first event of a sidechain, which is
+// the user message the Task tool sent to its subagent
+func sidechainPrompt(event Event) string {
+	msg, ok := event.(*UserMessage)
+	if !ok {
+		return ""
+	}
+	if text, ok := msg.Message.Content.(string); ok {
+		return strings.TrimSpace(text)
+	}
+	return ""
+}
+
+// isSidechain reports whether an event comes from a subagent
+func isSidechain(event Event) bool {
+	base := BaseOf(event)
+	return base != nil && base.IsSidechain
+}
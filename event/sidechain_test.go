@@ -0,0 +1,104 @@
+package event
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+// sidechainPromptMessage creates the first event of a sidechain
+func sidechainPromptMessage(uuid, prompt string, at time.Time) *UserMessage {
+	return &UserMessage{
+		BaseEvent: BaseEvent{TypeString: "user", UUID: uuid, IsSidechain: true, Timestamp: at},
+		Message:   UserMessageContent{Role: "user", Content: prompt},
+	}
+}
+
+func TestSidechainTracker(t *testing.T) {
+	start := time.Date(2025, 1, 1, 10, 0, 0, 0, time.UTC)
+	tasks := NewTaskTracker()
+	tasks.TrackTask("task-a", "Find A", "Explore", "look for A")
+	tasks.TrackTask("task-b", "Find B", "", "look for B")
+	tracker := NewSidechainTracker(tasks)
+
+	// Parallel sidechains are told apart by their prompt and then by their parents
+	a := tracker.Attribute(sidechainPromptMessage("a1", "look for A", start))
+	b := tracker.Attribute(sidechainPromptMessage("b1", "look for B", start))
+	if a == nil || a.Task.ToolUseID != "task-a" || b == nil || b.Task.ToolUseID != "task-b" {
+		t.Fatalf("Attribute() = %+v, %+v; want task-a and task-b", a, b)
+	}
+	for _, use := range []struct {
+		uuid   string
+		parent string
+		tool   string
+		after  time.Duration
+	}{
+		{uuid: "a2", parent: "a1", tool: "Read", after: 10 * time.Second},
+		{uuid: "a3", parent: "a2", tool: "Grep", after: 20 * time.Second},
+		{uuid: "b2", parent: "b1", tool: "Read", after: 5 * time.Second},
+	} {
+		msg := assistantMessage("s1", start.Add(use.after), toolUseContent("sub-"+use.uuid, use.tool, nil))
+		msg.UUID, msg.ParentUUID, msg.IsSidechain = use.uuid, &use.parent, true
+		tracker.Attribute(msg)
+	}
+
+	run, ok := tracker.Finish("task-a")
+	if !ok || run.ToolUses != 2 || run.Elapsed() != 20*time.Second || run.Agent() != "Explore" {
+		t.Errorf("Finish(task-a) = %+v, %v; want 2 tool uses in 20s by Explore", run, ok)
+	}
+	run, ok = tracker.Finish("task-b")
+	if !ok || run.ToolUses != 1 || run.Agent() != defaultSubagentType {
+		t.Errorf("Finish(task-b) = %+v, %v; want 1 tool use by %s", run, ok, defaultSubagentType)
+	}
+	if _, ok := tracker.Finish("task-a"); ok {
+		t.Errorf("Finish() returned a run twice")
+	}
+}
+
+func TestHandler_ShowSidechains(t *testing.T) {
+	handler := NewHandler(&mockNarrator{}, false)
+	handler.SetShowSidechains(true)
+	sink := &recordingSink{}
+	handler.AddSink(sink)
+
+	start := time.Date(2025, 1, 1, 10, 0, 0, 0, time.UTC)
+	task := assistantMessage("s1", start, toolUseContent("task-1", "Task", map[string]interface{}{
+		"description":   "Find the handler",
+		"subagent_type": "Explore",
+		"prompt":        "find it",
+	}))
+	subagent := assistantMessage("s1", start.Add(3*time.Second), toolUseContent("sub-s2", "Read", map[string]interface{}{"file_path": "/tmp/a.go"}))
+	parent := "s1"
+	subagent.UUID, subagent.ParentUUID, subagent.IsSidechain = "s2", &parent, true
+	captureOutput(t, func() {
+		handler.processEvent(task)
+		handler.processEvent(sidechainPromptMessage("s1", "find it", start.Add(time.Second)))
+		handler.processEvent(subagent)
+		handler.processEvent(toolResult("task-1", start.Add(40*time.Second)))
+	})
+
+	var sidechain, completion string
+	for i, e := range sink.events {
+		switch e := e.(type) {
+		case *AssistantMessage:
+			if e.IsSidechain {
+				sidechain = sink.formatted[i]
+			}
+		case *TaskCompletionMessage:
+			completion = sink.formatted[i]
+		}
+	}
+	if !strings.HasPrefix(sidechain, "  ↳ [Explore] ") {
+		t.Errorf("sidechain output = %q, want it attributed to the subagent", sidechain)
+	}
+	for _, line := range strings.Split(strings.TrimSuffix(sidechain, "\n"), "\n")[1:] {
+		if !strings.HasPrefix(line, "  │ ") {
+			t.Errorf("sidechain line %q is not indented", line)
+		}
+	}
+	for _, want := range []string{"↳ Explore: 1 tool use in 2s", "Exploreがツールを1回使いました"} {
+		if !strings.Contains(completion, want) {
+			t.Errorf("task completion = %q, want it to contain %q", completion, want)
+		}
+	}
+}
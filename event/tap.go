@@ -0,0 +1,218 @@
+package event
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/kazegusuri/claude-companion/logger"
+)
+
+// tapQueueSize is the number of lines waiting to be forwarded; more are dropped while
+// the tap cannot keep up or its endpoint is unreachable
+const tapQueueSize = 1024
+
+// TapEnvelope wraps a raw transcript line forwarded by a Tap with the session it belongs to
+type TapEnvelope struct {
+	Time    time.Time       `json:"time"` // When the line was read
+	Root    string          `json:"root,omitempty"`
+	Project string          `json:"project"`
+	Session string          `json:"session"`
+	Path    string          `json:"path"`
+	Line    json.RawMessage `json:"line"` // The line as written; a JSON string if it is not valid JSON
+}
+
+// Tap forwards every line read from the transcripts, before it is parsed, to a JSONL
+// file or a TCP endpoint, so other tools can consume them without watching the
+// projects themselves. Lines are sent in the background; the TCP endpoint is
+// reconnected with exponential backoff and lines are dropped while the queue is full.
+type Tap struct {
+	target     string
+	path       string // File appended to; empty for TCP
+	addr       string // TCP endpoint
+	minBackoff time.Duration
+	maxBackoff time.Duration
+
+	queue   chan []byte
+	done    chan struct{}
+	wg      sync.WaitGroup
+	pending []byte // Line that failed to be written, resent first after reconnecting
+	dropped atomic.Int64
+}
+
+// NewTap creates a tap for a target: tcp://host:port, or the path of a file the
+// lines are appended to. Call Start to begin forwarding.
+func NewTap(target string) (*Tap, error) {
+	t := &Tap{
+		target:     target,
+		minBackoff: time.Second,
+		maxBackoff: time.Minute,
+		queue:      make(chan []byte, tapQueueSize),
+		done:       make(chan struct{}),
+	}
+	if strings.HasPrefix(target, "tcp://") {
+		u, err := url.Parse(target)
+		if err != nil || u.Hostname() == "" || u.Port() == "" {
+			return nil, fmt.Errorf("invalid tap %q (expected tcp://host:port)", target)
+		}
+		t.addr = u.Host
+		return t, nil
+	}
+	if target == "" {
+		return nil, fmt.Errorf("tap target is empty")
+	}
+	if err := os.MkdirAll(filepath.Dir(target), 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create tap directory: %w", err)
+	}
+	t.path = target
+	return t, nil
+}
+
+// Start begins forwarding lines in the background
+func (t *Tap) Start() {
+	t.wg.Add(1)
+	go t.run()
+}
+
+// Stop forwards the lines that can be written right away and closes the target
+func (t *Tap) Stop() {
+	close(t.done)
+	t.wg.Wait()
+}
+
+// Dropped returns the number of lines dropped because the queue was full
+func (t *Tap) Dropped() int64 {
+	return t.dropped.Load()
+}
+
+// QueueSize returns the number of lines waiting to be forwarded
+func (t *Tap) QueueSize() int {
+	return len(t.queue)
+}
+
+// Send queues a raw line of the transcript at path of session. It does nothing on a nil tap.
+func (t *Tap) Send(session *Session, path string, line string) {
+	if t == nil {
+		return
+	}
+	envelope := TapEnvelope{Time: time.Now(), Path: path}
+	if session != nil {
+		envelope.Root = session.Root
+		envelope.Project = session.Project
+		envelope.Session = session.Session
+	}
+	line = strings.TrimRight(line, "\r\n")
+	if json.Valid([]byte(line)) {
+		envelope.Line = json.RawMessage(line)
+	} else {
+		envelope.Line, _ = json.Marshal(line)
+	}
+	data, err := json.Marshal(envelope)
+	if err != nil {
+		logger.LogDebug("Failed to encode tap envelope: %v", err)
+		return
+	}
+	select {
+	case t.queue <- append(data, '\n'):
+	default:
+		if t.dropped.Add(1) == 1 {
+			logger.LogWarning("Tap %s is not keeping up, dropping lines", t.target)
+		}
+	}
+}
+
+// run keeps the target open and writes the queued lines to it
+func (t *Tap) run() {
+	defer t.wg.Done()
+	backoff := t.minBackoff
+	warned := false
+	for {
+		w, err := t.open()
+		if err != nil {
+			// Warn once per outage rather than on every attempt
+			if !warned {
+				logger.LogWarning("Failed to open tap %s, retrying: %v", t.target, err)
+				warned = true
+			} else {
+				logger.LogDebug("Failed to open tap %s: %v", t.target, err)
+			}
+			select {
+			case <-t.done:
+				return
+			case <-time.After(backoff):
+			}
+			backoff = min(backoff*2, t.maxBackoff)
+			continue
+		}
+		if t.addr != "" {
+			logger.LogInfo("Connected to tap %s", t.target)
+		}
+		backoff, warned = t.minBackoff, false
+
+		err = t.serve(w)
+		w.Close()
+		if err == nil {
+			return // Stopped
+		}
+		logger.LogWarning("Lost tap %s, reopening: %v", t.target, err)
+		warned = true
+	}
+}
+
+// open opens the file or connects to the TCP endpoint
+func (t *Tap) open() (io.WriteCloser, error) {
+	if t.addr != "" {
+		return net.DialTimeout("tcp", t.addr, 10*time.Second)
+	}
+	return os.OpenFile(t.path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o644)
+}
+
+// serve writes the queued lines until the tap stops, when it writes those still
+// queued, or a write fails
+func (t *Tap) serve(w io.Writer) error {
+	if t.pending != nil {
+		if err := t.write(w, t.pending); err != nil {
+			return err
+		}
+	}
+	for {
+		select {
+		case data := <-t.queue:
+			if err := t.write(w, data); err != nil {
+				return err
+			}
+		case <-t.done:
+			for {
+				select {
+				case data := <-t.queue:
+					if err := t.write(w, data); err != nil {
+						return nil // Stopping anyway
+					}
+				default:
+					return nil
+				}
+			}
+		}
+	}
+}
+
+// write writes a line, keeping it to resend if the write fails
+func (t *Tap) write(w io.Writer, data []byte) error {
+	if conn, ok := w.(net.Conn); ok {
+		conn.SetWriteDeadline(time.Now().Add(10 * time.Second))
+	}
+	if _, err := w.Write(data); err != nil {
+		t.pending = data
+		return err
+	}
+	t.pending = nil
+	return nil
+}
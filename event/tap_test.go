@@ -0,0 +1,130 @@
+package event
+
+import (
+	"bufio"
+	"encoding/json"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestTap_File(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "tap", "lines.jsonl")
+	tap, err := NewTap(path)
+	if err != nil {
+		t.Fatalf("NewTap() error = %v", err)
+	}
+	tap.Start()
+	session := &Session{Project: "-home-user-app", Session: "abc", Root: "work"}
+	tap.Send(session, "/projects/-home-user-app/abc.jsonl", `{"type":"user","uuid":"1"}`+"\n")
+	tap.Send(session, "/projects/-home-user-app/abc.jsonl", `{"type":"assist`)
+	tap.Stop()
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("tap wrote %d lines, want 2:\n%s", len(lines), data)
+	}
+	var first, second TapEnvelope
+	if err := json.Unmarshal([]byte(lines[0]), &first); err != nil {
+		t.Fatal(err)
+	}
+	if first.Root != "work" || first.Project != "-home-user-app" || first.Session != "abc" || string(first.Line) != `{"type":"user","uuid":"1"}` {
+		t.Errorf("first envelope = %+v, want the session labels and the line as is", first)
+	}
+	if err := json.Unmarshal([]byte(lines[1]), &second); err != nil {
+		t.Fatal(err)
+	}
+	if string(second.Line) != `"{\"type\":\"assist"` {
+		t.Errorf("second envelope line = %s, want the invalid line as a string", second.Line)
+	}
+}
+
+func TestTap_TCP(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ln.Close()
+	received := make(chan string, 2)
+	go func() {
+		conn, err := ln.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		scanner := bufio.NewScanner(conn)
+		for scanner.Scan() {
+			received <- scanner.Text()
+		}
+	}()
+
+	tap, err := NewTap("tcp://" + ln.Addr().String())
+	if err != nil {
+		t.Fatalf("NewTap() error = %v", err)
+	}
+	tap.Start()
+	defer tap.Stop()
+	tap.Send(&Session{Project: "p", Session: "s"}, "p/s.jsonl", `{"type":"summary"}`)
+
+	select {
+	case line := <-received:
+		var envelope TapEnvelope
+		if err := json.Unmarshal([]byte(line), &envelope); err != nil {
+			t.Fatal(err)
+		}
+		if envelope.Project != "p" || envelope.Session != "s" || envelope.Path != "p/s.jsonl" || string(envelope.Line) != `{"type":"summary"}` {
+			t.Errorf("envelope = %+v", envelope)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("the TCP endpoint received nothing")
+	}
+}
+
+func TestNewTap_Invalid(t *testing.T) {
+	for _, target := range []string{"", "tcp://localhost", "tcp://:9000"} {
+		if _, err := NewTap(target); err == nil {
+			t.Errorf("NewTap(%q) should fail", target)
+		}
+	}
+}
+
+func TestSessionWatcher_Tap(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "-home-user-app")
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	transcript := filepath.Join(dir, "abc.jsonl")
+	if err := os.WriteFile(transcript, []byte("{\"type\":\"summary\",\"summary\":\"s\"}\nnot json\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	tapFile := filepath.Join(t.TempDir(), "tap.jsonl")
+	tap, err := NewTap(tapFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tap.Start()
+
+	handler := NewHandler(&mockNarrator{}, false)
+	watcher := NewSessionWatcher(transcript, handler)
+	watcher.SetTap(tap)
+	if err := watcher.ReadFullFile(); err != nil {
+		t.Fatal(err)
+	}
+	tap.Stop()
+
+	data, err := os.ReadFile(tapFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Lines the parser rejects are forwarded too
+	if got := strings.Count(string(data), `"project":"-home-user-app","session":"abc"`); got != 2 {
+		t.Errorf("tap forwarded %d lines of the session, want 2:\n%s", got, data)
+	}
+}
@@ -9,11 +9,13 @@ type TaskInfo struct {
 	ToolUseID    string
 	Description  string
 	SubagentType string
+	Prompt       string
 }
 
 // TaskTracker tracks Task tool executions by their tool_use_id
 type TaskTracker struct {
 	tasks map[string]TaskInfo
+	order []string // tool_use_ids in the order the tasks started
 	mu    sync.RWMutex
 }
 
@@ -25,13 +27,17 @@ func NewTaskTracker() *TaskTracker {
 }
 
 // TrackTask stores information about a Task execution
-func (t *TaskTracker) TrackTask(toolUseID, description, subagentType string) {
+func (t *TaskTracker) TrackTask(toolUseID, description, subagentType, prompt string) {
 	t.mu.Lock()
 	defer t.mu.Unlock()
+	if _, exists := t.tasks[toolUseID]; !exists {
+		t.order = append(t.order, toolUseID)
+	}
 	t.tasks[toolUseID] = TaskInfo{
 		ToolUseID:    toolUseID,
 		Description:  description,
 		SubagentType: subagentType,
+		Prompt:       prompt,
 	}
 }
 
@@ -43,9 +49,31 @@ func (t *TaskTracker) GetTask(toolUseID string) (TaskInfo, bool) {
 	return info, exists
 }
 
+// FindTask returns the running Task that was given prompt, or the most recently
+// started one if none was
+func (t *TaskTracker) FindTask(prompt string) (TaskInfo, bool) {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	if len(t.order) == 0 {
+		return TaskInfo{}, false
+	}
+	for _, id := range t.order {
+		if info := t.tasks[id]; prompt != "" && info.Prompt == prompt {
+			return info, true
+		}
+	}
+	return t.tasks[t.order[len(t.order)-1]], true
+}
+
 // RemoveTask removes Task information after it's been used
 func (t *TaskTracker) RemoveTask(toolUseID string) {
 	t.mu.Lock()
 	defer t.mu.Unlock()
 	delete(t.tasks, toolUseID)
+	for i, id := range t.order {
+		if id == toolUseID {
+			t.order = append(t.order[:i], t.order[i+1:]...)
+			break
+		}
+	}
 }
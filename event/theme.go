@@ -0,0 +1,283 @@
+package event
+
+import (
+	"bytes"
+	"embed"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/kazegusuri/claude-companion/logger"
+	"github.com/mattn/go-runewidth"
+	"gopkg.in/yaml.v3"
+)
+
+// Templates of assistant message content items, rendered inside the assistant template
+const (
+	ThemeText     = "text"
+	ThemeThinking = "thinking"
+	ThemeToolUse  = "tool_use"
+	// ThemeAny is the template of events whose kind has no template of its own
+	ThemeAny = "*"
+)
+
+//go:embed themes/*.yaml
+var builtinThemes embed.FS
+
+// BuiltinThemes lists the themes shipped with the companion
+var BuiltinThemes = []string{"default", "minimal"}
+
+// Theme renders console output with Go templates per event kind, such as user,
+// assistant or tool_use, and may change the console colors. Kinds without a
+// template keep the built-in format.
+type Theme struct {
+	Name      string
+	templates map[string]*template.Template
+	colors    Palette // Console colors replacing those of DefaultPalette
+}
+
+// themeFile is the YAML format of a theme file
+type themeFile struct {
+	Name      string            `yaml:"name"`
+	Templates map[string]string `yaml:"templates"`
+	Colors    map[string]string `yaml:"colors"`
+}
+
+// ThemeEvent is the data of an event template
+type ThemeEvent struct {
+	Kind    string // Event kind, as accepted by --include-events
+	Time    time.Time
+	Project string
+	Session string
+	Default string // The built-in rendering of the event, with the item templates applied
+	Header  string // First line of Default
+	Body    string // Lines of Default after the header
+	Content string // Lines of an assistant message rendered from its content, without the file summary and token usage
+	Text    string // Text of a user message
+	Model   string // Model of an assistant message
+	Usage   Usage  // Token usage of an assistant message
+	Event   Event
+}
+
+// ThemeItem is the data of a text, thinking or tool_use template
+type ThemeItem struct {
+	Kind      string // ThemeText, ThemeThinking or ThemeToolUse
+	Default   string // The built-in rendering of the item
+	Text      string // Text or thinking, with code blocks replaced by placeholders
+	Narration string
+	Tool      string
+	ToolID    string
+	Target    string // File, command, pattern or URL the tool works on
+	Input     map[string]interface{}
+}
+
+// LoadTheme loads a built-in theme by name or a theme file by path
+func LoadTheme(nameOrPath string) (*Theme, error) {
+	var data []byte
+	var err error
+	if isBuiltinTheme(nameOrPath) {
+		data, err = builtinThemes.ReadFile("themes/" + nameOrPath + ".yaml")
+	} else {
+		data, err = os.ReadFile(nameOrPath)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read theme: %w", err)
+	}
+	return ParseTheme(data)
+}
+
+// isBuiltinTheme reports whether name is a theme shipped with the companion
+func isBuiltinTheme(name string) bool {
+	for _, builtin := range BuiltinThemes {
+		if builtin == name {
+			return true
+		}
+	}
+	return false
+}
+
+// ParseTheme parses a theme file
+func ParseTheme(data []byte) (*Theme, error) {
+	var file themeFile
+	if err := yaml.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("failed to parse theme: %w", err)
+	}
+	colors, err := parsePalette(file.Colors)
+	if err != nil {
+		return nil, err
+	}
+	theme := &Theme{Name: file.Name, templates: make(map[string]*template.Template), colors: colors}
+	for kind, text := range file.Templates {
+		if !isThemeKind(kind) {
+			return nil, fmt.Errorf("invalid theme template %q (must be one of %s)", kind, strings.Join(themeKinds(), ", "))
+		}
+		tmpl, err := template.New(kind).Funcs(themeFuncs).Parse(text)
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s template: %w", kind, err)
+		}
+		theme.templates[kind] = tmpl
+	}
+	return theme, nil
+}
+
+// themeKinds returns the names a theme may have templates for
+func themeKinds() []string {
+	return append(append([]string{}, EventKinds...), ThemeText, ThemeThinking, ThemeToolUse, ThemeAny)
+}
+
+// isThemeKind reports whether a theme may have a template named name
+func isThemeKind(name string) bool {
+	for _, kind := range themeKinds() {
+		if kind == name {
+			return true
+		}
+	}
+	return false
+}
+
+// Kinds returns the names of the templates of the theme, sorted
+func (t *Theme) Kinds() []string {
+	kinds := make([]string, 0, len(t.templates))
+	for kind := range t.templates {
+		kinds = append(kinds, kind)
+	}
+	sort.Strings(kinds)
+	return kinds
+}
+
+// render renders the template of kind with data. It returns the built-in rendering
+// if the theme has no such template or it fails.
+func (t *Theme) render(kind string, data any, builtin string) string {
+	if t == nil {
+		return builtin
+	}
+	tmpl, ok := t.templates[kind]
+	if !ok {
+		return builtin
+	}
+	var out bytes.Buffer
+	if err := tmpl.Execute(&out, data); err != nil {
+		logger.LogDebug("Theme template %s failed, using the built-in format: %v", kind, err)
+		return builtin
+	}
+	return out.String()
+}
+
+// renderEvent renders an event with the template of its kind, or with ThemeAny.
+// content is the part of builtin rendered from the content of an assistant message.
+func (t *Theme) renderEvent(event Event, builtin, content string) string {
+	if t == nil || builtin == "" {
+		return builtin
+	}
+	kind := KindOf(event)
+	if _, ok := t.templates[kind]; !ok {
+		kind = ThemeAny
+	}
+	data := ThemeEvent{Kind: KindOf(event), Default: builtin, Content: content, Event: event}
+	data.Header, data.Body, _ = strings.Cut(builtin, "\n")
+	if session := SessionOf(event); session != nil {
+		data.Project = session.Project
+		data.Session = session.Session
+	}
+	switch e := event.(type) {
+	case *NotificationEvent:
+		data.Time = notificationTime(e)
+	case *UserMessage:
+		data.Text = userText(e)
+	case *AssistantMessage:
+		data.Model = e.Message.Model
+		data.Usage = e.Message.Usage
+	}
+	if base := BaseOf(event); base != nil {
+		data.Time = base.Timestamp
+	}
+	return t.render(kind, data, builtin)
+}
+
+// userText returns the text a user typed, without tool results and attachments
+func userText(event *UserMessage) string {
+	switch content := event.Message.Content.(type) {
+	case string:
+		return strings.TrimSpace(content)
+	case []interface{}:
+		var texts []string
+		for _, item := range content {
+			if block, ok := item.(map[string]interface{}); ok && block["type"] == "text" {
+				if text, ok := block["text"].(string); ok {
+					texts = append(texts, strings.TrimSpace(text))
+				}
+			}
+		}
+		return strings.Join(texts, "\n")
+	}
+	return ""
+}
+
+// themeColors are the ANSI colors of the color template function
+var themeColors = map[string]string{
+	"red":     "31",
+	"green":   "32",
+	"yellow":  "33",
+	"blue":    "34",
+	"magenta": "35",
+	"cyan":    "36",
+	"gray":    "90",
+	"bold":    "1",
+}
+
+// themeFuncs are the helpers available to theme templates. Like sprig, the value
+// piped in is the last argument, as in {{.Text | truncate 80}}.
+var themeFuncs = template.FuncMap{
+	"upper":      strings.ToUpper,
+	"lower":      strings.ToLower,
+	"trim":       strings.TrimSpace,
+	"trimPrefix": func(prefix, s string) string { return strings.TrimPrefix(s, prefix) },
+	"trimSuffix": func(suffix, s string) string { return strings.TrimSuffix(s, suffix) },
+	"replace":    func(old, new, s string) string { return strings.ReplaceAll(s, old, new) },
+	"contains":   func(substr, s string) bool { return strings.Contains(s, substr) },
+	"hasPrefix":  func(prefix, s string) bool { return strings.HasPrefix(s, prefix) },
+	"hasSuffix":  func(suffix, s string) bool { return strings.HasSuffix(s, suffix) },
+	"repeat":     func(n int, s string) string { return strings.Repeat(s, max(n, 0)) },
+	"join":       func(sep string, items []string) string { return strings.Join(items, sep) },
+	"lines":      func(s string) []string { return strings.Split(strings.TrimSuffix(s, "\n"), "\n") },
+	"firstLine": func(s string) string {
+		line, _, _ := strings.Cut(s, "\n")
+		return line
+	},
+	"default": func(fallback string, s string) string {
+		if s == "" {
+			return fallback
+		}
+		return s
+	},
+	"truncate": func(n int, s string) string {
+		if runewidth.StringWidth(s) <= n {
+			return s
+		}
+		return runewidth.Truncate(s, n, "...")
+	},
+	"pad": func(n int, s string) string { return runewidth.FillRight(s, n) },
+	"indent": func(n int, s string) string {
+		prefix := strings.Repeat(" ", n)
+		lines := strings.SplitAfter(s, "\n")
+		for i, line := range lines {
+			if line != "" && line != "\n" {
+				lines[i] = prefix + line
+			}
+		}
+		return strings.Join(lines, "")
+	},
+	"date":  func(layout string, t time.Time) string { return t.Format(layout) },
+	"clock": func(t time.Time) string { return t.Format("15:04:05") },
+	"plain": accessibleText,
+	"color": func(name, s string) string {
+		code, ok := themeColors[name]
+		if !ok || s == "" {
+			return s
+		}
+		return "\x1b[" + code + "m" + s + "\x1b[0m"
+	},
+}
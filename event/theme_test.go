@@ -0,0 +1,111 @@
+package event
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/kazegusuri/claude-companion/narrator"
+)
+
+func themeTestEvents() []Event {
+	ts := time.Date(2025, 1, 1, 15, 4, 5, 0, time.UTC)
+	user := &UserMessage{BaseEvent: BaseEvent{TypeString: "user", Timestamp: ts}}
+	user.Message.Content = "Fix the failing test\nin the parser"
+	assistant := &AssistantMessage{BaseEvent: BaseEvent{TypeString: "assistant", Timestamp: ts}}
+	assistant.Message.Model = "claude-sonnet-4"
+	assistant.Message.Content = []AssistantContent{
+		{Type: "text", Text: "Let me look at it."},
+		{Type: "tool_use", ID: "toolu_1", Name: "Bash", Input: map[string]interface{}{"command": "go test ./..."}},
+	}
+	system := &SystemMessage{BaseEvent: BaseEvent{TypeString: "system", Timestamp: ts}, Content: "Compacting", Level: "info"}
+	return []Event{user, assistant, system}
+}
+
+func TestBuiltinThemes(t *testing.T) {
+	for _, name := range BuiltinThemes {
+		theme, err := LoadTheme(name)
+		if err != nil {
+			t.Fatalf("LoadTheme(%q) error = %v", name, err)
+		}
+		if theme.Name != name || len(theme.Kinds()) == 0 {
+			t.Errorf("LoadTheme(%q) = %q with templates %v", name, theme.Name, theme.Kinds())
+		}
+	}
+}
+
+func TestDefaultThemeMatchesBuiltinFormat(t *testing.T) {
+	theme, err := LoadTheme("default")
+	if err != nil {
+		t.Fatal(err)
+	}
+	builtin := NewFormatter(narrator.NewNoOpNarrator())
+	themed := NewFormatter(narrator.NewNoOpNarrator())
+	themed.SetTheme(theme)
+	for _, e := range themeTestEvents() {
+		want, _ := builtin.Format(e)
+		got, _ := themed.Format(e)
+		if got != want {
+			t.Errorf("Format(%s) = %q, want %q", KindOf(e), got, want)
+		}
+	}
+}
+
+func TestMinimalTheme(t *testing.T) {
+	theme, err := LoadTheme("minimal")
+	if err != nil {
+		t.Fatal(err)
+	}
+	f := NewFormatter(narrator.NewNoOpNarrator())
+	f.SetTheme(theme)
+
+	var got []string
+	for _, e := range themeTestEvents() {
+		out, err := f.Format(e)
+		if err != nil {
+			t.Fatalf("Format() error = %v", err)
+		}
+		got = append(got, out)
+	}
+	want := []string{
+		"15:04:05 > Fix the failing test\n",
+		"15:04:05 claude\n  Let me look at it.\n  - Bash go test ./...\n",
+	}
+	for i, w := range want {
+		if got[i] != w {
+			t.Errorf("Format(%s) = %q, want %q", KindOf(themeTestEvents()[i]), got[i], w)
+		}
+	}
+	if strings.Contains(got[2], "📣") || !strings.Contains(got[2], "Compacting") {
+		t.Errorf("Format(system) = %q, want the built-in format without emoji", got[2])
+	}
+}
+
+func TestParseTheme(t *testing.T) {
+	theme, err := ParseTheme([]byte("name: custom\ntemplates:\n  user: \"{{.Text | upper}} @ {{.Project | default \\\"?\\\"}}\\n\"\n  tool_use: \"{{.Missing}}\"\n"))
+	if err != nil {
+		t.Fatalf("ParseTheme() error = %v", err)
+	}
+	user := themeTestEvents()[0]
+	if got := theme.renderEvent(user, "builtin", ""); got != "FIX THE FAILING TEST\nIN THE PARSER @ ?\n" {
+		t.Errorf("renderEvent() = %q", got)
+	}
+	// A template that fails to execute falls back to the built-in format
+	if got := theme.render(ThemeToolUse, ThemeItem{}, "builtin"); got != "builtin" {
+		t.Errorf("render() = %q, want the built-in format", got)
+	}
+
+	for _, data := range []string{
+		"templates:\n  unknown: \"x\"\n",
+		"templates:\n  user: \"{{.Text\"\n",
+		"templates:\n  user: \"{{nosuchfunc .Text}}\"\n",
+		"templates: [\n",
+	} {
+		if _, err := ParseTheme([]byte(data)); err == nil {
+			t.Errorf("ParseTheme(%q) error = nil, want error", data)
+		}
+	}
+	if _, err := LoadTheme("no-such-theme.yaml"); err == nil {
+		t.Error("LoadTheme() of a missing file error = nil, want error")
+	}
+}
@@ -0,0 +1,102 @@
+package event
+
+import (
+	"fmt"
+	"sync"
+	"time"
+)
+
+// localTimestampLayouts are the layouts of timestamps logged without a time zone
+var localTimestampLayouts = []string{
+	"2006-01-02T15:04:05.999999999",
+	"2006-01-02 15:04:05.999999999",
+	"2006-01-02 15:04:05",
+}
+
+// ParseTimestamp parses a timestamp. Timestamps with a time zone (RFC 3339) are
+// converted to loc; timestamps without one are interpreted in loc.
+func ParseTimestamp(value string, loc *time.Location) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339Nano, value); err == nil {
+		return t.In(loc), nil
+	}
+	for _, layout := range localTimestampLayouts {
+		if t, err := time.ParseInLocation(layout, value, loc); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("unsupported timestamp format: %q", value)
+}
+
+// Stamp is the position of an event in the combined event stream
+type Stamp struct {
+	Seq       uint64    // Arrival order, starting at 1
+	Time      time.Time // Normalized time; never earlier than the previous event
+	Regressed bool      // The event's own timestamp was earlier than the previous event
+}
+
+// Before reports whether s is ordered before o
+func (s Stamp) Before(o Stamp) bool {
+	if !s.Time.Equal(o.Time) {
+		return s.Time.Before(o.Time)
+	}
+	return s.Seq < o.Seq
+}
+
+// Sequencer orders events from sources whose clocks and time zones disagree.
+// Timestamps are normalized to one location, and an event whose timestamp
+// regresses falls back to arrival order by taking the previous event's time.
+type Sequencer struct {
+	mu   sync.Mutex
+	loc  *time.Location
+	now  func() time.Time
+	seq  uint64
+	last time.Time
+}
+
+// NewSequencer creates a sequencer that normalizes timestamps to loc
+func NewSequencer(loc *time.Location) *Sequencer {
+	return &Sequencer{loc: loc, now: time.Now}
+}
+
+// Stamp assigns the next stamp to a timestamp; a zero timestamp is taken as the current time
+func (s *Sequencer) Stamp(t time.Time) Stamp {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if t.IsZero() {
+		t = s.now()
+	}
+	t = t.In(s.loc)
+
+	s.seq++
+	stamp := Stamp{Seq: s.seq, Time: t}
+	if t.Before(s.last) {
+		stamp.Time = s.last
+		stamp.Regressed = true
+	} else {
+		s.last = t
+	}
+	return stamp
+}
+
+// Apply stamps an event and rewrites its timestamp to the normalized time
+func (s *Sequencer) Apply(event Event) (Stamp, bool) {
+	ts := timestampOf(event)
+	if ts == nil {
+		return Stamp{}, false
+	}
+	stamp := s.Stamp(*ts)
+	*ts = stamp.Time
+	return stamp, true
+}
+
+// timestampOf returns a pointer to the timestamp of an event, or nil if it has none
+func timestampOf(event Event) *time.Time {
+	if e, ok := event.(*NotificationEvent); ok {
+		return &e.Timestamp
+	}
+	if base := BaseOf(event); base != nil {
+		return &base.Timestamp
+	}
+	return nil
+}
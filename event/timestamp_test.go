@@ -0,0 +1,143 @@
+package event
+
+import (
+	"sort"
+	"testing"
+	"time"
+)
+
+func TestParseTimestamp(t *testing.T) {
+	jst := time.FixedZone("JST", 9*60*60)
+	want := time.Date(2025, 1, 26, 19, 0, 0, 0, jst)
+
+	tests := []struct {
+		name    string
+		value   string
+		wantErr bool
+	}{
+		{name: "UTC", value: "2025-01-26T10:00:00Z"},
+		{name: "UTC with fraction", value: "2025-01-26T10:00:00.000Z"},
+		{name: "offset", value: "2025-01-26T19:00:00+09:00"},
+		{name: "local without zone", value: "2025-01-26T19:00:00"},
+		{name: "local with space", value: "2025-01-26 19:00:00"},
+		{name: "invalid", value: "yesterday", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseTimestamp(tt.value, jst)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseTimestamp(%q) error = %v, wantErr %v", tt.value, err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if !got.Equal(want) {
+				t.Errorf("ParseTimestamp(%q) = %v, want %v", tt.value, got, want)
+			}
+			if got.Location() != jst {
+				t.Errorf("ParseTimestamp(%q) location = %v, want %v", tt.value, got.Location(), jst)
+			}
+		})
+	}
+}
+
+func TestSequencer_MixedSources(t *testing.T) {
+	jst := time.FixedZone("JST", 9*60*60)
+	base := time.Date(2025, 1, 26, 10, 0, 0, 0, time.UTC)
+	now := base.Add(10 * time.Second)
+
+	type source struct {
+		name string
+		ts   time.Time
+	}
+	// Events in arrival order: transcript lines carry UTC timestamps, hook
+	// notifications carry local times, and one hook line has no time at all.
+	arrivals := []source{
+		{name: "transcript-1", ts: base},
+		{name: "hook-1", ts: base.Add(2 * time.Second).In(jst)},
+		{name: "transcript-2", ts: base.Add(1 * time.Second)}, // written before hook-1 but read after it
+		{name: "hook-2", ts: time.Time{}},                     // no time; uses the read time
+		{name: "transcript-3", ts: base.Add(20 * time.Second)},
+	}
+
+	tests := []struct {
+		name          string
+		wantTimes     []time.Time
+		wantRegressed []bool
+	}{
+		{
+			name: "normalized and monotonic",
+			wantTimes: []time.Time{
+				base,
+				base.Add(2 * time.Second),
+				base.Add(2 * time.Second),
+				now,
+				base.Add(20 * time.Second),
+			},
+			wantRegressed: []bool{false, false, true, false, false},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := NewSequencer(jst)
+			s.now = func() time.Time { return now }
+
+			stamps := make([]Stamp, len(arrivals))
+			for i, a := range arrivals {
+				stamps[i] = s.Stamp(a.ts)
+			}
+
+			for i, stamp := range stamps {
+				if stamp.Seq != uint64(i+1) {
+					t.Errorf("%s: Seq = %d, want %d", arrivals[i].name, stamp.Seq, i+1)
+				}
+				if !stamp.Time.Equal(tt.wantTimes[i]) {
+					t.Errorf("%s: Time = %v, want %v", arrivals[i].name, stamp.Time, tt.wantTimes[i])
+				}
+				if stamp.Time.Location() != jst {
+					t.Errorf("%s: location = %v, want %v", arrivals[i].name, stamp.Time.Location(), jst)
+				}
+				if stamp.Regressed != tt.wantRegressed[i] {
+					t.Errorf("%s: Regressed = %v, want %v", arrivals[i].name, stamp.Regressed, tt.wantRegressed[i])
+				}
+			}
+
+			// Sorting reversed stamps restores arrival order, even for equal times
+			sorted := make([]Stamp, 0, len(stamps))
+			for i := len(stamps) - 1; i >= 0; i-- {
+				sorted = append(sorted, stamps[i])
+			}
+			sort.Slice(sorted, func(i, j int) bool { return sorted[i].Before(sorted[j]) })
+			for i, stamp := range sorted {
+				if stamp.Seq != uint64(i+1) {
+					t.Errorf("sorted[%d].Seq = %d, want %d", i, stamp.Seq, i+1)
+				}
+			}
+		})
+	}
+}
+
+func TestSequencer_Apply(t *testing.T) {
+	s := NewSequencer(time.UTC)
+	later := time.Date(2025, 1, 26, 10, 0, 5, 0, time.UTC)
+	earlier := later.Add(-3 * time.Second)
+
+	notification := &NotificationEvent{Timestamp: later}
+	user := &UserMessage{BaseEvent: BaseEvent{Timestamp: earlier}}
+
+	if _, ok := s.Apply(notification); !ok {
+		t.Fatal("expected NotificationEvent to be stamped")
+	}
+	stamp, ok := s.Apply(user)
+	if !ok || !stamp.Regressed {
+		t.Fatalf("expected regressed stamp, got %+v (ok=%v)", stamp, ok)
+	}
+	if !user.Timestamp.Equal(later) {
+		t.Errorf("UserMessage timestamp = %v, want %v", user.Timestamp, later)
+	}
+	if _, ok := s.Apply(&SummaryEvent{}); ok {
+		t.Error("SummaryEvent has no timestamp and should not be stamped")
+	}
+}
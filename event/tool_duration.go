@@ -0,0 +1,55 @@
+package event
+
+import (
+	"sync"
+	"time"
+)
+
+// maxPendingToolAge is how long a PreToolUse waits for its PostToolUse. Tool uses that
+// were denied or interrupted never get one.
+const maxPendingToolAge = time.Hour
+
+// ToolDurationTracker pairs PreToolUse and PostToolUse hook events by tool_use_id and
+// measures how long each tool ran, from the hook timestamps
+type ToolDurationTracker struct {
+	pending map[string]time.Time // key: tool_use_id
+	mu      sync.Mutex
+}
+
+// NewToolDurationTracker creates a new ToolDurationTracker
+func NewToolDurationTracker() *ToolDurationTracker {
+	return &ToolDurationTracker{
+		pending: make(map[string]time.Time),
+	}
+}
+
+// Start records the start of the tool use of a PreToolUse event
+func (t *ToolDurationTracker) Start(e *NotificationEvent) {
+	if e.ToolUseID == "" || e.Timestamp.IsZero() {
+		return
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	for id, start := range t.pending {
+		if e.Timestamp.Sub(start) > maxPendingToolAge {
+			delete(t.pending, id)
+		}
+	}
+	t.pending[e.ToolUseID] = e.Timestamp
+}
+
+// Finish returns how long the tool of a PostToolUse event ran, or false if its
+// PreToolUse was not seen
+func (t *ToolDurationTracker) Finish(e *NotificationEvent) (time.Duration, bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	start, ok := t.pending[e.ToolUseID]
+	if !ok {
+		return 0, false
+	}
+	delete(t.pending, e.ToolUseID)
+	if e.Timestamp.Before(start) {
+		return 0, true
+	}
+	return e.Timestamp.Sub(start), true
+}
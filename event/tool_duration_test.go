@@ -0,0 +1,66 @@
+package event
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+// toolHook creates a PreToolUse or PostToolUse hook event at the given time
+func toolHook(hook, id, name string, at time.Time) *NotificationEvent {
+	return &NotificationEvent{HookEventName: hook, ToolUseID: id, ToolName: name, Timestamp: at}
+}
+
+func TestToolDurationTracker(t *testing.T) {
+	start := time.Date(2025, 1, 1, 10, 0, 0, 0, time.UTC)
+	tracker := NewToolDurationTracker()
+
+	tracker.Start(toolHook("PreToolUse", "toolu_1", "Bash", start))
+	tracker.Start(toolHook("PreToolUse", "stale", "Bash", start))
+	// Starting a tool use much later drops the ones that never finished
+	tracker.Start(toolHook("PreToolUse", "toolu_2", "Read", start.Add(2*time.Hour)))
+
+	if elapsed, ok := tracker.Finish(toolHook("PostToolUse", "toolu_2", "Read", start.Add(2*time.Hour+time.Second))); !ok || elapsed != time.Second {
+		t.Errorf("Finish(toolu_2) = %v, %v; want 1s, true", elapsed, ok)
+	}
+	if _, ok := tracker.Finish(toolHook("PostToolUse", "stale", "Bash", start.Add(2*time.Hour))); ok {
+		t.Errorf("Finish() paired a tool use that was dropped")
+	}
+	if _, ok := tracker.Finish(toolHook("PostToolUse", "unknown", "Bash", start)); ok {
+		t.Errorf("Finish() paired a tool use without PreToolUse")
+	}
+	// A tool use is paired only once
+	if _, ok := tracker.Finish(toolHook("PostToolUse", "toolu_2", "Read", start.Add(3*time.Hour))); ok {
+		t.Errorf("Finish() paired a tool use twice")
+	}
+}
+
+func TestHandler_ToolDuration(t *testing.T) {
+	handler := NewHandler(&mockNarrator{}, false)
+	sink := &recordingSink{}
+	handler.AddSink(sink)
+
+	start := time.Date(2025, 1, 1, 10, 0, 0, 0, time.UTC)
+	captureOutput(t, func() {
+		handler.processEvent(toolHook("PreToolUse", "slow", "Bash", start))
+		handler.processEvent(toolHook("PostToolUse", "slow", "Bash", start.Add(12*time.Second)))
+		handler.processEvent(toolHook("PreToolUse", "fast", "Read", start.Add(13*time.Second)))
+		handler.processEvent(toolHook("PostToolUse", "fast", "Read", start.Add(14*time.Second)))
+	})
+
+	if len(sink.events) != 4 {
+		t.Fatalf("sink received %d events, want 4", len(sink.events))
+	}
+	if got := sink.events[1].(*NotificationEvent).Elapsed; got != 12*time.Second {
+		t.Errorf("Elapsed = %v, want 12s", got)
+	}
+	for _, want := range []string{"PostToolUse: Bash (12s)", "Bashが12sで完了しました"} {
+		if !strings.Contains(sink.formatted[1], want) {
+			t.Errorf("formatted slow tool = %q, want it to contain %q", sink.formatted[1], want)
+		}
+	}
+	// Short tools are shown but not narrated
+	if got := sink.formatted[3]; !strings.Contains(got, "PostToolUse: Read (1s)") || strings.Contains(got, "💬") {
+		t.Errorf("formatted fast tool = %q", got)
+	}
+}
@@ -0,0 +1,102 @@
+package event
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ParseToolSLAs parses TOOL=DURATION values (e.g. Bash=120s) into the expected maximum duration per tool
+func ParseToolSLAs(values []string) (map[string]time.Duration, error) {
+	limits := make(map[string]time.Duration)
+	for _, value := range values {
+		tool, duration, ok := strings.Cut(value, "=")
+		if !ok || tool == "" {
+			return nil, fmt.Errorf("invalid tool SLA %q: must be TOOL=DURATION", value)
+		}
+		limit, err := time.ParseDuration(duration)
+		if err != nil || limit <= 0 {
+			return nil, fmt.Errorf("invalid tool SLA %q: duration must be positive, like 120s", value)
+		}
+		limits[tool] = limit
+	}
+	return limits, nil
+}
+
+// pendingTool is a tool use waiting for its result
+type pendingTool struct {
+	name  string
+	start time.Time
+}
+
+// ToolSLATracker pairs tool uses with their results and reports the tools that took
+// longer than their expected maximum duration. Durations are measured between the
+// transcript timestamps of the tool use and the tool result.
+type ToolSLATracker struct {
+	limits  map[string]time.Duration
+	pending map[string]pendingTool // key: tool_use_id
+	mu      sync.Mutex
+}
+
+// NewToolSLATracker creates a tracker for the tools in limits
+func NewToolSLATracker(limits map[string]time.Duration) *ToolSLATracker {
+	return &ToolSLATracker{
+		limits:  limits,
+		pending: make(map[string]pendingTool),
+	}
+}
+
+// Start records the tool uses of an assistant message that have an expected duration
+func (t *ToolSLATracker) Start(msg *AssistantMessage) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	for _, content := range msg.Message.Content {
+		if content.Type != "tool_use" || content.ID == "" {
+			continue
+		}
+		if _, ok := t.limits[content.Name]; ok {
+			t.pending[content.ID] = pendingTool{name: content.Name, start: msg.Timestamp}
+		}
+	}
+}
+
+// Finish pairs the tool results of a user message with their tool uses and returns
+// a breach for each tool that took longer than expected
+func (t *ToolSLATracker) Finish(msg *UserMessage) []*ToolSLABreachMessage {
+	items, ok := msg.Message.Content.([]interface{})
+	if !ok {
+		return nil
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	var breaches []*ToolSLABreachMessage
+	for _, item := range items {
+		m, ok := item.(map[string]interface{})
+		if !ok || m["type"] != "tool_result" {
+			continue
+		}
+		toolUseID, _ := m["tool_use_id"].(string)
+		tool, ok := t.pending[toolUseID]
+		if !ok {
+			continue
+		}
+		delete(t.pending, toolUseID)
+
+		if tool.start.IsZero() || msg.Timestamp.IsZero() {
+			continue
+		}
+		limit := t.limits[tool.name]
+		if elapsed := msg.Timestamp.Sub(tool.start); elapsed > limit {
+			breaches = append(breaches, &ToolSLABreachMessage{
+				BaseEvent: msg.BaseEvent,
+				ToolName:  tool.name,
+				ToolUseID: toolUseID,
+				Elapsed:   elapsed,
+				Limit:     limit,
+			})
+		}
+	}
+	return breaches
+}
@@ -0,0 +1,116 @@
+package event
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestParseToolSLAs(t *testing.T) {
+	tests := []struct {
+		name    string
+		values  []string
+		want    map[string]time.Duration
+		wantErr bool
+	}{
+		{name: "empty", want: map[string]time.Duration{}},
+		{name: "tools", values: []string{"Bash=120s", "WebFetch=30s", "mcp__github__create_pr=1m"}, want: map[string]time.Duration{"Bash": 120 * time.Second, "WebFetch": 30 * time.Second, "mcp__github__create_pr": time.Minute}},
+		{name: "missing duration", values: []string{"Bash"}, wantErr: true},
+		{name: "missing tool", values: []string{"=30s"}, wantErr: true},
+		{name: "invalid duration", values: []string{"Bash=120"}, wantErr: true},
+		{name: "zero duration", values: []string{"Bash=0s"}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseToolSLAs(tt.values)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseToolSLAs() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if diff := cmp.Diff(tt.want, got); !tt.wantErr && diff != "" {
+				t.Errorf("ParseToolSLAs() mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+// toolResult creates a user message with a tool result at the given time
+func toolResult(id string, at time.Time) *UserMessage {
+	return &UserMessage{
+		BaseEvent: BaseEvent{TypeString: "user", Timestamp: at},
+		Message: UserMessageContent{
+			Role:    "user",
+			Content: []interface{}{map[string]interface{}{"type": "tool_result", "tool_use_id": id}},
+		},
+	}
+}
+
+func TestToolSLATracker(t *testing.T) {
+	start := time.Date(2025, 1, 1, 10, 0, 0, 0, time.UTC)
+	tracker := NewToolSLATracker(map[string]time.Duration{"Bash": 2 * time.Minute, "WebFetch": 30 * time.Second})
+
+	tracker.Start(assistantMessage("s1", start, toolUseContent("slow", "Bash", nil)))
+	tracker.Start(assistantMessage("s1", start, toolUseContent("fast", "WebFetch", nil)))
+	tracker.Start(assistantMessage("s1", start, toolUseContent("untracked", "Read", nil)))
+
+	if got := tracker.Finish(toolResult("fast", start.Add(10*time.Second))); len(got) != 0 {
+		t.Errorf("Finish() of a fast tool = %d breaches, want 0", len(got))
+	}
+	if got := tracker.Finish(toolResult("untracked", start.Add(time.Hour))); len(got) != 0 {
+		t.Errorf("Finish() of a tool without an SLA = %d breaches, want 0", len(got))
+	}
+
+	got := tracker.Finish(toolResult("slow", start.Add(150*time.Second)))
+	if len(got) != 1 {
+		t.Fatalf("Finish() of a slow tool = %d breaches, want 1", len(got))
+	}
+	if got[0].ToolName != "Bash" || got[0].ToolUseID != "slow" || got[0].Elapsed != 150*time.Second || got[0].Limit != 2*time.Minute {
+		t.Errorf("breach = %+v", got[0])
+	}
+
+	// A result is paired only once
+	if got := tracker.Finish(toolResult("slow", start.Add(200*time.Second))); len(got) != 0 {
+		t.Errorf("Finish() of a repeated result = %d breaches, want 0", len(got))
+	}
+}
+
+// recordingSink records the events dispatched by the handler
+type recordingSink struct {
+	events    []Event
+	formatted []string
+}
+
+func (s *recordingSink) HandleEvent(ev Event, formatted string) {
+	s.events = append(s.events, ev)
+	s.formatted = append(s.formatted, formatted)
+}
+
+func TestHandler_ToolSLABreach(t *testing.T) {
+	handler := NewHandler(&mockNarrator{}, false)
+	handler.SetToolSLAs(map[string]time.Duration{"Bash": 2 * time.Minute})
+	sink := &recordingSink{}
+	handler.AddSink(sink)
+
+	start := time.Date(2025, 1, 1, 10, 0, 0, 0, time.UTC)
+	captureOutput(t, func() {
+		handler.processEvent(assistantMessage("s1", start, toolUseContent("toolu_1", "Bash", nil)))
+		handler.processEvent(toolResult("toolu_1", start.Add(150*time.Second)))
+	})
+
+	if len(sink.events) != 3 {
+		t.Fatalf("sink received %d events, want tool use, breach and result", len(sink.events))
+	}
+	breach, ok := sink.events[1].(*ToolSLABreachMessage)
+	if !ok {
+		t.Fatalf("second event = %T, want *ToolSLABreachMessage", sink.events[1])
+	}
+	if breach.Priority == 0 {
+		t.Errorf("breach has no priority")
+	}
+	for _, want := range []string{"SLA BREACH: Bash took 2m30s (expected at most 2m0s", "Bashが2m30sかかりました"} {
+		if !strings.Contains(sink.formatted[1], want) {
+			t.Errorf("formatted breach = %q, want it to contain %q", sink.formatted[1], want)
+		}
+	}
+}
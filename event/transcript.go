@@ -0,0 +1,57 @@
+package event
+
+import (
+	"compress/gzip"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Transcript file extensions; archived transcripts may be compressed
+const (
+	TranscriptExt           = ".jsonl"
+	CompressedTranscriptExt = ".jsonl.gz"
+)
+
+// SessionName returns the session name of a transcript path, without its extension
+func SessionName(path string) string {
+	name := filepath.Base(path)
+	if strings.HasSuffix(name, CompressedTranscriptExt) {
+		return strings.TrimSuffix(name, CompressedTranscriptExt)
+	}
+	return strings.TrimSuffix(name, TranscriptExt)
+}
+
+// OpenTranscript opens a transcript for reading, decompressing it if it is gzipped
+func OpenTranscript(path string) (io.ReadCloser, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open file: %w", err)
+	}
+	if !strings.HasSuffix(path, ".gz") {
+		return file, nil
+	}
+	gz, err := gzip.NewReader(file)
+	if err != nil {
+		file.Close()
+		return nil, fmt.Errorf("failed to read compressed transcript: %w", err)
+	}
+	return &gzipTranscript{Reader: gz, file: file}, nil
+}
+
+// gzipTranscript is a compressed transcript being read
+type gzipTranscript struct {
+	*gzip.Reader
+	file *os.File
+}
+
+// Close closes the decompressor and the file
+func (t *gzipTranscript) Close() error {
+	err := t.Reader.Close()
+	if cerr := t.file.Close(); err == nil {
+		err = cerr
+	}
+	return err
+}
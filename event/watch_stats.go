@@ -0,0 +1,145 @@
+package event
+
+import (
+	"sort"
+	"sync"
+	"time"
+)
+
+// WatchStatus reports how the transcripts are watched and how far behind reading them is
+type WatchStatus struct {
+	Roots     []RootWatchStatus `json:"roots"`
+	Files     []FileWatchStatus `json:"files"`    // Most behind first
+	LagBytes  int64             `json:"lagBytes"` // Bytes written but not read yet, over all files
+	Throttled int64             `json:"throttled"`
+}
+
+// RootWatchStatus is how a projects root is watched
+type RootWatchStatus struct {
+	Path string `json:"path"`
+	Mode string `json:"mode"` // fsnotify or poll
+}
+
+// FileWatchStatus is how far a transcript has been read
+type FileWatchStatus struct {
+	Path      string    `json:"path"`
+	Offset    int64     `json:"offset"`
+	Size      int64     `json:"size"`
+	LagBytes  int64     `json:"lagBytes"`
+	Lines     int64     `json:"lines"`
+	Throttled int64     `json:"throttled"` // Lines delayed by the read rate limit
+	LastRead  time.Time `json:"lastRead"`
+	// How long after its timestamp the last event was read, in milliseconds
+	DelayMillis int64 `json:"delayMillis"`
+}
+
+// WatchStats collects the status of the watchers. A nil WatchStats ignores updates.
+type WatchStats struct {
+	mu    sync.Mutex
+	roots map[string]string // Projects root to watch mode
+	files map[string]*FileWatchStatus
+	now   func() time.Time
+}
+
+// NewWatchStats creates an empty watcher status
+func NewWatchStats() *WatchStats {
+	return &WatchStats{
+		roots: make(map[string]string),
+		files: make(map[string]*FileWatchStatus),
+		now:   time.Now,
+	}
+}
+
+// setRoot records the watch mode of a projects root
+func (s *WatchStats) setRoot(path, mode string) {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.roots[path] = mode
+}
+
+// file returns the status of a transcript, creating it; s.mu must be held
+func (s *WatchStats) file(path string) *FileWatchStatus {
+	f, ok := s.files[path]
+	if !ok {
+		f = &FileWatchStatus{Path: path}
+		s.files[path] = f
+	}
+	return f
+}
+
+// read records a line read from a transcript up to offset, holding an event written at timestamp
+func (s *WatchStats) read(path string, offset int64, timestamp time.Time) {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	f := s.file(path)
+	now := s.now()
+	f.Offset = offset
+	f.Size = max(f.Size, offset)
+	f.Lines++
+	f.LastRead = now
+	if !timestamp.IsZero() {
+		f.DelayMillis = max(now.Sub(timestamp).Milliseconds(), 0)
+	}
+}
+
+// setSize records the size of a transcript when reading it caught up or rewound
+func (s *WatchStats) setSize(path string, offset, size int64) {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	f := s.file(path)
+	f.Offset, f.Size = offset, size
+}
+
+// throttle counts a line delayed by the read rate limit
+func (s *WatchStats) throttle(path string) {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.file(path).Throttled++
+}
+
+// remove forgets a transcript that is no longer watched
+func (s *WatchStats) remove(path string) {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.files, path)
+}
+
+// Status returns the current status of the watchers
+func (s *WatchStats) Status() WatchStatus {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	status := WatchStatus{Roots: []RootWatchStatus{}, Files: make([]FileWatchStatus, 0, len(s.files))}
+	for path, mode := range s.roots {
+		status.Roots = append(status.Roots, RootWatchStatus{Path: path, Mode: mode})
+	}
+	for _, f := range s.files {
+		file := *f
+		file.LagBytes = max(file.Size-file.Offset, 0)
+		status.LagBytes += file.LagBytes
+		status.Throttled += file.Throttled
+		status.Files = append(status.Files, file)
+	}
+	sort.Slice(status.Roots, func(i, j int) bool { return status.Roots[i].Path < status.Roots[j].Path })
+	sort.Slice(status.Files, func(i, j int) bool {
+		if status.Files[i].LagBytes != status.Files[j].LagBytes {
+			return status.Files[i].LagBytes > status.Files[j].LagBytes
+		}
+		return status.Files[i].Path < status.Files[j].Path
+	})
+	return status
+}
@@ -0,0 +1,107 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/kazegusuri/claude-companion/event"
+	"github.com/kazegusuri/claude-companion/export"
+	"github.com/kazegusuri/claude-companion/logger"
+	"github.com/kazegusuri/claude-companion/narrator"
+	"github.com/spf13/pflag"
+)
+
+// runExport renders session transcripts as Markdown or HTML documents
+func runExport(args []string) int {
+	fs := pflag.NewFlagSet("export", pflag.ContinueOnError)
+	var projectsRoots []string
+	var project, session, file string
+	var formatName, output, outDir string
+	var narratorConfigPath, langCode string
+	fs.StringSliceVar(&projectsRoots, "projects-root", []string{"~/.claude/projects"}, "Root directory for projects (repeatable or comma-separated; LABEL=PATH is accepted)")
+	fs.StringVarP(&project, "project", "p", "", "Project name")
+	fs.StringVarP(&session, "session", "s", "", "Session name")
+	fs.StringVarP(&file, "file", "f", "", "Direct path to a session file")
+	fs.StringVar(&formatName, "format", "markdown", "Output format: markdown or html")
+	fs.StringVarP(&output, "output", "o", "", "Write a single session to this file instead of stdout")
+	fs.StringVar(&outDir, "out-dir", "", "Write one file per session under this directory")
+	fs.StringVar(&narratorConfigPath, "narrator-config", "", "Path to narrator configuration file (JSON, or YAML for .yaml/.yml)")
+	fs.StringVar(&langCode, "lang", "ja", "Narration language: ja or en")
+	if err := fs.Parse(args); err != nil {
+		if err == pflag.ErrHelp {
+			return 0
+		}
+		return 2
+	}
+
+	format, err := export.ParseFormat(formatName)
+	if err != nil {
+		logger.LogError("%v", err)
+		return 2
+	}
+	lang, err := narrator.ParseLanguage(langCode)
+	if err != nil {
+		logger.LogError("%v", err)
+		return 2
+	}
+	if file == "" && project == "" {
+		logger.LogError("export requires --file or --project")
+		return 2
+	}
+	paths, err := transcriptPaths(file, projectsRoots, project, session)
+	if err != nil {
+		logger.LogError("%v", err)
+		return 2
+	}
+	if len(paths) == 0 {
+		logger.LogError("No session transcripts found")
+		return 1
+	}
+	if len(paths) > 1 && outDir == "" {
+		logger.LogError("Found %d sessions; use --out-dir to write one file per session", len(paths))
+		return 2
+	}
+
+	// Narrate with rules only so exports are reproducible and need no API key
+	n := narrator.NewHybridNarratorWithLanguage("", false, &narratorConfigPath, lang)
+	for _, path := range paths {
+		transcript, err := export.BuildFile(path, event.NewFormatter(n))
+		if err != nil {
+			logger.LogError("Failed to read %s: %v", path, err)
+			return 1
+		}
+
+		dest := output
+		if outDir != "" {
+			dest = filepath.Join(outDir, transcript.Project, transcript.Session+format.Ext())
+		}
+		if err := writeExport(transcript, format, dest); err != nil {
+			logger.LogError("Failed to export %s: %v", path, err)
+			return 1
+		}
+		if dest != "" {
+			fmt.Fprintf(os.Stderr, "Exported %s to %s\n", path, dest)
+		}
+	}
+	return 0
+}
+
+// writeExport writes a rendered transcript to dest, or to stdout if dest is empty
+func writeExport(transcript *export.Transcript, format export.Format, dest string) error {
+	if dest == "" {
+		return transcript.Write(os.Stdout, format)
+	}
+	if err := os.MkdirAll(filepath.Dir(dest), 0o755); err != nil {
+		return fmt.Errorf("failed to create directory: %w", err)
+	}
+	out, err := os.Create(dest)
+	if err != nil {
+		return fmt.Errorf("failed to create file: %w", err)
+	}
+	if err := transcript.Write(out, format); err != nil {
+		out.Close()
+		return err
+	}
+	return out.Close()
+}
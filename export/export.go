@@ -0,0 +1,195 @@
+package export
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/kazegusuri/claude-companion/event"
+	"github.com/kazegusuri/claude-companion/usage"
+)
+
+// maxLineSize is the maximum size of a transcript line
+const maxLineSize = 10 * 1024 * 1024
+
+// Format is an output document format
+type Format string
+
+// Supported output formats
+const (
+	FormatMarkdown Format = "markdown"
+	FormatHTML     Format = "html"
+)
+
+// ParseFormat parses an output format name
+func ParseFormat(name string) (Format, error) {
+	switch strings.ToLower(name) {
+	case "markdown", "md":
+		return FormatMarkdown, nil
+	case "html":
+		return FormatHTML, nil
+	}
+	return "", fmt.Errorf("invalid export format: %s (must be markdown or html)", name)
+}
+
+// Ext returns the file extension of the format
+func (f Format) Ext() string {
+	if f == FormatHTML {
+		return ".html"
+	}
+	return ".md"
+}
+
+// Block is a piece of an entry body
+type Block struct {
+	Code     bool     // Lines are code rather than formatted text
+	Language string   // Language of a code block
+	Lines    []string // Lines without the console indentation
+}
+
+// ToolCall is a tool use of an assistant message
+type ToolCall struct {
+	ID    string
+	Name  string
+	Input string // Indented JSON
+}
+
+// Entry is a formatted event
+type Entry struct {
+	Header    string // First line of the formatted output, such as "[15:04:05] 👤 USER"
+	Blocks    []Block
+	ToolCalls []ToolCall
+	Usage     *event.Usage
+}
+
+// Transcript is a session rendered for export
+type Transcript struct {
+	Project string
+	Session string
+	Entries []Entry
+	Usage   *usage.SessionUsage
+}
+
+// codeBlockHeader matches the line the formatter puts before a code block preview
+var codeBlockHeader = regexp.MustCompile(`^📝 Code Block \d+ \((\w+)\):$`)
+
+// BuildFile reads a session transcript and formats its events with formatter
+func BuildFile(path string, formatter *event.Formatter) (*Transcript, error) {
+	file, err := event.OpenTranscript(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	return Build(file, path, formatter)
+}
+
+// Build formats the events of a transcript read from r; path names the project and session
+func Build(r io.Reader, path string, formatter *event.Formatter) (*Transcript, error) {
+	project := filepath.Base(filepath.Dir(path))
+	session := event.SessionName(path)
+	t := &Transcript{
+		Project: project,
+		Session: session,
+		Usage:   usage.NewSessionUsage(project, session),
+	}
+	t.Usage.Path = path
+
+	parser := event.NewParserWithPath(path)
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), maxLineSize)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+		ev, err := parser.Parse(line)
+		if err != nil {
+			continue // Broken lines are reported by fsck
+		}
+		formatted, err := formatter.Format(ev)
+		if err != nil || strings.TrimSpace(formatted) == "" {
+			continue
+		}
+
+		entry := parseFormatted(formatted)
+		if msg, ok := ev.(*event.AssistantMessage); ok {
+			entry.ToolCalls = toolCalls(msg)
+			if msg.Message.Usage.OutputTokens > 0 {
+				u := msg.Message.Usage
+				entry.Usage = &u
+			}
+			t.Usage.AddMessage(msg)
+		}
+		t.Entries = append(t.Entries, entry)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("error reading file: %w", err)
+	}
+	return t, nil
+}
+
+// parseFormatted splits console output into the header, text and code blocks.
+// Token usage lines are dropped since usage is rendered from the event.
+func parseFormatted(formatted string) Entry {
+	lines := strings.Split(strings.TrimRight(formatted, "\n"), "\n")
+	entry := Entry{Header: strings.TrimSuffix(strings.TrimSpace(lines[0]), ":")}
+
+	var text *Block
+	flush := func() {
+		if text != nil && len(text.Lines) > 0 {
+			entry.Blocks = append(entry.Blocks, *text)
+		}
+		text = nil
+	}
+	for i := 1; i < len(lines); i++ {
+		line := strings.TrimPrefix(lines[i], "  ")
+		trimmed := strings.TrimSpace(line)
+		switch {
+		case trimmed == "":
+			flush()
+		case strings.HasPrefix(trimmed, "💰 Tokens:"):
+		case codeBlockHeader.MatchString(trimmed) && i+1 < len(lines) && strings.TrimSpace(lines[i+1]) == "```":
+			flush()
+			code := Block{Code: true, Language: codeBlockHeader.FindStringSubmatch(trimmed)[1]}
+			for i += 2; i < len(lines) && strings.TrimSpace(lines[i]) != "```"; i++ {
+				code.Lines = append(code.Lines, strings.TrimPrefix(lines[i], "    "))
+			}
+			entry.Blocks = append(entry.Blocks, code)
+		default:
+			if text == nil {
+				text = &Block{}
+			}
+			text.Lines = append(text.Lines, line)
+		}
+	}
+	flush()
+	return entry
+}
+
+// toolCalls returns the tool uses of an assistant message
+func toolCalls(msg *event.AssistantMessage) []ToolCall {
+	var calls []ToolCall
+	for _, content := range msg.Message.Content {
+		if content.Type != "tool_use" {
+			continue
+		}
+		input, err := json.MarshalIndent(content.Input, "", "  ")
+		if err != nil {
+			input = []byte(fmt.Sprintf("%v", content.Input))
+		}
+		calls = append(calls, ToolCall{ID: content.ID, Name: content.Name, Input: string(input)})
+	}
+	return calls
+}
+
+// Write renders the transcript in format
+func (t *Transcript) Write(w io.Writer, format Format) error {
+	if format == FormatHTML {
+		return t.writeHTML(w)
+	}
+	return t.writeMarkdown(w)
+}
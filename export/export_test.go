@@ -0,0 +1,116 @@
+package export
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/kazegusuri/claude-companion/event"
+	"github.com/kazegusuri/claude-companion/narrator"
+)
+
+const transcript = `{"type":"user","uuid":"u1","timestamp":"2025-01-01T10:00:00Z","message":{"role":"user","content":"Fix the build"}}
+{"type":"assistant","uuid":"a1","timestamp":"2025-01-01T10:00:05Z","requestId":"r1","message":{"id":"m1","model":"claude-sonnet-4-20250514","content":[{"type":"text","text":"Here is the fix:\n` + "```go\\nfunc main() {}\\n```" + `\nDone."}],"usage":{"input_tokens":10,"output_tokens":20}}}
+{"type":"assistant","uuid":"a2","timestamp":"2025-01-01T10:00:06Z","requestId":"r1","message":{"id":"m1","model":"claude-sonnet-4-20250514","content":[{"type":"tool_use","id":"toolu_1","name":"Bash","input":{"command":"go build ./..."}}],"usage":{"input_tokens":10,"output_tokens":20}}}
+{broken
+`
+
+func TestParseFormat(t *testing.T) {
+	tests := []struct {
+		name    string
+		want    Format
+		wantErr bool
+	}{
+		{name: "markdown", want: FormatMarkdown},
+		{name: "md", want: FormatMarkdown},
+		{name: "HTML", want: FormatHTML},
+		{name: "pdf", wantErr: true},
+	}
+	for _, tt := range tests {
+		got, err := ParseFormat(tt.name)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("ParseFormat(%q) error = %v, wantErr %v", tt.name, err, tt.wantErr)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("ParseFormat(%q) = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestParseFormatted(t *testing.T) {
+	formatted := "[10:00:05] 🤖 ASSISTANT (claude-sonnet-4-20250514):\n" +
+		"  💬 Here is the fix\n" +
+		"  📝 Here is the fix:\n" +
+		"  Done.\n" +
+		"\n" +
+		"  📝 Code Block 1 (go):\n" +
+		"    ```\n" +
+		"    func main() {\n" +
+		"    \treturn\n" +
+		"    }\n" +
+		"    ```\n" +
+		"  💰 Tokens: input=10, output=20, cache_read=0, cache_creation=0\n"
+
+	want := Entry{
+		Header: "[10:00:05] 🤖 ASSISTANT (claude-sonnet-4-20250514)",
+		Blocks: []Block{
+			{Lines: []string{"💬 Here is the fix", "📝 Here is the fix:", "Done."}},
+			{Code: true, Language: "go", Lines: []string{"func main() {", "\treturn", "}"}},
+		},
+	}
+	if diff := cmp.Diff(want, parseFormatted(formatted)); diff != "" {
+		t.Errorf("parseFormatted() mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestBuild(t *testing.T) {
+	tr, err := Build(strings.NewReader(transcript), "/projects/proj/sess1.jsonl", event.NewFormatter(narrator.NewNoOpNarrator()))
+	if err != nil {
+		t.Fatalf("Build() error = %v", err)
+	}
+	if tr.Project != "proj" || tr.Session != "sess1" {
+		t.Errorf("Build() project, session = %q, %q", tr.Project, tr.Session)
+	}
+	if len(tr.Entries) != 3 {
+		t.Fatalf("Build() entries = %d, want 3", len(tr.Entries))
+	}
+	wantCalls := []ToolCall{{ID: "toolu_1", Name: "Bash", Input: "{\n  \"command\": \"go build ./...\"\n}"}}
+	if diff := cmp.Diff(wantCalls, tr.Entries[2].ToolCalls); diff != "" {
+		t.Errorf("tool calls mismatch (-want +got):\n%s", diff)
+	}
+	// Lines of the same message are counted once
+	if got := tr.Usage.Models.Tokens().OutputTokens; got != 20 {
+		t.Errorf("output tokens = %d, want 20", got)
+	}
+
+	var md bytes.Buffer
+	if err := tr.Write(&md, FormatMarkdown); err != nil {
+		t.Fatalf("Write(markdown) error = %v", err)
+	}
+	for _, want := range []string{"# sess1\n", "### [10:00:00] 👤 USER\n", "```go\nfunc main() {}\n```", "<summary>🔧 Bash (toolu_1)</summary>", "## Token Usage", "| Total | 10 | 20 | 0 | 0 |"} {
+		if !strings.Contains(md.String(), want) {
+			t.Errorf("markdown does not contain %q:\n%s", want, md.String())
+		}
+	}
+
+	var html bytes.Buffer
+	if err := tr.Write(&html, FormatHTML); err != nil {
+		t.Fatalf("Write(html) error = %v", err)
+	}
+	for _, want := range []string{"<h1>sess1</h1>", `<pre><code class="language-go">func main() {}</code></pre>`, "&#34;command&#34;: &#34;go build ./...&#34;", "<td>Total</td>"} {
+		if !strings.Contains(html.String(), want) {
+			t.Errorf("html does not contain %q:\n%s", want, html.String())
+		}
+	}
+}
+
+func TestFence(t *testing.T) {
+	if got := fence([]string{"plain"}); got != "```" {
+		t.Errorf("fence() = %q, want ```", got)
+	}
+	if got := fence([]string{"````md", "x"}); got != "`````" {
+		t.Errorf("fence() = %q, want a longer fence", got)
+	}
+}
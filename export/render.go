@@ -0,0 +1,170 @@
+package export
+
+import (
+	"bufio"
+	"fmt"
+	"html/template"
+	"io"
+	"strings"
+
+	"github.com/kazegusuri/claude-companion/event"
+	"github.com/kazegusuri/claude-companion/usage"
+)
+
+// usageRow is a row of the token usage table
+type usageRow struct {
+	Model  string
+	Tokens usage.Tokens
+	Cost   float64
+}
+
+// usageRows returns a row per model followed by the total
+func (t *Transcript) usageRows() []usageRow {
+	var rows []usageRow
+	for _, model := range t.Usage.Models.Models() {
+		tokens := *t.Usage.Models[model]
+		rows = append(rows, usageRow{Model: model, Tokens: tokens, Cost: usage.EstimateCost(model, tokens)})
+	}
+	return append(rows, usageRow{Model: "Total", Tokens: t.Usage.Models.Tokens(), Cost: t.Usage.Cost()})
+}
+
+// usageLine summarizes the token usage of a message
+func usageLine(u *event.Usage) string {
+	return fmt.Sprintf("input=%d, output=%d, cache_read=%d, cache_creation=%d",
+		u.InputTokens, u.OutputTokens, u.CacheReadInputTokens, u.CacheCreationInputTokens)
+}
+
+// fence returns a code fence longer than any backtick run in the lines
+func fence(lines []string) string {
+	longest := 0
+	for _, line := range lines {
+		run := 0
+		for _, r := range line {
+			if r == '`' {
+				run++
+				longest = max(longest, run)
+			} else {
+				run = 0
+			}
+		}
+	}
+	return strings.Repeat("`", max(3, longest+1))
+}
+
+// writeMarkdown renders the transcript as a Markdown document
+func (t *Transcript) writeMarkdown(w io.Writer) error {
+	bw := bufio.NewWriter(w)
+	fmt.Fprintf(bw, "# %s\n\n", t.Session)
+	fmt.Fprintf(bw, "- Project: `%s`\n", t.Project)
+	if !t.Usage.FirstSeen.IsZero() {
+		fmt.Fprintf(bw, "- Period: %s – %s\n", t.Usage.FirstSeen.Format("2006-01-02 15:04:05"), t.Usage.LastSeen.Format("2006-01-02 15:04:05"))
+	}
+	fmt.Fprintf(bw, "- Events: %d\n", len(t.Entries))
+
+	for _, entry := range t.Entries {
+		fmt.Fprintf(bw, "\n### %s\n", entry.Header)
+		for _, block := range entry.Blocks {
+			if block.Code {
+				f := fence(block.Lines)
+				fmt.Fprintf(bw, "\n%s%s\n%s\n%s\n", f, block.Language, strings.Join(block.Lines, "\n"), f)
+				continue
+			}
+			// Two trailing spaces keep the console line breaks
+			fmt.Fprintf(bw, "\n%s\n", strings.Join(block.Lines, "  \n"))
+		}
+		for _, call := range entry.ToolCalls {
+			lines := strings.Split(call.Input, "\n")
+			f := fence(lines)
+			fmt.Fprintf(bw, "\n<details><summary>🔧 %s (%s)</summary>\n\n%sjson\n%s\n%s\n\n</details>\n", call.Name, call.ID, f, call.Input, f)
+		}
+		if entry.Usage != nil {
+			fmt.Fprintf(bw, "\n> 💰 Tokens: %s\n", usageLine(entry.Usage))
+		}
+	}
+
+	if len(t.Usage.Models) > 0 {
+		fmt.Fprintf(bw, "\n## Token Usage\n\n")
+		fmt.Fprintf(bw, "| Model | Input | Output | Cache Read | Cache Creation | Cost (USD) |\n")
+		fmt.Fprintf(bw, "|---|---:|---:|---:|---:|---:|\n")
+		for _, row := range t.usageRows() {
+			fmt.Fprintf(bw, "| %s | %d | %d | %d | %d | %.4f |\n", row.Model,
+				row.Tokens.InputTokens, row.Tokens.OutputTokens, row.Tokens.CacheReadInputTokens, row.Tokens.CacheCreationInputTokens, row.Cost)
+		}
+	}
+	return bw.Flush()
+}
+
+// htmlTemplate renders a transcript as a standalone HTML document
+var htmlTemplate = template.Must(template.New("transcript").Funcs(template.FuncMap{
+	"join":      strings.Join,
+	"usageLine": usageLine,
+}).Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>{{.T.Session}}</title>
+<style>
+body { font-family: sans-serif; max-width: 960px; margin: 2em auto; padding: 0 1em; line-height: 1.5; }
+section { border-top: 1px solid #ddd; padding: 0.5em 0; }
+h3 { font-size: 1em; margin: 0.5em 0; }
+p { white-space: pre-wrap; margin: 0.5em 0; }
+pre { background: #f6f8fa; padding: 0.75em; overflow-x: auto; }
+.usage { color: #666; font-size: 0.9em; }
+table { border-collapse: collapse; }
+th, td { border: 1px solid #ddd; padding: 0.25em 0.75em; }
+td.num { text-align: right; }
+</style>
+</head>
+<body>
+<h1>{{.T.Session}}</h1>
+<ul>
+<li>Project: <code>{{.T.Project}}</code></li>
+{{- if not .T.Usage.FirstSeen.IsZero}}
+<li>Period: {{.T.Usage.FirstSeen.Format "2006-01-02 15:04:05"}} – {{.T.Usage.LastSeen.Format "2006-01-02 15:04:05"}}</li>
+{{- end}}
+<li>Events: {{len .T.Entries}}</li>
+</ul>
+{{- range .T.Entries}}
+<section>
+<h3>{{.Header}}</h3>
+{{- range .Blocks}}
+{{- if .Code}}
+<pre><code class="language-{{.Language}}">{{join .Lines "\n"}}</code></pre>
+{{- else}}
+<p>{{join .Lines "\n"}}</p>
+{{- end}}
+{{- end}}
+{{- range .ToolCalls}}
+<details><summary>🔧 {{.Name}} ({{.ID}})</summary>
+<pre><code class="language-json">{{.Input}}</code></pre>
+</details>
+{{- end}}
+{{- if .Usage}}
+<p class="usage">💰 Tokens: {{usageLine .Usage}}</p>
+{{- end}}
+</section>
+{{- end}}
+{{- if .Rows}}
+<h2>Token Usage</h2>
+<table>
+<tr><th>Model</th><th>Input</th><th>Output</th><th>Cache Read</th><th>Cache Creation</th><th>Cost (USD)</th></tr>
+{{- range .Rows}}
+<tr><td>{{.Model}}</td><td class="num">{{.Tokens.InputTokens}}</td><td class="num">{{.Tokens.OutputTokens}}</td><td class="num">{{.Tokens.CacheReadInputTokens}}</td><td class="num">{{.Tokens.CacheCreationInputTokens}}</td><td class="num">{{printf "%.4f" .Cost}}</td></tr>
+{{- end}}
+</table>
+{{- end}}
+</body>
+</html>
+`))
+
+// writeHTML renders the transcript as an HTML document
+func (t *Transcript) writeHTML(w io.Writer) error {
+	var rows []usageRow
+	if len(t.Usage.Models) > 0 {
+		rows = t.usageRows()
+	}
+	return htmlTemplate.Execute(w, struct {
+		T    *Transcript
+		Rows []usageRow
+	}{T: t, Rows: rows})
+}
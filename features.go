@@ -0,0 +1,569 @@
+package main
+
+import (
+	"fmt"
+	"net"
+	"net/url"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/kazegusuri/claude-companion/event"
+	"github.com/kazegusuri/claude-companion/logger"
+	"github.com/kazegusuri/claude-companion/narrator"
+	"github.com/kazegusuri/claude-companion/notify"
+	"github.com/kazegusuri/claude-companion/server"
+	"github.com/kazegusuri/claude-companion/usage"
+)
+
+// Feature describes a subsystem and its effective configuration for this run
+type Feature struct {
+	Name    string
+	Enabled bool
+	Detail  string
+	Warning string
+}
+
+// reportFeatures prints the enabled subsystems and their effective configuration
+func reportFeatures(features []Feature) {
+	logger.LogInfo("Enabled features:")
+	for _, f := range features {
+		state := "disabled"
+		if f.Enabled {
+			state = "enabled"
+		}
+		if f.Detail != "" {
+			logger.LogInfo("  %-14s %s (%s)", f.Name+":", state, f.Detail)
+		} else {
+			logger.LogInfo("  %-14s %s", f.Name+":", state)
+		}
+		if f.Warning != "" {
+			logger.LogWarning("  %s: %s", f.Name, f.Warning)
+		}
+	}
+}
+
+// capabilities converts the feature report for the capabilities API of the server
+func capabilities(features []Feature) []server.Capability {
+	result := make([]server.Capability, len(features))
+	for i, f := range features {
+		result[i] = server.Capability{Name: f.Name, Enabled: f.Enabled, Detail: f.Detail, Warning: f.Warning}
+	}
+	return result
+}
+
+// featureOptions holds the effective command line configuration used to build the feature report
+type featureOptions struct {
+	useAINarrator      bool
+	useAIPriority      bool
+	aiProvider         string
+	ollamaURL          string
+	ollamaModel        string
+	aiProviders        []narrator.AIProviderConfig
+	openaiAPIKey       string
+	narratorConfigPath string
+	enableVoice        bool
+	voicevoxURL        string
+	audioPlayer        string
+	audioCacheDir      string
+	audioCacheSize     int
+	voiceSpeakerID     int
+	voiceSpeakerMap    *narrator.SpeakerMap
+	voiceMaxSeconds    float64
+	translationCache   string
+	translator         narrator.TranslationBackend
+	glossary           *narrator.Glossary
+	userDictionary     *narrator.UserDictionary
+	voiceKatakana      bool
+	voiceDedupWindow   time.Duration
+	voiceToolRate      int
+	voiceLookahead     int
+	voiceFocus         bool
+	voiceFocusIdle     time.Duration
+	narrationLog       string
+	notificationLog    string
+	projectsRoots      []projectsRoot
+	file               string
+	headMode           bool
+	project            string
+	session            string
+	debugMode          bool
+	logLevel           logger.Level
+	logFormat          string
+	logFile            string
+	dbFile             string
+	sessionStateFile   string
+	offsetStateFile    string
+	watchMode          string
+	pollInterval       time.Duration
+	readRate           int
+	tap                string
+	mirrorDir          string
+	mirrorFsync        string
+	attachmentDir      string
+	projectConfig      bool
+	metricsInterval    time.Duration
+	enableServer       bool
+	serverAddr         string
+	serverTLS          bool
+	serverTokens       []server.Token
+	serverRateLimit    float64
+	serverRateBurst    int
+	layout             string
+	language           narrator.Language
+	accessible         bool
+	theme              string
+	colored            bool
+	wrap               bool
+	showSidechains     bool
+	sessionSummary     time.Duration
+	costLimit          usage.Limit
+	costLimitCommand   string
+	budget             event.Budget
+	contextAlerts      []int
+	actions            string
+	actionRules        int
+	security           bool
+	securityRules      string
+	securityRuleCount  int
+	redact             bool
+	redactRules        string
+	redactRuleCount    int
+	redactEntropy      bool
+	contextLimit       int
+	costAuditLog       string
+	desktopNotify      bool
+	mqttBroker         string
+	mqttTopicPrefix    string
+	mqttQoS            int
+	toolSLAs           map[string]time.Duration
+	quietHours         *notify.QuietHours
+	includeEvents      []string
+	excludeEvents      []string
+	includeTools       []string
+	excludeTools       []string
+}
+
+// buildFeatures builds the feature report from the effective configuration
+func buildFeatures(opts featureOptions) []Feature {
+	var features []Feature
+
+	// Input sources
+	if opts.file != "" {
+		mode := "tail"
+		if opts.headMode {
+			mode = "head"
+		}
+		features = append(features, Feature{Name: "input", Enabled: true, Detail: fmt.Sprintf("file %s, mode=%s", opts.file, mode)})
+	} else {
+		input := Feature{Name: "input", Enabled: true}
+		var roots []string
+		for _, root := range opts.projectsRoots {
+			if root.label != root.path {
+				roots = append(roots, fmt.Sprintf("%s=%s", root.label, root.path))
+			} else {
+				roots = append(roots, root.path)
+			}
+			if path, err := usage.ExpandHome(root.path); err == nil {
+				if _, err := os.Stat(path); err != nil {
+					input.Warning = fmt.Sprintf("projects root %s is not accessible: %v", root.path, err)
+				}
+			}
+		}
+		input.Detail = "projects root " + strings.Join(roots, ", ")
+		features = append(features, input)
+
+		watch := fmt.Sprintf("mode=%s", opts.watchMode)
+		if opts.watchMode != event.WatchModeFSNotify {
+			watch += fmt.Sprintf(", poll interval=%s", opts.pollInterval)
+		}
+		features = append(features, Feature{Name: "watch", Enabled: true, Detail: watch})
+	}
+	if !opts.headMode {
+		rate := Feature{Name: "read-rate", Enabled: opts.readRate > 0}
+		if rate.Enabled {
+			rate.Detail = fmt.Sprintf("%d lines/s per file", opts.readRate)
+		}
+		features = append(features, rate)
+	}
+
+	// Filters
+	filter := Feature{Name: "filters", Enabled: opts.project != "" || opts.session != ""}
+	if filter.Enabled {
+		filter.Detail = fmt.Sprintf("project=%q, session=%q", opts.project, opts.session)
+		if opts.file != "" {
+			filter.Warning = "project/session filters are ignored when --file is used"
+		}
+	}
+	features = append(features, filter)
+
+	// Event filters
+	eventFilter := Feature{Name: "event-filter"}
+	for _, f := range []struct {
+		name   string
+		values []string
+	}{
+		{"events", opts.includeEvents},
+		{"not events", opts.excludeEvents},
+		{"tools", opts.includeTools},
+		{"not tools", opts.excludeTools},
+	} {
+		if len(f.values) > 0 {
+			eventFilter.Enabled = true
+			if eventFilter.Detail != "" {
+				eventFilter.Detail += "; "
+			}
+			eventFilter.Detail += f.name + " " + strings.Join(f.values, ",")
+		}
+	}
+	features = append(features, eventFilter)
+	features = append(features, Feature{Name: "sidechains", Enabled: opts.showSidechains})
+
+	summary := Feature{Name: "session-summary", Enabled: opts.sessionSummary > 0}
+	if summary.Enabled {
+		summary.Detail = fmt.Sprintf("idle %s", opts.sessionSummary)
+	}
+	features = append(features, summary)
+	features = append(features, Feature{Name: "tap", Enabled: opts.tap != "", Detail: opts.tap})
+	mirror := Feature{Name: "mirror", Enabled: opts.mirrorDir != ""}
+	if mirror.Enabled {
+		mirror.Detail = fmt.Sprintf("%s, fsync=%s", opts.mirrorDir, opts.mirrorFsync)
+	}
+	features = append(features, mirror)
+	features = append(features, Feature{Name: "attachments", Enabled: opts.attachmentDir != "", Detail: opts.attachmentDir})
+
+	// Notification log
+	notification := Feature{Name: "notification", Enabled: opts.notificationLog != "", Detail: opts.notificationLog}
+	if notification.Enabled {
+		if _, err := os.Stat(opts.notificationLog); err != nil {
+			notification.Warning = fmt.Sprintf("notification log is not readable yet (%v); waiting for it", err)
+		}
+	}
+	features = append(features, notification)
+
+	// Narrator
+	narratorConfig := Feature{Name: "narrator", Enabled: true, Detail: fmt.Sprintf("built-in rules, lang=%s", opts.language)}
+	if opts.narratorConfigPath != "" {
+		narratorConfig.Detail = fmt.Sprintf("rules from %s (hot-reload), lang=%s", opts.narratorConfigPath, opts.language)
+	}
+	features = append(features, narratorConfig)
+
+	ai := Feature{Name: "ai-narrator", Enabled: opts.useAINarrator}
+	if ai.Enabled {
+		backend := "OpenAI"
+		ai.Detail = fmt.Sprintf("OpenAI, model=%s", narrator.OpenAINarratorModel())
+		switch opts.aiProvider {
+		case narrator.ProviderAnthropic:
+			backend = "Anthropic"
+			ai.Detail = fmt.Sprintf("Anthropic, model=%s", narrator.AnthropicNarratorModel())
+		case narrator.ProviderOllama:
+			backend = "Ollama"
+			ai.Detail = fmt.Sprintf("Ollama %s, model=%s", opts.ollamaURL, opts.ollamaModel)
+		}
+		if len(opts.aiProviders) > 0 {
+			if chain, err := narrator.NewProviderChainFromConfig(opts.aiProviders, opts.openaiAPIKey); err != nil {
+				ai.Warning = fmt.Sprintf("invalid aiProviders, using %s only: %v", backend, err)
+			} else {
+				ai.Detail = "fallback chain " + chain.Name()
+			}
+		}
+	}
+	features = append(features, ai)
+
+	priority := Feature{Name: "priority", Enabled: true, Detail: "rules"}
+	if opts.useAIPriority {
+		priority.Detail = fmt.Sprintf("rules + OpenAI, model=%s", narrator.OpenAINarratorModel())
+	}
+	features = append(features, priority)
+
+	// Voice
+	voice := Feature{Name: "voice", Enabled: opts.enableVoice}
+	if voice.Enabled {
+		voice.Detail = fmt.Sprintf("VOICEVOX %s, speaker=%d", opts.voicevoxURL, opts.voiceSpeakerID)
+		if opts.audioPlayer != "" && opts.audioPlayer != "native" {
+			voice.Detail += ", player " + opts.audioPlayer
+		}
+		if opts.audioCacheDir != "" {
+			voice.Detail += fmt.Sprintf(", audio cache %s (%d MB)", opts.audioCacheDir, opts.audioCacheSize)
+		}
+		if rules := opts.voiceSpeakerMap.Rules(); len(rules) > 0 {
+			voice.Detail += fmt.Sprintf(", %d speaker mapping(s)", len(rules))
+		}
+		if opts.voiceMaxSeconds > 0 {
+			voice.Detail += fmt.Sprintf(", max %gs per text", opts.voiceMaxSeconds)
+		}
+		if opts.voiceKatakana {
+			voice.Detail += ", English words as katakana"
+		}
+		if n := opts.userDictionary.Len(); n > 0 {
+			voice.Detail += fmt.Sprintf(", %d user dictionary entries", n)
+		}
+		if opts.voiceDedupWindow > 0 {
+			voice.Detail += fmt.Sprintf(", repeats collapsed within %s", opts.voiceDedupWindow)
+		}
+		if opts.voiceToolRate > 0 {
+			voice.Detail += fmt.Sprintf(", max %d tool narrations/min", opts.voiceToolRate)
+		}
+		if opts.voiceLookahead > 0 {
+			voice.Detail += fmt.Sprintf(", %d synthesized ahead", opts.voiceLookahead)
+		}
+		if opts.voiceFocus && opts.voiceFocusIdle > 0 {
+			voice.Detail += fmt.Sprintf(", focused session only (switches after %s quiet)", opts.voiceFocusIdle)
+		} else if opts.voiceFocus {
+			voice.Detail += ", focused session only"
+		}
+		if opts.narrationLog != "" {
+			voice.Detail += ", narration log " + opts.narrationLog
+		}
+		if opts.language == narrator.LanguageEnglish {
+			voice.Warning = "VOICEVOX speaks Japanese; English narration may not be read well"
+		} else if !opts.useAINarrator && !opts.voiceKatakana {
+			voice.Warning = "English text may not be narrated well without --ai"
+		}
+	}
+	if !voice.Enabled && len(opts.voiceSpeakerMap.Rules()) > 0 {
+		voice.Warning = "--voice-speaker-map has no effect without --voice"
+	} else if !voice.Enabled && opts.narrationLog != "" {
+		voice.Warning = "--narration-log has no effect without --voice"
+	} else if !voice.Enabled && opts.voiceFocus {
+		voice.Warning = "--voice-focus has no effect without --voice"
+	} else if !voice.Enabled && opts.userDictionary != nil {
+		voice.Warning = "--user-dictionary has no effect without --voice"
+	}
+	features = append(features, voice)
+
+	// Translation of English narrations for voice
+	translation := Feature{Name: "translation", Enabled: opts.enableVoice}
+	if translation.Enabled {
+		translation.Detail = "rules"
+		if opts.translator != nil {
+			translation.Detail = opts.translator.Name() + " -> rules -> as is"
+			if opts.translationCache != "" {
+				translation.Detail += ", cache " + opts.translationCache
+			}
+		}
+		if n := opts.glossary.Len(); n > 0 {
+			translation.Detail += fmt.Sprintf(", %d glossary term(s)", n)
+		}
+	}
+	if !translation.Enabled && opts.glossary.Len() > 0 {
+		translation.Warning = "the glossary has no effect without --voice"
+	}
+	features = append(features, translation)
+
+	features = append(features, Feature{Name: "database", Enabled: opts.dbFile != "", Detail: opts.dbFile})
+	features = append(features, Feature{Name: "session-state", Enabled: opts.sessionStateFile != "", Detail: opts.sessionStateFile})
+	features = append(features, Feature{Name: "offset-state", Enabled: opts.offsetStateFile != "", Detail: opts.offsetStateFile})
+	features = append(features, Feature{Name: "project-config", Enabled: opts.projectConfig, Detail: event.ProjectConfigFile})
+
+	metrics := Feature{Name: "metrics-history", Enabled: opts.dbFile != "" && opts.metricsInterval > 0}
+	if metrics.Enabled {
+		metrics.Detail = fmt.Sprintf("snapshot every %s", opts.metricsInterval)
+	}
+	features = append(features, metrics)
+
+	srv := Feature{Name: "server", Enabled: opts.enableServer}
+	if srv.Enabled {
+		scheme := "http"
+		if opts.serverTLS {
+			scheme = "https"
+		}
+		srv.Detail = fmt.Sprintf("%s://%s", scheme, opts.serverAddr)
+		admins, viewers := 0, 0
+		for _, token := range opts.serverTokens {
+			if token.Role == server.RoleViewer {
+				viewers++
+			} else {
+				admins++
+			}
+		}
+		if len(opts.serverTokens) > 0 {
+			srv.Detail += fmt.Sprintf(", auth: %d admin, %d viewer token(s)", admins, viewers)
+		} else {
+			srv.Detail += ", loopback Host only"
+			if !isLoopbackAddr(opts.serverAddr) {
+				srv.Warning = "the server is reachable from the network without authentication; set --server-token"
+			}
+		}
+		// The admin API needs an admin token over HTTP; the control socket needs none
+		if admins > 0 {
+			srv.Detail += ", admin API"
+		} else {
+			srv.Detail += ", admin API only on --control-socket (needs an admin --server-token over HTTP)"
+		}
+		if opts.serverRateLimit > 0 {
+			srv.Detail += fmt.Sprintf(", rate limit %g/s (burst %d) per client", opts.serverRateLimit, opts.serverRateBurst)
+		} else {
+			srv.Detail += ", no rate limit"
+		}
+	}
+	features = append(features, srv)
+
+	layout := Feature{Name: "layout", Enabled: true, Detail: opts.layout}
+	if opts.layout == "two-column" {
+		if width := terminalWidth(); width < event.MinTwoColumnWidth {
+			layout.Warning = fmt.Sprintf("terminal is %d columns wide; details are shown below narration until it is at least %d", width, event.MinTwoColumnWidth)
+		}
+	}
+	features = append(features, layout)
+
+	features = append(features, Feature{Name: "accessible", Enabled: opts.accessible})
+	features = append(features, Feature{Name: "theme", Enabled: opts.theme != "", Detail: opts.theme})
+	features = append(features, Feature{Name: "color", Enabled: opts.colored})
+	features = append(features, Feature{Name: "wrap", Enabled: opts.wrap})
+
+	desktop := Feature{Name: "desktop-notify", Enabled: opts.desktopNotify}
+	if desktop.Enabled {
+		desktop.Detail = "permission requests, task completions, tool SLA breaches"
+		if err := notify.NewDesktopNotifier().Available(); err != nil {
+			desktop.Warning = fmt.Sprintf("desktop notifications may not be shown: %v", err)
+		}
+	}
+	features = append(features, desktop)
+
+	mqttFeature := Feature{Name: "mqtt", Enabled: opts.mqttBroker != ""}
+	if mqttFeature.Enabled {
+		broker := opts.mqttBroker
+		if u, err := url.Parse(broker); err == nil {
+			broker = u.Redacted() // Do not print the password
+		}
+		mqttFeature.Detail = fmt.Sprintf("%s, topics %s/PROJECT/SESSION/KIND, QoS %d", broker, strings.TrimSuffix(opts.mqttTopicPrefix, "/"), opts.mqttQoS)
+	}
+	features = append(features, mqttFeature)
+
+	quiet := Feature{Name: "quiet-hours", Enabled: opts.quietHours != nil}
+	if quiet.Enabled {
+		quiet.Detail = opts.quietHours.String() + ", mutes voice and desktop notifications"
+		if !opts.enableVoice && !opts.desktopNotify {
+			quiet.Warning = "--quiet-hours has no effect without --voice or --desktop-notify"
+		}
+	}
+	features = append(features, quiet)
+
+	sla := Feature{Name: "tool-sla", Enabled: len(opts.toolSLAs) > 0}
+	if sla.Enabled {
+		tools := make([]string, 0, len(opts.toolSLAs))
+		for tool := range opts.toolSLAs {
+			tools = append(tools, tool)
+		}
+		sort.Strings(tools)
+		for i, tool := range tools {
+			tools[i] = fmt.Sprintf("%s=%s", tool, opts.toolSLAs[tool])
+		}
+		sla.Detail = strings.Join(tools, ", ")
+	}
+	features = append(features, sla)
+
+	guardrail := Feature{Name: "cost-guardrail", Enabled: opts.costLimit.Enabled()}
+	if guardrail.Enabled {
+		var limits []string
+		if opts.costLimit.Cost > 0 {
+			limits = append(limits, fmt.Sprintf("$%.2f", opts.costLimit.Cost))
+		}
+		if opts.costLimit.Tokens > 0 {
+			limits = append(limits, fmt.Sprintf("%d tokens", opts.costLimit.Tokens))
+		}
+		guardrail.Detail = "per session " + strings.Join(limits, " or ")
+		if opts.costLimitCommand != "" {
+			guardrail.Detail += ", runs command"
+		}
+		if opts.costAuditLog != "" {
+			guardrail.Detail += ", audit log " + opts.costAuditLog
+		}
+	} else if opts.costLimitCommand != "" || opts.costAuditLog != "" {
+		guardrail.Warning = "--cost-limit-command and --cost-audit-log need --max-session-cost or --max-session-tokens"
+	}
+	features = append(features, guardrail)
+
+	budget := Feature{Name: "budget", Enabled: opts.budget.Enabled()}
+	if budget.Enabled {
+		var limits []string
+		if opts.budget.SessionTokens > 0 {
+			limits = append(limits, fmt.Sprintf("%d tokens per session", opts.budget.SessionTokens))
+		}
+		if opts.budget.SessionCost > 0 {
+			limits = append(limits, fmt.Sprintf("$%.2f per session", opts.budget.SessionCost))
+		}
+		if opts.budget.DayTokens > 0 {
+			limits = append(limits, fmt.Sprintf("%d tokens per day", opts.budget.DayTokens))
+		}
+		if opts.budget.DayCost > 0 {
+			limits = append(limits, fmt.Sprintf("$%.2f per day", opts.budget.DayCost))
+		}
+		budget.Detail = strings.Join(limits, ", ") + ", alerts at 80% and 100%"
+	}
+	features = append(features, budget)
+
+	contextAlerts := Feature{Name: "context-alerts", Enabled: len(opts.contextAlerts) > 0}
+	if contextAlerts.Enabled {
+		percents := make([]string, len(opts.contextAlerts))
+		for i, threshold := range opts.contextAlerts {
+			percents[i] = fmt.Sprintf("%d%%", threshold)
+		}
+		contextAlerts.Detail = "at " + strings.Join(percents, ", ")
+		if opts.contextLimit > 0 {
+			contextAlerts.Detail += fmt.Sprintf(" of %d tokens", opts.contextLimit)
+		}
+	} else if opts.contextLimit > 0 {
+		contextAlerts.Warning = "--context-limit has no effect without --context-alerts"
+	}
+	features = append(features, contextAlerts)
+
+	actions := Feature{Name: "actions", Enabled: opts.actions != ""}
+	if actions.Enabled {
+		actions.Detail = fmt.Sprintf("%d rule(s) from %s", opts.actionRules, opts.actions)
+	}
+	features = append(features, actions)
+
+	security := Feature{Name: "security", Enabled: opts.security}
+	if security.Enabled {
+		security.Detail = fmt.Sprintf("%d rule(s), built-in", opts.securityRuleCount)
+		if opts.securityRules != "" {
+			security.Detail = fmt.Sprintf("%d rule(s), built-in and from %s", opts.securityRuleCount, opts.securityRules)
+		}
+	} else if opts.securityRules != "" {
+		security.Warning = "--security-rules has no effect with --security=false"
+	}
+	features = append(features, security)
+
+	redaction := Feature{Name: "redact", Enabled: opts.redact}
+	if redaction.Enabled {
+		redaction.Detail = fmt.Sprintf("%d rule(s), built-in", opts.redactRuleCount)
+		if opts.redactRules != "" {
+			redaction.Detail = fmt.Sprintf("%d rule(s), built-in and from %s", opts.redactRuleCount, opts.redactRules)
+		}
+		if opts.redactEntropy {
+			redaction.Detail += ", entropy heuristic"
+		}
+		if opts.tap != "" {
+			redaction.Warning = "--tap forwards raw transcript lines without redaction"
+		}
+	} else if opts.redactRules != "" {
+		redaction.Warning = "--redact-rules has no effect with --redact=false"
+	}
+	features = append(features, redaction)
+
+	features = append(features, Feature{Name: "debug", Enabled: opts.debugMode})
+
+	logging := Feature{Name: "logging", Enabled: true, Detail: fmt.Sprintf("level=%s, format=%s", opts.logLevel, opts.logFormat)}
+	if opts.logFile != "" {
+		logging.Detail += ", file " + opts.logFile
+	}
+	features = append(features, logging)
+
+	return features
+}
+
+// isLoopbackAddr reports whether a listen address only accepts local connections
+func isLoopbackAddr(addr string) bool {
+	host, _, err := net.SplitHostPort(addr)
+	if err != nil {
+		return false
+	}
+	if host == "localhost" {
+		return true
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
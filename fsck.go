@@ -0,0 +1,186 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/kazegusuri/claude-companion/event"
+	"github.com/kazegusuri/claude-companion/logger"
+	"github.com/kazegusuri/claude-companion/usage"
+	"github.com/spf13/pflag"
+)
+
+// fsckIssueKinds are the issue kinds in report order
+var fsckIssueKinds = []string{
+	event.IssueBrokenJSON,
+	event.IssueDuplicateUUID,
+	event.IssueOrphanedParent,
+	event.IssueTimestampRegression,
+}
+
+// runFsck checks session transcripts for structural problems.
+// It exits with 1 if any problem was found so it can guard exports and analytics.
+func runFsck(args []string) int {
+	fs := pflag.NewFlagSet("fsck", pflag.ContinueOnError)
+	var projectsRoots []string
+	var project, session, file string
+	var cleanDir string
+	var verbose, jsonOutput bool
+	fs.StringSliceVar(&projectsRoots, "projects-root", []string{"~/.claude/projects"}, "Root directory for projects (repeatable or comma-separated; LABEL=PATH is accepted)")
+	fs.StringVarP(&project, "project", "p", "", "Project name")
+	fs.StringVarP(&session, "session", "s", "", "Session name")
+	fs.StringVarP(&file, "file", "f", "", "Direct path to a session file")
+	fs.StringVar(&cleanDir, "clean-dir", "", "Write cleaned copies (without broken lines and duplicate UUIDs) under this directory")
+	fs.BoolVarP(&verbose, "verbose", "v", false, "List every issue instead of a summary per file")
+	fs.BoolVar(&jsonOutput, "json", false, "Print the reports as JSON")
+	if err := fs.Parse(args); err != nil {
+		if err == pflag.ErrHelp {
+			return 0
+		}
+		return 2
+	}
+
+	paths, err := transcriptPaths(file, projectsRoots, project, session)
+	if err != nil {
+		logger.LogError("%v", err)
+		return 2
+	}
+
+	var reports []*event.IntegrityReport
+	for _, path := range paths {
+		report, err := fsckFile(path, cleanDir)
+		if err != nil {
+			logger.LogError("Failed to check %s: %v", path, err)
+			return 1
+		}
+		reports = append(reports, report)
+	}
+
+	if jsonOutput {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(reports); err != nil {
+			logger.LogError("Failed to encode reports: %v", err)
+			return 1
+		}
+	} else {
+		printFsckReports(os.Stdout, reports, verbose)
+	}
+
+	for _, report := range reports {
+		if len(report.Issues) > 0 {
+			return 1
+		}
+	}
+	return 0
+}
+
+// transcriptPaths returns the direct file, or the sessions under the roots
+func transcriptPaths(file string, projectsRoots []string, project, session string) ([]string, error) {
+	if file != "" {
+		return []string{file}, nil
+	}
+
+	roots, err := parseProjectsRoots(projectsRoots)
+	if err != nil {
+		return nil, err
+	}
+	var paths []string
+	for _, root := range roots {
+		dir, err := usage.ExpandHome(root.path)
+		if err != nil {
+			return nil, err
+		}
+		files, err := filepath.Glob(filepath.Join(dir, "*", "*.jsonl"))
+		if err != nil {
+			return nil, err
+		}
+		for _, path := range files {
+			p := filepath.Base(filepath.Dir(path))
+			s := strings.TrimSuffix(filepath.Base(path), ".jsonl")
+			if (project == "" || p == project) && (session == "" || s == session) {
+				paths = append(paths, path)
+			}
+		}
+	}
+	return paths, nil
+}
+
+// fsckFile checks a transcript, writing a cleaned copy to cleanDir/<project>/<session>.jsonl if set
+func fsckFile(path, cleanDir string) (*event.IntegrityReport, error) {
+	in, err := event.OpenTranscript(path)
+	if err != nil {
+		return nil, err
+	}
+	defer in.Close()
+
+	var clean io.Writer
+	if cleanDir != "" {
+		outPath := filepath.Join(cleanDir, filepath.Base(filepath.Dir(path)), event.SessionName(path)+event.TranscriptExt)
+		if sameFile(outPath, path) {
+			return nil, fmt.Errorf("cleaned copy would overwrite the transcript; choose another --clean-dir")
+		}
+		if err := os.MkdirAll(filepath.Dir(outPath), 0o755); err != nil {
+			return nil, fmt.Errorf("failed to create directory: %w", err)
+		}
+		out, err := os.Create(outPath)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create cleaned copy: %w", err)
+		}
+		defer out.Close()
+		clean = out
+	}
+
+	report, err := event.CheckTranscript(in, clean)
+	if err != nil {
+		return nil, err
+	}
+	report.Path = path
+	return report, nil
+}
+
+// sameFile reports whether two paths refer to the same location
+func sameFile(a, b string) bool {
+	absA, errA := filepath.Abs(a)
+	absB, errB := filepath.Abs(b)
+	return errA == nil && errB == nil && absA == absB
+}
+
+// printFsckReports prints a summary line per transcript and the totals
+func printFsckReports(out io.Writer, reports []*event.IntegrityReport, verbose bool) {
+	totals := make(map[string]int)
+	lines, events, damaged := 0, 0, 0
+	for _, report := range reports {
+		lines += report.Lines
+		events += report.Events
+		if len(report.Issues) == 0 {
+			continue
+		}
+		damaged++
+
+		var counts []string
+		for _, kind := range fsckIssueKinds {
+			if n := report.Count(kind); n > 0 {
+				totals[kind] += n
+				counts = append(counts, fmt.Sprintf("%d %s", n, kind))
+			}
+		}
+		fmt.Fprintf(out, "%s: %s\n", report.Path, strings.Join(counts, ", "))
+		if verbose {
+			for _, issue := range report.Issues {
+				fmt.Fprintf(out, "  line %d: %s: %s\n", issue.Line, issue.Kind, issue.Detail)
+			}
+		}
+	}
+
+	fmt.Fprintf(out, "\nChecked %d transcript(s), %d lines, %d events: %d with issues\n", len(reports), lines, events, damaged)
+	for _, kind := range fsckIssueKinds {
+		if totals[kind] > 0 {
+			fmt.Fprintf(out, "  %-20s %d\n", kind, totals[kind])
+		}
+	}
+}
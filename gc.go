@@ -0,0 +1,118 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/kazegusuri/claude-companion/archive"
+	"github.com/kazegusuri/claude-companion/event"
+	"github.com/kazegusuri/claude-companion/logger"
+	"github.com/kazegusuri/claude-companion/usage"
+	"github.com/spf13/pflag"
+)
+
+// runGC archives sessions inactive for a number of days, moving their transcripts
+// out of the projects roots and recording them in the archive index
+func runGC(args []string) int {
+	fs := pflag.NewFlagSet("gc", pflag.ContinueOnError)
+	var projectsRoots []string
+	var project, session, archiveDir string
+	var days int
+	var compress, dryRun bool
+	fs.StringSliceVar(&projectsRoots, "projects-root", []string{"~/.claude/projects"}, "Root directory for projects (repeatable or comma-separated; LABEL=PATH is accepted)")
+	fs.StringVarP(&project, "project", "p", "", "Project name")
+	fs.StringVarP(&session, "session", "s", "", "Session name")
+	fs.IntVar(&days, "days", 30, "Archive sessions inactive for more than N days")
+	fs.StringVar(&archiveDir, "archive-dir", archive.DefaultDir, "Directory sessions are archived in")
+	fs.BoolVar(&compress, "compress", false, "Compress archived transcripts to .jsonl.gz")
+	fs.BoolVarP(&dryRun, "dry-run", "n", false, "List the sessions that would be archived without archiving them")
+	if err := fs.Parse(args); err != nil {
+		if err == pflag.ErrHelp {
+			return 0
+		}
+		return 2
+	}
+	if days <= 0 {
+		logger.LogError("--days must be positive")
+		return 2
+	}
+
+	dir, err := usage.ExpandHome(archiveDir)
+	if err != nil {
+		logger.LogError("%v", err)
+		return 2
+	}
+	paths, err := transcriptPaths("", projectsRoots, project, session)
+	if err != nil {
+		logger.LogError("%v", err)
+		return 2
+	}
+	index, err := archive.LoadIndex(dir)
+	if err != nil {
+		logger.LogError("%v", err)
+		return 1
+	}
+
+	now := time.Now()
+	cutoff := now.AddDate(0, 0, -days)
+	archived := 0
+	var before, after int64
+	for _, path := range paths {
+		info, err := os.Stat(path)
+		if err != nil || !info.ModTime().Before(cutoff) {
+			continue
+		}
+		if dryRun {
+			fmt.Printf("%s (last active %s)\n", path, info.ModTime().Local().Format("2006-01-02"))
+			archived++
+			before += info.Size()
+			continue
+		}
+		entry, err := archive.Archive(path, dir, compress, now)
+		if err != nil {
+			logger.LogError("Failed to archive %s: %v", path, err)
+			continue
+		}
+		index.Add(*entry)
+		// Save after each session so the index never misses a moved transcript
+		if err := index.Save(); err != nil {
+			logger.LogError("%v", err)
+			return 1
+		}
+		fmt.Printf("%s -> %s\n", path, entry.Path)
+		archived++
+		before += entry.Size
+		after += entry.ArchivedSize
+	}
+
+	switch {
+	case dryRun:
+		fmt.Fprintf(os.Stderr, "%d sessions (%s) would be archived to %s\n", archived, event.FormatBytes(before), dir)
+	case compress:
+		fmt.Fprintf(os.Stderr, "Archived %d sessions to %s: %s compressed to %s\n", archived, dir, event.FormatBytes(before), event.FormatBytes(after))
+	default:
+		fmt.Fprintf(os.Stderr, "Archived %d sessions (%s) to %s\n", archived, event.FormatBytes(before), dir)
+	}
+	return 0
+}
+
+// archivedPaths returns the archived transcripts in the index of dir matching the session filter
+func archivedPaths(archiveDir, session string) ([]string, error) {
+	dir, err := usage.ExpandHome(archiveDir)
+	if err != nil {
+		return nil, err
+	}
+	index, err := archive.LoadIndex(dir)
+	if err != nil {
+		return nil, err
+	}
+	var paths []string
+	for _, entry := range index.Entries() {
+		if session == "" || entry.Session == session {
+			paths = append(paths, filepath.Clean(entry.Path))
+		}
+	}
+	return paths, nil
+}
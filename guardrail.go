@@ -0,0 +1,147 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"os/exec"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/kazegusuri/claude-companion/logger"
+	"github.com/kazegusuri/claude-companion/narrator"
+	"github.com/kazegusuri/claude-companion/notify"
+	"github.com/kazegusuri/claude-companion/usage"
+)
+
+// costLimitCommandTimeout bounds how long the cost limit command may run
+const costLimitCommandTimeout = 30 * time.Second
+
+// costGuard escalates sessions that exceed the cost guardrail
+type costGuard struct {
+	lang      narrator.Language
+	voice     *narrator.VoiceNarrator // nil when voice is disabled
+	notifier  notify.Notifier         // nil without desktop notifications
+	command   string
+	auditPath string
+
+	auditMu sync.Mutex
+}
+
+// auditEntry is a line of the guardrail audit log
+type auditEntry struct {
+	Time        time.Time `json:"time"`
+	Action      string    `json:"action"`
+	Project     string    `json:"project"`
+	Session     string    `json:"session"`
+	Cost        float64   `json:"cost"`
+	Tokens      int64     `json:"tokens"`
+	LimitCost   float64   `json:"limitCost,omitempty"`
+	LimitTokens int64     `json:"limitTokens,omitempty"`
+	Command     string    `json:"command,omitempty"`
+	Output      string    `json:"output,omitempty"`
+	Error       string    `json:"error,omitempty"`
+}
+
+// pauseMessage is the message suggested to the session when it exceeds its limit
+func (g *costGuard) pauseMessage() string {
+	if g.lang == narrator.LanguageEnglish {
+		return "The usage limit for this session has been reached. Please summarize your progress and pause."
+	}
+	return "このセッションの使用量が上限に達しました。ここまでの作業を要約して一時停止してください。"
+}
+
+// announcement is the message spoken when a session exceeds its limit
+func (g *costGuard) announcement() string {
+	if g.lang == narrator.LanguageEnglish {
+		return "This session has reached its usage limit. Consider stopping it."
+	}
+	return "このセッションの使用量が上限に達しました。停止を検討してください"
+}
+
+// handle escalates an exceeded session
+func (g *costGuard) handle(alert usage.Alert) {
+	logger.LogWarning("Session %s/%s exceeded the cost guardrail: %s", alert.Project, alert.Session, alert.Reason())
+	if g.voice != nil {
+		g.voice.Announce(g.announcement())
+	}
+	if g.notifier != nil {
+		if err := g.notifier.Notify("Claude Companion: Cost limit", alert.Project+": "+g.announcement()); err != nil {
+			logger.LogError("Failed to send desktop notification: %v", err)
+		}
+	}
+	g.audit(newAuditEntry("alert", alert))
+
+	if g.command != "" {
+		// Run the command outside the event handler so slow commands do not delay output
+		go g.runCommand(alert)
+	}
+}
+
+// runCommand runs the cost limit command with the alert in its environment
+func (g *costGuard) runCommand(alert usage.Alert) {
+	ctx, cancel := context.WithTimeout(context.Background(), costLimitCommandTimeout)
+	defer cancel()
+
+	cmd := exec.CommandContext(ctx, "sh", "-c", g.command)
+	cmd.Env = append(os.Environ(),
+		"CLAUDE_COMPANION_PROJECT="+alert.Project,
+		"CLAUDE_COMPANION_SESSION="+alert.Session,
+		"CLAUDE_COMPANION_COST="+strconv.FormatFloat(alert.Cost, 'f', 4, 64),
+		"CLAUDE_COMPANION_TOKENS="+strconv.FormatInt(alert.Tokens, 10),
+		"CLAUDE_COMPANION_REASON="+alert.Reason(),
+		"CLAUDE_COMPANION_MESSAGE="+g.pauseMessage(),
+	)
+	output, err := cmd.CombinedOutput()
+
+	entry := newAuditEntry("command", alert)
+	entry.Command = g.command
+	entry.Output = string(output)
+	if err != nil {
+		entry.Error = err.Error()
+		logger.LogError("Cost limit command failed for session %s: %v", alert.Session, err)
+	} else {
+		logger.LogInfo("Cost limit command ran for session %s", alert.Session)
+	}
+	g.audit(entry)
+}
+
+// audit appends an entry to the audit log
+func (g *costGuard) audit(entry auditEntry) {
+	if g.auditPath == "" {
+		return
+	}
+
+	g.auditMu.Lock()
+	defer g.auditMu.Unlock()
+
+	data, err := json.Marshal(entry)
+	if err != nil {
+		logger.LogError("Error encoding audit entry: %v", err)
+		return
+	}
+	f, err := os.OpenFile(g.auditPath, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		logger.LogError("Error opening audit log: %v", err)
+		return
+	}
+	defer f.Close()
+	if _, err := f.Write(append(data, '\n')); err != nil {
+		logger.LogError("Error writing audit log: %v", err)
+	}
+}
+
+// newAuditEntry creates an audit entry for an alert
+func newAuditEntry(action string, alert usage.Alert) auditEntry {
+	return auditEntry{
+		Time:        time.Now(),
+		Action:      action,
+		Project:     alert.Project,
+		Session:     alert.Session,
+		Cost:        alert.Cost,
+		Tokens:      alert.Tokens,
+		LimitCost:   alert.Limit.Cost,
+		LimitTokens: alert.Limit.Tokens,
+	}
+}
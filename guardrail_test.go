@@ -0,0 +1,74 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/kazegusuri/claude-companion/narrator"
+	"github.com/kazegusuri/claude-companion/usage"
+)
+
+// fakeNotifier records the notifications it is asked to show and fails with err
+type fakeNotifier struct {
+	err    error
+	titles []string
+	bodies []string
+}
+
+func (n *fakeNotifier) Notify(title, body string) error {
+	n.titles = append(n.titles, title)
+	n.bodies = append(n.bodies, body)
+	return n.err
+}
+
+func TestCostGuard_Handle(t *testing.T) {
+	alert := usage.Alert{Project: "app", Session: "s1", Cost: 5.2, Tokens: 1200000, Limit: usage.Limit{Cost: 5}}
+
+	tests := []struct {
+		name     string
+		lang     narrator.Language
+		notifier *fakeNotifier // nil without desktop notifications
+		want     string
+	}{
+		{name: "without notifications"},
+		{name: "japanese", lang: narrator.LanguageJapanese, notifier: &fakeNotifier{}, want: "app: このセッションの使用量が上限に達しました。停止を検討してください"},
+		{name: "english", lang: narrator.LanguageEnglish, notifier: &fakeNotifier{}, want: "app: This session has reached its usage limit. Consider stopping it."},
+		{name: "notification fails", lang: narrator.LanguageEnglish, notifier: &fakeNotifier{err: errors.New("no notify-send")}, want: "app: This session has reached its usage limit. Consider stopping it."},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			auditPath := filepath.Join(t.TempDir(), "audit.jsonl")
+			guard := &costGuard{lang: tt.lang, auditPath: auditPath}
+			if tt.notifier != nil {
+				guard.notifier = tt.notifier
+			}
+			guard.handle(alert)
+
+			if tt.notifier != nil {
+				if diff := cmp.Diff([]string{"Claude Companion: Cost limit"}, tt.notifier.titles); diff != "" {
+					t.Errorf("titles mismatch (-want +got):\n%s", diff)
+				}
+				if diff := cmp.Diff([]string{tt.want}, tt.notifier.bodies); diff != "" {
+					t.Errorf("bodies mismatch (-want +got):\n%s", diff)
+				}
+			}
+
+			// The alert is audited whether or not the notification was shown
+			data, err := os.ReadFile(auditPath)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var entry auditEntry
+			if err := json.Unmarshal(data, &entry); err != nil {
+				t.Fatal(err)
+			}
+			if entry.Action != "alert" || entry.Session != "s1" || entry.LimitCost != 5 {
+				t.Errorf("audit entry = %+v", entry)
+			}
+		})
+	}
+}
@@ -0,0 +1,102 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/spf13/pflag"
+)
+
+// runHook forwards the payload of a Claude Code hook to a running companion.
+// It is meant to be the command of Claude Code hooks in place of the notification
+// script, so events arrive without going through the notification log. It always
+// exits with 0 and prints nothing to stdout, so it never blocks or alters Claude Code.
+func runHook(args []string) int {
+	fs := pflag.NewFlagSet("hook", pflag.ContinueOnError)
+	var serverAddr string
+	var serverCA string
+	var token string
+	var timeout time.Duration
+	var fallbackLog string
+	fs.StringVar(&serverAddr, "server-addr", "127.0.0.1:8765", "Address of the companion HTTP server, as host:port or an http:// or https:// URL")
+	fs.StringVar(&serverCA, "server-ca", "", "PEM CA certificate to verify the HTTPS companion server with, such as its --server-tls-cert; implies https")
+	fs.StringVar(&token, "token", os.Getenv("CLAUDE_COMPANION_TOKEN"), "Admin API token for the companion server (can also use CLAUDE_COMPANION_TOKEN env var)")
+	fs.DurationVar(&timeout, "timeout", time.Second, "Timeout for sending the payload")
+	fs.StringVar(&fallbackLog, "fallback-log", "", "Notification log to append the payload to when the companion cannot be reached")
+	if err := fs.Parse(args); err != nil {
+		if err == pflag.ErrHelp {
+			return 0
+		}
+		return 2
+	}
+
+	payload, err := io.ReadAll(os.Stdin)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "claude-companion hook: failed to read payload: %v\n", err)
+		return 0
+	}
+	payload = bytes.TrimSpace(payload)
+	if len(payload) == 0 {
+		return 0
+	}
+
+	client, baseURL, err := serverClient(serverAddr, serverCA)
+	if err == nil {
+		ctx, cancel := context.WithTimeout(context.Background(), timeout)
+		defer cancel()
+		err = sendHook(ctx, client, baseURL, token, payload)
+	}
+	if err == nil {
+		return 0
+	}
+	fmt.Fprintf(os.Stderr, "claude-companion hook: %v\n", err)
+	if fallbackLog != "" {
+		if err := appendLine(fallbackLog, payload); err != nil {
+			fmt.Fprintf(os.Stderr, "claude-companion hook: %v\n", err)
+		}
+	}
+	return 0
+}
+
+// sendHook posts a hook payload to the companion server
+func sendHook(ctx context.Context, client *http.Client, baseURL, token string, payload []byte) error {
+	req, err := http.NewRequestWithContext(ctx, "POST", baseURL+"/api/hooks", bytes.NewReader(payload))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to reach the companion server: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("companion rejected the hook: %s: %s", resp.Status, bytes.TrimSpace(body))
+	}
+	return nil
+}
+
+// appendLine appends a payload as one line to a notification log
+func appendLine(path string, payload []byte) error {
+	file, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+	if err != nil {
+		return fmt.Errorf("failed to open fallback log: %w", err)
+	}
+	defer file.Close()
+	// Payloads are single-line JSON from Claude Code, but keep the log line-based anyway
+	line := append(bytes.ReplaceAll(payload, []byte("\n"), []byte(" ")), '\n')
+	if _, err := file.Write(line); err != nil {
+		return fmt.Errorf("failed to write fallback log: %w", err)
+	}
+	return nil
+}
@@ -1,27 +1,174 @@
 package logger
 
 import (
+	"encoding/json"
 	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
 	"time"
 )
 
+// Level is the severity of a log message
+type Level int
+
+const (
+	LevelDebug Level = iota
+	LevelInfo
+	LevelWarning
+	LevelError
+)
+
+// String returns the name of the level as accepted by ParseLevel
+func (l Level) String() string {
+	switch l {
+	case LevelDebug:
+		return "debug"
+	case LevelInfo:
+		return "info"
+	case LevelWarning:
+		return "warn"
+	default:
+		return "error"
+	}
+}
+
+// ParseLevel parses a level name: debug, info, warn (or warning) or error
+func ParseLevel(name string) (Level, error) {
+	switch strings.ToLower(name) {
+	case "debug":
+		return LevelDebug, nil
+	case "info", "":
+		return LevelInfo, nil
+	case "warn", "warning":
+		return LevelWarning, nil
+	case "error":
+		return LevelError, nil
+	default:
+		return LevelInfo, fmt.Errorf("unknown log level %q (expected debug, info, warn or error)", name)
+	}
+}
+
+var (
+	mu         sync.Mutex
+	level      = LevelInfo
+	jsonFormat bool
+	console    io.Writer     = os.Stdout
+	file       *RotatingFile // nil without a log file
+	now        = time.Now
+)
+
+// accessible replaces emoji prefixes with text labels
+var accessible bool
+
+// SetAccessible enables or disables emoji-free log prefixes
+func SetAccessible(enabled bool) {
+	accessible = enabled
+}
+
+// SetLevel sets the lowest level of messages that are logged
+func SetLevel(l Level) {
+	mu.Lock()
+	defer mu.Unlock()
+	level = l
+}
+
+// SetJSON writes log messages as JSON lines with time, level and msg fields
+func SetJSON(enabled bool) {
+	mu.Lock()
+	defer mu.Unlock()
+	jsonFormat = enabled
+}
+
+// SetFile also writes log messages to a file, which is rotated once it grows past
+// maxSize bytes keeping maxBackups old files (path.1 is the newest). maxSize 0
+// disables rotation. An empty path stops writing to a file.
+func SetFile(path string, maxSize int64, maxBackups int) error {
+	var f *RotatingFile
+	if path != "" {
+		var err error
+		f, err = OpenRotatingFile(path, maxSize, maxBackups)
+		if err != nil {
+			return err
+		}
+	}
+	mu.Lock()
+	old := file
+	file = f
+	mu.Unlock()
+	if old != nil {
+		return old.Close()
+	}
+	return nil
+}
+
+// Close closes the log file, if any
+func Close() error {
+	return SetFile("", 0, 0)
+}
+
+// entry is a log message in JSON format
+type entry struct {
+	Time  time.Time `json:"time"`
+	Level string    `json:"level"`
+	Msg   string    `json:"msg"`
+}
+
+// log writes a message of a level to the console and the log file
+func log(l Level, emoji, label, message string, args ...any) {
+	mu.Lock()
+	defer mu.Unlock()
+	if l < level {
+		return
+	}
+	t := now()
+	formattedMessage := fmt.Sprintf(message, args...)
+
+	var jsonLine []byte
+	if jsonFormat {
+		jsonLine, _ = json.Marshal(entry{Time: t, Level: l.String(), Msg: formattedMessage})
+		jsonLine = append(jsonLine, '\n')
+		console.Write(jsonLine)
+	} else {
+		fmt.Fprintf(console, "[%s] %s %s\n", t.Format("15:04:05"), prefix(emoji, label), formattedMessage)
+	}
+
+	if file == nil {
+		return
+	}
+	if jsonLine == nil {
+		// The file outlives the day, so its lines carry the date
+		fmt.Fprintf(file, "%s [%s] %s\n", t.Format(time.RFC3339), label, formattedMessage)
+	} else {
+		file.Write(jsonLine)
+	}
+}
+
+// prefix returns the log prefix for a level
+func prefix(emoji, level string) string {
+	if accessible {
+		return fmt.Sprintf("[%s]", level)
+	}
+	return fmt.Sprintf("%s %s:", emoji, level)
+}
+
 // LogError logs an error message with consistent formatting
 func LogError(message string, args ...any) {
-	timestamp := time.Now().Format("15:04:05")
-	formattedMessage := fmt.Sprintf(message, args...)
-	fmt.Printf("[%s] ❌ ERROR: %s\n", timestamp, formattedMessage)
+	log(LevelError, "❌", "ERROR", message, args...)
 }
 
 // LogInfo logs an info message with consistent formatting
 func LogInfo(message string, args ...any) {
-	timestamp := time.Now().Format("15:04:05")
-	formattedMessage := fmt.Sprintf(message, args...)
-	fmt.Printf("[%s] ℹ️ INFO: %s\n", timestamp, formattedMessage)
+	log(LevelInfo, "ℹ️", "INFO", message, args...)
 }
 
 // LogWarning logs a warning message with consistent formatting
 func LogWarning(message string, args ...any) {
-	timestamp := time.Now().Format("15:04:05")
-	formattedMessage := fmt.Sprintf(message, args...)
-	fmt.Printf("[%s] ⚠️ WARNING: %s\n", timestamp, formattedMessage)
+	log(LevelWarning, "⚠️", "WARNING", message, args...)
+}
+
+// LogDebug logs a diagnostic message, shown only at the debug level
+func LogDebug(message string, args ...any) {
+	log(LevelDebug, "🐛", "DEBUG", message, args...)
 }
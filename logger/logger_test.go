@@ -0,0 +1,122 @@
+package logger
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// capture sends console output to a buffer and restores the settings after the test
+func capture(t *testing.T) *bytes.Buffer {
+	t.Helper()
+	var buf bytes.Buffer
+	oldConsole, oldNow := console, now
+	console = &buf
+	now = func() time.Time { return time.Date(2025, 1, 26, 10, 0, 0, 0, time.UTC) }
+	t.Cleanup(func() {
+		console, now = oldConsole, oldNow
+		SetLevel(LevelInfo)
+		SetJSON(false)
+		Close()
+	})
+	return &buf
+}
+
+func TestLevels(t *testing.T) {
+	buf := capture(t)
+	SetLevel(LevelWarning)
+	LogDebug("debug")
+	LogInfo("info")
+	LogWarning("warning %d", 1)
+	LogError("error")
+
+	got := buf.String()
+	if strings.Contains(got, "debug") || strings.Contains(got, "info") {
+		t.Errorf("messages below the level were logged:\n%s", got)
+	}
+	if !strings.Contains(got, "WARNING: warning 1") || !strings.Contains(got, "ERROR: error") {
+		t.Errorf("messages at or above the level are missing:\n%s", got)
+	}
+}
+
+func TestParseLevel(t *testing.T) {
+	tests := []struct {
+		name    string
+		want    Level
+		wantErr bool
+	}{
+		{"debug", LevelDebug, false},
+		{"INFO", LevelInfo, false},
+		{"warning", LevelWarning, false},
+		{"error", LevelError, false},
+		{"verbose", LevelInfo, true},
+	}
+	for _, tt := range tests {
+		got, err := ParseLevel(tt.name)
+		if (err != nil) != tt.wantErr || got != tt.want {
+			t.Errorf("ParseLevel(%q) = %v, %v; want %v, error %v", tt.name, got, err, tt.want, tt.wantErr)
+		}
+	}
+}
+
+func TestJSONFile(t *testing.T) {
+	buf := capture(t)
+	path := filepath.Join(t.TempDir(), "logs", "companion.log")
+	if err := SetFile(path, 0, 0); err != nil {
+		t.Fatal(err)
+	}
+	SetJSON(true)
+	LogInfo("hello %s", "world")
+	Close()
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var e entry
+	if err := json.Unmarshal(data, &e); err != nil {
+		t.Fatalf("log file line is not JSON: %q", data)
+	}
+	if e.Level != "info" || e.Msg != "hello world" || e.Time.IsZero() {
+		t.Errorf("entry = %+v", e)
+	}
+	if buf.String() != string(data) {
+		t.Errorf("console = %q, want the same line as the file %q", buf.String(), data)
+	}
+}
+
+func TestRotatingFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "companion.log")
+	r, err := OpenRotatingFile(path, 10, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, line := range []string{"first\n", "second\n", "third\n", "fourth\n"} {
+		if _, err := r.Write([]byte(line)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	r.Close()
+
+	want := map[string]string{
+		path:        "fourth\n",
+		path + ".1": "third\n",
+		path + ".2": "second\n", // "first" was rotated out
+	}
+	for p, content := range want {
+		data, err := os.ReadFile(p)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(data) != content {
+			t.Errorf("%s = %q, want %q", filepath.Base(p), data, content)
+		}
+	}
+	if _, err := os.Stat(path + ".3"); !os.IsNotExist(err) {
+		t.Errorf("%s.3 exists; only 2 backups should be kept", filepath.Base(path))
+	}
+}
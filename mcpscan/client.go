@@ -0,0 +1,193 @@
+package mcpscan
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+)
+
+// protocolVersion is the MCP protocol version requested from servers
+const protocolVersion = "2025-06-18"
+
+// maxToolPages bounds the pages of a paginated tool list
+const maxToolPages = 100
+
+// Tool is a tool of an MCP server
+type Tool struct {
+	Name        string      `json:"name"`
+	Description string      `json:"description,omitempty"`
+	InputSchema InputSchema `json:"inputSchema"`
+}
+
+// InputSchema is the JSON schema of the input of a tool
+type InputSchema struct {
+	Properties map[string]Property `json:"properties,omitempty"`
+	Required   []string            `json:"required,omitempty"`
+}
+
+// Property is a property of a tool input
+type Property struct {
+	Type        interface{} `json:"type,omitempty"` // A type name or a list of them
+	Description string      `json:"description,omitempty"`
+}
+
+// TypeName returns the type of the property, e.g. string or string|null
+func (p Property) TypeName() string {
+	switch t := p.Type.(type) {
+	case string:
+		return t
+	case []interface{}:
+		name := ""
+		for i, item := range t {
+			if i > 0 {
+				name += "|"
+			}
+			name += fmt.Sprint(item)
+		}
+		return name
+	}
+	return ""
+}
+
+// ListTools starts a stdio MCP server and asks it for its tools
+func ListTools(ctx context.Context, server *Server) ([]Tool, error) {
+	if !server.Stdio() {
+		return nil, fmt.Errorf("%s servers are not supported; use a tool manifest", server.Type)
+	}
+	cmd := exec.CommandContext(ctx, server.Command, server.Args...)
+	cmd.Env = os.Environ()
+	for key, value := range server.Env {
+		cmd.Env = append(cmd.Env, key+"="+value)
+	}
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		return nil, err
+	}
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return nil, err
+	}
+	if err := cmd.Start(); err != nil {
+		return nil, fmt.Errorf("failed to start %s: %w", server.Command, err)
+	}
+	defer func() {
+		stdin.Close()
+		cmd.Process.Kill()
+		cmd.Wait()
+	}()
+
+	// The server may never answer; the context kills it and unblocks the reads
+	type result struct {
+		tools []Tool
+		err   error
+	}
+	done := make(chan result, 1)
+	go func() {
+		tools, err := listTools(stdout, stdin)
+		done <- result{tools, err}
+	}()
+	select {
+	case r := <-done:
+		return r.tools, r.err
+	case <-ctx.Done():
+		return nil, fmt.Errorf("no tool list from %s: %w", server.Command, ctx.Err())
+	}
+}
+
+// rpcMessage is a JSON-RPC request, notification or response
+type rpcMessage struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      *int            `json:"id,omitempty"`
+	Method  string          `json:"method,omitempty"`
+	Params  interface{}     `json:"params,omitempty"`
+	Result  json.RawMessage `json:"result,omitempty"`
+	Error   *struct {
+		Code    int    `json:"code"`
+		Message string `json:"message"`
+	} `json:"error,omitempty"`
+}
+
+// rpcClient exchanges newline-delimited JSON-RPC messages with a server
+type rpcClient struct {
+	in     *bufio.Scanner
+	out    *json.Encoder
+	nextID int
+}
+
+// call sends a request and returns the result of its response, skipping the
+// notifications and requests the server sends meanwhile
+func (c *rpcClient) call(method string, params interface{}, result interface{}) error {
+	c.nextID++
+	id := c.nextID
+	if err := c.out.Encode(rpcMessage{JSONRPC: "2.0", ID: &id, Method: method, Params: params}); err != nil {
+		return fmt.Errorf("failed to send %s: %w", method, err)
+	}
+	for c.in.Scan() {
+		var msg rpcMessage
+		if err := json.Unmarshal(c.in.Bytes(), &msg); err != nil || msg.ID == nil || *msg.ID != id || msg.Method != "" {
+			continue // Logs on stdout, notifications and server requests
+		}
+		if msg.Error != nil {
+			return fmt.Errorf("%s failed: %s (%d)", method, msg.Error.Message, msg.Error.Code)
+		}
+		if err := json.Unmarshal(msg.Result, result); err != nil {
+			return fmt.Errorf("invalid %s result: %w", method, err)
+		}
+		return nil
+	}
+	if err := c.in.Err(); err != nil {
+		return fmt.Errorf("failed to read %s response: %w", method, err)
+	}
+	return fmt.Errorf("server exited before answering %s", method)
+}
+
+// notify sends a notification
+func (c *rpcClient) notify(method string) error {
+	return c.out.Encode(rpcMessage{JSONRPC: "2.0", Method: method})
+}
+
+// listTools initializes an MCP session over r and w and lists the tools, following pages
+func listTools(r io.Reader, w io.Writer) ([]Tool, error) {
+	in := bufio.NewScanner(r)
+	in.Buffer(make([]byte, 64*1024), 10*1024*1024)
+	c := &rpcClient{in: in, out: json.NewEncoder(w)}
+
+	var initialized json.RawMessage
+	err := c.call("initialize", map[string]interface{}{
+		"protocolVersion": protocolVersion,
+		"capabilities":    map[string]interface{}{},
+		"clientInfo":      map[string]string{"name": "claude-companion", "version": "mcp-scan"},
+	}, &initialized)
+	if err != nil {
+		return nil, err
+	}
+	if err := c.notify("notifications/initialized"); err != nil {
+		return nil, err
+	}
+
+	var tools []Tool
+	cursor := ""
+	for page := 0; page < maxToolPages; page++ {
+		params := map[string]string{}
+		if cursor != "" {
+			params["cursor"] = cursor
+		}
+		var result struct {
+			Tools      []Tool `json:"tools"`
+			NextCursor string `json:"nextCursor"`
+		}
+		if err := c.call("tools/list", params, &result); err != nil {
+			return nil, err
+		}
+		tools = append(tools, result.Tools...)
+		if result.NextCursor == "" {
+			break
+		}
+		cursor = result.NextCursor
+	}
+	return tools, nil
+}
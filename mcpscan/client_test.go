@@ -0,0 +1,75 @@
+package mcpscan
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"testing"
+)
+
+// fakeMCPServer answers initialize and pages of tools/list on r and w like a stdio
+// MCP server, logging and notifying in between
+func fakeMCPServer(t *testing.T, r io.Reader, w io.Writer) {
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		var req struct {
+			ID     *int   `json:"id"`
+			Method string `json:"method"`
+			Params struct {
+				Cursor string `json:"cursor"`
+			} `json:"params"`
+		}
+		if err := json.Unmarshal(scanner.Bytes(), &req); err != nil {
+			t.Errorf("invalid request %q: %v", scanner.Text(), err)
+			return
+		}
+		if req.ID == nil {
+			continue
+		}
+		fmt.Fprintln(w, "server starting...")
+		fmt.Fprintln(w, `{"jsonrpc":"2.0","method":"notifications/message","params":{"level":"info"}}`)
+		switch {
+		case req.Method == "initialize":
+			fmt.Fprintf(w, `{"jsonrpc":"2.0","id":%d,"result":{"protocolVersion":"2025-06-18","capabilities":{"tools":{}}}}`+"\n", *req.ID)
+		case req.Method == "tools/list" && req.Params.Cursor == "":
+			fmt.Fprintf(w, `{"jsonrpc":"2.0","id":%d,"result":{"tools":[{"name":"a","inputSchema":{}}],"nextCursor":"2"}}`+"\n", *req.ID)
+		case req.Method == "tools/list" && req.Params.Cursor == "2":
+			fmt.Fprintf(w, `{"jsonrpc":"2.0","id":%d,"result":{"tools":[{"name":"b","inputSchema":{}}]}}`+"\n", *req.ID)
+		default:
+			fmt.Fprintf(w, `{"jsonrpc":"2.0","id":%d,"error":{"code":-32601,"message":"unknown method"}}`+"\n", *req.ID)
+		}
+	}
+}
+
+func TestListTools(t *testing.T) {
+	requests, requestWriter := io.Pipe()
+	responseReader, responses := io.Pipe()
+	go func() {
+		fakeMCPServer(t, requests, responses)
+		responses.Close()
+	}()
+
+	tools, err := listTools(responseReader, requestWriter)
+	requestWriter.Close()
+	if err != nil {
+		t.Fatalf("listTools() error = %v", err)
+	}
+	if len(tools) != 2 || tools[0].Name != "a" || tools[1].Name != "b" {
+		t.Errorf("listTools() = %+v, want tools a and b", tools)
+	}
+}
+
+func TestListTools_ServerExits(t *testing.T) {
+	requests, requestWriter := io.Pipe()
+	responseReader, responses := io.Pipe()
+	go func() {
+		bufio.NewReader(requests).ReadString('\n')
+		responses.Close()
+		io.Copy(io.Discard, requests)
+	}()
+	if _, err := listTools(responseReader, requestWriter); err == nil {
+		t.Error("listTools() succeeded with a server that exited")
+	}
+	requestWriter.Close()
+}
@@ -0,0 +1,80 @@
+// Package mcpscan lists the tools of the MCP servers configured for Claude Code and
+// generates skeleton narration rules for them
+package mcpscan
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"regexp"
+	"sort"
+)
+
+// Server is an MCP server configured for Claude Code
+type Server struct {
+	Name    string            `json:"-"`
+	Type    string            `json:"type,omitempty"` // stdio (default), sse or http
+	Command string            `json:"command,omitempty"`
+	Args    []string          `json:"args,omitempty"`
+	Env     map[string]string `json:"env,omitempty"`
+	URL     string            `json:"url,omitempty"`
+}
+
+// Stdio reports whether the server is started as a local process
+func (s *Server) Stdio() bool {
+	return s.Type == "" || s.Type == "stdio"
+}
+
+// claudeConfig is the part of ~/.claude.json and .mcp.json listing MCP servers
+type claudeConfig struct {
+	MCPServers map[string]*Server `json:"mcpServers"`
+	Projects   map[string]struct {
+		MCPServers map[string]*Server `json:"mcpServers"`
+	} `json:"projects"`
+}
+
+// LoadServers reads the MCP servers of Claude Code config files: user and local
+// servers in ~/.claude.json, for the project in projectDir, and project servers in
+// .mcp.json files. Missing files are skipped, and later files override earlier ones.
+func LoadServers(projectDir string, paths ...string) ([]*Server, error) {
+	servers := make(map[string]*Server)
+	for _, path := range paths {
+		data, err := os.ReadFile(path)
+		if errors.Is(err, os.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read MCP config: %w", err)
+		}
+		var config claudeConfig
+		if err := json.Unmarshal(data, &config); err != nil {
+			return nil, fmt.Errorf("failed to parse MCP config %s: %w", path, err)
+		}
+		for name, server := range config.MCPServers {
+			servers[name] = server
+		}
+		for name, server := range config.Projects[projectDir].MCPServers {
+			servers[name] = server
+		}
+	}
+
+	list := make([]*Server, 0, len(servers))
+	for name, server := range servers {
+		if server == nil {
+			continue
+		}
+		server.Name = name
+		list = append(list, server)
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].Name < list[j].Name })
+	return list, nil
+}
+
+// invalidNameChars are the characters Claude Code replaces in the server name of MCP tool names
+var invalidNameChars = regexp.MustCompile(`[^a-zA-Z0-9_-]`)
+
+// ToolServerName returns the server name as it appears in tool names, mcp__<server>__<tool>
+func ToolServerName(name string) string {
+	return invalidNameChars.ReplaceAllString(name, "_")
+}
@@ -0,0 +1,59 @@
+package mcpscan
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestLoadServers(t *testing.T) {
+	dir := t.TempDir()
+	user := filepath.Join(dir, ".claude.json")
+	project := filepath.Join(dir, ".mcp.json")
+	writeFile(t, user, `{
+		"mcpServers": {"github": {"command": "github-mcp", "args": ["stdio"]}, "docs": {"type": "http", "url": "https://example.com/mcp"}},
+		"projects": {
+			"/work/app": {"mcpServers": {"db": {"command": "db-mcp", "env": {"DB": "app"}}}},
+			"/work/other": {"mcpServers": {"other": {"command": "other-mcp"}}}
+		}
+	}`)
+	writeFile(t, project, `{"mcpServers": {"github": {"command": "github-mcp-project"}}}`)
+
+	servers, err := LoadServers("/work/app", user, project, filepath.Join(dir, "missing.json"))
+	if err != nil {
+		t.Fatalf("LoadServers() error = %v", err)
+	}
+	var names []string
+	byName := make(map[string]*Server)
+	for _, server := range servers {
+		names = append(names, server.Name)
+		byName[server.Name] = server
+	}
+	if len(names) != 3 || names[0] != "db" || names[1] != "docs" || names[2] != "github" {
+		t.Fatalf("LoadServers() names = %v, want [db docs github]", names)
+	}
+	if byName["github"].Command != "github-mcp-project" {
+		t.Errorf("github command = %q, want the project config to override", byName["github"].Command)
+	}
+	if byName["db"].Env["DB"] != "app" || !byName["db"].Stdio() || byName["docs"].Stdio() {
+		t.Errorf("servers = %+v", servers)
+	}
+
+	writeFile(t, project, `{`)
+	if _, err := LoadServers("", project); err == nil {
+		t.Error("LoadServers() of a broken config succeeded")
+	}
+}
+
+func TestToolServerName(t *testing.T) {
+	if got := ToolServerName("my.server name"); got != "my_server_name" {
+		t.Errorf("ToolServerName() = %q", got)
+	}
+}
+
+func writeFile(t *testing.T, path, data string) {
+	t.Helper()
+	if err := os.WriteFile(path, []byte(data), 0o600); err != nil {
+		t.Fatal(err)
+	}
+}
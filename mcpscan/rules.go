@@ -0,0 +1,114 @@
+package mcpscan
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/kazegusuri/claude-companion/narrator"
+)
+
+// maxCaptures is how many input values a generated message mentions at most
+const maxCaptures = 2
+
+// longInputKeys are inputs too long to be read out, such as file contents
+var longInputKeys = []string{"body", "code", "content", "data", "diff", "new_string", "old_string", "patch", "text"}
+
+// ReadManifest reads a tool manifest: a JSON object mapping server names to the
+// result of their tools/list request, {"server": {"tools": [...]}}
+func ReadManifest(path string) (map[string][]Tool, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read tool manifest: %w", err)
+	}
+	var manifest map[string]struct {
+		Tools []Tool `json:"tools"`
+	}
+	if err := json.Unmarshal(data, &manifest); err != nil {
+		return nil, fmt.Errorf("failed to parse tool manifest %s: %w", path, err)
+	}
+	servers := make(map[string][]Tool, len(manifest))
+	for name, server := range manifest {
+		servers[name] = server.Tools
+	}
+	return servers, nil
+}
+
+// GenerateRules generates skeleton narration rules for the tools of an MCP server:
+// a message per tool mentioning up to two of its string inputs as capture placeholders.
+// The rules in existing are kept as they are, so the result can replace them.
+func GenerateRules(server string, tools []Tool, existing narrator.MCPRules, lang narrator.Language) narrator.MCPRules {
+	rules := narrator.MCPRules{
+		Default:   existing.Default,
+		Rules:     make(map[string]narrator.ToolRules, len(tools)),
+		Verbosity: existing.Verbosity,
+	}
+	if rules.Default == "" {
+		rules.Default = localize(lang, server+"の「{operation}」を実行します", "Running {operation} of "+server)
+	}
+	for operation, rule := range existing.Rules {
+		rules.Rules[operation] = rule
+	}
+	for _, tool := range tools {
+		if _, ok := existing.Rules[tool.Name]; ok {
+			continue
+		}
+		keys := captureKeys(tool.InputSchema)
+		placeholders := make([]string, len(keys))
+		var captures []narrator.CaptureRule
+		for i, key := range keys {
+			placeholders[i] = "{" + key + "}"
+			capture := narrator.CaptureRule{InputKey: key}
+			if isPathKey(key) {
+				capture.Type = "file"
+				capture.ParseFileType = true
+			}
+			captures = append(captures, capture)
+		}
+
+		message := localize(lang, tool.Name+"を実行します", "Running "+tool.Name)
+		if len(placeholders) > 0 {
+			message += localize(lang, "（"+strings.Join(placeholders, "、")+"）", " ("+strings.Join(placeholders, ", ")+")")
+		}
+		rules.Rules[tool.Name] = narrator.ToolRules{Default: message, Captures: captures}
+	}
+	return rules
+}
+
+// captureKeys picks the string inputs of a tool worth reading out, required ones first
+func captureKeys(schema InputSchema) []string {
+	var keys []string
+	for key, prop := range schema.Properties {
+		if strings.Contains(prop.TypeName(), "string") && !slices.Contains(longInputKeys, strings.ToLower(key)) {
+			keys = append(keys, key)
+		}
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		ri, rj := slices.Contains(schema.Required, keys[i]), slices.Contains(schema.Required, keys[j])
+		if ri != rj {
+			return ri
+		}
+		return keys[i] < keys[j]
+	})
+	if len(keys) > maxCaptures {
+		keys = keys[:maxCaptures]
+	}
+	return keys
+}
+
+// isPathKey reports whether an input holds a file path, read out as its base name
+func isPathKey(key string) bool {
+	key = strings.ToLower(key)
+	return strings.Contains(key, "path") || strings.Contains(key, "file")
+}
+
+// localize returns the English message for LanguageEnglish and the Japanese message otherwise
+func localize(lang narrator.Language, ja, en string) string {
+	if lang == narrator.LanguageEnglish {
+		return en
+	}
+	return ja
+}
@@ -0,0 +1,87 @@
+package mcpscan
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/kazegusuri/claude-companion/narrator"
+)
+
+func TestGenerateRules(t *testing.T) {
+	tools := []Tool{
+		{Name: "create_issue", InputSchema: InputSchema{
+			Properties: map[string]Property{
+				"body":  {Type: "string"},
+				"owner": {Type: "string"},
+				"repo":  {Type: "string"},
+				"title": {Type: "string"},
+				"draft": {Type: "boolean"},
+			},
+			Required: []string{"title", "repo"},
+		}},
+		{Name: "get_file", InputSchema: InputSchema{
+			Properties: map[string]Property{"file_path": {Type: []interface{}{"string", "null"}}},
+		}},
+		{Name: "list_repos"},
+		{Name: "search", InputSchema: InputSchema{Properties: map[string]Property{"query": {Type: "string"}}}},
+	}
+	existing := narrator.MCPRules{Rules: map[string]narrator.ToolRules{"search": {Default: "検索します"}}}
+
+	got := GenerateRules("github", tools, existing, narrator.LanguageJapanese)
+	want := narrator.MCPRules{
+		Default: "githubの「{operation}」を実行します",
+		Rules: map[string]narrator.ToolRules{
+			"create_issue": {
+				Default:  "create_issueを実行します（{repo}、{title}）",
+				Captures: []narrator.CaptureRule{{InputKey: "repo"}, {InputKey: "title"}},
+			},
+			"get_file": {
+				Default:  "get_fileを実行します（{file_path}）",
+				Captures: []narrator.CaptureRule{{InputKey: "file_path", Type: "file", ParseFileType: true}},
+			},
+			"list_repos": {Default: "list_reposを実行します"},
+			"search":     {Default: "検索します"},
+		},
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("GenerateRules() mismatch (-want +got):\n%s", diff)
+	}
+
+	en := GenerateRules("github", tools[2:3], narrator.MCPRules{Default: "GitHub: {operation}"}, narrator.LanguageEnglish)
+	if en.Default != "GitHub: {operation}" || en.Rules["list_repos"].Default != "Running list_repos" {
+		t.Errorf("GenerateRules() in English = %+v", en)
+	}
+}
+
+func TestGenerateRules_Narration(t *testing.T) {
+	tools := []Tool{{Name: "get_file", InputSchema: InputSchema{
+		Properties: map[string]Property{"path": {Type: "string"}},
+		Required:   []string{"path"},
+	}}}
+	config := &narrator.NarratorConfig{MCPRules: map[string]narrator.MCPRules{
+		"repo": GenerateRules("repo", tools, narrator.MCPRules{}, narrator.LanguageEnglish),
+	}}
+	n := narrator.NewRuleBasedNarratorWithLanguage(config, narrator.LanguageEnglish)
+	got, _ := n.NarrateToolUse("mcp__repo__get_file", map[string]interface{}{"path": "/src/app/main.go"})
+	if got != "Running get_file (main.go)" {
+		t.Errorf("NarrateToolUse() = %q", got)
+	}
+}
+
+func TestReadManifest(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "manifest.json")
+	data := `{"github": {"tools": [{"name": "search", "inputSchema": {"type": "object", "properties": {"query": {"type": "string"}}, "required": ["query"]}}]}}`
+	if err := os.WriteFile(path, []byte(data), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	got, err := ReadManifest(path)
+	if err != nil {
+		t.Fatalf("ReadManifest() error = %v", err)
+	}
+	tools := got["github"]
+	if len(tools) != 1 || tools[0].Name != "search" || tools[0].InputSchema.Properties["query"].TypeName() != "string" {
+		t.Errorf("ReadManifest() = %+v", got)
+	}
+}
@@ -0,0 +1,388 @@
+// Package mqtt publishes Claude Code events to an MQTT broker for home automation.
+// It implements the publishing side of MQTT 3.1.1 with QoS 0 and 1.
+package mqtt
+
+import (
+	"bufio"
+	"crypto/tls"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/url"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/kazegusuri/claude-companion/logger"
+)
+
+// MQTT control packet types
+const (
+	packetConnect    = 1
+	packetConnAck    = 2
+	packetPublish    = 3
+	packetPubAck     = 4
+	packetPingReq    = 12
+	packetPingResp   = 13
+	packetDisconnect = 14
+)
+
+// queueSize is the number of messages waiting to be sent; more are dropped while the
+// broker is unreachable
+const queueSize = 256
+
+// ErrQueueFull is returned by Publish when messages cannot be sent as fast as they come
+var ErrQueueFull = errors.New("MQTT publish queue is full")
+
+// message is a message waiting to be published
+type message struct {
+	topic   string
+	payload []byte
+	qos     byte
+	retain  bool
+	id      uint16 // Packet ID of a QoS 1 message
+}
+
+// Client publishes messages to an MQTT broker in the background. It reconnects with
+// exponential backoff when the connection is lost, and resends QoS 1 messages the
+// broker did not acknowledge.
+type Client struct {
+	addr       string
+	tlsConfig  *tls.Config // nil for plain TCP
+	username   string
+	password   string
+	clientID   string
+	keepAlive  time.Duration
+	minBackoff time.Duration
+	maxBackoff time.Duration
+
+	queue  chan message
+	done   chan struct{}
+	wg     sync.WaitGroup
+	nextID uint16
+	// QoS 1 messages sent but not acknowledged, by packet ID; used by the run goroutine only
+	inflight map[uint16]message
+}
+
+// NewClient creates a client for a broker URL: tcp://host:port (port 1883 by default)
+// or ssl://host:port, mqtts://host:port for TLS (port 8883 by default). A user and
+// password in the URL are used to log in. Call Start to connect.
+func NewClient(broker string) (*Client, error) {
+	u, err := url.Parse(broker)
+	if err != nil || u.Host == "" {
+		return nil, fmt.Errorf("invalid MQTT broker %q (expected tcp://host:port)", broker)
+	}
+	c := &Client{
+		clientID:   "claude-companion-" + uuid.New().String()[:8],
+		keepAlive:  30 * time.Second,
+		minBackoff: time.Second,
+		maxBackoff: time.Minute,
+		queue:      make(chan message, queueSize),
+		done:       make(chan struct{}),
+		inflight:   make(map[uint16]message),
+	}
+	port := "1883"
+	switch u.Scheme {
+	case "tcp", "mqtt":
+	case "ssl", "tls", "mqtts":
+		port = "8883"
+		c.tlsConfig = &tls.Config{ServerName: u.Hostname()}
+	default:
+		return nil, fmt.Errorf("unsupported MQTT broker scheme %q (expected tcp, mqtt, ssl or mqtts)", u.Scheme)
+	}
+	if u.Port() != "" {
+		port = u.Port()
+	}
+	c.addr = net.JoinHostPort(u.Hostname(), port)
+	if u.User != nil {
+		c.username = u.User.Username()
+		c.password, _ = u.User.Password()
+	}
+	return c, nil
+}
+
+// Start connects to the broker and starts publishing in the background
+func (c *Client) Start() {
+	c.wg.Add(1)
+	go c.run()
+}
+
+// Stop sends the messages that can be sent right away and disconnects
+func (c *Client) Stop() {
+	close(c.done)
+	c.wg.Wait()
+}
+
+// Publish queues a message. It does not wait for the message to be sent; QoS 1
+// messages are resent until the broker acknowledges them.
+func (c *Client) Publish(topic string, payload []byte, qos byte, retain bool) error {
+	if qos > 1 {
+		return fmt.Errorf("unsupported MQTT QoS %d (expected 0 or 1)", qos)
+	}
+	select {
+	case c.queue <- message{topic: topic, payload: payload, qos: qos, retain: retain}:
+		return nil
+	default:
+		return ErrQueueFull
+	}
+}
+
+// run keeps a connection to the broker and sends the queued messages over it
+func (c *Client) run() {
+	defer c.wg.Done()
+	backoff := c.minBackoff
+	warned := false
+	for {
+		conn, err := c.connect()
+		if err != nil {
+			// Warn once per outage rather than on every attempt
+			if !warned {
+				logger.LogWarning("Failed to connect to MQTT broker %s, retrying: %v", c.addr, err)
+				warned = true
+			} else {
+				logger.LogDebug("Failed to connect to MQTT broker %s: %v", c.addr, err)
+			}
+			select {
+			case <-c.done:
+				return
+			case <-time.After(backoff):
+			}
+			backoff = min(backoff*2, c.maxBackoff)
+			continue
+		}
+		logger.LogInfo("Connected to MQTT broker %s", c.addr)
+		backoff, warned = c.minBackoff, false
+
+		err = c.serve(conn)
+		conn.Close()
+		if err == nil {
+			return // Stopped
+		}
+		logger.LogWarning("Lost connection to MQTT broker %s, reconnecting: %v", c.addr, err)
+		warned = true
+	}
+}
+
+// connect dials the broker and logs in
+func (c *Client) connect() (net.Conn, error) {
+	dialer := &net.Dialer{Timeout: 10 * time.Second}
+	var conn net.Conn
+	var err error
+	if c.tlsConfig != nil {
+		conn, err = tls.DialWithDialer(dialer, "tcp", c.addr, c.tlsConfig)
+	} else {
+		conn, err = dialer.Dial("tcp", c.addr)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	conn.SetDeadline(time.Now().Add(10 * time.Second))
+	if _, err := conn.Write(c.connectPacket()); err != nil {
+		conn.Close()
+		return nil, err
+	}
+	typ, _, body, err := readPacket(bufio.NewReader(conn))
+	if err == nil && (typ != packetConnAck || len(body) != 2) {
+		err = fmt.Errorf("unexpected packet type %d instead of CONNACK", typ)
+	} else if err == nil && body[1] != 0 {
+		err = fmt.Errorf("broker refused the connection: %s", connAckError(body[1]))
+	}
+	if err != nil {
+		conn.Close()
+		return nil, err
+	}
+	conn.SetDeadline(time.Time{})
+	return conn, nil
+}
+
+// serve sends queued messages and pings until the connection fails, returning the
+// error, or the client is stopped, returning nil
+func (c *Client) serve(conn net.Conn) error {
+	acks := make(chan uint16, queueSize)
+	readErr := make(chan error, 1)
+	go func() {
+		readErr <- c.readLoop(conn, acks)
+	}()
+
+	// Messages not acknowledged over the previous connection are sent again
+	for _, msg := range c.inflight {
+		if _, err := conn.Write(publishPacket(msg, true)); err != nil {
+			return err
+		}
+	}
+
+	ping := time.NewTicker(c.keepAlive)
+	defer ping.Stop()
+	for {
+		select {
+		case <-c.done:
+			c.flush(conn)
+			conn.Write([]byte{packetDisconnect << 4, 0})
+			return nil
+		case err := <-readErr:
+			return err
+		case id := <-acks:
+			delete(c.inflight, id)
+		case <-ping.C:
+			if _, err := conn.Write([]byte{packetPingReq << 4, 0}); err != nil {
+				return err
+			}
+		case msg := <-c.queue:
+			if err := c.send(conn, msg); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// send publishes a message, keeping QoS 1 messages until they are acknowledged
+func (c *Client) send(conn net.Conn, msg message) error {
+	if msg.qos > 0 {
+		c.nextID++
+		if c.nextID == 0 {
+			c.nextID = 1 // Packet ID 0 is not allowed
+		}
+		msg.id = c.nextID
+		c.inflight[msg.id] = msg
+	}
+	_, err := conn.Write(publishPacket(msg, false))
+	return err
+}
+
+// flush sends the messages already queued when the client stops
+func (c *Client) flush(conn net.Conn) {
+	conn.SetWriteDeadline(time.Now().Add(2 * time.Second))
+	for {
+		select {
+		case msg := <-c.queue:
+			if c.send(conn, msg) != nil {
+				return
+			}
+		default:
+			return
+		}
+	}
+}
+
+// readLoop reads acknowledgements until the connection fails. The broker answers
+// pings, so nothing for 1.5 keepalive periods means the connection is dead.
+func (c *Client) readLoop(conn net.Conn, acks chan<- uint16) error {
+	r := bufio.NewReader(conn)
+	for {
+		conn.SetReadDeadline(time.Now().Add(c.keepAlive * 3 / 2))
+		typ, _, body, err := readPacket(r)
+		if err != nil {
+			return err
+		}
+		if typ == packetPubAck && len(body) >= 2 {
+			acks <- binary.BigEndian.Uint16(body)
+		}
+	}
+}
+
+// connectPacket encodes the CONNECT packet, starting a clean session
+func (c *Client) connectPacket() []byte {
+	flags := byte(0x02) // Clean session
+	var payload []byte
+	payload = appendString(payload, c.clientID)
+	if c.username != "" {
+		flags |= 0x80
+		payload = appendString(payload, c.username)
+		if c.password != "" {
+			flags |= 0x40
+			payload = appendString(payload, c.password)
+		}
+	}
+	body := appendString(nil, "MQTT")
+	body = append(body, 4, flags) // Protocol level 4 is MQTT 3.1.1
+	body = binary.BigEndian.AppendUint16(body, uint16(c.keepAlive/time.Second))
+	return appendPacket(packetConnect<<4, append(body, payload...))
+}
+
+// publishPacket encodes a PUBLISH packet, marked as a duplicate when resent
+func publishPacket(msg message, dup bool) []byte {
+	header := byte(packetPublish<<4) | msg.qos<<1
+	if dup {
+		header |= 0x08
+	}
+	if msg.retain {
+		header |= 0x01
+	}
+	body := appendString(nil, msg.topic)
+	if msg.qos > 0 {
+		body = binary.BigEndian.AppendUint16(body, msg.id)
+	}
+	return appendPacket(header, append(body, msg.payload...))
+}
+
+// appendPacket encodes a packet from its first header byte and its body
+func appendPacket(header byte, body []byte) []byte {
+	packet := []byte{header}
+	n := len(body)
+	for {
+		b := byte(n % 128)
+		n /= 128
+		if n > 0 {
+			b |= 0x80
+		}
+		packet = append(packet, b)
+		if n == 0 {
+			break
+		}
+	}
+	return append(packet, body...)
+}
+
+// appendString appends a length-prefixed UTF-8 string
+func appendString(b []byte, s string) []byte {
+	b = binary.BigEndian.AppendUint16(b, uint16(len(s)))
+	return append(b, s...)
+}
+
+// readPacket reads a packet and returns its type, flags and body
+func readPacket(r *bufio.Reader) (typ, flags byte, body []byte, err error) {
+	header, err := r.ReadByte()
+	if err != nil {
+		return 0, 0, nil, err
+	}
+	length, multiplier := 0, 1
+	for i := 0; ; i++ {
+		b, err := r.ReadByte()
+		if err != nil {
+			return 0, 0, nil, err
+		}
+		length += int(b&0x7f) * multiplier
+		if b&0x80 == 0 {
+			break
+		}
+		if i == 3 {
+			return 0, 0, nil, errors.New("malformed MQTT packet length")
+		}
+		multiplier *= 128
+	}
+	body = make([]byte, length)
+	if _, err := io.ReadFull(r, body); err != nil {
+		return 0, 0, nil, err
+	}
+	return header >> 4, header & 0x0f, body, nil
+}
+
+// connAckError describes a CONNACK return code
+func connAckError(code byte) string {
+	switch code {
+	case 1:
+		return "unacceptable protocol version"
+	case 2:
+		return "client identifier rejected"
+	case 3:
+		return "server unavailable"
+	case 4:
+		return "bad user name or password"
+	case 5:
+		return "not authorized"
+	}
+	return fmt.Sprintf("return code %d", code)
+}
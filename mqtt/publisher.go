@@ -0,0 +1,98 @@
+package mqtt
+
+import (
+	"encoding/json"
+	"strings"
+	"time"
+
+	"github.com/kazegusuri/claude-companion/event"
+	"github.com/kazegusuri/claude-companion/logger"
+)
+
+// KindToolUse is the topic kind of assistant messages that use tools
+const KindToolUse = "tool_use"
+
+// Message is the JSON payload published for an event
+type Message struct {
+	Kind       string    `json:"kind"`
+	Type       string    `json:"type"`
+	Project    string    `json:"project,omitempty"`
+	Session    string    `json:"session,omitempty"`
+	Timestamp  time.Time `json:"timestamp"`
+	Text       string    `json:"text"`                 // As shown on the console, without colors
+	Narrations []string  `json:"narrations,omitempty"` // As spoken with voice
+	Tools      []string  `json:"tools,omitempty"`      // Tools used by an assistant message
+	Priority   int       `json:"priority"`
+}
+
+// Publisher publishes events with their narrations on topics like
+// claude/{project}/{session}/tool_use. It implements event.EventSink.
+type Publisher struct {
+	client *Client
+	prefix string
+	qos    byte
+}
+
+// NewPublisher creates a publisher of events under the topic prefix
+func NewPublisher(client *Client, prefix string, qos byte) *Publisher {
+	return &Publisher{client: client, prefix: strings.TrimSuffix(prefix, "/"), qos: qos}
+}
+
+// HandleEvent implements event.EventSink
+func (p *Publisher) HandleEvent(ev event.Event, formatted string) {
+	narrations := event.NarrationsOf(ev)
+	if formatted == "" && len(narrations) == 0 {
+		return // Nothing was shown or spoken
+	}
+
+	msg := Message{
+		Kind:       event.KindOf(ev),
+		Type:       string(ev.Type()),
+		Timestamp:  time.Now(),
+		Text:       formatted,
+		Narrations: narrations,
+		Priority:   event.PriorityOf(ev),
+	}
+	if session := event.SessionOf(ev); session != nil {
+		msg.Project, msg.Session = session.Project, session.Session
+	}
+	if msg.Session == "" {
+		msg.Session = event.SessionIDOf(ev)
+	}
+	if base := event.BaseOf(ev); base != nil && !base.Timestamp.IsZero() {
+		msg.Timestamp = base.Timestamp
+	}
+	if am, ok := ev.(*event.AssistantMessage); ok {
+		for _, content := range am.Message.Content {
+			if content.Type == "tool_use" {
+				msg.Tools = append(msg.Tools, content.Name)
+			}
+		}
+		if len(msg.Tools) > 0 {
+			msg.Kind = KindToolUse
+		}
+	}
+
+	payload, err := json.Marshal(msg)
+	if err != nil {
+		logger.LogError("Failed to encode MQTT message: %v", err)
+		return
+	}
+	if err := p.client.Publish(p.Topic(msg), payload, p.qos, false); err != nil {
+		logger.LogDebug("Dropping MQTT message: %v", err)
+	}
+}
+
+// Topic returns the topic of a message: {prefix}/{project}/{session}/{kind}
+func (p *Publisher) Topic(msg Message) string {
+	return strings.Join([]string{p.prefix, topicLevel(msg.Project), topicLevel(msg.Session), msg.Kind}, "/")
+}
+
+// topicLevel makes a name usable as one topic level, replacing the separator and
+// wildcards MQTT gives a meaning to
+func topicLevel(name string) string {
+	if name == "" {
+		return "unknown"
+	}
+	return strings.NewReplacer("/", "_", "+", "_", "#", "_").Replace(name)
+}
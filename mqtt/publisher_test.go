@@ -0,0 +1,54 @@
+package mqtt
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/kazegusuri/claude-companion/event"
+)
+
+func TestPublisher_HandleEvent(t *testing.T) {
+	c, err := NewClient("tcp://localhost")
+	if err != nil {
+		t.Fatal(err)
+	}
+	p := NewPublisher(c, "claude/", 1)
+
+	ev := &event.AssistantMessage{
+		BaseEvent: event.BaseEvent{
+			TypeString: "assistant",
+			Session:    &event.Session{Project: "-home-me-app", Session: "s1"},
+			Narrations: []string{"main.goを読み込みます"},
+		},
+		Message: event.AssistantMessageContent{Content: []event.AssistantContent{
+			{Type: "text", Text: "Let me look"},
+			{Type: "tool_use", Name: "Read"},
+		}},
+	}
+	p.HandleEvent(ev, "📖 Reading main.go")
+	// Events that are neither shown nor spoken are not published
+	p.HandleEvent(&event.AssistantMessage{BaseEvent: event.BaseEvent{TypeString: "assistant"}}, "")
+
+	if len(c.queue) != 1 {
+		t.Fatalf("queued %d messages, want 1", len(c.queue))
+	}
+	msg := <-c.queue
+	if msg.topic != "claude/-home-me-app/s1/tool_use" || msg.qos != 1 {
+		t.Errorf("published to %s with QoS %d, want claude/-home-me-app/s1/tool_use with QoS 1", msg.topic, msg.qos)
+	}
+	var got Message
+	if err := json.Unmarshal(msg.payload, &got); err != nil {
+		t.Fatal(err)
+	}
+	if got.Kind != KindToolUse || got.Text != "📖 Reading main.go" || len(got.Tools) != 1 || got.Tools[0] != "Read" || len(got.Narrations) != 1 {
+		t.Errorf("payload = %+v", got)
+	}
+}
+
+func TestTopicLevel(t *testing.T) {
+	for name, want := range map[string]string{"": "unknown", "a/b": "a_b", "x+#": "x__", "-home-me-app": "-home-me-app"} {
+		if got := topicLevel(name); got != want {
+			t.Errorf("topicLevel(%q) = %q, want %q", name, got, want)
+		}
+	}
+}
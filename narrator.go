@@ -0,0 +1,206 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/kazegusuri/claude-companion/logger"
+	"github.com/kazegusuri/claude-companion/mcpscan"
+	"github.com/kazegusuri/claude-companion/narrator"
+	"github.com/kazegusuri/claude-companion/usage"
+	"github.com/spf13/pflag"
+	"gopkg.in/yaml.v3"
+)
+
+// narratorCommands maps the narrator subcommands to their entry points
+var narratorCommands = map[string]func(args []string) int{
+	"mcp-scan": runNarratorMCPScan,
+}
+
+// runNarrator runs a narrator subcommand
+func runNarrator(args []string) int {
+	if len(args) > 0 {
+		if cmd, ok := narratorCommands[args[0]]; ok {
+			return cmd(args[1:])
+		}
+	}
+	fmt.Fprintln(os.Stderr, "Usage: claude-companion narrator mcp-scan [flags]")
+	return 2
+}
+
+// mcpRulesFile is a narrator config holding only MCP rules, to be included by another
+type mcpRulesFile struct {
+	MCPRules map[string]narrator.MCPRules `json:"mcpRules"`
+}
+
+// runNarratorMCPScan lists the tools of the configured MCP servers and prints skeleton
+// narration rules for them, to be edited and included in the narrator config
+func runNarratorMCPScan(args []string) int {
+	fs := pflag.NewFlagSet("narrator mcp-scan", pflag.ContinueOnError)
+	var claudeConfig, projectDir, manifest, narratorConfigPath, langCode, format, output string
+	var mcpConfigs, servers []string
+	var list bool
+	var timeout time.Duration
+	fs.StringVar(&claudeConfig, "claude-config", "~/.claude.json", "Claude Code config listing user and local MCP servers")
+	fs.StringSliceVar(&mcpConfigs, "mcp-config", []string{".mcp.json"}, "Project MCP config files (repeatable)")
+	fs.StringVar(&projectDir, "project-dir", "", "Project whose local MCP servers are scanned (default: the working directory)")
+	fs.StringVar(&manifest, "manifest", "", "Read tools from this manifest, {\"server\": {\"tools\": [...]}}, instead of starting the servers")
+	fs.StringSliceVar(&servers, "server", nil, "Only scan these servers (repeatable)")
+	fs.BoolVar(&list, "list", false, "List the tools and their inputs instead of generating rules")
+	fs.StringVar(&narratorConfigPath, "narrator-config", "", "Keep the MCP rules of this narrator config and generate rules only for tools they miss")
+	fs.StringVar(&langCode, "lang", "ja", "Language of the generated messages: ja or en")
+	fs.StringVar(&format, "format", "json", "Output format of the rules: json or yaml")
+	fs.StringVarP(&output, "output", "o", "", "Write the rules to this file instead of stdout")
+	fs.DurationVar(&timeout, "timeout", 15*time.Second, "How long to wait for each server to list its tools")
+	if err := fs.Parse(args); err != nil {
+		if err == pflag.ErrHelp {
+			return 0
+		}
+		return 2
+	}
+	lang, err := narrator.ParseLanguage(langCode)
+	if err != nil {
+		logger.LogError("%v", err)
+		return 2
+	}
+	if format != "json" && format != "yaml" {
+		logger.LogError("unknown format %q (expected json or yaml)", format)
+		return 2
+	}
+
+	tools, err := scanMCPTools(claudeConfig, mcpConfigs, projectDir, manifest, timeout)
+	if err != nil {
+		logger.LogError("%v", err)
+		return 1
+	}
+	names := make([]string, 0, len(tools))
+	for name := range tools {
+		if len(servers) == 0 || slices.Contains(servers, name) {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	if len(names) == 0 {
+		logger.LogError("No MCP servers found")
+		return 1
+	}
+
+	if list {
+		printMCPTools(os.Stdout, names, tools)
+		return 0
+	}
+
+	existing := narrator.GetDefaultNarratorConfigForLanguage(lang).MCPRules
+	if narratorConfigPath != "" {
+		config, err := narrator.LoadNarratorConfig(narratorConfigPath)
+		if err != nil {
+			logger.LogError("%v", err)
+			return 1
+		}
+		existing = config.MCPRules
+	}
+	rules := mcpRulesFile{MCPRules: make(map[string]narrator.MCPRules, len(names))}
+	for _, name := range names {
+		server := mcpscan.ToolServerName(name)
+		rules.MCPRules[server] = mcpscan.GenerateRules(server, tools[name], existing[server], lang)
+	}
+
+	data, err := json.MarshalIndent(rules, "", "  ")
+	if err == nil && format == "yaml" {
+		data, err = jsonToYAML(data)
+	}
+	if err != nil {
+		logger.LogError("Failed to encode rules: %v", err)
+		return 1
+	}
+	if output == "" {
+		os.Stdout.Write(append(data, '\n'))
+		return 0
+	}
+	if err := os.WriteFile(output, append(data, '\n'), 0o644); err != nil {
+		logger.LogError("Failed to write rules: %v", err)
+		return 1
+	}
+	return 0
+}
+
+// scanMCPTools returns the tools of each MCP server, read from a manifest or from the
+// servers in the Claude Code configs. Servers that cannot be scanned are skipped.
+func scanMCPTools(claudeConfig string, mcpConfigs []string, projectDir, manifest string, timeout time.Duration) (map[string][]mcpscan.Tool, error) {
+	if manifest != "" {
+		return mcpscan.ReadManifest(manifest)
+	}
+
+	userConfig, err := usage.ExpandHome(claudeConfig)
+	if err != nil {
+		return nil, err
+	}
+	if projectDir == "" {
+		if projectDir, err = os.Getwd(); err != nil {
+			return nil, err
+		}
+	}
+	servers, err := mcpscan.LoadServers(projectDir, append([]string{userConfig}, mcpConfigs...)...)
+	if err != nil {
+		return nil, err
+	}
+	tools := make(map[string][]mcpscan.Tool, len(servers))
+	for _, server := range servers {
+		ctx, cancel := context.WithTimeout(context.Background(), timeout)
+		found, err := mcpscan.ListTools(ctx, server)
+		cancel()
+		if err != nil {
+			logger.LogWarning("Skipping MCP server %s: %v", server.Name, err)
+			continue
+		}
+		tools[server.Name] = found
+	}
+	return tools, nil
+}
+
+// printMCPTools prints the tools of each server with their inputs, required ones marked with *
+func printMCPTools(out io.Writer, names []string, tools map[string][]mcpscan.Tool) {
+	for _, name := range names {
+		fmt.Fprintf(out, "%s (%d tools)\n", name, len(tools[name]))
+		for _, tool := range tools[name] {
+			fmt.Fprintf(out, "  mcp__%s__%s\n", mcpscan.ToolServerName(name), tool.Name)
+			if description, _, _ := strings.Cut(strings.TrimSpace(tool.Description), "\n"); description != "" {
+				fmt.Fprintf(out, "    %s\n", description)
+			}
+			keys := make([]string, 0, len(tool.InputSchema.Properties))
+			for key := range tool.InputSchema.Properties {
+				keys = append(keys, key)
+			}
+			sort.Strings(keys)
+			for _, key := range keys {
+				mark := ""
+				if slices.Contains(tool.InputSchema.Required, key) {
+					mark = "*"
+				}
+				fmt.Fprintf(out, "    - %s%s: %s\n", key, mark, tool.InputSchema.Properties[key].TypeName())
+			}
+		}
+	}
+}
+
+// jsonToYAML converts JSON to YAML with the same keys
+func jsonToYAML(data []byte) ([]byte, error) {
+	var value interface{}
+	if err := json.Unmarshal(data, &value); err != nil {
+		return nil, err
+	}
+	var b strings.Builder
+	enc := yaml.NewEncoder(&b)
+	enc.SetIndent(2)
+	if err := enc.Encode(value); err != nil {
+		return nil, err
+	}
+	return []byte(strings.TrimSuffix(b.String(), "\n")), nil
+}
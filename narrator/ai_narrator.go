@@ -1,29 +1,28 @@
 package narrator
 
 import (
-	"bytes"
 	"context"
-	"encoding/json"
 	"fmt"
-	"io"
-	"net/http"
 	"os"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/kazegusuri/claude-companion/logger"
 )
 
-// OpenAINarrator uses OpenAI API for narration
-type OpenAINarrator struct {
-	apiKey     string
-	model      string
-	timeout    time.Duration
-	httpClient *http.Client
+// AINarrator uses an AI provider, such as OpenAI, Anthropic or Ollama, for narration
+type AINarrator struct {
+	provider Provider
+	timeout  time.Duration
+	language Language
+
+	mu     sync.Mutex
+	failed providerErrors // Failed requests of a provider that is not a chain
 }
 
-// NewOpenAINarrator creates a new OpenAI narrator
-func NewOpenAINarrator(apiKey string) *OpenAINarrator {
+// OpenAINarratorModel returns the model used by the OpenAI narrator
+func OpenAINarratorModel() string {
 	// Check environment variable for model override
 	model := os.Getenv("OPENAI_NARRATOR_MODEL")
 	if model == "" {
@@ -31,19 +30,60 @@ func NewOpenAINarrator(apiKey string) *OpenAINarrator {
 		// Other options: gpt-4o-nano, gpt-4o-mini, gpt-3.5-turbo, gpt-4o
 		model = "gpt-4.1-nano"
 	}
+	return model
+}
+
+// NewOpenAINarrator creates an AI narrator backed by OpenAI
+func NewOpenAINarrator(apiKey string) *AINarrator {
+	return NewAINarrator(NewOpenAIProvider(apiKey, OpenAINarratorModel()))
+}
+
+// NewAINarrator creates an AI narrator backed by provider. A provider chain
+// gets enough time for every provider in it to be tried.
+func NewAINarrator(provider Provider) *AINarrator {
+	timeout := defaultProviderTimeout
+	if chain, ok := provider.(*ProviderChain); ok {
+		timeout = chain.Timeout()
+	}
+	return &AINarrator{
+		provider: provider,
+		timeout:  timeout,
+	}
+}
+
+// Provider returns the provider used for narration
+func (ai *AINarrator) Provider() Provider {
+	return ai.provider
+}
+
+// Health returns the health of the providers used for narration, in the order they are tried
+func (ai *AINarrator) Health() []ProviderHealth {
+	if chain, ok := ai.provider.(*ProviderChain); ok {
+		return chain.Health()
+	}
+	ai.mu.Lock()
+	defer ai.mu.Unlock()
+	return []ProviderHealth{ai.failed.health(ai.provider.Name())}
+}
 
-	return &OpenAINarrator{
-		apiKey:  apiKey,
-		model:   model,
-		timeout: 5 * time.Second,
-		httpClient: &http.Client{
-			Timeout: 10 * time.Second,
-		},
+// record counts the result of a request to a provider that is not a chain, which
+// tracks the health of its providers itself
+func (ai *AINarrator) record(err error) {
+	if _, ok := ai.provider.(*ProviderChain); ok {
+		return
 	}
+	ai.mu.Lock()
+	defer ai.mu.Unlock()
+	ai.failed.recordError(err, time.Now())
+}
+
+// SetLanguage sets the language of responses
+func (ai *AINarrator) SetLanguage(lang Language) {
+	ai.language = lang
 }
 
 // NarrateToolUse uses OpenAI to narrate tool usage
-func (ai *OpenAINarrator) NarrateToolUse(toolName string, input map[string]interface{}) (string, bool) {
+func (ai *AINarrator) NarrateToolUse(toolName string, input map[string]interface{}) (string, bool) {
 	ctx, cancel := context.WithTimeout(context.Background(), ai.timeout)
 	defer cancel()
 
@@ -51,7 +91,7 @@ func (ai *OpenAINarrator) NarrateToolUse(toolName string, input map[string]inter
 	prompt := ai.createToolPrompt(toolName, input)
 
 	// Call OpenAI API
-	response, err := ai.callOpenAI(ctx, prompt, 0.3, 50)
+	response, err := ai.complete(ctx, prompt, 0.3, 50)
 	if err != nil {
 		// Return empty to fallback to rule-based
 		logger.LogError("Failed to call OpenAI for tool narration: %v", err)
@@ -62,7 +102,7 @@ func (ai *OpenAINarrator) NarrateToolUse(toolName string, input map[string]inter
 }
 
 // NarrateToolUsePermission narrates a tool permission request
-func (ai *OpenAINarrator) NarrateToolUsePermission(toolName string) (string, bool) {
+func (ai *AINarrator) NarrateToolUsePermission(toolName string) (string, bool) {
 	// For permission requests, we can use a simpler prompt
 	ctx, cancel := context.WithTimeout(context.Background(), ai.timeout)
 	defer cancel()
@@ -80,18 +120,18 @@ func (ai *OpenAINarrator) NarrateToolUsePermission(toolName string) (string, boo
 - ファイル書き込みの許可を求めています
 - コマンド実行の許可を求めています`, toolName)
 
-	response, err := ai.callOpenAI(ctx, prompt, 0.3, 50)
+	response, err := ai.complete(ctx, prompt, 0.3, 50)
 	if err != nil {
 		// Fallback to simple format
 		logger.LogError("Failed to call OpenAI for tool permission narration: %v", err)
-		return fmt.Sprintf("%sの使用許可を求めています", toolName), true
+		return localize(ai.language, fmt.Sprintf("%sの使用許可を求めています", toolName), fmt.Sprintf("Requesting permission to use %s", toolName)), true
 	}
 
 	return response, false
 }
 
 // NarrateText returns the text as-is
-func (ai *OpenAINarrator) NarrateText(text string, isThinking bool) (string, bool) {
+func (ai *AINarrator) NarrateText(text string, isThinking bool) (string, bool) {
 	// If text is a single line without newlines, return as-is
 	if !strings.Contains(text, "\n") {
 		return text, false
@@ -101,6 +141,55 @@ func (ai *OpenAINarrator) NarrateText(text string, isThinking bool) (string, boo
 	ctx, cancel := context.WithTimeout(context.Background(), ai.timeout)
 	defer cancel()
 
+	prompt, maxTokens := textPrompt(text, isThinking)
+
+	// Use higher token limit for text narration
+	response, err := ai.complete(ctx, prompt, 0.8, maxTokens)
+	if err != nil {
+		// Fallback to simple format
+		logger.LogError("Failed to call OpenAI for tool permission narration: %v", err)
+		return text, true
+	}
+
+	return response, false
+}
+
+// NarrateTextStream summarizes text like NarrateText, streaming the completion and
+// calling onSentence with each sentence as soon as it is complete
+func (ai *AINarrator) NarrateTextStream(text string, isThinking bool, onSentence func(string)) (string, bool) {
+	// If text is a single line without newlines, return as-is
+	if !strings.Contains(text, "\n") {
+		onSentence(text)
+		return text, false
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), ai.timeout)
+	defer cancel()
+
+	prompt, maxTokens := textPrompt(text, isThinking)
+
+	var sentences []string
+	splitter := newSentenceSplitter(func(sentence string) {
+		sentences = append(sentences, sentence)
+		onSentence(sentence)
+	})
+	response, err := ai.completeStream(ctx, prompt, 0.8, maxTokens, splitter.Write)
+	if err != nil {
+		if len(sentences) == 0 {
+			logger.LogError("Failed to stream OpenAI text narration: %v", err)
+			return text, true
+		}
+		// Keep the sentences that were already handed off and drop the incomplete rest
+		logger.LogError("OpenAI text narration stream ended early: %v", err)
+		return strings.Join(sentences, ""), false
+	}
+	splitter.Flush()
+
+	return strings.TrimSpace(response), false
+}
+
+// textPrompt builds the summarization prompt for text and returns it with the token limit
+func textPrompt(text string, isThinking bool) (string, int) {
 	// Determine max sentences based on text size (4KB = 4096 bytes)
 	maxSentences := 3
 	maxTokens := 150
@@ -140,31 +229,65 @@ func (ai *OpenAINarrator) NarrateText(text string, isThinking bool) (string, boo
 `, maxSentences, text)
 	}
 
-	// Use higher token limit for text narration
-	response, err := ai.callOpenAI(ctx, prompt, 0.8, maxTokens)
-	if err != nil {
-		// Fallback to simple format
-		logger.LogError("Failed to call OpenAI for tool permission narration: %v", err)
-		return text, true
-	}
-
-	return response, false
+	return prompt, maxTokens
 }
 
 // NarrateNotification narrates notification events
-func (ai *OpenAINarrator) NarrateNotification(notificationType NotificationType) (string, bool) {
+func (ai *AINarrator) NarrateNotification(notificationType NotificationType) (string, bool) {
 	// Always return empty string and false
 	return "", false
 }
 
 // NarrateTaskCompletion narrates task completion events
-func (ai *OpenAINarrator) NarrateTaskCompletion(description string, subagentType string) (string, bool) {
+func (ai *AINarrator) NarrateTaskCompletion(description string, subagentType string) (string, bool) {
+	// Always return empty string and false
+	return "", false
+}
+
+// NarrateToolSLABreach narrates a tool that took longer than expected
+func (ai *AINarrator) NarrateToolSLABreach(toolName string, elapsed, limit time.Duration) (string, bool) {
+	// Always return empty string and false
+	return "", false
+}
+
+// NarrateToolDuration narrates how long a finished tool ran
+func (ai *AINarrator) NarrateToolDuration(toolName string, elapsed time.Duration) (string, bool) {
+	// Always return empty string and false
+	return "", false
+}
+
+// NarrateSidechainSummary narrates what a Task subagent did
+func (ai *AINarrator) NarrateSidechainSummary(subagentType string, toolUses int, elapsed time.Duration) (string, bool) {
+	// Always return empty string and false
+	return "", false
+}
+
+// NarrateSessionSummary narrates what a session did when it ended
+func (ai *AINarrator) NarrateSessionSummary(summary SessionSummary) (string, bool) {
+	// Always return empty string and false
+	return "", false
+}
+
+// NarrateBudgetAlert narrates usage that crossed a threshold of its budget
+func (ai *AINarrator) NarrateBudgetAlert(alert BudgetAlert) (string, bool) {
+	// Always return empty string and false
+	return "", false
+}
+
+// NarrateContextAlert narrates a context that crossed a threshold of its window
+func (ai *AINarrator) NarrateContextAlert(threshold, percent int) (string, bool) {
+	// Always return empty string and false
+	return "", false
+}
+
+// NarrateSecurityAlert narrates a risky tool use flagged by a security rule
+func (ai *AINarrator) NarrateSecurityAlert(alert SecurityAlert) (string, bool) {
 	// Always return empty string and false
 	return "", false
 }
 
 // NarrateAPIError narrates an API error
-func (ai *OpenAINarrator) NarrateAPIError(statusCode int, errorType string, message string) (string, bool) {
+func (ai *AINarrator) NarrateAPIError(statusCode int, errorType string, message string) (string, bool) {
 	ctx, cancel := context.WithTimeout(context.Background(), ai.timeout)
 	defer cancel()
 
@@ -185,7 +308,7 @@ func (ai *OpenAINarrator) NarrateAPIError(statusCode int, errorType string, mess
 - Claude のサーバーが過負荷状態です。少し待ってから再試行してください。
 - Claude のサーバーからリクエストエラーを受け取りました。入力内容を確認してください。`, statusCode, errorType, message)
 
-	response, err := ai.callOpenAI(ctx, prompt, 0.3, 50)
+	response, err := ai.complete(ctx, prompt, 0.3, 50)
 	if err != nil {
 		// Fallback to simple format
 		logger.LogError("Failed to call OpenAI for API error narration: %v", err)
@@ -201,6 +324,7 @@ type openAIRequest struct {
 	Messages    []openAIMessage `json:"messages"`
 	Temperature float64         `json:"temperature"`
 	MaxTokens   int             `json:"max_tokens"`
+	Stream      bool            `json:"stream,omitempty"`
 }
 
 type openAIMessage struct {
@@ -221,7 +345,7 @@ type openAIError struct {
 }
 
 // createToolPrompt creates a prompt for the AI to narrate tool usage
-func (ai *OpenAINarrator) createToolPrompt(toolName string, input map[string]interface{}) string {
+func (ai *AINarrator) createToolPrompt(toolName string, input map[string]interface{}) string {
 	// For Bash tool, use command field if available
 	keysStr := ""
 	if toolName == "Bash" {
@@ -285,60 +409,49 @@ func (ai *OpenAINarrator) createToolPrompt(toolName string, input map[string]int
 	return prompt
 }
 
-// callOpenAI makes the actual API call to OpenAI
-func (ai *OpenAINarrator) callOpenAI(ctx context.Context, prompt string, temperature float64, maxTokens int) (string, error) {
-	request := openAIRequest{
-		Model: ai.model,
-		Messages: []openAIMessage{
-			{
-				Role:    "system",
-				Content: "あなたはAIアシスタントの行動を簡潔に説明するロボットです。短く、分かりやすい日本語で応答してください。",
-			},
-			{
-				Role:    "user",
-				Content: prompt,
-			},
-		},
+// systemPrompt returns the system prompt for the narration language
+func (ai *AINarrator) systemPrompt() string {
+	return localize(ai.language,
+		"あなたはAIアシスタントの行動を簡潔に説明するロボットです。短く、分かりやすい日本語で応答してください。",
+		"You are a robot that briefly describes what an AI assistant is doing. Always respond in short, plain English, even if the instructions are written in Japanese.")
+}
+
+// complete makes the actual API call to the provider
+func (ai *AINarrator) complete(ctx context.Context, prompt string, temperature float64, maxTokens int) (string, error) {
+	result, err := ai.provider.Complete(ctx, CompletionRequest{
+		System:      ai.systemPrompt(),
+		Prompt:      prompt,
 		Temperature: temperature,
 		MaxTokens:   maxTokens,
+	})
+	if ctx.Err() == nil {
+		ai.record(err)
 	}
+	return result, err
+}
 
-	jsonData, err := json.Marshal(request)
-	if err != nil {
-		return "", err
-	}
-
-	req, err := http.NewRequestWithContext(ctx, "POST", "https://api.openai.com/v1/chat/completions", bytes.NewBuffer(jsonData))
-	if err != nil {
-		return "", err
-	}
-
-	req.Header.Set("Content-Type", "application/json")
-	req.Header.Set("Authorization", "Bearer "+ai.apiKey)
-
-	resp, err := ai.httpClient.Do(req)
-	if err != nil {
-		return "", err
-	}
-	defer resp.Body.Close()
-
-	body, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return "", err
+// completeStream streams the completion from the provider, or delivers it at once
+// if the provider cannot stream
+func (ai *AINarrator) completeStream(ctx context.Context, prompt string, temperature float64, maxTokens int, onDelta func(string)) (string, error) {
+	req := CompletionRequest{
+		System:      ai.systemPrompt(),
+		Prompt:      prompt,
+		Temperature: temperature,
+		MaxTokens:   maxTokens,
 	}
-
-	var response openAIResponse
-	if err := json.Unmarshal(body, &response); err != nil {
-		return "", err
+	if sp, ok := ai.provider.(StreamingProvider); ok {
+		result, err := sp.CompleteStream(ctx, req, onDelta)
+		if ctx.Err() == nil {
+			ai.record(err)
+		}
+		return result, err
 	}
-
-	if response.Error != nil {
-		return "", fmt.Errorf("OpenAI API error: %s", response.Error.Message)
+	result, err := ai.provider.Complete(ctx, req)
+	if ctx.Err() == nil {
+		ai.record(err)
 	}
-
-	if len(response.Choices) > 0 {
-		return strings.TrimSpace(response.Choices[0].Message.Content), nil
+	if err == nil {
+		onDelta(result)
 	}
-
-	return "", fmt.Errorf("no response from OpenAI")
+	return result, err
 }
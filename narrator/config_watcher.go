@@ -0,0 +1,141 @@
+package narrator
+
+import (
+	"fmt"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+	"github.com/kazegusuri/claude-companion/logger"
+)
+
+// ConfigWatcher watches a configuration file, such as the narrator config or a user
+// dictionary, and reloads it on change
+type ConfigWatcher struct {
+	path     string
+	name     string       // What the file is, for logs
+	load     func() error // Loads the file and applies it
+	debounce time.Duration
+	watcher  *fsnotify.Watcher
+	done     chan struct{}
+	wg       sync.WaitGroup
+}
+
+// NewConfigWatcher creates a watcher that calls onReload with each successfully loaded config
+func NewConfigWatcher(path string, onReload func(config *NarratorConfig)) *ConfigWatcher {
+	return newConfigWatcher(path, "narrator config", func() error {
+		config, err := LoadNarratorConfig(path)
+		if err != nil {
+			return err
+		}
+		onReload(config)
+		return nil
+	})
+}
+
+// NewUserDictionaryWatcher creates a watcher that calls onReload with each successfully
+// loaded user dictionary
+func NewUserDictionaryWatcher(path string, onReload func(dict *UserDictionary)) *ConfigWatcher {
+	return newConfigWatcher(path, "user dictionary", func() error {
+		dict, err := LoadUserDictionary(path)
+		if err != nil {
+			return err
+		}
+		onReload(dict)
+		return nil
+	})
+}
+
+// newConfigWatcher creates a watcher that calls load when the file changes
+func newConfigWatcher(path, name string, load func() error) *ConfigWatcher {
+	return &ConfigWatcher{
+		path:     path,
+		name:     name,
+		load:     load,
+		debounce: 200 * time.Millisecond,
+		done:     make(chan struct{}),
+	}
+}
+
+// Start starts watching the configuration file
+func (w *ConfigWatcher) Start() error {
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return fmt.Errorf("failed to create config watcher: %w", err)
+	}
+
+	// Watch the directory since editors often replace the file instead of writing to it
+	dir := filepath.Dir(w.path)
+	if err := watcher.Add(dir); err != nil {
+		watcher.Close()
+		return fmt.Errorf("failed to watch directory %s: %w", dir, err)
+	}
+	w.watcher = watcher
+
+	w.wg.Add(1)
+	go w.watch()
+	return nil
+}
+
+// Stop stops the watcher
+func (w *ConfigWatcher) Stop() {
+	close(w.done)
+	if w.watcher != nil {
+		w.watcher.Close()
+	}
+	w.wg.Wait()
+}
+
+// watch waits for changes to the configuration file and reloads it after they settle
+func (w *ConfigWatcher) watch() {
+	defer w.wg.Done()
+
+	fileName := filepath.Base(w.path)
+	var timer *time.Timer
+	var timerC <-chan time.Time
+
+	for {
+		select {
+		case <-w.done:
+			if timer != nil {
+				timer.Stop()
+			}
+			return
+		case event, ok := <-w.watcher.Events:
+			if !ok {
+				return
+			}
+			if filepath.Base(event.Name) != fileName {
+				continue
+			}
+			if event.Op&(fsnotify.Write|fsnotify.Create|fsnotify.Rename) == 0 {
+				continue
+			}
+			// Editors may write a file several times in a row; reload once they are done
+			if timer == nil {
+				timer = time.NewTimer(w.debounce)
+			} else {
+				timer.Reset(w.debounce)
+			}
+			timerC = timer.C
+		case <-timerC:
+			timerC = nil
+			w.reload()
+		case err, ok := <-w.watcher.Errors:
+			if !ok {
+				return
+			}
+			logger.LogError("Watcher of %s %s failed: %v", w.name, w.path, err)
+		}
+	}
+}
+
+// reload loads the configuration file, keeping the previous one on error
+func (w *ConfigWatcher) reload() {
+	if err := w.load(); err != nil {
+		logger.LogError("Failed to reload %s, keeping the previous one: %v", w.name, err)
+		return
+	}
+	logger.LogInfo("Reloaded %s: %s", w.name, w.path)
+}
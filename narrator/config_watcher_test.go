@@ -0,0 +1,65 @@
+package narrator
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestConfigWatcher(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "narrator.json")
+	if err := os.WriteFile(path, []byte(`{"rules":{"Read":{"default":"first"}}}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	reloaded := make(chan *NarratorConfig, 10)
+	watcher := NewConfigWatcher(path, func(config *NarratorConfig) {
+		reloaded <- config
+	})
+	watcher.debounce = 10 * time.Millisecond
+	if err := watcher.Start(); err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+	defer watcher.Stop()
+
+	// A valid config is reloaded
+	if err := os.WriteFile(path, []byte(`{"rules":{"Read":{"default":"second"}}}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case config := <-reloaded:
+		if got := config.Rules["Read"].Default; got != "second" {
+			t.Errorf("reloaded Read rule = %q, want %q", got, "second")
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("config was not reloaded")
+	}
+
+	// An invalid config is ignored
+	if err := os.WriteFile(path, []byte(`{"rules":`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case config := <-reloaded:
+		t.Errorf("invalid config should not be reloaded, got %+v", config)
+	case <-time.After(200 * time.Millisecond):
+	}
+}
+
+func TestHybridNarrator_SetConfig(t *testing.T) {
+	hn := NewHybridNarrator("", false)
+
+	before, _ := hn.NarrateToolUse("ExitPlanMode", map[string]interface{}{})
+	hn.SetConfig(&NarratorConfig{
+		Rules: map[string]ToolRules{"ExitPlanMode": {Default: "reloaded"}},
+	})
+	after, _ := hn.NarrateToolUse("ExitPlanMode", map[string]interface{}{})
+
+	if before == "reloaded" {
+		t.Fatalf("unexpected narration before reload: %q", before)
+	}
+	if after != "reloaded" {
+		t.Errorf("NarrateToolUse() after SetConfig = %q, want %q", after, "reloaded")
+	}
+}
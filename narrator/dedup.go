@@ -0,0 +1,126 @@
+package narrator
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+)
+
+// dedupDecision is what to do with a tool narration
+type dedupDecision int
+
+const (
+	dedupSpeak    dedupDecision = iota // Queue it as a new narration
+	dedupCollapse                      // Fold it into the queued narration of the same tool and target
+	dedupDrop                          // Drop it: over the rate limit
+)
+
+// repeatedNarration is the last tool narration and how often it repeated
+type repeatedNarration struct {
+	key   string
+	text  string // Narration of the first occurrence
+	id    string // Queue item of the first occurrence
+	count int
+	last  time.Time
+}
+
+// NarrationDeduper collapses consecutive narrations of the same tool on the same target,
+// such as twenty edits of one file, and limits how many tool narrations are spoken per minute
+type NarrationDeduper struct {
+	mu           sync.Mutex
+	window       time.Duration // Repeats further apart than this are narrated again; 0 disables collapsing
+	maxPerMinute int           // 0 is unlimited
+	now          func() time.Time
+	last         *repeatedNarration
+	spoken       []time.Time // Tool narrations queued within the last minute
+}
+
+// NewNarrationDeduper creates a deduper that collapses repeats within window and
+// queues at most maxPerMinute tool narrations a minute
+func NewNarrationDeduper(window time.Duration, maxPerMinute int) *NarrationDeduper {
+	return &NarrationDeduper{
+		window:       window,
+		maxPerMinute: maxPerMinute,
+		now:          time.Now,
+	}
+}
+
+// check decides what to do with a tool narration of key. For dedupCollapse it returns
+// the first narration of the streak, its queue item and how often it was repeated.
+func (d *NarrationDeduper) check(key string) (decision dedupDecision, text, id string, count int) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	now := d.now()
+
+	if l := d.last; l != nil && d.window > 0 && l.key == key && now.Sub(l.last) <= d.window {
+		l.count++
+		l.last = now
+		return dedupCollapse, l.text, l.id, l.count
+	}
+
+	if d.maxPerMinute > 0 {
+		cutoff := now.Add(-time.Minute)
+		i := 0
+		for i < len(d.spoken) && !d.spoken[i].After(cutoff) {
+			i++
+		}
+		d.spoken = d.spoken[i:]
+		if len(d.spoken) >= d.maxPerMinute {
+			return dedupDrop, "", "", 0
+		}
+	}
+	return dedupSpeak, "", "", 0
+}
+
+// record remembers a tool narration queued as item id, starting a new streak
+func (d *NarrationDeduper) record(key, text, id string) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	now := d.now()
+	d.last = &repeatedNarration{key: key, text: text, id: id, count: 1, last: now}
+	if d.maxPerMinute > 0 {
+		d.spoken = append(d.spoken, now)
+	}
+}
+
+// reset ends the current streak, since another kind of narration came in between
+func (d *NarrationDeduper) reset() {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.last = nil
+}
+
+// dedupKey identifies the tool narrations that count as repeats of each other
+func dedupKey(in PriorityInput) string {
+	target := in.Target
+	if target == "" {
+		target = in.Text
+	}
+	return in.ToolName + "\x00" + target
+}
+
+// toolTarget returns what a tool use works on: a file, a path, a pattern, a URL or a command
+func toolTarget(input map[string]interface{}) string {
+	for _, key := range []string{"file_path", "notebook_path", "path", "pattern", "url", "query", "command"} {
+		if v, ok := input[key].(string); ok && v != "" {
+			return v
+		}
+	}
+	return ""
+}
+
+// collapseRepeated rewrites a narration to say it happens count times, e.g.
+// "main.goを編集します" becomes "main.goを5回編集します"
+func collapseRepeated(lang Language, text string, count int) string {
+	if lang == LanguageEnglish {
+		trimmed := strings.TrimRight(text, ". ")
+		return fmt.Sprintf("%s (%d times)", trimmed, count)
+	}
+	if i := strings.LastIndex(text, "を"); i >= 0 {
+		i += len("を")
+		return fmt.Sprintf("%s%d回%s", text[:i], count, text[i:])
+	}
+	trimmed := strings.TrimRight(text, "。")
+	return fmt.Sprintf("%s（%d回）", trimmed, count)
+}
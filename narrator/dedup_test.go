@@ -0,0 +1,93 @@
+package narrator
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestCollapseRepeated(t *testing.T) {
+	tests := []struct {
+		lang  Language
+		text  string
+		count int
+		want  string
+	}{
+		{LanguageJapanese, "main.goを編集します", 5, "main.goを5回編集します"},
+		{LanguageJapanese, "テストを実行します。", 3, "テストを3回実行します。"},
+		{LanguageJapanese, "ファイル一覧を確認中", 2, "ファイル一覧を2回確認中"},
+		{LanguageJapanese, "Web検索します。", 2, "Web検索します（2回）"},
+		{LanguageEnglish, "Editing main.go.", 4, "Editing main.go (4 times)"},
+	}
+	for _, tt := range tests {
+		if got := collapseRepeated(tt.lang, tt.text, tt.count); got != tt.want {
+			t.Errorf("collapseRepeated(%q, %q, %d) = %q, want %q", tt.lang, tt.text, tt.count, got, tt.want)
+		}
+	}
+}
+
+func TestNarrationDeduper(t *testing.T) {
+	now := time.Date(2025, 1, 26, 10, 0, 0, 0, time.UTC)
+	d := NewNarrationDeduper(10*time.Second, 3)
+	d.now = func() time.Time { return now }
+
+	steps := []struct {
+		advance   time.Duration
+		key       string
+		want      dedupDecision
+		wantCount int
+	}{
+		{0, "Edit:a.go", dedupSpeak, 0},
+		{time.Second, "Edit:a.go", dedupCollapse, 2},
+		{8 * time.Second, "Edit:a.go", dedupCollapse, 3}, // The window slides with each repeat
+		{11 * time.Second, "Edit:a.go", dedupSpeak, 0},   // Too far apart
+		{time.Second, "Edit:b.go", dedupSpeak, 0},
+		{time.Second, "Edit:c.go", dedupDrop, 0}, // Three tool narrations within the last minute
+		{time.Minute, "Edit:c.go", dedupSpeak, 0},
+	}
+	for i, step := range steps {
+		now = now.Add(step.advance)
+		got, _, _, count := d.check(step.key)
+		if got != step.want || count != step.wantCount {
+			t.Fatalf("step %d: check(%q) = %d, %d; want %d, %d", i, step.key, got, count, step.want, step.wantCount)
+		}
+		if got == dedupSpeak {
+			d.record(step.key, step.key, "")
+		}
+	}
+}
+
+func TestVoiceNarrator_CollapsesRepeatedToolUses(t *testing.T) {
+	vn := NewVoiceNarrator(nil, nil, nil, false)
+	defer vn.Close()
+	vn.SetDeduper(NewNarrationDeduper(time.Minute, 0))
+
+	edit := PriorityInput{Type: NarrationTypeToolUse, ToolName: "Edit", Target: "/src/main.go"}
+	for i := 0; i < 5; i++ {
+		vn.enqueueNarration("main.goを編集します", edit)
+	}
+	vn.enqueueNarration("テストが通りました", PriorityInput{Type: NarrationTypeText})
+	vn.enqueueNarration("main.goを編集します", edit)
+
+	ctx := context.Background()
+	var got []string
+	for vn.QueueSize() > 0 {
+		got = append(got, vn.queue.Dequeue(ctx).OriginalText)
+	}
+	want := []string{"main.goを5回編集します", "テストが通りました", "main.goを編集します"}
+	if len(got) != len(want) {
+		t.Fatalf("queued %q, want %q", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("queued %q, want %q", got, want)
+			break
+		}
+	}
+
+	// A repeat of a narration already spoken is dropped
+	vn.enqueueNarration("main.goを編集します", edit)
+	if size := vn.QueueSize(); size != 0 {
+		t.Errorf("QueueSize() = %d, want 0", size)
+	}
+}
@@ -0,0 +1,72 @@
+package narrator
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/kazegusuri/claude-companion/logger"
+)
+
+// engineHealth tracks whether the speech engine is up. When a synthesis fails and the
+// engine does not answer, the voice is muted with a single warning, and the engine is
+// polled with exponential backoff until it answers again. A nil engineHealth is
+// always up.
+type engineHealth struct {
+	available  func() bool
+	down       atomic.Bool
+	minBackoff time.Duration
+	maxBackoff time.Duration
+}
+
+// newEngineHealth creates the health of an engine answering to available
+func newEngineHealth(available func() bool) *engineHealth {
+	return &engineHealth{available: available, minBackoff: time.Second, maxBackoff: time.Minute}
+}
+
+// Down reports whether the engine is down
+func (h *engineHealth) Down() bool {
+	return h != nil && h.down.Load()
+}
+
+// checkFailure checks the engine after a synthesis failed and reports whether it is
+// down. When it goes down, it is polled in the background until it comes back or ctx
+// is done.
+func (h *engineHealth) checkFailure(ctx context.Context, wg *sync.WaitGroup) bool {
+	if h == nil {
+		return false
+	}
+	if h.down.Load() {
+		return true
+	}
+	if h.available() {
+		return false // The engine is up; only this synthesis failed
+	}
+	if !h.down.CompareAndSwap(false, true) {
+		return true
+	}
+	logger.LogWarning("Speech engine is not responding; narrations are not spoken until it comes back")
+	wg.Add(1)
+	go h.poll(ctx, wg)
+	return true
+}
+
+// poll checks the engine with exponential backoff until it answers
+func (h *engineHealth) poll(ctx context.Context, wg *sync.WaitGroup) {
+	defer wg.Done()
+	backoff := h.minBackoff
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(backoff):
+		}
+		if h.available() {
+			h.down.Store(false)
+			logger.LogInfo("Speech engine is back; resuming voice narration")
+			return
+		}
+		backoff = min(backoff*2, h.maxBackoff)
+	}
+}
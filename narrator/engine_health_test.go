@@ -0,0 +1,59 @@
+package narrator
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestEngineHealth(t *testing.T) {
+	var up atomic.Bool
+	var checks atomic.Int32
+	h := newEngineHealth(func() bool {
+		checks.Add(1)
+		return up.Load()
+	})
+	h.minBackoff, h.maxBackoff = time.Millisecond, 4*time.Millisecond
+
+	ctx, cancel := context.WithCancel(context.Background())
+	var wg sync.WaitGroup
+	defer wg.Wait()
+	defer cancel()
+
+	// A failure while the engine answers is only that synthesis failing
+	up.Store(true)
+	if h.checkFailure(ctx, &wg) || h.Down() {
+		t.Fatal("engine reported down while it answers")
+	}
+
+	up.Store(false)
+	if !h.checkFailure(ctx, &wg) || !h.Down() {
+		t.Fatal("engine not reported down while it does not answer")
+	}
+	if !h.checkFailure(ctx, &wg) {
+		t.Error("checkFailure() while down = false, want true")
+	}
+
+	// It is polled until it comes back
+	deadline := time.Now().Add(2 * time.Second)
+	for checks.Load() < 4 {
+		if time.Now().After(deadline) {
+			t.Fatal("engine was not polled while down")
+		}
+		time.Sleep(time.Millisecond)
+	}
+	up.Store(true)
+	for h.Down() {
+		if time.Now().After(deadline) {
+			t.Fatal("voice did not resume after the engine came back")
+		}
+		time.Sleep(time.Millisecond)
+	}
+
+	var none *engineHealth
+	if none.Down() || none.checkFailure(ctx, &wg) {
+		t.Error("nil engineHealth reported down")
+	}
+}
@@ -0,0 +1,86 @@
+package narrator
+
+import (
+	"regexp"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// Glossary maps English terms to the Japanese reading narrations should use, such as
+// "pull request" to "プルリクエスト". A nil Glossary leaves text unchanged.
+type Glossary struct {
+	terms   map[string]string // key: lower-cased English term
+	pattern *regexp.Regexp
+}
+
+// NewGlossary creates a glossary from English term to Japanese reading. Terms match
+// whole words, ignoring case; it returns nil when there are no terms.
+func NewGlossary(terms map[string]string) *Glossary {
+	g := &Glossary{terms: make(map[string]string)}
+	var keys []string
+	for term, reading := range terms {
+		term = strings.TrimSpace(term)
+		if term == "" || reading == "" {
+			continue
+		}
+		key := strings.ToLower(term)
+		if _, ok := g.terms[key]; !ok {
+			keys = append(keys, key)
+		}
+		g.terms[key] = reading
+	}
+	if len(keys) == 0 {
+		return nil
+	}
+
+	// Longer terms first so "pull request" wins over "request"
+	sort.Slice(keys, func(i, j int) bool {
+		if len(keys[i]) != len(keys[j]) {
+			return len(keys[i]) > len(keys[j])
+		}
+		return keys[i] < keys[j]
+	})
+	alternatives := make([]string, len(keys))
+	for i, key := range keys {
+		alternatives[i] = wordBounded(key)
+	}
+	g.pattern = regexp.MustCompile("(?i)" + strings.Join(alternatives, "|"))
+	return g
+}
+
+// wordBounded quotes term for a regexp that only matches it as a whole word
+func wordBounded(term string) string {
+	quoted := regexp.QuoteMeta(term)
+	runes := []rune(term)
+	if isWordChar(runes[0]) {
+		quoted = `\b` + quoted
+	}
+	if isWordChar(runes[len(runes)-1]) {
+		quoted += `\b`
+	}
+	return quoted
+}
+
+// isWordChar reports whether r is a regexp word character
+func isWordChar(r rune) bool {
+	return r == '_' || r < unicode.MaxASCII && (unicode.IsLetter(r) || unicode.IsDigit(r))
+}
+
+// Apply replaces the glossary terms in text with their readings
+func (g *Glossary) Apply(text string) string {
+	if g == nil {
+		return text
+	}
+	return g.pattern.ReplaceAllStringFunc(text, func(term string) string {
+		return g.terms[strings.ToLower(term)]
+	})
+}
+
+// Len returns the number of terms
+func (g *Glossary) Len() int {
+	if g == nil {
+		return 0
+	}
+	return len(g.terms)
+}
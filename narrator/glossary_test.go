@@ -0,0 +1,34 @@
+package narrator
+
+import "testing"
+
+func TestGlossary_Apply(t *testing.T) {
+	g := NewGlossary(map[string]string{
+		"pull request": "プルリクエスト",
+		"request":      "リクエスト",
+		"C++":          "シープラスプラス",
+	})
+	tests := []struct {
+		text string
+		want string
+	}{
+		{"Open a Pull Request", "Open a プルリクエスト"},
+		{"Send the request", "Send the リクエスト"},
+		{"requests are queued", "requests are queued"},
+		{"Build the C++ sources", "Build the シープラスプラス sources"},
+		{"プルリクエストを作成", "プルリクエストを作成"},
+	}
+	for _, tt := range tests {
+		if got := g.Apply(tt.text); got != tt.want {
+			t.Errorf("Apply(%q) = %q, want %q", tt.text, got, tt.want)
+		}
+	}
+
+	if NewGlossary(nil) != nil {
+		t.Errorf("NewGlossary(nil) should return nil")
+	}
+	var none *Glossary
+	if got := none.Apply("pull request"); got != "pull request" {
+		t.Errorf("nil Glossary changed the text to %q", got)
+	}
+}
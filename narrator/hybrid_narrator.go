@@ -2,21 +2,41 @@ package narrator
 
 import (
 	"fmt"
+	"reflect"
 	"sort"
 	"strings"
 	"sync"
 	"time"
+
+	"github.com/kazegusuri/claude-companion/logger"
 )
 
 // HybridNarrator uses multiple narrators in sequence
 type HybridNarrator struct {
-	narrators []Narrator
-	cache     map[string]string
-	cacheMu   sync.RWMutex
-	cacheTime map[string]time.Time
-	cacheTTL  time.Duration
+	narrators   []Narrator
+	narratorsMu sync.RWMutex
+	cache       map[string]string
+	cacheMu     sync.RWMutex
+	cacheTime   map[string]time.Time
+	cacheTTL    time.Duration
+	language    Language
+
+	// AI narrator settings, used to rebuild it when the provider config changes
+	apiKey      string
+	provider    Provider // Used instead of OpenAI when no provider chain is configured
+	useAI       bool
+	aiProviders []AIProviderConfig
+
+	// Rule-based narrators of projects that override the narrator rules
+	projectConfig *NarratorConfig
+	projectRules  map[*NarratorConfig]*RuleBasedNarrator
+
+	thinking ThinkingPolicy // How thinking blocks are narrated; empty narrates them like text
 }
 
+// maxProjectRules bounds the rule-based narrators kept for project overrides
+const maxProjectRules = 64
+
 // NewHybridNarrator creates a new hybrid narrator
 func NewHybridNarrator(apiKey string, useAI bool) *HybridNarrator {
 	return NewHybridNarratorWithConfig(apiKey, useAI, nil)
@@ -24,34 +44,168 @@ func NewHybridNarrator(apiKey string, useAI bool) *HybridNarrator {
 
 // NewHybridNarratorWithConfig creates a new hybrid narrator with optional config
 func NewHybridNarratorWithConfig(apiKey string, useAI bool, configPath *string) *HybridNarrator {
+	return NewHybridNarratorWithLanguage(apiKey, useAI, configPath, LanguageJapanese)
+}
+
+// NewHybridNarratorWithLanguage creates a new hybrid narrator with optional config for the given language
+func NewHybridNarratorWithLanguage(apiKey string, useAI bool, configPath *string, lang Language) *HybridNarrator {
+	return NewHybridNarratorWithProvider(apiKey, nil, useAI, configPath, lang)
+}
+
+// NewHybridNarratorWithProvider creates a new hybrid narrator whose AI narrator uses
+// provider unless the config has a provider chain. A nil provider uses OpenAI with apiKey.
+func NewHybridNarratorWithProvider(apiKey string, provider Provider, useAI bool, configPath *string, lang Language) *HybridNarrator {
 	hn := &HybridNarrator{
 		cache:     make(map[string]string),
 		cacheTime: make(map[string]time.Time),
 		cacheTTL:  30 * time.Minute,
 		narrators: make([]Narrator, 0),
+		language:  lang,
+		apiKey:    apiKey,
+		provider:  provider,
+		useAI:     useAI,
 	}
 
 	// Load config if path is provided, otherwise use defaults
 	var config *NarratorConfig
 	if configPath != nil && *configPath != "" {
-		config = LoadNarratorConfigWithDefaults(*configPath)
+		config = LoadNarratorConfigWithLanguage(*configPath, lang)
 	} else {
-		config = GetDefaultNarratorConfig()
+		config = GetDefaultNarratorConfigForLanguage(lang)
 	}
 
 	// Create rule-based narrator (always first)
-	ruleBasedNarrator := NewRuleBasedNarrator(config)
+	ruleBasedNarrator := NewRuleBasedNarratorWithLanguage(config, lang)
 	hn.narrators = append(hn.narrators, ruleBasedNarrator)
 
 	// Add AI narrator if enabled
-	if useAI && apiKey != "" {
-		aiNarrator := NewOpenAINarrator(apiKey)
+	if aiNarrator := hn.newAINarrator(config); aiNarrator != nil {
 		hn.narrators = append(hn.narrators, aiNarrator)
 	}
 
 	return hn
 }
 
+// newAINarrator creates the AI narrator from the provider chain in config, or from
+// the default provider if none is configured. It returns nil if AI is disabled.
+func (hn *HybridNarrator) newAINarrator(config *NarratorConfig) *AINarrator {
+	if !hn.useAI {
+		return nil
+	}
+	hn.aiProviders = config.AIProviders
+
+	var aiNarrator *AINarrator
+	if len(config.AIProviders) > 0 {
+		chain, err := NewProviderChainFromConfig(config.AIProviders, hn.apiKey)
+		if err != nil {
+			logger.LogWarning("Invalid AI provider chain, using the default provider only: %v", err)
+		} else {
+			aiNarrator = NewAINarrator(chain)
+		}
+	}
+	if aiNarrator == nil {
+		switch {
+		case hn.provider != nil:
+			aiNarrator = NewAINarrator(hn.provider)
+		case hn.apiKey != "":
+			aiNarrator = NewOpenAINarrator(hn.apiKey)
+		default:
+			return nil
+		}
+	}
+	aiNarrator.SetLanguage(hn.language)
+	return aiNarrator
+}
+
+// AIHealth returns the health of the providers of the AI narrator, or nil without one
+func (hn *HybridNarrator) AIHealth() []ProviderHealth {
+	hn.narratorsMu.RLock()
+	defer hn.narratorsMu.RUnlock()
+	for _, n := range hn.narrators {
+		if ai, ok := n.(*AINarrator); ok {
+			return ai.Health()
+		}
+	}
+	return nil
+}
+
+// chain returns the narrators in the order they are tried, with the rules of the
+// current project's override in place of the global rules
+func (hn *HybridNarrator) chain() []Narrator {
+	hn.narratorsMu.RLock()
+	defer hn.narratorsMu.RUnlock()
+	if hn.projectConfig == nil {
+		return hn.narrators
+	}
+	narrators := make([]Narrator, len(hn.narrators))
+	for i, n := range hn.narrators {
+		if _, ok := n.(*RuleBasedNarrator); ok {
+			n = hn.projectRules[hn.projectConfig]
+		}
+		narrators[i] = n
+	}
+	return narrators
+}
+
+// SetProjectOverrides narrates with the project's narrator rules until the overrides change
+func (hn *HybridNarrator) SetProjectOverrides(o *ProjectOverrides) {
+	hn.narratorsMu.Lock()
+	defer hn.narratorsMu.Unlock()
+	hn.projectConfig = nil
+	if o == nil || o.Config == nil {
+		return
+	}
+	if _, ok := hn.projectRules[o.Config]; !ok {
+		if hn.projectRules == nil || len(hn.projectRules) >= maxProjectRules {
+			hn.projectRules = make(map[*NarratorConfig]*RuleBasedNarrator)
+		}
+		hn.projectRules[o.Config] = NewRuleBasedNarratorWithLanguage(o.Config, hn.language)
+	}
+	hn.projectConfig = o.Config
+}
+
+// cacheScope returns the prefix of cache keys, so narrations under project rules are cached apart
+func (hn *HybridNarrator) cacheScope() string {
+	hn.narratorsMu.RLock()
+	defer hn.narratorsMu.RUnlock()
+	if hn.projectConfig == nil {
+		return ""
+	}
+	return fmt.Sprintf("%p:", hn.projectConfig)
+}
+
+// SetConfig replaces the rule-based narrator with one built from config and clears the cache
+func (hn *HybridNarrator) SetConfig(config *NarratorConfig) {
+	hn.narratorsMu.Lock()
+	narrators := make([]Narrator, len(hn.narrators))
+	copy(narrators, hn.narrators)
+	for i, n := range narrators {
+		if _, ok := n.(*RuleBasedNarrator); ok {
+			narrators[i] = NewRuleBasedNarratorWithLanguage(config, hn.language)
+		}
+	}
+	// Rebuild the AI narrator only when its providers changed, so provider health survives reloads
+	if hn.useAI && !reflect.DeepEqual(config.AIProviders, hn.aiProviders) {
+		var rebuilt []Narrator
+		for _, n := range narrators {
+			if _, ok := n.(*AINarrator); !ok {
+				rebuilt = append(rebuilt, n)
+			}
+		}
+		if aiNarrator := hn.newAINarrator(config); aiNarrator != nil {
+			rebuilt = append(rebuilt, aiNarrator)
+		}
+		narrators = rebuilt
+	}
+	hn.narrators = narrators
+	hn.narratorsMu.Unlock()
+
+	hn.cacheMu.Lock()
+	hn.cache = make(map[string]string)
+	hn.cacheTime = make(map[string]time.Time)
+	hn.cacheMu.Unlock()
+}
+
 // NarrateToolUse converts tool usage to natural Japanese
 func (hn *HybridNarrator) NarrateToolUse(toolName string, input map[string]interface{}) (string, bool) {
 	// Create cache key - for Bash tool, use command; otherwise use sorted input keys
@@ -74,6 +228,7 @@ func (hn *HybridNarrator) NarrateToolUse(toolName string, input map[string]inter
 		}
 		cacheKey = fmt.Sprintf("%s:%s", toolName, strings.Join(keys, ","))
 	}
+	cacheKey = hn.cacheScope() + cacheKey
 
 	// Check cache first
 	hn.cacheMu.RLock()
@@ -88,7 +243,7 @@ func (hn *HybridNarrator) NarrateToolUse(toolName string, input map[string]inter
 	hn.cacheMu.RUnlock()
 
 	// Try each narrator in sequence
-	for i, narrator := range hn.narrators {
+	for i, narrator := range hn.chain() {
 		narration, shouldFallback := narrator.NarrateToolUse(toolName, input)
 		if !shouldFallback {
 			// Cache the result only if not the first narrator
@@ -103,13 +258,24 @@ func (hn *HybridNarrator) NarrateToolUse(toolName string, input map[string]inter
 	}
 
 	// Generic fallback - return a simple message
-	return fmt.Sprintf("%sを実行中...", toolName), false
+	return localize(hn.language, fmt.Sprintf("%sを実行中...", toolName), fmt.Sprintf("Running %s...", toolName)), false
+}
+
+// ToolVerbosity returns how much of a tool is narrated on each output, under the
+// rules of the current project's override or the global rules
+func (hn *HybridNarrator) ToolVerbosity(toolName string) ToolVerbosity {
+	for _, n := range hn.chain() {
+		if rules, ok := n.(*RuleBasedNarrator); ok {
+			return rules.ToolVerbosity(toolName)
+		}
+	}
+	return DetailedVerbosity
 }
 
 // NarrateToolUsePermission narrates a tool permission request
 func (hn *HybridNarrator) NarrateToolUsePermission(toolName string) (string, bool) {
 	// Check cache first
-	cacheKey := fmt.Sprintf("permission:%s", toolName)
+	cacheKey := hn.cacheScope() + fmt.Sprintf("permission:%s", toolName)
 	hn.cacheMu.RLock()
 	if cached, ok := hn.cache[cacheKey]; ok {
 		if cacheTime, ok := hn.cacheTime[cacheKey]; ok {
@@ -122,7 +288,7 @@ func (hn *HybridNarrator) NarrateToolUsePermission(toolName string) (string, boo
 	hn.cacheMu.RUnlock()
 
 	// Try each narrator in sequence
-	for _, narrator := range hn.narrators {
+	for _, narrator := range hn.chain() {
 		narration, shouldFallback := narrator.NarrateToolUsePermission(toolName)
 		if !shouldFallback {
 			// Cache the result
@@ -134,33 +300,92 @@ func (hn *HybridNarrator) NarrateToolUsePermission(toolName string) (string, boo
 		}
 	}
 	// Fallback
-	return fmt.Sprintf("%sの使用許可を求めています", toolName), false
+	return localize(hn.language, fmt.Sprintf("%sの使用許可を求めています", toolName), fmt.Sprintf("Requesting permission to use %s", toolName)), false
+}
+
+// SetThinkingPolicy sets how thinking blocks are narrated. It must be called before narrating.
+func (hn *HybridNarrator) SetThinkingPolicy(p ThinkingPolicy) {
+	hn.thinking = p
+}
+
+// narrateThinking narrates a thinking block under a policy other than full: nothing
+// when skipped and the thinking message when summarized. It reports false when the
+// thinking block is narrated in full instead.
+func (hn *HybridNarrator) narrateThinking() (string, bool) {
+	switch hn.thinking {
+	case ThinkingSkip:
+		return "", true
+	case ThinkingSummary:
+		for _, n := range hn.chain() {
+			if rules, ok := n.(*RuleBasedNarrator); ok {
+				return rules.message(func(m MessageTemplates) string { return m.Thinking }), true
+			}
+		}
+		return localize(hn.language, "考え中です…", "Thinking…"), true
+	}
+	return "", false
 }
 
 // NarrateText returns the text as-is
 func (hn *HybridNarrator) NarrateText(text string, isThinking bool) (string, bool) {
+	if isThinking {
+		if narration, ok := hn.narrateThinking(); ok {
+			return narration, false
+		}
+	}
+
 	// Try each narrator in sequence with first line only
-	for _, narrator := range hn.narrators {
+	for _, narrator := range hn.chain() {
 		narration, shouldFallback := narrator.NarrateText(text, isThinking)
 		if !shouldFallback {
 			return narration, false
 		}
 	}
 
-	// Extract first line for narration
-	firstLine := text
-	if idx := strings.IndexByte(text, '\n'); idx != -1 {
-		firstLine = text[:idx]
+	// Default behavior - return first line as-is
+	return firstLine(text), false
+}
+
+// NarrateTextStream narrates text like NarrateText, streaming from narrators that support it
+func (hn *HybridNarrator) NarrateTextStream(text string, isThinking bool, onSentence func(string)) (string, bool) {
+	if isThinking {
+		if narration, ok := hn.narrateThinking(); ok {
+			onSentence(narration)
+			return narration, false
+		}
 	}
 
-	// Default behavior - return first line as-is
-	return firstLine, false
+	for _, narrator := range hn.chain() {
+		sn, ok := narrator.(StreamingNarrator)
+		if !ok {
+			if narration, shouldFallback := narrator.NarrateText(text, isThinking); !shouldFallback {
+				onSentence(narration)
+				return narration, false
+			}
+			continue
+		}
+		if narration, shouldFallback := sn.NarrateTextStream(text, isThinking, onSentence); !shouldFallback {
+			return narration, false
+		}
+	}
+
+	narration := firstLine(text)
+	onSentence(narration)
+	return narration, false
+}
+
+// firstLine returns the first line of text
+func firstLine(text string) string {
+	if idx := strings.IndexByte(text, '\n'); idx != -1 {
+		return text[:idx]
+	}
+	return text
 }
 
 // NarrateNotification narrates notification events
 func (hn *HybridNarrator) NarrateNotification(notificationType NotificationType) (string, bool) {
 	// Try each narrator in sequence
-	for _, narrator := range hn.narrators {
+	for _, narrator := range hn.chain() {
 		narration, shouldFallback := narrator.NarrateNotification(notificationType)
 		if !shouldFallback {
 			return narration, false
@@ -173,25 +398,116 @@ func (hn *HybridNarrator) NarrateNotification(notificationType NotificationType)
 // NarrateTaskCompletion narrates task completion events
 func (hn *HybridNarrator) NarrateTaskCompletion(description string, subagentType string) (string, bool) {
 	// Try each narrator in sequence
-	for _, narrator := range hn.narrators {
+	for _, narrator := range hn.chain() {
 		narration, shouldFallback := narrator.NarrateTaskCompletion(description, subagentType)
 		if !shouldFallback {
 			return narration, false
 		}
 	}
 	// Fallback
-	return "タスクが完了しました", false
+	return localize(hn.language, "タスクが完了しました", "Task completed"), false
 }
 
 // NarrateAPIError narrates an API error
 func (hn *HybridNarrator) NarrateAPIError(statusCode int, errorType string, message string) (string, bool) {
 	// Try each narrator in sequence
-	for _, narrator := range hn.narrators {
+	for _, narrator := range hn.chain() {
 		narration, shouldFallback := narrator.NarrateAPIError(statusCode, errorType, message)
 		if !shouldFallback {
 			return narration, false
 		}
 	}
 	// Fallback
-	return fmt.Sprintf("APIエラー %d: %s", statusCode, message), false
+	return localize(hn.language, fmt.Sprintf("APIエラー %d: %s", statusCode, message), fmt.Sprintf("API error %d: %s", statusCode, message)), false
+}
+
+// NarrateToolSLABreach narrates a tool that took longer than its expected maximum duration
+func (hn *HybridNarrator) NarrateToolSLABreach(toolName string, elapsed, limit time.Duration) (string, bool) {
+	// Try each narrator in sequence
+	for _, narrator := range hn.chain() {
+		narration, shouldFallback := narrator.NarrateToolSLABreach(toolName, elapsed, limit)
+		if !shouldFallback {
+			return narration, false
+		}
+	}
+	// Fallback
+	return localize(hn.language, fmt.Sprintf("%sが想定より時間がかかっています", toolName), fmt.Sprintf("%s is taking longer than expected", toolName)), false
+}
+
+// NarrateToolDuration narrates how long a finished tool ran
+func (hn *HybridNarrator) NarrateToolDuration(toolName string, elapsed time.Duration) (string, bool) {
+	// Try each narrator in sequence
+	for _, narrator := range hn.chain() {
+		narration, shouldFallback := narrator.NarrateToolDuration(toolName, elapsed)
+		if !shouldFallback {
+			return narration, false
+		}
+	}
+	// Fallback
+	return localize(hn.language, fmt.Sprintf("%sが完了しました", toolName), fmt.Sprintf("%s finished", toolName)), false
+}
+
+// NarrateSidechainSummary narrates what a Task subagent did
+func (hn *HybridNarrator) NarrateSidechainSummary(subagentType string, toolUses int, elapsed time.Duration) (string, bool) {
+	// Try each narrator in sequence
+	for _, narrator := range hn.chain() {
+		narration, shouldFallback := narrator.NarrateSidechainSummary(subagentType, toolUses, elapsed)
+		if !shouldFallback {
+			return narration, false
+		}
+	}
+	// Fallback
+	return localize(hn.language, fmt.Sprintf("%s agentがツールを%d回使いました", subagentType, toolUses), fmt.Sprintf("The %s agent used %d tools", subagentType, toolUses)), false
+}
+
+// NarrateSessionSummary narrates what a session did when it ended
+func (hn *HybridNarrator) NarrateSessionSummary(summary SessionSummary) (string, bool) {
+	// Try each narrator in sequence
+	for _, narrator := range hn.chain() {
+		narration, shouldFallback := narrator.NarrateSessionSummary(summary)
+		if !shouldFallback {
+			return narration, false
+		}
+	}
+	// Fallback
+	return localize(hn.language, "セッションが終わりました", "The session ended"), false
+}
+
+// NarrateBudgetAlert narrates usage that crossed a threshold of its budget
+func (hn *HybridNarrator) NarrateBudgetAlert(alert BudgetAlert) (string, bool) {
+	// Try each narrator in sequence
+	for _, narrator := range hn.chain() {
+		narration, shouldFallback := narrator.NarrateBudgetAlert(alert)
+		if !shouldFallback {
+			return narration, false
+		}
+	}
+	// Fallback
+	return localize(hn.language, fmt.Sprintf("予算の%d%%に達しました", alert.Percent), fmt.Sprintf("%d%% of the budget has been used", alert.Percent)), false
+}
+
+// NarrateContextAlert narrates a context that crossed a threshold of its window
+func (hn *HybridNarrator) NarrateContextAlert(threshold, percent int) (string, bool) {
+	// Try each narrator in sequence
+	for _, narrator := range hn.chain() {
+		narration, shouldFallback := narrator.NarrateContextAlert(threshold, percent)
+		if !shouldFallback {
+			return narration, false
+		}
+	}
+	// Fallback
+	return localize(hn.language, fmt.Sprintf("コンテキストの使用量が%d%%になりました", percent), fmt.Sprintf("The context is %d%% full", percent)), false
+}
+
+// NarrateSecurityAlert narrates a risky tool use flagged by a security rule
+func (hn *HybridNarrator) NarrateSecurityAlert(alert SecurityAlert) (string, bool) {
+	// Try each narrator in sequence
+	for _, narrator := range hn.chain() {
+		narration, shouldFallback := narrator.NarrateSecurityAlert(alert)
+		if !shouldFallback {
+			return narration, false
+		}
+	}
+	// Fallback
+	return localize(hn.language, fmt.Sprintf("警告です。%sで危険な操作を検出しました", alert.ToolName), fmt.Sprintf("Warning: risky operation in %s", alert.ToolName)), false
 }
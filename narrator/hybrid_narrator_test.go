@@ -2,6 +2,7 @@ package narrator
 
 import (
 	"testing"
+	"time"
 )
 
 // mockAINarrator is a mock implementation of AI narrator for testing
@@ -36,6 +37,34 @@ func (m *mockAINarrator) NarrateAPIError(statusCode int, errorType string, messa
 	return "", false
 }
 
+func (m *mockAINarrator) NarrateToolSLABreach(toolName string, elapsed, limit time.Duration) (string, bool) {
+	return "", false
+}
+
+func (m *mockAINarrator) NarrateToolDuration(toolName string, elapsed time.Duration) (string, bool) {
+	return "", false
+}
+
+func (m *mockAINarrator) NarrateSidechainSummary(subagentType string, toolUses int, elapsed time.Duration) (string, bool) {
+	return "", false
+}
+
+func (m *mockAINarrator) NarrateSessionSummary(summary SessionSummary) (string, bool) {
+	return "", false
+}
+
+func (m *mockAINarrator) NarrateBudgetAlert(alert BudgetAlert) (string, bool) {
+	return "", false
+}
+
+func (m *mockAINarrator) NarrateContextAlert(threshold, percent int) (string, bool) {
+	return "", false
+}
+
+func (m *mockAINarrator) NarrateSecurityAlert(alert SecurityAlert) (string, bool) {
+	return "", false
+}
+
 func TestHybridNarrator_NarrateToolUse(t *testing.T) {
 	// Define test cases that will be tested under different AI configurations
 	testCases := []struct {
@@ -203,3 +232,54 @@ func TestHybridNarrator_NarrateToolUse(t *testing.T) {
 		}
 	})
 }
+
+func TestHybridNarrator_DefaultProvider(t *testing.T) {
+	provider := &fakeProvider{name: "anthropic(test)"}
+
+	tests := []struct {
+		name         string
+		apiKey       string
+		provider     Provider
+		useAI        bool
+		wantProvider string // Empty if no AI narrator is expected
+	}{
+		{name: "default provider", provider: provider, useAI: true, wantProvider: "anthropic(test)"},
+		{name: "default provider wins over OpenAI key", apiKey: "sk-test", provider: provider, useAI: true, wantProvider: "anthropic(test)"},
+		{name: "OpenAI without provider", apiKey: "sk-test", useAI: true, wantProvider: "openai(" + OpenAINarratorModel() + ")"},
+		{name: "AI disabled", provider: provider},
+		{name: "no provider and no key", useAI: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			hn := NewHybridNarratorWithProvider(tt.apiKey, tt.provider, tt.useAI, nil, LanguageJapanese)
+			var got string
+			for _, n := range hn.chain() {
+				if ai, ok := n.(*AINarrator); ok {
+					got = ai.provider.Name()
+				}
+			}
+			if got != tt.wantProvider {
+				t.Errorf("AI narrator provider = %q, want %q", got, tt.wantProvider)
+			}
+		})
+	}
+}
+
+func TestHybridNarrator_ProjectOverrides(t *testing.T) {
+	hn := NewHybridNarrator("", false)
+	input := map[string]interface{}{"pattern": "*.go"}
+	global, _ := hn.NarrateToolUse("Glob", input)
+
+	hn.SetProjectOverrides(&ProjectOverrides{Config: &NarratorConfig{
+		Rules: map[string]ToolRules{"Glob": {Default: "プロジェクトのファイルを探します"}},
+	}})
+	if got, _ := hn.NarrateToolUse("Glob", input); got != "プロジェクトのファイルを探します" {
+		t.Errorf("NarrateToolUse() with project rules = %q", got)
+	}
+
+	hn.SetProjectOverrides(nil)
+	if got, _ := hn.NarrateToolUse("Glob", input); got != global {
+		t.Errorf("NarrateToolUse() after clearing the overrides = %q, want %q", got, global)
+	}
+}
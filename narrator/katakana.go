@@ -0,0 +1,458 @@
+package narrator
+
+import (
+	"regexp"
+	"strings"
+	"unicode"
+)
+
+// englishWordPattern matches runs of ASCII letters left after the normalizer's replacements
+var englishWordPattern = regexp.MustCompile(`[A-Za-z]+`)
+
+// katakanaWords holds readings of common words the spelling rules get wrong
+var katakanaWords = map[string]string{
+	"a": "ア", "about": "アバウト", "add": "アド", "all": "オール", "and": "アンド", "app": "アプリ",
+	"are": "アー", "argument": "アーギュメント", "array": "アレイ", "as": "アズ", "async": "アシンク",
+	"at": "アット", "await": "アウェイト", "bash": "バッシュ", "be": "ビー", "branch": "ブランチ",
+	"bug": "バグ", "but": "バット", "button": "ボタン", "by": "バイ", "cache": "キャッシュ",
+	"callback": "コールバック", "can": "キャン", "change": "チェンジ", "class": "クラス",
+	"claude": "クロード", "client": "クライアント", "column": "カラム", "command": "コマンド",
+	"component": "コンポーネント", "config": "コンフィグ", "context": "コンテキスト", "could": "クッド",
+	"create": "クリエイト", "data": "データ", "database": "データベース", "debug": "デバッグ",
+	"default": "デフォルト", "delete": "デリート", "deploy": "デプロイ", "directory": "ディレクトリ",
+	"do": "ドゥー", "done": "ダン", "edit": "エディット", "email": "イーメール", "error": "エラー",
+	"event": "イベント", "export": "エクスポート", "fail": "フェイル", "failed": "フェイルド",
+	"false": "フォールス", "feature": "フィーチャー", "folder": "フォルダ", "form": "フォーム",
+	"from": "フロム", "function": "ファンクション", "go": "ゴー", "handler": "ハンドラー", "has": "ハズ",
+	"have": "ハブ", "here": "ヒア", "hook": "フック", "how": "ハウ", "if": "イフ", "image": "イメージ",
+	"import": "インポート", "index": "インデックス", "info": "インフォ", "input": "インプット",
+	"install": "インストール", "into": "イントゥー", "is": "イズ", "issue": "イシュー", "it": "イット",
+	"java": "ジャバ", "key": "キー", "let": "レット", "linux": "リナックス", "list": "リスト",
+	"local": "ローカル", "log": "ログ", "login": "ログイン", "logout": "ログアウト", "look": "ルック",
+	"loop": "ループ", "main": "メイン", "make": "メイク", "master": "マスター", "me": "ミー",
+	"merge": "マージ", "message": "メッセージ", "method": "メソッド", "model": "モデル",
+	"module": "モジュール", "more": "モア", "my": "マイ", "name": "ネーム", "network": "ネットワーク",
+	"new": "ニュー", "nil": "ニル", "no": "ノー", "node": "ノード", "now": "ナウ", "null": "ヌル",
+	"object": "オブジェクト", "of": "オブ", "ok": "オーケー", "okay": "オーケー", "one": "ワン",
+	"option": "オプション", "or": "オア", "output": "アウトプット", "package": "パッケージ",
+	"page": "ページ", "parameter": "パラメーター", "password": "パスワード", "path": "パス",
+	"profile": "プロファイル", "promise": "プロミス", "pull": "プル", "push": "プッシュ",
+	"python": "パイソン", "query": "クエリ", "react": "リアクト", "read": "リード", "release": "リリース",
+	"remove": "リムーブ", "request": "リクエスト", "response": "レスポンス", "retry": "リトライ",
+	"return": "リターン", "review": "レビュー", "route": "ルート", "router": "ルーター", "run": "ラン",
+	"rust": "ラスト", "search": "サーチ", "secret": "シークレット", "security": "セキュリティ",
+	"see": "シー", "service": "サービス", "session": "セッション", "setup": "セットアップ",
+	"should": "シュッド", "site": "サイト", "so": "ソー", "some": "サム", "state": "ステート",
+	"status": "ステータス", "string": "ストリング", "style": "スタイル", "success": "サクセス",
+	"table": "テーブル", "task": "タスク", "the": "ザ", "theme": "テーマ", "there": "ゼア",
+	"this": "ディス", "timeout": "タイムアウト", "to": "トゥー", "token": "トークン", "tool": "ツール",
+	"true": "トゥルー", "two": "ツー", "type": "タイプ", "update": "アップデート", "use": "ユーズ",
+	"user": "ユーザー", "value": "バリュー", "variable": "バリアブル", "version": "バージョン",
+	"view": "ビュー", "vue": "ビュー", "warning": "ワーニング", "was": "ワズ", "we": "ウィー",
+	"web": "ウェブ", "what": "ワット", "when": "ウェン", "where": "ウェア", "which": "ウィッチ",
+	"why": "ワイ", "will": "ウィル", "windows": "ウィンドウズ", "with": "ウィズ", "would": "ウッド",
+	"write": "ライト", "yes": "イエス", "you": "ユー",
+}
+
+// katakanaRows maps a consonant sound to its kana for the vowels a, i, u, e and o
+var katakanaRows = map[string][5]string{
+	"":   {"ア", "イ", "ウ", "エ", "オ"},
+	"k":  {"カ", "キ", "ク", "ケ", "コ"},
+	"g":  {"ガ", "ギ", "グ", "ゲ", "ゴ"},
+	"s":  {"サ", "シ", "ス", "セ", "ソ"},
+	"z":  {"ザ", "ジ", "ズ", "ゼ", "ゾ"},
+	"t":  {"タ", "ティ", "トゥ", "テ", "ト"},
+	"d":  {"ダ", "ディ", "ドゥ", "デ", "ド"},
+	"n":  {"ナ", "ニ", "ヌ", "ネ", "ノ"},
+	"h":  {"ハ", "ヒ", "フ", "ヘ", "ホ"},
+	"b":  {"バ", "ビ", "ブ", "ベ", "ボ"},
+	"p":  {"パ", "ピ", "プ", "ペ", "ポ"},
+	"m":  {"マ", "ミ", "ム", "メ", "モ"},
+	"y":  {"ヤ", "イ", "ユ", "イェ", "ヨ"},
+	"r":  {"ラ", "リ", "ル", "レ", "ロ"},
+	"l":  {"ラ", "リ", "ル", "レ", "ロ"},
+	"w":  {"ワ", "ウィ", "ウ", "ウェ", "ウォ"},
+	"f":  {"ファ", "フィ", "フ", "フェ", "フォ"},
+	"v":  {"バ", "ビ", "ブ", "ベ", "ボ"},
+	"sh": {"シャ", "シ", "シュ", "シェ", "ショ"},
+	"ch": {"チャ", "チ", "チュ", "チェ", "チョ"},
+	"j":  {"ジャ", "ジ", "ジュ", "ジェ", "ジョ"},
+	"th": {"サ", "シ", "ス", "セ", "ソ"},
+	"kw": {"クア", "クイ", "クウ", "クエ", "クオ"},
+}
+
+// bareKatakana is the reading of a consonant not followed by a vowel
+var bareKatakana = map[string]string{
+	"t": "ト", "d": "ド", "ch": "チ", "j": "ジ", "sh": "シュ", "n": "ン", "h": "",
+	"w": "ウ", "y": "イ", "ng": "ング", "kw": "ク",
+}
+
+// kanaUnit is a consonant, a vowel or a fixed reading in a word being converted
+type kanaUnit struct {
+	cons    string // Consonant sound; empty for a vowel
+	vowel   int    // Index of the vowel in katakanaRows, or -1 for a consonant
+	ext     string // Appended after a vowel, such as ー for a long vowel
+	short   bool   // Short vowel written with a single letter
+	yu      bool   // Long u read as yu
+	gem     bool   // Consonant doubled before a following consonant, as in tt or ck
+	literal string // Fixed reading of a suffix such as -tion
+}
+
+// replaceEnglishWords rewrites English words in text as katakana so that VOICEVOX
+// reads them instead of spelling them out. Acronyms are left for VOICEVOX to spell.
+func replaceEnglishWords(text string) string {
+	return englishWordPattern.ReplaceAllStringFunc(text, func(word string) string {
+		var b strings.Builder
+		for _, part := range splitCamelCase(word) {
+			if len(part) < 2 || strings.ToUpper(part) == part {
+				b.WriteString(part)
+				continue
+			}
+			b.WriteString(englishToKatakana(part))
+		}
+		return b.String()
+	})
+}
+
+// splitCamelCase splits a word such as GetUserProfile into its parts
+func splitCamelCase(word string) []string {
+	var parts []string
+	start := 0
+	for i := 1; i < len(word); i++ {
+		if unicode.IsLower(rune(word[i-1])) && unicode.IsUpper(rune(word[i])) {
+			parts = append(parts, word[start:i])
+			start = i
+		}
+	}
+	return append(parts, word[start:])
+}
+
+// englishToKatakana converts an English word to katakana, from the dictionary or by
+// spelling rules that approximate how loanwords are usually written
+func englishToKatakana(word string) string {
+	w := strings.ToLower(word)
+	if reading, ok := katakanaWords[w]; ok {
+		return reading
+	}
+	// Plurals of dictionary words, as in tools and tasks
+	if stem := strings.TrimSuffix(w, "s"); stem != w {
+		if reading, ok := katakanaWords[stem]; ok {
+			if strings.ContainsRune("kptf", rune(stem[len(stem)-1])) {
+				return reading + "ス"
+			}
+			return reading + "ズ"
+		}
+	}
+	return renderKatakana(spellUnits(w))
+}
+
+// isVowelLetter reports whether c is a vowel letter
+func isVowelLetter(c byte) bool {
+	return strings.IndexByte("aeiou", c) >= 0
+}
+
+// spellUnits splits a lowercase word into consonants and vowels by spelling rules
+func spellUnits(w string) []kanaUnit {
+	var units []kanaUnit
+	consonant := func(cons string, gem bool) {
+		units = append(units, kanaUnit{cons: cons, vowel: -1, gem: gem})
+	}
+	vowel := func(v int, ext string, short bool) *kanaUnit {
+		units = append(units, kanaUnit{vowel: v, ext: ext, short: short})
+		return &units[len(units)-1]
+	}
+	at := func(i int) byte {
+		if i < len(w) {
+			return w[i]
+		}
+		return 0
+	}
+	silent := make(map[int]bool)
+
+	for i := 0; i < len(w); {
+		if silent[i] {
+			i++
+			continue
+		}
+		rest := w[i:]
+		c := w[i]
+
+		// Suffixes with fixed readings
+		switch {
+		case strings.HasPrefix(rest, "tion"):
+			units = append(units, kanaUnit{vowel: -1, literal: "ション"})
+			i += 4
+			continue
+		case strings.HasPrefix(rest, "sion"):
+			units = append(units, kanaUnit{vowel: -1, literal: "ジョン"})
+			i += 4
+			continue
+		case strings.HasPrefix(rest, "ture"):
+			units = append(units, kanaUnit{vowel: -1, literal: "チャー"})
+			i += 4
+			continue
+		case rest == "ing" || rest == "ings":
+			vowel(1, "ング", false)
+			i = len(w)
+			continue
+		case rest == "ous":
+			vowel(0, "ス", false)
+			i = len(w)
+			continue
+		}
+
+		if isVowelLetter(c) || (c == 'y' && i > 0 && !isVowelLetter(at(i+1))) {
+			i += spellVowel(w, i, vowel, units, silent)
+			continue
+		}
+
+		// Consonants
+		switch {
+		case strings.HasPrefix(rest, "tch"):
+			consonant("ch", true)
+			i += 3
+		case strings.HasPrefix(rest, "dge"):
+			consonant("j", true)
+			i += 3
+		case strings.HasPrefix(rest, "ck"):
+			consonant("k", true)
+			i += 2
+		case strings.HasPrefix(rest, "sh"), strings.HasPrefix(rest, "ch"), strings.HasPrefix(rest, "th"):
+			consonant(rest[:2], false)
+			i += 2
+		case strings.HasPrefix(rest, "ph"):
+			consonant("f", false)
+			i += 2
+		case strings.HasPrefix(rest, "wh"):
+			consonant("w", false)
+			i += 2
+		case strings.HasPrefix(rest, "qu"):
+			consonant("kw", false)
+			i += 2
+		case strings.HasPrefix(rest, "gh"):
+			i += 2
+		case strings.HasPrefix(rest, "ng") && !isVowelLetter(at(i+2)):
+			consonant("ng", false)
+			i += 2
+		case c == 'x':
+			// A final x is doubled as in fix, but not inside a word as in next
+			consonant("k", i == len(w)-1)
+			consonant("s", false)
+			i++
+		case c == 'c':
+			switch next, after := at(i+1), at(i+2); {
+			case next == 'e' || next == 'i' || next == 'y':
+				consonant("s", false)
+				i++
+			case next == 'c' && (after == 'e' || after == 'i'):
+				// The second c of access reads s
+				consonant("k", false)
+				i++
+			case next == 'c':
+				consonant("k", true)
+				i += 2
+			default:
+				consonant("k", false)
+				i++
+			}
+		case c == 'q':
+			consonant("k", false)
+			i++
+		case !unicode.IsLetter(rune(c)):
+			i++
+		default:
+			doubled := at(i+1) == c
+			consonant(string(c), doubled)
+			i++
+			if doubled {
+				i++
+			}
+		}
+	}
+	return units
+}
+
+// spellVowel appends the vowel starting at w[i] and returns the number of letters it takes
+func spellVowel(w string, i int, vowel func(v int, ext string, short bool) *kanaUnit, units []kanaUnit, silent map[int]bool) int {
+	at := func(i int) byte {
+		if i < len(w) {
+			return w[i]
+		}
+		return 0
+	}
+	rest := w[i:]
+	c := w[i]
+
+	// Vowel digraphs
+	for _, d := range []struct {
+		spelling string
+		v        int
+		ext      string
+	}{
+		{"igh", 0, "イ"}, {"ee", 1, "ー"}, {"ea", 1, "ー"}, {"oo", 2, "ー"}, {"ou", 0, "ウ"},
+		{"ow", 0, "ウ"}, {"ai", 3, "イ"}, {"ay", 3, "イ"}, {"oa", 4, "ー"}, {"oi", 4, "イ"},
+		{"oy", 4, "イ"}, {"au", 4, "ー"}, {"aw", 4, "ー"}, {"ie", 1, "ー"}, {"ei", 3, "イ"},
+		{"ey", 3, "イ"}, {"ue", 2, "ー"}, {"ew", 2, "ー"},
+	} {
+		if strings.HasPrefix(rest, d.spelling) {
+			vowel(d.v, d.ext, false)
+			return len(d.spelling)
+		}
+	}
+
+	// A silent final e, as in table or files
+	if c == 'e' && hasVowelUnit(units) && (i == len(w)-1 || ((at(i+1) == 's' || at(i+1) == 'd') && i+2 == len(w))) {
+		return 1
+	}
+	// A lone final e is long, as in she
+	if c == 'e' && i == len(w)-1 {
+		vowel(1, "ー", false)
+		return 1
+	}
+
+	// y as a vowel: long at the end, as in query, and short inside, as in system
+	if c == 'y' {
+		if i == len(w)-1 {
+			vowel(1, "ー", false)
+		} else {
+			vowel(1, "", true)
+		}
+		return 1
+	}
+
+	// R-colored vowels, as in server and port
+	if at(i+1) == 'r' && at(i+2) != 'r' && !isVowelLetter(at(i+2)) {
+		if c == 'o' {
+			vowel(4, "ー", false)
+		} else {
+			vowel(0, "ー", false)
+		}
+		return 2
+	}
+
+	// Magic e, as in file and code: a vowel, one consonant and a silent final e
+	if next := at(i + 1); next != 0 && !isVowelLetter(next) && next != 'r' && next != 'w' && next != 'x' && next != 'y' && at(i+2) == 'e' &&
+		(i+3 == len(w) || ((at(i+3) == 's' || at(i+3) == 'd') && i+4 == len(w))) {
+		silent[i+2] = true
+		switch c {
+		case 'a':
+			vowel(3, "イ", false)
+		case 'e':
+			vowel(1, "ー", false)
+		case 'i':
+			vowel(0, "イ", false)
+		case 'o':
+			vowel(4, "ー", false)
+		case 'u':
+			vowel(2, "ー", false).yu = true
+		}
+		return 1
+	}
+
+	switch c {
+	case 'a':
+		vowel(0, "", true)
+	case 'e':
+		vowel(3, "", true)
+	case 'i':
+		vowel(1, "", true)
+	case 'o':
+		vowel(4, "", true)
+	case 'u':
+		// u starting a word before a single consonant reads yu, as in unit
+		if i == 0 && !isVowelLetter(at(1)) && isVowelLetter(at(2)) {
+			vowel(2, "ー", false).yu = true
+		} else {
+			vowel(0, "", true)
+		}
+	}
+	return 1
+}
+
+// hasVowelUnit reports whether units has a vowel
+func hasVowelUnit(units []kanaUnit) bool {
+	for _, u := range units {
+		if u.vowel >= 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// isGeminating reports whether a consonant is preceded by a small tsu after a short vowel
+func isGeminating(cons string) bool {
+	switch cons {
+	case "k", "p", "t", "ch", "sh", "j":
+		return true
+	}
+	return false
+}
+
+// renderKatakana writes the units as katakana
+func renderKatakana(units []kanaUnit) string {
+	var b strings.Builder
+	for i := 0; i < len(units); i++ {
+		u := units[i]
+		if u.literal != "" {
+			b.WriteString(u.literal)
+			continue
+		}
+		if u.vowel >= 0 {
+			b.WriteString(vowelKatakana("", u))
+			continue
+		}
+
+		// A consonant followed by a vowel makes one kana
+		if i+1 < len(units) && units[i+1].vowel >= 0 && units[i+1].literal == "" && u.cons != "ng" {
+			b.WriteString(vowelKatakana(u.cons, units[i+1]))
+			i++
+			continue
+		}
+
+		// A bare consonant takes a default vowel; a hard consonant after a short vowel
+		// at the end of a word or doubled is preceded by a small tsu, as in check
+		final := i+1 == len(units)
+		if i > 0 && units[i-1].short && (final || u.gem) && isGeminating(u.cons) {
+			b.WriteString("ッ")
+		}
+		if u.cons == "m" && !final && (units[i+1].cons == "b" || units[i+1].cons == "p") {
+			b.WriteString("ン")
+			continue
+		}
+		if reading, ok := bareKatakana[u.cons]; ok {
+			b.WriteString(reading)
+			continue
+		}
+		if u.cons == "s" && final && i > 0 && !units[i-1].short && units[i-1].cons != "k" && units[i-1].cons != "t" && units[i-1].cons != "p" {
+			b.WriteString("ズ")
+			continue
+		}
+		if row, ok := katakanaRows[u.cons]; ok {
+			b.WriteString(row[2])
+		}
+	}
+	return b.String()
+}
+
+// vowelKatakana reads a vowel after a consonant
+func vowelKatakana(cons string, u kanaUnit) string {
+	row, ok := katakanaRows[cons]
+	if !ok {
+		row = katakanaRows[""]
+	}
+	if u.yu {
+		switch cons {
+		case "":
+			return "ユー"
+		case "r", "l", "j", "ch", "sh":
+			return row[2] + "ー"
+		case "t":
+			return "チュー"
+		case "d":
+			return "デュー"
+		default:
+			return strings.TrimSuffix(row[1], "ィ") + "ュー"
+		}
+	}
+	return row[u.vowel] + u.ext
+}
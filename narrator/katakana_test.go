@@ -0,0 +1,75 @@
+package narrator
+
+import (
+	"testing"
+)
+
+func TestEnglishToKatakana(t *testing.T) {
+	tests := []struct {
+		word     string
+		expected string
+	}{
+		// Dictionary
+		{"user", "ユーザー"},
+		{"Error", "エラー"},
+		{"tools", "ツールズ"},
+		{"tasks", "タスクス"},
+
+		// Spelling rules
+		{"server", "サーバー"},
+		{"file", "ファイル"},
+		{"code", "コード"},
+		{"check", "チェック"},
+		{"fix", "フィックス"},
+		{"next", "ネクスト"},
+		{"simple", "シンプル"},
+		{"system", "システム"},
+		{"testing", "テスティング"},
+		{"action", "アクション"},
+		{"picture", "ピクチャー"},
+		{"light", "ライト"},
+		{"point", "ポイント"},
+		{"start", "スタート"},
+		{"number", "ナンバー"},
+		{"program", "プログラム"},
+		{"project", "プロジェクト"},
+		{"access", "アクセス"},
+		{"edge", "エッジ"},
+		{"cute", "キュート"},
+		{"quick", "クイック"},
+		{"files", "ファイルズ"},
+		{"she", "シー"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.word, func(t *testing.T) {
+			if got := englishToKatakana(tt.word); got != tt.expected {
+				t.Errorf("englishToKatakana(%q) = %q, want %q", tt.word, got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestTextNormalizer_Katakana(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected string
+	}{
+		{name: "words in Japanese text", input: "fix the bugを確認", expected: "フィックス ザ バグを確認"},
+		{name: "CamelCase", input: "GetUserProfileを呼び出し", expected: "ゲットユーザープロファイルを呼び出し"},
+		{name: "acronyms are kept", input: "CLIとSDK", expected: "CLIとSDK"},
+		{name: "after replacements", input: "API server", expected: "エーピーアイ サーバー"},
+		{name: "filename", input: "simple_server.go", expected: "シンプル サーバードットゴー"},
+	}
+
+	normalizer := NewTextNormalizer()
+	normalizer.SetKatakana(true)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := normalizer.Normalize(tt.input); got != tt.expected {
+				t.Errorf("Normalize(%q) = %q, want %q", tt.input, got, tt.expected)
+			}
+		})
+	}
+}
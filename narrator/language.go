@@ -0,0 +1,33 @@
+package narrator
+
+import (
+	"fmt"
+)
+
+// Language represents the language narration is produced in
+type Language string
+
+const (
+	LanguageJapanese Language = "ja"
+	LanguageEnglish  Language = "en"
+)
+
+// ParseLanguage parses a language code
+func ParseLanguage(code string) (Language, error) {
+	switch Language(code) {
+	case "", LanguageJapanese:
+		return LanguageJapanese, nil
+	case LanguageEnglish:
+		return LanguageEnglish, nil
+	default:
+		return "", fmt.Errorf("unsupported language %q (expected ja or en)", code)
+	}
+}
+
+// localize returns the English message for LanguageEnglish and the Japanese message otherwise
+func localize(lang Language, ja, en string) string {
+	if lang == LanguageEnglish {
+		return en
+	}
+	return ja
+}
@@ -0,0 +1,56 @@
+package narrator
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// NarrationLogEntry is a spoken narration in the narration log
+type NarrationLogEntry struct {
+	Time           time.Time `json:"time"` // When playback started
+	Project        string    `json:"project,omitempty"`
+	Session        string    `json:"session,omitempty"`
+	Text           string    `json:"text"`           // Narration as produced, after translation
+	NormalizedText string    `json:"normalizedText"` // Text sent to the synthesizer
+	Duration       float64   `json:"duration"`       // Length of the audio in seconds
+	Speaker        *int      `json:"speaker,omitempty"`
+}
+
+// NarrationLog appends the narrations that were spoken to a JSONL file, to review
+// what the companion said and tune the narrator rules
+type NarrationLog struct {
+	mu   sync.Mutex
+	path string
+}
+
+// NewNarrationLog creates a narration log appending to path, creating its directory if needed
+func NewNarrationLog(path string) (*NarrationLog, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create narration log directory: %w", err)
+	}
+	return &NarrationLog{path: path}, nil
+}
+
+// Append writes an entry to the log
+func (l *NarrationLog) Append(entry NarrationLogEntry) error {
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return fmt.Errorf("failed to encode narration log entry: %w", err)
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	f, err := os.OpenFile(l.path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o644)
+	if err != nil {
+		return fmt.Errorf("failed to open narration log: %w", err)
+	}
+	defer f.Close()
+	if _, err := f.Write(append(data, '\n')); err != nil {
+		return fmt.Errorf("failed to write narration log: %w", err)
+	}
+	return nil
+}
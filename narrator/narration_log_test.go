@@ -0,0 +1,49 @@
+package narrator
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/kazegusuri/claude-companion/speech"
+)
+
+func TestVoiceNarrator_NarrationLog(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "logs", "narrations.jsonl")
+	log, err := NewNarrationLog(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	vn := NewVoiceNarrator(nil, nil, nil, false)
+	defer vn.Close()
+	vn.SetNarrationLog(log)
+	vn.SetSession("-home-me-app", "abc")
+
+	for _, text := range []string{"main.goを編集します", "テストが通りました"} {
+		vn.enqueueNarration(text, PriorityInput{Type: NarrationTypeText})
+		item := vn.queue.Dequeue(context.Background())
+		started := time.Date(2025, 1, 26, 10, 0, 0, 0, time.UTC)
+		vn.logSpoken(*item, &speech.AudioMeta{Duration: 1500 * time.Millisecond}, started)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("got %d lines, want 2:\n%s", len(lines), data)
+	}
+	var entry NarrationLogEntry
+	if err := json.Unmarshal([]byte(lines[0]), &entry); err != nil {
+		t.Fatal(err)
+	}
+	if entry.Project != "-home-me-app" || entry.Session != "abc" || entry.Text != "main.goを編集します" ||
+		entry.NormalizedText == "" || entry.Duration != 1.5 || entry.Time.IsZero() {
+		t.Errorf("entry = %+v", entry)
+	}
+}
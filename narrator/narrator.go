@@ -2,6 +2,7 @@ package narrator
 
 import (
 	"strings"
+	"time"
 )
 
 // NotificationType represents different types of notifications
@@ -23,6 +24,39 @@ type Narrator interface {
 	NarrateNotification(notificationType NotificationType) (string, bool)
 	NarrateTaskCompletion(description string, subagentType string) (string, bool)
 	NarrateAPIError(statusCode int, errorType string, message string) (string, bool)
+	NarrateToolSLABreach(toolName string, elapsed, limit time.Duration) (string, bool)
+	NarrateToolDuration(toolName string, elapsed time.Duration) (string, bool)
+	NarrateSidechainSummary(subagentType string, toolUses int, elapsed time.Duration) (string, bool)
+	NarrateSessionSummary(summary SessionSummary) (string, bool)
+	NarrateBudgetAlert(alert BudgetAlert) (string, bool)
+	NarrateContextAlert(threshold, percent int) (string, bool)
+	NarrateSecurityAlert(alert SecurityAlert) (string, bool)
+}
+
+// SessionSummary is what a session did, narrated when it ends
+type SessionSummary struct {
+	Duration       time.Duration
+	FilesEdited    int
+	Commands       int
+	Tokens         int64
+	TasksCompleted int
+}
+
+// BudgetAlert is usage that crossed a threshold of its token or cost budget
+type BudgetAlert struct {
+	Scope   string  // "session" or "day"
+	Metric  string  // "tokens" or "cost"
+	Used    float64 // Tokens, or estimated cost in USD
+	Limit   float64
+	Percent int // Used as a percentage of Limit
+}
+
+// SecurityAlert is a risky tool use flagged by a security rule
+type SecurityAlert struct {
+	Rule     string // Name of the rule
+	Reason   string // Why the rule flags the tool use
+	ToolName string
+	Target   string // Command or file the tool use acts on
 }
 
 // Helper function to extract domain from URL
@@ -83,3 +117,38 @@ func (n *NoOpNarrator) NarrateTaskCompletion(description string, subagentType st
 func (n *NoOpNarrator) NarrateAPIError(statusCode int, errorType string, message string) (string, bool) {
 	return "", true
 }
+
+// NarrateToolSLABreach returns empty string
+func (n *NoOpNarrator) NarrateToolSLABreach(toolName string, elapsed, limit time.Duration) (string, bool) {
+	return "", false
+}
+
+// NarrateToolDuration returns empty string
+func (n *NoOpNarrator) NarrateToolDuration(toolName string, elapsed time.Duration) (string, bool) {
+	return "", false
+}
+
+// NarrateSidechainSummary returns empty string
+func (n *NoOpNarrator) NarrateSidechainSummary(subagentType string, toolUses int, elapsed time.Duration) (string, bool) {
+	return "", false
+}
+
+// NarrateSessionSummary returns empty string
+func (n *NoOpNarrator) NarrateSessionSummary(summary SessionSummary) (string, bool) {
+	return "", false
+}
+
+// NarrateBudgetAlert returns empty string
+func (n *NoOpNarrator) NarrateBudgetAlert(alert BudgetAlert) (string, bool) {
+	return "", false
+}
+
+// NarrateContextAlert returns empty string
+func (n *NoOpNarrator) NarrateContextAlert(threshold, percent int) (string, bool) {
+	return "", false
+}
+
+// NarrateSecurityAlert returns empty string
+func (n *NoOpNarrator) NarrateSecurityAlert(alert SecurityAlert) (string, bool) {
+	return "", false
+}
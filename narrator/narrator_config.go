@@ -1,21 +1,36 @@
 package narrator
 
 import (
+	"bytes"
 	_ "embed"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
 	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+
+	"gopkg.in/yaml.v3"
 )
 
 //go:embed narrator-rules.json
 var defaultNarratorRulesJSON string
 
+//go:embed narrator-rules-en.json
+var defaultNarratorRulesEnJSON string
+
 // NarratorConfig represents the configuration for narrative rules
 type NarratorConfig struct {
 	Rules         map[string]ToolRules `json:"rules"`
 	Messages      MessageTemplates     `json:"messages"`
-	FileTypeNames map[string]string    `json:"fileTypeNames"` // Extension to file type name mapping
-	MCPRules      map[string]MCPRules  `json:"mcpRules"`      // MCP-specific rules by server name
+	FileTypeNames map[string]string    `json:"fileTypeNames"`         // Extension to file type name mapping
+	MCPRules      map[string]MCPRules  `json:"mcpRules"`              // MCP-specific rules by server name
+	Notifications map[string]string    `json:"notifications"`         // Notification type to message mapping
+	AIProviders   []AIProviderConfig   `json:"aiProviders,omitempty"` // Fallback chain of the AI narrator, in order
+	Glossary      map[string]string    `json:"glossary,omitempty"`    // English term to the Japanese reading used when translating for voice
+	Include       []string             `json:"include,omitempty"`     // Config files merged under this one, relative to it
 }
 
 // ToolRules represents rules for a specific tool
@@ -37,6 +52,10 @@ type ToolRules struct {
 
 	// For configurable input value captures and replacements
 	Captures []CaptureRule `json:"captures,omitempty"`
+
+	// How much of the tool is narrated on each output, and the message of the short level
+	Verbosity *VerbosityRule `json:"verbosity,omitempty"`
+	Short     string         `json:"short,omitempty"`
 }
 
 // PrefixRule represents a prefix-based rule (mainly for Bash commands)
@@ -61,8 +80,9 @@ type CaptureRule struct {
 
 // MCPRules represents rules for a specific MCP server
 type MCPRules struct {
-	Default string               `json:"default"` // Default message for unknown operations
-	Rules   map[string]ToolRules `json:"rules"`   // Operation-specific rules
+	Default   string               `json:"default"`             // Default message for unknown operations
+	Rules     map[string]ToolRules `json:"rules"`               // Operation-specific rules
+	Verbosity *VerbosityRule       `json:"verbosity,omitempty"` // Verbosity of every operation without its own
 }
 
 // MessageTemplates contains general message templates
@@ -74,38 +94,197 @@ type MessageTemplates struct {
 	DirectoryContents       string `json:"directoryContents"`       // For directory listing
 	TodoListUpdate          string `json:"todoListUpdate"`          // For todo list updates
 	GenericToolPermission   string `json:"genericToolPermission"`   // For tool permission requests
+	UnknownFileType         string `json:"unknownFileType"`         // For files with unknown extensions
+	WholeProject            string `json:"wholeProject"`            // For searches without a path
+	AgentTask               string `json:"agentTask"`               // For tasks delegated to a subagent
+
+	TaskCompleted                string `json:"taskCompleted"`                // For completed tasks without description
+	TaskCompletedWithDescription string `json:"taskCompletedWithDescription"` // For completed tasks
+	TaskCompletedByAgent         string `json:"taskCompletedByAgent"`         // For tasks completed by a subagent
+
+	APIErrorOverloaded     string `json:"apiErrorOverloaded"`     // For overloaded errors
+	APIErrorInvalidRequest string `json:"apiErrorInvalidRequest"` // For invalid request errors
+	APIError               string `json:"apiError"`               // For other API errors
+
+	ToolSLABreach string `json:"toolSLABreach"` // For tools that took longer than expected
+	ToolCompleted string `json:"toolCompleted"` // For long tool runs reported by the PostToolUse hook
+
+	SidechainSummary string `json:"sidechainSummary"` // For what a Task subagent did, with --show-sidechains
+	SessionSummary   string `json:"sessionSummary"`   // For what a session did when it ends, with --session-summary
+
+	BudgetSessionTokens string `json:"budgetSessionTokens"` // For sessions reaching a threshold of their token budget
+	BudgetSessionCost   string `json:"budgetSessionCost"`   // For sessions reaching a threshold of their cost budget
+	BudgetDayTokens     string `json:"budgetDayTokens"`     // For days reaching a threshold of their token budget
+	BudgetDayCost       string `json:"budgetDayCost"`       // For days reaching a threshold of their cost budget
+
+	ContextAlert string `json:"contextAlert"` // For contexts reaching a threshold without a context_{threshold} notification
+
+	SecurityAlert string `json:"securityAlert"` // For risky tool uses flagged by a security rule
+
+	Thinking string `json:"thinking"` // For thinking blocks, with --thinking-narration summary
 }
 
-// LoadNarratorConfig loads narrator configuration from a file
+// LoadNarratorConfig loads narrator configuration from a file: JSON, or YAML for .yaml
+// and .yml files. A YAML file can hold several documents, each overriding the ones
+// before it, and a config can list other config files under include, such as the rules
+// of each MCP server in a file of its own; the including config overrides them.
 func LoadNarratorConfig(path string) (*NarratorConfig, error) {
+	return loadNarratorConfig(path, nil)
+}
+
+// loadNarratorConfig loads a config file and its includes; including lists the files
+// being loaded, to detect include cycles
+func loadNarratorConfig(path string, including []string) (*NarratorConfig, error) {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read config file: %w", err)
+	}
+	for _, p := range including {
+		if p == abs {
+			return nil, fmt.Errorf("config file %s includes itself", path)
+		}
+	}
+
 	data, err := os.ReadFile(path)
 	if err != nil {
 		return nil, fmt.Errorf("failed to read config file: %w", err)
 	}
 
-	var config NarratorConfig
-	if err := json.Unmarshal(data, &config); err != nil {
-		return nil, fmt.Errorf("failed to parse config file: %w", err)
+	var docs []*NarratorConfig
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml":
+		docs, err = parseYAMLNarratorConfig(data)
+	default:
+		var config NarratorConfig
+		err = json.Unmarshal(data, &config)
+		docs = []*NarratorConfig{&config}
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse config file %s: %w", path, err)
+	}
+
+	config := &NarratorConfig{}
+	for _, doc := range docs {
+		for _, include := range doc.Include {
+			if !filepath.IsAbs(include) {
+				include = filepath.Join(filepath.Dir(path), include)
+			}
+			included, err := loadNarratorConfig(include, append(including, abs))
+			if err != nil {
+				return nil, fmt.Errorf("failed to include %s in %s: %w", include, path, err)
+			}
+			config.merge(included)
+		}
+		config.merge(doc)
 	}
+	config.Include = nil
+	return config, nil
+}
+
+// parseYAMLNarratorConfig parses the documents of a YAML config
+func parseYAMLNarratorConfig(data []byte) ([]*NarratorConfig, error) {
+	docs, err := yamlDocumentsToJSON(data)
+	if err != nil {
+		return nil, err
+	}
+	configs := make([]*NarratorConfig, len(docs))
+	for i, doc := range docs {
+		configs[i] = &NarratorConfig{}
+		if err := json.Unmarshal(doc, configs[i]); err != nil {
+			return nil, err
+		}
+	}
+	return configs, nil
+}
 
-	return &config, nil
+// yamlDocumentsToJSON converts each non-empty document of a YAML file to JSON, so
+// YAML files are decoded with the same keys as JSON ones
+func yamlDocumentsToJSON(data []byte) ([][]byte, error) {
+	var docs [][]byte
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	for {
+		var doc interface{}
+		if err := dec.Decode(&doc); errors.Is(err, io.EOF) {
+			return docs, nil
+		} else if err != nil {
+			return nil, err
+		}
+		if doc == nil {
+			continue // Empty document
+		}
+		jsonData, err := json.Marshal(doc)
+		if err != nil {
+			return nil, fmt.Errorf("unsupported YAML value: %w", err)
+		}
+		docs = append(docs, jsonData)
+	}
+}
+
+// merge overrides c with what o defines: entries of its maps, its non-empty messages
+// and its AI providers
+func (c *NarratorConfig) merge(o *NarratorConfig) {
+	c.Rules = mergeMap(c.Rules, o.Rules)
+	c.FileTypeNames = mergeMap(c.FileTypeNames, o.FileTypeNames)
+	c.MCPRules = mergeMap(c.MCPRules, o.MCPRules)
+	c.Notifications = mergeMap(c.Notifications, o.Notifications)
+	c.Glossary = mergeMap(c.Glossary, o.Glossary)
+	if len(o.AIProviders) > 0 {
+		c.AIProviders = o.AIProviders
+	}
+
+	messages := reflect.ValueOf(&c.Messages).Elem()
+	overrides := reflect.ValueOf(o.Messages)
+	for i := 0; i < overrides.NumField(); i++ {
+		if msg := overrides.Field(i).String(); msg != "" {
+			messages.Field(i).SetString(msg)
+		}
+	}
+}
+
+// mergeMap copies the entries of src over dst, allocating dst if needed
+func mergeMap[V any](dst, src map[string]V) map[string]V {
+	if len(src) == 0 {
+		return dst
+	}
+	if dst == nil {
+		dst = make(map[string]V, len(src))
+	}
+	for k, v := range src {
+		dst[k] = v
+	}
+	return dst
 }
 
 // LoadNarratorConfigWithDefaults loads config or returns default if file doesn't exist
 func LoadNarratorConfigWithDefaults(path string) *NarratorConfig {
+	return LoadNarratorConfigWithLanguage(path, LanguageJapanese)
+}
+
+// LoadNarratorConfigWithLanguage loads config or returns the default for the language if file doesn't exist
+func LoadNarratorConfigWithLanguage(path string, lang Language) *NarratorConfig {
 	config, err := LoadNarratorConfig(path)
 	if err == nil {
 		return config
 	}
 
 	// Return default configuration
-	return GetDefaultNarratorConfig()
+	return GetDefaultNarratorConfigForLanguage(lang)
 }
 
 // GetDefaultNarratorConfig returns the default narrator configuration
 func GetDefaultNarratorConfig() *NarratorConfig {
+	return GetDefaultNarratorConfigForLanguage(LanguageJapanese)
+}
+
+// GetDefaultNarratorConfigForLanguage returns the default narrator configuration for the language
+func GetDefaultNarratorConfigForLanguage(lang Language) *NarratorConfig {
+	rules := defaultNarratorRulesJSON
+	if lang == LanguageEnglish {
+		rules = defaultNarratorRulesEnJSON
+	}
+
 	var config NarratorConfig
-	if err := json.Unmarshal([]byte(defaultNarratorRulesJSON), &config); err != nil {
+	if err := json.Unmarshal([]byte(rules), &config); err != nil {
 		// This should never happen as the embedded JSON is validated at compile time
 		panic(fmt.Sprintf("failed to parse embedded narrator rules: %v", err))
 	}
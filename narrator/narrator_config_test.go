@@ -0,0 +1,89 @@
+package narrator
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestLoadNarratorConfig_YAMLAndIncludes(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"mcp/github.json": `{"mcpRules": {"github": {"default": "GitHubを操作します"}}, "messages": {"agentTask": "included"}}`,
+		"mcp/slack.yaml":  "mcpRules:\n  slack:\n    default: Slackを操作します\n",
+		"config.yaml": `include:
+  - mcp/github.json
+  - mcp/slack.yaml
+rules:
+  Read:
+    default: "{filename}を読みます"
+messages:
+  complexTask: first
+---
+rules:
+  Write:
+    default: "{filename}を書きます"
+messages:
+  complexTask: second
+`,
+	}
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	config, err := LoadNarratorConfig(filepath.Join(dir, "config.yaml"))
+	if err != nil {
+		t.Fatalf("LoadNarratorConfig() error = %v", err)
+	}
+	if got := config.Rules["Read"].Default; got != "{filename}を読みます" {
+		t.Errorf("Read rule = %q", got)
+	}
+	if got := config.Rules["Write"].Default; got != "{filename}を書きます" {
+		t.Errorf("Write rule from the second document = %q", got)
+	}
+	if got := config.Messages.ComplexTask; got != "second" {
+		t.Errorf("complexTask = %q, want the later document's", got)
+	}
+	if got := config.Messages.AgentTask; got != "included" {
+		t.Errorf("agentTask = %q, want the included one", got)
+	}
+	for server, want := range map[string]string{"github": "GitHubを操作します", "slack": "Slackを操作します"} {
+		if got := config.MCPRules[server].Default; got != want {
+			t.Errorf("mcpRules[%s] = %q, want %q", server, got, want)
+		}
+	}
+}
+
+func TestLoadNarratorConfig_Errors(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"loop-a.yaml":    "include: [loop-b.json]\n",
+		"loop-b.json":    `{"include": ["loop-a.yaml"]}`,
+		"missing.yml":    "include: [nowhere.json]\n",
+		"malformed.yaml": "rules: [\n",
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tests := map[string]string{
+		"loop-a.yaml":    "includes itself",
+		"missing.yml":    "nowhere.json",
+		"malformed.yaml": "failed to parse",
+	}
+	for name, want := range tests {
+		_, err := LoadNarratorConfig(filepath.Join(dir, name))
+		if err == nil || !strings.Contains(err.Error(), want) {
+			t.Errorf("LoadNarratorConfig(%s) error = %v, want one mentioning %q", name, err, want)
+		}
+	}
+}
@@ -0,0 +1,83 @@
+package narrator
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// CorpusCase is a text and its expected normalization in a pronunciation corpus
+type CorpusCase struct {
+	Line int // Line number in the corpus file
+	Text string
+	Want string
+}
+
+// CorpusResult is the normalization of a corpus case
+type CorpusResult struct {
+	CorpusCase
+	Got string
+}
+
+// Passed reports whether the text was normalized as expected
+func (r CorpusResult) Passed() bool {
+	return r.Got == r.Want
+}
+
+// LoadNormalizerCorpus reads a pronunciation corpus: one case per line with the
+// text and its expected normalization separated by a tab. Blank lines and lines
+// starting with # are ignored.
+func LoadNormalizerCorpus(path string) ([]CorpusCase, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var cases []CorpusCase
+	scanner := bufio.NewScanner(f)
+	line := 0
+	for scanner.Scan() {
+		line++
+		text := strings.TrimSuffix(scanner.Text(), "\r")
+		if strings.TrimSpace(text) == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+		text, want, ok := strings.Cut(text, "\t")
+		if !ok {
+			return nil, fmt.Errorf("%s:%d: expected TEXT<tab>NORMALIZED", path, line)
+		}
+		cases = append(cases, CorpusCase{Line: line, Text: text, Want: want})
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read corpus %s: %w", path, err)
+	}
+	return cases, nil
+}
+
+// RunNormalizerCorpus normalizes the text of each case
+func RunNormalizerCorpus(n *TextNormalizer, cases []CorpusCase) []CorpusResult {
+	results := make([]CorpusResult, len(cases))
+	for i, c := range cases {
+		results[i] = CorpusResult{CorpusCase: c, Got: n.Normalize(c.Text)}
+	}
+	return results
+}
+
+// UpdateNormalizerCorpus rewrites the expected normalizations of a corpus file with
+// the results, keeping its comments and blank lines
+func UpdateNormalizerCorpus(path string, results []CorpusResult) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	lines := strings.Split(string(data), "\n")
+	for _, r := range results {
+		if r.Line < 1 || r.Line > len(lines) {
+			return fmt.Errorf("%s:%d: no such line", path, r.Line)
+		}
+		lines[r.Line-1] = r.Text + "\t" + r.Got
+	}
+	return os.WriteFile(path, []byte(strings.Join(lines, "\n")), 0o644)
+}
@@ -0,0 +1,75 @@
+package narrator
+
+import (
+	"flag"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+var updateCorpus = flag.Bool("update", false, "Rewrite the expected normalizations of the corpora in testdata/normalizer")
+
+// corpusOptions are the normalizer options each corpus in testdata/normalizer is read with
+var corpusOptions = map[string]NormalizerOptions{
+	"default.tsv":     {},
+	"katakana.tsv":    {Katakana: true},
+	"raw_numbers.tsv": {RawNumbers: true},
+	"skip_urls.tsv":   {SkipURLs: true},
+}
+
+func TestNormalizerCorpus(t *testing.T) {
+	for name, opts := range corpusOptions {
+		t.Run(name, func(t *testing.T) {
+			path := filepath.Join("testdata", "normalizer", name)
+			cases, err := LoadNormalizerCorpus(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			n := NewTextNormalizer()
+			n.SetOptions(opts)
+			results := RunNormalizerCorpus(n, cases)
+			if *updateCorpus {
+				if err := UpdateNormalizerCorpus(path, results); err != nil {
+					t.Fatal(err)
+				}
+				return
+			}
+			for _, r := range results {
+				if !r.Passed() {
+					t.Errorf("%s:%d: Normalize(%q) = %q, want %q", path, r.Line, r.Text, r.Got, r.Want)
+				}
+			}
+		})
+	}
+}
+
+func TestLoadNormalizerCorpus(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "corpus.tsv")
+	if err := os.WriteFile(path, []byte("# comment\n\nmain.go\tメイン\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	cases, err := LoadNormalizerCorpus(path)
+	if err != nil {
+		t.Fatalf("LoadNormalizerCorpus() error = %v", err)
+	}
+	if len(cases) != 1 || cases[0] != (CorpusCase{Line: 3, Text: "main.go", Want: "メイン"}) {
+		t.Errorf("LoadNormalizerCorpus() = %+v", cases)
+	}
+
+	// Updating keeps the comments and fixes the expectation
+	results := RunNormalizerCorpus(NewTextNormalizer(), cases)
+	if err := UpdateNormalizerCorpus(path, results); err != nil {
+		t.Fatalf("UpdateNormalizerCorpus() error = %v", err)
+	}
+	data, _ := os.ReadFile(path)
+	if want := "# comment\n\nmain.go\tmainドットゴー\n"; string(data) != want {
+		t.Errorf("updated corpus = %q, want %q", data, want)
+	}
+
+	if err := os.WriteFile(path, []byte("no tab\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := LoadNormalizerCorpus(path); err == nil {
+		t.Error("LoadNormalizerCorpus() should fail without a tab")
+	}
+}
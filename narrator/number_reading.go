@@ -0,0 +1,185 @@
+package narrator
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+var (
+	// 2025-01-26, 2025/1/26 and ISO 8601 timestamps such as 2025-01-26T15:04:05.123Z
+	dateReadingPattern = regexp.MustCompile(`(\d{4})[-/](\d{1,2})[-/](\d{1,2})(?:[T ](\d{1,2}):(\d{2})(?::(\d{2}))?(?:\.\d+)?(?:Z|[+-]\d{2}:?\d{2})?)?`)
+	// 15:04 and 15:04:05
+	timeReadingPattern = regexp.MustCompile(`(\d{1,2}):(\d{2})(?::(\d{2}))?(?:\.\d+)?`)
+	// v1.2.3, v2 and bare three-part versions such as 1.24.5
+	versionReadingPattern = regexp.MustCompile(`[vV](\d+(?:\.\d+){0,3})|(\d+\.\d+\.\d+)`)
+	// 50% and 12.5%
+	percentReadingPattern = regexp.MustCompile(`(\d+(?:\.\d+)?)%`)
+)
+
+var englishMonths = []string{"January", "February", "March", "April", "May", "June", "July", "August", "September", "October", "November", "December"}
+
+// readNumbers rewrites dates, times, versions and percentages in an ASCII segment
+// into words, in English when english is set and in Japanese otherwise, so they are
+// not read digit by digit or split by the dot and slash replacements.
+func readNumbers(text string, english bool) string {
+	text = replaceReadable(text, dateReadingPattern, func(m []string) (string, bool) {
+		year, month, day := atoi(m[1]), atoi(m[2]), atoi(m[3])
+		if month < 1 || month > 12 || day < 1 || day > 31 {
+			return "", false
+		}
+		date := fmt.Sprintf("%d年%d月%d日", year, month, day)
+		if english {
+			date = fmt.Sprintf("%s %d, %d", englishMonths[month-1], day, year)
+		}
+		if m[4] == "" {
+			return date, true
+		}
+		clock, ok := readTime(m[4], m[5], m[6], english)
+		if !ok {
+			return "", false
+		}
+		if english {
+			return date + ", " + clock, true
+		}
+		return date + clock, true
+	})
+	text = replaceReadable(text, timeReadingPattern, func(m []string) (string, bool) {
+		return readTime(m[1], m[2], m[3], english)
+	})
+	text = replaceReadable(text, versionReadingPattern, func(m []string) (string, bool) {
+		version := m[1] + m[2]
+		if english {
+			return "version " + strings.ReplaceAll(version, ".", " point "), true
+		}
+		return "バージョン" + strings.ReplaceAll(version, ".", "点"), true
+	})
+	text = replaceReadable(text, percentReadingPattern, func(m []string) (string, bool) {
+		if english {
+			return strings.ReplaceAll(m[1], ".", " point ") + " percent", true
+		}
+		return strings.ReplaceAll(m[1], ".", "点") + "パーセント", true
+	})
+	return text
+}
+
+// readTime reads a clock time, returning false if it is not a valid time of day
+func readTime(hour, minute, second string, english bool) (string, bool) {
+	h, m := atoi(hour), atoi(minute)
+	s := -1
+	if second != "" {
+		s = atoi(second)
+	}
+	if h > 23 || m > 59 || s > 59 {
+		return "", false
+	}
+
+	if english {
+		suffix := "AM"
+		if h >= 12 {
+			suffix = "PM"
+		}
+		h12 := h % 12
+		if h12 == 0 {
+			h12 = 12
+		}
+		var b strings.Builder
+		fmt.Fprintf(&b, "%d", h12)
+		switch {
+		case m == 0:
+		case m < 10:
+			fmt.Fprintf(&b, " oh %d", m)
+		default:
+			fmt.Fprintf(&b, " %d", m)
+		}
+		b.WriteString(" " + suffix)
+		if s > 0 {
+			fmt.Fprintf(&b, " and %d seconds", s)
+		}
+		return b.String(), true
+	}
+
+	reading := fmt.Sprintf("%d時", h)
+	if m > 0 {
+		reading += fmt.Sprintf("%d分", m)
+	}
+	if s > 0 {
+		reading += fmt.Sprintf("%d秒", s)
+	}
+	return reading, true
+}
+
+// replaceReadable replaces matches of re that stand alone, so identifiers such as
+// api_v2, line references such as main.go:42:10 and IP addresses are left as they are
+func replaceReadable(text string, re *regexp.Regexp, read func(m []string) (string, bool)) string {
+	var b strings.Builder
+	last := 0
+	for _, loc := range re.FindAllStringSubmatchIndex(text, -1) {
+		start, end := loc[0], loc[1]
+		if !standsAlone(text, start, end) {
+			continue
+		}
+		m := make([]string, len(loc)/2)
+		for i := range m {
+			if loc[2*i] >= 0 {
+				m[i] = text[loc[2*i]:loc[2*i+1]]
+			}
+		}
+		reading, ok := read(m)
+		if !ok {
+			continue
+		}
+		b.WriteString(text[last:start])
+		b.WriteString(reading)
+		last = end
+	}
+	if last == 0 {
+		return text
+	}
+	b.WriteString(text[last:])
+	return b.String()
+}
+
+// standsAlone reports whether text[start:end] is not part of a longer word, number or path
+func standsAlone(text string, start, end int) bool {
+	if start > 0 {
+		prev := rune(text[start-1])
+		if isWordRune(prev) || strings.ContainsRune(".:-/_", prev) {
+			return false
+		}
+	}
+	if end < len(text) {
+		next := rune(text[end])
+		if isWordRune(next) || strings.ContainsRune(":-/_%", next) {
+			return false
+		}
+		// A dot followed by a digit continues the number, as in an IP address
+		if next == '.' && end+1 < len(text) && unicode.IsDigit(rune(text[end+1])) {
+			return false
+		}
+	}
+	return true
+}
+
+// isWordRune reports whether r is an ASCII letter or digit
+func isWordRune(r rune) bool {
+	return r < unicode.MaxASCII && (unicode.IsLetter(r) || unicode.IsDigit(r))
+}
+
+// hasJapanese reports whether text contains kana or kanji
+func hasJapanese(text string) bool {
+	for _, r := range text {
+		if unicode.In(r, unicode.Hiragana, unicode.Katakana, unicode.Han) {
+			return true
+		}
+	}
+	return false
+}
+
+// atoi converts a matched run of digits
+func atoi(s string) int {
+	n, _ := strconv.Atoi(s)
+	return n
+}
@@ -0,0 +1,52 @@
+package narrator
+
+import (
+	"testing"
+)
+
+func TestTextNormalizer_ReadNumbers(t *testing.T) {
+	tests := []struct {
+		name     string
+		lang     Language
+		input    string
+		expected string
+	}{
+		// Japanese
+		{name: "date", lang: LanguageJapanese, input: "2025-01-26に更新", expected: "2025年1月26日に更新"},
+		{name: "slashed date", lang: LanguageJapanese, input: "2025/1/26", expected: "2025年1月26日"},
+		{name: "ISO timestamp", lang: LanguageJapanese, input: "2025-01-26T15:04:05.123Z", expected: "2025年1月26日15時4分5秒"},
+		{name: "date and time", lang: LanguageJapanese, input: "2025-01-26 09:30", expected: "2025年1月26日9時30分"},
+		{name: "time", lang: LanguageJapanese, input: "15:00に開始", expected: "15時に開始"},
+		{name: "time with seconds", lang: LanguageJapanese, input: "12:34:56", expected: "12時34分56秒"},
+		{name: "version", lang: LanguageJapanese, input: "v1.2.3をリリース", expected: "バージョン1点2点3をリリース"},
+		{name: "bare version", lang: LanguageJapanese, input: "go 1.24.5", expected: "go バージョン1点24点5"},
+		{name: "major version", lang: LanguageJapanese, input: "v2に移行", expected: "バージョン2に移行"},
+		{name: "percentage", lang: LanguageJapanese, input: "カバレッジ85%", expected: "カバレッジ85パーセント"},
+		{name: "decimal percentage", lang: LanguageJapanese, input: "12.5%", expected: "12点5パーセント"},
+
+		// English
+		{name: "English date", lang: LanguageEnglish, input: "Updated on 2025-01-26", expected: "Updated on January 26, 2025"},
+		{name: "English timestamp", lang: LanguageEnglish, input: "2025-12-01T00:05:00Z", expected: "December 1, 2025, 12 oh 5 AM"},
+		{name: "English time", lang: LanguageEnglish, input: "Started at 15:30", expected: "Started at 3 30 PM"},
+		{name: "English version", lang: LanguageEnglish, input: "Released v1.2.3", expected: "Released version 1 point 2 point 3"},
+		{name: "English percentage", lang: LanguageEnglish, input: "Coverage is 12.5%", expected: "Coverage is 12 point 5 percent"},
+		{name: "English mode with Japanese text", lang: LanguageEnglish, input: "2025-01-26に更新", expected: "2025年1月26日に更新"},
+
+		// Left as they are
+		{name: "invalid date", lang: LanguageJapanese, input: "2025-13-40", expected: "2025-13-40"},
+		{name: "invalid time", lang: LanguageJapanese, input: "25:61", expected: "25:61"},
+		{name: "line reference", lang: LanguageJapanese, input: "main.go:42:10", expected: "mainドットゴー:42:10"},
+		{name: "IP address", lang: LanguageJapanese, input: "192.168.1.10", expected: "192ドット168ドット1ドット10"},
+		{name: "identifier with version", lang: LanguageJapanese, input: "api_v2", expected: "api v2"},
+		{name: "word ending in v", lang: LanguageJapanese, input: "dev1.2", expected: "dev1ドット2"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := NewTextNormalizerWithLanguage(tt.lang).Normalize(tt.input)
+			if result != tt.expected {
+				t.Errorf("Normalize(%q) = %q, want %q", tt.input, result, tt.expected)
+			}
+		})
+	}
+}
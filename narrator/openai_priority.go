@@ -0,0 +1,153 @@
+package narrator
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/kazegusuri/claude-companion/logger"
+)
+
+// openAIChatCompletionsURL is the endpoint of the Chat Completions API
+const openAIChatCompletionsURL = "https://api.openai.com/v1/chat/completions"
+
+// maxPriorityCacheSize bounds the number of cached AI scores
+const maxPriorityCacheSize = 512
+
+// OpenAIPriorityScorer refines the rule-based score of assistant text with OpenAI.
+// Other narrations, text the rules already find urgent, and failed requests use the rules.
+type OpenAIPriorityScorer struct {
+	apiKey     string
+	model      string
+	endpoint   string
+	timeout    time.Duration
+	httpClient *http.Client
+	rules      *RulePriorityScorer
+
+	cacheMu sync.Mutex
+	cache   map[string]int
+}
+
+// NewOpenAIPriorityScorer creates a new OpenAI priority scorer
+func NewOpenAIPriorityScorer(apiKey string) *OpenAIPriorityScorer {
+	return &OpenAIPriorityScorer{
+		apiKey:   apiKey,
+		model:    OpenAINarratorModel(),
+		endpoint: openAIChatCompletionsURL,
+		timeout:  3 * time.Second,
+		httpClient: &http.Client{
+			Timeout: 10 * time.Second,
+		},
+		rules: NewRulePriorityScorer(),
+		cache: make(map[string]int),
+	}
+}
+
+// ScorePriority scores a narration
+func (s *OpenAIPriorityScorer) ScorePriority(in PriorityInput) int {
+	score := s.rules.ScorePriority(in)
+	if in.Type != NarrationTypeText || score == PriorityUrgent || strings.TrimSpace(in.Text) == "" {
+		return score
+	}
+
+	s.cacheMu.Lock()
+	cached, ok := s.cache[in.Text]
+	s.cacheMu.Unlock()
+	if ok {
+		return cached
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), s.timeout)
+	defer cancel()
+	urgency, err := s.classify(ctx, in.Text)
+	if err != nil {
+		logger.LogError("Failed to call OpenAI for priority scoring: %v", err)
+		return score
+	}
+
+	switch urgency {
+	case "high":
+		score = PriorityUrgent
+	case "low":
+		score = PriorityLow
+	}
+
+	s.cacheMu.Lock()
+	if len(s.cache) >= maxPriorityCacheSize {
+		s.cache = make(map[string]int)
+	}
+	s.cache[in.Text] = score
+	s.cacheMu.Unlock()
+	return score
+}
+
+// classify asks OpenAI whether text needs the user's attention and returns high, normal or low
+func (s *OpenAIPriorityScorer) classify(ctx context.Context, text string) (string, error) {
+	request := openAIRequest{
+		Model: s.model,
+		Messages: []openAIMessage{
+			{
+				Role: "system",
+				Content: "You rate how urgently a developer needs to hear a message from their AI coding assistant. " +
+					"Answer with exactly one word: high (errors, failures, questions or decisions for the user), " +
+					"normal (progress and results), or low (routine chatter).",
+			},
+			{
+				Role:    "user",
+				Content: text,
+			},
+		},
+		Temperature: 0,
+		MaxTokens:   3,
+	}
+
+	jsonData, err := json.Marshal(request)
+	if err != nil {
+		return "", err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "POST", s.endpoint, bytes.NewBuffer(jsonData))
+	if err != nil {
+		return "", err
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+s.apiKey)
+
+	resp, err := s.httpClient.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return "", err
+	}
+
+	var response openAIResponse
+	if err := json.Unmarshal(body, &response); err != nil {
+		return "", err
+	}
+
+	if response.Error != nil {
+		return "", fmt.Errorf("OpenAI API error: %s", response.Error.Message)
+	}
+
+	if len(response.Choices) == 0 {
+		return "", fmt.Errorf("no response from OpenAI")
+	}
+
+	urgency := strings.ToLower(strings.Trim(strings.TrimSpace(response.Choices[0].Message.Content), "."))
+	switch urgency {
+	case "high", "normal", "low":
+		return urgency, nil
+	}
+	return "", fmt.Errorf("unexpected urgency from OpenAI: %q", urgency)
+}
@@ -0,0 +1,63 @@
+package narrator
+
+import (
+	"strings"
+	"unicode"
+)
+
+// StreamingNarrator is implemented by narrators that can narrate text incrementally.
+// onSentence is called with each sentence of the narration as soon as it is complete;
+// it is not called when the narrator falls back.
+type StreamingNarrator interface {
+	NarrateTextStream(text string, isThinking bool, onSentence func(string)) (string, bool)
+}
+
+// sentenceSplitter collects streamed text and emits each sentence once it is complete
+type sentenceSplitter struct {
+	buf  []rune
+	emit func(string)
+}
+
+// newSentenceSplitter creates a splitter that calls emit with each sentence
+func newSentenceSplitter(emit func(string)) *sentenceSplitter {
+	return &sentenceSplitter{emit: emit}
+}
+
+// Write adds streamed text and emits the sentences it completes.
+// Japanese terminators and newlines end a sentence immediately; ASCII
+// terminators only when followed by a space, so "v1.2" or "main.go" are kept.
+func (s *sentenceSplitter) Write(delta string) {
+	s.buf = append(s.buf, []rune(delta)...)
+
+	start := 0
+	for i, r := range s.buf {
+		end := -1
+		switch r {
+		case '。', '！', '？', '\n':
+			end = i + 1
+		case '.', '!', '?':
+			if i+1 < len(s.buf) && unicode.IsSpace(s.buf[i+1]) {
+				end = i + 1
+			}
+		}
+		if end < 0 {
+			continue
+		}
+		s.emitSentence(string(s.buf[start:end]))
+		start = end
+	}
+	s.buf = s.buf[start:]
+}
+
+// Flush emits the remaining text as the last sentence
+func (s *sentenceSplitter) Flush() {
+	s.emitSentence(string(s.buf))
+	s.buf = nil
+}
+
+// emitSentence emits a sentence unless it is blank
+func (s *sentenceSplitter) emitSentence(sentence string) {
+	if sentence = strings.TrimSpace(sentence); sentence != "" {
+		s.emit(sentence)
+	}
+}
@@ -0,0 +1,129 @@
+package narrator
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestSentenceSplitter(t *testing.T) {
+	tests := []struct {
+		name   string
+		deltas []string
+		want   []string
+	}{
+		{
+			name:   "japanese",
+			deltas: []string{"ファイルを", "更新しました。テス", "トを実行します", "。"},
+			want:   []string{"ファイルを更新しました。", "テストを実行します。"},
+		},
+		{
+			name:   "english waits for the following space",
+			deltas: []string{"Updated main", ".go and v1.2", ". Running", " tests now!"},
+			want:   []string{"Updated main.go and v1.2.", "Running tests now!"},
+		},
+		{
+			name:   "unterminated text is flushed",
+			deltas: []string{"確認してください？", "残り"},
+			want:   []string{"確認してください？", "残り"},
+		},
+		{
+			name:   "newlines and blanks",
+			deltas: []string{"first\n", "\n  ", "second"},
+			want:   []string{"first", "second"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []string
+			s := newSentenceSplitter(func(sentence string) { got = append(got, sentence) })
+			for _, d := range tt.deltas {
+				s.Write(d)
+			}
+			s.Flush()
+			if diff := cmp.Diff(tt.want, got); diff != "" {
+				t.Errorf("sentences mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestAINarrator_NarrateTextStream(t *testing.T) {
+	release := make(chan struct{})
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		flusher := w.(http.Flusher)
+		send := func(content string) {
+			fmt.Fprintf(w, "data: {\"choices\":[{\"delta\":{\"content\":%q}}]}\n\n", content)
+			flusher.Flush()
+		}
+		send("設定を")
+		send("読み込みました。")
+		// Hold the rest of the completion until the first sentence has been handed off
+		<-release
+		send("次にテストを実行します。")
+		fmt.Fprint(w, "data: [DONE]\n\n")
+	}))
+	defer ts.Close()
+
+	provider := NewOpenAIProvider("test-key", "test-model")
+	provider.endpoint = ts.URL
+	ai := NewAINarrator(provider)
+
+	var sentences []string
+	result, shouldFallback := ai.NarrateTextStream("line1\nline2", false, func(sentence string) {
+		sentences = append(sentences, sentence)
+		if len(sentences) == 1 {
+			close(release)
+		}
+	})
+	if shouldFallback {
+		t.Fatal("unexpected fallback")
+	}
+	if want := "設定を読み込みました。次にテストを実行します。"; result != want {
+		t.Errorf("result = %q, want %q", result, want)
+	}
+	if diff := cmp.Diff([]string{"設定を読み込みました。", "次にテストを実行します。"}, sentences); diff != "" {
+		t.Errorf("sentences mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestAINarrator_NarrateTextStreamError(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusTooManyRequests)
+		fmt.Fprint(w, `{"error":{"message":"rate limited","type":"requests"}}`)
+	}))
+	defer ts.Close()
+
+	provider := NewOpenAIProvider("test-key", "test-model")
+	provider.endpoint = ts.URL
+	ai := NewAINarrator(provider)
+	ai.timeout = time.Second
+
+	called := false
+	result, shouldFallback := ai.NarrateTextStream("line1\nline2", false, func(string) { called = true })
+	if !shouldFallback || called {
+		t.Errorf("expected fallback without sentences, got fallback=%v called=%v", shouldFallback, called)
+	}
+	if result != "line1\nline2" {
+		t.Errorf("result = %q, want the original text", result)
+	}
+}
+
+func TestHybridNarrator_NarrateTextStreamWithoutAI(t *testing.T) {
+	hn := NewHybridNarrator("", false)
+
+	var sentences []string
+	result, _ := hn.NarrateTextStream("first line\nsecond line", false, func(s string) { sentences = append(sentences, s) })
+	if result != "first line" {
+		t.Errorf("result = %q, want first line", result)
+	}
+	if diff := cmp.Diff([]string{"first line"}, sentences); diff != "" {
+		t.Errorf("sentences mismatch (-want +got):\n%s", diff)
+	}
+}
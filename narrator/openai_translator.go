@@ -1,45 +1,44 @@
 package narrator
 
 import (
-	"bytes"
 	"context"
-	"encoding/json"
-	"fmt"
-	"io"
-	"net/http"
 	"strings"
-	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/kazegusuri/claude-companion/logger"
 )
 
-// OpenAITranslator uses OpenAI API for English to Japanese translation
+// translationSystemPrompt instructs the model to translate text for speech
+const translationSystemPrompt = `You are a translator specializing in technical documentation. 
+Translate English text to natural Japanese suitable for text-to-speech.
+Rules:
+1. Keep technical terms that are commonly used in Japanese as-is (API, URL, JSON, etc.)
+2. Translate programming-related phrases naturally
+3. For file operations, use appropriate Japanese (読み込み, 書き込み, etc.)
+4. Return ONLY the translated text, no explanations
+5. If the text is already in Japanese, return it unchanged`
+
+// OpenAITranslator uses an AI provider (OpenAI by default) for English to Japanese translation
 type OpenAITranslator struct {
-	apiKey     string
-	model      string
-	httpClient *http.Client
-	cache      map[string]string
-	cacheMu    sync.RWMutex
-	cacheTTL   time.Duration
-	cacheTime  map[string]time.Time
+	provider Provider
+	cache    *TranslationCache
 }
 
 // NewOpenAITranslator creates a new OpenAI translator
 func NewOpenAITranslator(apiKey string) *OpenAITranslator {
+	return NewAITranslator(NewOpenAIProvider(apiKey, "gpt-4o-mini")) // Fast and cost-effective
+}
+
+// NewAITranslator creates a translator backed by provider
+func NewAITranslator(provider Provider) *OpenAITranslator {
 	return &OpenAITranslator{
-		apiKey: apiKey,
-		model:  "gpt-4o-mini", // Fast and cost-effective
-		httpClient: &http.Client{
-			Timeout: 10 * time.Second,
-		},
-		cache:     make(map[string]string),
-		cacheTime: make(map[string]time.Time),
-		cacheTTL:  1 * time.Hour,
+		provider: provider,
+		cache:    NewTranslationCache(""),
 	}
 }
 
-// Translate translates English text to Japanese using OpenAI
+// Translate translates English text to Japanese using the AI provider
 func (t *OpenAITranslator) Translate(ctx context.Context, text string) (string, error) {
 	// Check if text is already mostly Japanese
 	if t.isMostlyJapanese(text) {
@@ -47,97 +46,27 @@ func (t *OpenAITranslator) Translate(ctx context.Context, text string) (string,
 	}
 
 	// Check cache
-	t.cacheMu.RLock()
-	if cached, ok := t.cache[text]; ok {
-		if cacheTime, ok := t.cacheTime[text]; ok {
-			if time.Since(cacheTime) < t.cacheTTL {
-				t.cacheMu.RUnlock()
-				return cached, nil
-			}
-		}
+	if cached, ok := t.cache.Get(text); ok {
+		return cached, nil
 	}
-	t.cacheMu.RUnlock()
 
-	// Call OpenAI API
-	translated, err := t.callOpenAI(ctx, text)
+	// Call the AI provider
+	translated, err := t.provider.Complete(ctx, CompletionRequest{
+		System:      translationSystemPrompt,
+		Prompt:      text,
+		Temperature: 0.3,
+		MaxTokens:   200,
+	})
 	if err != nil {
 		return text, err // Return original text on error
 	}
 
 	// Cache the result
-	t.cacheMu.Lock()
-	t.cache[text] = translated
-	t.cacheTime[text] = time.Now()
-	t.cacheMu.Unlock()
+	t.cache.Put(text, translated)
 
 	return translated, nil
 }
 
-// callOpenAI makes the actual API call to OpenAI
-func (t *OpenAITranslator) callOpenAI(ctx context.Context, text string) (string, error) {
-	request := openAIRequest{
-		Model: t.model,
-		Messages: []openAIMessage{
-			{
-				Role: "system",
-				Content: `You are a translator specializing in technical documentation. 
-Translate English text to natural Japanese suitable for text-to-speech.
-Rules:
-1. Keep technical terms that are commonly used in Japanese as-is (API, URL, JSON, etc.)
-2. Translate programming-related phrases naturally
-3. For file operations, use appropriate Japanese (読み込み, 書き込み, etc.)
-4. Return ONLY the translated text, no explanations
-5. If the text is already in Japanese, return it unchanged`,
-			},
-			{
-				Role:    "user",
-				Content: text,
-			},
-		},
-		Temperature: 0.3,
-		MaxTokens:   200,
-	}
-
-	jsonData, err := json.Marshal(request)
-	if err != nil {
-		return "", err
-	}
-
-	req, err := http.NewRequestWithContext(ctx, "POST", "https://api.openai.com/v1/chat/completions", bytes.NewBuffer(jsonData))
-	if err != nil {
-		return "", err
-	}
-
-	req.Header.Set("Content-Type", "application/json")
-	req.Header.Set("Authorization", "Bearer "+t.apiKey)
-
-	resp, err := t.httpClient.Do(req)
-	if err != nil {
-		return "", err
-	}
-	defer resp.Body.Close()
-
-	body, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return "", err
-	}
-
-	var response openAIResponse
-	if err := json.Unmarshal(body, &response); err != nil {
-		return "", err
-	}
-
-	if response.Error != nil {
-		return "", fmt.Errorf("OpenAI API error: %s", response.Error.Message)
-	}
-
-	if len(response.Choices) > 0 {
-		return strings.TrimSpace(response.Choices[0].Message.Content), nil
-	}
-
-	return "", fmt.Errorf("no response from OpenAI")
-}
-
 // isMostlyJapanese checks if text contains Japanese characters
 func (t *OpenAITranslator) isMostlyJapanese(text string) bool {
 	// Count Japanese characters (Hiragana, Katakana, Kanji)
@@ -168,46 +97,117 @@ type TranslatorInterface interface {
 	Translate(ctx context.Context, text string) (string, error)
 }
 
-// CombinedTranslator tries rule-based first, then falls back to OpenAI
+// translationTimeout bounds an AI translation request
+const translationTimeout = 5 * time.Second
+
+// CombinedTranslator translates with a fallback chain: the rule-based translator when
+// it leaves little English, then the translation backend (or its cached translations)
+// while it is healthy, then the rule-based translation, and finally the text as is
 type CombinedTranslator struct {
 	simpleTranslator *SimpleTranslator
-	openAITranslator *OpenAITranslator
-	useOpenAI        bool
+	backend          *translationBatcher // nil translates with the rules only
+	cache            *TranslationCache
+	glossary         *Glossary
+	aiDown           atomic.Bool
 }
 
 // NewCombinedTranslator creates a translator that combines rule-based and OpenAI
 func NewCombinedTranslator(apiKey string, useOpenAI bool) *CombinedTranslator {
 	ct := &CombinedTranslator{
 		simpleTranslator: NewSimpleTranslator(),
-		useOpenAI:        useOpenAI,
+		cache:            NewTranslationCache(""),
 	}
 
 	if useOpenAI && apiKey != "" {
-		ct.openAITranslator = NewOpenAITranslator(apiKey)
+		ct.SetProvider(NewOpenAIProvider(apiKey, "gpt-4o-mini")) // Fast and cost-effective
 	}
 
 	return ct
 }
 
+// NewCombinedTranslatorWithProvider creates a translator that combines rule-based and the given AI provider
+func NewCombinedTranslatorWithProvider(provider Provider) *CombinedTranslator {
+	ct := NewCombinedTranslator("", false)
+	ct.SetProvider(provider)
+	return ct
+}
+
+// SetProvider translates text the rules cannot handle with provider
+func (ct *CombinedTranslator) SetProvider(provider Provider) {
+	ct.SetBackend(NewProviderBackend(provider))
+}
+
+// SetBackend translates text the rules cannot handle with backend, batching concurrent
+// translations. A nil backend translates with the rules only.
+func (ct *CombinedTranslator) SetBackend(backend TranslationBackend) {
+	if backend == nil {
+		ct.backend = nil
+		return
+	}
+	ct.backend = newTranslationBatcher(backend, translationTimeout)
+}
+
+// SetCache sets the cache of backend translations
+func (ct *CombinedTranslator) SetCache(cache *TranslationCache) {
+	ct.cache = cache
+}
+
+// SetGlossary sets the readings used for English terms by every translation backend
+func (ct *CombinedTranslator) SetGlossary(glossary *Glossary) {
+	ct.glossary = glossary
+}
+
 // Translate attempts translation using available methods
 func (ct *CombinedTranslator) Translate(ctx context.Context, text string) (string, error) {
-	// Always try simple translation first
-	translated := ct.simpleTranslator.Translate(text)
-
-	// If simple translation didn't change much and OpenAI is available, use it
-	if ct.useOpenAI && ct.openAITranslator != nil {
-		// Check if simple translation still contains significant English
-		if ct.containsSignificantEnglish(translated) {
-			openAITranslated, err := ct.openAITranslator.Translate(ctx, text)
-			if err == nil {
-				return openAITranslated, nil
-			}
-			// Fall back to simple translation on error
-			logger.LogError("Failed to translate with OpenAI, falling back to simple translation: %v", err)
+	// Apply the glossary first so every backend keeps its readings
+	source := ct.glossary.Apply(text)
+
+	// Always try simple translation first, passing the text through if the rules lose it
+	translated := ct.simpleTranslator.Translate(source)
+	if strings.TrimSpace(translated) == "" {
+		translated = source
+	}
+
+	// If simple translation didn't change much and a backend is available, use it
+	if ct.backend != nil && ct.containsSignificantEnglish(translated) {
+		if aiTranslated, ok := ct.translateWithAI(ctx, source); ok {
+			translated = aiTranslated
 		}
 	}
 
-	return translated, nil
+	// Apply the glossary again in case a backend brought an English term back
+	return ct.glossary.Apply(translated), nil
+}
+
+// translateWithAI translates with a cached translation or the backend. It gives up
+// without a request while the backend cools down after a failure.
+func (ct *CombinedTranslator) translateWithAI(ctx context.Context, text string) (string, bool) {
+	if cached, ok := ct.cache.Get(text); ok {
+		return cached, true
+	}
+	if !ct.AIHealthy() {
+		return "", false
+	}
+
+	name := ct.backend.backend.Name()
+	translated, err := ct.backend.Translate(ctx, text)
+	if err != nil {
+		// Log only when the backend goes down, not for every narration until it recovers
+		if ctx.Err() == nil && !ct.aiDown.Swap(true) {
+			logger.LogWarning("Failed to translate with %s, falling back to simple translation until it recovers: %v", name, err)
+		}
+		return "", false
+	}
+	if ct.aiDown.Swap(false) {
+		logger.LogInfo("Translation with %s recovered", name)
+	}
+	ct.cache.Put(text, translated)
+	return translated, true
+}
+
+// AIHealthy reports whether the translation backend is available and not cooling down after a failure
+func (ct *CombinedTranslator) AIHealthy() bool {
+	return ct.backend != nil && ct.backend.Healthy()
 }
 
 // containsSignificantEnglish checks if text contains significant English words
@@ -0,0 +1,83 @@
+package narrator
+
+import (
+	"regexp"
+	"strings"
+)
+
+// Priority levels outside the per-type defaults in priorityMap
+const (
+	PriorityRoutine = 0 // Read-only tool uses that can always be skipped
+	PriorityLow     = 1 // Same as an ordinary tool use
+	PriorityUrgent  = 6 // Errors and questions to the user; never skipped for other narrations
+)
+
+// PriorityInput describes a narration to score
+type PriorityInput struct {
+	Type     NarrationType
+	ToolName string // Tool name of tool use narrations
+	Text     string // Text the narration is based on
+	Target   string // File, command or other target of a tool use
+}
+
+// PriorityScorer assigns a priority to a narration (higher number = higher priority)
+type PriorityScorer interface {
+	ScorePriority(in PriorityInput) int
+}
+
+// routineTools are read-only tools whose narrations are low value
+var routineTools = map[string]bool{
+	"Read":         true,
+	"Glob":         true,
+	"Grep":         true,
+	"LS":           true,
+	"NotebookRead": true,
+	"TodoRead":     true,
+	"TodoWrite":    true,
+}
+
+// urgentPattern matches text reporting an error or a failure
+var urgentPattern = regexp.MustCompile(`(?i)\b(error|errors|failed|failure|fails|exception|panic|fatal|denied|cannot|can't|unable to|timed out)\b|エラー|失敗|例外|できません|拒否`)
+
+// questionPattern matches text asking the user something
+var questionPattern = regexp.MustCompile(`[?？]\s*$|(?i)\b(should i|do you want|would you like|shall i|which (one|option))\b|でしょうか|ますか[。]?\s*$|しますか|どうしますか`)
+
+// RulePriorityScorer scores narrations with keyword rules
+type RulePriorityScorer struct{}
+
+// NewRulePriorityScorer creates a new rule-based priority scorer
+func NewRulePriorityScorer() *RulePriorityScorer {
+	return &RulePriorityScorer{}
+}
+
+// ScorePriority scores a narration: errors, questions, permission requests and
+// security alerts are urgent, read-only tool uses are routine, and everything
+// else keeps the default priority of its type
+func (s *RulePriorityScorer) ScorePriority(in PriorityInput) int {
+	switch in.Type {
+	case NarrationTypeError, NarrationTypeToolUsePermission, NarrationTypeSecurity:
+		return PriorityUrgent
+	case NarrationTypeToolUse, NarrationTypeToolUseMCP:
+		if routineTools[in.ToolName] {
+			return PriorityRoutine
+		}
+		return priorityMap[in.Type]
+	case NarrationTypeThinking:
+		// Thinking aloud about an error is not an error
+		return priorityMap[in.Type]
+	}
+
+	if IsUrgentText(in.Text) {
+		return PriorityUrgent
+	}
+	return priorityMap[in.Type]
+}
+
+// IsUrgentText reports whether text reports an error or asks the user a question
+func IsUrgentText(text string) bool {
+	text = strings.TrimSpace(text)
+	if text == "" {
+		return false
+	}
+	return urgentPattern.MatchString(text) || questionPattern.MatchString(text)
+}
@@ -16,15 +16,33 @@ const (
 	NarrationTypeToolUsePermission
 	NarrationTypeNotification
 	NarrationTypeText
+	NarrationTypeError
+	NarrationTypeThinking
+	NarrationTypeSecurity
 )
 
+// narrationTypeNames are the names of narration types in flags such as --voice-style
+var narrationTypeNames = map[string]NarrationType{
+	"tool":         NarrationTypeToolUse,
+	"mcp":          NarrationTypeToolUseMCP,
+	"permission":   NarrationTypeToolUsePermission,
+	"notification": NarrationTypeNotification,
+	"text":         NarrationTypeText,
+	"error":        NarrationTypeError,
+	"thinking":     NarrationTypeThinking,
+	"security":     NarrationTypeSecurity,
+}
+
 // Priority mapping for each narration type (higher number = higher priority)
 var priorityMap = map[NarrationType]int{
 	NarrationTypeToolUse:           1, // Lowest priority
 	NarrationTypeToolUseMCP:        2,
 	NarrationTypeToolUsePermission: 3,
 	NarrationTypeNotification:      4,
-	NarrationTypeText:              5, // Highest priority
+	NarrationTypeText:              5,
+	NarrationTypeError:             PriorityUrgent, // Highest priority
+	NarrationTypeThinking:          1,              // Same as a tool use
+	NarrationTypeSecurity:          PriorityUrgent, // Risky tool uses
 }
 
 // NarrationItem represents an item in the narration queue
@@ -35,6 +53,9 @@ type NarrationItem struct {
 	Priority     int
 	Timestamp    time.Time
 	ID           string
+	SpeakerID    *int // Speaker override; nil uses the synthesizer's default speaker
+	Project      string
+	Session      string
 }
 
 // PriorityQueue manages narration items with priority-based skipping
@@ -108,6 +129,30 @@ func (pq *PriorityQueue) Dequeue(ctx context.Context) *NarrationItem {
 	return &item
 }
 
+// Update changes an item still waiting in the queue. It returns false if the item
+// was already dequeued.
+func (pq *PriorityQueue) Update(id string, f func(item *NarrationItem)) bool {
+	pq.mu.Lock()
+	defer pq.mu.Unlock()
+	for i := range pq.items {
+		if pq.items[i].ID == id {
+			f(&pq.items[i])
+			return true
+		}
+	}
+	return false
+}
+
+// Peek returns up to n items at the head of the queue without removing them
+func (pq *PriorityQueue) Peek(n int) []NarrationItem {
+	pq.mu.Lock()
+	defer pq.mu.Unlock()
+	if n > len(pq.items) {
+		n = len(pq.items)
+	}
+	return append([]NarrationItem(nil), pq.items[:n]...)
+}
+
 // ShouldSkip determines if an item should be skipped based on queue priorities
 func (pq *PriorityQueue) ShouldSkip(item NarrationItem) bool {
 	pq.mu.Lock()
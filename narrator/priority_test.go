@@ -0,0 +1,95 @@
+package narrator
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+)
+
+func TestRulePriorityScorer(t *testing.T) {
+	tests := []struct {
+		name string
+		in   PriorityInput
+		want int
+	}{
+		{name: "routine read", in: PriorityInput{Type: NarrationTypeToolUse, ToolName: "Read"}, want: PriorityRoutine},
+		{name: "routine grep", in: PriorityInput{Type: NarrationTypeToolUse, ToolName: "Grep"}, want: PriorityRoutine},
+		{name: "edit", in: PriorityInput{Type: NarrationTypeToolUse, ToolName: "Edit"}, want: 1},
+		{name: "mcp tool", in: PriorityInput{Type: NarrationTypeToolUseMCP, ToolName: "mcp__github__get_issue"}, want: 2},
+		{name: "permission", in: PriorityInput{Type: NarrationTypeToolUsePermission, ToolName: "Bash"}, want: PriorityUrgent},
+		{name: "api error", in: PriorityInput{Type: NarrationTypeError}, want: PriorityUrgent},
+		{name: "plain text", in: PriorityInput{Type: NarrationTypeText, Text: "I updated the parser."}, want: 5},
+		{name: "error text", in: PriorityInput{Type: NarrationTypeText, Text: "The build failed with 3 errors."}, want: PriorityUrgent},
+		{name: "question", in: PriorityInput{Type: NarrationTypeText, Text: "Which approach do you prefer?"}, want: PriorityUrgent},
+		{name: "japanese question", in: PriorityInput{Type: NarrationTypeText, Text: "この変更をコミットしますか"}, want: PriorityUrgent},
+		{name: "japanese error", in: PriorityInput{Type: NarrationTypeText, Text: "テストが失敗しました"}, want: PriorityUrgent},
+		{name: "question mark mid text", in: PriorityInput{Type: NarrationTypeText, Text: "Why? Because the cache was stale.\nFixed it."}, want: 5},
+		{name: "notification", in: PriorityInput{Type: NarrationTypeNotification, Text: "Task completed"}, want: 4},
+	}
+
+	scorer := NewRulePriorityScorer()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := scorer.ScorePriority(tt.in); got != tt.want {
+				t.Errorf("ScorePriority(%+v) = %d, want %d", tt.in, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestOpenAIPriorityScorer(t *testing.T) {
+	var calls atomic.Int32
+	answers := map[string]string{
+		"Let me know which file to keep":   "high",
+		"I renamed the helper.":            "normal",
+		"Looking at the code now, one sec": "low",
+		"broken":                           "maybe",
+	}
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		body, _ := io.ReadAll(r.Body)
+		var answer string
+		for text, a := range answers {
+			if strings.Contains(string(body), text) {
+				answer = a
+				break
+			}
+		}
+		fmt.Fprintf(w, `{"choices":[{"message":{"role":"assistant","content":%q}}]}`, answer)
+	}))
+	defer ts.Close()
+
+	scorer := NewOpenAIPriorityScorer("test-key")
+	scorer.endpoint = ts.URL
+
+	tests := []struct {
+		name      string
+		in        PriorityInput
+		want      int
+		wantCalls int32
+	}{
+		{name: "high", in: PriorityInput{Type: NarrationTypeText, Text: "Let me know which file to keep"}, want: PriorityUrgent, wantCalls: 1},
+		{name: "normal", in: PriorityInput{Type: NarrationTypeText, Text: "I renamed the helper."}, want: 5, wantCalls: 1},
+		{name: "low", in: PriorityInput{Type: NarrationTypeText, Text: "Looking at the code now, one sec"}, want: PriorityLow, wantCalls: 1},
+		{name: "cached", in: PriorityInput{Type: NarrationTypeText, Text: "Looking at the code now, one sec"}, want: PriorityLow, wantCalls: 0},
+		{name: "unexpected answer falls back to rules", in: PriorityInput{Type: NarrationTypeText, Text: "broken"}, want: 5, wantCalls: 1},
+		{name: "urgent by rules skips the API", in: PriorityInput{Type: NarrationTypeText, Text: "The tests failed."}, want: PriorityUrgent, wantCalls: 0},
+		{name: "tool uses use the rules", in: PriorityInput{Type: NarrationTypeToolUse, ToolName: "Read"}, want: PriorityRoutine, wantCalls: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			before := calls.Load()
+			if got := scorer.ScorePriority(tt.in); got != tt.want {
+				t.Errorf("ScorePriority(%+v) = %d, want %d", tt.in, got, tt.want)
+			}
+			if got := calls.Load() - before; got != tt.wantCalls {
+				t.Errorf("API calls = %d, want %d", got, tt.wantCalls)
+			}
+		})
+	}
+}
@@ -0,0 +1,14 @@
+package narrator
+
+// ProjectOverrides are narration settings of a project that override the global ones
+type ProjectOverrides struct {
+	Config  *NarratorConfig // Narrator rules used instead of the global ones; nil keeps them
+	Speaker *int            // VOICEVOX speaker used instead of the speaker map; nil keeps it
+	Mute    bool            // Do not speak narrations of the project
+}
+
+// ProjectAware is implemented by narrators whose settings a project can override
+type ProjectAware interface {
+	// SetProjectOverrides sets the overrides of the project whose events are narrated next; nil clears them
+	SetProjectOverrides(o *ProjectOverrides)
+}
@@ -0,0 +1,113 @@
+package narrator
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"time"
+)
+
+// CompletionRequest is a single-turn request to a language model
+type CompletionRequest struct {
+	System      string
+	Prompt      string
+	Temperature float64
+	MaxTokens   int
+}
+
+// Provider completes prompts with a language model
+type Provider interface {
+	Name() string
+	Complete(ctx context.Context, req CompletionRequest) (string, error)
+}
+
+// StreamingProvider is a provider that can stream its completion.
+// onDelta is called with each piece of content as it arrives.
+type StreamingProvider interface {
+	Provider
+	CompleteStream(ctx context.Context, req CompletionRequest, onDelta func(string)) (string, error)
+}
+
+// AIProviderConfig configures one provider of the AI narrator fallback chain
+type AIProviderConfig struct {
+	Type      string `json:"type"`                // openai, anthropic or ollama
+	Model     string `json:"model,omitempty"`     // Defaults to the provider's default model
+	URL       string `json:"url,omitempty"`       // API base URL; mainly for ollama
+	APIKeyEnv string `json:"apiKeyEnv,omitempty"` // Environment variable holding the API key
+	Timeout   string `json:"timeout,omitempty"`   // Per-request timeout such as "5s"
+}
+
+// Provider types
+const (
+	ProviderOpenAI    = "openai"
+	ProviderAnthropic = "anthropic"
+	ProviderOllama    = "ollama"
+)
+
+// defaultProviderTimeout is the request timeout of a provider without one configured
+const defaultProviderTimeout = 5 * time.Second
+
+// NewProvider creates a provider from its configuration. openaiAPIKey is used for
+// OpenAI when the configuration does not name an API key variable.
+func NewProvider(cfg AIProviderConfig, openaiAPIKey string) (Provider, error) {
+	apiKey := ""
+	if cfg.APIKeyEnv != "" {
+		apiKey = os.Getenv(cfg.APIKeyEnv)
+	}
+
+	switch cfg.Type {
+	case ProviderOpenAI:
+		if apiKey == "" {
+			apiKey = openaiAPIKey
+		}
+		if apiKey == "" {
+			return nil, fmt.Errorf("openai provider requires an API key")
+		}
+		model := cfg.Model
+		if model == "" {
+			model = OpenAINarratorModel()
+		}
+		p := NewOpenAIProvider(apiKey, model)
+		if cfg.URL != "" {
+			p.endpoint = cfg.URL
+		}
+		return p, nil
+	case ProviderAnthropic:
+		if apiKey == "" {
+			apiKey = os.Getenv("ANTHROPIC_API_KEY")
+		}
+		if apiKey == "" {
+			return nil, fmt.Errorf("anthropic provider requires an API key (ANTHROPIC_API_KEY)")
+		}
+		p := NewAnthropicProvider(apiKey, cfg.Model)
+		if cfg.URL != "" {
+			p.endpoint = cfg.URL
+		}
+		return p, nil
+	case ProviderOllama:
+		return NewOllamaProvider(cfg.URL, cfg.Model), nil
+	default:
+		return nil, fmt.Errorf("unknown AI provider type: %q", cfg.Type)
+	}
+}
+
+// NewProviderChainFromConfig creates a fallback chain from the configured providers in order
+func NewProviderChainFromConfig(configs []AIProviderConfig, openaiAPIKey string) (*ProviderChain, error) {
+	chain := NewProviderChain()
+	for i, cfg := range configs {
+		p, err := NewProvider(cfg, openaiAPIKey)
+		if err != nil {
+			return nil, fmt.Errorf("aiProviders[%d]: %w", i, err)
+		}
+		timeout := defaultProviderTimeout
+		if cfg.Timeout != "" {
+			d, err := time.ParseDuration(cfg.Timeout)
+			if err != nil || d <= 0 {
+				return nil, fmt.Errorf("aiProviders[%d]: invalid timeout %q", i, cfg.Timeout)
+			}
+			timeout = d
+		}
+		chain.Add(p, timeout)
+	}
+	return chain, nil
+}
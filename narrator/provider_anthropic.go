@@ -0,0 +1,145 @@
+package narrator
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+)
+
+// anthropicMessagesURL is the endpoint of the Anthropic Messages API
+const anthropicMessagesURL = "https://api.anthropic.com/v1/messages"
+
+// anthropicAPIVersion is the Anthropic API version sent with every request
+const anthropicAPIVersion = "2023-06-01"
+
+// defaultAnthropicModel is the model used when none is configured
+const defaultAnthropicModel = "claude-3-5-haiku-latest"
+
+// AnthropicProvider completes prompts with the Anthropic Messages API
+type AnthropicProvider struct {
+	apiKey     string
+	model      string
+	endpoint   string
+	httpClient *http.Client
+}
+
+// AnthropicNarratorModel returns the Anthropic model used for narration
+func AnthropicNarratorModel() string {
+	// Check environment variable for model override
+	if model := os.Getenv("ANTHROPIC_NARRATOR_MODEL"); model != "" {
+		return model
+	}
+	return defaultAnthropicModel
+}
+
+// NewAnthropicProvider creates a new Anthropic provider; an empty model uses AnthropicNarratorModel
+func NewAnthropicProvider(apiKey, model string) *AnthropicProvider {
+	if model == "" {
+		model = AnthropicNarratorModel()
+	}
+	return &AnthropicProvider{
+		apiKey:   apiKey,
+		model:    model,
+		endpoint: anthropicMessagesURL,
+		httpClient: &http.Client{
+			Timeout: 10 * time.Second,
+		},
+	}
+}
+
+// Name returns the provider name
+func (p *AnthropicProvider) Name() string {
+	return fmt.Sprintf("%s(%s)", ProviderAnthropic, p.model)
+}
+
+// Anthropic API structures
+type anthropicRequest struct {
+	Model       string             `json:"model"`
+	System      string             `json:"system,omitempty"`
+	Messages    []anthropicMessage `json:"messages"`
+	Temperature float64            `json:"temperature"`
+	MaxTokens   int                `json:"max_tokens"`
+}
+
+type anthropicMessage struct {
+	Role    string `json:"role"`
+	Content string `json:"content"`
+}
+
+type anthropicResponse struct {
+	Content []struct {
+		Type string `json:"type"`
+		Text string `json:"text"`
+	} `json:"content"`
+	Error *struct {
+		Type    string `json:"type"`
+		Message string `json:"message"`
+	} `json:"error,omitempty"`
+}
+
+// Complete makes the actual API call to Anthropic
+func (p *AnthropicProvider) Complete(ctx context.Context, req CompletionRequest) (string, error) {
+	request := anthropicRequest{
+		Model:  p.model,
+		System: req.System,
+		Messages: []anthropicMessage{
+			{
+				Role:    "user",
+				Content: req.Prompt,
+			},
+		},
+		Temperature: min(req.Temperature, 1), // Anthropic accepts 0 to 1
+		MaxTokens:   req.MaxTokens,
+	}
+
+	jsonData, err := json.Marshal(request)
+	if err != nil {
+		return "", err
+	}
+
+	httpReq, err := http.NewRequestWithContext(ctx, "POST", p.endpoint, bytes.NewBuffer(jsonData))
+	if err != nil {
+		return "", err
+	}
+
+	httpReq.Header.Set("Content-Type", "application/json")
+	httpReq.Header.Set("x-api-key", p.apiKey)
+	httpReq.Header.Set("anthropic-version", anthropicAPIVersion)
+
+	resp, err := p.httpClient.Do(httpReq)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return "", err
+	}
+
+	var response anthropicResponse
+	if err := json.Unmarshal(body, &response); err != nil {
+		return "", err
+	}
+
+	if response.Error != nil {
+		return "", fmt.Errorf("Anthropic API error: %s", response.Error.Message)
+	}
+
+	var text strings.Builder
+	for _, content := range response.Content {
+		if content.Type == "text" {
+			text.WriteString(content.Text)
+		}
+	}
+	if text.Len() == 0 {
+		return "", fmt.Errorf("no response from Anthropic")
+	}
+	return strings.TrimSpace(text.String()), nil
+}
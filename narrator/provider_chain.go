@@ -0,0 +1,224 @@
+package narrator
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/kazegusuri/claude-companion/logger"
+)
+
+// Health tracking of chained providers
+const (
+	providerCooldown    = 30 * time.Second // Time a provider is skipped after its first failure
+	providerMaxCooldown = 5 * time.Minute  // Upper bound of the doubling cooldown
+)
+
+// ProviderHealth is the health of a provider in a chain
+type ProviderHealth struct {
+	Name        string
+	Healthy     bool
+	Failures    int       // Consecutive failures
+	DownUntil   time.Time // The provider is skipped until this time
+	LastError   string    // Error of the last failed request; empty if none failed
+	LastErrorAt time.Time
+	QuotaErrors int // Requests refused for exceeding the quota or rate limit
+}
+
+// providerErrors counts the failed requests of a provider
+type providerErrors struct {
+	failures    int // Consecutive failures
+	lastError   string
+	lastErrorAt time.Time
+	quotaErrors int
+}
+
+// recordError counts the result of a request
+func (p *providerErrors) recordError(err error, now time.Time) {
+	if err == nil {
+		p.failures = 0
+		return
+	}
+	p.failures++
+	p.lastError = err.Error()
+	p.lastErrorAt = now
+	if isQuotaError(err) {
+		p.quotaErrors++
+	}
+}
+
+// health returns the health of the provider named name
+func (p *providerErrors) health(name string) ProviderHealth {
+	return ProviderHealth{
+		Name:        name,
+		Healthy:     true,
+		Failures:    p.failures,
+		LastError:   p.lastError,
+		LastErrorAt: p.lastErrorAt,
+		QuotaErrors: p.quotaErrors,
+	}
+}
+
+// isQuotaError reports whether a provider refused a request for exceeding the
+// quota of the account or its rate limit, such as OpenAI's insufficient_quota
+func isQuotaError(err error) bool {
+	msg := strings.ToLower(err.Error())
+	return strings.Contains(msg, "quota") || strings.Contains(msg, "rate limit") || strings.Contains(msg, "status 429")
+}
+
+// chainEntry is a provider in a chain with its health
+type chainEntry struct {
+	providerErrors
+	provider  Provider
+	timeout   time.Duration
+	downUntil time.Time
+}
+
+// ProviderChain tries its providers in order until one succeeds. A provider that
+// fails is skipped for a cooldown that doubles with each consecutive failure.
+type ProviderChain struct {
+	mu      sync.Mutex
+	entries []*chainEntry
+	now     func() time.Time
+}
+
+// NewProviderChain creates an empty provider chain
+func NewProviderChain() *ProviderChain {
+	return &ProviderChain{now: time.Now}
+}
+
+// Add appends a provider with its request timeout
+func (c *ProviderChain) Add(p Provider, timeout time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.entries = append(c.entries, &chainEntry{provider: p, timeout: timeout})
+}
+
+// Name returns the names of the providers in order
+func (c *ProviderChain) Name() string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	names := make([]string, len(c.entries))
+	for i, e := range c.entries {
+		names[i] = e.provider.Name()
+	}
+	return strings.Join(names, " -> ")
+}
+
+// Timeout returns the longest time a request can take through the whole chain
+func (c *ProviderChain) Timeout() time.Duration {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	var total time.Duration
+	for _, e := range c.entries {
+		total += e.timeout
+	}
+	return total
+}
+
+// Health returns the health of each provider in order
+func (c *ProviderChain) Health() []ProviderHealth {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	now := c.now()
+	health := make([]ProviderHealth, len(c.entries))
+	for i, e := range c.entries {
+		health[i] = e.health(e.provider.Name())
+		health[i].Healthy = !now.Before(e.downUntil)
+		health[i].DownUntil = e.downUntil
+	}
+	return health
+}
+
+// Complete completes a prompt with the first healthy provider that succeeds
+func (c *ProviderChain) Complete(ctx context.Context, req CompletionRequest) (string, error) {
+	return c.try(ctx, func(ctx context.Context, p Provider) (string, bool, error) {
+		result, err := p.Complete(ctx, req)
+		return result, false, err
+	})
+}
+
+// CompleteStream streams a completion from the first healthy provider that succeeds.
+// Providers that cannot stream deliver their whole completion as one delta. Once a
+// provider has streamed content, its failure ends the chain since the content
+// cannot be taken back.
+func (c *ProviderChain) CompleteStream(ctx context.Context, req CompletionRequest, onDelta func(string)) (string, error) {
+	return c.try(ctx, func(ctx context.Context, p Provider) (string, bool, error) {
+		sp, ok := p.(StreamingProvider)
+		if !ok {
+			result, err := p.Complete(ctx, req)
+			if err == nil {
+				onDelta(result)
+			}
+			return result, false, err
+		}
+		streamed := false
+		result, err := sp.CompleteStream(ctx, req, func(delta string) {
+			streamed = true
+			onDelta(delta)
+		})
+		return result, streamed, err
+	})
+}
+
+// try runs call against each healthy provider until one succeeds or a call reports it cannot be retried
+func (c *ProviderChain) try(ctx context.Context, call func(ctx context.Context, p Provider) (string, bool, error)) (string, error) {
+	var errs []error
+	for _, e := range c.healthy() {
+		callCtx, cancel := context.WithTimeout(ctx, e.timeout)
+		result, final, err := call(callCtx, e.provider)
+		cancel()
+
+		if err != nil && ctx.Err() != nil {
+			// The caller gave up; this is not the provider's fault
+			return result, err
+		}
+		c.record(e, err)
+		if err == nil {
+			logger.LogDebug("AI provider %s answered", e.provider.Name())
+			return result, nil
+		}
+		errs = append(errs, fmt.Errorf("%s: %w", e.provider.Name(), err))
+		if final {
+			return result, errors.Join(errs...)
+		}
+		logger.LogWarning("AI provider %s failed, trying the next one: %v", e.provider.Name(), err)
+	}
+	if len(errs) == 0 {
+		return "", fmt.Errorf("no healthy AI provider")
+	}
+	return "", errors.Join(errs...)
+}
+
+// healthy returns the providers that are not cooling down, in order
+func (c *ProviderChain) healthy() []*chainEntry {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	now := c.now()
+	var entries []*chainEntry
+	for _, e := range c.entries {
+		if !now.Before(e.downUntil) {
+			entries = append(entries, e)
+		}
+	}
+	return entries
+}
+
+// record updates the health of a provider after a request
+func (c *ProviderChain) record(e *chainEntry, err error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	e.recordError(err, c.now())
+	if err == nil {
+		e.downUntil = time.Time{}
+		return
+	}
+	cooldown := providerCooldown << min(e.failures-1, 10)
+	if cooldown > providerMaxCooldown {
+		cooldown = providerMaxCooldown
+	}
+	e.downUntil = c.now().Add(cooldown)
+}
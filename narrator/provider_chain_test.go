@@ -0,0 +1,307 @@
+package narrator
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+// fakeProvider returns a fixed result or error and counts its calls
+type fakeProvider struct {
+	name   string
+	result string
+	err    error
+	calls  int
+}
+
+func (p *fakeProvider) Name() string { return p.name }
+
+func (p *fakeProvider) Complete(ctx context.Context, req CompletionRequest) (string, error) {
+	p.calls++
+	return p.result, p.err
+}
+
+// fakeStreamingProvider streams its deltas and then returns err
+type fakeStreamingProvider struct {
+	fakeProvider
+	deltas []string
+}
+
+func (p *fakeStreamingProvider) CompleteStream(ctx context.Context, req CompletionRequest, onDelta func(string)) (string, error) {
+	p.calls++
+	for _, d := range p.deltas {
+		onDelta(d)
+	}
+	return p.result, p.err
+}
+
+func TestProviderChain_Fallback(t *testing.T) {
+	now := time.Date(2025, 1, 26, 10, 0, 0, 0, time.UTC)
+	primary := &fakeProvider{name: "primary", result: "from primary"}
+	secondary := &fakeProvider{name: "secondary", result: "from secondary"}
+
+	chain := NewProviderChain()
+	chain.now = func() time.Time { return now }
+	chain.Add(primary, time.Second)
+	chain.Add(secondary, time.Second)
+
+	tests := []struct {
+		name          string
+		advance       time.Duration
+		primaryErr    error
+		wantPrimary   int
+		wantSecondary int
+		wantResult    string
+		wantHealthy   bool
+	}{
+		{name: "primary fails, secondary answers", primaryErr: errors.New("timeout"), wantPrimary: 1, wantSecondary: 1, wantResult: "from secondary"},
+		{name: "failed primary is skipped", advance: 10 * time.Second, primaryErr: errors.New("timeout"), wantPrimary: 1, wantSecondary: 2, wantResult: "from secondary"},
+		{name: "primary is retried after the cooldown", advance: providerCooldown, primaryErr: errors.New("timeout"), wantPrimary: 2, wantSecondary: 3, wantResult: "from secondary"},
+		{name: "second failure doubles the cooldown", advance: providerCooldown, wantPrimary: 2, wantSecondary: 4, wantResult: "from secondary"},
+		{name: "recovered primary answers", advance: providerCooldown, wantPrimary: 3, wantSecondary: 4, wantResult: "from primary", wantHealthy: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			now = now.Add(tt.advance)
+			primary.err = tt.primaryErr
+			result, err := chain.Complete(context.Background(), CompletionRequest{Prompt: "hi"})
+			if err != nil {
+				t.Fatalf("Complete() error = %v", err)
+			}
+			if result != tt.wantResult {
+				t.Errorf("Complete() = %q, want %q", result, tt.wantResult)
+			}
+			if primary.calls != tt.wantPrimary || secondary.calls != tt.wantSecondary {
+				t.Errorf("calls = %d/%d, want %d/%d", primary.calls, secondary.calls, tt.wantPrimary, tt.wantSecondary)
+			}
+			if healthy := chain.Health()[0].Healthy; healthy != tt.wantHealthy {
+				t.Errorf("primary healthy = %v, want %v", healthy, tt.wantHealthy)
+			}
+		})
+	}
+}
+
+func TestProviderChain_AllFail(t *testing.T) {
+	chain := NewProviderChain()
+	chain.Add(&fakeProvider{name: "a", err: errors.New("boom")}, time.Second)
+	chain.Add(&fakeProvider{name: "b", err: errors.New("bang")}, time.Second)
+
+	if _, err := chain.Complete(context.Background(), CompletionRequest{}); err == nil {
+		t.Fatal("expected an error when every provider fails")
+	}
+	// Both providers are now cooling down
+	if _, err := chain.Complete(context.Background(), CompletionRequest{}); err == nil || err.Error() != "no healthy AI provider" {
+		t.Errorf("error = %v, want no healthy AI provider", err)
+	}
+}
+
+func TestProviderChain_CompleteStream(t *testing.T) {
+	tests := []struct {
+		name       string
+		providers  []Provider
+		wantDeltas []string
+		wantErr    bool
+	}{
+		{
+			name: "falls back before anything was streamed",
+			providers: []Provider{
+				&fakeStreamingProvider{fakeProvider: fakeProvider{name: "a", err: errors.New("refused")}},
+				&fakeProvider{name: "b", result: "全部です。"},
+			},
+			wantDeltas: []string{"全部です。"},
+		},
+		{
+			name: "no fallback after a partial stream",
+			providers: []Provider{
+				&fakeStreamingProvider{fakeProvider: fakeProvider{name: "a", err: errors.New("reset")}, deltas: []string{"途中"}},
+				&fakeProvider{name: "b", result: "unused"},
+			},
+			wantDeltas: []string{"途中"},
+			wantErr:    true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			chain := NewProviderChain()
+			for _, p := range tt.providers {
+				chain.Add(p, time.Second)
+			}
+			var deltas []string
+			_, err := chain.CompleteStream(context.Background(), CompletionRequest{}, func(d string) { deltas = append(deltas, d) })
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("CompleteStream() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if diff := cmp.Diff(tt.wantDeltas, deltas); diff != "" {
+				t.Errorf("deltas mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestNewProviderChainFromConfig(t *testing.T) {
+	t.Setenv("TEST_ANTHROPIC_KEY", "sk-ant")
+
+	tests := []struct {
+		name     string
+		configs  []AIProviderConfig
+		wantName string
+		wantErr  bool
+	}{
+		{
+			name: "openai, anthropic and ollama",
+			configs: []AIProviderConfig{
+				{Type: "openai", Model: "gpt-4.1-nano"},
+				{Type: "anthropic", APIKeyEnv: "TEST_ANTHROPIC_KEY", Model: "claude-3-5-haiku-latest", Timeout: "3s"},
+				{Type: "ollama"},
+			},
+			wantName: "openai(gpt-4.1-nano) -> anthropic(claude-3-5-haiku-latest) -> ollama(llama3.2)",
+		},
+		{name: "unknown type", configs: []AIProviderConfig{{Type: "gemini"}}, wantErr: true},
+		{name: "missing key", configs: []AIProviderConfig{{Type: "anthropic", APIKeyEnv: "TEST_MISSING_KEY"}}, wantErr: true},
+		{name: "invalid timeout", configs: []AIProviderConfig{{Type: "ollama", Timeout: "soon"}}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("ANTHROPIC_API_KEY", "")
+			chain, err := NewProviderChainFromConfig(tt.configs, "sk-openai")
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("NewProviderChainFromConfig() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if got := chain.Name(); got != tt.wantName {
+				t.Errorf("Name() = %q, want %q", got, tt.wantName)
+			}
+		})
+	}
+}
+
+func TestAnthropicProvider_Complete(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("x-api-key") != "sk-ant" || r.Header.Get("anthropic-version") == "" {
+			w.WriteHeader(http.StatusUnauthorized)
+			w.Write([]byte(`{"type":"error","error":{"type":"authentication_error","message":"invalid x-api-key"}}`))
+			return
+		}
+		var req anthropicRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.System == "" || len(req.Messages) != 1 {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		w.Write([]byte(`{"content":[{"type":"text","text":" テストを実行します "}]}`))
+	}))
+	defer ts.Close()
+
+	p := NewAnthropicProvider("sk-ant", "")
+	p.endpoint = ts.URL
+	got, err := p.Complete(context.Background(), CompletionRequest{System: "system", Prompt: "prompt", MaxTokens: 50})
+	if err != nil {
+		t.Fatalf("Complete() error = %v", err)
+	}
+	if got != "テストを実行します" {
+		t.Errorf("Complete() = %q", got)
+	}
+
+	p.apiKey = "wrong"
+	if _, err := p.Complete(context.Background(), CompletionRequest{System: "system", Prompt: "prompt"}); err == nil {
+		t.Error("expected an authentication error")
+	}
+}
+
+func TestOllamaProvider_Complete(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/chat" {
+			http.NotFound(w, r)
+			return
+		}
+		var req ollamaRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.Stream || req.Model != "qwen2.5" {
+			w.Write([]byte(`{"error":"model not found"}`))
+			return
+		}
+		w.Write([]byte(`{"message":{"role":"assistant","content":"ファイルを読み込みます"}}`))
+	}))
+	defer ts.Close()
+
+	got, err := NewOllamaProvider(ts.URL+"/", "qwen2.5").Complete(context.Background(), CompletionRequest{Prompt: "prompt"})
+	if err != nil {
+		t.Fatalf("Complete() error = %v", err)
+	}
+	if got != "ファイルを読み込みます" {
+		t.Errorf("Complete() = %q", got)
+	}
+
+	if _, err := NewOllamaProvider(ts.URL, "missing").Complete(context.Background(), CompletionRequest{}); err == nil {
+		t.Error("expected an error for a missing model")
+	}
+}
+
+func TestOllamaNarrator(t *testing.T) {
+	var requests []ollamaRequest
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req ollamaRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		requests = append(requests, req)
+		w.Write([]byte(`{"message":{"role":"assistant","content":"ローカルモデルの応答"}}`))
+	}))
+	defer ts.Close()
+
+	text := "The build finished.\nThere are two warnings."
+	n := NewAINarrator(NewOllamaProvider(ts.URL, "qwen2.5"))
+	if got, fallback := n.NarrateText(text, false); fallback || got != "ローカルモデルの応答" {
+		t.Errorf("NarrateText() = %q, %v", got, fallback)
+	}
+
+	translator := NewAITranslator(n.Provider())
+	if got, err := translator.Translate(context.Background(), "Reading the configuration file"); err != nil || got != "ローカルモデルの応答" {
+		t.Errorf("Translate() = %q, %v", got, err)
+	}
+
+	// Narration and translation use the same prompts as with OpenAI
+	if len(requests) != 2 {
+		t.Fatalf("got %d requests, want 2", len(requests))
+	}
+	prompt, _ := textPrompt(text, false)
+	if got := requests[0].Messages; got[0].Content != n.systemPrompt() || got[1].Content != prompt {
+		t.Errorf("narration messages = %+v", got)
+	}
+	if got := requests[1].Messages; got[0].Content != translationSystemPrompt || got[1].Content != "Reading the configuration file" {
+		t.Errorf("translation messages = %+v", got)
+	}
+}
+
+func TestProviderHealth_QuotaErrors(t *testing.T) {
+	quota := errors.New("OpenAI API error: You exceeded your current quota, please check your plan and billing details.")
+	openai := &fakeProvider{name: "openai", err: quota}
+	ai := NewAINarrator(openai)
+
+	for range 2 {
+		ai.complete(context.Background(), "hi", 0, 10)
+	}
+	openai.err = errors.New("timeout")
+	ai.complete(context.Background(), "hi", 0, 10)
+
+	health := ai.Health()
+	if len(health) != 1 || health[0].QuotaErrors != 2 || health[0].Failures != 3 || health[0].LastError != "timeout" {
+		t.Errorf("Health() = %+v, want 2 quota errors of 3 failures, the last a timeout", health)
+	}
+
+	openai.err = nil
+	ai.complete(context.Background(), "hi", 0, 10)
+	if health := ai.Health(); health[0].Failures != 0 || health[0].QuotaErrors != 2 {
+		t.Errorf("Health() after an answer = %+v, want no consecutive failures and the quota errors kept", health)
+	}
+}
@@ -0,0 +1,123 @@
+package narrator
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// Defaults of a local Ollama server
+const (
+	defaultOllamaURL   = "http://localhost:11434"
+	defaultOllamaModel = "llama3.2"
+)
+
+// OllamaProvider completes prompts with a local Ollama server
+type OllamaProvider struct {
+	baseURL    string
+	model      string
+	httpClient *http.Client
+}
+
+// NewOllamaProvider creates a new Ollama provider; empty values use the defaults
+func NewOllamaProvider(baseURL, model string) *OllamaProvider {
+	if baseURL == "" {
+		baseURL = defaultOllamaURL
+	}
+	if model == "" {
+		model = defaultOllamaModel
+	}
+	return &OllamaProvider{
+		baseURL: strings.TrimRight(baseURL, "/"),
+		model:   model,
+		httpClient: &http.Client{
+			// Local models can be slow to load on the first request
+			Timeout: 30 * time.Second,
+		},
+	}
+}
+
+// Name returns the provider name
+func (p *OllamaProvider) Name() string {
+	return fmt.Sprintf("%s(%s)", ProviderOllama, p.model)
+}
+
+// Ollama API structures
+type ollamaRequest struct {
+	Model    string          `json:"model"`
+	Messages []openAIMessage `json:"messages"`
+	Stream   bool            `json:"stream"`
+	Options  ollamaOptions   `json:"options"`
+}
+
+type ollamaOptions struct {
+	Temperature float64 `json:"temperature"`
+	NumPredict  int     `json:"num_predict,omitempty"`
+}
+
+type ollamaResponse struct {
+	Message openAIMessage `json:"message"`
+	Error   string        `json:"error,omitempty"`
+}
+
+// Complete makes the actual API call to Ollama
+func (p *OllamaProvider) Complete(ctx context.Context, req CompletionRequest) (string, error) {
+	request := ollamaRequest{
+		Model: p.model,
+		Messages: []openAIMessage{
+			{
+				Role:    "system",
+				Content: req.System,
+			},
+			{
+				Role:    "user",
+				Content: req.Prompt,
+			},
+		},
+		Options: ollamaOptions{
+			Temperature: req.Temperature,
+			NumPredict:  req.MaxTokens,
+		},
+	}
+
+	jsonData, err := json.Marshal(request)
+	if err != nil {
+		return "", err
+	}
+
+	httpReq, err := http.NewRequestWithContext(ctx, "POST", p.baseURL+"/api/chat", bytes.NewBuffer(jsonData))
+	if err != nil {
+		return "", err
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+
+	resp, err := p.httpClient.Do(httpReq)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return "", err
+	}
+
+	var response ollamaResponse
+	if err := json.Unmarshal(body, &response); err != nil {
+		return "", err
+	}
+
+	if response.Error != "" {
+		return "", fmt.Errorf("Ollama error: %s", response.Error)
+	}
+
+	if content := strings.TrimSpace(response.Message.Content); content != "" {
+		return content, nil
+	}
+	return "", fmt.Errorf("no response from Ollama")
+}
@@ -0,0 +1,170 @@
+package narrator
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// OpenAIProvider completes prompts with the OpenAI Chat Completions API
+type OpenAIProvider struct {
+	apiKey     string
+	model      string
+	endpoint   string
+	httpClient *http.Client
+}
+
+// NewOpenAIProvider creates a new OpenAI provider
+func NewOpenAIProvider(apiKey, model string) *OpenAIProvider {
+	return &OpenAIProvider{
+		apiKey:   apiKey,
+		model:    model,
+		endpoint: openAIChatCompletionsURL,
+		httpClient: &http.Client{
+			Timeout: 10 * time.Second,
+		},
+	}
+}
+
+// Name returns the provider name
+func (p *OpenAIProvider) Name() string {
+	return fmt.Sprintf("%s(%s)", ProviderOpenAI, p.model)
+}
+
+// newRequest builds a Chat Completions request
+func (p *OpenAIProvider) newRequest(req CompletionRequest) openAIRequest {
+	return openAIRequest{
+		Model: p.model,
+		Messages: []openAIMessage{
+			{
+				Role:    "system",
+				Content: req.System,
+			},
+			{
+				Role:    "user",
+				Content: req.Prompt,
+			},
+		},
+		Temperature: req.Temperature,
+		MaxTokens:   req.MaxTokens,
+	}
+}
+
+// post sends a Chat Completions request
+func (p *OpenAIProvider) post(ctx context.Context, request openAIRequest) (*http.Response, error) {
+	jsonData, err := json.Marshal(request)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "POST", p.endpoint, bytes.NewBuffer(jsonData))
+	if err != nil {
+		return nil, err
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	if request.Stream {
+		req.Header.Set("Accept", "text/event-stream")
+	}
+	req.Header.Set("Authorization", "Bearer "+p.apiKey)
+
+	return p.httpClient.Do(req)
+}
+
+// Complete makes the actual API call to OpenAI
+func (p *OpenAIProvider) Complete(ctx context.Context, req CompletionRequest) (string, error) {
+	resp, err := p.post(ctx, p.newRequest(req))
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return "", err
+	}
+
+	var response openAIResponse
+	if err := json.Unmarshal(body, &response); err != nil {
+		return "", err
+	}
+
+	if response.Error != nil {
+		return "", fmt.Errorf("OpenAI API error: %s", response.Error.Message)
+	}
+
+	if len(response.Choices) > 0 {
+		return strings.TrimSpace(response.Choices[0].Message.Content), nil
+	}
+
+	return "", fmt.Errorf("no response from OpenAI")
+}
+
+// openAIStreamChunk is a chunk of a streamed Chat Completions response
+type openAIStreamChunk struct {
+	Choices []struct {
+		Delta struct {
+			Content string `json:"content"`
+		} `json:"delta"`
+	} `json:"choices"`
+	Error *openAIError `json:"error,omitempty"`
+}
+
+// CompleteStream streams the completion as Server-Sent Events, calling onDelta
+// with each piece of content as it arrives, and returns the whole content
+func (p *OpenAIProvider) CompleteStream(ctx context.Context, req CompletionRequest, onDelta func(string)) (string, error) {
+	request := p.newRequest(req)
+	request.Stream = true
+
+	resp, err := p.post(ctx, request)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		var response openAIResponse
+		if err := json.Unmarshal(body, &response); err == nil && response.Error != nil {
+			return "", fmt.Errorf("OpenAI API error: %s", response.Error.Message)
+		}
+		return "", fmt.Errorf("OpenAI API returned status %d", resp.StatusCode)
+	}
+
+	var content strings.Builder
+	scanner := bufio.NewScanner(resp.Body)
+	for scanner.Scan() {
+		data, ok := strings.CutPrefix(scanner.Text(), "data:")
+		if !ok {
+			continue // blank separators and SSE comments
+		}
+		data = strings.TrimSpace(data)
+		if data == "[DONE]" {
+			return content.String(), nil
+		}
+
+		var chunk openAIStreamChunk
+		if err := json.Unmarshal([]byte(data), &chunk); err != nil {
+			return content.String(), err
+		}
+		if chunk.Error != nil {
+			return content.String(), fmt.Errorf("OpenAI API error: %s", chunk.Error.Message)
+		}
+		for _, choice := range chunk.Choices {
+			if choice.Delta.Content != "" {
+				content.WriteString(choice.Delta.Content)
+				onDelta(choice.Delta.Content)
+			}
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return content.String(), err
+	}
+	return content.String(), fmt.Errorf("OpenAI stream ended without [DONE]")
+}
@@ -3,20 +3,41 @@ package narrator
 import (
 	"fmt"
 	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
 	"strings"
+	"sync"
+	"time"
+
+	"github.com/kazegusuri/claude-companion/logger"
 )
 
 // RuleBasedNarrator uses configuration file for narrative rules
 type RuleBasedNarrator struct {
 	config        *NarratorConfig
 	defaultConfig *NarratorConfig
+	baseConfig    *NarratorConfig // built-in Japanese config used when a message is missing
+	language      Language
+	warned        sync.Map // Tools already reported as having no narration
 }
 
 // NewRuleBasedNarrator creates a new rule-based narrator
 func NewRuleBasedNarrator(config *NarratorConfig) *RuleBasedNarrator {
+	return NewRuleBasedNarratorWithLanguage(config, LanguageJapanese)
+}
+
+// NewRuleBasedNarratorWithLanguage creates a new rule-based narrator for the given language.
+// A nil config narrates with the defaults only.
+func NewRuleBasedNarratorWithLanguage(config *NarratorConfig, lang Language) *RuleBasedNarrator {
+	if config == nil {
+		config = &NarratorConfig{}
+	}
 	return &RuleBasedNarrator{
 		config:        config,
-		defaultConfig: GetDefaultNarratorConfig(),
+		defaultConfig: GetDefaultNarratorConfigForLanguage(lang),
+		baseConfig:    GetDefaultNarratorConfig(),
+		language:      lang,
 	}
 }
 
@@ -58,12 +79,32 @@ func parseMCPToolName(toolName string) (server string, operation string, isMCP b
 	return "", "", false
 }
 
-// getStringOrDefault returns the value from config if not empty, otherwise from defaultConfig
-func (cn *RuleBasedNarrator) getStringOrDefault(configValue, defaultValue string) string {
-	if configValue != "" {
-		return configValue
+// message returns the first non-empty message template from config, the default config
+// for the language, and the built-in Japanese config
+func (cn *RuleBasedNarrator) message(get func(MessageTemplates) string) string {
+	for _, config := range []*NarratorConfig{cn.config, cn.defaultConfig, cn.baseConfig} {
+		if config == nil {
+			continue
+		}
+		if msg := get(config.Messages); msg != "" {
+			return msg
+		}
 	}
-	return defaultValue
+	return ""
+}
+
+// notification returns the message for a notification type from config, the default config
+// for the language, and the built-in Japanese config
+func (cn *RuleBasedNarrator) notification(notificationType NotificationType) string {
+	for _, config := range []*NarratorConfig{cn.config, cn.defaultConfig, cn.baseConfig} {
+		if config == nil {
+			continue
+		}
+		if msg := config.Notifications[string(notificationType)]; msg != "" {
+			return msg
+		}
+	}
+	return ""
 }
 
 // applyCaptures applies capture rules to a template string using input values
@@ -110,7 +151,10 @@ func (cn *RuleBasedNarrator) applyCaptures(template string, captures []CaptureRu
 				ext := filepath.Ext(strValue)
 				fileTypeName := cn.getFileTypeName(ext)
 				if fileTypeName == "" {
-					fileTypeName = "ファイル" // Default for unknown extensions
+					fileTypeName = cn.message(func(m MessageTemplates) string { return m.UnknownFileType })
+				}
+				if fileTypeName == "" {
+					fileTypeName = localize(cn.language, "ファイル", "file") // Default for unknown extensions
 				}
 				result = strings.ReplaceAll(result, "{filetype}", fileTypeName)
 			}
@@ -160,8 +204,91 @@ func (cn *RuleBasedNarrator) handleGenericMCPTool(toolName string, rules ToolRul
 	return rules.Default
 }
 
-// NarrateToolUse converts tool usage to natural Japanese using config rules
+// unfilledPlaceholder matches a placeholder left in a message, such as {filename}
+var unfilledPlaceholder = regexp.MustCompile(`\{[a-z_]+\}`)
+
+// toolRules returns the rules of a tool from config, or from the default config. A rule
+// of config without any message of its own (only a permission message, say) takes the
+// messages of the default rule, so it still narrates the tool.
+func (cn *RuleBasedNarrator) toolRules(toolName string) (ToolRules, bool) {
+	rules, ok := cn.config.Rules[toolName]
+	var defaults ToolRules
+	var hasDefaults bool
+	if cn.defaultConfig != nil {
+		defaults, hasDefaults = cn.defaultConfig.Rules[toolName]
+	}
+	if !ok {
+		return defaults, hasDefaults
+	}
+	if hasDefaults && rules.Default == "" && len(rules.Prefixes) == 0 && len(rules.Patterns) == 0 {
+		rules.Default = defaults.Default
+		rules.Prefixes = defaults.Prefixes
+		rules.Patterns = defaults.Patterns
+		rules.Extensions = defaults.Extensions
+		rules.Captures = defaults.Captures
+	}
+	return rules, true
+}
+
+// ToolVerbosity returns how much of a tool is narrated on each output. The short message
+// is the one of the tool's rules, of its MCP server, or the generic tool message.
+func (cn *RuleBasedNarrator) ToolVerbosity(toolName string) ToolVerbosity {
+	v := DetailedVerbosity
+	defaults := cn.defaultConfig
+	if defaults == nil {
+		defaults = &NarratorConfig{}
+	}
+	if server, operation, isMCP := parseMCPToolName(toolName); isMCP {
+		mcpRules, found := cn.config.MCPRules[server]
+		if !found {
+			mcpRules = defaults.MCPRules[server]
+		}
+		rules := mcpRules.Rules[operation]
+		v.VerbosityRule = v.override(mcpRules.Verbosity).override(rules.Verbosity)
+		v.Short = rules.Short
+		if v.Short == "" && mcpRules.Default != "" {
+			v.Short = strings.ReplaceAll(mcpRules.Default, "{operation}", operation)
+		}
+	} else {
+		rules, _ := cn.toolRules(toolName)
+		v.VerbosityRule = v.override(rules.Verbosity)
+		v.Short = rules.Short
+		if v.Short == "" {
+			v.Short = defaults.Rules[toolName].Short
+		}
+	}
+	if v.Short == "" {
+		v.Short = strings.ReplaceAll(cn.message(func(m MessageTemplates) string { return m.GenericToolExecution }), "{tool}", toolName)
+	}
+	return v
+}
+
+// NarrateToolUse converts tool usage to natural Japanese using config rules. When the
+// rules of a tool produce no message, the tool is left to the fallback narrator with a
+// warning logged once per tool, so an unusual tool or an incomplete config never
+// silences or breaks narration.
 func (cn *RuleBasedNarrator) NarrateToolUse(toolName string, input map[string]interface{}) (string, bool) {
+	text, shouldFallback := cn.narrateToolUse(toolName, input)
+	if unfilledPlaceholder.MatchString(text) {
+		// The input lacks what the message is about, such as a Read without file_path
+		text = ""
+	}
+	if text != "" || shouldFallback {
+		return text, shouldFallback
+	}
+	if _, warned := cn.warned.LoadOrStore(toolName, true); !warned {
+		keys := make([]string, 0, len(input))
+		for key := range input {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		logger.LogWarning("Narrator rules of tool %s gave no message (input keys: %s); leaving it to the fallback narrator", toolName, strings.Join(keys, ", "))
+	}
+	return "", true
+}
+
+// narrateToolUse narrates a tool use with the config rules
+func (cn *RuleBasedNarrator) narrateToolUse(toolName string, input map[string]interface{}) (string, bool) {
 	// Handle MCP tools first with new MCPRules structure
 	if server, operation, isMCP := parseMCPToolName(toolName); isMCP {
 		// Check if we have MCPRules for this server
@@ -197,20 +324,15 @@ func (cn *RuleBasedNarrator) NarrateToolUse(toolName string, input map[string]in
 		return "", true
 	}
 
-	rules, ok := cn.config.Rules[toolName]
+	rules, ok := cn.toolRules(toolName)
 	if !ok {
-		// Try default config
-		if defaultRules, ok := cn.defaultConfig.Rules[toolName]; ok {
-			rules = defaultRules
-		} else {
-			// No rules for this tool in both configs
-			template := cn.getStringOrDefault(cn.config.Messages.GenericToolExecution, cn.defaultConfig.Messages.GenericToolExecution)
-			if template != "" {
-				return strings.ReplaceAll(template, "{tool}", toolName), false
-			}
-			// Return empty string for fallback
-			return "", true
+		// No rules for this tool in both configs
+		template := cn.message(func(m MessageTemplates) string { return m.GenericToolExecution })
+		if template != "" {
+			return strings.ReplaceAll(template, "{tool}", toolName), false
 		}
+		// Return empty string for fallback
+		return "", true
 	}
 
 	// Handle tool-specific logic
@@ -278,7 +400,7 @@ func (cn *RuleBasedNarrator) NarrateToolUse(toolName string, input map[string]in
 		}
 		// Set default path if not present
 		if _, hasPath := modifiedInput["path"]; !hasPath {
-			modifiedInput["path"] = "プロジェクト全体"
+			modifiedInput["path"] = cn.message(func(m MessageTemplates) string { return m.WholeProject })
 		}
 		return cn.handleGenericMCPTool(toolName, rules, modifiedInput), false
 
@@ -305,7 +427,7 @@ func (cn *RuleBasedNarrator) NarrateToolUse(toolName string, input map[string]in
 		if path, ok := input["path"].(string); ok {
 			dirName := filepath.Base(path)
 			if dirName == "." || dirName == "/" {
-				msg := cn.getStringOrDefault(cn.config.Messages.CurrentDirectory, cn.defaultConfig.Messages.CurrentDirectory)
+				msg := cn.message(func(m MessageTemplates) string { return m.CurrentDirectory })
 				if msg != "" {
 					return msg, false
 				}
@@ -314,7 +436,7 @@ func (cn *RuleBasedNarrator) NarrateToolUse(toolName string, input map[string]in
 			}
 			return strings.ReplaceAll(rules.Default, "{dirname}", dirName), false
 		}
-		msg := cn.getStringOrDefault(cn.config.Messages.DirectoryContents, cn.defaultConfig.Messages.DirectoryContents)
+		msg := cn.message(func(m MessageTemplates) string { return m.DirectoryContents })
 		if msg != "" {
 			return msg, false
 		}
@@ -349,7 +471,7 @@ func (cn *RuleBasedNarrator) NarrateToolUse(toolName string, input map[string]in
 		// Handle slash command first
 		if hasPrompt && strings.HasPrefix(prompt, "/") {
 			cmd := strings.Fields(prompt)[0]
-			template := cn.getStringOrDefault(cn.config.Messages.GenericCommandExecution, cn.defaultConfig.Messages.GenericCommandExecution)
+			template := cn.message(func(m MessageTemplates) string { return m.GenericCommandExecution })
 			if template != "" {
 				return strings.ReplaceAll(template, "{command}", cmd), false
 			}
@@ -360,14 +482,16 @@ func (cn *RuleBasedNarrator) NarrateToolUse(toolName string, input map[string]in
 		// Build message based on available info
 		if hasSubagentType && subagentType != "" && hasDesc {
 			// When subagent_type is not empty, include agent type and description
-			return fmt.Sprintf("%s agentでタスク「%s」を実行します", subagentType, desc), false
+			msg := cn.message(func(m MessageTemplates) string { return m.AgentTask })
+			msg = strings.ReplaceAll(msg, "{agent}", subagentType)
+			return strings.ReplaceAll(msg, "{description}", desc), false
 		} else if hasDesc {
 			// Just description
 			return strings.ReplaceAll(rules.Default, "{description}", desc), false
 		}
 
 		// Default message
-		msg := cn.getStringOrDefault(cn.config.Messages.ComplexTask, cn.defaultConfig.Messages.ComplexTask)
+		msg := cn.message(func(m MessageTemplates) string { return m.ComplexTask })
 		if msg != "" {
 			return msg, false
 		}
@@ -398,7 +522,7 @@ func (cn *RuleBasedNarrator) NarrateToolUse(toolName string, input map[string]in
 			msg = strings.ReplaceAll(msg, "{in_progress}", fmt.Sprintf("%d", inProgress))
 			return msg, false
 		}
-		msg := cn.getStringOrDefault(cn.config.Messages.TodoListUpdate, cn.defaultConfig.Messages.TodoListUpdate)
+		msg := cn.message(func(m MessageTemplates) string { return m.TodoListUpdate })
 		if msg != "" {
 			return msg, false
 		}
@@ -416,7 +540,7 @@ func (cn *RuleBasedNarrator) NarrateToolUse(toolName string, input map[string]in
 	}
 
 	// Generic fallback
-	template := cn.getStringOrDefault(cn.config.Messages.GenericToolExecution, cn.defaultConfig.Messages.GenericToolExecution)
+	template := cn.message(func(m MessageTemplates) string { return m.GenericToolExecution })
 	if template != "" {
 		return strings.ReplaceAll(template, "{tool}", toolName), false
 	}
@@ -441,13 +565,13 @@ func (cn *RuleBasedNarrator) NarrateToolUsePermission(toolName string) (string,
 	}
 
 	// Use generic permission message
-	template := cn.getStringOrDefault(cn.config.Messages.GenericToolPermission, cn.defaultConfig.Messages.GenericToolPermission)
+	template := cn.message(func(m MessageTemplates) string { return m.GenericToolPermission })
 	if template != "" {
 		return strings.ReplaceAll(template, "{tool}", toolName), false
 	}
 
 	// Final fallback
-	return fmt.Sprintf("%sの使用許可を求めています", toolName), true
+	return localize(cn.language, fmt.Sprintf("%sの使用許可を求めています", toolName), fmt.Sprintf("Requesting permission to use %s", toolName)), true
 }
 
 // NarrateText returns the text as-is
@@ -457,41 +581,146 @@ func (cn *RuleBasedNarrator) NarrateText(text string, isThinking bool) (string,
 
 // NarrateNotification narrates notification events
 func (cn *RuleBasedNarrator) NarrateNotification(notificationType NotificationType) (string, bool) {
-	// Return messages based on notification type
-	switch notificationType {
-	case NotificationTypeCompact:
-		return "コンテキストを圧縮しています", false
-	case NotificationTypeSessionStartStartup:
-		return "こんにちは！何かお手伝いできることはありますか？", false
-	case NotificationTypeSessionStartClear:
-		return "何かお手伝いできることはありますか？", false
-	case NotificationTypeSessionStartResume:
-		return "前回の作業を続けましょう。どこから再開しますか？", false
-	case NotificationTypeSessionStartCompact:
-		return "セッションを再開しました", false
-	default:
-		return "", true
+	if msg := cn.notification(notificationType); msg != "" {
+		return msg, false
 	}
+	return "", true
 }
 
 // NarrateTaskCompletion narrates task completion events
 func (cn *RuleBasedNarrator) NarrateTaskCompletion(description string, subagentType string) (string, bool) {
 	// Build message based on available information
+	var msg string
 	if subagentType != "" && description != "" {
-		return fmt.Sprintf("%s agentがタスク「%s」を完了しました", subagentType, description), false
+		msg = cn.message(func(m MessageTemplates) string { return m.TaskCompletedByAgent })
 	} else if description != "" {
-		return fmt.Sprintf("タスク「%s」が完了しました", description), false
+		msg = cn.message(func(m MessageTemplates) string { return m.TaskCompletedWithDescription })
+	} else {
+		msg = cn.message(func(m MessageTemplates) string { return m.TaskCompleted })
+	}
+	msg = strings.ReplaceAll(msg, "{agent}", subagentType)
+	return strings.ReplaceAll(msg, "{description}", description), false
+}
+
+// NarrateToolSLABreach narrates a tool that took longer than its expected maximum duration
+func (cn *RuleBasedNarrator) NarrateToolSLABreach(toolName string, elapsed, limit time.Duration) (string, bool) {
+	msg := cn.message(func(m MessageTemplates) string { return m.ToolSLABreach })
+	msg = strings.ReplaceAll(msg, "{tool}", toolName)
+	msg = strings.ReplaceAll(msg, "{elapsed}", spokenDuration(elapsed, cn.language))
+	return strings.ReplaceAll(msg, "{limit}", spokenDuration(limit, cn.language)), false
+}
+
+// NarrateToolDuration narrates how long a finished tool ran
+func (cn *RuleBasedNarrator) NarrateToolDuration(toolName string, elapsed time.Duration) (string, bool) {
+	msg := cn.message(func(m MessageTemplates) string { return m.ToolCompleted })
+	msg = strings.ReplaceAll(msg, "{tool}", toolName)
+	return strings.ReplaceAll(msg, "{elapsed}", spokenDuration(elapsed, cn.language)), false
+}
+
+// NarrateSidechainSummary narrates what a Task subagent did
+func (cn *RuleBasedNarrator) NarrateSidechainSummary(subagentType string, toolUses int, elapsed time.Duration) (string, bool) {
+	msg := cn.message(func(m MessageTemplates) string { return m.SidechainSummary })
+	msg = strings.ReplaceAll(msg, "{agent}", subagentType)
+	msg = strings.ReplaceAll(msg, "{count}", strconv.Itoa(toolUses))
+	return strings.ReplaceAll(msg, "{elapsed}", spokenDuration(elapsed, cn.language)), false
+}
+
+// NarrateSessionSummary narrates what a session did when it ended
+func (cn *RuleBasedNarrator) NarrateSessionSummary(summary SessionSummary) (string, bool) {
+	msg := cn.message(func(m MessageTemplates) string { return m.SessionSummary })
+	return strings.NewReplacer(
+		"{duration}", spokenDuration(summary.Duration, cn.language),
+		"{files}", strconv.Itoa(summary.FilesEdited),
+		"{commands}", strconv.Itoa(summary.Commands),
+		"{tasks}", strconv.Itoa(summary.TasksCompleted),
+		"{tokens}", spokenTokens(summary.Tokens, cn.language),
+	).Replace(msg), false
+}
+
+// NarrateBudgetAlert narrates usage that crossed a threshold of its budget
+func (cn *RuleBasedNarrator) NarrateBudgetAlert(alert BudgetAlert) (string, bool) {
+	msg := cn.message(func(m MessageTemplates) string {
+		switch {
+		case alert.Scope == "day" && alert.Metric == "cost":
+			return m.BudgetDayCost
+		case alert.Scope == "day":
+			return m.BudgetDayTokens
+		case alert.Metric == "cost":
+			return m.BudgetSessionCost
+		}
+		return m.BudgetSessionTokens
+	})
+	used := spokenTokens(int64(alert.Used), cn.language)
+	if alert.Metric == "cost" {
+		used = fmt.Sprintf("%.2f", alert.Used)
+	}
+	return strings.NewReplacer(
+		"{percent}", strconv.Itoa(alert.Percent),
+		"{used}", used,
+	).Replace(msg), false
+}
+
+// NarrateContextAlert narrates a context that crossed a threshold of its window, with
+// the context_{threshold} notification message or the contextAlert message
+func (cn *RuleBasedNarrator) NarrateContextAlert(threshold, percent int) (string, bool) {
+	msg := cn.notification(NotificationType(fmt.Sprintf("context_%d", threshold)))
+	if msg == "" {
+		msg = cn.message(func(m MessageTemplates) string { return m.ContextAlert })
+	}
+	return strings.ReplaceAll(msg, "{percent}", strconv.Itoa(percent)), false
+}
+
+// NarrateSecurityAlert narrates a risky tool use flagged by a security rule
+func (cn *RuleBasedNarrator) NarrateSecurityAlert(alert SecurityAlert) (string, bool) {
+	msg := cn.message(func(m MessageTemplates) string { return m.SecurityAlert })
+	return strings.NewReplacer(
+		"{rule}", alert.Rule,
+		"{reason}", alert.Reason,
+		"{tool}", alert.ToolName,
+	).Replace(msg), false
+}
+
+// spokenTokens formats a token count the way it is read aloud, rounded to ten
+// thousands (man) in Japanese and to thousands or millions in English
+func spokenTokens(n int64, lang Language) string {
+	if lang != LanguageEnglish {
+		if n < 10000 {
+			return strconv.FormatInt(n, 10)
+		}
+		return fmt.Sprintf("%d万", n/10000)
+	}
+	switch {
+	case n < 1000:
+		return strconv.FormatInt(n, 10)
+	case n < 1000000:
+		return fmt.Sprintf("%d thousand", n/1000)
+	}
+	return fmt.Sprintf("%.1f million", float64(n)/1e6)
+}
+
+// spokenDuration formats a duration in whole seconds the way it is read aloud
+func spokenDuration(d time.Duration, lang Language) string {
+	seconds := int(d.Round(time.Second) / time.Second)
+	minutes, seconds := seconds/60, seconds%60
+	switch {
+	case minutes == 0:
+		return localize(lang, fmt.Sprintf("%d秒", seconds), fmt.Sprintf("%d seconds", seconds))
+	case seconds == 0:
+		return localize(lang, fmt.Sprintf("%d分", minutes), fmt.Sprintf("%d minutes", minutes))
 	}
-	return "タスクが完了しました", false
+	return localize(lang, fmt.Sprintf("%d分%d秒", minutes, seconds), fmt.Sprintf("%d minutes %d seconds", minutes, seconds))
 }
 
 // NarrateAPIError narrates an API error
 func (cn *RuleBasedNarrator) NarrateAPIError(statusCode int, errorType string, message string) (string, bool) {
 	if statusCode == 500 && errorType == "api_error" && message == "Overloaded" {
-		return "Claude のサーバーが過負荷状態です。しばらく待ってから再試行してください。", true
+		return cn.message(func(m MessageTemplates) string { return m.APIErrorOverloaded }), true
 	}
 	if statusCode == 400 && errorType == "invalid_request_error" {
-		return "Claude のサーバーからリクエストエラーを受け取りました", true
+		return cn.message(func(m MessageTemplates) string { return m.APIErrorInvalidRequest }), true
 	}
-	return fmt.Sprintf("APIエラー %d: %s - %s", statusCode, errorType, message), true
+	msg := cn.message(func(m MessageTemplates) string { return m.APIError })
+	msg = strings.ReplaceAll(msg, "{status}", fmt.Sprintf("%d", statusCode))
+	msg = strings.ReplaceAll(msg, "{type}", errorType)
+	return strings.ReplaceAll(msg, "{message}", message), true
 }
@@ -2,6 +2,7 @@ package narrator
 
 import (
 	"testing"
+	"time"
 )
 
 func TestRuleBasedNarrator_NarrateToolUse(t *testing.T) {
@@ -374,6 +375,50 @@ func TestRuleBasedNarrator_UnknownToolsWithoutGenericMessage(t *testing.T) {
 	}
 }
 
+func TestRuleBasedNarrator_EmptyAndPartialConfigs(t *testing.T) {
+	bash := map[string]interface{}{"command": "make test"}
+	read := map[string]interface{}{"file_path": "/src/main.go"}
+	tests := []struct {
+		name         string
+		config       *NarratorConfig
+		toolName     string
+		input        map[string]interface{}
+		want         string
+		wantFallback bool
+	}{
+		{name: "nil config", toolName: "Bash", input: bash, want: "テストを実行します"},
+		{name: "empty config", config: &NarratorConfig{}, toolName: "Bash", input: bash, want: "テストを実行します"},
+		{
+			name:     "rule with only a permission message",
+			config:   &NarratorConfig{Rules: map[string]ToolRules{"Bash": {PermissionMessage: "コマンドの許可が必要です"}}},
+			toolName: "Bash",
+			input:    bash,
+			want:     "テストを実行します",
+		},
+		{
+			name:         "rule without a default message",
+			config:       &NarratorConfig{Rules: map[string]ToolRules{"Read": {Patterns: []PatternRule{{Contains: "_test", Message: "テストを読みます"}}}}},
+			toolName:     "Read",
+			input:        read,
+			wantFallback: true,
+		},
+		{name: "tool without the input its message needs", config: &NarratorConfig{}, toolName: "Read", input: nil, wantFallback: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cn := NewRuleBasedNarrator(tt.config)
+			got, fallback := cn.NarrateToolUse(tt.toolName, tt.input)
+			if got != tt.want || fallback != tt.wantFallback {
+				t.Errorf("NarrateToolUse(%s) = (%q, %v), want (%q, %v)", tt.toolName, got, fallback, tt.want, tt.wantFallback)
+			}
+			// Other narrations do not break on the config either
+			cn.NarrateToolUsePermission(tt.toolName)
+			cn.NarrateNotification(NotificationTypeCompact)
+		})
+	}
+}
+
 func TestHybridNarrator_UnknownTools(t *testing.T) {
 	// Create HybridNarrator without AI
 	hn := NewHybridNarrator("", false)
@@ -516,3 +561,142 @@ func TestRuleBasedNarrator_CaptureRules(t *testing.T) {
 		})
 	}
 }
+
+func TestRuleBasedNarrator_English(t *testing.T) {
+	cn := NewRuleBasedNarratorWithLanguage(GetDefaultNarratorConfigForLanguage(LanguageEnglish), LanguageEnglish)
+
+	t.Run("tool use", func(t *testing.T) {
+		tests := []struct {
+			toolName string
+			input    map[string]interface{}
+			expected string
+		}{
+			{"Read", map[string]interface{}{"file_path": "/src/main.go"}, "Reading Go file 'main.go'"},
+			{"Read", map[string]interface{}{"file_path": "/src/data.xyz"}, "Reading file 'data.xyz'"},
+			{"Bash", map[string]interface{}{"command": "git status"}, "Checking the Git repository status"},
+			{"Grep", map[string]interface{}{"pattern": "foo"}, "Searching for 'foo' in the whole project"},
+			{"Task", map[string]interface{}{"description": "review", "subagent_type": "reviewer"}, "Running task 'review' with the reviewer agent"},
+			{"UnknownTool", map[string]interface{}{}, "Running tool 'UnknownTool'"},
+		}
+		for _, tt := range tests {
+			result, _ := cn.NarrateToolUse(tt.toolName, tt.input)
+			if result != tt.expected {
+				t.Errorf("NarrateToolUse(%s, %v) = %q, want %q", tt.toolName, tt.input, result, tt.expected)
+			}
+		}
+	})
+
+	t.Run("notification", func(t *testing.T) {
+		result, _ := cn.NarrateNotification(NotificationTypeCompact)
+		if result != "Compacting the context" {
+			t.Errorf("NarrateNotification() = %q, want %q", result, "Compacting the context")
+		}
+	})
+
+	t.Run("task completion", func(t *testing.T) {
+		tests := []struct {
+			description  string
+			subagentType string
+			expected     string
+		}{
+			{"review", "reviewer", "The reviewer agent completed task 'review'"},
+			{"review", "", "Task 'review' completed"},
+			{"", "", "Task completed"},
+		}
+		for _, tt := range tests {
+			result, _ := cn.NarrateTaskCompletion(tt.description, tt.subagentType)
+			if result != tt.expected {
+				t.Errorf("NarrateTaskCompletion(%q, %q) = %q, want %q", tt.description, tt.subagentType, result, tt.expected)
+			}
+		}
+	})
+
+	t.Run("tool SLA breach", func(t *testing.T) {
+		result, _ := cn.NarrateToolSLABreach("Bash", 151*time.Second, 2*time.Minute)
+		if want := "Bash took 2 minutes 31 seconds, longer than the expected 2 minutes"; result != want {
+			t.Errorf("NarrateToolSLABreach() = %q, want %q", result, want)
+		}
+	})
+
+	t.Run("session summary", func(t *testing.T) {
+		result, _ := cn.NarrateSessionSummary(SessionSummary{Duration: 41 * time.Minute, FilesEdited: 3, Commands: 9, Tokens: 1234567, TasksCompleted: 2})
+		if want := "The session ended after 41 minutes: 3 files edited, 9 commands run, 2 tasks completed and 1.2 million tokens used"; result != want {
+			t.Errorf("NarrateSessionSummary() = %q, want %q", result, want)
+		}
+	})
+
+	t.Run("tool duration", func(t *testing.T) {
+		result, _ := cn.NarrateToolDuration("Bash", 12*time.Second)
+		if want := "Bash finished in 12 seconds"; result != want {
+			t.Errorf("NarrateToolDuration() = %q, want %q", result, want)
+		}
+	})
+
+	t.Run("budget alert", func(t *testing.T) {
+		result, _ := cn.NarrateBudgetAlert(BudgetAlert{Scope: "session", Metric: "tokens", Used: 1650000, Limit: 2000000, Percent: 82})
+		if want := "This session has used 82% of its token budget: 1.6 million tokens"; result != want {
+			t.Errorf("NarrateBudgetAlert() = %q, want %q", result, want)
+		}
+		result, _ = cn.NarrateBudgetAlert(BudgetAlert{Scope: "day", Metric: "cost", Used: 10.25, Limit: 10, Percent: 102})
+		if want := "Today's usage has reached 102% of the budget: 10.25 dollars"; result != want {
+			t.Errorf("NarrateBudgetAlert() = %q, want %q", result, want)
+		}
+	})
+
+	t.Run("context alert", func(t *testing.T) {
+		result, _ := cn.NarrateContextAlert(85, 87)
+		if want := "The context is 87% full and will be compacted soon"; result != want {
+			t.Errorf("NarrateContextAlert() = %q, want %q", result, want)
+		}
+		result, _ = cn.NarrateContextAlert(60, 62)
+		if want := "The context is 62% full"; result != want {
+			t.Errorf("NarrateContextAlert() = %q, want %q", result, want)
+		}
+	})
+
+	t.Run("security alert", func(t *testing.T) {
+		result, _ := cn.NarrateSecurityAlert(SecurityAlert{Rule: "force push", Reason: "overwrites the history of a remote branch", ToolName: "Bash"})
+		if want := "Warning: Bash is about to run a risky operation, force push"; result != want {
+			t.Errorf("NarrateSecurityAlert() = %q, want %q", result, want)
+		}
+	})
+}
+
+func TestRuleBasedNarrator_MissingMessageFallback(t *testing.T) {
+	// An English config without notifications falls back to the built-in messages
+	cn := &RuleBasedNarrator{
+		config:        &NarratorConfig{},
+		defaultConfig: &NarratorConfig{},
+		baseConfig:    GetDefaultNarratorConfig(),
+		language:      LanguageEnglish,
+	}
+
+	result, shouldFallback := cn.NarrateNotification(NotificationTypeCompact)
+	if result != "コンテキストを圧縮しています" || shouldFallback {
+		t.Errorf("NarrateNotification() = (%q, %v), want built-in message", result, shouldFallback)
+	}
+}
+
+func TestDefaultNarratorConfigLocales(t *testing.T) {
+	ja := GetDefaultNarratorConfigForLanguage(LanguageJapanese)
+	en := GetDefaultNarratorConfigForLanguage(LanguageEnglish)
+
+	for name := range ja.Rules {
+		if _, ok := en.Rules[name]; !ok {
+			t.Errorf("English config is missing rules for %s", name)
+		}
+	}
+	for ext := range ja.FileTypeNames {
+		if _, ok := en.FileTypeNames[ext]; !ok {
+			t.Errorf("English config is missing file type name for %s", ext)
+		}
+	}
+	for notificationType := range ja.Notifications {
+		if _, ok := en.Notifications[notificationType]; !ok {
+			t.Errorf("English config is missing notification %s", notificationType)
+		}
+	}
+	if en.Messages.TaskCompletedByAgent == "" || en.Messages.AgentTask == "" {
+		t.Errorf("English config is missing message templates")
+	}
+}
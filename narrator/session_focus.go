@@ -0,0 +1,98 @@
+package narrator
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// InteractionAware is implemented by narrators that follow the session the user works in
+type InteractionAware interface {
+	// UserInteraction records that the user sent a prompt to a session; cwd names it
+	UserInteraction(project, session, cwd string)
+}
+
+// SessionFocus picks the one session whose narrations are spoken when several sessions
+// are active: the one the user sent a prompt to last. Before any prompt, or once the
+// focused session has been quiet for the idle time, the next session to narrate takes
+// the focus. Narrations of other sessions are only shown on the console.
+type SessionFocus struct {
+	mu       sync.Mutex
+	idle     time.Duration
+	key      string // Project and session in focus; empty before the first narration
+	lastSeen time.Time
+	labels   map[string]string // Spoken names of the sessions the user interacted with
+	now      func() time.Time
+}
+
+// NewSessionFocus creates a focus that moves to another session after idle without
+// narrations from the focused one; 0 only moves it on user prompts
+func NewSessionFocus(idle time.Duration) *SessionFocus {
+	return &SessionFocus{idle: idle, labels: make(map[string]string), now: time.Now}
+}
+
+// Interact focuses the session the user sent a prompt to. It reports whether the
+// focus moved, with the name to announce.
+func (f *SessionFocus) Interact(project, session, cwd string) (label string, switched bool) {
+	if session == "" {
+		return "", false
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	key := focusKey(project, session)
+	if cwd != "" {
+		f.labels[key] = filepath.Base(cwd)
+	}
+	switched = f.key != "" && f.key != key
+	f.key, f.lastSeen = key, f.now()
+	return f.label(key, project), switched
+}
+
+// Allow reports whether a narration of a session is spoken. When the focus moves to
+// the session because the focused one went idle, it also returns the name to announce.
+func (f *SessionFocus) Allow(project, session string) (allowed bool, label string, switched bool) {
+	if session == "" {
+		// Narrations outside of any session, such as announcements, are always spoken
+		return true, "", false
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	key := focusKey(project, session)
+	now := f.now()
+	switch {
+	case f.key == key:
+	case f.key == "":
+		f.key = key
+	case f.idle > 0 && now.Sub(f.lastSeen) >= f.idle:
+		f.key = key
+		label, switched = f.label(key, project), true
+	default:
+		return false, "", false
+	}
+	f.lastSeen = now
+	return true, label, switched
+}
+
+// label returns the spoken name of a session: the base name of its directory if known,
+// or the last part of the project name
+func (f *SessionFocus) label(key, project string) string {
+	if label, ok := f.labels[key]; ok {
+		return label
+	}
+	return project[strings.LastIndex(project, "-")+1:]
+}
+
+// focusKey identifies a session
+func focusKey(project, session string) string {
+	return project + "/" + session
+}
+
+// focusAnnouncement returns the announcement of the focus moving to a session
+func focusAnnouncement(lang Language, label string) string {
+	if lang == LanguageEnglish {
+		return fmt.Sprintf("Switching to %s.", label)
+	}
+	return fmt.Sprintf("%sに切り替えます。", label)
+}
@@ -0,0 +1,84 @@
+package narrator
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestSessionFocus(t *testing.T) {
+	now := time.Date(2025, 1, 26, 10, 0, 0, 0, time.UTC)
+	f := NewSessionFocus(time.Minute)
+	f.now = func() time.Time { return now }
+
+	steps := []struct {
+		advance      time.Duration
+		prompt       bool // The user sends a prompt instead of the session narrating
+		session      string
+		wantAllowed  bool
+		wantSwitched bool
+	}{
+		{0, false, "a", true, false}, // The first session to narrate takes the focus
+		{time.Second, false, "b", false, false},
+		{time.Second, true, "b", true, true},
+		{time.Second, false, "a", false, false},
+		{time.Second, false, "b", true, false},
+		{time.Minute, false, "a", true, true}, // b went quiet
+		{time.Second, false, "", true, false}, // Narrations outside of sessions are always spoken
+		{time.Second, false, "b", false, false},
+	}
+	for i, step := range steps {
+		now = now.Add(step.advance)
+		var allowed, switched bool
+		if step.prompt {
+			_, switched = f.Interact("-home-me-app", step.session, "/home/me/app")
+			allowed = true
+		} else {
+			allowed, _, switched = f.Allow("-home-me-app", step.session)
+		}
+		if allowed != step.wantAllowed || switched != step.wantSwitched {
+			t.Fatalf("step %d: session %q allowed = %v, switched = %v; want %v, %v", i, step.session, allowed, switched, step.wantAllowed, step.wantSwitched)
+		}
+	}
+}
+
+func TestSessionFocusLabel(t *testing.T) {
+	f := NewSessionFocus(0)
+	if label, _ := f.Interact("-home-me-my-app", "s1", "/home/me/my-app"); label != "my-app" {
+		t.Errorf("label = %q, want my-app", label)
+	}
+	if got := f.label(focusKey("-home-me-other", "s2"), "-home-me-other"); got != "other" {
+		t.Errorf("label without a directory = %q, want other", got)
+	}
+}
+
+func TestVoiceNarrator_SpeaksFocusedSession(t *testing.T) {
+	vn := NewVoiceNarrator(nil, nil, nil, false)
+	defer vn.Close()
+	vn.enabled = true // Queue without a voice worker
+	vn.SetSessionFocus(NewSessionFocus(0), true)
+
+	text := PriorityInput{Type: NarrationTypeText}
+	vn.SetSession("-home-me-app", "a")
+	vn.enqueueNarration("first", text)
+	vn.SetSession("-home-me-web", "b")
+	vn.enqueueNarration("dropped", text)
+	vn.UserInteraction("-home-me-web", "b", "/home/me/web")
+	vn.enqueueNarration("second", text)
+
+	ctx := context.Background()
+	var got []string
+	for vn.QueueSize() > 0 {
+		got = append(got, vn.queue.Dequeue(ctx).OriginalText)
+	}
+	want := []string{"first", "webに切り替えます。", "second"}
+	if len(got) != len(want) {
+		t.Fatalf("queued %q, want %q", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("queued %q, want %q", got, want)
+			break
+		}
+	}
+}
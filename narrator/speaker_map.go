@@ -0,0 +1,89 @@
+package narrator
+
+import (
+	"fmt"
+	"path"
+	"strconv"
+	"strings"
+)
+
+// SessionAware is implemented by narrators that vary their output per session
+type SessionAware interface {
+	// SetSession sets the project and session of the events being narrated next
+	SetSession(project, session string)
+}
+
+// SpeakerRule maps a project or session name pattern to a speaker ID
+type SpeakerRule struct {
+	Session   bool   // Match the session name instead of the project name
+	Pattern   string // Glob pattern (path.Match syntax)
+	SpeakerID int
+}
+
+// SpeakerMap selects a speaker ID for a project and session
+type SpeakerMap struct {
+	rules []SpeakerRule
+}
+
+// ParseSpeakerMap parses speaker mapping entries of the form PATTERN=ID or session:PATTERN=ID.
+// Patterns without the session: prefix match the project name; the first matching entry wins.
+func ParseSpeakerMap(entries []string) (*SpeakerMap, error) {
+	m := &SpeakerMap{}
+	for _, entry := range entries {
+		sep := strings.LastIndex(entry, "=")
+		if sep < 0 {
+			return nil, fmt.Errorf("invalid speaker mapping %q (expected PATTERN=ID)", entry)
+		}
+
+		rule := SpeakerRule{Pattern: entry[:sep]}
+		if pattern, ok := strings.CutPrefix(rule.Pattern, "session:"); ok {
+			rule.Session = true
+			rule.Pattern = pattern
+		} else if pattern, ok := strings.CutPrefix(rule.Pattern, "project:"); ok {
+			rule.Pattern = pattern
+		}
+		if rule.Pattern == "" {
+			return nil, fmt.Errorf("invalid speaker mapping %q: empty pattern", entry)
+		}
+		if _, err := path.Match(rule.Pattern, ""); err != nil {
+			return nil, fmt.Errorf("invalid speaker mapping %q: %w", entry, err)
+		}
+
+		id, err := strconv.Atoi(entry[sep+1:])
+		if err != nil || id < 0 {
+			return nil, fmt.Errorf("invalid speaker mapping %q: speaker ID must be a non-negative integer", entry)
+		}
+		rule.SpeakerID = id
+
+		m.rules = append(m.rules, rule)
+	}
+	return m, nil
+}
+
+// Rules returns the mapping rules in match order
+func (m *SpeakerMap) Rules() []SpeakerRule {
+	if m == nil {
+		return nil
+	}
+	return m.rules
+}
+
+// SpeakerFor returns the speaker ID of the first rule matching the project or session
+func (m *SpeakerMap) SpeakerFor(project, session string) (int, bool) {
+	if m == nil {
+		return 0, false
+	}
+	for _, rule := range m.rules {
+		name := project
+		if rule.Session {
+			name = session
+		}
+		if name == "" {
+			continue
+		}
+		if ok, _ := path.Match(rule.Pattern, name); ok {
+			return rule.SpeakerID, true
+		}
+	}
+	return 0, false
+}
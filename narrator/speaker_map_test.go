@@ -0,0 +1,183 @@
+package narrator
+
+import (
+	"context"
+	"testing"
+)
+
+func TestParseSpeakerMap(t *testing.T) {
+	tests := []struct {
+		name    string
+		entries []string
+		want    []SpeakerRule
+		wantErr bool
+	}{
+		{
+			name:    "project and session rules",
+			entries: []string{"*frontend=3", "project:api=0", "session:abc*=8"},
+			want: []SpeakerRule{
+				{Pattern: "*frontend", SpeakerID: 3},
+				{Pattern: "api", SpeakerID: 0},
+				{Session: true, Pattern: "abc*", SpeakerID: 8},
+			},
+		},
+		{name: "missing ID", entries: []string{"frontend"}, wantErr: true},
+		{name: "non-numeric ID", entries: []string{"frontend=zundamon"}, wantErr: true},
+		{name: "negative ID", entries: []string{"frontend=-1"}, wantErr: true},
+		{name: "empty pattern", entries: []string{"session:=3"}, wantErr: true},
+		{name: "malformed glob", entries: []string{"[front=3"}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m, err := ParseSpeakerMap(tt.entries)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseSpeakerMap() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			rules := m.Rules()
+			if len(rules) != len(tt.want) {
+				t.Fatalf("got %d rules, want %d", len(rules), len(tt.want))
+			}
+			for i := range rules {
+				if rules[i] != tt.want[i] {
+					t.Errorf("rule %d = %+v, want %+v", i, rules[i], tt.want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestSpeakerMap_SpeakerFor(t *testing.T) {
+	m, err := ParseSpeakerMap([]string{"session:pinned=5", "*frontend=3", "*=1"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		project string
+		session string
+		wantID  int
+		wantOK  bool
+	}{
+		{name: "session rule wins", project: "-home-me-frontend", session: "pinned", wantID: 5, wantOK: true},
+		{name: "project rule", project: "-home-me-frontend", session: "abc", wantID: 3, wantOK: true},
+		{name: "catch-all", project: "-home-me-backend", session: "abc", wantID: 1, wantOK: true},
+		{name: "unknown session", wantOK: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			id, ok := m.SpeakerFor(tt.project, tt.session)
+			if ok != tt.wantOK || id != tt.wantID {
+				t.Errorf("SpeakerFor(%q, %q) = %d, %v; want %d, %v", tt.project, tt.session, id, ok, tt.wantID, tt.wantOK)
+			}
+		})
+	}
+
+	var nilMap *SpeakerMap
+	if _, ok := nilMap.SpeakerFor("frontend", "abc"); ok {
+		t.Error("nil SpeakerMap should not match")
+	}
+}
+
+// speakerRecorder records the speaker used for each synthesis
+type speakerRecorder struct {
+	speakers []int
+}
+
+func (s *speakerRecorder) Synthesize(ctx context.Context, text string) ([]byte, error) {
+	s.speakers = append(s.speakers, -1)
+	return nil, nil
+}
+
+func (s *speakerRecorder) SynthesizeWithSpeaker(ctx context.Context, text string, speakerID int) ([]byte, error) {
+	s.speakers = append(s.speakers, speakerID)
+	return nil, nil
+}
+
+func (s *speakerRecorder) IsAvailable() bool                                           { return true }
+func (s *speakerRecorder) SetVoiceParameters(speed, pitch, volume, intonation float64) {}
+
+func TestVoiceNarrator_SessionSpeaker(t *testing.T) {
+	synthesizer := &speakerRecorder{}
+	vn := NewVoiceNarrator(nil, synthesizer, nil, false)
+	defer vn.Close()
+
+	m, err := ParseSpeakerMap([]string{"*frontend=3"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	vn.SetSpeakerMap(m)
+
+	vn.SetSession("-home-me-frontend", "abc")
+	vn.enqueueNarration("frontend", PriorityInput{Type: NarrationTypeText})
+	vn.SetSession("-home-me-backend", "def")
+	vn.enqueueNarration("backend", PriorityInput{Type: NarrationTypeText})
+
+	ctx := context.Background()
+	for i := 0; i < 2; i++ {
+		item := vn.queue.Dequeue(ctx)
+		if item == nil {
+			t.Fatal("expected a queued narration")
+		}
+		if _, err := vn.synthesize(ctx, *item); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	want := []int{3, -1}
+	if len(synthesizer.speakers) != len(want) {
+		t.Fatalf("got speakers %v, want %v", synthesizer.speakers, want)
+	}
+	for i := range want {
+		if synthesizer.speakers[i] != want[i] {
+			t.Errorf("got speakers %v, want %v", synthesizer.speakers, want)
+			break
+		}
+	}
+}
+
+func TestVoiceNarrator_RuntimeSpeakerAndMute(t *testing.T) {
+	synthesizer := &speakerRecorder{}
+	vn := NewVoiceNarrator(nil, synthesizer, nil, false)
+	defer vn.Close()
+
+	m, err := ParseSpeakerMap([]string{"*frontend=3"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	vn.SetSpeakerMap(m)
+	vn.SetSession("-home-me-frontend", "abc")
+
+	speaker := 8
+	vn.SetSpeaker(&speaker)
+	vn.enqueueNarration("runtime speaker", PriorityInput{Type: NarrationTypeText})
+	vn.SetMuted(true)
+	vn.enqueueNarration("muted", PriorityInput{Type: NarrationTypeText})
+	if size := vn.QueueSize(); size != 1 {
+		t.Errorf("QueueSize() = %d, want 1 (narrations are dropped while muted)", size)
+	}
+	vn.SetMuted(false)
+	vn.SetSpeaker(nil)
+	vn.enqueueNarration("speaker map", PriorityInput{Type: NarrationTypeText})
+
+	ctx := context.Background()
+	for i := 0; i < 2; i++ {
+		item := vn.queue.Dequeue(ctx)
+		if item == nil {
+			t.Fatal("expected a queued narration")
+		}
+		if _, err := vn.synthesize(ctx, *item); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	want := []int{8, 3}
+	if len(synthesizer.speakers) != len(want) || synthesizer.speakers[0] != want[0] || synthesizer.speakers[1] != want[1] {
+		t.Errorf("got speakers %v, want %v", synthesizer.speakers, want)
+	}
+}
@@ -0,0 +1,124 @@
+package narrator
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// synthesisTimeout bounds the synthesis of one narration
+const synthesisTimeout = 15 * time.Second
+
+// synthPrefetcher synthesizes the narrations waiting at the head of the queue while the
+// current one plays, so the next one is ready when it finishes. A nil synthPrefetcher
+// synthesizes nothing ahead.
+type synthPrefetcher struct {
+	ctx        context.Context
+	queue      *PriorityQueue
+	lookahead  int
+	synthesize func(ctx context.Context, item NarrationItem) ([]byte, error)
+	sem        chan struct{} // Bounds the syntheses running at once
+	mu         sync.Mutex
+	pending    map[string]*prefetchedAudio // key: item ID
+}
+
+// prefetchedAudio is the audio of a narration synthesized ahead of time
+type prefetchedAudio struct {
+	text   string // Text synthesized; an item updated since is synthesized again
+	done   chan struct{}
+	audio  []byte
+	err    error
+	cancel context.CancelFunc
+}
+
+// newSynthPrefetcher creates a prefetcher that synthesizes up to lookahead narrations of
+// queue ahead, at most lookahead at once
+func newSynthPrefetcher(ctx context.Context, queue *PriorityQueue, lookahead int, synthesize func(ctx context.Context, item NarrationItem) ([]byte, error)) *synthPrefetcher {
+	return &synthPrefetcher{
+		ctx:        ctx,
+		queue:      queue,
+		lookahead:  lookahead,
+		synthesize: synthesize,
+		sem:        make(chan struct{}, lookahead),
+		pending:    make(map[string]*prefetchedAudio),
+	}
+}
+
+// prefetch starts synthesizing the narrations at the head of the queue that are not
+// being synthesized yet
+func (p *synthPrefetcher) prefetch() {
+	if p == nil {
+		return
+	}
+	// Peek while holding p.mu so an item the voice worker took is not started again
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	for _, item := range p.queue.Peek(p.lookahead) {
+		if pa, ok := p.pending[item.ID]; ok {
+			if pa.text == item.Text {
+				continue
+			}
+			pa.cancel() // Updated since, such as collapsed with its repeats
+		}
+		p.pending[item.ID] = p.start(item)
+	}
+}
+
+// start synthesizes an item in the background
+func (p *synthPrefetcher) start(item NarrationItem) *prefetchedAudio {
+	ctx, cancel := context.WithCancel(p.ctx)
+	pa := &prefetchedAudio{text: item.Text, done: make(chan struct{}), cancel: cancel}
+	go func() {
+		defer close(pa.done)
+		select {
+		case p.sem <- struct{}{}:
+			defer func() { <-p.sem }()
+		case <-ctx.Done():
+			pa.err = ctx.Err()
+			return
+		}
+		ctx, cancel := context.WithTimeout(ctx, synthesisTimeout)
+		defer cancel()
+		pa.audio, pa.err = p.synthesize(ctx, item)
+	}()
+	return pa
+}
+
+// take returns the audio synthesized ahead for an item taken off the queue, if any
+func (p *synthPrefetcher) take(item NarrationItem) (*prefetchedAudio, bool) {
+	if p == nil {
+		return nil, false
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	pa, ok := p.pending[item.ID]
+	if !ok {
+		return nil, false
+	}
+	delete(p.pending, item.ID)
+	if pa.text != item.Text {
+		pa.cancel()
+		return nil, false
+	}
+	return pa, true
+}
+
+// discard cancels the synthesis of an item that will not be played
+func (p *synthPrefetcher) discard(id string) {
+	if p == nil {
+		return
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if pa, ok := p.pending[id]; ok {
+		pa.cancel()
+		delete(p.pending, id)
+	}
+}
+
+// wait waits for the synthesis to finish and returns the audio
+func (pa *prefetchedAudio) wait() ([]byte, error) {
+	<-pa.done
+	pa.cancel()
+	return pa.audio, pa.err
+}
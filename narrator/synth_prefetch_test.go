@@ -0,0 +1,87 @@
+package narrator
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/kazegusuri/claude-companion/speech"
+)
+
+// recordingSynthesizer reports the texts it synthesizes
+type recordingSynthesizer struct {
+	synthesized chan string
+}
+
+func (s *recordingSynthesizer) Synthesize(ctx context.Context, text string) ([]byte, error) {
+	s.synthesized <- text
+	return speech.GetSilentWAV(), nil
+}
+
+func (s *recordingSynthesizer) IsAvailable() bool { return true }
+
+func (s *recordingSynthesizer) SetVoiceParameters(speed, pitch, volume, intonation float64) {}
+
+// blockingPlayer plays each audio until it is released
+type blockingPlayer struct {
+	playing chan string
+	release chan struct{}
+}
+
+func (p *blockingPlayer) Play(audioData []byte, meta *speech.AudioMeta) error {
+	p.playing <- meta.OriginalText
+	<-p.release
+	return nil
+}
+
+func (p *blockingPlayer) TestPlay() error { return nil }
+
+func TestVoiceNarrator_SynthesizesAhead(t *testing.T) {
+	synth := &recordingSynthesizer{synthesized: make(chan string, 10)}
+	player := &blockingPlayer{playing: make(chan string, 10), release: make(chan struct{})}
+	vn := NewVoiceNarrator(nil, synth, player, true)
+	defer vn.Close()
+	defer close(player.release)
+	vn.SetLookahead(2)
+
+	texts := []string{"一つ目です", "二つ目です", "三つ目です"}
+	for _, text := range texts {
+		vn.Announce(text)
+	}
+
+	receive := func(ch chan string, what string) string {
+		t.Helper()
+		select {
+		case text := <-ch:
+			return text
+		case <-time.After(2 * time.Second):
+			t.Fatalf("timed out waiting for %s", what)
+			return ""
+		}
+	}
+	if got := receive(player.playing, "playback"); got != texts[0] {
+		t.Fatalf("playing %q, want %q", got, texts[0])
+	}
+	// The others are synthesized while the first one plays
+	synthesized := map[string]bool{}
+	for i := 0; i < len(texts); i++ {
+		synthesized[receive(synth.synthesized, "synthesis")] = true
+	}
+	for _, text := range texts {
+		if !synthesized[text] {
+			t.Errorf("%q was not synthesized while the first narration played", text)
+		}
+	}
+
+	for _, want := range texts[1:] {
+		player.release <- struct{}{}
+		if got := receive(player.playing, "playback"); got != want {
+			t.Errorf("playing %q, want %q", got, want)
+		}
+	}
+	select {
+	case text := <-synth.synthesized:
+		t.Errorf("%q was synthesized again", text)
+	default:
+	}
+}
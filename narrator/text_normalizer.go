@@ -5,114 +5,191 @@ import (
 	"path/filepath"
 	"regexp"
 	"strings"
+	"sync"
 )
 
 const ellipsisMarker = "なんとか,"
 
+// defaultDomainReplacements are the readings of well-known domains in URLs
+var defaultDomainReplacements = map[string]string{
+	"github.com":        "ギットハブ",
+	"api.github.com":    "ギットハブAPI",
+	"google.com":        "グーグル",
+	"youtube.com":       "ユーチューブ",
+	"twitter.com":       "ツイッター",
+	"x.com":             "エックス",
+	"facebook.com":      "フェイスブック",
+	"instagram.com":     "インスタグラム",
+	"linkedin.com":      "リンクトイン",
+	"reddit.com":        "レディット",
+	"stackoverflow.com": "スタックオーバーフロー",
+	"amazon.com":        "アマゾン",
+	"wikipedia.org":     "ウィキペディア",
+	"openai.com":        "オープンエーアイ",
+	"anthropic.com":     "アンソロピック",
+	"microsoft.com":     "マイクロソフト",
+	"apple.com":         "アップル",
+	"golang.org":        "ゴーラング",
+	"nodejs.org":        "ノードジェーエス",
+	"python.org":        "パイソン",
+	"npmjs.com":         "エヌピーエム",
+	"docker.com":        "ドッカー",
+	"kubernetes.io":     "クバネティス",
+}
+
+// defaultReplacements are the readings of common file names, extensions, abbreviations
+// and terms
+var defaultReplacements = map[string]string{
+	// Common file extensions
+	"README.md": "リードミー",
+	"README":    "リードミー",
+	".md":       "ドットエムディー",
+	".go":       "ドットゴー",
+	".js":       "ドットジェーエス",
+	".ts":       "ドットティーエス",
+	".py":       "ドットパイ",
+	".json":     "ドットジェイソン",
+	".yaml":     "ドットヤムル",
+	".yml":      "ドットヤムル",
+	".txt":      "ドットテキスト",
+	".log":      "ドットログ",
+	".sh":       "ドットエスエイチ",
+	".bash":     "ドットバッシュ",
+	".sql":      "ドットエスキューエル",
+	".html":     "ドットエイチティーエムエル",
+	".css":      "ドットシーエスエス",
+	".xml":      "ドットエックスエムエル",
+
+	// Common abbreviations
+	"TODO":  "トゥードゥー",
+	"API":   "エーピーアイ",
+	"URL":   "ユーアールエル",
+	"HTTP":  "エイチティーティーピー",
+	"HTTPS": "エイチティーティーピーエス",
+	"JSON":  "ジェイソン",
+	"XML":   "エックスエムエル",
+	"CSV":   "シーエスブイ",
+	"PDF":   "ピーディーエフ",
+	"PNG":   "ピング",
+	"JPG":   "ジェイペグ",
+	"JPEG":  "ジェイペグ",
+	"GIF":   "ジフ",
+	"gRPC":  "ジーアールピーシー",
+	"GRPC":  "ジーアールピーシー",
+
+	// Programming terms
+	"npm":        "エヌピーエム",
+	"git":        "ギット",
+	"GitHub":     "ギットハブ",
+	"Docker":     "ドッカー",
+	"Kubernetes": "クバネティス",
+	"k8s":        "クバネティス",
+
+	// Common directory names
+	"src":          "ソース",
+	"pkg":          "パッケージ",
+	"cmd":          "コマンド",
+	"dist":         "ディスト",
+	"build":        "ビルド",
+	"test":         "テスト",
+	"tests":        "テスト",
+	"doc":          "ドキュメント",
+	"docs":         "ドキュメント",
+	"lib":          "ライブラリ",
+	"libs":         "ライブラリ",
+	"vendor":       "ベンダー",
+	"node_modules": "ノードモジュール",
+}
+
+// NormalizerOptions are the behavior flags of a TextNormalizer
+type NormalizerOptions struct {
+	Katakana   bool // Rewrite remaining English words as katakana
+	SkipURLs   bool // Drop URLs instead of reading their domain
+	RawNumbers bool // Leave dates, times, versions, percentages and long numbers as written
+}
+
 // TextNormalizer normalizes text for better TTS pronunciation
 type TextNormalizer struct {
+	mu                 sync.RWMutex // Guards the replacements and rules replaced by SetUserDictionary, and the options
 	replacements       map[string]string
 	domainReplacements map[string]string
+	rules              []dictionaryRule // Regexp rules of the user dictionary
+	lang               Language         // Language dates, times, versions and percentages are read in
+	opts               NormalizerOptions
 }
 
-// NewTextNormalizer creates a new text normalizer
+// NewTextNormalizer creates a new text normalizer that reads numbers in Japanese
 func NewTextNormalizer() *TextNormalizer {
+	return NewTextNormalizerWithLanguage(LanguageJapanese)
+}
+
+// NewTextNormalizerWithLanguage creates a text normalizer that reads numbers in lang.
+// English readings are only used for text without Japanese, such as an untranslated
+// English narration.
+func NewTextNormalizerWithLanguage(lang Language) *TextNormalizer {
 	return &TextNormalizer{
-		domainReplacements: map[string]string{
-			"github.com":        "ギットハブ",
-			"api.github.com":    "ギットハブAPI",
-			"google.com":        "グーグル",
-			"youtube.com":       "ユーチューブ",
-			"twitter.com":       "ツイッター",
-			"x.com":             "エックス",
-			"facebook.com":      "フェイスブック",
-			"instagram.com":     "インスタグラム",
-			"linkedin.com":      "リンクトイン",
-			"reddit.com":        "レディット",
-			"stackoverflow.com": "スタックオーバーフロー",
-			"amazon.com":        "アマゾン",
-			"wikipedia.org":     "ウィキペディア",
-			"openai.com":        "オープンエーアイ",
-			"anthropic.com":     "アンソロピック",
-			"microsoft.com":     "マイクロソフト",
-			"apple.com":         "アップル",
-			"golang.org":        "ゴーラング",
-			"nodejs.org":        "ノードジェーエス",
-			"python.org":        "パイソン",
-			"npmjs.com":         "エヌピーエム",
-			"docker.com":        "ドッカー",
-			"kubernetes.io":     "クバネティス",
-		},
-		replacements: map[string]string{
-			// Common file extensions
-			"README.md": "リードミー",
-			"README":    "リードミー",
-			".md":       "ドットエムディー",
-			".go":       "ドットゴー",
-			".js":       "ドットジェーエス",
-			".ts":       "ドットティーエス",
-			".py":       "ドットパイ",
-			".json":     "ドットジェイソン",
-			".yaml":     "ドットヤムル",
-			".yml":      "ドットヤムル",
-			".txt":      "ドットテキスト",
-			".log":      "ドットログ",
-			".sh":       "ドットエスエイチ",
-			".bash":     "ドットバッシュ",
-			".sql":      "ドットエスキューエル",
-			".html":     "ドットエイチティーエムエル",
-			".css":      "ドットシーエスエス",
-			".xml":      "ドットエックスエムエル",
-
-			// Common abbreviations
-			"TODO":  "トゥードゥー",
-			"API":   "エーピーアイ",
-			"URL":   "ユーアールエル",
-			"HTTP":  "エイチティーティーピー",
-			"HTTPS": "エイチティーティーピーエス",
-			"JSON":  "ジェイソン",
-			"XML":   "エックスエムエル",
-			"CSV":   "シーエスブイ",
-			"PDF":   "ピーディーエフ",
-			"PNG":   "ピング",
-			"JPG":   "ジェイペグ",
-			"JPEG":  "ジェイペグ",
-			"GIF":   "ジフ",
-			"gRPC":  "ジーアールピーシー",
-			"GRPC":  "ジーアールピーシー",
-
-			// Programming terms
-			"npm":        "エヌピーエム",
-			"git":        "ギット",
-			"GitHub":     "ギットハブ",
-			"Docker":     "ドッカー",
-			"Kubernetes": "クバネティス",
-			"k8s":        "クバネティス",
-
-			// Common directory names
-			"src":          "ソース",
-			"pkg":          "パッケージ",
-			"cmd":          "コマンド",
-			"dist":         "ディスト",
-			"build":        "ビルド",
-			"test":         "テスト",
-			"tests":        "テスト",
-			"doc":          "ドキュメント",
-			"docs":         "ドキュメント",
-			"lib":          "ライブラリ",
-			"libs":         "ライブラリ",
-			"vendor":       "ベンダー",
-			"node_modules": "ノードモジュール",
-		},
+		lang:               lang,
+		replacements:       defaultReplacements,
+		domainReplacements: defaultDomainReplacements,
+	}
+}
+
+// SetKatakana sets whether English words left after the replacements are rewritten as katakana
+func (n *TextNormalizer) SetKatakana(enabled bool) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	n.opts.Katakana = enabled
+}
+
+// SetOptions sets the behavior flags
+func (n *TextNormalizer) SetOptions(opts NormalizerOptions) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	n.opts = opts
+}
+
+// Options returns the behavior flags
+func (n *TextNormalizer) Options() NormalizerOptions {
+	n.mu.RLock()
+	defer n.mu.RUnlock()
+	return n.opts
+}
+
+// SetUserDictionary merges the entries of a user dictionary over the built-in
+// replacements, replacing the previous user dictionary; nil restores the built-in ones
+func (n *TextNormalizer) SetUserDictionary(d *UserDictionary) error {
+	replacements, domains := defaultReplacements, defaultDomainReplacements
+	var rules []dictionaryRule
+	if d != nil {
+		var err error
+		if rules, err = d.compileRules(); err != nil {
+			return err
+		}
+		replacements = mergeMap(mergeMap(nil, defaultReplacements), d.Terms)
+		domains = mergeMap(mergeMap(nil, defaultDomainReplacements), d.Domains)
 	}
+
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	n.replacements, n.domainReplacements, n.rules = replacements, domains, rules
+	return nil
 }
 
 // Normalize converts text for better TTS pronunciation
 func (n *TextNormalizer) Normalize(text string) string {
+	n.mu.RLock()
+	defer n.mu.RUnlock()
+
+	// User rules see the text as written
+	for _, rule := range n.rules {
+		text = rule.re.ReplaceAllString(text, rule.replacement)
+	}
+
 	// Extract ASCII printable sequences and apply replacements only to them
 	result := ""
 	runes := []rune(text)
 	i := 0
+	english := n.lang == LanguageEnglish && !hasJapanese(text)
 
 	for i < len(runes) {
 		// Check if current rune is ASCII printable (32-126)
@@ -132,7 +209,10 @@ func (n *TextNormalizer) Normalize(text string) string {
 			skipNormalProcessing := false
 			if parsedURL, err := url.Parse(asciiPart); err == nil && parsedURL.Host != "" {
 				// It's a URL with a host
-				if replacement, ok := n.domainReplacements[parsedURL.Host]; ok {
+				if n.opts.SkipURLs {
+					normalized = ""
+					skipNormalProcessing = true
+				} else if replacement, ok := n.domainReplacements[parsedURL.Host]; ok {
 					// Replace the entire URL with the domain-specific replacement
 					normalized = replacement
 					skipNormalProcessing = true
@@ -169,7 +249,12 @@ func (n *TextNormalizer) Normalize(text string) string {
 
 			// Apply normal replacements if not skipped
 			if !skipNormalProcessing {
-				// First, handle specific full matches like "README.md"
+				// Read dates, times, versions and percentages before dots and slashes are replaced
+				if !n.opts.RawNumbers {
+					normalized = readNumbers(normalized, english)
+				}
+
+				// Handle specific full matches like "README.md"
 				for old, new := range n.replacements {
 					if strings.Contains(old, ".") && len(old) > 3 {
 						// Full filename replacements
@@ -206,7 +291,14 @@ func (n *TextNormalizer) Normalize(text string) string {
 				normalized = strings.ReplaceAll(normalized, "_", " ")
 
 				// Split long numbers (4+ digits) into groups of 4
-				normalized = n.splitLongNumbers(normalized)
+				if !n.opts.RawNumbers {
+					normalized = n.splitLongNumbers(normalized)
+				}
+
+				// Read remaining English words as katakana
+				if n.opts.Katakana {
+					normalized = replaceEnglishWords(normalized)
+				}
 			}
 
 			result += normalized
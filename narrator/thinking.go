@@ -0,0 +1,60 @@
+package narrator
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/kazegusuri/claude-companion/speech"
+)
+
+// ThinkingPolicy is how thinking blocks are narrated
+type ThinkingPolicy string
+
+const (
+	ThinkingSkip    ThinkingPolicy = "skip"    // Not narrated
+	ThinkingSummary ThinkingPolicy = "summary" // Narrated as the thinking message, such as 考え中です…
+	ThinkingFull    ThinkingPolicy = "full"    // Narrated like text
+)
+
+// ParseThinkingPolicy parses a thinking policy: skip, summary or full
+func ParseThinkingPolicy(s string) (ThinkingPolicy, error) {
+	switch p := ThinkingPolicy(strings.ToLower(s)); p {
+	case ThinkingSkip, ThinkingSummary, ThinkingFull:
+		return p, nil
+	}
+	return "", fmt.Errorf("unknown thinking policy %q (expected skip, summary or full)", s)
+}
+
+// ParseVoiceStyle parses the voice parameters of a narration type, such as
+// thinking:speed=1.2,volume=0.7, for synthesizers that change them per narration
+func ParseVoiceStyle(s string) (NarrationType, map[string]float64, error) {
+	name, spec, ok := strings.Cut(s, ":")
+	t, known := narrationTypeNames[strings.TrimSpace(name)]
+	if !ok || !known {
+		names := make([]string, 0, len(narrationTypeNames))
+		for name := range narrationTypeNames {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		return 0, nil, fmt.Errorf("invalid voice style %q: expected TYPE:NAME=VALUE,... with TYPE one of %s", s, strings.Join(names, ", "))
+	}
+	params := make(map[string]float64)
+	for _, param := range strings.Split(spec, ",") {
+		key, value, ok := strings.Cut(param, "=")
+		if !ok {
+			return 0, nil, fmt.Errorf("invalid voice style %q: expected NAME=VALUE, got %q", s, param)
+		}
+		key = strings.TrimSpace(key)
+		v, err := strconv.ParseFloat(strings.TrimSpace(value), 64)
+		if err != nil {
+			return 0, nil, fmt.Errorf("invalid voice style %q: %s is not a number", s, value)
+		}
+		if err := speech.CheckVoiceParameter(key, v); err != nil {
+			return 0, nil, fmt.Errorf("invalid voice style %q: %w", s, err)
+		}
+		params[key] = v
+	}
+	return t, params, nil
+}
@@ -0,0 +1,97 @@
+package narrator
+
+import (
+	"context"
+	"testing"
+)
+
+func TestHybridNarrator_ThinkingPolicy(t *testing.T) {
+	const thinking = "The user wants a test.\nLet me read the file first."
+	tests := []struct {
+		policy ThinkingPolicy
+		lang   Language
+		want   string
+	}{
+		{"", LanguageJapanese, "The user wants a test."},
+		{ThinkingFull, LanguageJapanese, "The user wants a test."},
+		{ThinkingSummary, LanguageJapanese, "考え中です…"},
+		{ThinkingSummary, LanguageEnglish, "Thinking…"},
+		{ThinkingSkip, LanguageJapanese, ""},
+	}
+	for _, tt := range tests {
+		t.Run(string(tt.policy)+"/"+string(tt.lang), func(t *testing.T) {
+			hn := NewHybridNarratorWithLanguage("", false, nil, tt.lang)
+			hn.SetThinkingPolicy(tt.policy)
+			if got, _ := hn.NarrateText(thinking, true); got != tt.want {
+				t.Errorf("NarrateText() = %q, want %q", got, tt.want)
+			}
+			var sentences []string
+			got, _ := hn.NarrateTextStream(thinking, true, func(s string) { sentences = append(sentences, s) })
+			if got != tt.want || len(sentences) != 1 || sentences[0] != tt.want {
+				t.Errorf("NarrateTextStream() = %q with sentences %q, want %q", got, sentences, tt.want)
+			}
+			// Text is narrated whatever the policy
+			if got, _ := hn.NarrateText("Done.", false); got != "Done." {
+				t.Errorf("NarrateText(text) = %q, want it as is", got)
+			}
+		})
+	}
+}
+
+func TestParseThinkingPolicy(t *testing.T) {
+	for _, s := range []string{"skip", "summary", "FULL"} {
+		if _, err := ParseThinkingPolicy(s); err != nil {
+			t.Errorf("ParseThinkingPolicy(%q) error = %v", s, err)
+		}
+	}
+	if _, err := ParseThinkingPolicy("brief"); err == nil {
+		t.Error("ParseThinkingPolicy(brief) should fail")
+	}
+}
+
+func TestParseVoiceStyle(t *testing.T) {
+	typ, params, err := ParseVoiceStyle("thinking:speed=1.2, volume=0.7")
+	if err != nil {
+		t.Fatalf("ParseVoiceStyle() error = %v", err)
+	}
+	if typ != NarrationTypeThinking || len(params) != 2 || params["speed"] != 1.2 || params["volume"] != 0.7 {
+		t.Errorf("ParseVoiceStyle() = %v, %v", typ, params)
+	}
+
+	for _, s := range []string{"speed=1.2", "whisper:speed=1.2", "thinking:speed", "thinking:speed=fast", "thinking:speed=5", "thinking:tempo=1"} {
+		if _, _, err := ParseVoiceStyle(s); err == nil {
+			t.Errorf("ParseVoiceStyle(%q) should fail", s)
+		}
+	}
+}
+
+// parameterRecorder records the voice parameters of each synthesis
+type parameterRecorder struct {
+	speakerRecorder
+	params []map[string]float64
+}
+
+func (s *parameterRecorder) SynthesizeWithParameters(ctx context.Context, text string, speakerID *int, params map[string]float64) ([]byte, error) {
+	s.params = append(s.params, params)
+	return nil, nil
+}
+
+func TestVoiceNarrator_VoiceStyle(t *testing.T) {
+	synthesizer := &parameterRecorder{}
+	vn := NewVoiceNarrator(nil, synthesizer, nil, false)
+	defer vn.Close()
+	vn.SetVoiceStyle(NarrationTypeThinking, map[string]float64{"speed": 1.2})
+
+	ctx := context.Background()
+	for _, typ := range []NarrationType{NarrationTypeThinking, NarrationTypeText} {
+		if _, err := vn.synthesize(ctx, NarrationItem{Text: "考え中です", Type: typ}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if len(synthesizer.params) != 1 || synthesizer.params[0]["speed"] != 1.2 {
+		t.Errorf("synthesized with parameters %v, want thinking only at speed 1.2", synthesizer.params)
+	}
+	if len(synthesizer.speakers) != 1 {
+		t.Errorf("synthesized %d narrations with the default voice, want the text one", len(synthesizer.speakers))
+	}
+}
@@ -0,0 +1,133 @@
+package narrator
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// ToolBatcher is implemented by narrators that can speak the tool uses of one
+// assistant message as a single narration
+type ToolBatcher interface {
+	// BeginToolBatch holds back the tool use narrations that follow
+	BeginToolBatch()
+	// EndToolBatch speaks the held back narrations as one
+	EndToolBatch()
+}
+
+// batchedToolUse is a tool use narration held back for a batch
+type batchedToolUse struct {
+	text string
+	in   PriorityInput
+}
+
+// toolKinds groups tools whose uses a batch narration counts together
+var toolKinds = map[string]string{
+	"Read":         "read",
+	"NotebookRead": "read",
+	"Write":        "edit",
+	"Edit":         "edit",
+	"MultiEdit":    "edit",
+	"NotebookEdit": "edit",
+	"Grep":         "search",
+	"Glob":         "search",
+	"LS":           "search",
+	"Bash":         "command",
+}
+
+// summarizeToolBatch narrates a batch of tool uses in one sentence, e.g.
+// "3つのファイルを読み込み、テストを実行します". A kind of tool used once keeps its own narration.
+func summarizeToolBatch(lang Language, batch []batchedToolUse) string {
+	type group struct {
+		kind string
+		uses []batchedToolUse
+	}
+	groups := make(map[string]*group)
+	var ordered []*group
+	for _, use := range batch {
+		kind, ok := toolKinds[use.in.ToolName]
+		if !ok {
+			kind = use.in.ToolName
+		}
+		g, ok := groups[kind]
+		if !ok {
+			g = &group{kind: kind}
+			groups[kind] = g
+			ordered = append(ordered, g)
+		}
+		g.uses = append(g.uses, use)
+	}
+
+	phrases := make([]string, len(ordered))
+	for i, g := range ordered {
+		if len(g.uses) == 1 {
+			phrases[i] = g.uses[0].text
+		} else {
+			phrases[i] = batchPhrase(lang, g.kind, len(g.uses))
+		}
+	}
+	if lang == LanguageEnglish {
+		return joinEnglishPhrases(phrases)
+	}
+	return joinJapanesePhrases(phrases)
+}
+
+// batchPhrase narrates count uses of a kind of tool
+func batchPhrase(lang Language, kind string, count int) string {
+	switch kind {
+	case "read":
+		return localize(lang, fmt.Sprintf("%sのファイルを読み込みます", japaneseCount(count)), fmt.Sprintf("reading %d files", count))
+	case "edit":
+		return localize(lang, fmt.Sprintf("%sのファイルを編集します", japaneseCount(count)), fmt.Sprintf("editing %d files", count))
+	case "search":
+		return localize(lang, fmt.Sprintf("%d件検索します", count), fmt.Sprintf("running %d searches", count))
+	case "command":
+		return localize(lang, fmt.Sprintf("%d件のコマンドを実行します", count), fmt.Sprintf("running %d commands", count))
+	default:
+		return localize(lang, fmt.Sprintf("%sを%d回使います", kind, count), fmt.Sprintf("using %s %d times", kind, count))
+	}
+}
+
+// japaneseCount counts things: "3つ" up to nine and "12個" above
+func japaneseCount(n int) string {
+	if n < 10 {
+		return fmt.Sprintf("%dつ", n)
+	}
+	return fmt.Sprintf("%d個", n)
+}
+
+// joinJapanesePhrases joins sentences with their verbs in the continuative form,
+// e.g. "読み込みます" and "実行します" into "読み込み、実行します"
+func joinJapanesePhrases(phrases []string) string {
+	var b strings.Builder
+	for i, phrase := range phrases {
+		phrase = strings.TrimRight(strings.TrimSpace(phrase), "。")
+		if i < len(phrases)-1 {
+			phrase = strings.TrimSuffix(phrase, "ます") + "、"
+		}
+		b.WriteString(phrase)
+	}
+	return b.String()
+}
+
+// joinEnglishPhrases joins phrases into one sentence, e.g. "Reading 3 files and running tests"
+func joinEnglishPhrases(phrases []string) string {
+	for i, phrase := range phrases {
+		phrase = strings.TrimRight(strings.TrimSpace(phrase), ".")
+		r, size := utf8.DecodeRuneInString(phrase)
+		if size == 0 {
+			continue
+		}
+		if i == 0 {
+			phrase = string(unicode.ToUpper(r)) + phrase[size:]
+		} else {
+			phrase = string(unicode.ToLower(r)) + phrase[size:]
+		}
+		phrases[i] = phrase
+	}
+	if len(phrases) < 2 {
+		return strings.Join(phrases, "")
+	}
+	return strings.Join(phrases[:len(phrases)-1], ", ") + " and " + phrases[len(phrases)-1]
+}
@@ -0,0 +1,91 @@
+package narrator
+
+import (
+	"context"
+	"testing"
+)
+
+func TestSummarizeToolBatch(t *testing.T) {
+	read := func(text string) batchedToolUse {
+		return batchedToolUse{text: text, in: PriorityInput{Type: NarrationTypeToolUse, ToolName: "Read"}}
+	}
+	bash := batchedToolUse{text: "テストを実行します", in: PriorityInput{Type: NarrationTypeToolUse, ToolName: "Bash"}}
+	tests := []struct {
+		name  string
+		lang  Language
+		batch []batchedToolUse
+		want  string
+	}{
+		{
+			name:  "counted reads and a command",
+			lang:  LanguageJapanese,
+			batch: []batchedToolUse{read("a.goを読み込みます"), read("b.goを読み込みます"), read("c.goを読み込みます"), bash},
+			want:  "3つのファイルを読み込み、テストを実行します",
+		},
+		{
+			name:  "single uses keep their narrations",
+			lang:  LanguageJapanese,
+			batch: []batchedToolUse{read("a.goを読み込みます。"), bash},
+			want:  "a.goを読み込み、テストを実行します",
+		},
+		{
+			name: "other tools are counted by name",
+			lang: LanguageJapanese,
+			batch: []batchedToolUse{
+				{text: "ウェブを検索します", in: PriorityInput{ToolName: "WebSearch"}},
+				{text: "ウェブを検索します", in: PriorityInput{ToolName: "WebSearch"}},
+			},
+			want: "WebSearchを2回使います",
+		},
+		{
+			name: "English",
+			lang: LanguageEnglish,
+			batch: []batchedToolUse{
+				read("Reading a.go"), read("Reading b.go"),
+				{text: "Running tests.", in: PriorityInput{ToolName: "Bash"}},
+				{text: "Editing main.go", in: PriorityInput{ToolName: "Edit"}},
+			},
+			want: "Reading 2 files, running tests and editing main.go",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := summarizeToolBatch(tt.lang, tt.batch); got != tt.want {
+				t.Errorf("summarizeToolBatch() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestVoiceNarrator_ToolBatch(t *testing.T) {
+	vn := NewVoiceNarrator(NewRuleBasedNarrator(GetDefaultNarratorConfig()), nil, nil, true)
+	defer vn.Close()
+
+	vn.BeginToolBatch()
+	texts := []string{}
+	for _, path := range []string{"/src/a.go", "/src/b.go"} {
+		text, _ := vn.NarrateToolUse("Read", map[string]interface{}{"file_path": path})
+		texts = append(texts, text)
+	}
+	if texts[0] == "" || texts[0] == texts[1] {
+		t.Fatalf("NarrateToolUse() returned %q; each tool use should still be narrated", texts)
+	}
+	if size := vn.QueueSize(); size != 0 {
+		t.Fatalf("QueueSize() = %d during the batch, want 0", size)
+	}
+	vn.EndToolBatch()
+
+	if size := vn.QueueSize(); size != 1 {
+		t.Fatalf("QueueSize() = %d, want 1", size)
+	}
+	item := vn.queue.Dequeue(context.Background())
+	if item.OriginalText != "2つのファイルを読み込みます" {
+		t.Errorf("batched narration = %q, want %q", item.OriginalText, "2つのファイルを読み込みます")
+	}
+
+	// Without a batch each tool use is spoken on its own
+	vn.NarrateToolUse("Read", map[string]interface{}{"file_path": "/src/a.go"})
+	if size := vn.QueueSize(); size != 1 {
+		t.Errorf("QueueSize() = %d, want 1", size)
+	}
+}
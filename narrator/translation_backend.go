@@ -0,0 +1,234 @@
+package narrator
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// TranslationBackend translates English texts to Japanese, several in one request
+type TranslationBackend interface {
+	Name() string
+	// TranslateBatch returns the translations of texts in the same order
+	TranslateBatch(ctx context.Context, texts []string) ([]string, error)
+}
+
+// batchTranslationPrompt asks a model to translate numbered lines in one completion
+const batchTranslationPrompt = `Translate each numbered line separately. Answer with one line per number in the form "1. translation", in the same order.
+
+`
+
+// batchAnswerLine matches a numbered line of a batch translation
+var batchAnswerLine = regexp.MustCompile(`^(\d+)[.)]\s*(.*)$`)
+
+// providerBackend translates with a language model provider such as OpenAI, Anthropic or a local Ollama model
+type providerBackend struct {
+	provider Provider
+}
+
+// NewProviderBackend creates a translation backend that asks provider to translate
+func NewProviderBackend(provider Provider) TranslationBackend {
+	return &providerBackend{provider: provider}
+}
+
+// Name returns the provider name
+func (b *providerBackend) Name() string {
+	return b.provider.Name()
+}
+
+// TranslateBatch translates texts in one completion of numbered lines. If the answer
+// does not have a line for every text, each text is translated on its own.
+func (b *providerBackend) TranslateBatch(ctx context.Context, texts []string) ([]string, error) {
+	if len(texts) == 1 {
+		translated, err := b.translate(ctx, texts[0])
+		if err != nil {
+			return nil, err
+		}
+		return []string{translated}, nil
+	}
+
+	var prompt strings.Builder
+	prompt.WriteString(batchTranslationPrompt)
+	for i, text := range texts {
+		// One line per text so the numbers stay at the start of the lines
+		fmt.Fprintf(&prompt, "%d. %s\n", i+1, strings.Join(strings.Fields(text), " "))
+	}
+	answer, err := b.provider.Complete(ctx, CompletionRequest{
+		System:      translationSystemPrompt,
+		Prompt:      prompt.String(),
+		Temperature: 0.3,
+		MaxTokens:   200 * len(texts),
+	})
+	if err != nil {
+		return nil, err
+	}
+	if translations, ok := parseBatchAnswer(answer, len(texts)); ok {
+		return translations, nil
+	}
+
+	translations := make([]string, len(texts))
+	for i, text := range texts {
+		if translations[i], err = b.translate(ctx, text); err != nil {
+			return nil, err
+		}
+	}
+	return translations, nil
+}
+
+// translate translates a single text
+func (b *providerBackend) translate(ctx context.Context, text string) (string, error) {
+	return b.provider.Complete(ctx, CompletionRequest{
+		System:      translationSystemPrompt,
+		Prompt:      text,
+		Temperature: 0.3,
+		MaxTokens:   200,
+	})
+}
+
+// parseBatchAnswer returns the translations of n numbered lines, or false if one is missing
+func parseBatchAnswer(answer string, n int) ([]string, bool) {
+	translations := make([]string, n)
+	for _, line := range strings.Split(answer, "\n") {
+		m := batchAnswerLine.FindStringSubmatch(strings.TrimSpace(line))
+		if m == nil {
+			continue
+		}
+		i, err := strconv.Atoi(m[1])
+		if err != nil || i < 1 || i > n {
+			continue
+		}
+		translations[i-1] = strings.TrimSpace(m[2])
+	}
+	for _, t := range translations {
+		if t == "" {
+			return nil, false
+		}
+	}
+	return translations, true
+}
+
+// Batching of translation requests
+const (
+	translationBatchWindow = 20 * time.Millisecond // Time a translation waits for others to share its request
+	maxTranslationBatch    = 16                    // Most texts translated in one request
+)
+
+// batchRequest is a text waiting for its batch to be translated
+type batchRequest struct {
+	text string
+	done chan batchResult
+}
+
+type batchResult struct {
+	text string
+	err  error
+}
+
+// translationBatcher collects concurrent translations, such as narrations of several
+// sessions, into one backend request. Like a provider in a chain, a backend that
+// fails is skipped for a cooldown that doubles with each consecutive failure.
+type translationBatcher struct {
+	backend TranslationBackend
+	timeout time.Duration
+	window  time.Duration
+
+	mu        sync.Mutex
+	pending   []*batchRequest
+	failures  int
+	downUntil time.Time
+	now       func() time.Time
+}
+
+// newTranslationBatcher creates a batcher whose requests to backend time out after timeout
+func newTranslationBatcher(backend TranslationBackend, timeout time.Duration) *translationBatcher {
+	return &translationBatcher{
+		backend: backend,
+		timeout: timeout,
+		window:  translationBatchWindow,
+		now:     time.Now,
+	}
+}
+
+// Translate translates text in the next batch
+func (b *translationBatcher) Translate(ctx context.Context, text string) (string, error) {
+	req := &batchRequest{text: text, done: make(chan batchResult, 1)}
+
+	b.mu.Lock()
+	b.pending = append(b.pending, req)
+	switch len(b.pending) {
+	case maxTranslationBatch:
+		batch := b.pending
+		b.pending = nil
+		go b.run(batch)
+	case 1:
+		time.AfterFunc(b.window, b.flush)
+	}
+	b.mu.Unlock()
+
+	select {
+	case result := <-req.done:
+		return result.text, result.err
+	case <-ctx.Done():
+		return "", ctx.Err()
+	}
+}
+
+// Healthy reports whether the backend is not cooling down after a failure
+func (b *translationBatcher) Healthy() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return !b.now().Before(b.downUntil)
+}
+
+// flush translates the pending texts
+func (b *translationBatcher) flush() {
+	b.mu.Lock()
+	batch := b.pending
+	b.pending = nil
+	b.mu.Unlock()
+	if len(batch) > 0 {
+		b.run(batch)
+	}
+}
+
+// run translates a batch and hands each text its translation
+func (b *translationBatcher) run(batch []*batchRequest) {
+	// Not bound to a caller, so one giving up does not fail the batch of the others
+	ctx, cancel := context.WithTimeout(context.Background(), b.timeout)
+	defer cancel()
+
+	texts := make([]string, len(batch))
+	for i, req := range batch {
+		texts[i] = req.text
+	}
+	translations, err := b.backend.TranslateBatch(ctx, texts)
+	if err == nil && len(translations) != len(texts) {
+		err = fmt.Errorf("got %d translations for %d texts", len(translations), len(texts))
+	}
+	b.record(err)
+
+	for i, req := range batch {
+		if err != nil {
+			req.done <- batchResult{err: err}
+		} else {
+			req.done <- batchResult{text: translations[i]}
+		}
+	}
+}
+
+// record updates the health of the backend after a request
+func (b *translationBatcher) record(err error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if err == nil {
+		b.failures = 0
+		b.downUntil = time.Time{}
+		return
+	}
+	b.failures++
+	b.downUntil = b.now().Add(min(providerCooldown<<min(b.failures-1, 10), providerMaxCooldown))
+}
@@ -0,0 +1,176 @@
+package narrator
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+// promptRecorder answers each prompt with the next result
+type promptRecorder struct {
+	results []string
+	prompts []string
+}
+
+func (p *promptRecorder) Name() string { return "recorder" }
+
+func (p *promptRecorder) Complete(ctx context.Context, req CompletionRequest) (string, error) {
+	p.prompts = append(p.prompts, req.Prompt)
+	result := p.results[0]
+	p.results = p.results[1:]
+	return result, nil
+}
+
+func TestProviderBackend_TranslateBatch(t *testing.T) {
+	ctx := context.Background()
+	texts := []string{"Reading the config", "Running the\ntests"}
+
+	provider := &promptRecorder{results: []string{"1. 設定を読みます\n2) テストを実行します"}}
+	got, err := NewProviderBackend(provider).TranslateBatch(ctx, texts)
+	if err != nil {
+		t.Fatalf("TranslateBatch() error = %v", err)
+	}
+	if diff := cmp.Diff([]string{"設定を読みます", "テストを実行します"}, got); diff != "" {
+		t.Errorf("TranslateBatch() mismatch (-want +got):\n%s", diff)
+	}
+	if len(provider.prompts) != 1 || !strings.Contains(provider.prompts[0], "1. Reading the config\n2. Running the tests\n") {
+		t.Errorf("prompts = %q, want one numbered line per text", provider.prompts)
+	}
+
+	// An answer missing a line falls back to a request per text
+	provider = &promptRecorder{results: []string{"1. 設定を読みます", "設定を読みます", "テストを実行します"}}
+	got, err = NewProviderBackend(provider).TranslateBatch(ctx, texts)
+	if err != nil {
+		t.Fatalf("TranslateBatch() error = %v", err)
+	}
+	if diff := cmp.Diff([]string{"設定を読みます", "テストを実行します"}, got); diff != "" {
+		t.Errorf("TranslateBatch() mismatch (-want +got):\n%s", diff)
+	}
+	if len(provider.prompts) != 3 || provider.prompts[1] != texts[0] {
+		t.Errorf("prompts = %q, want the batch then each text", provider.prompts)
+	}
+}
+
+// batchRecorder records the batches it translates
+type batchRecorder struct {
+	mu      sync.Mutex
+	batches [][]string
+	err     error
+}
+
+func (b *batchRecorder) Name() string { return "batch" }
+
+func (b *batchRecorder) TranslateBatch(ctx context.Context, texts []string) ([]string, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.batches = append(b.batches, texts)
+	if b.err != nil {
+		return nil, b.err
+	}
+	translations := make([]string, len(texts))
+	for i, text := range texts {
+		translations[i] = "訳:" + text
+	}
+	return translations, nil
+}
+
+func TestTranslationBatcher(t *testing.T) {
+	backend := &batchRecorder{}
+	b := newTranslationBatcher(backend, time.Second)
+	b.window = 100 * time.Millisecond
+
+	var wg sync.WaitGroup
+	results := make([]string, 3)
+	for i := range results {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			results[i], _ = b.Translate(context.Background(), fmt.Sprintf("text %d", i))
+		}()
+	}
+	wg.Wait()
+
+	for i, got := range results {
+		if want := fmt.Sprintf("訳:text %d", i); got != want {
+			t.Errorf("Translate(text %d) = %q, want %q", i, got, want)
+		}
+	}
+	if len(backend.batches) != 1 || len(backend.batches[0]) != 3 {
+		t.Errorf("batches = %q, want the three texts in one", backend.batches)
+	}
+
+	// A failing backend cools down
+	backend.err = fmt.Errorf("quota exceeded")
+	if _, err := b.Translate(context.Background(), "text"); err == nil || b.Healthy() {
+		t.Errorf("Translate() error = %v and healthy = %v, want an error and unhealthy", err, b.Healthy())
+	}
+}
+
+func TestCombinedTranslator_Backend(t *testing.T) {
+	const text = "Investigating flaky behaviour within scheduler internals"
+	backend := &batchRecorder{}
+	translator := NewCombinedTranslator("", false)
+	translator.SetBackend(backend)
+
+	for range 2 {
+		if got, _ := translator.Translate(context.Background(), text); got != "訳:"+text {
+			t.Errorf("Translate() = %q, want the backend translation", got)
+		}
+	}
+	if len(backend.batches) != 1 {
+		t.Errorf("backend called %d times, want 1 with the cached translation reused", len(backend.batches))
+	}
+
+	translator.SetBackend(nil)
+	if translator.AIHealthy() {
+		t.Error("AIHealthy() = true without a backend")
+	}
+}
+
+func TestDeepLBackend(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if got := r.Header.Get("Authorization"); got != "DeepL-Auth-Key secret:fx" {
+			w.WriteHeader(http.StatusForbidden)
+			w.Write([]byte(`{"message":"Wrong endpoint"}`))
+			return
+		}
+		var req deepLRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.SourceLang != "EN" || req.TargetLang != "JA" {
+			t.Errorf("request = %+v, %v", req, err)
+		}
+		var response deepLResponse
+		for _, text := range req.Text {
+			response.Translations = append(response.Translations, struct {
+				Text string `json:"text"`
+			}{"訳:" + text})
+		}
+		json.NewEncoder(w).Encode(response)
+	}))
+	defer ts.Close()
+
+	backend := NewDeepLBackend("secret:fx")
+	if backend.endpoint != deepLFreeURL || NewDeepLBackend("secret").endpoint != deepLProURL {
+		t.Errorf("endpoint = %s, want the free API for a :fx key", backend.endpoint)
+	}
+	backend.endpoint = ts.URL
+	got, err := backend.TranslateBatch(context.Background(), []string{"Hello", "World"})
+	if err != nil {
+		t.Fatalf("TranslateBatch() error = %v", err)
+	}
+	if diff := cmp.Diff([]string{"訳:Hello", "訳:World"}, got); diff != "" {
+		t.Errorf("TranslateBatch() mismatch (-want +got):\n%s", diff)
+	}
+
+	backend.apiKey = "wrong"
+	if _, err := backend.TranslateBatch(context.Background(), []string{"Hello"}); err == nil || !strings.Contains(err.Error(), "Wrong endpoint") {
+		t.Errorf("TranslateBatch() error = %v, want the API message", err)
+	}
+}
@@ -0,0 +1,176 @@
+package narrator
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/kazegusuri/claude-companion/logger"
+)
+
+// maxTranslationCacheEntries bounds the cache; the least recently used translations are dropped
+const maxTranslationCacheEntries = 5000
+
+// translationCacheEntry is a cached translation
+type translationCacheEntry struct {
+	Translation string    `json:"translation"`
+	Used        time.Time `json:"used"`
+}
+
+// TranslationCache keeps AI translations and persists them to a JSON file, so the
+// same narration is read the same way across restarts without calling the API again
+type TranslationCache struct {
+	path    string
+	mu      sync.Mutex
+	entries map[string]*translationCacheEntry // key: source text
+	dirty   bool
+	now     func() time.Time
+
+	done chan struct{}
+	wg   sync.WaitGroup
+}
+
+// NewTranslationCache creates a cache persisted to path. An empty path keeps it in memory.
+func NewTranslationCache(path string) *TranslationCache {
+	return &TranslationCache{
+		path:    path,
+		entries: make(map[string]*translationCacheEntry),
+		now:     time.Now,
+	}
+}
+
+// Get returns the cached translation of text
+func (c *TranslationCache) Get(text string) (string, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	entry, ok := c.entries[text]
+	if !ok {
+		return "", false
+	}
+	entry.Used = c.now()
+	return entry.Translation, true
+}
+
+// Put caches the translation of text
+func (c *TranslationCache) Put(text, translation string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if _, ok := c.entries[text]; !ok && len(c.entries) >= maxTranslationCacheEntries {
+		c.evict()
+	}
+	c.entries[text] = &translationCacheEntry{Translation: translation, Used: c.now()}
+	c.dirty = true
+}
+
+// Len returns the number of cached translations
+func (c *TranslationCache) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return len(c.entries)
+}
+
+// evict drops the least recently used translation; c.mu must be held
+func (c *TranslationCache) evict() {
+	var oldest string
+	var oldestUsed time.Time
+	for text, entry := range c.entries {
+		if oldestUsed.IsZero() || entry.Used.Before(oldestUsed) {
+			oldest, oldestUsed = text, entry.Used
+		}
+	}
+	delete(c.entries, oldest)
+}
+
+// Load reads the persisted translations. A missing file is not an error.
+func (c *TranslationCache) Load() error {
+	if c.path == "" {
+		return nil
+	}
+	data, err := os.ReadFile(c.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read translation cache: %w", err)
+	}
+	var entries map[string]*translationCacheEntry
+	if err := json.Unmarshal(data, &entries); err != nil {
+		return fmt.Errorf("failed to parse translation cache %s: %w", c.path, err)
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for text, entry := range entries {
+		if entry != nil && entry.Translation != "" {
+			c.entries[text] = entry
+		}
+	}
+	return nil
+}
+
+// Save writes the translations to the cache file if they changed since the last save
+func (c *TranslationCache) Save() error {
+	if c.path == "" {
+		return nil
+	}
+	c.mu.Lock()
+	if !c.dirty {
+		c.mu.Unlock()
+		return nil
+	}
+	data, err := json.MarshalIndent(c.entries, "", "  ")
+	c.dirty = false
+	c.mu.Unlock()
+	if err != nil {
+		return fmt.Errorf("failed to encode translation cache: %w", err)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(c.path), 0o755); err != nil {
+		return fmt.Errorf("failed to create translation cache directory: %w", err)
+	}
+	// Write a temporary file and rename it so a crash never leaves a partial file
+	tmp := c.path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o600); err != nil {
+		return fmt.Errorf("failed to write translation cache: %w", err)
+	}
+	if err := os.Rename(tmp, c.path); err != nil {
+		return fmt.Errorf("failed to write translation cache: %w", err)
+	}
+	return nil
+}
+
+// Start saves the translations periodically in the background
+func (c *TranslationCache) Start(interval time.Duration) {
+	c.done = make(chan struct{})
+	c.wg.Add(1)
+	go func() {
+		defer c.wg.Done()
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				if err := c.Save(); err != nil {
+					logger.LogError("Error saving translation cache: %v", err)
+				}
+			case <-c.done:
+				return
+			}
+		}
+	}()
+}
+
+// Stop stops the periodic saves and saves the translations one last time
+func (c *TranslationCache) Stop() {
+	if c.done != nil {
+		close(c.done)
+		c.wg.Wait()
+	}
+	if err := c.Save(); err != nil {
+		logger.LogError("Error saving translation cache: %v", err)
+	}
+}
@@ -0,0 +1,64 @@
+package narrator
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"testing"
+)
+
+func TestTranslationCache_Persistence(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cache", "translations.json")
+	cache := NewTranslationCache(path)
+	cache.Put("Build finished", "ビルドが完了しました")
+	if err := cache.Save(); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+
+	reloaded := NewTranslationCache(path)
+	if err := reloaded.Load(); err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if got, ok := reloaded.Get("Build finished"); !ok || got != "ビルドが完了しました" {
+		t.Errorf("Get() = %q, %v after reload", got, ok)
+	}
+
+	if err := NewTranslationCache(filepath.Join(t.TempDir(), "missing.json")).Load(); err != nil {
+		t.Errorf("Load() of a missing file error = %v", err)
+	}
+}
+
+func TestCombinedTranslator_Fallback(t *testing.T) {
+	const text = "Investigating flaky behaviour within scheduler internals"
+	simple := NewSimpleTranslator().Translate(text)
+	provider := &fakeProvider{name: "fake", err: errors.New("service unavailable")}
+	translator := NewCombinedTranslatorWithProvider(provider)
+	ctx := context.Background()
+
+	// The provider fails: use the rules and skip the provider while it cools down
+	for range 2 {
+		if got, _ := translator.Translate(ctx, text); got != simple {
+			t.Errorf("Translate() = %q, want the rule-based %q", got, simple)
+		}
+	}
+	if provider.calls != 1 || translator.AIHealthy() {
+		t.Errorf("provider called %d times and healthy = %v, want 1 call and unhealthy", provider.calls, translator.AIHealthy())
+	}
+
+	// Cached translations are still used while the provider is down
+	translator.cache.Put(text, "スケジューラーの内部を調査しています")
+	if got, _ := translator.Translate(ctx, text); got != "スケジューラーの内部を調査しています" {
+		t.Errorf("Translate() = %q, want the cached translation", got)
+	}
+}
+
+func TestCombinedTranslator_Glossary(t *testing.T) {
+	provider := &fakeProvider{name: "fake", result: "pull requestをレビューしています"}
+	translator := NewCombinedTranslatorWithProvider(provider)
+	translator.SetGlossary(NewGlossary(map[string]string{"pull request": "プルリクエスト"}))
+
+	got, _ := translator.Translate(context.Background(), "Reviewing the Pull Request before merging upstream")
+	if got != "プルリクエストをレビューしています" {
+		t.Errorf("Translate() = %q, want the glossary reading", got)
+	}
+}
@@ -0,0 +1,106 @@
+package narrator
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// DeepL API endpoints; keys of the free plan end with ":fx"
+const (
+	deepLFreeURL = "https://api-free.deepl.com/v2/translate"
+	deepLProURL  = "https://api.deepl.com/v2/translate"
+)
+
+// DeepLBackend translates with the DeepL API
+type DeepLBackend struct {
+	apiKey     string
+	endpoint   string
+	httpClient *http.Client
+}
+
+// NewDeepLBackend creates a DeepL backend for the free or pro API depending on apiKey
+func NewDeepLBackend(apiKey string) *DeepLBackend {
+	endpoint := deepLProURL
+	if strings.HasSuffix(apiKey, ":fx") {
+		endpoint = deepLFreeURL
+	}
+	return &DeepLBackend{
+		apiKey:   apiKey,
+		endpoint: endpoint,
+		httpClient: &http.Client{
+			Timeout: 10 * time.Second,
+		},
+	}
+}
+
+// Name returns the backend name
+func (b *DeepLBackend) Name() string {
+	return "deepl"
+}
+
+// DeepL API structures
+type deepLRequest struct {
+	Text       []string `json:"text"`
+	SourceLang string   `json:"source_lang"`
+	TargetLang string   `json:"target_lang"`
+}
+
+type deepLResponse struct {
+	Translations []struct {
+		Text string `json:"text"`
+	} `json:"translations"`
+	Message string `json:"message,omitempty"`
+}
+
+// TranslateBatch translates texts in one request
+func (b *DeepLBackend) TranslateBatch(ctx context.Context, texts []string) ([]string, error) {
+	jsonData, err := json.Marshal(deepLRequest{Text: texts, SourceLang: "EN", TargetLang: "JA"})
+	if err != nil {
+		return nil, err
+	}
+
+	httpReq, err := http.NewRequestWithContext(ctx, "POST", b.endpoint, bytes.NewBuffer(jsonData))
+	if err != nil {
+		return nil, err
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+	httpReq.Header.Set("Authorization", "DeepL-Auth-Key "+b.apiKey)
+
+	resp, err := b.httpClient.Do(httpReq)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		// Quota and authentication errors come with a message, if any
+		var response deepLResponse
+		if json.Unmarshal(body, &response) == nil && response.Message != "" {
+			return nil, fmt.Errorf("DeepL API error: %s", response.Message)
+		}
+		return nil, fmt.Errorf("DeepL API error: %s", resp.Status)
+	}
+
+	var response deepLResponse
+	if err := json.Unmarshal(body, &response); err != nil {
+		return nil, err
+	}
+	if len(response.Translations) != len(texts) {
+		return nil, fmt.Errorf("DeepL returned %d translations for %d texts", len(response.Translations), len(texts))
+	}
+	translations := make([]string, len(texts))
+	for i, t := range response.Translations {
+		translations[i] = t.Text
+	}
+	return translations, nil
+}
@@ -0,0 +1,86 @@
+package narrator
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// UserDictionary holds custom readings for spoken narrations, such as project names
+// the synthesizer mispronounces. Its entries are merged over the built-in ones.
+type UserDictionary struct {
+	Terms   map[string]string `json:"terms"`   // Term, file name or extension to its reading
+	Domains map[string]string `json:"domains"` // Domain to the reading of URLs on it
+	Rules   []DictionaryRule  `json:"rules"`   // Regexp rules applied before anything else
+}
+
+// DictionaryRule replaces the matches of a regexp in narrations
+type DictionaryRule struct {
+	Pattern     string `json:"pattern"`     // Go regexp syntax
+	Replacement string `json:"replacement"` // May refer to groups as $1 or ${name}
+}
+
+// dictionaryRule is a DictionaryRule with its regexp compiled
+type dictionaryRule struct {
+	re          *regexp.Regexp
+	replacement string
+}
+
+// LoadUserDictionary loads a user dictionary: JSON, or YAML for .yaml and .yml files,
+// where later documents add to earlier ones
+func LoadUserDictionary(path string) (*UserDictionary, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read user dictionary: %w", err)
+	}
+
+	docs := [][]byte{data}
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml":
+		if docs, err = yamlDocumentsToJSON(data); err != nil {
+			return nil, fmt.Errorf("failed to parse user dictionary %s: %w", path, err)
+		}
+	}
+
+	dict := &UserDictionary{}
+	for _, doc := range docs {
+		var d UserDictionary
+		if err := json.Unmarshal(doc, &d); err != nil {
+			return nil, fmt.Errorf("failed to parse user dictionary %s: %w", path, err)
+		}
+		dict.Terms = mergeMap(dict.Terms, d.Terms)
+		dict.Domains = mergeMap(dict.Domains, d.Domains)
+		dict.Rules = append(dict.Rules, d.Rules...)
+	}
+	if _, err := dict.compileRules(); err != nil {
+		return nil, fmt.Errorf("invalid user dictionary %s: %w", path, err)
+	}
+	return dict, nil
+}
+
+// compileRules compiles the regexps of the rules
+func (d *UserDictionary) compileRules() ([]dictionaryRule, error) {
+	rules := make([]dictionaryRule, 0, len(d.Rules))
+	for i, rule := range d.Rules {
+		if rule.Pattern == "" {
+			return nil, fmt.Errorf("rule %d has no pattern", i+1)
+		}
+		re, err := regexp.Compile(rule.Pattern)
+		if err != nil {
+			return nil, fmt.Errorf("rule %d: %w", i+1, err)
+		}
+		rules = append(rules, dictionaryRule{re: re, replacement: rule.Replacement})
+	}
+	return rules, nil
+}
+
+// Len returns the number of entries in the dictionary
+func (d *UserDictionary) Len() int {
+	if d == nil {
+		return 0
+	}
+	return len(d.Terms) + len(d.Domains) + len(d.Rules)
+}
@@ -0,0 +1,81 @@
+package narrator
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestLoadUserDictionary(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "dictionary.yaml")
+	content := `terms:
+  kazegusuri: カゼグスリ
+domains:
+  example.com: イグザンプル
+rules:
+  - pattern: 'PR#(\d+)'
+    replacement: 'プルリク$1'
+---
+terms:
+  companion: コンパニオン
+`
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	dict, err := LoadUserDictionary(path)
+	if err != nil {
+		t.Fatalf("LoadUserDictionary() error = %v", err)
+	}
+	if dict.Len() != 4 {
+		t.Errorf("Len() = %d, want 4 entries from both documents", dict.Len())
+	}
+
+	invalid := filepath.Join(dir, "invalid.json")
+	if err := os.WriteFile(invalid, []byte(`{"rules": [{"pattern": "(unclosed", "replacement": ""}]}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := LoadUserDictionary(invalid); err == nil || !strings.Contains(err.Error(), "rule 1") {
+		t.Errorf("LoadUserDictionary() with an invalid regexp error = %v, want one naming rule 1", err)
+	}
+}
+
+func TestTextNormalizer_SetUserDictionary(t *testing.T) {
+	n := NewTextNormalizer()
+	dict := &UserDictionary{
+		Terms:   map[string]string{"kazegusuri": "カゼグスリ", "git": "ギット君"},
+		Domains: map[string]string{"example.com": "イグザンプル"},
+		Rules:   []DictionaryRule{{Pattern: `PR#(\d+)`, Replacement: "プルリク$1"}},
+	}
+	if err := n.SetUserDictionary(dict); err != nil {
+		t.Fatalf("SetUserDictionary() error = %v", err)
+	}
+
+	tests := []struct {
+		input string
+		want  string
+	}{
+		{"kazegusuriを開きます", "カゼグスリを開きます"},
+		{"gitを実行します", "ギット君を実行します"}, // User terms override the built-in ones
+		{"https://example.com/docsを開きます", "イグザンプルを開きます"},
+		{"PR#42を確認します", "プルリク42を確認します"},
+		{"READMEを読みます", "リードミーを読みます"}, // Built-in terms still apply
+	}
+	for _, tt := range tests {
+		if got := n.Normalize(tt.input); got != tt.want {
+			t.Errorf("Normalize(%q) = %q, want %q", tt.input, got, tt.want)
+		}
+	}
+
+	// Clearing the dictionary restores the built-in readings
+	if err := n.SetUserDictionary(nil); err != nil {
+		t.Fatal(err)
+	}
+	if got := n.Normalize("gitを実行します"); got != "ギットを実行します" {
+		t.Errorf("Normalize() after clearing = %q, want the built-in reading", got)
+	}
+	if defaultReplacements["git"] != "ギット" {
+		t.Error("SetUserDictionary() modified the built-in replacements")
+	}
+}
@@ -0,0 +1,106 @@
+package narrator
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// Verbosity is how much of a tool use is narrated on an output
+type Verbosity string
+
+const (
+	VerbositySilent   Verbosity = "silent"   // Nothing at all
+	VerbosityShort    Verbosity = "short"    // A short message without the tool input
+	VerbosityDetailed Verbosity = "detailed" // The full narration, with its details on the console
+)
+
+// valid reports whether v is a known level; an empty level is valid and means detailed
+func (v Verbosity) valid() bool {
+	switch v {
+	case "", VerbositySilent, VerbosityShort, VerbosityDetailed:
+		return true
+	}
+	return false
+}
+
+// VerbosityRule sets the verbosity of a tool on each output: the console, voice and
+// the stream of events sent to WebSocket, SSE and MQTT clients. In a config it is
+// either a single level for every output or an object of levels per output.
+type VerbosityRule struct {
+	Console Verbosity `json:"console,omitempty"`
+	Voice   Verbosity `json:"voice,omitempty"`
+	Stream  Verbosity `json:"stream,omitempty"`
+}
+
+// UnmarshalJSON accepts a level for every output, such as "silent", or an object of levels
+func (r *VerbosityRule) UnmarshalJSON(data []byte) error {
+	var level Verbosity
+	if err := json.Unmarshal(data, &level); err == nil {
+		*r = VerbosityRule{Console: level, Voice: level, Stream: level}
+	} else {
+		type plain VerbosityRule
+		if err := json.Unmarshal(data, (*plain)(r)); err != nil {
+			return err
+		}
+	}
+	for _, v := range []Verbosity{r.Console, r.Voice, r.Stream} {
+		if !v.valid() {
+			return fmt.Errorf("unknown verbosity %q (expected silent, short or detailed)", v)
+		}
+	}
+	return nil
+}
+
+// override returns r with the levels set in o
+func (r VerbosityRule) override(o *VerbosityRule) VerbosityRule {
+	if o == nil {
+		return r
+	}
+	if o.Console != "" {
+		r.Console = o.Console
+	}
+	if o.Voice != "" {
+		r.Voice = o.Voice
+	}
+	if o.Stream != "" {
+		r.Stream = o.Stream
+	}
+	return r
+}
+
+// ToolVerbosity is how a tool use is narrated on each output
+type ToolVerbosity struct {
+	VerbosityRule
+	Short string // The message narrated at VerbosityShort
+}
+
+// DetailedVerbosity narrates a tool in full on every output
+var DetailedVerbosity = ToolVerbosity{VerbosityRule: VerbosityRule{Console: VerbosityDetailed, Voice: VerbosityDetailed, Stream: VerbosityDetailed}}
+
+// Narration returns what is narrated at level for a tool use narrated as narration:
+// nothing when silent, the short message when short, and narration otherwise
+func (v ToolVerbosity) Narration(level Verbosity, narration string) string {
+	switch level {
+	case VerbositySilent:
+		return ""
+	case VerbosityShort:
+		if v.Short != "" {
+			return v.Short
+		}
+	}
+	return narration
+}
+
+// VerbosityNarrator is a narrator whose rules set the verbosity of each tool
+type VerbosityNarrator interface {
+	ToolVerbosity(toolName string) ToolVerbosity
+}
+
+// VerbosityOf returns the verbosity of a tool under the rules of n. Tools are detailed
+// on every output of narrators without rules.
+func VerbosityOf(n Narrator, toolName string) ToolVerbosity {
+	if vn, ok := n.(VerbosityNarrator); ok {
+		return vn.ToolVerbosity(toolName)
+	}
+	return DetailedVerbosity
+}
@@ -0,0 +1,107 @@
+package narrator
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestVerbosityRule_UnmarshalJSON(t *testing.T) {
+	tests := []struct {
+		name    string
+		data    string
+		want    VerbosityRule
+		wantErr bool
+	}{
+		{"single level", `"silent"`, VerbosityRule{Console: VerbositySilent, Voice: VerbositySilent, Stream: VerbositySilent}, false},
+		{"per output", `{"voice": "silent", "console": "short"}`, VerbosityRule{Console: VerbosityShort, Voice: VerbositySilent}, false},
+		{"unknown level", `"quiet"`, VerbosityRule{}, true},
+		{"unknown level of an output", `{"stream": "loud"}`, VerbosityRule{}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got VerbosityRule
+			err := json.Unmarshal([]byte(tt.data), &got)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Unmarshal() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && got != tt.want {
+				t.Errorf("Unmarshal() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRuleBasedNarrator_ToolVerbosity(t *testing.T) {
+	var config NarratorConfig
+	err := json.Unmarshal([]byte(`{
+		"rules": {
+			"Read": {"verbosity": {"voice": "silent", "stream": "short"}},
+			"Bash": {"verbosity": "detailed", "short": "実行します"}
+		},
+		"mcpRules": {
+			"github": {
+				"default": "GitHubの{operation}を実行します",
+				"verbosity": "short",
+				"rules": {"create_issue": {"default": "Issueを作ります", "verbosity": {"console": "detailed"}}}
+			}
+		}
+	}`), &config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	n := NewRuleBasedNarrator(&config)
+
+	tests := []struct {
+		tool string
+		want ToolVerbosity
+	}{
+		{"Read", ToolVerbosity{VerbosityRule{VerbosityDetailed, VerbositySilent, VerbosityShort}, "ファイルを読み込みます"}},
+		{"Bash", ToolVerbosity{VerbosityRule{VerbosityDetailed, VerbosityDetailed, VerbosityDetailed}, "実行します"}},
+		{"Unknown", ToolVerbosity{VerbosityRule{VerbosityDetailed, VerbosityDetailed, VerbosityDetailed}, "ツール「Unknown」を実行します"}},
+		{"mcp__github__list_issues", ToolVerbosity{VerbosityRule{VerbosityShort, VerbosityShort, VerbosityShort}, "GitHubのlist_issuesを実行します"}},
+		{"mcp__github__create_issue", ToolVerbosity{VerbosityRule{VerbosityDetailed, VerbosityShort, VerbosityShort}, "GitHubのcreate_issueを実行します"}},
+	}
+	for _, tt := range tests {
+		if got := n.ToolVerbosity(tt.tool); got != tt.want {
+			t.Errorf("ToolVerbosity(%s) = %+v, want %+v", tt.tool, got, tt.want)
+		}
+	}
+
+	// A rule with only a verbosity still narrates with the default messages
+	if got, _ := n.NarrateToolUse("Read", map[string]interface{}{"file_path": "/src/main.go"}); got != "Goファイル「main.go」を読み込みます" {
+		t.Errorf("NarrateToolUse(Read) = %q", got)
+	}
+}
+
+func TestToolVerbosity_Narration(t *testing.T) {
+	v := ToolVerbosity{Short: "short"}
+	tests := []struct {
+		level Verbosity
+		want  string
+	}{
+		{VerbositySilent, ""},
+		{VerbosityShort, "short"},
+		{VerbosityDetailed, "full"},
+		{"", "full"},
+	}
+	for _, tt := range tests {
+		if got := v.Narration(tt.level, "full"); got != tt.want {
+			t.Errorf("Narration(%q) = %q, want %q", tt.level, got, tt.want)
+		}
+	}
+	if got := (ToolVerbosity{}).Narration(VerbosityShort, "full"); got != "full" {
+		t.Errorf("Narration(short) without a short message = %q, want the full narration", got)
+	}
+}
+
+func TestVerbosityOf(t *testing.T) {
+	if got := VerbosityOf(NewNoOpNarrator(), "Read"); got != DetailedVerbosity {
+		t.Errorf("VerbosityOf(NoOpNarrator) = %+v, want detailed", got)
+	}
+	hybrid := NewHybridNarrator("", false)
+	hybrid.SetConfig(&NarratorConfig{Rules: map[string]ToolRules{"Read": {Verbosity: &VerbosityRule{Voice: VerbositySilent}}}})
+	voice := NewVoiceNarrator(hybrid, nil, nil, false)
+	if got := VerbosityOf(voice, "Read").Voice; got != VerbositySilent {
+		t.Errorf("VerbosityOf(VoiceNarrator).Voice = %q, want silent", got)
+	}
+}
@@ -4,10 +4,12 @@ import (
 	"context"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/google/uuid"
 	"github.com/kazegusuri/claude-companion/logger"
+	"github.com/kazegusuri/claude-companion/notify"
 	"github.com/kazegusuri/claude-companion/speech"
 )
 
@@ -24,6 +26,33 @@ type VoiceNarrator struct {
 	normalizer  *TextNormalizer
 	translator  *CombinedTranslator
 	metrics     *NarrationMetrics
+	scorer      PriorityScorer
+	summarizer  *VoiceSummarizer  // Shortens long text narrations; nil speaks them in full
+	deduper     *NarrationDeduper // Collapses repeated tool narrations; nil speaks every one
+	log         *NarrationLog     // Records spoken narrations; nil without a log
+	quietHours  atomic.Pointer[notify.QuietHours]
+	muted       atomic.Bool      // Muted at runtime through the admin API
+	focus       *SessionFocus    // Speaks only the session in focus; nil speaks every session
+	announce    bool             // Announce when the focus moves to another session
+	prefetcher  *synthPrefetcher // Synthesizes queued narrations ahead; nil synthesizes each before playing it
+	health      *engineHealth    // Mutes the voice while the speech engine is down
+
+	// Voice parameters of each narration type over the synthesizer's own
+	styles map[NarrationType]map[string]float64
+
+	// Tool uses held back to be spoken as one narration
+	batchMu  sync.Mutex
+	batching bool
+	batch    []batchedToolUse
+
+	// Per-session speaker selection
+	speakerMu  sync.Mutex
+	speakerMap *SpeakerMap
+	speakerID  *int
+	speaker    *int // Speaker chosen at runtime, over the speaker map
+	project    string
+	session    string
+	overrides  *ProjectOverrides
 }
 
 // NewVoiceNarrator creates a new voice narrator
@@ -46,6 +75,7 @@ func NewVoiceNarratorWithTranslator(narrator Narrator, synthesizer speech.Synthe
 		normalizer:  NewTextNormalizer(),
 		translator:  NewCombinedTranslator(openaiAPIKey, useOpenAI),
 		metrics:     NewNarrationMetrics(),
+		scorer:      NewRulePriorityScorer(),
 	}
 
 	if enabled && synthesizer != nil && player != nil {
@@ -54,6 +84,7 @@ func NewVoiceNarratorWithTranslator(narrator Narrator, synthesizer speech.Synthe
 			logger.LogWarning("Speech synthesizer is not available")
 			vn.enabled = false
 		} else {
+			vn.health = newEngineHealth(synthesizer.IsAvailable)
 			// Start voice worker
 			vn.wg.Add(1)
 			go vn.voiceWorker()
@@ -63,17 +94,77 @@ func NewVoiceNarratorWithTranslator(narrator Narrator, synthesizer speech.Synthe
 	return vn
 }
 
-// NarrateToolUse narrates tool usage with optional voice
+// SetSpeakerMap sets the mapping used to pick a speaker for each project and session
+func (vn *VoiceNarrator) SetSpeakerMap(m *SpeakerMap) {
+	vn.speakerMu.Lock()
+	defer vn.speakerMu.Unlock()
+	vn.speakerMap = m
+}
+
+// SetPriorityScorer sets the scorer that assigns queue priorities to narrations
+func (vn *VoiceNarrator) SetPriorityScorer(scorer PriorityScorer) {
+	vn.scorer = scorer
+}
+
+// SetSession selects the speaker for narrations of the given project and session
+func (vn *VoiceNarrator) SetSession(project, session string) {
+	vn.speakerMu.Lock()
+	defer vn.speakerMu.Unlock()
+	vn.project, vn.session = project, session
+	vn.speakerID = nil
+	if id, ok := vn.speakerMap.SpeakerFor(project, session); ok {
+		vn.speakerID = &id
+	}
+}
+
+// SetSpeaker speaks every session with a speaker instead of the one from the speaker
+// map; nil goes back to the map. Project overrides still take precedence.
+func (vn *VoiceNarrator) SetSpeaker(id *int) {
+	vn.speakerMu.Lock()
+	defer vn.speakerMu.Unlock()
+	vn.speaker = id
+}
+
+// SetMuted stops or resumes speaking narrations. Narrations are dropped while muted.
+func (vn *VoiceNarrator) SetMuted(muted bool) {
+	vn.muted.Store(muted)
+}
+
+// SetProjectOverrides overrides the speaker or mutes narrations of the project narrated
+// next, and passes the overrides on to the wrapped narrator
+func (vn *VoiceNarrator) SetProjectOverrides(o *ProjectOverrides) {
+	vn.speakerMu.Lock()
+	vn.overrides = o
+	vn.speakerMu.Unlock()
+	if pa, ok := vn.narrator.(ProjectAware); ok {
+		pa.SetProjectOverrides(o)
+	}
+}
+
+// ToolVerbosity returns how much of a tool is narrated on each output under the rules
+// of the wrapped narrator
+func (vn *VoiceNarrator) ToolVerbosity(toolName string) ToolVerbosity {
+	return VerbosityOf(vn.narrator, toolName)
+}
+
+// NarrateToolUse narrates tool usage with optional voice. Only the voice verbosity of
+// the tool applies here; the narration is returned in full for the other outputs.
 func (vn *VoiceNarrator) NarrateToolUse(toolName string, input map[string]interface{}) (string, bool) {
 	text, shouldFallback := vn.narrator.NarrateToolUse(toolName, input)
 
 	if vn.enabled && text != "" {
-		narType := NarrationTypeToolUse
-		if isMCPTool(toolName) {
-			narType = NarrationTypeToolUseMCP
+		v := vn.ToolVerbosity(toolName)
+		if spoken := v.Narration(v.Voice, text); spoken != "" {
+			narType := NarrationTypeToolUse
+			if isMCPTool(toolName) {
+				narType = NarrationTypeToolUseMCP
+			}
+
+			in := PriorityInput{Type: narType, ToolName: toolName, Target: toolTarget(input)}
+			if !vn.holdForBatch(spoken, in) {
+				vn.enqueueNarration(spoken, in)
+			}
 		}
-
-		vn.enqueueNarration(text, narType)
 	}
 
 	return text, shouldFallback
@@ -84,18 +175,56 @@ func (vn *VoiceNarrator) NarrateToolUsePermission(toolName string) (string, bool
 	text, shouldFallback := vn.narrator.NarrateToolUsePermission(toolName)
 
 	if vn.enabled && text != "" {
-		vn.enqueueNarration(text, NarrationTypeToolUsePermission)
+		vn.enqueueNarration(text, PriorityInput{Type: NarrationTypeToolUsePermission, ToolName: toolName})
 	}
 
 	return text, shouldFallback
 }
 
-// NarrateText narrates text with optional voice
+// NarrateText narrates text with optional voice. With a streaming narrator each
+// sentence is queued as soon as it is available, so speech starts before the
+// whole narration is ready.
 func (vn *VoiceNarrator) NarrateText(text string, isThinking bool) (string, bool) {
+	narType := NarrationTypeText
+	if isThinking {
+		narType = NarrationTypeThinking
+	}
+	if sn, ok := vn.narrator.(StreamingNarrator); ok && vn.enabled {
+		in := PriorityInput{Type: narType, Text: text}
+		var spoken time.Duration
+		return sn.NarrateTextStream(text, isThinking, func(sentence string) {
+			if sentence == "" {
+				return
+			}
+			// Sentences are spoken as they arrive, so stop at the first one that no longer fits
+			if vn.summarizer != nil {
+				limit := vn.summarizer.MaxDuration()
+				duration := EstimateSpeechDuration(sentence)
+				switch {
+				case spoken >= limit:
+					return
+				case spoken == 0 && duration > limit:
+					sentence = TruncateForSpeech(sentence, limit)
+					duration = limit
+				case spoken+duration > limit:
+					spoken = limit
+					return
+				}
+				spoken += duration
+			}
+			vn.enqueueNarration(sentence, in)
+		})
+	}
+
 	result, shouldFallback := vn.narrator.NarrateText(text, isThinking)
 
+	// The console keeps the full narration; only the spoken one is shortened
 	if vn.enabled && result != "" {
-		vn.enqueueNarration(result, NarrationTypeText)
+		spoken := result
+		if vn.summarizer != nil {
+			spoken = vn.summarizer.Summarize(vn.ctx, result)
+		}
+		vn.enqueueNarration(spoken, PriorityInput{Type: narType, Text: text})
 	}
 
 	return result, shouldFallback
@@ -106,7 +235,7 @@ func (vn *VoiceNarrator) NarrateNotification(notificationType NotificationType)
 	text, shouldFallback := vn.narrator.NarrateNotification(notificationType)
 
 	if vn.enabled && text != "" {
-		vn.enqueueNarration(text, NarrationTypeNotification)
+		vn.enqueueNarration(text, PriorityInput{Type: NarrationTypeNotification, Text: text})
 	}
 
 	return text, shouldFallback
@@ -117,7 +246,7 @@ func (vn *VoiceNarrator) NarrateTaskCompletion(description string, subagentType
 	text, shouldFallback := vn.narrator.NarrateTaskCompletion(description, subagentType)
 
 	if vn.enabled && text != "" {
-		vn.enqueueNarration(text, NarrationTypeNotification)
+		vn.enqueueNarration(text, PriorityInput{Type: NarrationTypeNotification, Text: description})
 	}
 
 	return text, shouldFallback
@@ -128,12 +257,178 @@ func (vn *VoiceNarrator) NarrateAPIError(statusCode int, errorType string, messa
 	text, shouldFallback := vn.narrator.NarrateAPIError(statusCode, errorType, message)
 
 	if vn.enabled && text != "" {
-		vn.enqueueNarration(text, NarrationTypeNotification)
+		vn.enqueueNarration(text, PriorityInput{Type: NarrationTypeError, Text: message})
+	}
+
+	return text, shouldFallback
+}
+
+// NarrateToolSLABreach narrates a tool that took longer than expected with optional voice
+func (vn *VoiceNarrator) NarrateToolSLABreach(toolName string, elapsed, limit time.Duration) (string, bool) {
+	text, shouldFallback := vn.narrator.NarrateToolSLABreach(toolName, elapsed, limit)
+
+	if vn.enabled && text != "" {
+		vn.enqueueNarration(text, PriorityInput{Type: NarrationTypeError, ToolName: toolName, Text: text})
+	}
+
+	return text, shouldFallback
+}
+
+// NarrateToolDuration narrates how long a finished tool ran with optional voice
+func (vn *VoiceNarrator) NarrateToolDuration(toolName string, elapsed time.Duration) (string, bool) {
+	text, shouldFallback := vn.narrator.NarrateToolDuration(toolName, elapsed)
+
+	if vn.enabled && text != "" {
+		vn.enqueueNarration(text, PriorityInput{Type: NarrationTypeNotification, ToolName: toolName, Text: text})
 	}
 
 	return text, shouldFallback
 }
 
+// NarrateSidechainSummary narrates what a Task subagent did with optional voice
+func (vn *VoiceNarrator) NarrateSidechainSummary(subagentType string, toolUses int, elapsed time.Duration) (string, bool) {
+	text, shouldFallback := vn.narrator.NarrateSidechainSummary(subagentType, toolUses, elapsed)
+
+	if vn.enabled && text != "" {
+		vn.enqueueNarration(text, PriorityInput{Type: NarrationTypeNotification, Text: text})
+	}
+
+	return text, shouldFallback
+}
+
+// NarrateSessionSummary narrates what a session did when it ended with optional voice
+func (vn *VoiceNarrator) NarrateSessionSummary(summary SessionSummary) (string, bool) {
+	text, shouldFallback := vn.narrator.NarrateSessionSummary(summary)
+
+	if vn.enabled && text != "" {
+		vn.enqueueNarration(text, PriorityInput{Type: NarrationTypeNotification, Text: text})
+	}
+
+	return text, shouldFallback
+}
+
+// NarrateBudgetAlert narrates usage that crossed a threshold of its budget with optional voice
+func (vn *VoiceNarrator) NarrateBudgetAlert(alert BudgetAlert) (string, bool) {
+	text, shouldFallback := vn.narrator.NarrateBudgetAlert(alert)
+
+	if vn.enabled && text != "" {
+		vn.enqueueNarration(text, PriorityInput{Type: NarrationTypeError, Text: text})
+	}
+
+	return text, shouldFallback
+}
+
+// NarrateSecurityAlert narrates a risky tool use with optional voice, as an urgent
+// narration in the voice style of security narrations
+func (vn *VoiceNarrator) NarrateSecurityAlert(alert SecurityAlert) (string, bool) {
+	text, shouldFallback := vn.narrator.NarrateSecurityAlert(alert)
+
+	if vn.enabled && text != "" {
+		vn.enqueueNarration(text, PriorityInput{Type: NarrationTypeSecurity, ToolName: alert.ToolName, Text: text, Target: alert.Target})
+	}
+
+	return text, shouldFallback
+}
+
+// NarrateContextAlert narrates a context that crossed a threshold of its window with optional voice
+func (vn *VoiceNarrator) NarrateContextAlert(threshold, percent int) (string, bool) {
+	text, shouldFallback := vn.narrator.NarrateContextAlert(threshold, percent)
+
+	if vn.enabled && text != "" {
+		vn.enqueueNarration(text, PriorityInput{Type: NarrationTypeNotification, Text: text})
+	}
+
+	return text, shouldFallback
+}
+
+// Unvoiced returns a narrator that produces the same narrations as n without
+// speaking them
+func Unvoiced(n Narrator) Narrator {
+	if vn, ok := n.(*VoiceNarrator); ok {
+		return vn.narrator
+	}
+	return n
+}
+
+// BeginToolBatch holds back the tool use narrations that follow, to be spoken as one
+// by EndToolBatch
+func (vn *VoiceNarrator) BeginToolBatch() {
+	vn.batchMu.Lock()
+	defer vn.batchMu.Unlock()
+	vn.batching = true
+	vn.batch = nil
+}
+
+// EndToolBatch speaks the tool uses held back since BeginToolBatch as one narration,
+// e.g. "3つのファイルを読み込み、テストを実行します", with the priority of the most
+// important of them
+func (vn *VoiceNarrator) EndToolBatch() {
+	vn.batchMu.Lock()
+	batch := vn.batch
+	vn.batching, vn.batch = false, nil
+	vn.batchMu.Unlock()
+
+	switch len(batch) {
+	case 0:
+		return
+	case 1:
+		vn.enqueueNarration(batch[0].text, batch[0].in)
+		return
+	}
+	text := summarizeToolBatch(vn.normalizer.lang, batch)
+	in, best := batch[0].in, vn.scorePriority(batch[0].in)
+	for _, use := range batch[1:] {
+		if p := vn.scorePriority(use.in); p > best {
+			in, best = use.in, p
+		}
+	}
+	in.Target, in.Text = "", text
+	vn.enqueueNarration(text, in)
+}
+
+// holdForBatch keeps a tool use narration for the current batch; it returns false
+// when no batch is open
+func (vn *VoiceNarrator) holdForBatch(text string, in PriorityInput) bool {
+	vn.batchMu.Lock()
+	defer vn.batchMu.Unlock()
+	if !vn.batching {
+		return false
+	}
+	vn.batch = append(vn.batch, batchedToolUse{text: text, in: in})
+	return true
+}
+
+// Announce speaks a message that does not come from the wrapped narrator
+func (vn *VoiceNarrator) Announce(text string) {
+	if vn.enabled && text != "" {
+		vn.enqueue(text, PriorityInput{Type: NarrationTypeNotification, Text: text})
+	}
+}
+
+// SetSessionFocus speaks only the narrations of the session in focus, announcing when
+// the focus moves if announce is set. Other sessions are only shown on the console.
+func (vn *VoiceNarrator) SetSessionFocus(focus *SessionFocus, announce bool) {
+	vn.focus, vn.announce = focus, announce
+}
+
+// UserInteraction moves the focus to the session the user sent a prompt to
+func (vn *VoiceNarrator) UserInteraction(project, session, cwd string) {
+	if vn.focus == nil {
+		return
+	}
+	if label, switched := vn.focus.Interact(project, session, cwd); switched {
+		vn.announceFocus(label)
+	}
+}
+
+// announceFocus announces that the focus moved to a session
+func (vn *VoiceNarrator) announceFocus(label string) {
+	logger.LogDebug("Voice focus moved to %s", label)
+	if vn.announce {
+		vn.Announce(focusAnnouncement(vn.normalizer.lang, label))
+	}
+}
+
 // voiceWorker processes voice queue
 func (vn *VoiceNarrator) voiceWorker() {
 	defer vn.wg.Done()
@@ -146,20 +441,37 @@ func (vn *VoiceNarrator) voiceWorker() {
 
 		// Check if this item should be skipped
 		if vn.queue.ShouldSkip(*item) {
+			logger.LogDebug("Skipping narration for a more important one: %s", item.OriginalText)
+			vn.metrics.IncrementSkipped()
+			vn.prefetcher.discard(item.ID)
+			continue
+		}
+
+		// Stay silent during quiet hours; the console and the server still show the narration
+		if vn.quietHours.Load().Active() {
+			logger.LogDebug("Skipping narration during quiet hours: %s", item.OriginalText)
 			vn.metrics.IncrementSkipped()
+			vn.prefetcher.discard(item.ID)
 			continue
 		}
 
-		// Create timeout context for each TTS operation
-		ctx, cancel := context.WithTimeout(vn.ctx, 15*time.Second)
+		// The narrations queued before the engine went down are dropped too
+		if vn.health.Down() {
+			vn.metrics.IncrementSkipped()
+			vn.prefetcher.discard(item.ID)
+			continue
+		}
 
-		// Try to synthesize
-		audioData, err := vn.synthesizer.Synthesize(ctx, item.Text)
-		cancel()
+		// Synthesize the next narrations while this one is synthesized and played
+		vn.prefetchAhead()
+		audioData, err := vn.synthesizeItem(*item)
 
 		if err != nil {
 			vn.metrics.IncrementErrors()
-			logger.LogError("Failed to synthesize speech: %v", err)
+			// An engine that went down is reported once instead of on every narration
+			if !vn.health.checkFailure(vn.ctx, &vn.wg) {
+				logger.LogError("Failed to synthesize speech: %v", err)
+			}
 			continue
 		}
 
@@ -169,24 +481,101 @@ func (vn *VoiceNarrator) voiceWorker() {
 			NormalizedText: item.Text,
 		}
 
-		// Parse audio duration
-		if duration, err := speech.ParseWAVDuration(audioData); err == nil {
-			meta.Duration = duration
+		// Parse audio format and duration
+		if format, err := speech.ParseWAV(audioData); err == nil {
+			meta.Duration = format.Duration
+			meta.Format = format
 		} else {
 			// Log error but continue processing
-			logger.LogWarning("Failed to parse WAV duration: %v", err)
+			logger.LogWarning("Failed to parse WAV: %v", err)
 		}
 
 		// Play audio with metadata
+		started := time.Now()
 		if err := vn.player.Play(audioData, meta); err != nil {
 			vn.metrics.IncrementErrors()
 			logger.LogError("Failed to play audio: %v", err)
 		} else {
 			vn.metrics.IncrementPlayed()
+			vn.logSpoken(*item, meta, started)
 		}
 	}
 }
 
+// logSpoken records a narration that was played in the narration log
+func (vn *VoiceNarrator) logSpoken(item NarrationItem, meta *speech.AudioMeta, started time.Time) {
+	if vn.log == nil {
+		return
+	}
+	err := vn.log.Append(NarrationLogEntry{
+		Time:           started,
+		Project:        item.Project,
+		Session:        item.Session,
+		Text:           item.OriginalText,
+		NormalizedText: item.Text,
+		Duration:       meta.Duration.Seconds(),
+		Speaker:        item.SpeakerID,
+	})
+	if err != nil {
+		logger.LogError("%v", err)
+	}
+}
+
+// synthesizeItem returns the audio of an item taken off the queue: the audio synthesized
+// ahead of time if any, or the audio synthesized now
+func (vn *VoiceNarrator) synthesizeItem(item NarrationItem) ([]byte, error) {
+	if pa, ok := vn.prefetcher.take(item); ok {
+		return pa.wait()
+	}
+	ctx, cancel := context.WithTimeout(vn.ctx, synthesisTimeout)
+	defer cancel()
+	return vn.synthesize(ctx, item)
+}
+
+// SetLookahead synthesizes up to n queued narrations in parallel while the current one
+// plays, so narrations follow each other without gaps; 0 synthesizes each one right
+// before it plays. It must be called before narrating.
+func (vn *VoiceNarrator) SetLookahead(n int) {
+	vn.prefetcher = nil
+	if n > 0 {
+		vn.prefetcher = newSynthPrefetcher(vn.ctx, vn.queue, n, vn.synthesize)
+	}
+}
+
+// prefetchAhead starts synthesizing the narrations at the head of the queue
+func (vn *VoiceNarrator) prefetchAhead() {
+	// Narrations during quiet hours are dropped, so there is nothing to synthesize
+	if !vn.enabled || vn.quietHours.Load().Active() || vn.health.Down() {
+		return
+	}
+	vn.prefetcher.prefetch()
+}
+
+// SetVoiceStyle speaks narrations of a type with voice parameters, such as a slower and
+// quieter voice for thinking, when the synthesizer supports it. It must be called before narrating.
+func (vn *VoiceNarrator) SetVoiceStyle(t NarrationType, params map[string]float64) {
+	if vn.styles == nil {
+		vn.styles = make(map[NarrationType]map[string]float64)
+	}
+	vn.styles[t] = params
+}
+
+// synthesize converts an item to audio, using its speaker override and the voice style
+// of its type when the synthesizer supports them
+func (vn *VoiceNarrator) synthesize(ctx context.Context, item NarrationItem) ([]byte, error) {
+	if params := vn.styles[item.Type]; len(params) > 0 {
+		if ps, ok := vn.synthesizer.(speech.ParameterSynthesizer); ok {
+			return ps.SynthesizeWithParameters(ctx, item.Text, item.SpeakerID, params)
+		}
+	}
+	if item.SpeakerID != nil {
+		if ss, ok := vn.synthesizer.(speech.SpeakerSynthesizer); ok {
+			return ss.SynthesizeWithSpeaker(ctx, item.Text, *item.SpeakerID)
+		}
+	}
+	return vn.synthesizer.Synthesize(ctx, item.Text)
+}
+
 // Close stops the voice worker
 func (vn *VoiceNarrator) Close() {
 	vn.cancel()
@@ -194,35 +583,194 @@ func (vn *VoiceNarrator) Close() {
 	vn.wg.Wait()
 }
 
-// enqueueNarration processes and enqueues a narration item
-func (vn *VoiceNarrator) enqueueNarration(text string, narType NarrationType) {
-	// Translate English to Japanese if needed
-	ctx, cancel := context.WithTimeout(vn.ctx, 5*time.Second)
-	translatedText, _ := vn.translator.Translate(ctx, text)
-	cancel()
+// enqueueNarration enqueues a narration of the session being narrated, unless another
+// session is in focus
+func (vn *VoiceNarrator) enqueueNarration(text string, in PriorityInput) {
+	if vn.focus != nil && !vn.muted.Load() {
+		vn.speakerMu.Lock()
+		project, session := vn.project, vn.session
+		vn.speakerMu.Unlock()
+		allowed, label, switched := vn.focus.Allow(project, session)
+		if !allowed {
+			logger.LogDebug("Not speaking a narration of %s/%s out of focus: %s", project, session, text)
+			vn.metrics.IncrementSkipped()
+			return
+		}
+		if switched {
+			vn.announceFocus(label)
+		}
+	}
+	vn.enqueue(text, in)
+}
 
-	// Normalize text for better TTS pronunciation
-	normalizedText := vn.normalizer.Normalize(translatedText)
+// enqueue processes and enqueues a narration item with the priority scored for in
+func (vn *VoiceNarrator) enqueue(text string, in PriorityInput) {
+	if vn.muted.Load() {
+		return
+	}
+	if vn.health.Down() {
+		logger.LogDebug("Not speaking a narration while the speech engine is down: %s", text)
+		vn.metrics.IncrementSkipped()
+		return
+	}
+	vn.speakerMu.Lock()
+	speakerID, overrides := vn.speakerID, vn.overrides
+	project, session := vn.project, vn.session
+	if vn.speaker != nil {
+		speakerID = vn.speaker
+	}
+	vn.speakerMu.Unlock()
+	if overrides != nil {
+		if overrides.Mute {
+			return
+		}
+		if overrides.Speaker != nil {
+			speakerID = overrides.Speaker
+		}
+	}
+
+	isTool := in.Type == NarrationTypeToolUse || in.Type == NarrationTypeToolUseMCP
+	var key string
+	if vn.deduper != nil {
+		if !isTool {
+			vn.deduper.reset()
+		} else {
+			key = dedupKey(in)
+			decision, first, id, count := vn.deduper.check(key)
+			switch decision {
+			case dedupCollapse:
+				translatedText, normalizedText := vn.prepareText(collapseRepeated(vn.normalizer.lang, first, count))
+				// A repeat of a narration already spoken is not spoken again
+				if !vn.queue.Update(id, func(item *NarrationItem) {
+					item.Text = normalizedText
+					item.OriginalText = translatedText
+				}) {
+					logger.LogDebug("Dropping repeat of a spoken narration: %s", text)
+					vn.metrics.IncrementSkipped()
+				} else {
+					vn.prefetchAhead()
+				}
+				return
+			case dedupDrop:
+				logger.LogDebug("Dropping tool narration over the rate limit: %s", text)
+				vn.metrics.IncrementSkipped()
+				return
+			}
+		}
+	}
 
+	translatedText, normalizedText := vn.prepareText(text)
 	item := NarrationItem{
 		Text:         normalizedText,
 		OriginalText: translatedText, // Use translated text as original
-		Type:         narType,
-		Priority:     priorityMap[narType],
+		Type:         in.Type,
+		Priority:     vn.scorePriority(in),
 		Timestamp:    time.Now(),
 		ID:           uuid.New().String(),
+		SpeakerID:    speakerID,
+		Project:      project,
+		Session:      session,
 	}
 
 	if vn.queue.Enqueue(item) {
 		vn.metrics.IncrementQueued()
+		if isTool && vn.deduper != nil {
+			vn.deduper.record(key, text, item.ID)
+		}
+		vn.prefetchAhead()
 	}
 }
 
+// prepareText translates a narration if needed and normalizes it for speech
+func (vn *VoiceNarrator) prepareText(text string) (translated, normalized string) {
+	// Translate English to Japanese if needed
+	ctx, cancel := context.WithTimeout(vn.ctx, 5*time.Second)
+	translated, _ = vn.translator.Translate(ctx, text)
+	cancel()
+
+	// Normalize text for better TTS pronunciation
+	return translated, vn.normalizer.Normalize(translated)
+}
+
+// scorePriority scores a narration, falling back to the type's default priority without a scorer
+func (vn *VoiceNarrator) scorePriority(in PriorityInput) int {
+	if vn.scorer == nil {
+		return priorityMap[in.Type]
+	}
+	return vn.scorer.ScorePriority(in)
+}
+
 // isMCPTool checks if a tool name is an MCP tool
 func isMCPTool(toolName string) bool {
 	return strings.HasPrefix(toolName, "mcp__")
 }
 
+// SetSummarizer shortens text narrations that would take too long to speak
+func (vn *VoiceNarrator) SetSummarizer(s *VoiceSummarizer) {
+	vn.summarizer = s
+}
+
+// SetDeduper collapses repeated tool narrations and limits how many are spoken
+func (vn *VoiceNarrator) SetDeduper(d *NarrationDeduper) {
+	vn.deduper = d
+}
+
+// SetNarrationLog records every narration that is spoken in log
+func (vn *VoiceNarrator) SetNarrationLog(log *NarrationLog) {
+	vn.log = log
+}
+
+// SetLanguage reads dates, times, versions and percentages of untranslated narrations in lang
+func (vn *VoiceNarrator) SetLanguage(lang Language) {
+	vn.normalizer.lang = lang
+}
+
+// SetQuietHours drops narrations instead of speaking them during quiet hours
+func (vn *VoiceNarrator) SetQuietHours(q *notify.QuietHours) {
+	vn.quietHours.Store(q)
+}
+
+// SetKatakana sets whether English words left in narrations are read as katakana
+func (vn *VoiceNarrator) SetKatakana(enabled bool) {
+	vn.normalizer.SetKatakana(enabled)
+}
+
+// SetUserDictionary reads spoken narrations with the entries of a user dictionary
+func (vn *VoiceNarrator) SetUserDictionary(d *UserDictionary) error {
+	return vn.normalizer.SetUserDictionary(d)
+}
+
+// SetTranslationProvider translates English narrations with provider instead of OpenAI
+func (vn *VoiceNarrator) SetTranslationProvider(provider Provider) {
+	vn.translator.SetProvider(provider)
+}
+
+// SetTranslationBackend translates English narrations with backend, such as DeepL or a local model
+func (vn *VoiceNarrator) SetTranslationBackend(backend TranslationBackend) {
+	vn.translator.SetBackend(backend)
+}
+
+// SetTranslationCache keeps AI translations in cache, which can be persisted across restarts
+func (vn *VoiceNarrator) SetTranslationCache(cache *TranslationCache) {
+	vn.translator.SetCache(cache)
+}
+
+// SetGlossary sets the Japanese readings of English terms used when translating narrations
+func (vn *VoiceNarrator) SetGlossary(glossary *Glossary) {
+	vn.translator.SetGlossary(glossary)
+}
+
+// Enabled reports whether narrations are spoken, which they are not while muted or
+// while the speech engine is down
+func (vn *VoiceNarrator) Enabled() bool {
+	return vn.enabled && !vn.muted.Load() && !vn.health.Down()
+}
+
+// QueueSize returns the number of narrations waiting to be spoken
+func (vn *VoiceNarrator) QueueSize() int {
+	return vn.queue.Size()
+}
+
 // GetMetrics returns current performance metrics
 func (vn *VoiceNarrator) GetMetrics() map[string]interface{} {
 	stats := vn.metrics.GetStats()
@@ -0,0 +1,137 @@
+package narrator
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// Approximate speaking rates of VOICEVOX at the default speed
+const (
+	japaneseCharsPerSecond = 7.0  // Kana and kanji
+	asciiCharsPerSecond    = 14.0 // Letters and digits, read as words
+)
+
+// summarySystemPrompt instructs the model to shorten text for speech
+const summarySystemPrompt = `You shorten text for text-to-speech.
+Rules:
+1. Summarize the text in natural Japanese within the given number of characters
+2. Keep the conclusion and anything the user needs to act on
+3. Do not read out code, file paths or URLs
+4. Return ONLY the summary, no explanations`
+
+// EstimateSpeechDuration estimates how long text takes to speak
+func EstimateSpeechDuration(text string) time.Duration {
+	var seconds float64
+	for _, r := range text {
+		switch {
+		case unicode.IsSpace(r) || unicode.IsPunct(r):
+			// Pauses are short enough to ignore
+		case r < unicode.MaxASCII:
+			if unicode.IsLetter(r) || unicode.IsDigit(r) {
+				seconds += 1 / asciiCharsPerSecond
+			}
+		default:
+			seconds += 1 / japaneseCharsPerSecond
+		}
+	}
+	return time.Duration(seconds * float64(time.Second))
+}
+
+// VoiceSummarizer shortens narrations that would take longer than a target to speak.
+// It asks an AI provider for a summary if one is set and otherwise, or if the summary
+// is still too long, keeps the leading sentences that fit.
+type VoiceSummarizer struct {
+	maxDuration time.Duration
+	provider    Provider
+	timeout     time.Duration
+}
+
+// NewVoiceSummarizer creates a summarizer targeting maxDuration; provider may be nil
+func NewVoiceSummarizer(maxDuration time.Duration, provider Provider) *VoiceSummarizer {
+	return &VoiceSummarizer{
+		maxDuration: maxDuration,
+		provider:    provider,
+		timeout:     defaultProviderTimeout,
+	}
+}
+
+// MaxDuration returns the target duration of a narration
+func (s *VoiceSummarizer) MaxDuration() time.Duration {
+	return s.maxDuration
+}
+
+// Summarize returns text shortened to fit the target duration
+func (s *VoiceSummarizer) Summarize(ctx context.Context, text string) string {
+	if EstimateSpeechDuration(text) <= s.maxDuration {
+		return text
+	}
+
+	if s.provider != nil {
+		ctx, cancel := context.WithTimeout(ctx, s.timeout)
+		defer cancel()
+		maxChars := int(s.maxDuration.Seconds() * japaneseCharsPerSecond)
+		summary, err := s.provider.Complete(ctx, CompletionRequest{
+			System:      summarySystemPrompt,
+			Prompt:      fmt.Sprintf("Summarize within %d characters:\n\n%s", maxChars, text),
+			Temperature: 0.3,
+			MaxTokens:   maxChars * 2,
+		})
+		if err == nil && summary != "" {
+			text = summary
+		}
+	}
+
+	return TruncateForSpeech(text, s.maxDuration)
+}
+
+// TruncateForSpeech keeps the leading sentences of text that can be spoken within limit.
+// If even the first sentence is too long, it is cut off.
+func TruncateForSpeech(text string, limit time.Duration) string {
+	if EstimateSpeechDuration(text) <= limit {
+		return text
+	}
+
+	var kept string
+	full := false
+	splitter := newSentenceSplitter(func(sentence string) {
+		if full {
+			return
+		}
+		candidate := joinSentences(kept, sentence)
+		if EstimateSpeechDuration(candidate) > limit {
+			full = true
+			return
+		}
+		kept = candidate
+	})
+	splitter.Write(text)
+	splitter.Flush()
+	if kept != "" {
+		return kept
+	}
+
+	// The first sentence alone is too long; cut it where the budget runs out
+	var budget time.Duration
+	var cut strings.Builder
+	for _, r := range strings.TrimSpace(text) {
+		if budget += EstimateSpeechDuration(string(r)); budget > limit {
+			break
+		}
+		cut.WriteRune(r)
+	}
+	return cut.String()
+}
+
+// joinSentences appends a sentence, separating sentences that end in ASCII with a space
+func joinSentences(text, sentence string) string {
+	if text == "" {
+		return sentence
+	}
+	if last := text[len(text)-1]; last < unicode.MaxASCII {
+		return text + " " + sentence
+	}
+	return text + sentence
+}
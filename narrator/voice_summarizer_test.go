@@ -0,0 +1,113 @@
+package narrator
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestEstimateSpeechDuration(t *testing.T) {
+	tests := []struct {
+		text string
+		want time.Duration
+	}{
+		{text: "", want: 0},
+		{text: "テストを実行します。", want: 9 * time.Second / 7}, // Punctuation is free
+		{text: "run the tests", want: 11 * time.Second / 14},
+	}
+	for _, tt := range tests {
+		got := EstimateSpeechDuration(tt.text)
+		if diff := got - tt.want; diff < -time.Millisecond || diff > time.Millisecond {
+			t.Errorf("EstimateSpeechDuration(%q) = %v, want %v", tt.text, got, tt.want)
+		}
+	}
+}
+
+func TestTruncateForSpeech(t *testing.T) {
+	tests := []struct {
+		name  string
+		text  string
+		limit time.Duration
+		want  string
+	}{
+		{name: "fits", text: "テストを実行します。", limit: 2 * time.Second, want: "テストを実行します。"},
+		{name: "keeps leading sentences", text: "テストを実行します。ビルドは成功しました。警告が二つあります。", limit: 3 * time.Second, want: "テストを実行します。ビルドは成功しました。"},
+		{name: "english sentences", text: "Tests pass. The build is green. Two warnings remain.", limit: 2 * time.Second, want: "Tests pass. The build is green."},
+		{name: "cuts a long first sentence", text: "とても長い一文がここから始まって終わりません", limit: 1100 * time.Millisecond, want: "とても長い一文"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := TruncateForSpeech(tt.text, tt.limit); got != tt.want {
+				t.Errorf("TruncateForSpeech() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestVoiceSummarizer_Summarize(t *testing.T) {
+	long := strings.Repeat("長い説明が続きます。", 10) // 9 characters each; 3s fits 21
+
+	tests := []struct {
+		name     string
+		provider *fakeProvider
+		want     string
+	}{
+		{name: "rule-based", want: "長い説明が続きます。長い説明が続きます。"},
+		{name: "AI summary", provider: &fakeProvider{result: "要点だけを話します。"}, want: "要点だけを話します。"},
+		{name: "AI summary still too long", provider: &fakeProvider{result: long}, want: "長い説明が続きます。長い説明が続きます。"},
+		{name: "AI failure falls back", provider: &fakeProvider{err: errors.New("unavailable")}, want: "長い説明が続きます。長い説明が続きます。"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var provider Provider
+			if tt.provider != nil {
+				provider = tt.provider
+			}
+			s := NewVoiceSummarizer(3*time.Second, provider)
+			if got := s.Summarize(context.Background(), long); got != tt.want {
+				t.Errorf("Summarize() = %q, want %q", got, tt.want)
+			}
+			if got := s.Summarize(context.Background(), "短い文です。"); got != "短い文です。" {
+				t.Errorf("Summarize() of a short text = %q", got)
+			}
+		})
+	}
+}
+
+// sentenceStreamer streams fixed sentences as its narration
+type sentenceStreamer struct {
+	NoOpNarrator
+	sentences []string
+}
+
+func (s *sentenceStreamer) NarrateTextStream(text string, isThinking bool, onSentence func(string)) (string, bool) {
+	for _, sentence := range s.sentences {
+		onSentence(sentence)
+	}
+	return strings.Join(s.sentences, ""), false
+}
+
+func TestVoiceNarrator_SummarizesSpokenText(t *testing.T) {
+	streamer := &sentenceStreamer{sentences: []string{"テストを実行します。", "ビルドは成功しました。", "警告が二つあります。"}}
+	vn := NewVoiceNarrator(streamer, &speakerRecorder{}, nil, false)
+	defer vn.Close()
+	vn.enabled = true // Queue narrations without starting the voice worker
+	vn.SetSummarizer(NewVoiceSummarizer(3*time.Second, nil))
+
+	narration, _ := vn.NarrateText("long text", false)
+	if narration != "テストを実行します。ビルドは成功しました。警告が二つあります。" {
+		t.Errorf("NarrateText() = %q, want the full narration for the console", narration)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	var spoken []string
+	for vn.queue.Size() > 0 {
+		spoken = append(spoken, vn.queue.Dequeue(ctx).OriginalText)
+	}
+	if got := strings.Join(spoken, ""); got != "テストを実行します。ビルドは成功しました。" {
+		t.Errorf("spoken = %q, want the sentences within 3s", got)
+	}
+}
@@ -0,0 +1,104 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/kazegusuri/claude-companion/logger"
+	"github.com/kazegusuri/claude-companion/narrator"
+	"github.com/spf13/pflag"
+)
+
+// runNormalizer dispatches the normalizer subcommands
+func runNormalizer(args []string) int {
+	if len(args) == 0 || args[0] != "test" {
+		fmt.Fprintln(os.Stderr, "Usage: claude-companion normalizer test [flags] CORPUS...")
+		return 2
+	}
+	return runNormalizerTest(args[1:])
+}
+
+// runNormalizerTest normalizes the texts of pronunciation corpora and reports those
+// read differently than expected, to validate user dictionary and normalizer changes
+func runNormalizerTest(args []string) int {
+	fs := pflag.NewFlagSet("normalizer test", pflag.ContinueOnError)
+	var langCode string
+	var userDictionaryPath string
+	var opts narrator.NormalizerOptions
+	var update bool
+	fs.StringVar(&langCode, "lang", "ja", "Language numbers of English texts are read in: ja or en")
+	fs.StringVar(&userDictionaryPath, "user-dictionary", "", "Read the texts with the readings of this dictionary as --user-dictionary does")
+	fs.BoolVar(&opts.Katakana, "katakana", false, "Read English words as katakana as --voice-katakana does")
+	fs.BoolVar(&opts.SkipURLs, "skip-urls", false, "Drop URLs instead of reading their domain")
+	fs.BoolVar(&opts.RawNumbers, "raw-numbers", false, "Leave dates, times, versions, percentages and long numbers as written")
+	fs.BoolVar(&update, "update", false, "Rewrite the expected normalizations with the current ones")
+	fs.Usage = func() {
+		fmt.Fprintln(os.Stderr, "Usage: claude-companion normalizer test [flags] CORPUS...")
+		fmt.Fprintln(os.Stderr, "Each line of a corpus is a text and its expected normalization separated by a tab.")
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args); err != nil {
+		if err == pflag.ErrHelp {
+			return 0
+		}
+		return 2
+	}
+	if fs.NArg() == 0 {
+		fs.Usage()
+		return 2
+	}
+	lang, err := narrator.ParseLanguage(langCode)
+	if err != nil {
+		logger.LogError("%v", err)
+		return 2
+	}
+
+	normalizer := narrator.NewTextNormalizerWithLanguage(lang)
+	normalizer.SetOptions(opts)
+	if userDictionaryPath != "" {
+		dict, err := narrator.LoadUserDictionary(userDictionaryPath)
+		if err == nil {
+			err = normalizer.SetUserDictionary(dict)
+		}
+		if err != nil {
+			logger.LogError("%v", err)
+			return 2
+		}
+	}
+
+	total, failed := 0, 0
+	for _, path := range fs.Args() {
+		cases, err := narrator.LoadNormalizerCorpus(path)
+		if err != nil {
+			logger.LogError("%v", err)
+			return 1
+		}
+		results := narrator.RunNormalizerCorpus(normalizer, cases)
+		for _, r := range results {
+			total++
+			if r.Passed() {
+				continue
+			}
+			failed++
+			fmt.Printf("%s:%d: %s\n", path, r.Line, r.Text)
+			fmt.Printf("  - want: %s\n", r.Want)
+			fmt.Printf("  + got:  %s\n", r.Got)
+		}
+		if update {
+			if err := narrator.UpdateNormalizerCorpus(path, results); err != nil {
+				logger.LogError("Failed to update %s: %v", path, err)
+				return 1
+			}
+		}
+	}
+
+	if update {
+		fmt.Printf("%d cases, %d updated\n", total, failed)
+		return 0
+	}
+	fmt.Printf("%d cases, %d failed\n", total, failed)
+	if failed > 0 {
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,86 @@
+package notify
+
+import (
+	"fmt"
+	"os/exec"
+	"runtime"
+	"strings"
+)
+
+// Notifier sends notifications to the user
+type Notifier interface {
+	// Notify shows a notification with the given title and body
+	Notify(title, body string) error
+}
+
+// DesktopNotifier implements Notifier using the desktop notification command of each platform
+type DesktopNotifier struct{}
+
+// NewDesktopNotifier creates a new desktop notifier
+func NewDesktopNotifier() *DesktopNotifier {
+	return &DesktopNotifier{}
+}
+
+// Notify shows a desktop notification without waiting for it to be dismissed
+func (n *DesktopNotifier) Notify(title, body string) error {
+	name, args, err := command(runtime.GOOS, title, body)
+	if err != nil {
+		return err
+	}
+
+	cmd := exec.Command(name, args...)
+	if err := cmd.Start(); err != nil {
+		return fmt.Errorf("failed to run %s: %w", name, err)
+	}
+	go cmd.Wait()
+	return nil
+}
+
+// Available checks if the notification command of this platform is installed
+func (n *DesktopNotifier) Available() error {
+	name, _, err := command(runtime.GOOS, "", "")
+	if err != nil {
+		return err
+	}
+	if _, err := exec.LookPath(name); err != nil {
+		return fmt.Errorf("%s not found", name)
+	}
+	return nil
+}
+
+// command returns the command that shows a notification on the given platform
+func command(goos, title, body string) (string, []string, error) {
+	switch goos {
+	case "linux":
+		return "notify-send", []string{"--app-name=claude-companion", "--", title, body}, nil
+	case "darwin":
+		script := fmt.Sprintf("display notification %s with title %s", appleScriptString(body), appleScriptString(title))
+		return "osascript", []string{"-e", script}, nil
+	case "windows":
+		script := fmt.Sprintf(windowsToastScript, powerShellString(title), powerShellString(body))
+		return "powershell", []string{"-NoProfile", "-NonInteractive", "-Command", script}, nil
+	default:
+		return "", nil, fmt.Errorf("unsupported OS: %s", goos)
+	}
+}
+
+// windowsToastScript shows a toast notification through the Windows Runtime API
+const windowsToastScript = `[Windows.UI.Notifications.ToastNotificationManager, Windows.UI.Notifications, ContentType = WindowsRuntime] | Out-Null
+$template = [Windows.UI.Notifications.ToastNotificationManager]::GetTemplateContent([Windows.UI.Notifications.ToastTemplateType]::ToastText02)
+$text = $template.GetElementsByTagName('text')
+$text.Item(0).AppendChild($template.CreateTextNode(%s)) | Out-Null
+$text.Item(1).AppendChild($template.CreateTextNode(%s)) | Out-Null
+$toast = [Windows.UI.Notifications.ToastNotification]::new($template)
+[Windows.UI.Notifications.ToastNotificationManager]::CreateToastNotifier('claude-companion').Show($toast)`
+
+// appleScriptString quotes a string as an AppleScript string literal
+func appleScriptString(s string) string {
+	s = strings.ReplaceAll(s, `\`, `\\`)
+	s = strings.ReplaceAll(s, `"`, `\"`)
+	return `"` + s + `"`
+}
+
+// powerShellString quotes a string as a PowerShell single-quoted string literal
+func powerShellString(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", "''") + "'"
+}
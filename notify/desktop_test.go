@@ -0,0 +1,55 @@
+package notify
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestCommand(t *testing.T) {
+	tests := []struct {
+		goos     string
+		wantName string
+		wantArgs []string
+		contains string
+		wantErr  bool
+	}{
+		{
+			goos:     "linux",
+			wantName: "notify-send",
+			wantArgs: []string{"--app-name=claude-companion", "--", `Say "hi"`, `it's done`},
+		},
+		{
+			goos:     "darwin",
+			wantName: "osascript",
+			contains: `display notification "it's done" with title "Say \"hi\""`,
+		},
+		{
+			goos:     "windows",
+			wantName: "powershell",
+			contains: `CreateTextNode('it''s done')`,
+		},
+		{goos: "plan9", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.goos, func(t *testing.T) {
+			name, args, err := command(tt.goos, `Say "hi"`, `it's done`)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("command() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if name != tt.wantName {
+				t.Errorf("name = %q, want %q", name, tt.wantName)
+			}
+			if tt.wantArgs != nil && !reflect.DeepEqual(args, tt.wantArgs) {
+				t.Errorf("args = %q, want %q", args, tt.wantArgs)
+			}
+			if tt.contains != "" && !strings.Contains(args[len(args)-1], tt.contains) {
+				t.Errorf("script %q does not contain %q", args[len(args)-1], tt.contains)
+			}
+		})
+	}
+}
@@ -0,0 +1,116 @@
+package notify
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// quietWindow is a daily time window in minutes since midnight. A window whose end
+// is before its start runs past midnight.
+type quietWindow struct {
+	start, end int
+}
+
+// contains reports whether the minute of the day falls in the window
+func (w quietWindow) contains(minute int) bool {
+	if w.start < w.end {
+		return minute >= w.start && minute < w.end
+	}
+	return minute >= w.start || minute < w.end
+}
+
+// String formats the window as HH:MM-HH:MM
+func (w quietWindow) String() string {
+	return fmt.Sprintf("%02d:%02d-%02d:%02d", w.start/60, w.start%60, w.end/60, w.end%60)
+}
+
+// QuietHours is a set of daily time windows in which audio and desktop notifications
+// are muted. A nil QuietHours is never active.
+type QuietHours struct {
+	windows []quietWindow
+	now     func() time.Time
+}
+
+// ParseQuietHours parses HH:MM-HH:MM windows in local time, such as 22:00-08:00
+func ParseQuietHours(values []string) (*QuietHours, error) {
+	if len(values) == 0 {
+		return nil, nil
+	}
+	q := &QuietHours{now: time.Now}
+	for _, value := range values {
+		from, to, ok := strings.Cut(value, "-")
+		start, startErr := parseClock(from)
+		end, endErr := parseClock(to)
+		if !ok || startErr != nil || endErr != nil {
+			return nil, fmt.Errorf("invalid quiet hours %q: must be HH:MM-HH:MM", value)
+		}
+		if start == end {
+			return nil, fmt.Errorf("invalid quiet hours %q: start and end must differ", value)
+		}
+		q.windows = append(q.windows, quietWindow{start: start, end: end})
+	}
+	return q, nil
+}
+
+// parseClock parses HH:MM into minutes since midnight
+func parseClock(s string) (int, error) {
+	t, err := time.Parse("15:04", strings.TrimSpace(s))
+	if err != nil {
+		return 0, err
+	}
+	return t.Hour()*60 + t.Minute(), nil
+}
+
+// Active reports whether it is quiet hours now
+func (q *QuietHours) Active() bool {
+	if q == nil {
+		return false
+	}
+	return q.ActiveAt(q.now())
+}
+
+// ActiveAt reports whether t falls in quiet hours
+func (q *QuietHours) ActiveAt(t time.Time) bool {
+	if q == nil {
+		return false
+	}
+	minute := t.Hour()*60 + t.Minute()
+	for _, w := range q.windows {
+		if w.contains(minute) {
+			return true
+		}
+	}
+	return false
+}
+
+// String lists the windows, such as 22:00-08:00, 12:00-13:00
+func (q *QuietHours) String() string {
+	if q == nil {
+		return ""
+	}
+	windows := make([]string, len(q.windows))
+	for i, w := range q.windows {
+		windows[i] = w.String()
+	}
+	return strings.Join(windows, ", ")
+}
+
+// QuietNotifier wraps a Notifier and drops notifications during quiet hours
+type QuietNotifier struct {
+	notifier Notifier
+	quiet    *QuietHours
+}
+
+// NewQuietNotifier creates a notifier that is muted during quiet hours
+func NewQuietNotifier(notifier Notifier, quiet *QuietHours) *QuietNotifier {
+	return &QuietNotifier{notifier: notifier, quiet: quiet}
+}
+
+// Notify shows the notification unless it is quiet hours
+func (n *QuietNotifier) Notify(title, body string) error {
+	if n.quiet.Active() {
+		return nil
+	}
+	return n.notifier.Notify(title, body)
+}
@@ -0,0 +1,88 @@
+package notify
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseQuietHours(t *testing.T) {
+	tests := []struct {
+		name    string
+		values  []string
+		want    string
+		wantErr bool
+	}{
+		{name: "none"},
+		{name: "overnight", values: []string{"22:00-08:00"}, want: "22:00-08:00"},
+		{name: "several", values: []string{"22:00-8:00", "12:00-13:30"}, want: "22:00-08:00, 12:00-13:30"},
+		{name: "missing end", values: []string{"22:00"}, wantErr: true},
+		{name: "invalid time", values: []string{"22:00-25:00"}, wantErr: true},
+		{name: "empty window", values: []string{"08:00-08:00"}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			q, err := ParseQuietHours(tt.values)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseQuietHours() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got := q.String(); got != tt.want {
+				t.Errorf("String() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestQuietHours_ActiveAt(t *testing.T) {
+	q, err := ParseQuietHours([]string{"22:00-08:00", "12:00-13:00"})
+	if err != nil {
+		t.Fatalf("ParseQuietHours() error = %v", err)
+	}
+	tests := []struct {
+		clock string
+		want  bool
+	}{
+		{"21:59", false},
+		{"22:00", true},
+		{"03:00", true},
+		{"07:59", true},
+		{"08:00", false},
+		{"12:30", true},
+		{"13:00", false},
+	}
+	for _, tt := range tests {
+		at, _ := time.Parse("15:04", tt.clock)
+		if got := q.ActiveAt(at); got != tt.want {
+			t.Errorf("ActiveAt(%s) = %v, want %v", tt.clock, got, tt.want)
+		}
+	}
+
+	var none *QuietHours
+	if none.Active() {
+		t.Errorf("nil QuietHours is active")
+	}
+}
+
+type recordingNotifier struct {
+	titles []string
+}
+
+func (n *recordingNotifier) Notify(title, body string) error {
+	n.titles = append(n.titles, title)
+	return nil
+}
+
+func TestQuietNotifier(t *testing.T) {
+	q, _ := ParseQuietHours([]string{"22:00-08:00"})
+	clock := time.Date(2025, 1, 26, 23, 0, 0, 0, time.Local)
+	q.now = func() time.Time { return clock }
+
+	inner := &recordingNotifier{}
+	n := NewQuietNotifier(inner, q)
+	n.Notify("night", "")
+	clock = clock.Add(10 * time.Hour)
+	n.Notify("morning", "")
+
+	if len(inner.titles) != 1 || inner.titles[0] != "morning" {
+		t.Errorf("notified %v, want only the notification outside quiet hours", inner.titles)
+	}
+}
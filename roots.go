@@ -0,0 +1,51 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+)
+
+// projectsRoot is a projects root directory and the label its events are tagged with
+type projectsRoot struct {
+	label string
+	path  string
+}
+
+// parseProjectsRoots parses --projects-root values of the form PATH or LABEL=PATH.
+// A root without a label is labeled with its path.
+func parseProjectsRoots(values []string) ([]projectsRoot, error) {
+	var roots []projectsRoot
+	seen := make(map[string]bool)
+	for _, value := range values {
+		value = strings.TrimSpace(value)
+		if value == "" {
+			continue
+		}
+
+		root := projectsRoot{label: value, path: value}
+		if label, path, ok := strings.Cut(value, "="); ok && label != "" && !strings.ContainsAny(label, `/\~`) {
+			root = projectsRoot{label: label, path: path}
+		}
+		if root.path == "" {
+			return nil, fmt.Errorf("invalid projects root %q: empty path", value)
+		}
+		if seen[root.label] {
+			return nil, fmt.Errorf("duplicate projects root %q", root.label)
+		}
+		seen[root.label] = true
+		roots = append(roots, root)
+	}
+	if len(roots) == 0 {
+		return nil, fmt.Errorf("no projects root given")
+	}
+	return roots, nil
+}
+
+// rootPaths returns the paths of the projects roots
+func rootPaths(roots []projectsRoot) []string {
+	paths := make([]string, len(roots))
+	for i, root := range roots {
+		paths[i] = root.path
+	}
+	return paths
+}
@@ -0,0 +1,106 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/kazegusuri/claude-companion/archive"
+	"github.com/kazegusuri/claude-companion/event"
+	"github.com/kazegusuri/claude-companion/logger"
+	"github.com/kazegusuri/claude-companion/narrator"
+	"github.com/kazegusuri/claude-companion/search"
+	"github.com/spf13/pflag"
+)
+
+// runSearch finds events in session transcripts by tool, text and time
+func runSearch(args []string) int {
+	fs := pflag.NewFlagSet("search", pflag.ContinueOnError)
+	var projectsRoots, tools []string
+	var project, session, file, text, since, archiveDir string
+	var narratorConfigPath, langCode string
+	var jsonOutput bool
+	fs.StringSliceVar(&projectsRoots, "projects-root", []string{"~/.claude/projects"}, "Root directory for projects (repeatable or comma-separated; LABEL=PATH is accepted)")
+	fs.StringVarP(&project, "project", "p", "", "Search projects whose name contains this")
+	fs.StringVarP(&session, "session", "s", "", "Session name")
+	fs.StringVarP(&file, "file", "f", "", "Direct path to a session file")
+	fs.StringSliceVar(&tools, "tool", nil, "Match uses and results of these tools (repeatable; globs like mcp__* are accepted)")
+	fs.StringVar(&text, "text", "", "Match events containing this text, ignoring case")
+	fs.StringVar(&since, "since", "", "Match events since a duration ago (e.g. 2d, 12h) or a date (YYYY-MM-DD)")
+	fs.StringVar(&archiveDir, "archive-dir", archive.DefaultDir, "Also search the sessions archived in this directory by gc (empty skips them)")
+	fs.BoolVar(&jsonOutput, "json", false, "Print one JSON object per match")
+	fs.StringVar(&narratorConfigPath, "narrator-config", "", "Path to narrator configuration file (JSON, or YAML for .yaml/.yml)")
+	fs.StringVar(&langCode, "lang", "ja", "Narration language: ja or en")
+	if err := fs.Parse(args); err != nil {
+		if err == pflag.ErrHelp {
+			return 0
+		}
+		return 2
+	}
+
+	lang, err := narrator.ParseLanguage(langCode)
+	if err != nil {
+		logger.LogError("%v", err)
+		return 2
+	}
+	query := search.Query{Tools: tools, Text: text}
+	if since != "" {
+		if query.Since, err = search.ParseSince(since, time.Now()); err != nil {
+			logger.LogError("%v", err)
+			return 2
+		}
+	}
+	// Narrate with rules only so results are reproducible and need no API key
+	n := narrator.NewHybridNarratorWithLanguage("", false, &narratorConfigPath, lang)
+	searcher, err := search.NewSearcher(query, event.NewFormatter(n))
+	if err != nil {
+		logger.LogError("%v", err)
+		return 2
+	}
+
+	paths, err := transcriptPaths(file, projectsRoots, "", session)
+	if err != nil {
+		logger.LogError("%v", err)
+		return 2
+	}
+	if file == "" && archiveDir != "" {
+		archived, err := archivedPaths(archiveDir, session)
+		if err != nil {
+			logger.LogError("%v", err)
+			return 1
+		}
+		paths = append(paths, archived...)
+	}
+
+	out := json.NewEncoder(os.Stdout)
+	found, searched := 0, 0
+	for _, path := range paths {
+		if file == "" && !strings.Contains(filepath.Base(filepath.Dir(path)), project) {
+			continue
+		}
+		// Transcripts are appended to, so one last written before --since has no newer events
+		if info, err := os.Stat(path); err == nil && info.ModTime().Before(query.Since) {
+			continue
+		}
+		searched++
+		err := searcher.SearchFile(path, func(m *search.Match) error {
+			found++
+			if jsonOutput {
+				return out.Encode(m)
+			}
+			_, err := fmt.Printf("── %s/%s %s\n%s\n\n", m.Project, m.Session, m.Timestamp.Local().Format("2006-01-02 15:04:05"), m.Text)
+			return err
+		})
+		if err != nil {
+			logger.LogError("Failed to search %s: %v", path, err)
+			return 1
+		}
+	}
+	if !jsonOutput {
+		fmt.Fprintf(os.Stderr, "%d matches in %d transcripts\n", found, searched)
+	}
+	return 0
+}
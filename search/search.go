@@ -0,0 +1,183 @@
+// Package search finds events in session transcripts by tool, text and time
+package search
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/kazegusuri/claude-companion/event"
+)
+
+// maxLineSize is the maximum size of a transcript line
+const maxLineSize = 10 * 1024 * 1024
+
+// Query selects events; empty fields match every event
+type Query struct {
+	Tools []string  // Glob patterns of tool names; tool uses, their results and hooks match
+	Text  string    // Text in the event's transcript line, ignoring case
+	Since time.Time // Events at or after this time
+}
+
+// Match is an event found by a search
+type Match struct {
+	Project   string      `json:"project"`
+	Session   string      `json:"session"`
+	Timestamp time.Time   `json:"timestamp"`
+	Kind      string      `json:"kind"`
+	Tools     []string    `json:"tools,omitempty"`
+	Text      string      `json:"text"` // As shown on the console
+	Event     event.Event `json:"event"`
+}
+
+// Searcher streams transcripts and reports the events that match a query
+type Searcher struct {
+	query     Query
+	text      string // Lower-cased query text
+	formatter *event.Formatter
+}
+
+// NewSearcher creates a searcher that formats matches with formatter
+func NewSearcher(query Query, formatter *event.Formatter) (*Searcher, error) {
+	for _, pattern := range query.Tools {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return nil, fmt.Errorf("invalid tool pattern %q: %w", pattern, err)
+		}
+	}
+	return &Searcher{query: query, text: strings.ToLower(query.Text), formatter: formatter}, nil
+}
+
+// SearchFile searches a transcript, calling fn with each match in order. It stops at
+// the first error fn returns.
+func (s *Searcher) SearchFile(path string, fn func(*Match) error) error {
+	file, err := event.OpenTranscript(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	return s.Search(file, path, fn)
+}
+
+// Search searches a transcript read from r line by line; path names its project and
+// session
+func (s *Searcher) Search(r io.Reader, path string, fn func(*Match) error) error {
+	project := filepath.Base(filepath.Dir(path))
+	session := event.SessionName(path)
+	parser := event.NewParserWithPath(path)
+	toolNames := make(map[string]string) // Tool use ID to tool name, to match results
+
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), maxLineSize)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+		textMatched := s.text == "" || strings.Contains(strings.ToLower(line), s.text)
+		// Tool uses are parsed even when their text does not match, to find their results
+		if !textMatched && (len(s.query.Tools) == 0 || !strings.Contains(line, `"tool_use"`)) {
+			continue
+		}
+		ev, err := parser.Parse(line)
+		if err != nil {
+			continue // Broken lines are reported by fsck
+		}
+		tools := eventTools(ev, toolNames)
+		if !textMatched || !s.matchTools(tools) {
+			continue
+		}
+
+		var timestamp time.Time
+		if base := event.BaseOf(ev); base != nil {
+			timestamp = base.Timestamp
+		}
+		if !s.query.Since.IsZero() && timestamp.Before(s.query.Since) {
+			continue
+		}
+		formatted, err := s.formatter.Format(ev)
+		if err != nil {
+			continue
+		}
+		match := &Match{
+			Project:   project,
+			Session:   session,
+			Timestamp: timestamp,
+			Kind:      event.KindOf(ev),
+			Tools:     tools,
+			Text:      strings.TrimRight(formatted, "\n"),
+			Event:     ev,
+		}
+		if err := fn(match); err != nil {
+			return err
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("error reading file: %w", err)
+	}
+	return nil
+}
+
+// matchTools reports whether an event with tools matches the tool patterns
+func (s *Searcher) matchTools(tools []string) bool {
+	if len(s.query.Tools) == 0 {
+		return true
+	}
+	for _, tool := range tools {
+		for _, pattern := range s.query.Tools {
+			if ok, _ := path.Match(pattern, tool); ok {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// eventTools returns the tools an event uses or returns the result of, remembering
+// tool uses in names by ID
+func eventTools(ev event.Event, names map[string]string) []string {
+	var tools []string
+	switch e := ev.(type) {
+	case *event.AssistantMessage:
+		for _, content := range e.Message.Content {
+			if content.Type == "tool_use" {
+				names[content.ID] = content.Name
+				tools = append(tools, content.Name)
+			}
+		}
+	case *event.UserMessage:
+		items, _ := e.Message.Content.([]interface{})
+		for _, item := range items {
+			m, _ := item.(map[string]interface{})
+			if id, _ := m["tool_use_id"].(string); names[id] != "" {
+				tools = append(tools, names[id])
+			}
+		}
+	case *event.NotificationEvent:
+		if e.ToolName != "" {
+			tools = append(tools, e.ToolName)
+		}
+	}
+	return tools
+}
+
+// ParseSince parses a --since value: a duration back from now such as 2d, 12h or
+// 30m, or a date (YYYY-MM-DD) in local time
+func ParseSince(value string, now time.Time) (time.Time, error) {
+	if t, err := time.ParseInLocation("2006-01-02", value, time.Local); err == nil {
+		return t, nil
+	}
+	if days, ok := strings.CutSuffix(value, "d"); ok {
+		if n, err := strconv.Atoi(days); err == nil && n >= 0 {
+			return now.AddDate(0, 0, -n), nil
+		}
+	}
+	if d, err := time.ParseDuration(value); err == nil && d >= 0 {
+		return now.Add(-d), nil
+	}
+	return time.Time{}, fmt.Errorf("invalid --since %q (expected a duration such as 2d or 12h, or a date YYYY-MM-DD)", value)
+}
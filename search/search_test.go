@@ -0,0 +1,87 @@
+package search
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/kazegusuri/claude-companion/event"
+	"github.com/kazegusuri/claude-companion/narrator"
+)
+
+const transcript = `{"type":"user","uuid":"u1","timestamp":"2025-01-01T10:00:00Z","message":{"role":"user","content":"Why does it panic?"}}
+{"type":"assistant","uuid":"a1","timestamp":"2025-01-01T10:00:05Z","message":{"id":"m1","model":"claude-sonnet-4-20250514","content":[{"type":"tool_use","id":"toolu_1","name":"Bash","input":{"command":"go test ./..."}}]}}
+{"type":"user","uuid":"u2","timestamp":"2025-01-01T10:00:09Z","message":{"role":"user","content":[{"type":"tool_result","tool_use_id":"toolu_1","content":"panic: nil map"}]}}
+{"type":"assistant","uuid":"a2","timestamp":"2025-01-02T10:00:00Z","message":{"id":"m2","model":"claude-sonnet-4-20250514","content":[{"type":"tool_use","id":"toolu_2","name":"mcp__github__get_issue","input":{"number":1}}]}}
+not json
+{"type":"assistant","uuid":"a3","timestamp":"2025-01-02T10:00:05Z","message":{"id":"m3","model":"claude-sonnet-4-20250514","content":[{"type":"text","text":"The PANIC is fixed."}]}}
+`
+
+func TestSearcher_Search(t *testing.T) {
+	tests := []struct {
+		name  string
+		query Query
+		want  []string // UUIDs of the matches
+	}{
+		{name: "everything", query: Query{}, want: []string{"u1", "a1", "u2", "a2", "a3"}},
+		{name: "tool use and its result", query: Query{Tools: []string{"Bash"}}, want: []string{"a1", "u2"}},
+		{name: "tool glob", query: Query{Tools: []string{"mcp__*"}}, want: []string{"a2"}},
+		{name: "text ignores case", query: Query{Text: "panic"}, want: []string{"u1", "u2", "a3"}},
+		{name: "tool result text", query: Query{Tools: []string{"Bash"}, Text: "nil map"}, want: []string{"u2"}},
+		{name: "since", query: Query{Since: time.Date(2025, 1, 2, 0, 0, 0, 0, time.UTC)}, want: []string{"a2", "a3"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, err := NewSearcher(tt.query, event.NewFormatter(narrator.NewNoOpNarrator()))
+			if err != nil {
+				t.Fatalf("NewSearcher() error = %v", err)
+			}
+			var got []string
+			err = s.Search(strings.NewReader(transcript), "/root/-home-me-app/s1.jsonl", func(m *Match) error {
+				if m.Project != "-home-me-app" || m.Session != "s1" {
+					t.Errorf("match in %s/%s, want -home-me-app/s1", m.Project, m.Session)
+				}
+				got = append(got, event.BaseOf(m.Event).UUID)
+				return nil
+			})
+			if err != nil {
+				t.Fatalf("Search() error = %v", err)
+			}
+			if strings.Join(got, ",") != strings.Join(tt.want, ",") {
+				t.Errorf("matches = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNewSearcher_InvalidPattern(t *testing.T) {
+	if _, err := NewSearcher(Query{Tools: []string{"["}}, event.NewFormatter(narrator.NewNoOpNarrator())); err == nil {
+		t.Error("NewSearcher() with a malformed glob succeeded, want an error")
+	}
+}
+
+func TestParseSince(t *testing.T) {
+	now := time.Date(2025, 3, 10, 12, 0, 0, 0, time.Local)
+	tests := []struct {
+		value   string
+		want    time.Time
+		wantErr bool
+	}{
+		{value: "2d", want: time.Date(2025, 3, 8, 12, 0, 0, 0, time.Local)},
+		{value: "12h", want: time.Date(2025, 3, 10, 0, 0, 0, 0, time.Local)},
+		{value: "30m", want: time.Date(2025, 3, 10, 11, 30, 0, 0, time.Local)},
+		{value: "2025-03-01", want: time.Date(2025, 3, 1, 0, 0, 0, 0, time.Local)},
+		{value: "yesterday", wantErr: true},
+		{value: "-2d", wantErr: true},
+	}
+	for _, tt := range tests {
+		got, err := ParseSince(tt.value, now)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("ParseSince(%q) error = %v, wantErr %v", tt.value, err, tt.wantErr)
+			continue
+		}
+		if !tt.wantErr && !got.Equal(tt.want) {
+			t.Errorf("ParseSince(%q) = %v, want %v", tt.value, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,171 @@
+package server
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/kazegusuri/claude-companion/logger"
+)
+
+// Admin controls the companion process for the admin API
+type Admin interface {
+	// Reload reloads the narrator and project configuration
+	Reload() error
+	// RestartWatchers stops and restarts the transcript and notification watchers
+	RestartWatchers() error
+	// Shutdown stops the companion gracefully; it returns before the companion stops
+	Shutdown()
+	// SetMuted stops or resumes speaking narrations
+	SetMuted(muted bool) error
+	// SetSpeaker speaks with a speaker ID; nil goes back to the configured speakers
+	SetSpeaker(id *int) error
+	// SetNarratorConfig switches to another narrator rules file
+	SetNarratorConfig(path string) error
+	// SetVoiceParameters changes voice parameters (speed, pitch, volume, intonation) by name
+	SetVoiceParameters(params map[string]float64) error
+}
+
+// AdminAction is an action of the admin API
+type AdminAction string
+
+const (
+	AdminReload          AdminAction = "reload"
+	AdminRestartWatchers AdminAction = "restart-watchers"
+	AdminShutdown        AdminAction = "shutdown"
+	AdminMute            AdminAction = "mute"
+	AdminUnmute          AdminAction = "unmute"
+	AdminSpeaker         AdminAction = "speaker"         // Value: speaker ID, or "default"
+	AdminNarratorConfig  AdminAction = "narrator-config" // Value: path of the narrator rules file
+	AdminVoice           AdminAction = "voice"           // Value: NAME=VALUE pairs, such as speed=1.2,pitch=0.05
+)
+
+// AdminActions lists the actions of the admin API
+var AdminActions = []AdminAction{AdminReload, AdminRestartWatchers, AdminShutdown, AdminMute, AdminUnmute, AdminSpeaker, AdminNarratorConfig, AdminVoice}
+
+// TakesValue reports whether the action needs a value in the request
+func (a AdminAction) TakesValue() bool {
+	return a == AdminSpeaker || a == AdminNarratorConfig || a == AdminVoice
+}
+
+// AdminRequest is the optional body of an admin request
+type AdminRequest struct {
+	Value string `json:"value"`
+}
+
+// adminResponse is the response of the admin API
+type adminResponse struct {
+	Action AdminAction `json:"action"`
+	Status string      `json:"status"` // "ok", "accepted" or "error"
+	Error  string      `json:"error,omitempty"`
+}
+
+// SetAdmin enables the admin API. It is served on the control socket, and on the HTTP
+// server only once an admin token is configured.
+func (s *Server) SetAdmin(admin Admin) {
+	s.admin = admin
+}
+
+// handleAdmin runs an admin action
+func (s *Server) handleAdmin(w http.ResponseWriter, r *http.Request) {
+	if s.admin == nil {
+		http.Error(w, "admin API is not enabled", http.StatusNotFound)
+		return
+	}
+	action := AdminAction(r.PathValue("action"))
+	logger.LogInfo("Admin request from %s: %s", clientIP(r), action)
+
+	var req AdminRequest
+	if action.TakesValue() {
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil && !errors.Is(err, io.EOF) {
+			http.Error(w, "invalid admin request: "+err.Error(), http.StatusBadRequest)
+			return
+		}
+	}
+
+	resp := adminResponse{Action: action, Status: "ok"}
+	status := http.StatusOK
+	var err error
+	switch action {
+	case AdminReload:
+		err = s.admin.Reload()
+	case AdminRestartWatchers:
+		err = s.admin.RestartWatchers()
+	case AdminShutdown:
+		// Respond before the server goes away
+		resp.Status = "accepted"
+		status = http.StatusAccepted
+		defer s.admin.Shutdown()
+	case AdminMute, AdminUnmute:
+		err = s.admin.SetMuted(action == AdminMute)
+	case AdminSpeaker:
+		id, parseErr := parseSpeaker(req.Value)
+		if parseErr != nil {
+			http.Error(w, parseErr.Error(), http.StatusBadRequest)
+			return
+		}
+		err = s.admin.SetSpeaker(id)
+	case AdminNarratorConfig:
+		if req.Value == "" {
+			http.Error(w, "narrator-config needs the path of a narrator config", http.StatusBadRequest)
+			return
+		}
+		err = s.admin.SetNarratorConfig(req.Value)
+	case AdminVoice:
+		params, parseErr := parseVoiceParameters(req.Value)
+		if parseErr != nil {
+			http.Error(w, parseErr.Error(), http.StatusBadRequest)
+			return
+		}
+		err = s.admin.SetVoiceParameters(params)
+	default:
+		http.Error(w, "unknown admin action: "+string(action), http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		logger.LogError("Admin %s failed: %v", action, err)
+		resp.Status = "error"
+		resp.Error = err.Error()
+		status = http.StatusInternalServerError
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(resp)
+	if f, ok := w.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+// parseSpeaker parses the value of a speaker request: a speaker ID, or "default" for nil
+func parseSpeaker(value string) (*int, error) {
+	if value == "" || value == "default" {
+		return nil, nil
+	}
+	id, err := strconv.Atoi(value)
+	if err != nil || id < 0 {
+		return nil, fmt.Errorf("invalid speaker: %q (must be a speaker ID or default)", value)
+	}
+	return &id, nil
+}
+
+// parseVoiceParameters parses the value of a voice request: comma-separated NAME=VALUE pairs
+func parseVoiceParameters(value string) (map[string]float64, error) {
+	params := make(map[string]float64)
+	for _, pair := range strings.Split(value, ",") {
+		name, v, ok := strings.Cut(strings.TrimSpace(pair), "=")
+		if !ok {
+			return nil, fmt.Errorf("invalid voice parameter: %q (expected NAME=VALUE)", pair)
+		}
+		f, err := strconv.ParseFloat(strings.TrimSpace(v), 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid value of voice parameter %s: %q", name, v)
+		}
+		params[strings.TrimSpace(name)] = f
+	}
+	return params, nil
+}
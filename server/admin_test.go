@@ -0,0 +1,191 @@
+package server
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// fakeAdmin records the admin actions it runs
+type fakeAdmin struct {
+	actions   []string
+	reloadErr error
+	shutdown  chan struct{} // Signaled by Shutdown, which runs after the response is sent
+}
+
+func (a *fakeAdmin) Reload() error {
+	a.actions = append(a.actions, "reload")
+	return a.reloadErr
+}
+
+func (a *fakeAdmin) RestartWatchers() error {
+	a.actions = append(a.actions, "restart-watchers")
+	return nil
+}
+
+func (a *fakeAdmin) Shutdown() {
+	a.actions = append(a.actions, "shutdown")
+	if a.shutdown != nil {
+		a.shutdown <- struct{}{}
+	}
+}
+
+func (a *fakeAdmin) SetMuted(muted bool) error {
+	if muted {
+		a.actions = append(a.actions, "mute")
+	} else {
+		a.actions = append(a.actions, "unmute")
+	}
+	return nil
+}
+
+func (a *fakeAdmin) SetSpeaker(id *int) error {
+	if id == nil {
+		a.actions = append(a.actions, "speaker:default")
+	} else {
+		a.actions = append(a.actions, fmt.Sprintf("speaker:%d", *id))
+	}
+	return nil
+}
+
+func (a *fakeAdmin) SetNarratorConfig(path string) error {
+	a.actions = append(a.actions, "narrator-config:"+path)
+	return nil
+}
+
+func (a *fakeAdmin) SetVoiceParameters(params map[string]float64) error {
+	for _, name := range []string{"speed", "pitch", "volume", "intonation"} {
+		if value, ok := params[name]; ok {
+			a.actions = append(a.actions, fmt.Sprintf("voice:%s=%g", name, value))
+		}
+	}
+	return nil
+}
+
+func TestAdmin(t *testing.T) {
+	admin := &fakeAdmin{shutdown: make(chan struct{}, 1)}
+	srv := NewServer("127.0.0.1:0")
+	srv.AddToken(Token{Value: "admin-token", Role: RoleAdmin})
+	srv.AddToken(Token{Value: "viewer-token", Role: RoleViewer})
+	srv.SetAdmin(admin)
+	ts := httptest.NewServer(srv.httpServer.Handler)
+	defer ts.Close()
+
+	tests := []struct {
+		name       string
+		action     string
+		body       string
+		token      string
+		reloadErr  error
+		wantStatus int
+		wantAction string
+	}{
+		{name: "reload", action: "reload", token: "admin-token", wantStatus: http.StatusOK, wantAction: "reload"},
+		{name: "reload fails", action: "reload", token: "admin-token", reloadErr: errors.New("bad config"), wantStatus: http.StatusInternalServerError, wantAction: "reload"},
+		{name: "restart watchers", action: "restart-watchers", token: "admin-token", wantStatus: http.StatusOK, wantAction: "restart-watchers"},
+		{name: "shutdown", action: "shutdown", token: "admin-token", wantStatus: http.StatusAccepted, wantAction: "shutdown"},
+		{name: "mute", action: "mute", token: "admin-token", wantStatus: http.StatusOK, wantAction: "mute"},
+		{name: "unmute", action: "unmute", token: "admin-token", wantStatus: http.StatusOK, wantAction: "unmute"},
+		{name: "speaker", action: "speaker", body: `{"value":"8"}`, token: "admin-token", wantStatus: http.StatusOK, wantAction: "speaker:8"},
+		{name: "default speaker", action: "speaker", body: `{"value":"default"}`, token: "admin-token", wantStatus: http.StatusOK, wantAction: "speaker:default"},
+		{name: "invalid speaker", action: "speaker", body: `{"value":"alice"}`, token: "admin-token", wantStatus: http.StatusBadRequest},
+		{name: "narrator config", action: "narrator-config", body: `{"value":"/tmp/rules.json"}`, token: "admin-token", wantStatus: http.StatusOK, wantAction: "narrator-config:/tmp/rules.json"},
+		{name: "narrator config without path", action: "narrator-config", token: "admin-token", wantStatus: http.StatusBadRequest},
+		{name: "voice", action: "voice", body: `{"value":"speed=1.2"}`, token: "admin-token", wantStatus: http.StatusOK, wantAction: "voice:speed=1.2"},
+		{name: "invalid voice", action: "voice", body: `{"value":"speed"}`, token: "admin-token", wantStatus: http.StatusBadRequest},
+		{name: "viewer", action: "shutdown", token: "viewer-token", wantStatus: http.StatusForbidden},
+		{name: "unknown action", action: "reboot", token: "admin-token", wantStatus: http.StatusNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			admin.actions = nil
+			admin.reloadErr = tt.reloadErr
+			req, _ := http.NewRequest("POST", ts.URL+"/api/admin/"+tt.action, strings.NewReader(tt.body))
+			req.Header.Set("Authorization", "Bearer "+tt.token)
+			resp, err := http.DefaultClient.Do(req)
+			if err != nil {
+				t.Fatalf("POST error = %v", err)
+			}
+			defer resp.Body.Close()
+			if resp.StatusCode != tt.wantStatus {
+				t.Fatalf("status = %d, want %d", resp.StatusCode, tt.wantStatus)
+			}
+			if tt.wantAction == "shutdown" {
+				<-admin.shutdown
+			}
+			if tt.wantAction == "" {
+				if len(admin.actions) != 0 {
+					t.Errorf("ran %v, want nothing", admin.actions)
+				}
+				return
+			}
+			if len(admin.actions) != 1 || admin.actions[0] != tt.wantAction {
+				t.Errorf("ran %v, want %s", admin.actions, tt.wantAction)
+			}
+			var body adminResponse
+			if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+				t.Fatalf("failed to decode response: %v", err)
+			}
+			if (body.Error != "") != (tt.reloadErr != nil) {
+				t.Errorf("response = %+v", body)
+			}
+		})
+	}
+}
+
+func TestAdminDisabled(t *testing.T) {
+	srv := NewServer("127.0.0.1:0")
+	ts := httptest.NewServer(srv.mux)
+	defer ts.Close()
+
+	resp, err := http.Post(ts.URL+"/api/admin/shutdown", "", nil)
+	if err != nil {
+		t.Fatalf("POST error = %v", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusNotFound {
+		t.Errorf("status = %d, want %d", resp.StatusCode, http.StatusNotFound)
+	}
+}
+
+func TestAdminWithoutToken(t *testing.T) {
+	admin := &fakeAdmin{}
+	srv := NewServer("127.0.0.1:0")
+	srv.AddToken(Token{Value: "viewer-token", Role: RoleViewer})
+	srv.SetAdmin(admin)
+	if srv.AdminOverHTTP() {
+		t.Error("AdminOverHTTP() = true without an admin token")
+	}
+
+	// Without an admin token, the admin API is not on the HTTP server
+	ts := httptest.NewServer(NewServer("127.0.0.1:0").httpServer.Handler)
+	defer ts.Close()
+	resp, err := http.Post(ts.URL+"/api/admin/shutdown", "", nil)
+	if err != nil {
+		t.Fatalf("POST error = %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusNotFound {
+		t.Errorf("status = %d, want %d", resp.StatusCode, http.StatusNotFound)
+	}
+
+	// but it is on the control socket
+	path := filepath.Join(shortTempDir(t), "control.sock")
+	if err := srv.StartControl(path); err != nil {
+		t.Fatalf("StartControl() error = %v", err)
+	}
+	defer srv.Stop()
+	resp, err = unixClient(path).Post("http://companion/api/admin/mute", "", nil)
+	if err != nil {
+		t.Fatalf("POST error = %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK || len(admin.actions) != 1 || admin.actions[0] != "mute" {
+		t.Errorf("status = %d, actions = %v", resp.StatusCode, admin.actions)
+	}
+}
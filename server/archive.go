@@ -0,0 +1,40 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/kazegusuri/claude-companion/archive"
+)
+
+// archiveResponse is the response of the archived session list API
+type archiveResponse struct {
+	Sessions []archive.Entry `json:"sessions"`
+}
+
+// SetArchiveDir enables the archived session list API for sessions archived in dir
+func (s *Server) SetArchiveDir(dir string) {
+	s.archiveDir = dir
+}
+
+// handleListArchive returns the sessions archived by gc, most recently active first.
+// The index is read on every request since gc runs in another process.
+func (s *Server) handleListArchive(w http.ResponseWriter, r *http.Request) {
+	resp := archiveResponse{Sessions: []archive.Entry{}}
+	if s.archiveDir != "" {
+		index, err := archive.LoadIndex(s.archiveDir)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		project, session := r.URL.Query().Get("project"), r.URL.Query().Get("session")
+		for _, entry := range index.Entries() {
+			if (project == "" || entry.Project == project) && (session == "" || entry.Session == session) {
+				resp.Sessions = append(resp.Sessions, entry)
+			}
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(resp)
+}
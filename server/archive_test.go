@@ -0,0 +1,52 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/kazegusuri/claude-companion/archive"
+)
+
+func TestServer_HandleListArchive(t *testing.T) {
+	dir := t.TempDir()
+	index, err := archive.LoadIndex(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	index.Add(archive.Entry{Project: "app", Session: "s1", Path: dir + "/app/s1.jsonl.gz", Compressed: true})
+	index.Add(archive.Entry{Project: "web", Session: "s2", Path: dir + "/web/s2.jsonl"})
+	if err := index.Save(); err != nil {
+		t.Fatal(err)
+	}
+
+	srv := NewServer("127.0.0.1:0")
+	ts := httptest.NewServer(srv.httpServer.Handler)
+	defer ts.Close()
+
+	list := func(query string) []archive.Entry {
+		t.Helper()
+		resp, err := http.Get(ts.URL + "/api/archive" + query)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer resp.Body.Close()
+		var got archiveResponse
+		if err := json.NewDecoder(resp.Body).Decode(&got); err != nil {
+			t.Fatal(err)
+		}
+		return got.Sessions
+	}
+
+	if got := list(""); len(got) != 0 {
+		t.Errorf("sessions without an archive = %+v", got)
+	}
+	srv.SetArchiveDir(dir)
+	if got := list(""); len(got) != 2 {
+		t.Errorf("sessions = %+v, want 2", got)
+	}
+	if got := list("?project=app"); len(got) != 1 || got[0].Session != "s1" || !got[0].Compressed {
+		t.Errorf("sessions of app = %+v", got)
+	}
+}
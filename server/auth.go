@@ -0,0 +1,143 @@
+package server
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// Role is the access level granted by an API token
+type Role string
+
+const (
+	// RoleViewer can read event streams but cannot send commands
+	RoleViewer Role = "viewer"
+	// RoleAdmin can read event streams and send commands
+	RoleAdmin Role = "admin"
+)
+
+// allows reports whether the role grants the access of the required role
+func (r Role) allows(required Role) bool {
+	return r == RoleAdmin || r == required
+}
+
+// Token is an API token and the role it grants
+type Token struct {
+	Value string
+	Role  Role
+}
+
+// ParseToken parses a token of the form TOKEN, admin:TOKEN or viewer:TOKEN.
+// Tokens without a role prefix are admin tokens. Any other prefix is an error, so
+// a misspelled role does not grant full access.
+func ParseToken(s string) (Token, error) {
+	token := Token{Value: s, Role: RoleAdmin}
+	if role, value, ok := strings.Cut(s, ":"); ok {
+		switch Role(role) {
+		case RoleAdmin, RoleViewer:
+			token = Token{Value: value, Role: Role(role)}
+		default:
+			return Token{}, fmt.Errorf("invalid server token: unknown role %q (must be admin or viewer)", role)
+		}
+	}
+	if token.Value == "" {
+		return Token{}, fmt.Errorf("invalid server token %q: empty token", s)
+	}
+	return token, nil
+}
+
+// roleKey is the context key of the authenticated role
+type roleKey struct{}
+
+// RoleFromContext returns the role of the authenticated request.
+// Requests to a server without tokens are admin requests.
+func RoleFromContext(ctx context.Context) Role {
+	if role, ok := ctx.Value(roleKey{}).(Role); ok {
+		return role
+	}
+	return RoleAdmin
+}
+
+// AddToken allows requests authenticated with the token. The first admin token
+// enables the admin API on the HTTP server.
+func (s *Server) AddToken(token Token) {
+	s.tokens = append(s.tokens, token)
+	if token.Role == RoleAdmin && !s.adminRoutes {
+		s.mux.HandleFunc("POST /api/admin/{action}", s.handleAdmin)
+		s.adminRoutes = true
+	}
+}
+
+// AdminOverHTTP reports whether the admin API is served over HTTP, which needs an
+// admin token. Without one it is only on the control socket.
+func (s *Server) AdminOverHTTP() bool {
+	return s.adminRoutes
+}
+
+// AuthEnabled reports whether requests must be authenticated
+func (s *Server) AuthEnabled() bool {
+	return len(s.tokens) > 0
+}
+
+// authenticate rejects requests without a valid token once any token is configured.
+// Read-only methods need a viewer token; every other method needs an admin token.
+func (s *Server) authenticate(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !s.AuthEnabled() {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		role, ok := s.lookupToken(requestToken(r))
+		if !ok {
+			w.Header().Set("WWW-Authenticate", `Bearer realm="claude-companion"`)
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+
+		required := RoleAdmin
+		switch r.Method {
+		case http.MethodGet, http.MethodHead, http.MethodOptions:
+			required = RoleViewer
+		}
+		if !role.allows(required) {
+			http.Error(w, fmt.Sprintf("forbidden: %s role is read-only", role), http.StatusForbidden)
+			return
+		}
+
+		next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), roleKey{}, role)))
+	})
+}
+
+// lookupToken returns the role of a token
+func (s *Server) lookupToken(value string) (Role, bool) {
+	if value == "" {
+		return "", false
+	}
+	for _, token := range s.tokens {
+		if subtle.ConstantTimeCompare([]byte(token.Value), []byte(value)) == 1 {
+			return token.Role, true
+		}
+	}
+	return "", false
+}
+
+// requestToken returns the bearer token of a request.
+// EventSource cannot set headers, so the token query parameter is also accepted.
+func requestToken(r *http.Request) string {
+	if auth := r.Header.Get("Authorization"); auth != "" {
+		if token, ok := strings.CutPrefix(auth, "Bearer "); ok {
+			return strings.TrimSpace(token)
+		}
+	}
+	return r.URL.Query().Get("token")
+}
+
+// handleWhoAmI returns the role of the caller so clients can hide control actions from viewers
+func (s *Server) handleWhoAmI(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]string{"role": string(RoleFromContext(r.Context()))})
+}
@@ -0,0 +1,107 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestParseToken(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    Token
+		wantErr bool
+	}{
+		{in: "secret", want: Token{Value: "secret", Role: RoleAdmin}},
+		{in: "admin:secret", want: Token{Value: "secret", Role: RoleAdmin}},
+		{in: "viewer:secret", want: Token{Value: "secret", Role: RoleViewer}},
+		{in: "admin:abc:def", want: Token{Value: "abc:def", Role: RoleAdmin}},
+		{in: "veiwer:secret", wantErr: true},
+		{in: "Viewer:secret", wantErr: true},
+		{in: "viewer:", wantErr: true},
+		{in: "", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			got, err := ParseToken(tt.in)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseToken(%q) error = %v, wantErr %v", tt.in, err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("ParseToken(%q) = %+v, want %+v", tt.in, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAuthentication(t *testing.T) {
+	srv := NewServer("127.0.0.1:0")
+	srv.AddToken(Token{Value: "admin-token", Role: RoleAdmin})
+	srv.AddToken(Token{Value: "viewer-token", Role: RoleViewer})
+	srv.Handle("POST /api/test/command", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	ts := httptest.NewServer(srv.httpServer.Handler)
+	defer ts.Close()
+
+	tests := []struct {
+		name       string
+		method     string
+		path       string
+		header     string
+		wantStatus int
+		wantRole   Role
+	}{
+		{name: "no token", method: "GET", path: "/api/whoami", wantStatus: http.StatusUnauthorized},
+		{name: "wrong token", method: "GET", path: "/api/whoami", header: "Bearer nope", wantStatus: http.StatusUnauthorized},
+		{name: "viewer reads", method: "GET", path: "/api/whoami", header: "Bearer viewer-token", wantStatus: http.StatusOK, wantRole: RoleViewer},
+		{name: "admin reads", method: "GET", path: "/api/whoami", header: "Bearer admin-token", wantStatus: http.StatusOK, wantRole: RoleAdmin},
+		{name: "query token", method: "GET", path: "/api/whoami?token=viewer-token", wantStatus: http.StatusOK, wantRole: RoleViewer},
+		{name: "viewer cannot send commands", method: "POST", path: "/api/test/command", header: "Bearer viewer-token", wantStatus: http.StatusForbidden},
+		{name: "admin sends commands", method: "POST", path: "/api/test/command", header: "Bearer admin-token", wantStatus: http.StatusNoContent},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req, _ := http.NewRequest(tt.method, ts.URL+tt.path, nil)
+			if tt.header != "" {
+				req.Header.Set("Authorization", tt.header)
+			}
+			resp, err := http.DefaultClient.Do(req)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer resp.Body.Close()
+
+			if resp.StatusCode != tt.wantStatus {
+				t.Fatalf("status = %d, want %d", resp.StatusCode, tt.wantStatus)
+			}
+			if tt.wantRole != "" {
+				var body map[string]string
+				if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+					t.Fatal(err)
+				}
+				if Role(body["role"]) != tt.wantRole {
+					t.Errorf("role = %q, want %q", body["role"], tt.wantRole)
+				}
+			}
+		})
+	}
+}
+
+func TestAuthenticationDisabled(t *testing.T) {
+	srv := NewServer("127.0.0.1:0")
+	ts := httptest.NewServer(srv.httpServer.Handler)
+	defer ts.Close()
+
+	resp, err := http.Get(ts.URL + "/api/whoami")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Errorf("status = %d, want %d", resp.StatusCode, http.StatusOK)
+	}
+}
@@ -0,0 +1,304 @@
+package server
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/kazegusuri/claude-companion/event"
+)
+
+// defaultHistorySize is the number of messages kept per session for resuming streams
+const defaultHistorySize = 256
+
+// How long the broker keeps the history of a session after its last message: ended
+// sessions are kept long enough for clients to resume and see the end, and idle ones
+// as long as they may be resumed
+const (
+	endedRetention = time.Minute
+	idleRetention  = time.Hour
+)
+
+// pruneInterval is how often sessions past their retention are dropped
+const pruneInterval = time.Minute
+
+// StreamMessage is a single event distributed to streaming clients
+type StreamMessage struct {
+	ID        int64     `json:"id"`
+	Type      string    `json:"type"`
+	SessionID string    `json:"sessionId"`
+	Project   string    `json:"project,omitempty"`
+	Timestamp time.Time `json:"timestamp"`
+	Text      string    `json:"text"`
+	Priority  int       `json:"priority"`
+	Highlight bool      `json:"highlight,omitempty"` // Dashboards should draw attention to the event
+	// Narrations are the narrator outputs of the event, as spoken with voice
+	Narrations []string `json:"narrations,omitempty"`
+	// Event is the raw event, only included with SetRawEvents
+	Event event.Event `json:"event,omitempty"`
+}
+
+// StreamFilter selects the messages of a subscription: those of any of Sessions or of
+// any of Projects. An empty filter selects every message.
+type StreamFilter struct {
+	Sessions []string
+	Projects []string
+}
+
+// matches reports whether the filter selects a message
+func (f StreamFilter) matches(msg *StreamMessage) bool {
+	if len(f.Sessions) == 0 && len(f.Projects) == 0 {
+		return true
+	}
+	for _, id := range f.Sessions {
+		if msg.SessionID == id {
+			return true
+		}
+	}
+	for _, project := range f.Projects {
+		if msg.Project == project {
+			return true
+		}
+	}
+	return false
+}
+
+// Subscription is a client subscribed to the stream of some sessions
+type Subscription struct {
+	filter StreamFilter
+	ch     chan *StreamMessage
+}
+
+// Messages returns the channel of messages for the subscription
+func (s *Subscription) Messages() <-chan *StreamMessage {
+	return s.ch
+}
+
+// brokerSession is what the broker keeps of a session
+type brokerSession struct {
+	history  []*StreamMessage
+	pending  bool      // Waiting for a permission decision
+	ended    bool      // The SessionEnd hook was received
+	lastSeen time.Time // When the last message of the session was published
+}
+
+// Broker distributes formatted events to streaming clients and keeps a short history per session.
+// Sessions are dropped a minute after they end, or an hour after their last message.
+type Broker struct {
+	mu          sync.Mutex
+	nextID      int64
+	sessions    map[string]*brokerSession
+	historySize int
+	subscribers map[*Subscription]struct{}
+	closed      bool
+	lastPrune   time.Time
+	rawEvents   bool
+	now         func() time.Time
+}
+
+// NewBroker creates a new broker
+func NewBroker() *Broker {
+	return &Broker{
+		sessions:    make(map[string]*brokerSession),
+		historySize: defaultHistorySize,
+		subscribers: make(map[*Subscription]struct{}),
+		now:         time.Now,
+	}
+}
+
+// SetRawEvents sets whether messages carry the raw event next to the formatted text.
+// Raw events hold every field of the transcript, so they are left out by default.
+func (b *Broker) SetRawEvents(enabled bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.rawEvents = enabled
+}
+
+// session returns the state of a session, creating it on first use
+func (b *Broker) session(sessionID string) *brokerSession {
+	session, ok := b.sessions[sessionID]
+	if !ok {
+		session = &brokerSession{}
+		b.sessions[sessionID] = session
+	}
+	return session
+}
+
+// prune drops the sessions past their retention, at most once per pruneInterval
+func (b *Broker) prune(now time.Time) {
+	if now.Sub(b.lastPrune) < pruneInterval {
+		return
+	}
+	b.lastPrune = now
+	for id, session := range b.sessions {
+		idle := now.Sub(session.lastSeen)
+		if idle >= idleRetention || (session.ended && idle >= endedRetention) {
+			delete(b.sessions, id)
+		}
+	}
+}
+
+// HandleEvent implements event.EventSink
+func (b *Broker) HandleEvent(ev event.Event, formatted string) {
+	sessionID := event.SessionIDOf(ev)
+	if sessionID == "" {
+		return
+	}
+
+	b.mu.Lock()
+	session := b.session(sessionID)
+	if event.IsPermissionRequest(ev) {
+		session.pending = true
+	} else if resolvesPermission(ev) {
+		session.pending = false
+	}
+	if n, ok := ev.(*event.NotificationEvent); ok && n.HookEventName == "SessionEnd" {
+		session.ended = true
+	}
+	rawEvents := b.rawEvents
+	b.mu.Unlock()
+
+	msg := &StreamMessage{
+		Type:       string(ev.Type()),
+		SessionID:  sessionID,
+		Timestamp:  time.Now(),
+		Text:       formatted,
+		Priority:   event.PriorityOf(ev),
+		Highlight:  isHighlighted(ev),
+		Narrations: event.NarrationsOf(ev),
+	}
+	if rawEvents {
+		msg.Event = ev
+	}
+	if base := event.BaseOf(ev); base != nil {
+		if !base.Timestamp.IsZero() {
+			msg.Timestamp = base.Timestamp
+		}
+		if base.Session != nil {
+			msg.Project = base.Session.Project
+		}
+	}
+
+	b.Publish(msg)
+}
+
+// Publish assigns an ID to the message and distributes it to the subscribers it matches
+func (b *Broker) Publish(msg *StreamMessage) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.closed {
+		return
+	}
+
+	b.nextID++
+	msg.ID = b.nextID
+
+	now := b.now()
+	session := b.session(msg.SessionID)
+	session.history = append(session.history, msg)
+	if len(session.history) > b.historySize {
+		session.history = session.history[len(session.history)-b.historySize:]
+	}
+	session.lastSeen = now
+	b.prune(now)
+
+	for sub := range b.subscribers {
+		if !sub.filter.matches(msg) {
+			continue
+		}
+		select {
+		case sub.ch <- msg:
+		default:
+			// Slow client, drop the message rather than blocking the handler
+		}
+	}
+}
+
+// isHighlighted reports whether an event is an alert that dashboards should highlight
+func isHighlighted(ev event.Event) bool {
+	switch ev.(type) {
+	case *event.ToolSLABreachMessage, *event.BudgetAlertMessage, *event.SecurityAlertMessage:
+		return true
+	}
+	return false
+}
+
+// resolvesPermission reports whether an event shows that a pending permission request was answered.
+// The tool result (or a rejection) is written as a user message; other hooks mean the session moved on.
+// Assistant messages are ignored since the transcript can lag behind the notification.
+func resolvesPermission(ev event.Event) bool {
+	switch e := ev.(type) {
+	case *event.UserMessage:
+		return true
+	case *event.NotificationEvent:
+		return e.HookEventName != "Notification" && e.HookEventName != "PreToolUse"
+	}
+	return false
+}
+
+// PermissionPending reports whether a session is waiting for a permission decision
+func (b *Broker) PermissionPending(sessionID string) bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	session, ok := b.sessions[sessionID]
+	return ok && session.pending
+}
+
+// Subscribe subscribes to a session and returns the messages after lastEventID that are still in the history
+func (b *Broker) Subscribe(sessionID string, lastEventID int64) (*Subscription, []*StreamMessage) {
+	return b.SubscribeFilter(StreamFilter{Sessions: []string{sessionID}}, lastEventID)
+}
+
+// SubscribeFilter subscribes to the messages that filter selects and returns those after
+// lastEventID that are still in the history, oldest first
+func (b *Broker) SubscribeFilter(filter StreamFilter, lastEventID int64) (*Subscription, []*StreamMessage) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	sub := &Subscription{
+		filter: filter,
+		ch:     make(chan *StreamMessage, 64),
+	}
+	if b.closed {
+		close(sub.ch)
+		return sub, nil
+	}
+	b.subscribers[sub] = struct{}{}
+
+	var backlog []*StreamMessage
+	if lastEventID > 0 {
+		for _, session := range b.sessions {
+			for _, msg := range session.history {
+				if msg.ID > lastEventID && filter.matches(msg) {
+					backlog = append(backlog, msg)
+				}
+			}
+		}
+		sort.Slice(backlog, func(i, j int) bool { return backlog[i].ID < backlog[j].ID })
+	}
+	return sub, backlog
+}
+
+// Unsubscribe removes a subscriber
+func (b *Broker) Unsubscribe(sub *Subscription) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if _, ok := b.subscribers[sub]; ok {
+		delete(b.subscribers, sub)
+		close(sub.ch)
+	}
+}
+
+// Close disconnects all subscribers
+func (b *Broker) Close() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.closed = true
+	for sub := range b.subscribers {
+		delete(b.subscribers, sub)
+		close(sub.ch)
+	}
+}
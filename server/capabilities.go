@@ -0,0 +1,29 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+)
+
+// Capability is a subsystem of the companion and its effective configuration for this run
+type Capability struct {
+	Name    string `json:"name"`
+	Enabled bool   `json:"enabled"`
+	Detail  string `json:"detail,omitempty"`
+	Warning string `json:"warning,omitempty"` // Why the subsystem may not work as configured
+}
+
+// SetCapabilities sets the subsystems reported by the capabilities API
+func (s *Server) SetCapabilities(capabilities []Capability) {
+	s.capabilities = capabilities
+}
+
+// handleCapabilities returns the enabled subsystems and their effective configuration
+func (s *Server) handleCapabilities(w http.ResponseWriter, r *http.Request) {
+	capabilities := s.capabilities
+	if capabilities == nil {
+		capabilities = []Capability{}
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(capabilities)
+}
@@ -0,0 +1,47 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestServer_HandleCapabilities(t *testing.T) {
+	srv := NewServer("127.0.0.1:0")
+	ts := httptest.NewServer(srv.httpServer.Handler)
+	defer ts.Close()
+
+	get := func() []Capability {
+		t.Helper()
+		resp, err := http.Get(ts.URL + "/api/capabilities")
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer resp.Body.Close()
+		if resp.StatusCode != http.StatusOK {
+			t.Fatalf("status = %d, want %d", resp.StatusCode, http.StatusOK)
+		}
+		var got []Capability
+		if err := json.NewDecoder(resp.Body).Decode(&got); err != nil {
+			t.Fatal(err)
+		}
+		return got
+	}
+
+	if got := get(); len(got) != 0 {
+		t.Errorf("capabilities before SetCapabilities = %+v, want none", got)
+	}
+
+	want := []Capability{
+		{Name: "voice", Enabled: true, Detail: "VOICEVOX http://localhost:50021, speaker=1"},
+		{Name: "mqtt"},
+		{Name: "layout", Enabled: true, Detail: "two-column", Warning: "terminal is 80 columns wide"},
+	}
+	srv.SetCapabilities(want)
+	if diff := cmp.Diff(want, get()); diff != "" {
+		t.Errorf("capabilities mismatch (-want +got):\n%s", diff)
+	}
+}
@@ -0,0 +1,86 @@
+package server
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/kazegusuri/claude-companion/logger"
+)
+
+// StartControl serves the API on a Unix domain socket in the background. The socket
+// is only accessible to the user, so its requests are admin requests without a token.
+func (s *Server) StartControl(path string) error {
+	if err := removeStaleSocket(path); err != nil {
+		return err
+	}
+	listener, err := listenPrivate(path)
+	if err != nil {
+		return err
+	}
+
+	s.controlPath = path
+	s.controlServer = &http.Server{Handler: s.controlMux}
+	go func() {
+		if err := s.controlServer.Serve(listener); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			logger.LogError("Control socket error: %v", err)
+		}
+	}()
+	return nil
+}
+
+// listenPrivate listens on a Unix domain socket at path that only the user can access.
+// The socket is created in a new directory only the user can open, restricted, and
+// then moved to path, so others cannot connect while its permissions are set. It is
+// not removed when the listener closes; Stop removes it.
+func listenPrivate(path string) (*net.UnixListener, error) {
+	dir, err := os.MkdirTemp(filepath.Dir(path), ".cc")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create a private directory for %s: %w", path, err)
+	}
+	defer os.RemoveAll(dir)
+
+	tmp := filepath.Join(dir, "s")
+	listener, err := net.ListenUnix("unix", &net.UnixAddr{Name: tmp, Net: "unix"})
+	if err != nil {
+		return nil, fmt.Errorf("failed to listen on %s: %w", path, err)
+	}
+	listener.SetUnlinkOnClose(false)
+	if err := os.Chmod(tmp, 0o600); err != nil {
+		listener.Close()
+		return nil, fmt.Errorf("failed to restrict access to %s: %w", path, err)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		listener.Close()
+		return nil, fmt.Errorf("failed to move the socket to %s: %w", path, err)
+	}
+	return listener, nil
+}
+
+// removeStaleSocket removes a socket left behind by a companion that did not stop
+// cleanly. A socket in use by a running companion, or a file that is not a socket,
+// is left alone.
+func removeStaleSocket(path string) error {
+	info, err := os.Lstat(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to check %s: %w", path, err)
+	}
+	if info.Mode()&os.ModeSocket == 0 {
+		return fmt.Errorf("%s exists and is not a socket", path)
+	}
+	if conn, err := net.DialTimeout("unix", path, time.Second); err == nil {
+		conn.Close()
+		return fmt.Errorf("%s is in use by another companion", path)
+	}
+	if err := os.Remove(path); err != nil {
+		return fmt.Errorf("failed to remove stale socket %s: %w", path, err)
+	}
+	return nil
+}
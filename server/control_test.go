@@ -0,0 +1,102 @@
+package server
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// shortTempDir returns a temporary directory with a path short enough for a Unix socket
+func shortTempDir(t *testing.T) string {
+	t.Helper()
+	dir, err := os.MkdirTemp("", "cc")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { os.RemoveAll(dir) })
+	return dir
+}
+
+// unixClient returns an HTTP client that sends every request to a Unix socket
+func unixClient(path string) *http.Client {
+	return &http.Client{Transport: &http.Transport{
+		DialContext: func(ctx context.Context, _, _ string) (net.Conn, error) {
+			var d net.Dialer
+			return d.DialContext(ctx, "unix", path)
+		},
+	}}
+}
+
+func TestControlSocket(t *testing.T) {
+	path := filepath.Join(shortTempDir(t), "control.sock")
+	admin := &fakeAdmin{}
+	srv := NewServer("127.0.0.1:0")
+	srv.AddToken(Token{Value: "admin-token", Role: RoleAdmin})
+	srv.SetAdmin(admin)
+	if err := srv.StartControl(path); err != nil {
+		t.Fatalf("StartControl() error = %v", err)
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if perm := info.Mode().Perm(); perm != 0o600 {
+		t.Errorf("socket permissions = %o, want 600", perm)
+	}
+	// The private directory the socket is created in does not remain
+	if entries, err := os.ReadDir(filepath.Dir(path)); err != nil || len(entries) != 1 {
+		t.Errorf("socket directory has %d entries, error = %v", len(entries), err)
+	}
+
+	// No token is needed over the socket
+	resp, err := unixClient(path).Post("http://companion/api/admin/mute", "", nil)
+	if err != nil {
+		t.Fatalf("POST error = %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK || len(admin.actions) != 1 || admin.actions[0] != "mute" {
+		t.Errorf("status = %d, actions = %v", resp.StatusCode, admin.actions)
+	}
+
+	// A second companion cannot take over the socket
+	other := NewServer("127.0.0.1:0")
+	if err := other.StartControl(path); err == nil {
+		t.Error("StartControl() on a socket in use succeeded")
+	}
+
+	srv.Stop()
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Errorf("socket still exists after Stop: %v", err)
+	}
+}
+
+func TestControlSocket_Stale(t *testing.T) {
+	dir := shortTempDir(t)
+
+	// A socket nobody listens on is replaced
+	stale := filepath.Join(dir, "stale.sock")
+	listener, err := net.Listen("unix", stale)
+	if err != nil {
+		t.Fatal(err)
+	}
+	listener.(*net.UnixListener).SetUnlinkOnClose(false)
+	listener.Close()
+	srv := NewServer("127.0.0.1:0")
+	if err := srv.StartControl(stale); err != nil {
+		t.Errorf("StartControl() on a stale socket error = %v", err)
+	}
+	srv.Stop()
+
+	// Other files are left alone
+	file := filepath.Join(dir, "file")
+	if err := os.WriteFile(file, nil, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := NewServer("127.0.0.1:0").StartControl(file); err == nil {
+		t.Error("StartControl() on a regular file succeeded")
+	}
+}
@@ -0,0 +1,142 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/kazegusuri/claude-companion/event"
+)
+
+// healthCheckTimeout bounds each check of the health report
+const healthCheckTimeout = 5 * time.Second
+
+// Health report statuses
+const (
+	HealthOK       = "ok"
+	HealthDegraded = "degraded" // A check failed
+)
+
+// HealthCheck checks a dependency of the companion, such as the speech engine. It
+// returns a short description of its state, and an error if it is not working.
+type HealthCheck func(ctx context.Context) (string, error)
+
+// healthCheck is a named check of the health report
+type healthCheck struct {
+	name  string
+	check HealthCheck
+}
+
+// healthQueue is a named queue whose depth is in the health report
+type healthQueue struct {
+	name  string
+	depth func() int
+}
+
+// HealthReport is the self-diagnostics of the companion
+type HealthReport struct {
+	Status    string              `json:"status"` // HealthOK or HealthDegraded
+	StartedAt time.Time           `json:"startedAt"`
+	Checks    []HealthCheckResult `json:"checks"`
+	Watchers  *WatchersHealth     `json:"watchers,omitempty"`
+	Queues    map[string]int      `json:"queues"`   // Items waiting in each queue
+	Sessions  []SessionHealth     `json:"sessions"` // Most recent event first
+}
+
+// HealthCheckResult is the result of a check of the health report
+type HealthCheckResult struct {
+	Name          string `json:"name"`
+	OK            bool   `json:"ok"`
+	Detail        string `json:"detail,omitempty"`
+	Error         string `json:"error,omitempty"`
+	LatencyMillis int64  `json:"latencyMillis"`
+}
+
+// WatchersHealth summarizes how the transcripts are watched
+type WatchersHealth struct {
+	Roots       []event.RootWatchStatus `json:"roots"`
+	TailedFiles int                     `json:"tailedFiles"`
+	LagBytes    int64                   `json:"lagBytes"`
+}
+
+// SessionHealth is when a session last had an event
+type SessionHealth struct {
+	ID        string    `json:"id"`
+	Project   string    `json:"project,omitempty"`
+	LastEvent time.Time `json:"lastEvent"`
+}
+
+// AddHealthCheck adds a check of a dependency to the health report
+func (s *Server) AddHealthCheck(name string, check HealthCheck) {
+	s.healthChecks = append(s.healthChecks, healthCheck{name: name, check: check})
+}
+
+// AddHealthQueue adds a queue whose depth is shown in the health report
+func (s *Server) AddHealthQueue(name string, depth func() int) {
+	s.healthQueues = append(s.healthQueues, healthQueue{name: name, depth: depth})
+}
+
+// Health runs the checks and builds the health report
+func (s *Server) Health(ctx context.Context) HealthReport {
+	report := HealthReport{
+		Status:    HealthOK,
+		StartedAt: s.startedAt,
+		Checks:    make([]HealthCheckResult, len(s.healthChecks)),
+		Queues:    make(map[string]int),
+		Sessions:  []SessionHealth{},
+	}
+
+	// Checks run at once, so a dependency that hangs does not hold back the others
+	var wg sync.WaitGroup
+	for i, c := range s.healthChecks {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			checkCtx, cancel := context.WithTimeout(ctx, healthCheckTimeout)
+			defer cancel()
+			start := time.Now()
+			detail, err := c.check(checkCtx)
+			result := HealthCheckResult{Name: c.name, OK: err == nil, Detail: detail, LatencyMillis: time.Since(start).Milliseconds()}
+			if err != nil {
+				result.Error = err.Error()
+			}
+			report.Checks[i] = result
+		}()
+	}
+	wg.Wait()
+	for _, result := range report.Checks {
+		if !result.OK {
+			report.Status = HealthDegraded
+		}
+	}
+
+	if s.watchers != nil {
+		status := s.watchers.Status()
+		report.Watchers = &WatchersHealth{Roots: status.Roots, TailedFiles: len(status.Files), LagBytes: status.LagBytes}
+	}
+	for _, q := range s.healthQueues {
+		report.Queues[q.name] = q.depth()
+	}
+	if s.sessions != nil {
+		for _, session := range s.sessions.ListSessions() {
+			report.Sessions = append(report.Sessions, SessionHealth{ID: session.ID, Project: session.Project, LastEvent: session.LastSeen})
+		}
+		sort.Slice(report.Sessions, func(i, j int) bool {
+			return report.Sessions[i].LastEvent.After(report.Sessions[j].LastEvent)
+		})
+	}
+	return report
+}
+
+// handleHealth returns the health report, with 503 Service Unavailable if a check failed
+func (s *Server) handleHealth(w http.ResponseWriter, r *http.Request) {
+	report := s.Health(r.Context())
+	w.Header().Set("Content-Type", "application/json")
+	if report.Status != HealthOK {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}
+	json.NewEncoder(w).Encode(report)
+}
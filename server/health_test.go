@@ -0,0 +1,60 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/kazegusuri/claude-companion/event"
+)
+
+func TestServer_HandleHealth(t *testing.T) {
+	registry := event.NewSessionRegistry("")
+	registry.Observe(&event.NotificationEvent{SessionID: "s1", HookEventName: "SessionStart", Source: "startup", CWD: "/work"})
+	srv := NewServer("127.0.0.1:0")
+	srv.SetSessionStore(registry)
+	srv.SetWatcherStats(fakeWatcherStats{
+		Roots: []event.RootWatchStatus{{Path: "/home/me/.claude/projects", Mode: event.WatchModePoll}},
+		Files: []event.FileWatchStatus{{Path: "/home/me/.claude/projects/app/s1.jsonl"}, {Path: "/home/me/.claude/projects/app/s2.jsonl"}},
+	})
+	srv.AddHealthCheck("voicevox", func(ctx context.Context) (string, error) { return "VOICEVOX 0.14.10", nil })
+	srv.AddHealthQueue("voice", func() int { return 3 })
+	ts := httptest.NewServer(srv.httpServer.Handler)
+	defer ts.Close()
+
+	get := func() (int, HealthReport) {
+		resp, err := http.Get(ts.URL + "/api/health")
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer resp.Body.Close()
+		var report HealthReport
+		if err := json.NewDecoder(resp.Body).Decode(&report); err != nil {
+			t.Fatal(err)
+		}
+		return resp.StatusCode, report
+	}
+
+	code, report := get()
+	if code != http.StatusOK || report.Status != HealthOK {
+		t.Errorf("health = %d %s, want 200 ok", code, report.Status)
+	}
+	if len(report.Checks) != 1 || report.Checks[0].Detail != "VOICEVOX 0.14.10" || report.Queues["voice"] != 3 {
+		t.Errorf("checks = %+v, queues = %v", report.Checks, report.Queues)
+	}
+	if report.Watchers == nil || report.Watchers.TailedFiles != 2 || len(report.Watchers.Roots) != 1 {
+		t.Errorf("watchers = %+v, want one root and two tailed files", report.Watchers)
+	}
+	if len(report.Sessions) != 1 || report.Sessions[0].ID != "s1" || report.Sessions[0].LastEvent.IsZero() {
+		t.Errorf("sessions = %+v", report.Sessions)
+	}
+
+	srv.AddHealthCheck("database", func(ctx context.Context) (string, error) { return "", errors.New("database is locked") })
+	code, report = get()
+	if code != http.StatusServiceUnavailable || report.Status != HealthDegraded || report.Checks[1].Error != "database is locked" {
+		t.Errorf("health with a failed check = %d %+v, want 503 degraded", code, report)
+	}
+}
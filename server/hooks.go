@@ -0,0 +1,58 @@
+package server
+
+import (
+	"io"
+	"net"
+	"net/http"
+
+	"github.com/kazegusuri/claude-companion/event"
+)
+
+// SetHookReceiver enables the hook API, which passes hook payloads sent by the
+// hook subcommand to sender as notification events. Over HTTP it needs an admin
+// token once tokens are configured, and only takes local clients until then.
+func (s *Server) SetHookReceiver(sender event.EventSender) {
+	s.hooks = sender
+}
+
+// handleHook receives the JSON payload of a Claude Code hook
+func (s *Server) handleHook(w http.ResponseWriter, r *http.Request) {
+	if s.hooks == nil {
+		http.Error(w, "hook API is not enabled", http.StatusNotFound)
+		return
+	}
+	data, err := io.ReadAll(r.Body)
+	if err != nil {
+		http.Error(w, "failed to read hook payload", http.StatusBadRequest)
+		return
+	}
+	ev, err := event.ParseNotification(data)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	s.hooks.SendEvent(ev)
+	w.WriteHeader(http.StatusAccepted)
+}
+
+// localClients rejects requests from other machines while authentication is off, so
+// that nobody on the network can inject hook events to be narrated or acted on
+func (s *Server) localClients(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !s.AuthEnabled() && !isLoopbackClient(r.RemoteAddr) {
+			http.Error(w, "hooks from other machines need a server token", http.StatusForbidden)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// isLoopbackClient reports whether a remote address is on the local machine
+func isLoopbackClient(remoteAddr string) bool {
+	host, _, err := net.SplitHostPort(remoteAddr)
+	if err != nil {
+		return false
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
@@ -0,0 +1,140 @@
+package server
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/kazegusuri/claude-companion/event"
+	"github.com/kazegusuri/claude-companion/narrator"
+)
+
+// hookRecorder records the events sent by the hook API
+type hookRecorder struct {
+	events []event.Event
+}
+
+func (r *hookRecorder) SendEvent(ev event.Event) {
+	r.events = append(r.events, ev)
+}
+
+func TestHook(t *testing.T) {
+	recorder := &hookRecorder{}
+	srv := NewServer("127.0.0.1:0")
+	srv.AddToken(Token{Value: "admin-token", Role: RoleAdmin})
+	srv.AddToken(Token{Value: "viewer-token", Role: RoleViewer})
+	srv.SetHookReceiver(recorder)
+	ts := httptest.NewServer(srv.httpServer.Handler)
+	defer ts.Close()
+
+	tests := []struct {
+		name       string
+		token      string
+		body       string
+		wantStatus int
+	}{
+		{name: "notification", token: "admin-token", body: `{"session_id":"s1","hook_event_name":"Notification","message":"Claude needs your permission to use Bash"}`, wantStatus: http.StatusAccepted},
+		{name: "invalid payload", token: "admin-token", body: `{bad`, wantStatus: http.StatusBadRequest},
+		{name: "viewer", token: "viewer-token", body: `{"session_id":"s1"}`, wantStatus: http.StatusForbidden},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req, _ := http.NewRequest("POST", ts.URL+"/api/hooks", strings.NewReader(tt.body))
+			req.Header.Set("Authorization", "Bearer "+tt.token)
+			resp, err := http.DefaultClient.Do(req)
+			if err != nil {
+				t.Fatal(err)
+			}
+			resp.Body.Close()
+			if resp.StatusCode != tt.wantStatus {
+				t.Errorf("status = %d, want %d", resp.StatusCode, tt.wantStatus)
+			}
+		})
+	}
+
+	if len(recorder.events) != 1 {
+		t.Fatalf("got %d events, want 1", len(recorder.events))
+	}
+	ev, ok := recorder.events[0].(*event.NotificationEvent)
+	if !ok || ev.SessionID != "s1" || ev.HookEventName != "Notification" || ev.Timestamp.IsZero() {
+		t.Errorf("unexpected event: %+v", recorder.events[0])
+	}
+}
+
+func TestHookDisabled(t *testing.T) {
+	srv := NewServer("127.0.0.1:0")
+	ts := httptest.NewServer(srv.httpServer.Handler)
+	defer ts.Close()
+
+	resp, err := http.Post(ts.URL+"/api/hooks", "application/json", strings.NewReader(`{}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusNotFound {
+		t.Errorf("status = %d, want 404", resp.StatusCode)
+	}
+}
+
+func TestHookWithoutToken(t *testing.T) {
+	recorder := &hookRecorder{}
+	srv := NewServer("127.0.0.1:0")
+	srv.SetHookReceiver(recorder)
+
+	tests := []struct {
+		name       string
+		remoteAddr string
+		wantStatus int
+	}{
+		{name: "local client", remoteAddr: "127.0.0.1:50000", wantStatus: http.StatusAccepted},
+		{name: "other machine", remoteAddr: "192.168.1.20:50000", wantStatus: http.StatusForbidden},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest("POST", "/api/hooks", strings.NewReader(`{"session_id":"s1","hook_event_name":"Stop"}`))
+			req.Host = "127.0.0.1:8765"
+			req.RemoteAddr = tt.remoteAddr
+			rec := httptest.NewRecorder()
+			srv.httpServer.Handler.ServeHTTP(rec, req)
+			if rec.Code != tt.wantStatus {
+				t.Errorf("status = %d, want %d", rec.Code, tt.wantStatus)
+			}
+		})
+	}
+	if len(recorder.events) != 1 {
+		t.Errorf("got %d events, want 1 from the local client", len(recorder.events))
+	}
+}
+
+func TestHookDuringShutdown(t *testing.T) {
+	handler := event.NewHandler(narrator.NewNoOpNarrator(), false)
+	handler.Start()
+	srv := NewServer("127.0.0.1:0")
+	srv.SetHookReceiver(handler)
+
+	// Hooks keep arriving while the handler stops, and after it stopped
+	var started, wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		started.Add(1)
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			started.Done()
+			for j := 0; j < 200; j++ {
+				req := httptest.NewRequest("POST", "/api/hooks", strings.NewReader(`{"session_id":"s1","hook_event_name":"UserPromptSubmit"}`))
+				req.RemoteAddr = "127.0.0.1:50000"
+				rec := httptest.NewRecorder()
+				srv.mux.ServeHTTP(rec, req)
+				if rec.Code != http.StatusAccepted {
+					t.Errorf("status = %d, want %d", rec.Code, http.StatusAccepted)
+					return
+				}
+			}
+		}()
+	}
+	started.Wait()
+	handler.Stop()
+	wg.Wait()
+}
@@ -0,0 +1,106 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/kazegusuri/claude-companion/db"
+)
+
+// MetricsStore provides the stored metrics history
+type MetricsStore interface {
+	MetricsHistory(ctx context.Context, q db.MetricsQuery) ([]db.MetricPoint, error)
+}
+
+// metricsDefaults are the default step and range of each metric
+var metricsDefaults = map[string]struct{ step, since time.Duration }{
+	db.MetricEvents: {step: time.Minute, since: time.Hour},              // events/min
+	db.MetricTokens: {step: time.Hour, since: 24 * time.Hour},           // tokens/hour
+	db.MetricCost:   {step: 24 * time.Hour, since: 30 * 24 * time.Hour}, // cost/day
+}
+
+// maxMetricPoints bounds the buckets of a single project in a response
+const maxMetricPoints = 10000
+
+// metricsHistoryResponse is the response of the metrics history API
+type metricsHistoryResponse struct {
+	Metric string           `json:"metric"`
+	Step   string           `json:"step"`
+	Since  time.Time        `json:"since"`
+	Points []db.MetricPoint `json:"points"`
+}
+
+// SetMetricsStore enables the metrics history API
+func (s *Server) SetMetricsStore(store MetricsStore) {
+	s.metrics = store
+}
+
+// handleMetricsHistory returns the history of a metric for trend charts
+func (s *Server) handleMetricsHistory(w http.ResponseWriter, r *http.Request) {
+	if s.metrics == nil {
+		http.Error(w, "metrics history requires a database", http.StatusNotFound)
+		return
+	}
+
+	query := r.URL.Query()
+	metric := query.Get("metric")
+	if metric == "" {
+		metric = db.MetricEvents
+	}
+	defaults, ok := metricsDefaults[metric]
+	if !ok {
+		http.Error(w, fmt.Sprintf("unknown metric: %s", metric), http.StatusBadRequest)
+		return
+	}
+
+	step := defaults.step
+	if value := query.Get("step"); value != "" {
+		d, err := time.ParseDuration(value)
+		if err != nil || d < time.Second {
+			http.Error(w, fmt.Sprintf("invalid step: %s", value), http.StatusBadRequest)
+			return
+		}
+		step = d
+	}
+
+	since := defaults.since
+	if value := query.Get("since"); value != "" {
+		d, err := time.ParseDuration(value)
+		if err != nil || d <= 0 {
+			http.Error(w, fmt.Sprintf("invalid since: %s", value), http.StatusBadRequest)
+			return
+		}
+		since = d
+	}
+	if since/step > maxMetricPoints {
+		http.Error(w, fmt.Sprintf("too many points: since %s with step %s", since, step), http.StatusBadRequest)
+		return
+	}
+
+	now := time.Now()
+	points, err := s.metrics.MetricsHistory(r.Context(), db.MetricsQuery{
+		Metric:  metric,
+		Project: query.Get("project"),
+		Since:   now.Add(-since),
+		Until:   now,
+		Step:    step,
+	})
+	if err != nil {
+		http.Error(w, fmt.Sprintf("failed to load metrics history: %v", err), http.StatusInternalServerError)
+		return
+	}
+	if points == nil {
+		points = []db.MetricPoint{}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(metricsHistoryResponse{
+		Metric: metric,
+		Step:   step.String(),
+		Since:  now.Add(-since),
+		Points: points,
+	})
+}
@@ -0,0 +1,96 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/kazegusuri/claude-companion/db"
+)
+
+// fakeMetricsStore records the last query and returns fixed points
+type fakeMetricsStore struct {
+	query  db.MetricsQuery
+	points []db.MetricPoint
+}
+
+func (f *fakeMetricsStore) MetricsHistory(ctx context.Context, q db.MetricsQuery) ([]db.MetricPoint, error) {
+	f.query = q
+	return f.points, nil
+}
+
+func TestMetricsHistory(t *testing.T) {
+	store := &fakeMetricsStore{
+		points: []db.MetricPoint{{Time: time.Unix(1737885600, 0), Project: "proj", Value: 3}},
+	}
+	srv := NewServer("127.0.0.1:0")
+	srv.SetMetricsStore(store)
+	ts := httptest.NewServer(srv.mux)
+	defer ts.Close()
+
+	tests := []struct {
+		name       string
+		query      string
+		wantStatus int
+		wantMetric string
+		wantStep   time.Duration
+		wantSince  time.Duration
+	}{
+		{name: "default", query: "", wantStatus: http.StatusOK, wantMetric: db.MetricEvents, wantStep: time.Minute, wantSince: time.Hour},
+		{name: "tokens defaults", query: "?metric=tokens", wantStatus: http.StatusOK, wantMetric: db.MetricTokens, wantStep: time.Hour, wantSince: 24 * time.Hour},
+		{name: "cost defaults", query: "?metric=cost", wantStatus: http.StatusOK, wantMetric: db.MetricCost, wantStep: 24 * time.Hour, wantSince: 30 * 24 * time.Hour},
+		{name: "custom range", query: "?metric=events&step=5m&since=6h&project=proj", wantStatus: http.StatusOK, wantMetric: db.MetricEvents, wantStep: 5 * time.Minute, wantSince: 6 * time.Hour},
+		{name: "unknown metric", query: "?metric=latency", wantStatus: http.StatusBadRequest},
+		{name: "invalid step", query: "?step=fast", wantStatus: http.StatusBadRequest},
+		{name: "invalid since", query: "?since=-1h", wantStatus: http.StatusBadRequest},
+		{name: "too many points", query: "?step=1s&since=720h", wantStatus: http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp, err := http.Get(ts.URL + "/api/metrics/history" + tt.query)
+			if err != nil {
+				t.Fatalf("GET error = %v", err)
+			}
+			defer resp.Body.Close()
+			if resp.StatusCode != tt.wantStatus {
+				t.Fatalf("status = %d, want %d", resp.StatusCode, tt.wantStatus)
+			}
+			if tt.wantStatus != http.StatusOK {
+				return
+			}
+
+			var body metricsHistoryResponse
+			if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+				t.Fatalf("failed to decode response: %v", err)
+			}
+			if body.Metric != tt.wantMetric || body.Step != tt.wantStep.String() || len(body.Points) != 1 {
+				t.Errorf("response = %+v, want metric %s with step %s and 1 point", body, tt.wantMetric, tt.wantStep)
+			}
+			if store.query.Metric != tt.wantMetric || store.query.Step != tt.wantStep {
+				t.Errorf("query = %+v, want metric %s with step %s", store.query, tt.wantMetric, tt.wantStep)
+			}
+			if got := store.query.Until.Sub(store.query.Since); got != tt.wantSince {
+				t.Errorf("query range = %s, want %s", got, tt.wantSince)
+			}
+		})
+	}
+}
+
+func TestMetricsHistoryWithoutStore(t *testing.T) {
+	srv := NewServer("127.0.0.1:0")
+	ts := httptest.NewServer(srv.mux)
+	defer ts.Close()
+
+	resp, err := http.Get(ts.URL + "/api/metrics/history")
+	if err != nil {
+		t.Fatalf("GET error = %v", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusNotFound {
+		t.Errorf("status = %d, want %d", resp.StatusCode, http.StatusNotFound)
+	}
+}
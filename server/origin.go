@@ -0,0 +1,81 @@
+package server
+
+import (
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// SetAllowedOrigins lets browser pages from other origins use the API, such as a
+// dashboard served elsewhere. "*" allows every origin.
+func (s *Server) SetAllowedOrigins(origins []string) {
+	s.allowedOrigins = make(map[string]bool, len(origins))
+	for _, origin := range origins {
+		s.allowedOrigins[strings.TrimRight(origin, "/")] = true
+	}
+}
+
+// checkOrigin rejects browser requests from pages of other origins unless they are
+// allowed, so a web page cannot send commands to the companion on the user's behalf.
+// Allowed origins get CORS headers, and their preflight requests are answered here
+// since preflights carry no token.
+func (s *Server) checkOrigin(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		origin := r.Header.Get("Origin")
+		if origin == "" || sameOrigin(origin, r.Host) {
+			next.ServeHTTP(w, r)
+			return
+		}
+		if !s.allowedOrigins[origin] && !s.allowedOrigins["*"] {
+			http.Error(w, "origin not allowed: "+origin, http.StatusForbidden)
+			return
+		}
+
+		w.Header().Set("Access-Control-Allow-Origin", origin)
+		w.Header().Add("Vary", "Origin")
+		if r.Method == http.MethodOptions && r.Header.Get("Access-Control-Request-Method") != "" {
+			w.Header().Set("Access-Control-Allow-Methods", "GET, HEAD, POST")
+			w.Header().Set("Access-Control-Allow-Headers", "Authorization, Content-Type, Last-Event-ID")
+			w.Header().Set("Access-Control-Max-Age", "600")
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// sameOrigin reports whether an Origin header names the host the request was sent to
+func sameOrigin(origin, host string) bool {
+	u, err := url.Parse(origin)
+	if err != nil {
+		return false
+	}
+	return u.Host != "" && strings.EqualFold(u.Host, host)
+}
+
+// checkHost rejects requests whose Host header is not a loopback address while
+// authentication is off. A page that rebinds its own DNS name to 127.0.0.1 passes
+// the origin check, since its Origin matches the Host it sends, but not this one.
+func (s *Server) checkHost(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !s.AuthEnabled() && !isLoopbackHost(r.Host) {
+			http.Error(w, "host not allowed without a server token: "+r.Host, http.StatusForbidden)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// isLoopbackHost reports whether a Host header names the local machine
+func isLoopbackHost(host string) bool {
+	if h, _, err := net.SplitHostPort(host); err == nil {
+		host = h
+	}
+	host = strings.Trim(host, "[]")
+	if strings.EqualFold(host, "localhost") {
+		return true
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
@@ -0,0 +1,105 @@
+package server
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestCheckOrigin(t *testing.T) {
+	srv := NewServer("127.0.0.1:0")
+	srv.SetAllowedOrigins([]string{"https://dashboard.example.com/"})
+	ts := httptest.NewServer(srv.httpServer.Handler)
+	defer ts.Close()
+
+	tests := []struct {
+		name       string
+		method     string
+		origin     string
+		preflight  bool
+		wantStatus int
+		wantCORS   bool
+	}{
+		{name: "no origin", method: "GET", wantStatus: http.StatusOK},
+		{name: "same origin", method: "GET", origin: ts.URL, wantStatus: http.StatusOK},
+		{name: "allowed origin", method: "GET", origin: "https://dashboard.example.com", wantStatus: http.StatusOK, wantCORS: true},
+		{name: "other origin", method: "GET", origin: "https://evil.example.com", wantStatus: http.StatusForbidden},
+		{name: "other origin command", method: "POST", origin: "https://evil.example.com", wantStatus: http.StatusForbidden},
+		{name: "preflight", method: "OPTIONS", origin: "https://dashboard.example.com", preflight: true, wantStatus: http.StatusNoContent, wantCORS: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := "/api/sessions"
+			if tt.method == "POST" {
+				path = "/api/admin/shutdown"
+			}
+			req, _ := http.NewRequest(tt.method, ts.URL+path, nil)
+			if tt.origin != "" {
+				req.Header.Set("Origin", tt.origin)
+			}
+			if tt.preflight {
+				req.Header.Set("Access-Control-Request-Method", "GET")
+			}
+			resp, err := http.DefaultClient.Do(req)
+			if err != nil {
+				t.Fatal(err)
+			}
+			resp.Body.Close()
+			if resp.StatusCode != tt.wantStatus {
+				t.Errorf("status = %d, want %d", resp.StatusCode, tt.wantStatus)
+			}
+			if got := resp.Header.Get("Access-Control-Allow-Origin") != ""; got != tt.wantCORS {
+				t.Errorf("CORS headers = %v, want %v", got, tt.wantCORS)
+			}
+		})
+	}
+}
+
+func TestCheckOrigin_Wildcard(t *testing.T) {
+	srv := NewServer("127.0.0.1:0")
+	srv.SetAllowedOrigins([]string{"*"})
+	ts := httptest.NewServer(srv.httpServer.Handler)
+	defer ts.Close()
+
+	req, _ := http.NewRequest("GET", ts.URL+"/api/sessions", nil)
+	req.Header.Set("Origin", "http://localhost:3000")
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK || resp.Header.Get("Access-Control-Allow-Origin") != "http://localhost:3000" {
+		t.Errorf("status = %d, Access-Control-Allow-Origin = %q", resp.StatusCode, resp.Header.Get("Access-Control-Allow-Origin"))
+	}
+}
+
+func TestCheckHost(t *testing.T) {
+	open := NewServer("127.0.0.1:0")
+	authenticated := NewServer("127.0.0.1:0")
+	authenticated.AddToken(Token{Value: "viewer-token", Role: RoleViewer})
+
+	tests := []struct {
+		name       string
+		srv        *Server
+		host       string
+		wantStatus int
+	}{
+		{name: "localhost", srv: open, host: "localhost:8765", wantStatus: http.StatusOK},
+		{name: "ipv4 loopback", srv: open, host: "127.0.0.1:8765", wantStatus: http.StatusOK},
+		{name: "ipv6 loopback", srv: open, host: "[::1]:8765", wantStatus: http.StatusOK},
+		{name: "rebound name", srv: open, host: "attacker.example.com:8765", wantStatus: http.StatusForbidden},
+		{name: "network address", srv: open, host: "192.168.1.5:8765", wantStatus: http.StatusForbidden},
+		{name: "with a token", srv: authenticated, host: "192.168.1.5:8765", wantStatus: http.StatusOK},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest("GET", "/api/sessions?token=viewer-token", nil)
+			req.Host = tt.host
+			rec := httptest.NewRecorder()
+			tt.srv.httpServer.Handler.ServeHTTP(rec, req)
+			if rec.Code != tt.wantStatus {
+				t.Errorf("status = %d, want %d", rec.Code, tt.wantStatus)
+			}
+		})
+	}
+}
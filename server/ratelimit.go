@@ -0,0 +1,174 @@
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"net"
+	"net/http"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Default limits of the HTTP server
+const (
+	DefaultRateLimit    = 10.0    // Requests per second per client
+	DefaultRateBurst    = 20      // Requests a client can send at once
+	DefaultMaxBodyBytes = 1 << 20 // Largest request body accepted
+)
+
+// bucketIdleTime is how long a full bucket is kept before it is dropped
+const bucketIdleTime = 5 * time.Minute
+
+// tokenBucket is the request allowance of a client
+type tokenBucket struct {
+	tokens float64
+	last   time.Time
+}
+
+// rateLimiter is a token bucket rate limiter per client IP
+type rateLimiter struct {
+	mu        sync.Mutex
+	rate      float64 // Tokens added per second; 0 disables the limit
+	burst     float64
+	buckets   map[string]*tokenBucket
+	lastSweep time.Time
+	now       func() time.Time
+}
+
+// newRateLimiter creates a limiter allowing rate requests per second with bursts of burst
+func newRateLimiter(rate float64, burst int) *rateLimiter {
+	return &rateLimiter{
+		rate:    rate,
+		burst:   float64(max(burst, 1)),
+		buckets: make(map[string]*tokenBucket),
+		now:     time.Now,
+	}
+}
+
+// allow takes a token from the client's bucket. When the bucket is empty it
+// returns false and how long until the next token is available.
+func (l *rateLimiter) allow(client string) (bool, time.Duration) {
+	if l.rate <= 0 {
+		return true, 0
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	now := l.now()
+	l.sweep(now)
+
+	b, ok := l.buckets[client]
+	if !ok {
+		b = &tokenBucket{tokens: l.burst, last: now}
+		l.buckets[client] = b
+	}
+	b.tokens = math.Min(l.burst, b.tokens+now.Sub(b.last).Seconds()*l.rate)
+	b.last = now
+	if b.tokens < 1 {
+		return false, time.Duration((1 - b.tokens) / l.rate * float64(time.Second))
+	}
+	b.tokens--
+	return true, 0
+}
+
+// sweep drops the buckets of clients idle long enough to have refilled; l.mu must be held
+func (l *rateLimiter) sweep(now time.Time) {
+	if now.Sub(l.lastSweep) < time.Minute {
+		return
+	}
+	l.lastSweep = now
+	for client, b := range l.buckets {
+		if now.Sub(b.last) > bucketIdleTime {
+			delete(l.buckets, client)
+		}
+	}
+}
+
+// clients returns the number of clients being tracked
+func (l *rateLimiter) clients() int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return len(l.buckets)
+}
+
+// LimitStats counts the requests handled by the rate limiter and size limit
+type LimitStats struct {
+	Requests    int64 `json:"requests"`
+	RateLimited int64 `json:"rateLimited"`
+	TooLarge    int64 `json:"tooLarge"`
+	Clients     int   `json:"clients"`
+}
+
+// limitCounters are the counters behind LimitStats
+type limitCounters struct {
+	requests    atomic.Int64
+	rateLimited atomic.Int64
+	tooLarge    atomic.Int64
+}
+
+// SetRateLimit limits each client IP to rate requests per second with bursts of burst.
+// A rate of 0 disables the limit.
+func (s *Server) SetRateLimit(rate float64, burst int) {
+	s.limiter = newRateLimiter(rate, burst)
+}
+
+// SetMaxBodyBytes limits the size of request bodies. 0 disables the limit.
+func (s *Server) SetMaxBodyBytes(n int64) {
+	s.maxBodyBytes = n
+}
+
+// LimitStats returns the counters of the rate limiter and size limit
+func (s *Server) LimitStats() LimitStats {
+	return LimitStats{
+		Requests:    s.counters.requests.Load(),
+		RateLimited: s.counters.rateLimited.Load(),
+		TooLarge:    s.counters.tooLarge.Load(),
+		Clients:     s.limiter.clients(),
+	}
+}
+
+// limit rejects requests from clients over their rate limit with 429 and
+// requests with bodies over the size limit with 413. It runs before
+// authentication so unauthenticated floods are limited too.
+func (s *Server) limit(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		s.counters.requests.Add(1)
+
+		if ok, wait := s.limiter.allow(clientIP(r)); !ok {
+			s.counters.rateLimited.Add(1)
+			w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(wait.Seconds()))))
+			http.Error(w, "too many requests", http.StatusTooManyRequests)
+			return
+		}
+
+		if s.maxBodyBytes > 0 {
+			if r.ContentLength > s.maxBodyBytes {
+				s.counters.tooLarge.Add(1)
+				http.Error(w, fmt.Sprintf("request body too large: limit is %d bytes", s.maxBodyBytes), http.StatusRequestEntityTooLarge)
+				return
+			}
+			// Bodies without a length fail when a handler reads past the limit
+			r.Body = http.MaxBytesReader(w, r.Body, s.maxBodyBytes)
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+// clientIP returns the IP address of the client. Forwarding headers are ignored
+// since they can be set by the client itself.
+func clientIP(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
+
+// handleLimitStats returns the counters of the rate limiter and size limit
+func (s *Server) handleLimitStats(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(s.LimitStats())
+}
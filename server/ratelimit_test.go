@@ -0,0 +1,98 @@
+package server
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRateLimiter(t *testing.T) {
+	now := time.Date(2025, 1, 26, 10, 0, 0, 0, time.UTC)
+	l := newRateLimiter(2, 3)
+	l.now = func() time.Time { return now }
+
+	tests := []struct {
+		name     string
+		advance  time.Duration
+		client   string
+		want     bool
+		wantWait time.Duration
+	}{
+		{name: "burst 1", client: "a", want: true},
+		{name: "burst 2", client: "a", want: true},
+		{name: "burst 3", client: "a", want: true},
+		{name: "empty", client: "a", want: false, wantWait: 500 * time.Millisecond},
+		{name: "other client", client: "b", want: true},
+		{name: "refilled", advance: 500 * time.Millisecond, client: "a", want: true},
+		{name: "empty again", client: "a", want: false, wantWait: 500 * time.Millisecond},
+	}
+	for _, tt := range tests {
+		now = now.Add(tt.advance)
+		got, wait := l.allow(tt.client)
+		if got != tt.want || wait != tt.wantWait {
+			t.Errorf("%s: allow() = %v, %s, want %v, %s", tt.name, got, wait, tt.want, tt.wantWait)
+		}
+	}
+
+	// Idle clients are forgotten
+	now = now.Add(bucketIdleTime + time.Minute)
+	l.allow("c")
+	if got := l.clients(); got != 1 {
+		t.Errorf("clients() = %d after idle clients were swept, want 1", got)
+	}
+}
+
+func TestLimits(t *testing.T) {
+	srv := NewServer("127.0.0.1:0")
+	srv.SetRateLimit(1, 2)
+	srv.SetMaxBodyBytes(16)
+	srv.Handle("POST /api/test/command", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if _, err := io.ReadAll(r.Body); err != nil {
+			http.Error(w, err.Error(), http.StatusRequestEntityTooLarge)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	ts := httptest.NewServer(srv.httpServer.Handler)
+	defer ts.Close()
+
+	post := func(body io.Reader) *http.Response {
+		t.Helper()
+		resp, err := http.Post(ts.URL+"/api/test/command", "text/plain", body)
+		if err != nil {
+			t.Fatalf("POST error = %v", err)
+		}
+		resp.Body.Close()
+		return resp
+	}
+
+	if resp := post(strings.NewReader("small")); resp.StatusCode != http.StatusNoContent {
+		t.Errorf("small body: status = %d, want %d", resp.StatusCode, http.StatusNoContent)
+	}
+	if resp := post(strings.NewReader(strings.Repeat("x", 17))); resp.StatusCode != http.StatusRequestEntityTooLarge {
+		t.Errorf("large body: status = %d, want %d", resp.StatusCode, http.StatusRequestEntityTooLarge)
+	}
+	resp := post(strings.NewReader("small"))
+	if resp.StatusCode != http.StatusTooManyRequests || resp.Header.Get("Retry-After") == "" {
+		t.Errorf("over the limit: status = %d, Retry-After = %q, want %d with Retry-After", resp.StatusCode, resp.Header.Get("Retry-After"), http.StatusTooManyRequests)
+	}
+
+	srv.SetRateLimit(0, 0)
+	statsResp, err := http.Get(ts.URL + "/api/server/limits")
+	if err != nil {
+		t.Fatalf("GET error = %v", err)
+	}
+	defer statsResp.Body.Close()
+	var stats LimitStats
+	if err := json.NewDecoder(statsResp.Body).Decode(&stats); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	want := LimitStats{Requests: 4, RateLimited: 1, TooLarge: 1, Clients: 0}
+	if stats != want {
+		t.Errorf("stats = %+v, want %+v", stats, want)
+	}
+}
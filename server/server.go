@@ -0,0 +1,167 @@
+package server
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/kazegusuri/claude-companion/event"
+	"github.com/kazegusuri/claude-companion/logger"
+)
+
+// Server is the embedded HTTP server of the companion
+type Server struct {
+	addr        string
+	mux         *http.ServeMux // Routes of the HTTP server
+	controlMux  *http.ServeMux // Routes of the control socket, the admin API included
+	httpServer  *http.Server
+	listener    net.Listener
+	broker      *Broker
+	tokens      []Token
+	adminRoutes bool // Whether the admin API is registered on the HTTP server
+	metrics     MetricsStore
+	voice       VoiceStatus
+	sessions    SessionStore
+	admin       Admin
+	hooks       event.EventSender
+	watchers    WatcherStats
+	archiveDir  string // Sessions archived by gc; empty without
+	startedAt   time.Time
+
+	healthChecks []healthCheck
+	healthQueues []healthQueue
+	capabilities []Capability // Subsystems of this run, for the capabilities API
+
+	limiter      *rateLimiter
+	maxBodyBytes int64
+	counters     limitCounters
+
+	allowedOrigins map[string]bool // Origins of other sites allowed to use the API
+	controlServer  *http.Server    // Serves the control socket; nil without one
+	controlPath    string          // Path of the control socket, removed on Stop
+}
+
+// shutdownTimeout is how long Stop waits for requests in flight to finish
+const shutdownTimeout = 5 * time.Second
+
+// NewServer creates a new HTTP server listening on addr
+func NewServer(addr string) *Server {
+	s := &Server{
+		addr:         addr,
+		mux:          http.NewServeMux(),
+		controlMux:   http.NewServeMux(),
+		broker:       NewBroker(),
+		limiter:      newRateLimiter(DefaultRateLimit, DefaultRateBurst),
+		maxBodyBytes: DefaultMaxBodyBytes,
+		startedAt:    time.Now(),
+	}
+	s.httpServer = &http.Server{Handler: s.limit(s.checkHost(s.checkOrigin(s.authenticate(s.mux))))}
+	s.registerRoutes()
+	return s
+}
+
+// registerRoutes registers the routes of the HTTP server and the control socket.
+// The admin API is only on the control socket until an admin token is added.
+func (s *Server) registerRoutes() {
+	for _, mux := range []*http.ServeMux{s.mux, s.controlMux} {
+		mux.HandleFunc("GET /api/whoami", s.handleWhoAmI)
+		mux.HandleFunc("GET /api/sessions", s.handleListSessions)
+		mux.HandleFunc("GET /api/sessions/{id}", s.handleGetSession)
+		mux.HandleFunc("GET /api/sessions/{id}/stream", s.handleSessionStream)
+		mux.HandleFunc("GET /api/stream", s.handleStream)
+		mux.HandleFunc("GET /api/sessions/{id}/status", s.handleSessionStatus)
+		mux.HandleFunc("GET /api/archive", s.handleListArchive)
+		mux.HandleFunc("GET /api/metrics/history", s.handleMetricsHistory)
+		mux.HandleFunc("GET /api/server/limits", s.handleLimitStats)
+		mux.HandleFunc("GET /api/watchers", s.handleWatchers)
+		mux.HandleFunc("GET /api/health", s.handleHealth)
+		mux.HandleFunc("GET /api/capabilities", s.handleCapabilities)
+	}
+	s.mux.Handle("POST /api/hooks", s.localClients(http.HandlerFunc(s.handleHook)))
+	s.controlMux.HandleFunc("POST /api/hooks", s.handleHook)
+	s.controlMux.HandleFunc("POST /api/admin/{action}", s.handleAdmin)
+}
+
+// Broker returns the broker that distributes events to streaming clients
+func (s *Server) Broker() *Broker {
+	return s.broker
+}
+
+// Handle registers an additional handler on the server and the control socket
+func (s *Server) Handle(pattern string, handler http.Handler) {
+	s.mux.Handle(pattern, handler)
+	s.controlMux.Handle(pattern, handler)
+}
+
+// Addr returns the address the server is listening on
+func (s *Server) Addr() string {
+	if s.listener != nil {
+		return s.listener.Addr().String()
+	}
+	return s.addr
+}
+
+// SetTLS serves HTTPS with a certificate and key in PEM files
+func (s *Server) SetTLS(certFile, keyFile string) error {
+	cert, err := tls.LoadX509KeyPair(certFile, keyFile)
+	if err != nil {
+		return fmt.Errorf("failed to load TLS certificate: %w", err)
+	}
+	s.httpServer.TLSConfig = &tls.Config{
+		Certificates: []tls.Certificate{cert},
+		MinVersion:   tls.VersionTLS12,
+	}
+	return nil
+}
+
+// TLS reports whether the server serves HTTPS
+func (s *Server) TLS() bool {
+	return s.httpServer.TLSConfig != nil
+}
+
+// Start starts listening and serving in the background
+func (s *Server) Start() error {
+	listener, err := net.Listen("tcp", s.addr)
+	if err != nil {
+		return fmt.Errorf("failed to listen on %s: %w", s.addr, err)
+	}
+	if s.httpServer.TLSConfig != nil {
+		listener = tls.NewListener(listener, s.httpServer.TLSConfig)
+	}
+	s.listener = listener
+
+	go func() {
+		if err := s.httpServer.Serve(listener); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			logger.LogError("HTTP server error: %v", err)
+		}
+	}()
+	return nil
+}
+
+// Stop disconnects all streaming clients and stops the server, waiting up to
+// five seconds for other requests in flight to finish
+func (s *Server) Stop() {
+	// Streams end when the broker closes their subscriptions
+	s.broker.Close()
+	ctx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancel()
+	for _, srv := range []*http.Server{s.httpServer, s.controlServer} {
+		if srv == nil {
+			continue
+		}
+		if err := srv.Shutdown(ctx); err != nil {
+			logger.LogWarning("HTTP server did not shut down gracefully: %v", err)
+			srv.Close()
+		}
+	}
+	if s.controlPath != "" {
+		if err := os.Remove(s.controlPath); err != nil && !errors.Is(err, os.ErrNotExist) {
+			logger.LogWarning("Failed to remove the control socket: %v", err)
+		}
+	}
+}
@@ -0,0 +1,51 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/kazegusuri/claude-companion/event"
+)
+
+// SessionStore provides the sessions known to the companion
+type SessionStore interface {
+	ListSessions() []event.SessionState
+	GetSession(id string) (event.SessionState, bool)
+}
+
+// sessionsResponse is the response of the session list API
+type sessionsResponse struct {
+	Sessions []event.SessionState `json:"sessions"`
+}
+
+// SetSessionStore enables the session list API
+func (s *Server) SetSessionStore(store SessionStore) {
+	s.sessions = store
+}
+
+// handleListSessions returns the known sessions, most recently seen first
+func (s *Server) handleListSessions(w http.ResponseWriter, r *http.Request) {
+	resp := sessionsResponse{Sessions: []event.SessionState{}}
+	if s.sessions != nil {
+		resp.Sessions = append(resp.Sessions, s.sessions.ListSessions()...)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(resp)
+}
+
+// handleGetSession returns a session by its ID
+func (s *Server) handleGetSession(w http.ResponseWriter, r *http.Request) {
+	if s.sessions == nil {
+		http.Error(w, "session not found", http.StatusNotFound)
+		return
+	}
+	session, ok := s.sessions.GetSession(r.PathValue("id"))
+	if !ok {
+		http.Error(w, "session not found", http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(session)
+}
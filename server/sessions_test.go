@@ -0,0 +1,83 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/kazegusuri/claude-companion/event"
+)
+
+func TestSessions(t *testing.T) {
+	registry := event.NewSessionRegistry("")
+	registry.Observe(&event.NotificationEvent{SessionID: "s1", HookEventName: "SessionStart", Source: "startup", CWD: "/work"})
+	srv := NewServer("127.0.0.1:0")
+	srv.SetSessionStore(registry)
+	ts := httptest.NewServer(srv.mux)
+	defer ts.Close()
+
+	resp, err := http.Get(ts.URL + "/api/sessions")
+	if err != nil {
+		t.Fatalf("GET error = %v", err)
+	}
+	var list sessionsResponse
+	err = json.NewDecoder(resp.Body).Decode(&list)
+	resp.Body.Close()
+	if err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if len(list.Sessions) != 1 || list.Sessions[0].ID != "s1" || list.Sessions[0].CWD != "/work" {
+		t.Errorf("sessions = %+v, want s1 in /work", list.Sessions)
+	}
+
+	tests := []struct {
+		name       string
+		path       string
+		wantStatus int
+	}{
+		{name: "known", path: "/api/sessions/s1", wantStatus: http.StatusOK},
+		{name: "unknown", path: "/api/sessions/s2", wantStatus: http.StatusNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp, err := http.Get(ts.URL + tt.path)
+			if err != nil {
+				t.Fatalf("GET error = %v", err)
+			}
+			defer resp.Body.Close()
+			if resp.StatusCode != tt.wantStatus {
+				t.Fatalf("status = %d, want %d", resp.StatusCode, tt.wantStatus)
+			}
+			if tt.wantStatus != http.StatusOK {
+				return
+			}
+			var session event.SessionState
+			if err := json.NewDecoder(resp.Body).Decode(&session); err != nil {
+				t.Fatalf("failed to decode response: %v", err)
+			}
+			if session.Source != "startup" {
+				t.Errorf("source = %q, want startup", session.Source)
+			}
+		})
+	}
+}
+
+func TestSessionsWithoutStore(t *testing.T) {
+	srv := NewServer("127.0.0.1:0")
+	ts := httptest.NewServer(srv.mux)
+	defer ts.Close()
+
+	resp, err := http.Get(ts.URL + "/api/sessions")
+	if err != nil {
+		t.Fatalf("GET error = %v", err)
+	}
+	defer resp.Body.Close()
+	var list sessionsResponse
+	if err := json.NewDecoder(resp.Body).Decode(&list); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if list.Sessions == nil || len(list.Sessions) != 0 {
+		t.Errorf("sessions = %v, want an empty list", list.Sessions)
+	}
+}
@@ -0,0 +1,42 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+)
+
+// VoiceStatus reports the state of voice narration
+type VoiceStatus interface {
+	Enabled() bool
+	QueueSize() int
+}
+
+// SessionStatus is the companion state shown in a session's status line
+type SessionStatus struct {
+	Voice             string `json:"voice"`  // "on", "muted", or "off" without voice output
+	Queued            int    `json:"queued"` // Narrations waiting to be spoken
+	PermissionPending bool   `json:"permissionPending"`
+}
+
+// SetVoiceStatus sets the voice narrator reported by the status API
+func (s *Server) SetVoiceStatus(voice VoiceStatus) {
+	s.voice = voice
+}
+
+// handleSessionStatus returns the companion state for a session
+func (s *Server) handleSessionStatus(w http.ResponseWriter, r *http.Request) {
+	status := SessionStatus{
+		Voice:             "off",
+		PermissionPending: s.broker.PermissionPending(r.PathValue("id")),
+	}
+	if s.voice != nil {
+		status.Voice = "muted"
+		if s.voice.Enabled() {
+			status.Voice = "on"
+		}
+		status.Queued = s.voice.QueueSize()
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(status)
+}
@@ -0,0 +1,91 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/kazegusuri/claude-companion/event"
+)
+
+// fakeVoiceStatus is a fixed voice narrator state
+type fakeVoiceStatus struct {
+	enabled bool
+	queued  int
+}
+
+func (f *fakeVoiceStatus) Enabled() bool  { return f.enabled }
+func (f *fakeVoiceStatus) QueueSize() int { return f.queued }
+
+func TestBrokerPermissionPending(t *testing.T) {
+	permission := &event.NotificationEvent{SessionID: "s1", HookEventName: "Notification", Message: "Claude needs your permission to use Bash"}
+	idle := &event.NotificationEvent{SessionID: "s1", HookEventName: "Notification", Message: "Claude is waiting for your input"}
+	preToolUse := &event.NotificationEvent{SessionID: "s1", HookEventName: "PreToolUse", ToolName: "Bash"}
+	postToolUse := &event.NotificationEvent{SessionID: "s1", HookEventName: "PostToolUse", ToolName: "Bash"}
+	assistant := &event.AssistantMessage{BaseEvent: event.BaseEvent{TypeString: event.EventTypeAssistant, SessionID: "s1"}}
+
+	tests := []struct {
+		name   string
+		events []event.Event
+		want   bool
+	}{
+		{name: "no events", want: false},
+		{name: "permission request", events: []event.Event{permission}, want: true},
+		{name: "idle notification", events: []event.Event{idle}, want: false},
+		{name: "lagging transcript keeps it pending", events: []event.Event{preToolUse, permission, assistant}, want: true},
+		{name: "tool result resolves it", events: []event.Event{permission, newUserEvent("s1")}, want: false},
+		{name: "post tool use resolves it", events: []event.Event{permission, postToolUse}, want: false},
+		{name: "other session", events: []event.Event{permission, newUserEvent("s2")}, want: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			broker := NewBroker()
+			for _, ev := range tt.events {
+				broker.HandleEvent(ev, "")
+			}
+			if got := broker.PermissionPending("s1"); got != tt.want {
+				t.Errorf("PermissionPending() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSessionStatus(t *testing.T) {
+	tests := []struct {
+		name  string
+		voice VoiceStatus
+		want  SessionStatus
+	}{
+		{name: "without voice", want: SessionStatus{Voice: "off", PermissionPending: true}},
+		{name: "voice on", voice: &fakeVoiceStatus{enabled: true, queued: 2}, want: SessionStatus{Voice: "on", Queued: 2, PermissionPending: true}},
+		{name: "muted", voice: &fakeVoiceStatus{}, want: SessionStatus{Voice: "muted", PermissionPending: true}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := NewServer("127.0.0.1:0")
+			if tt.voice != nil {
+				srv.SetVoiceStatus(tt.voice)
+			}
+			srv.Broker().HandleEvent(&event.NotificationEvent{SessionID: "s1", HookEventName: "Notification", Message: "Claude needs your permission to use Bash"}, "")
+			ts := httptest.NewServer(srv.mux)
+			defer ts.Close()
+
+			resp, err := http.Get(ts.URL + "/api/sessions/s1/status")
+			if err != nil {
+				t.Fatalf("GET error = %v", err)
+			}
+			defer resp.Body.Close()
+
+			var got SessionStatus
+			if err := json.NewDecoder(resp.Body).Decode(&got); err != nil {
+				t.Fatalf("failed to decode response: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("status = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,170 @@
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// heartbeatInterval is the interval between heartbeat comments on idle streams
+var heartbeatInterval = 15 * time.Second
+
+// handleSessionStream streams events of a session as Server-Sent Events
+func (s *Server) handleSessionStream(w http.ResponseWriter, r *http.Request) {
+	sessionID := r.PathValue("id")
+	s.stream(w, r, StreamFilter{Sessions: []string{sessionID}}, "session "+sessionID)
+}
+
+// handleStream streams the events of the sessions and projects given with ?session=
+// and ?project=, which may be repeated, as Server-Sent Events. Without either, it
+// streams the events of every session. To change what it follows, a client
+// reconnects with other parameters and resumes from its last event.
+func (s *Server) handleStream(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	filter := StreamFilter{Sessions: splitValues(query["session"]), Projects: splitValues(query["project"])}
+	label := "all sessions"
+	if len(filter.Sessions) > 0 || len(filter.Projects) > 0 {
+		var parts []string
+		if len(filter.Sessions) > 0 {
+			parts = append(parts, "sessions "+strings.Join(filter.Sessions, ", "))
+		}
+		if len(filter.Projects) > 0 {
+			parts = append(parts, "projects "+strings.Join(filter.Projects, ", "))
+		}
+		label = strings.Join(parts, "; ")
+	}
+	s.stream(w, r, filter, label)
+}
+
+// splitValues splits comma-separated query values, dropping empty ones
+func splitValues(values []string) []string {
+	var result []string
+	for _, value := range values {
+		for _, v := range strings.Split(value, ",") {
+			if v = strings.TrimSpace(v); v != "" {
+				result = append(result, v)
+			}
+		}
+	}
+	return result
+}
+
+// stream streams the events that filter selects as Server-Sent Events. label names
+// what is streamed in the first comment.
+func (s *Server) stream(w http.ResponseWriter, r *http.Request, filter StreamFilter, label string) {
+	format := r.URL.Query().Get("format")
+	if format == "" {
+		format = "json"
+	}
+	if format != "json" && format != "text" {
+		http.Error(w, fmt.Sprintf("unsupported format: %s", format), http.StatusBadRequest)
+		return
+	}
+
+	// Only stream events at or above the requested priority
+	var minPriority int
+	if value := r.URL.Query().Get("minPriority"); value != "" {
+		p, err := strconv.Atoi(value)
+		if err != nil {
+			http.Error(w, fmt.Sprintf("invalid minimum priority: %s", value), http.StatusBadRequest)
+			return
+		}
+		minPriority = p
+	}
+
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "streaming unsupported", http.StatusInternalServerError)
+		return
+	}
+
+	// Resume from the last received event if requested
+	lastEventID := r.Header.Get("Last-Event-ID")
+	if lastEventID == "" {
+		lastEventID = r.URL.Query().Get("lastEventId")
+	}
+	var lastID int64
+	if lastEventID != "" {
+		id, err := strconv.ParseInt(lastEventID, 10, 64)
+		if err != nil {
+			http.Error(w, fmt.Sprintf("invalid last event id: %s", lastEventID), http.StatusBadRequest)
+			return
+		}
+		lastID = id
+	}
+
+	sub, backlog := s.broker.SubscribeFilter(filter, lastID)
+	defer s.broker.Unsubscribe(sub)
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.WriteHeader(http.StatusOK)
+	fmt.Fprintf(w, ": streaming %s\n\n", label)
+	flusher.Flush()
+
+	for _, msg := range backlog {
+		if msg.Priority < minPriority {
+			continue
+		}
+		if err := writeStreamMessage(w, msg, format); err != nil {
+			return
+		}
+	}
+	flusher.Flush()
+
+	heartbeat := time.NewTicker(heartbeatInterval)
+	defer heartbeat.Stop()
+
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case msg, ok := <-sub.Messages():
+			if !ok {
+				return
+			}
+			if msg.Priority < minPriority {
+				continue
+			}
+			if err := writeStreamMessage(w, msg, format); err != nil {
+				return
+			}
+			flusher.Flush()
+		case <-heartbeat.C:
+			if _, err := fmt.Fprint(w, ": heartbeat\n\n"); err != nil {
+				return
+			}
+			flusher.Flush()
+		}
+	}
+}
+
+// writeStreamMessage writes a single SSE message
+func writeStreamMessage(w http.ResponseWriter, msg *StreamMessage, format string) error {
+	var data string
+	switch format {
+	case "text":
+		data = strings.TrimRight(msg.Text, "\n")
+	default:
+		encoded, err := json.Marshal(msg)
+		if err != nil {
+			return err
+		}
+		data = string(encoded)
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "id: %d\n", msg.ID)
+	fmt.Fprintf(&b, "event: %s\n", msg.Type)
+	for _, line := range strings.Split(data, "\n") {
+		fmt.Fprintf(&b, "data: %s\n", line)
+	}
+	b.WriteString("\n")
+
+	_, err := fmt.Fprint(w, b.String())
+	return err
+}
@@ -0,0 +1,365 @@
+package server
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/kazegusuri/claude-companion/event"
+)
+
+// readSSEMessages reads SSE messages until n data messages have been received
+func readSSEMessages(t *testing.T, reader *bufio.Reader, n int) []map[string]string {
+	t.Helper()
+
+	var messages []map[string]string
+	current := map[string]string{}
+	for len(messages) < n {
+		line, err := reader.ReadString('\n')
+		if err != nil {
+			t.Fatalf("failed to read stream: %v", err)
+		}
+		line = strings.TrimRight(line, "\n")
+		switch {
+		case line == "":
+			if len(current) > 0 {
+				messages = append(messages, current)
+				current = map[string]string{}
+			}
+		case strings.HasPrefix(line, ":"):
+			// comment
+		default:
+			parts := strings.SplitN(line, ": ", 2)
+			if len(parts) == 2 {
+				if prev, ok := current[parts[0]]; ok {
+					current[parts[0]] = prev + "\n" + parts[1]
+				} else {
+					current[parts[0]] = parts[1]
+				}
+			}
+		}
+	}
+	return messages
+}
+
+func newUserEvent(sessionID string) event.Event {
+	return &event.UserMessage{
+		BaseEvent: event.BaseEvent{
+			TypeString: event.EventTypeUser,
+			SessionID:  sessionID,
+			Session:    &event.Session{Project: "proj", Session: sessionID},
+			Timestamp:  time.Now(),
+		},
+	}
+}
+
+func TestSessionStream(t *testing.T) {
+	srv := NewServer("127.0.0.1:0")
+	ts := httptest.NewServer(srv.mux)
+	defer ts.Close()
+	defer srv.broker.Close()
+
+	// Publish an event before connecting so it can be resumed
+	srv.broker.HandleEvent(newUserEvent("s1"), "first\n")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	req, _ := http.NewRequestWithContext(ctx, "GET", ts.URL+"/api/sessions/s1/stream?format=text", nil)
+	req.Header.Set("Last-Event-ID", "0")
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+
+	if ct := resp.Header.Get("Content-Type"); ct != "text/event-stream" {
+		t.Errorf("Content-Type = %q, want text/event-stream", ct)
+	}
+
+	reader := bufio.NewReader(resp.Body)
+
+	// Wait until the subscription is registered, then publish live events
+	go func() {
+		time.Sleep(100 * time.Millisecond)
+		srv.broker.HandleEvent(newUserEvent("other"), "ignored\n")
+		srv.broker.HandleEvent(newUserEvent("s1"), "second\nline\n")
+	}()
+
+	messages := readSSEMessages(t, reader, 1)
+	if messages[0]["data"] != "second\nline" {
+		t.Errorf("data = %q, want %q", messages[0]["data"], "second\nline")
+	}
+	if messages[0]["event"] != "user" {
+		t.Errorf("event = %q, want user", messages[0]["event"])
+	}
+	if messages[0]["id"] != "3" {
+		t.Errorf("id = %q, want 3", messages[0]["id"])
+	}
+}
+
+func TestSessionStreamResume(t *testing.T) {
+	srv := NewServer("127.0.0.1:0")
+	ts := httptest.NewServer(srv.mux)
+	defer ts.Close()
+	defer srv.broker.Close()
+
+	for i := 0; i < 3; i++ {
+		srv.broker.HandleEvent(newUserEvent("s1"), "event\n")
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	req, _ := http.NewRequestWithContext(ctx, "GET", ts.URL+"/api/sessions/s1/stream?lastEventId=1", nil)
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+
+	messages := readSSEMessages(t, bufio.NewReader(resp.Body), 2)
+	if messages[0]["id"] != "2" || messages[1]["id"] != "3" {
+		t.Errorf("resumed ids = %q, %q, want 2, 3", messages[0]["id"], messages[1]["id"])
+	}
+	if !strings.Contains(messages[0]["data"], `"sessionId":"s1"`) {
+		t.Errorf("json data missing session id: %s", messages[0]["data"])
+	}
+}
+
+func TestSessionStreamInvalidFormat(t *testing.T) {
+	srv := NewServer("127.0.0.1:0")
+	ts := httptest.NewServer(srv.mux)
+	defer ts.Close()
+
+	resp, err := http.Get(ts.URL + "/api/sessions/s1/stream?format=xml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusBadRequest {
+		t.Errorf("status = %d, want %d", resp.StatusCode, http.StatusBadRequest)
+	}
+}
+
+func TestSessionStreamMinPriority(t *testing.T) {
+	srv := NewServer("127.0.0.1:0")
+	ts := httptest.NewServer(srv.mux)
+	defer ts.Close()
+	defer srv.broker.Close()
+
+	low := newUserEvent("s1")
+	urgent := &event.UserMessage{BaseEvent: *event.BaseOf(newUserEvent("s1"))}
+	urgent.Priority = 6
+	srv.broker.HandleEvent(low, "before\n")
+	srv.broker.HandleEvent(low, "routine\n")
+	srv.broker.HandleEvent(urgent, "urgent\n")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	req, _ := http.NewRequestWithContext(ctx, "GET", ts.URL+"/api/sessions/s1/stream?format=text&minPriority=5", nil)
+	req.Header.Set("Last-Event-ID", "1")
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+
+	reader := bufio.NewReader(resp.Body)
+	go func() {
+		time.Sleep(100 * time.Millisecond)
+		srv.broker.HandleEvent(low, "routine again\n")
+		srv.broker.HandleEvent(urgent, "urgent again\n")
+	}()
+
+	// The routine events in the backlog and the live stream are filtered out
+	messages := readSSEMessages(t, reader, 2)
+	if messages[0]["data"] != "urgent" || messages[1]["data"] != "urgent again" {
+		t.Errorf("data = %q, %q, want urgent, urgent again", messages[0]["data"], messages[1]["data"])
+	}
+}
+
+func TestSessionStreamInvalidMinPriority(t *testing.T) {
+	srv := NewServer("127.0.0.1:0")
+	ts := httptest.NewServer(srv.mux)
+	defer ts.Close()
+
+	resp, err := http.Get(ts.URL + "/api/sessions/s1/stream?minPriority=high")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusBadRequest {
+		t.Errorf("status = %d, want %d", resp.StatusCode, http.StatusBadRequest)
+	}
+}
+
+func TestBrokerHighlight(t *testing.T) {
+	broker := NewBroker()
+	sub, _ := broker.Subscribe("s1", 0)
+	defer broker.Unsubscribe(sub)
+
+	broker.HandleEvent(newUserEvent("s1"), "result\n")
+	broker.HandleEvent(&event.ToolSLABreachMessage{BaseEvent: event.BaseEvent{SessionID: "s1"}, ToolName: "Bash"}, "breach\n")
+
+	for _, want := range []bool{false, true} {
+		msg := <-sub.Messages()
+		if msg.Highlight != want {
+			t.Errorf("Highlight of %s = %v, want %v", msg.Type, msg.Highlight, want)
+		}
+	}
+}
+
+func TestBrokerNarrations(t *testing.T) {
+	broker := NewBroker()
+	sub, _ := broker.Subscribe("s1", 0)
+	defer broker.Unsubscribe(sub)
+
+	ev := newUserEvent("s1").(*event.UserMessage)
+	ev.Narrations = []string{"テストを実行します"}
+	broker.HandleEvent(ev, "result\n")
+
+	msg := <-sub.Messages()
+	if len(msg.Narrations) != 1 || msg.Narrations[0] != "テストを実行します" {
+		t.Errorf("Narrations = %v, want [テストを実行します]", msg.Narrations)
+	}
+}
+
+func TestBrokerRawEvents(t *testing.T) {
+	tests := []struct {
+		name      string
+		rawEvents bool
+	}{
+		{name: "default", rawEvents: false},
+		{name: "raw events", rawEvents: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			broker := NewBroker()
+			broker.SetRawEvents(tt.rawEvents)
+			sub, _ := broker.Subscribe("s1", 0)
+			defer broker.Unsubscribe(sub)
+
+			ev := newUserEvent("s1")
+			broker.HandleEvent(ev, "result\n")
+
+			msg := <-sub.Messages()
+			if got := msg.Event != nil; got != tt.rawEvents {
+				t.Errorf("Event set = %v, want %v", got, tt.rawEvents)
+			}
+			encoded, err := json.Marshal(msg)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var fields map[string]json.RawMessage
+			if err := json.Unmarshal(encoded, &fields); err != nil {
+				t.Fatal(err)
+			}
+			if _, ok := fields["event"]; ok != tt.rawEvents {
+				t.Errorf("JSON has event = %v, want %v: %s", ok, tt.rawEvents, encoded)
+			}
+		})
+	}
+}
+
+func TestStream_Subscriptions(t *testing.T) {
+	srv := NewServer("127.0.0.1:0")
+	ts := httptest.NewServer(srv.mux)
+	defer ts.Close()
+	defer srv.broker.Close()
+
+	web := newUserEvent("w1").(*event.UserMessage)
+	web.Session.Project = "web"
+	srv.broker.HandleEvent(newUserEvent("s1"), "s1 first\n")
+	srv.broker.HandleEvent(newUserEvent("s1"), "s1 before\n")
+	srv.broker.HandleEvent(newUserEvent("s2"), "s2 before\n")
+	srv.broker.HandleEvent(web, "w1 before\n")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// Resume the sessions and projects subscribed to across their histories
+	req, _ := http.NewRequestWithContext(ctx, "GET", ts.URL+"/api/stream?format=text&session=s1,s9&project=web&lastEventId=1", nil)
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	reader := bufio.NewReader(resp.Body)
+
+	go func() {
+		time.Sleep(100 * time.Millisecond)
+		srv.broker.HandleEvent(newUserEvent("s2"), "s2 live\n")
+		srv.broker.HandleEvent(web, "w1 live\n")
+		srv.broker.HandleEvent(newUserEvent("s1"), "s1 live\n")
+	}()
+
+	var got []string
+	for _, msg := range readSSEMessages(t, reader, 4) {
+		got = append(got, msg["id"]+" "+msg["data"])
+	}
+	want := []string{"2 s1 before", "4 w1 before", "6 w1 live", "7 s1 live"}
+	if strings.Join(got, "|") != strings.Join(want, "|") {
+		t.Errorf("streamed %q, want %q", got, want)
+	}
+}
+
+func TestStream_AllSessions(t *testing.T) {
+	broker := NewBroker()
+	defer broker.Close()
+
+	sub, _ := broker.SubscribeFilter(StreamFilter{}, 0)
+	broker.HandleEvent(newUserEvent("s1"), "one\n")
+	broker.HandleEvent(newUserEvent("s2"), "two\n")
+	for _, want := range []string{"s1", "s2"} {
+		if msg := <-sub.Messages(); msg.SessionID != want {
+			t.Errorf("session = %q, want %q", msg.SessionID, want)
+		}
+	}
+}
+
+func TestBrokerPrune(t *testing.T) {
+	broker := NewBroker()
+	defer broker.Close()
+	now := time.Date(2025, 1, 1, 10, 0, 0, 0, time.UTC)
+	broker.now = func() time.Time { return now }
+
+	permission := &event.NotificationEvent{HookEventName: "Notification", SessionID: "ended", Message: "Claude needs your permission to use Bash"}
+	broker.HandleEvent(permission, "permission\n")
+	broker.HandleEvent(newUserEvent("idle"), "idle\n")
+	broker.HandleEvent(&event.NotificationEvent{HookEventName: "SessionEnd", SessionID: "ended"}, "end\n")
+
+	// Ended sessions are kept a minute for clients to resume
+	now = now.Add(30 * time.Second)
+	broker.HandleEvent(newUserEvent("active"), "active\n")
+	if len(broker.sessions) != 3 {
+		t.Errorf("kept %d sessions, want 3", len(broker.sessions))
+	}
+
+	now = now.Add(2 * time.Minute)
+	broker.HandleEvent(newUserEvent("active"), "active\n")
+	if _, ok := broker.sessions["ended"]; ok {
+		t.Error("ended session kept after a minute")
+	}
+	if broker.PermissionPending("ended") {
+		t.Error("ended session still waits for a permission decision")
+	}
+	if _, ok := broker.sessions["idle"]; !ok {
+		t.Error("idle session dropped before an hour")
+	}
+
+	now = now.Add(time.Hour)
+	broker.HandleEvent(newUserEvent("active"), "active\n")
+	if _, ok := broker.sessions["idle"]; ok {
+		t.Error("idle session kept after an hour")
+	}
+	if len(broker.sessions["active"].history) != 3 {
+		t.Errorf("active session has %d messages, want 3", len(broker.sessions["active"].history))
+	}
+}
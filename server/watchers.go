@@ -0,0 +1,28 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/kazegusuri/claude-companion/event"
+)
+
+// WatcherStats reports how the transcripts are watched and how far behind reading them is
+type WatcherStats interface {
+	Status() event.WatchStatus
+}
+
+// SetWatcherStats enables the watcher status API
+func (s *Server) SetWatcherStats(stats WatcherStats) {
+	s.watchers = stats
+}
+
+// handleWatchers returns the watch mode of each projects root and the read lag of each transcript
+func (s *Server) handleWatchers(w http.ResponseWriter, r *http.Request) {
+	if s.watchers == nil {
+		http.Error(w, "no transcripts are watched", http.StatusNotFound)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(s.watchers.Status())
+}
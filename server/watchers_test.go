@@ -0,0 +1,50 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/kazegusuri/claude-companion/event"
+)
+
+// fakeWatcherStats reports a fixed watcher status
+type fakeWatcherStats event.WatchStatus
+
+func (f fakeWatcherStats) Status() event.WatchStatus {
+	return event.WatchStatus(f)
+}
+
+func TestServer_HandleWatchers(t *testing.T) {
+	srv := NewServer("127.0.0.1:0")
+	ts := httptest.NewServer(srv.httpServer.Handler)
+	defer ts.Close()
+
+	resp, err := http.Get(ts.URL + "/api/watchers")
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusNotFound {
+		t.Errorf("status without watchers = %d, want %d", resp.StatusCode, http.StatusNotFound)
+	}
+
+	srv.SetWatcherStats(fakeWatcherStats{
+		Roots:    []event.RootWatchStatus{{Path: "/home/me/.claude/projects", Mode: event.WatchModePoll}},
+		Files:    []event.FileWatchStatus{{Path: "/home/me/.claude/projects/app/s1.jsonl", Offset: 100, Size: 400, LagBytes: 300}},
+		LagBytes: 300,
+	})
+	resp, err = http.Get(ts.URL + "/api/watchers")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	var got event.WatchStatus
+	if err := json.NewDecoder(resp.Body).Decode(&got); err != nil {
+		t.Fatal(err)
+	}
+	if got.LagBytes != 300 || len(got.Roots) != 1 || got.Roots[0].Mode != "poll" || len(got.Files) != 1 {
+		t.Errorf("status = %+v", got)
+	}
+}
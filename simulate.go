@@ -0,0 +1,93 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/kazegusuri/claude-companion/demo"
+	"github.com/kazegusuri/claude-companion/logger"
+	"github.com/kazegusuri/claude-companion/narrator"
+	"github.com/spf13/pflag"
+)
+
+// runSimulate generates a random session and plays it through the companion like demo,
+// or writes it to files with --output, to test narrator configs, voice setups and
+// server clients without Claude Code. Arguments after -- are passed to the companion.
+func runSimulate(args []string) int {
+	fs := pflag.NewFlagSet("simulate", pflag.ContinueOnError)
+	var seed int64
+	var prompts int
+	var speed float64
+	var langCode string
+	var output string
+	fs.Int64Var(&seed, "seed", 0, "Seed of the generated session; the same seed generates the same session (0 picks one)")
+	fs.IntVar(&prompts, "prompts", 3, "Number of user prompts in the session")
+	fs.Float64Var(&speed, "speed", 1, "Playback speed multiplier (2 plays the session twice as fast)")
+	fs.StringVar(&langCode, "lang", "ja", "Language of the session and its narration: ja or en")
+	fs.StringVar(&output, "output", "", "Write the transcript and notification log to this directory instead of playing them")
+	if err := fs.Parse(args); err != nil {
+		if err == pflag.ErrHelp {
+			return 0
+		}
+		return 2
+	}
+	lang, err := narrator.ParseLanguage(langCode)
+	if err != nil {
+		logger.LogError("%v", err)
+		return 2
+	}
+	if speed <= 0 || prompts <= 0 {
+		logger.LogError("--speed and --prompts must be positive")
+		return 2
+	}
+	if seed == 0 {
+		seed = time.Now().UnixNano()
+	}
+	opts := demo.GenerateOptions{Seed: seed, Prompts: prompts}
+	logger.LogInfo("Simulating a session with seed %d", seed)
+
+	if output != "" {
+		if err := writeSimulation(output, lang, opts); err != nil {
+			logger.LogError("%v", err)
+			return 1
+		}
+		return 0
+	}
+	return playWithCompanion("simulate", lang, speed, fs.Args(), func(projectDir string) *demo.Session {
+		return demo.Generate(demo.DemoCWD, projectDir, lang, opts)
+	})
+}
+
+// writeSimulation writes a generated session under dir in the layout of ~/.claude/projects,
+// with its hooks in dir/notification.log
+func writeSimulation(dir string, lang narrator.Language, opts demo.GenerateOptions) error {
+	projectDir := filepath.Join(dir, "projects", demo.ProjectName(demo.DemoCWD))
+	if err := os.MkdirAll(projectDir, 0o755); err != nil {
+		return fmt.Errorf("failed to create the output directory: %w", err)
+	}
+	session := demo.Generate(demo.DemoCWD, projectDir, lang, opts)
+
+	transcript, err := os.Create(session.TranscriptPath())
+	if err != nil {
+		return fmt.Errorf("failed to create the transcript: %w", err)
+	}
+	defer transcript.Close()
+	notificationLog := filepath.Join(dir, "notification.log")
+	notifications, err := os.Create(notificationLog)
+	if err != nil {
+		return fmt.Errorf("failed to create the notification log: %w", err)
+	}
+	defer notifications.Close()
+
+	// Written as fast as possible, so every line has about the same timestamp
+	if err := demo.Play(context.Background(), session.Steps, transcript, notifications, 1e9); err != nil {
+		return err
+	}
+	fmt.Printf("Transcript:       %s\n", session.TranscriptPath())
+	fmt.Printf("Notification log: %s\n", notificationLog)
+	fmt.Printf("Replay with:      claude-companion --head --file %s\n", session.TranscriptPath())
+	return nil
+}
@@ -0,0 +1,182 @@
+package speech
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/kazegusuri/claude-companion/logger"
+)
+
+// audioCacheExt is the extension of cached audio files
+const audioCacheExt = ".wav"
+
+// AudioCache stores synthesized audio in a directory, one file per hash of the text,
+// speaker and voice parameters it was synthesized with. When the files grow beyond the
+// size limit, the least recently used ones are removed.
+type AudioCache struct {
+	dir      string
+	maxBytes int64
+	mu       sync.Mutex
+	entries  map[string]*audioCacheEntry // key: hash
+	size     int64                       // Total size of the entries
+	now      func() time.Time
+}
+
+// audioCacheEntry is a cached audio file
+type audioCacheEntry struct {
+	size     int64
+	lastUsed time.Time
+}
+
+// NewAudioCache opens the cache in dir, creating it if needed, with the files already
+// in it ordered by their modification time
+func NewAudioCache(dir string, maxBytes int64) (*AudioCache, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create audio cache: %w", err)
+	}
+	files, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read audio cache: %w", err)
+	}
+
+	c := &AudioCache{dir: dir, maxBytes: maxBytes, entries: make(map[string]*audioCacheEntry), now: time.Now}
+	for _, file := range files {
+		key, ok := strings.CutSuffix(file.Name(), audioCacheExt)
+		if !ok || file.IsDir() {
+			continue
+		}
+		info, err := file.Info()
+		if err != nil {
+			continue
+		}
+		c.entries[key] = &audioCacheEntry{size: info.Size(), lastUsed: info.ModTime()}
+		c.size += info.Size()
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.evict()
+	return c, nil
+}
+
+// AudioCacheKey returns the cache key of text synthesized by an engine with a speaker
+// and voice parameters
+func AudioCacheKey(engine, text string, speakerID int, params map[string]float64) string {
+	names := make([]string, 0, len(params))
+	for name := range params {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	h := sha256.New()
+	fmt.Fprintf(h, "%s\x00%d\x00%s", engine, speakerID, text)
+	for _, name := range names {
+		fmt.Fprintf(h, "\x00%s=%g", name, params[name])
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// Get returns the cached audio of a key
+func (c *AudioCache) Get(key string) ([]byte, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	entry, ok := c.entries[key]
+	if !ok {
+		return nil, false
+	}
+	data, err := os.ReadFile(c.path(key))
+	if err != nil {
+		// Removed behind our back; forget it
+		c.remove(key)
+		return nil, false
+	}
+	entry.lastUsed = c.now()
+	// Keep the order of use across restarts
+	_ = os.Chtimes(c.path(key), entry.lastUsed, entry.lastUsed)
+	return data, true
+}
+
+// Put caches the audio of a key, removing the least recently used files if the cache
+// grows beyond its limit
+func (c *AudioCache) Put(key string, data []byte) error {
+	if int64(len(data)) > c.maxBytes {
+		return nil // Would evict everything, including itself
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	// Write to a temporary file first so a crash does not leave a truncated file
+	tmp, err := os.CreateTemp(c.dir, "tmp-*")
+	if err != nil {
+		return fmt.Errorf("failed to cache audio: %w", err)
+	}
+	_, err = tmp.Write(data)
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Rename(tmp.Name(), c.path(key))
+	}
+	if err != nil {
+		os.Remove(tmp.Name())
+		return fmt.Errorf("failed to cache audio: %w", err)
+	}
+
+	if old, ok := c.entries[key]; ok {
+		c.size -= old.size
+	}
+	c.entries[key] = &audioCacheEntry{size: int64(len(data)), lastUsed: c.now()}
+	c.size += int64(len(data))
+	c.evict()
+	return nil
+}
+
+// Size returns the total size of the cached audio in bytes
+func (c *AudioCache) Size() int64 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.size
+}
+
+// evict removes the least recently used files until the cache fits its limit; c.mu
+// must be held
+func (c *AudioCache) evict() {
+	if c.size <= c.maxBytes {
+		return
+	}
+	keys := make([]string, 0, len(c.entries))
+	for key := range c.entries {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		return c.entries[keys[i]].lastUsed.Before(c.entries[keys[j]].lastUsed)
+	})
+	for _, key := range keys {
+		if c.size <= c.maxBytes {
+			break
+		}
+		if err := os.Remove(c.path(key)); err != nil && !os.IsNotExist(err) {
+			logger.LogWarning("Failed to remove cached audio: %v", err)
+		}
+		c.remove(key)
+	}
+}
+
+// remove forgets an entry; c.mu must be held
+func (c *AudioCache) remove(key string) {
+	if entry, ok := c.entries[key]; ok {
+		c.size -= entry.size
+		delete(c.entries, key)
+	}
+}
+
+// path returns the file of a key
+func (c *AudioCache) path(key string) string {
+	return filepath.Join(c.dir, key+audioCacheExt)
+}
@@ -0,0 +1,97 @@
+package speech
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestAudioCache_EvictsLeastRecentlyUsed(t *testing.T) {
+	dir := t.TempDir()
+	c, err := NewAudioCache(dir, 10)
+	if err != nil {
+		t.Fatalf("NewAudioCache() error = %v", err)
+	}
+	now := time.Date(2025, 1, 26, 10, 0, 0, 0, time.UTC)
+	c.now = func() time.Time { now = now.Add(time.Second); return now }
+
+	for _, key := range []string{"a", "b"} {
+		if err := c.Put(key, []byte("12345")); err != nil {
+			t.Fatalf("Put(%s) error = %v", key, err)
+		}
+	}
+	if _, ok := c.Get("a"); !ok { // a is now used more recently than b
+		t.Fatal("Get(a) missed")
+	}
+	if err := c.Put("c", []byte("123")); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := c.Get("b"); ok {
+		t.Error("Get(b) hit, want the least recently used entry evicted")
+	}
+	if data, ok := c.Get("a"); !ok || string(data) != "12345" {
+		t.Errorf("Get(a) = %q, %v; want the cached audio", data, ok)
+	}
+	if got := c.Size(); got != 8 {
+		t.Errorf("Size() = %d, want 8", got)
+	}
+
+	// The files are found again after a restart
+	reopened, err := NewAudioCache(dir, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := reopened.Get("c"); !ok {
+		t.Error("Get(c) after reopening missed")
+	}
+}
+
+func TestVoiceVox_SynthesizeUsesCache(t *testing.T) {
+	var syntheses atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/audio_query":
+			w.Write([]byte(`{}`))
+		case "/synthesis":
+			syntheses.Add(1)
+			w.Write(GetSilentWAV())
+		}
+	}))
+	defer server.Close()
+
+	cache, err := NewAudioCache(t.TempDir(), 1<<20)
+	if err != nil {
+		t.Fatal(err)
+	}
+	v := NewVoiceVox(server.URL, 1)
+	v.SetCache(cache)
+
+	ctx := context.Background()
+	for i := 0; i < 2; i++ {
+		audio, err := v.Synthesize(ctx, "テストを実行します")
+		if err != nil {
+			t.Fatalf("Synthesize() error = %v", err)
+		}
+		if !bytes.Equal(audio, GetSilentWAV()) {
+			t.Errorf("Synthesize() returned %d bytes, want the synthesized audio", len(audio))
+		}
+	}
+	if got := syntheses.Load(); got != 1 {
+		t.Errorf("synthesized %d times, want 1 with the second served from the cache", got)
+	}
+
+	// Other voice parameters are another entry
+	if err := v.SetVoiceParameter("speed", 1.0); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := v.Synthesize(ctx, "テストを実行します"); err != nil {
+		t.Fatal(err)
+	}
+	if got := syntheses.Load(); got != 2 {
+		t.Errorf("synthesized %d times, want 2 after changing the speed", got)
+	}
+}
@@ -0,0 +1,109 @@
+package speech
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+	"time"
+
+	"github.com/kazegusuri/claude-companion/logger"
+)
+
+// filePlaceholder is replaced with the path of a WAV file in a custom player command;
+// without it the audio is written to the command's stdin
+const filePlaceholder = "{file}"
+
+// playerPresets are the arguments of the players known by name, reading WAV from stdin
+var playerPresets = map[string][]string{
+	"mpv":    {"mpv", "--no-terminal", "--no-video", "--really-quiet", "-"},
+	"ffplay": {"ffplay", "-nodisp", "-autoexit", "-loglevel", "quiet", "-"},
+	"aplay":  {"aplay", "-q", "-"},
+	"paplay": {"paplay"},
+}
+
+// CommandPlayer implements Player with an external command, such as mpv or ffplay
+type CommandPlayer struct {
+	args   []string // Command and arguments of a preset
+	script string   // Shell command of a custom player
+}
+
+// NewPlayer returns the player for a --audio-player value: "" or "native" picks a
+// player for the OS, mpv, ffplay, aplay and paplay run that player, and anything else
+// is a shell command that reads WAV from stdin, or from the file in place of {file}.
+// It fails if the player's command is not installed.
+func NewPlayer(spec string) (Player, error) {
+	spec = strings.TrimSpace(spec)
+	if spec == "" || spec == "native" {
+		return NewNativePlayer(), nil
+	}
+	if args, ok := playerPresets[spec]; ok {
+		if _, err := exec.LookPath(args[0]); err != nil {
+			return nil, fmt.Errorf("audio player %s is not installed: %w", spec, err)
+		}
+		return &CommandPlayer{args: args}, nil
+	}
+
+	command := strings.Fields(spec)[0]
+	if _, err := exec.LookPath(command); err != nil {
+		return nil, fmt.Errorf("audio player command %s is not installed: %w", command, err)
+	}
+	return &CommandPlayer{script: spec}, nil
+}
+
+// Play plays audio data by running the command
+func (p *CommandPlayer) Play(audioData []byte, meta *AudioMeta) error {
+	if meta != nil {
+		logger.LogDebug("Playing %v of audio: %s", meta.Duration.Round(time.Millisecond), meta.NormalizedText)
+	}
+
+	var cmd *exec.Cmd
+	if p.script == "" {
+		cmd = exec.Command(p.args[0], p.args[1:]...)
+		cmd.Stdin = bytes.NewReader(audioData)
+	} else if strings.Contains(p.script, filePlaceholder) {
+		tmpFile, err := os.CreateTemp("", "audio_*.wav")
+		if err != nil {
+			return err
+		}
+		defer os.Remove(tmpFile.Name())
+		if _, err := tmpFile.Write(audioData); err != nil {
+			tmpFile.Close()
+			return err
+		}
+		tmpFile.Close()
+		cmd = exec.Command("sh", "-c", strings.ReplaceAll(p.script, filePlaceholder, shellQuote(tmpFile.Name())))
+	} else {
+		cmd = exec.Command("sh", "-c", p.script)
+		cmd.Stdin = bytes.NewReader(audioData)
+	}
+
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return fmt.Errorf("audio player failed: %w: %s", err, msg)
+		}
+		return fmt.Errorf("audio player failed: %w", err)
+	}
+	return nil
+}
+
+// TestPlay tests if the player is working by playing a silent WAV
+func (p *CommandPlayer) TestPlay() error {
+	meta := &AudioMeta{
+		OriginalText:   "test",
+		NormalizedText: "test",
+	}
+	if format, err := ParseWAV(silentWAV); err == nil {
+		meta.Duration = format.Duration
+		meta.Format = format
+	}
+	return p.Play(silentWAV, meta)
+}
+
+// shellQuote quotes a string as a single shell word
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
@@ -0,0 +1,55 @@
+package speech
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestNewPlayer(t *testing.T) {
+	if _, err := NewPlayer("no-such-player-command --flag"); err == nil {
+		t.Error("NewPlayer() with a missing command succeeded, want an error")
+	}
+	player, err := NewPlayer("native")
+	if err != nil {
+		t.Fatalf("NewPlayer(native) error = %v", err)
+	}
+	if _, ok := player.(*NativePlayer); !ok {
+		t.Errorf("NewPlayer(native) = %T, want *NativePlayer", player)
+	}
+}
+
+func TestCommandPlayer_Play(t *testing.T) {
+	dir := t.TempDir()
+	tests := []struct {
+		name   string
+		script string
+	}{
+		{name: "stdin", script: "cat > " + shellQuote(filepath.Join(dir, "stdin.wav"))},
+		{name: "file", script: "cp {file} " + shellQuote(filepath.Join(dir, "file.wav"))},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			player, err := NewPlayer(tt.script)
+			if err != nil {
+				t.Fatalf("NewPlayer(%q) error = %v", tt.script, err)
+			}
+			if err := player.TestPlay(); err != nil {
+				t.Fatalf("TestPlay() error = %v", err)
+			}
+			got, err := os.ReadFile(filepath.Join(dir, tt.name+".wav"))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !bytes.Equal(got, GetSilentWAV()) {
+				t.Errorf("player received %d bytes, want the %d bytes of the silent WAV", len(got), len(GetSilentWAV()))
+			}
+		})
+	}
+
+	player, _ := NewPlayer("sh -c 'echo broken >&2; exit 1'")
+	if err := player.TestPlay(); err == nil {
+		t.Error("TestPlay() with a failing command succeeded, want an error")
+	}
+}
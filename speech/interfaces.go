@@ -14,6 +14,19 @@ type Synthesizer interface {
 	SetVoiceParameters(speed, pitch, volume, intonation float64)
 }
 
+// SpeakerSynthesizer is implemented by synthesizers that can switch speakers per request
+type SpeakerSynthesizer interface {
+	// SynthesizeWithSpeaker converts text to audio data (WAV format) using the given speaker
+	SynthesizeWithSpeaker(ctx context.Context, text string, speakerID int) ([]byte, error)
+}
+
+// ParameterSynthesizer is implemented by synthesizers that can change voice parameters per request
+type ParameterSynthesizer interface {
+	// SynthesizeWithParameters converts text to audio data (WAV format) with params in place
+	// of the voice parameters of the same names. A nil speakerID uses the default speaker.
+	SynthesizeWithParameters(ctx context.Context, text string, speakerID *int, params map[string]float64) ([]byte, error)
+}
+
 // Player interface defines the contract for playing audio data
 type Player interface {
 	// Play plays audio data (WAV format) with metadata
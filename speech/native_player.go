@@ -6,6 +6,9 @@ import (
 	"os"
 	"os/exec"
 	"runtime"
+	"time"
+
+	"github.com/kazegusuri/claude-companion/logger"
 )
 
 // NativePlayer implements Player interface for different platforms
@@ -18,10 +21,12 @@ func NewNativePlayer() *NativePlayer {
 
 // Play plays audio data using system-specific commands
 func (p *NativePlayer) Play(audioData []byte, meta *AudioMeta) error {
-	// Log metadata if available
 	if meta != nil {
-		// Metadata is available for logging or future use
-		// For now, we just play the audio
+		if meta.Format != nil {
+			logger.LogDebug("Playing %v of %s audio: %s", meta.Duration.Round(time.Millisecond), meta.Format, meta.NormalizedText)
+		} else {
+			logger.LogDebug("Playing %v of audio: %s", meta.Duration.Round(time.Millisecond), meta.NormalizedText)
+		}
 	}
 
 	switch runtime.GOOS {
@@ -44,9 +49,10 @@ func (p *NativePlayer) TestPlay() error {
 		NormalizedText: "test",
 	}
 
-	// Parse duration from silent WAV
-	if duration, err := ParseWAVDuration(silentWAV); err == nil {
-		meta.Duration = duration
+	// Parse the format of the silent WAV
+	if format, err := ParseWAV(silentWAV); err == nil {
+		meta.Duration = format.Duration
+		meta.Format = format
 	}
 
 	return p.Play(silentWAV, meta)
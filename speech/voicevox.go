@@ -8,7 +8,10 @@ import (
 	"io"
 	"net/http"
 	"net/url"
+	"sync"
 	"time"
+
+	"github.com/kazegusuri/claude-companion/logger"
 )
 
 // VoiceVox handles text-to-speech using VOICEVOX engine
@@ -16,6 +19,8 @@ type VoiceVox struct {
 	baseURL    string
 	speakerID  int
 	httpClient *http.Client
+	cache      *AudioCache // Synthesized audio reused for the same text and voice; nil disables it
+	mu         sync.Mutex  // Guards the voice parameters, which can change while synthesizing
 	speed      float64
 	pitch      float64
 	volume     float64
@@ -41,36 +46,132 @@ func NewVoiceVox(baseURL string, speakerID int) *VoiceVox {
 	}
 }
 
+// voiceParameterRanges are the values VOICEVOX accepts for each voice parameter
+var voiceParameterRanges = map[string][2]float64{
+	"speed":      {0.5, 2.0},
+	"pitch":      {-0.15, 0.15},
+	"volume":     {0.0, 2.0},
+	"intonation": {0.0, 2.0},
+}
+
 // SetVoiceParameters sets voice parameters
 func (v *VoiceVox) SetVoiceParameters(speed, pitch, volume, intonation float64) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
 	v.speed = speed
 	v.pitch = pitch
 	v.volume = volume
 	v.intonation = intonation
 }
 
+// SetVoiceParameter sets one voice parameter by name: speed, pitch, volume or intonation.
+// It fails for unknown names and values VOICEVOX does not accept.
+func (v *VoiceVox) SetVoiceParameter(name string, value float64) error {
+	if err := CheckVoiceParameter(name, value); err != nil {
+		return err
+	}
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	switch name {
+	case "speed":
+		v.speed = value
+	case "pitch":
+		v.pitch = value
+	case "volume":
+		v.volume = value
+	case "intonation":
+		v.intonation = value
+	}
+	return nil
+}
+
+// CheckVoiceParameter fails for unknown voice parameter names and values VOICEVOX does not accept
+func CheckVoiceParameter(name string, value float64) error {
+	r, ok := voiceParameterRanges[name]
+	if !ok {
+		return fmt.Errorf("unknown voice parameter %q (expected speed, pitch, volume or intonation)", name)
+	}
+	if value < r[0] || value > r[1] {
+		return fmt.Errorf("voice %s %g is out of range (%g to %g)", name, value, r[0], r[1])
+	}
+	return nil
+}
+
+// VoiceParameters returns the voice parameters by name
+func (v *VoiceVox) VoiceParameters() map[string]float64 {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	return map[string]float64{"speed": v.speed, "pitch": v.pitch, "volume": v.volume, "intonation": v.intonation}
+}
+
 // Synthesize converts text to audio data (WAV format)
 func (v *VoiceVox) Synthesize(ctx context.Context, text string) ([]byte, error) {
+	return v.SynthesizeWithSpeaker(ctx, text, v.speakerID)
+}
+
+// SetCache reuses audio synthesized before for the same text, speaker and voice
+// parameters from cache instead of asking the engine again
+func (v *VoiceVox) SetCache(cache *AudioCache) {
+	v.cache = cache
+}
+
+// SynthesizeWithSpeaker converts text to audio data (WAV format) using the given speaker
+func (v *VoiceVox) SynthesizeWithSpeaker(ctx context.Context, text string, speakerID int) ([]byte, error) {
+	return v.synthesize(ctx, text, speakerID, v.VoiceParameters())
+}
+
+// SynthesizeWithParameters converts text to audio data (WAV format) with params in
+// place of the voice parameters of the same names. A nil speakerID uses the default speaker.
+func (v *VoiceVox) SynthesizeWithParameters(ctx context.Context, text string, speakerID *int, params map[string]float64) ([]byte, error) {
+	speaker := v.speakerID
+	if speakerID != nil {
+		speaker = *speakerID
+	}
+	voice := v.VoiceParameters()
+	for name, value := range params {
+		voice[name] = value
+	}
+	return v.synthesize(ctx, text, speaker, voice)
+}
+
+// synthesize converts text to audio data (WAV format) with a speaker and voice parameters
+func (v *VoiceVox) synthesize(ctx context.Context, text string, speakerID int, voice map[string]float64) ([]byte, error) {
+	var cacheKey string
+	if v.cache != nil {
+		cacheKey = AudioCacheKey(v.baseURL, text, speakerID, voice)
+		if audioData, ok := v.cache.Get(cacheKey); ok {
+			logger.LogDebug("VOICEVOX audio for speaker %d found in the cache", speakerID)
+			return audioData, nil
+		}
+	}
+
+	start := time.Now()
 	// Generate audio query
-	query, err := v.generateAudioQuery(ctx, text)
+	query, err := v.generateAudioQuery(ctx, text, speakerID, voice)
 	if err != nil {
 		return nil, fmt.Errorf("failed to generate audio query: %w", err)
 	}
 
 	// Generate audio
-	audioData, err := v.generateAudio(ctx, query)
+	audioData, err := v.generateAudio(ctx, query, speakerID)
 	if err != nil {
 		return nil, fmt.Errorf("failed to generate audio: %w", err)
 	}
 
+	logger.LogDebug("VOICEVOX synthesized %d bytes for speaker %d in %v", len(audioData), speakerID, time.Since(start).Round(time.Millisecond))
+	if v.cache != nil {
+		if err := v.cache.Put(cacheKey, audioData); err != nil {
+			logger.LogWarning("%v", err)
+		}
+	}
 	return audioData, nil
 }
 
-// generateAudioQuery generates audio query from text
-func (v *VoiceVox) generateAudioQuery(ctx context.Context, text string) ([]byte, error) {
+// generateAudioQuery generates audio query from text with the voice parameters
+func (v *VoiceVox) generateAudioQuery(ctx context.Context, text string, speakerID int, voice map[string]float64) ([]byte, error) {
 	params := url.Values{}
 	params.Add("text", text)
-	params.Add("speaker", fmt.Sprintf("%d", v.speakerID))
+	params.Add("speaker", fmt.Sprintf("%d", speakerID))
 
 	url := fmt.Sprintf("%s/audio_query?%s", v.baseURL, params.Encode())
 
@@ -101,18 +202,18 @@ func (v *VoiceVox) generateAudioQuery(ctx context.Context, text string) ([]byte,
 		return nil, err
 	}
 
-	query["speedScale"] = v.speed
-	query["pitchScale"] = v.pitch
-	query["volumeScale"] = v.volume
-	query["intonationScale"] = v.intonation
+	query["speedScale"] = voice["speed"]
+	query["pitchScale"] = voice["pitch"]
+	query["volumeScale"] = voice["volume"]
+	query["intonationScale"] = voice["intonation"]
 
 	return json.Marshal(query)
 }
 
 // generateAudio generates audio from query
-func (v *VoiceVox) generateAudio(ctx context.Context, query []byte) ([]byte, error) {
+func (v *VoiceVox) generateAudio(ctx context.Context, query []byte, speakerID int) ([]byte, error) {
 	params := url.Values{}
-	params.Add("speaker", fmt.Sprintf("%d", v.speakerID))
+	params.Add("speaker", fmt.Sprintf("%d", speakerID))
 
 	url := fmt.Sprintf("%s/synthesis?%s", v.baseURL, params.Encode())
 
@@ -141,18 +242,31 @@ func (v *VoiceVox) IsAvailable() bool {
 	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
 	defer cancel()
 
+	_, err := v.Version(ctx)
+	return err == nil
+}
+
+// Version returns the version of the VOICEVOX engine, such as 0.14.10
+func (v *VoiceVox) Version(ctx context.Context) (string, error) {
 	req, err := http.NewRequestWithContext(ctx, "GET", v.baseURL+"/version", nil)
 	if err != nil {
-		return false
+		return "", err
 	}
 
 	resp, err := v.httpClient.Do(req)
 	if err != nil {
-		return false
+		return "", err
 	}
 	defer resp.Body.Close()
 
-	return resp.StatusCode == http.StatusOK
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("VOICEVOX returned %s", resp.Status)
+	}
+	var version string
+	if err := json.NewDecoder(resp.Body).Decode(&version); err != nil {
+		return "", fmt.Errorf("failed to decode VOICEVOX version: %w", err)
+	}
+	return version, nil
 }
 
 // GetSpeakers returns available speakers
@@ -0,0 +1,85 @@
+package speech
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestVoiceVox_SetVoiceParameter(t *testing.T) {
+	v := NewVoiceVox("", 1)
+	tests := []struct {
+		name    string
+		value   float64
+		wantErr bool
+	}{
+		{name: "speed", value: 1.2},
+		{name: "pitch", value: -0.1},
+		{name: "volume", value: 0},
+		{name: "intonation", value: 2},
+		{name: "speed", value: 3, wantErr: true},
+		{name: "pitch", value: 0.5, wantErr: true},
+		{name: "tempo", value: 1, wantErr: true},
+	}
+	for _, tt := range tests {
+		err := v.SetVoiceParameter(tt.name, tt.value)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("SetVoiceParameter(%q, %g) error = %v, wantErr %v", tt.name, tt.value, err, tt.wantErr)
+		}
+	}
+
+	want := map[string]float64{"speed": 1.2, "pitch": -0.1, "volume": 0, "intonation": 2}
+	got := v.VoiceParameters()
+	for name, value := range want {
+		if got[name] != value {
+			t.Errorf("VoiceParameters()[%q] = %g, want %g", name, got[name], value)
+		}
+	}
+}
+
+func TestVoiceVox_SynthesizeWithParameters(t *testing.T) {
+	var queries []map[string]interface{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/audio_query":
+			w.Write([]byte(`{}`))
+		case "/synthesis":
+			var query map[string]interface{}
+			json.NewDecoder(r.Body).Decode(&query)
+			query["speaker"] = r.URL.Query().Get("speaker")
+			queries = append(queries, query)
+			w.Write(GetSilentWAV())
+		}
+	}))
+	defer server.Close()
+
+	v := NewVoiceVox(server.URL, 1)
+	speaker := 3
+	if _, err := v.SynthesizeWithParameters(context.Background(), "考え中です", &speaker, map[string]float64{"speed": 1.0, "volume": 0.5}); err != nil {
+		t.Fatalf("SynthesizeWithParameters() error = %v", err)
+	}
+	if _, err := v.SynthesizeWithParameters(context.Background(), "考え中です", nil, nil); err != nil {
+		t.Fatalf("SynthesizeWithParameters() error = %v", err)
+	}
+
+	want := []map[string]interface{}{
+		{"speedScale": 1.0, "pitchScale": 0.0, "volumeScale": 0.5, "intonationScale": 1.0, "speaker": "3"},
+		{"speedScale": 1.5, "pitchScale": 0.0, "volumeScale": 1.0, "intonationScale": 1.0, "speaker": "1"},
+	}
+	if len(queries) != len(want) {
+		t.Fatalf("got %d syntheses, want %d", len(queries), len(want))
+	}
+	for i := range want {
+		for key, value := range want[i] {
+			if queries[i][key] != value {
+				t.Errorf("synthesis %d: %s = %v, want %v", i, key, queries[i][key], value)
+			}
+		}
+	}
+	// The synthesizer's own parameters are left as they were
+	if got := v.VoiceParameters()["speed"]; got != 1.5 {
+		t.Errorf("speed = %g after SynthesizeWithParameters, want 1.5", got)
+	}
+}
@@ -0,0 +1,251 @@
+package speech
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"time"
+)
+
+// WAV sample encodings, as in the format tag of the fmt chunk
+const (
+	WAVEncodingPCM        = 0x0001
+	WAVEncodingFloat      = 0x0003
+	WAVEncodingALaw       = 0x0006
+	WAVEncodingMuLaw      = 0x0007
+	wavEncodingExtensible = 0xFFFE
+)
+
+// wavExtensibleGUIDTail is the part of a WAVE_FORMAT_EXTENSIBLE sub-format GUID after its format tag
+var wavExtensibleGUIDTail = []byte{0x00, 0x00, 0x00, 0x00, 0x10, 0x00, 0x80, 0x00, 0x00, 0xAA, 0x00, 0x38, 0x9B, 0x71}
+
+// WAVFormat is the format of WAV audio
+type WAVFormat struct {
+	Encoding      uint16 // WAVEncodingPCM, WAVEncodingFloat, ...; the sub-format of extensible headers
+	Extensible    bool   // The header is WAVE_FORMAT_EXTENSIBLE
+	Channels      int
+	SampleRate    int
+	BitsPerSample int
+	BlockAlign    int   // Bytes per frame of all channels
+	DataSize      int64 // Bytes of audio in all data chunks
+	DataChunks    int
+	Duration      time.Duration
+}
+
+// String describes the format, such as "24-bit PCM, 48000 Hz, stereo"
+func (f *WAVFormat) String() string {
+	encoding := fmt.Sprintf("format 0x%04X", f.Encoding)
+	switch f.Encoding {
+	case WAVEncodingPCM:
+		encoding = "PCM"
+	case WAVEncodingFloat:
+		encoding = "float"
+	case WAVEncodingALaw:
+		encoding = "A-law"
+	case WAVEncodingMuLaw:
+		encoding = "μ-law"
+	}
+	channels := fmt.Sprintf("%d channels", f.Channels)
+	switch f.Channels {
+	case 1:
+		channels = "mono"
+	case 2:
+		channels = "stereo"
+	}
+	return fmt.Sprintf("%d-bit %s, %d Hz, %s", f.BitsPerSample, encoding, f.SampleRate, channels)
+}
+
+// ParseWAV parses the format of WAV audio. It reads PCM of any bit depth, float
+// and extensible headers, skips unknown chunks and sums the audio of all data
+// chunks. A data chunk cut short, as by a streaming encoder, counts what is there.
+func ParseWAV(audioData []byte) (*WAVFormat, error) {
+	if len(audioData) < 12 || string(audioData[0:4]) != "RIFF" || string(audioData[8:12]) != "WAVE" {
+		return nil, fmt.Errorf("invalid WAV file")
+	}
+
+	var format *WAVFormat
+	var formatErr error
+	var dataSize int64
+	dataChunks := 0
+	walkWAVChunks(audioData, func(id string, body []byte) {
+		switch id {
+		case "fmt ":
+			format, formatErr = parseWAVFormatChunk(body)
+		case "data":
+			dataSize += int64(len(body))
+			dataChunks++
+		}
+	})
+
+	if formatErr != nil {
+		return nil, formatErr
+	}
+	if format == nil {
+		return nil, fmt.Errorf("could not read WAV format")
+	}
+	if dataChunks == 0 {
+		return nil, fmt.Errorf("WAV file has no data chunk")
+	}
+	format.DataSize = dataSize
+	format.DataChunks = dataChunks
+	frames := dataSize / int64(format.BlockAlign)
+	format.Duration = time.Duration(float64(frames) / float64(format.SampleRate) * float64(time.Second))
+	return format, nil
+}
+
+// walkWAVChunks calls fn with each chunk of a WAV file in order; the body of a chunk
+// cut short is what is there
+func walkWAVChunks(audioData []byte, fn func(id string, body []byte)) {
+	for pos := 12; pos+8 <= len(audioData); {
+		size := int64(binary.LittleEndian.Uint32(audioData[pos+4 : pos+8]))
+		body := audioData[pos+8:]
+		if size < int64(len(body)) {
+			body = body[:size]
+		}
+		fn(string(audioData[pos:pos+4]), body)
+
+		// Chunks are padded to an even size
+		next := int64(pos) + 8 + size + size%2
+		if next > int64(len(audioData)) {
+			return
+		}
+		pos = int(next)
+	}
+}
+
+// parseWAVFormatChunk parses the body of a fmt chunk
+func parseWAVFormatChunk(body []byte) (*WAVFormat, error) {
+	if len(body) < 16 {
+		return nil, fmt.Errorf("WAV fmt chunk too short: %d bytes", len(body))
+	}
+	f := &WAVFormat{
+		Encoding:      binary.LittleEndian.Uint16(body[0:2]),
+		Channels:      int(binary.LittleEndian.Uint16(body[2:4])),
+		SampleRate:    int(binary.LittleEndian.Uint32(body[4:8])),
+		BlockAlign:    int(binary.LittleEndian.Uint16(body[12:14])),
+		BitsPerSample: int(binary.LittleEndian.Uint16(body[14:16])),
+	}
+	if f.Encoding == wavEncodingExtensible {
+		// cbSize, valid bits per sample, channel mask, then the sub-format GUID
+		if len(body) < 40 {
+			return nil, fmt.Errorf("WAV extensible fmt chunk too short: %d bytes", len(body))
+		}
+		f.Extensible = true
+		f.Encoding = binary.LittleEndian.Uint16(body[24:26])
+	}
+	if f.Channels == 0 {
+		return nil, fmt.Errorf("invalid number of channels: 0")
+	}
+	if f.SampleRate == 0 {
+		return nil, fmt.Errorf("invalid sample rate: 0")
+	}
+	if f.BlockAlign == 0 {
+		// Some encoders leave it out; derive it from the sample size
+		f.BlockAlign = (f.BitsPerSample + 7) / 8 * f.Channels
+	}
+	if f.BlockAlign == 0 {
+		return nil, fmt.Errorf("invalid block align: 0")
+	}
+	return f, nil
+}
+
+// EncodeWAV writes audio of format as a WAV file with a single data chunk. The
+// header is extensible if format is, and float audio gets the fact chunk it requires.
+func EncodeWAV(format *WAVFormat, data []byte) []byte {
+	var fmtChunk bytes.Buffer
+	tag := format.Encoding
+	if format.Extensible {
+		tag = wavEncodingExtensible
+	}
+	binary.Write(&fmtChunk, binary.LittleEndian, tag)
+	binary.Write(&fmtChunk, binary.LittleEndian, uint16(format.Channels))
+	binary.Write(&fmtChunk, binary.LittleEndian, uint32(format.SampleRate))
+	binary.Write(&fmtChunk, binary.LittleEndian, uint32(format.SampleRate*format.BlockAlign))
+	binary.Write(&fmtChunk, binary.LittleEndian, uint16(format.BlockAlign))
+	binary.Write(&fmtChunk, binary.LittleEndian, uint16(format.BitsPerSample))
+	switch {
+	case format.Extensible:
+		binary.Write(&fmtChunk, binary.LittleEndian, uint16(22))
+		binary.Write(&fmtChunk, binary.LittleEndian, uint16(format.BitsPerSample))
+		binary.Write(&fmtChunk, binary.LittleEndian, uint32(0)) // No channel mask
+		binary.Write(&fmtChunk, binary.LittleEndian, format.Encoding)
+		fmtChunk.Write(wavExtensibleGUIDTail)
+	case format.Encoding != WAVEncodingPCM:
+		binary.Write(&fmtChunk, binary.LittleEndian, uint16(0)) // cbSize
+	}
+
+	var body bytes.Buffer
+	body.WriteString("WAVE")
+	writeWAVChunk(&body, "fmt ", fmtChunk.Bytes())
+	if format.Encoding != WAVEncodingPCM {
+		fact := make([]byte, 4)
+		binary.LittleEndian.PutUint32(fact, uint32(len(data)/format.BlockAlign))
+		writeWAVChunk(&body, "fact", fact)
+	}
+	writeWAVChunk(&body, "data", data)
+
+	var out bytes.Buffer
+	out.WriteString("RIFF")
+	binary.Write(&out, binary.LittleEndian, uint32(body.Len()))
+	out.Write(body.Bytes())
+	return out.Bytes()
+}
+
+// writeWAVChunk writes a chunk, padding it to an even size
+func writeWAVChunk(buf *bytes.Buffer, id string, data []byte) {
+	buf.WriteString(id)
+	binary.Write(buf, binary.LittleEndian, uint32(len(data)))
+	buf.Write(data)
+	if len(data)%2 == 1 {
+		buf.WriteByte(0)
+	}
+}
+
+// ConcatWAV joins WAV files of the same encoding, channels, sample rate and bit depth
+// into one, with the audio of every data chunk of each in order
+func ConcatWAV(files ...[]byte) ([]byte, error) {
+	if len(files) == 0 {
+		return nil, fmt.Errorf("no WAV files to concatenate")
+	}
+	var format *WAVFormat
+	var data bytes.Buffer
+	for i, file := range files {
+		f, err := ParseWAV(file)
+		if err != nil {
+			return nil, fmt.Errorf("WAV file %d: %w", i+1, err)
+		}
+		if format == nil {
+			format = f
+		} else if f.Encoding != format.Encoding || f.Channels != format.Channels || f.SampleRate != format.SampleRate || f.BitsPerSample != format.BitsPerSample {
+			return nil, fmt.Errorf("WAV file %d is %s, want %s", i+1, f, format)
+		}
+		walkWAVChunks(file, func(id string, body []byte) {
+			if id == "data" {
+				data.Write(body)
+			}
+		})
+	}
+	return EncodeWAV(format, data.Bytes()), nil
+}
+
+// SilenceWAV returns a WAV file of silence of format lasting d
+func SilenceWAV(format *WAVFormat, d time.Duration) []byte {
+	frames := int(d.Seconds() * float64(format.SampleRate))
+	data := make([]byte, frames*format.BlockAlign)
+	// Zero is silence except in 8-bit PCM, which is unsigned, and the companded encodings
+	var silence byte
+	switch {
+	case format.Encoding == WAVEncodingPCM && format.BitsPerSample == 8:
+		silence = 0x80
+	case format.Encoding == WAVEncodingALaw:
+		silence = 0xD5
+	case format.Encoding == WAVEncodingMuLaw:
+		silence = 0xFF
+	}
+	if silence != 0 {
+		for i := range data {
+			data[i] = silence
+		}
+	}
+	return EncodeWAV(format, data)
+}
@@ -0,0 +1,106 @@
+package speech
+
+import (
+	"bytes"
+	"encoding/binary"
+	"testing"
+	"time"
+)
+
+func TestParseWAV(t *testing.T) {
+	pcm24 := &WAVFormat{Encoding: WAVEncodingPCM, Channels: 2, SampleRate: 48000, BitsPerSample: 24, BlockAlign: 6}
+	float32Ext := &WAVFormat{Encoding: WAVEncodingFloat, Extensible: true, Channels: 1, SampleRate: 24000, BitsPerSample: 32, BlockAlign: 4}
+
+	tests := []struct {
+		name       string
+		audioData  []byte
+		want       string
+		wantChunks int
+		duration   time.Duration
+	}{
+		{"16-bit PCM", silentWAV, "16-bit PCM, 44100 Hz, mono", 1, time.Second / 44100},
+		{"24-bit PCM", EncodeWAV(pcm24, make([]byte, 48000*6/2)), "24-bit PCM, 48000 Hz, stereo", 1, 500 * time.Millisecond},
+		{"Extensible float", EncodeWAV(float32Ext, make([]byte, 24000*4)), "32-bit float, 24000 Hz, mono", 1, time.Second},
+		{"Multiple data chunks", multiChunkWAV(), "16-bit PCM, 8000 Hz, mono", 2, time.Second},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseWAV(tt.audioData)
+			if err != nil {
+				t.Fatalf("ParseWAV() error = %v", err)
+			}
+			if got.String() != tt.want || got.DataChunks != tt.wantChunks || got.Duration != tt.duration {
+				t.Errorf("ParseWAV() = %s with %d data chunk(s) of %v, want %s with %d of %v", got, got.DataChunks, got.Duration, tt.want, tt.wantChunks, tt.duration)
+			}
+		})
+	}
+
+	// A data chunk cut short counts the audio that is there
+	truncated := EncodeWAV(pcm24, make([]byte, 48000*6))
+	truncated = truncated[:len(truncated)-48000*3]
+	if got, err := ParseWAV(truncated); err != nil || got.Duration != 500*time.Millisecond {
+		t.Errorf("ParseWAV(truncated) = %v, %v, want 500ms", got, err)
+	}
+
+	for name, data := range map[string][]byte{
+		"no data chunk": silentWAV[:36],
+		"short fmt":     append([]byte("RIFF\x10\x00\x00\x00WAVEfmt \x04\x00\x00\x00"), 1, 0, 1, 0),
+	} {
+		if _, err := ParseWAV(data); err == nil {
+			t.Errorf("ParseWAV(%s) should fail", name)
+		}
+	}
+}
+
+// multiChunkWAV is half a second of 8 kHz 16-bit audio in each of two data chunks,
+// with an odd-sized chunk padded between them
+func multiChunkWAV() []byte {
+	single := EncodeWAV(&WAVFormat{Encoding: WAVEncodingPCM, Channels: 1, SampleRate: 8000, BitsPerSample: 16, BlockAlign: 2}, make([]byte, 8000))
+	var body bytes.Buffer
+	body.Write(single[12:]) // fmt and the first data chunk
+	writeWAVChunk(&body, "LIST", []byte("odd"))
+	writeWAVChunk(&body, "data", make([]byte, 8000))
+
+	var out bytes.Buffer
+	out.WriteString("RIFF")
+	binary.Write(&out, binary.LittleEndian, uint32(body.Len()+4))
+	out.WriteString("WAVE")
+	out.Write(body.Bytes())
+	return out.Bytes()
+}
+
+func TestConcatWAV(t *testing.T) {
+	format, err := ParseWAV(multiChunkWAV())
+	if err != nil {
+		t.Fatal(err)
+	}
+	joined, err := ConcatWAV(multiChunkWAV(), SilenceWAV(format, 250*time.Millisecond))
+	if err != nil {
+		t.Fatalf("ConcatWAV() error = %v", err)
+	}
+	got, err := ParseWAV(joined)
+	if err != nil {
+		t.Fatalf("ParseWAV() error = %v", err)
+	}
+	if got.DataChunks != 1 || got.Duration != 1250*time.Millisecond {
+		t.Errorf("ConcatWAV() = %d data chunk(s) of %v, want one of 1.25s", got.DataChunks, got.Duration)
+	}
+
+	if _, err := ConcatWAV(silentWAV, multiChunkWAV()); err == nil {
+		t.Error("ConcatWAV() of different sample rates should fail")
+	}
+}
+
+func TestSilenceWAV(t *testing.T) {
+	pcm8 := &WAVFormat{Encoding: WAVEncodingPCM, Channels: 1, SampleRate: 8000, BitsPerSample: 8, BlockAlign: 1}
+	data := SilenceWAV(pcm8, 10*time.Millisecond)
+	if got := data[len(data)-80:]; !bytes.Equal(got, bytes.Repeat([]byte{0x80}, 80)) {
+		t.Errorf("8-bit silence = %v, want the unsigned midpoint", got)
+	}
+
+	float64Fmt := &WAVFormat{Encoding: WAVEncodingFloat, Channels: 2, SampleRate: 16000, BitsPerSample: 64, BlockAlign: 16}
+	got, err := ParseWAV(SilenceWAV(float64Fmt, time.Second))
+	if err != nil || got.String() != "64-bit float, 16000 Hz, stereo" || got.Duration != time.Second {
+		t.Errorf("ParseWAV(float silence) = %v, %v", got, err)
+	}
+}
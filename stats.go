@@ -0,0 +1,181 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"text/tabwriter"
+	"time"
+
+	"github.com/kazegusuri/claude-companion/logger"
+	"github.com/kazegusuri/claude-companion/usage"
+	"github.com/spf13/pflag"
+)
+
+// runStats scans transcripts and prints token usage and estimated cost
+func runStats(args []string) int {
+	fs := pflag.NewFlagSet("stats", pflag.ContinueOnError)
+	var projectsRoots []string
+	var project, session, since string
+	var days int
+	var jsonOutput bool
+	fs.StringSliceVar(&projectsRoots, "projects-root", []string{"~/.claude/projects"}, "Root directory for projects (repeatable or comma-separated; LABEL=PATH is accepted)")
+	fs.StringVarP(&project, "project", "p", "", "Project name")
+	fs.StringVarP(&session, "session", "s", "", "Session name")
+	fs.StringVar(&since, "since", "", "Only include usage on or after this date (YYYY-MM-DD)")
+	fs.IntVar(&days, "days", 0, "Only include usage in the last N days (not with --since)")
+	fs.BoolVar(&jsonOutput, "json", false, "Print the report as JSON")
+	if err := fs.Parse(args); err != nil {
+		if err == pflag.ErrHelp {
+			return 0
+		}
+		return 2
+	}
+
+	if since != "" && days > 0 {
+		logger.LogError("--since and --days cannot be used together")
+		return 2
+	}
+	var sinceDate string
+	if since != "" {
+		if _, err := time.ParseInLocation("2006-01-02", since, time.Local); err != nil {
+			logger.LogError("Invalid --since date %q: %v", since, err)
+			return 2
+		}
+		sinceDate = since
+	}
+	if days > 0 {
+		sinceDate = time.Now().AddDate(0, 0, -(days - 1)).Format("2006-01-02")
+	}
+
+	roots, err := parseProjectsRoots(projectsRoots)
+	if err != nil {
+		logger.LogError("%v", err)
+		return 2
+	}
+	var sessions []*usage.SessionUsage
+	for _, root := range roots {
+		found, err := usage.ScanRoot(root.path, func(p, s string) bool {
+			return (project == "" || p == project) && (session == "" || s == session)
+		})
+		if err != nil {
+			logger.LogError("Failed to scan transcripts in %s: %v", root.path, err)
+			return 1
+		}
+		sessions = append(sessions, found...)
+	}
+
+	report := buildStatsReport(sessions, sinceDate)
+	if jsonOutput {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(report); err != nil {
+			logger.LogError("Failed to encode report: %v", err)
+			return 1
+		}
+		return 0
+	}
+	printStatsReport(os.Stdout, report)
+	return 0
+}
+
+// statsEntry is a row of the stats report
+type statsEntry struct {
+	Name     string       `json:"name"`
+	Project  string       `json:"project,omitempty"`
+	Tokens   usage.Tokens `json:"tokens"`
+	Cost     float64      `json:"cost"`
+	Models   []string     `json:"models,omitempty"`
+	LastSeen time.Time    `json:"lastSeen,omitzero"`
+}
+
+// statsReport is the aggregated token usage
+type statsReport struct {
+	Total    statsEntry   `json:"total"`
+	Models   []statsEntry `json:"models"`
+	Projects []statsEntry `json:"projects"`
+	Sessions []statsEntry `json:"sessions"`
+	Daily    []statsEntry `json:"daily"`
+}
+
+// buildStatsReport aggregates session usages by model, project, session and day
+func buildStatsReport(sessions []*usage.SessionUsage, sinceDate string) *statsReport {
+	all := make(usage.ModelUsage)
+	projects := make(map[string]usage.ModelUsage)
+	daily := make(map[string]usage.ModelUsage)
+	report := &statsReport{}
+
+	for _, s := range sessions {
+		sessionUsage := make(usage.ModelUsage)
+		for day, models := range s.Daily {
+			if sinceDate != "" && day < sinceDate {
+				continue
+			}
+			sessionUsage.Merge(models)
+			if _, ok := daily[day]; !ok {
+				daily[day] = make(usage.ModelUsage)
+			}
+			daily[day].Merge(models)
+		}
+		if len(sessionUsage) == 0 {
+			continue
+		}
+		all.Merge(sessionUsage)
+		if _, ok := projects[s.Project]; !ok {
+			projects[s.Project] = make(usage.ModelUsage)
+		}
+		projects[s.Project].Merge(sessionUsage)
+		report.Sessions = append(report.Sessions, statsEntry{
+			Name:     s.Session,
+			Project:  s.Project,
+			Tokens:   sessionUsage.Tokens(),
+			Cost:     sessionUsage.Cost(),
+			Models:   sessionUsage.Models(),
+			LastSeen: s.LastSeen,
+		})
+	}
+
+	report.Total = statsEntry{Name: "total", Tokens: all.Tokens(), Cost: all.Cost(), Models: all.Models()}
+	for _, model := range all.Models() {
+		report.Models = append(report.Models, statsEntry{Name: model, Tokens: *all[model], Cost: usage.EstimateCost(model, *all[model])})
+	}
+	for name, models := range projects {
+		report.Projects = append(report.Projects, statsEntry{Name: name, Tokens: models.Tokens(), Cost: models.Cost(), Models: models.Models()})
+	}
+	for day, models := range daily {
+		report.Daily = append(report.Daily, statsEntry{Name: day, Tokens: models.Tokens(), Cost: models.Cost(), Models: models.Models()})
+	}
+
+	sort.Slice(report.Projects, func(i, j int) bool { return report.Projects[i].Cost > report.Projects[j].Cost })
+	sort.Slice(report.Sessions, func(i, j int) bool { return report.Sessions[i].LastSeen.After(report.Sessions[j].LastSeen) })
+	sort.Slice(report.Daily, func(i, j int) bool { return report.Daily[i].Name < report.Daily[j].Name })
+	return report
+}
+
+// printStatsReport prints the report as tables
+func printStatsReport(out io.Writer, report *statsReport) {
+	printSection := func(title, label string, entries []statsEntry) {
+		fmt.Fprintf(out, "\n=== %s ===\n", title)
+		w := tabwriter.NewWriter(out, 0, 0, 2, ' ', tabwriter.AlignRight)
+		fmt.Fprintf(w, "%s\tInput\tOutput\tCache Write\tCache Read\tTotal\tCost (USD)\t\n", label)
+		for _, e := range entries {
+			name := e.Name
+			if e.Project != "" {
+				name = e.Project + "/" + e.Name
+			}
+			fmt.Fprintf(w, "%s\t%d\t%d\t%d\t%d\t%d\t$%.2f\t\n", name,
+				e.Tokens.InputTokens, e.Tokens.OutputTokens,
+				e.Tokens.CacheCreationInputTokens, e.Tokens.CacheReadInputTokens,
+				e.Tokens.Total(), e.Cost)
+		}
+		w.Flush()
+	}
+
+	printSection("Daily", "Date", report.Daily)
+	printSection("Projects", "Project", report.Projects)
+	printSection("Sessions", "Session", report.Sessions)
+	printSection("Models", "Model", report.Models)
+	printSection("Total", "", []statsEntry{report.Total})
+}
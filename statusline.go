@@ -0,0 +1,385 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"net/url"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/kazegusuri/claude-companion/logger"
+	"github.com/kazegusuri/claude-companion/server"
+	"github.com/kazegusuri/claude-companion/usage"
+	"github.com/spf13/pflag"
+)
+
+// statusLineInput is the part of the Claude Code status line input we use
+type statusLineInput struct {
+	SessionID      string `json:"session_id"`
+	TranscriptPath string `json:"transcript_path"`
+	CWD            string `json:"cwd"`
+	Model          struct {
+		ID          string `json:"id"`
+		DisplayName string `json:"display_name"`
+	} `json:"model"`
+	Workspace struct {
+		CurrentDir string `json:"current_dir"`
+		ProjectDir string `json:"project_dir"`
+	} `json:"workspace"`
+	Cost struct {
+		TotalCostUSD float64 `json:"total_cost_usd"`
+	} `json:"cost"`
+}
+
+// statusLineData is the data available to a --format template
+type statusLineData struct {
+	Model     string  // Display name of the model
+	ModelID   string  // ID of the model
+	Dir       string  // Base name of the current directory
+	Path      string  // Full path of the current directory
+	Branch    string  // Git branch of the current directory, or "" outside a repository
+	Ahead     int     // Commits ahead of the upstream branch
+	Behind    int     // Commits behind the upstream branch
+	Dirty     bool    // Whether the working tree has uncommitted changes
+	Git       string  // Branch, dirty marker and ahead/behind counts, colored
+	Tokens    int     // Tokens in the context as of the latest response
+	Percent   float64 // Tokens as a percentage of the model's context window
+	SessionID string
+	Cost      float64 // Session cost in USD
+	BurnRate  float64 // Context growth in tokens per minute over the latest responses
+	CompactIn float64 // Estimated minutes until the context is compacted, or 0 when unknown
+	Burn      string  // Burn rate and time to compaction, or "" when unknown
+	Companion string  // Companion state segment, or "" when the companion is not running
+	Command   string  // Output of the --command status line
+}
+
+// ansiColors are the colors of the color template function
+var ansiColors = map[string]string{
+	"red":     "31",
+	"green":   "32",
+	"yellow":  "33",
+	"blue":    "34",
+	"magenta": "35",
+	"cyan":    "36",
+	"gray":    "90",
+}
+
+// statusLineOptions are the flags that shape the --format data
+type statusLineOptions struct {
+	format       string
+	colored      bool
+	accessible   bool
+	cachePath    string
+	burnWindow   int     // Assistant messages the burn rate is measured over
+	compactAt    float64 // Context usage percentage at which the context is compacted
+	contextLimit int     // Context window overriding the one of the model, or 0 to infer it
+}
+
+// statusLineFuncs returns the functions available to a --format template
+func statusLineFuncs(colored bool) template.FuncMap {
+	return template.FuncMap{
+		"compact": compactNumber,
+		"color": func(name, text string) string {
+			return colorize(colored, name, text)
+		},
+	}
+}
+
+// colorize wraps text in the ANSI escape codes of a color when colored is set
+func colorize(colored bool, name, text string) string {
+	code, ok := ansiColors[name]
+	if !colored || !ok || text == "" {
+		return text
+	}
+	return "\033[" + code + "m" + text + "\033[0m"
+}
+
+// runStatusLine prints a status line segment with the companion state of the session.
+// It is meant to be the statusLine command of Claude Code, optionally wrapping another one.
+func runStatusLine(args []string) int {
+	fs := pflag.NewFlagSet("status-line", pflag.ContinueOnError)
+	var serverAddr string
+	var serverCA string
+	var token string
+	var command string
+	var timeout time.Duration
+	var accessible bool
+	var format string
+	var noColor bool
+	var cachePath string
+	var burnWindow int
+	var compactAt float64
+	var contextLimit int
+	fs.StringVar(&serverAddr, "server-addr", "127.0.0.1:8765", "Address of the companion HTTP server, as host:port or an http:// or https:// URL")
+	fs.StringVar(&serverCA, "server-ca", "", "PEM CA certificate to verify the HTTPS companion server with, such as its --server-tls-cert; implies https")
+	fs.StringVar(&token, "token", os.Getenv("CLAUDE_COMPANION_TOKEN"), "API token for the companion server (can also use CLAUDE_COMPANION_TOKEN env var)")
+	fs.StringVar(&command, "command", "", "Status line command to wrap; the companion segment is appended to its output")
+	fs.DurationVar(&timeout, "timeout", 150*time.Millisecond, "Timeout for querying the companion server")
+	fs.BoolVar(&accessible, "accessible", false, "Use text labels instead of emojis")
+	fs.StringVar(&format, "format", "", "Go text/template for the whole status line (fields: .Model .ModelID .Dir .Path .Branch .Ahead .Behind .Dirty .Git .Tokens .Percent .SessionID .Cost .BurnRate .CompactIn .Burn .Companion .Command)")
+	fs.BoolVar(&noColor, "no-color", os.Getenv("NO_COLOR") != "", "Disable colors in --format output (can also use NO_COLOR env var)")
+	fs.StringVar(&cachePath, "cache", "~/.claude-companion/status-line.json", "Path to the file remembering how far transcripts were read, so each run only reads appended lines (empty reads the whole transcript)")
+	fs.IntVar(&burnWindow, "burn-window", 10, "Number of latest responses the burn rate is measured over")
+	fs.Float64Var(&compactAt, "compact-at", 80, "Context usage percentage at which Claude Code compacts, for the time to compaction estimate")
+	fs.IntVar(&contextLimit, "context-limit", 0, "Context window in tokens for .Percent and .CompactIn (0 infers it from the model)")
+	if err := fs.Parse(args); err != nil {
+		if err == pflag.ErrHelp {
+			return 0
+		}
+		return 2
+	}
+	cachePath, err := usage.ExpandHome(cachePath)
+	if err != nil {
+		logger.LogError("Invalid --cache: %v", err)
+		return 2
+	}
+	client, baseURL, err := serverClient(serverAddr, serverCA)
+	if err != nil {
+		logger.LogError("%v", err)
+		return 2
+	}
+
+	var tmpl *template.Template
+	if format != "" {
+		tmpl, err = template.New("status-line").Funcs(statusLineFuncs(!noColor)).Parse(format)
+		if err != nil {
+			logger.LogError("Invalid status line format: %v", err)
+			return 2
+		}
+	}
+
+	stdin, err := io.ReadAll(os.Stdin)
+	if err != nil {
+		logger.LogError("Failed to read status line input: %v", err)
+		return 1
+	}
+
+	var line string
+	if command != "" {
+		cmd := exec.Command("sh", "-c", command)
+		cmd.Stdin = bytes.NewReader(stdin)
+		cmd.Stderr = os.Stderr
+		out, err := cmd.Output()
+		if err != nil {
+			logger.LogError("Status line command failed: %v", err)
+		}
+		line = strings.TrimRight(string(out), "\n")
+	}
+
+	// The status line must not break or stall when the companion is not running
+	var input statusLineInput
+	var segment string
+	if err := json.Unmarshal(stdin, &input); err == nil && input.SessionID != "" && (tmpl == nil || strings.Contains(format, ".Companion")) {
+		ctx, cancel := context.WithTimeout(context.Background(), timeout)
+		defer cancel()
+		if status, err := fetchSessionStatus(ctx, client, baseURL, token, input.SessionID); err == nil {
+			segment = formatStatusSegment(status, accessible)
+		}
+	}
+
+	if tmpl != nil {
+		var buf bytes.Buffer
+		if err := tmpl.Execute(&buf, newStatusLineData(input, statusLineOptions{
+			format:       format,
+			colored:      !noColor,
+			accessible:   accessible,
+			cachePath:    cachePath,
+			burnWindow:   burnWindow,
+			compactAt:    compactAt,
+			contextLimit: contextLimit,
+		}, line, segment)); err != nil {
+			logger.LogError("Failed to format status line: %v", err)
+			return 1
+		}
+		line = strings.TrimRight(buf.String(), "\n")
+	} else if segment != "" {
+		if line != "" {
+			line += " | "
+		}
+		line += segment
+	}
+
+	if line != "" {
+		fmt.Println(line)
+	}
+	return 0
+}
+
+// newStatusLineData collects the template data. The transcript is only read
+// when the format uses the token count or the cost has to be estimated.
+func newStatusLineData(input statusLineInput, opts statusLineOptions, command, companion string) statusLineData {
+	dir := input.Workspace.CurrentDir
+	if dir == "" {
+		dir = input.CWD
+	}
+	data := statusLineData{
+		Model:     input.Model.DisplayName,
+		ModelID:   input.Model.ID,
+		Path:      dir,
+		SessionID: input.SessionID,
+		Cost:      input.Cost.TotalCostUSD,
+		Companion: companion,
+		Command:   command,
+	}
+	if dir != "" {
+		data.Dir = filepath.Base(dir)
+		if usesAny(opts.format, ".Branch", ".Ahead", ".Behind", ".Dirty", ".Git") {
+			git := readGitState(dir)
+			data.Branch, data.Ahead, data.Behind, data.Dirty = git.Branch, git.Ahead, git.Behind, git.Dirty
+			data.Git = git.segment(opts.colored)
+		}
+	}
+
+	needsTokens := usesAny(opts.format, ".Tokens", ".Percent", ".BurnRate", ".CompactIn", ".Burn")
+	needsCost := strings.Contains(opts.format, ".Cost") && data.Cost == 0
+	if input.TranscriptPath != "" && (needsTokens || needsCost) {
+		if session, err := scanTranscript(input.TranscriptPath, opts.cachePath); err == nil {
+			data.Tokens = session.ContextTokens()
+			if data.Cost == 0 {
+				data.Cost = session.Cost()
+			}
+			data.BurnRate = session.BurnRate(opts.burnWindow)
+			if window := contextWindow(opts.contextLimit, data.ModelID, data.Model, session.LastModel); window > 0 {
+				data.Percent = float64(data.Tokens) * 100 / float64(window)
+				threshold := float64(window) * opts.compactAt / 100
+				if data.BurnRate > 0 && float64(data.Tokens) < threshold {
+					data.CompactIn = (threshold - float64(data.Tokens)) / data.BurnRate
+				}
+			}
+			data.Burn = burnSegment(data.BurnRate, data.CompactIn, opts.accessible)
+		}
+	}
+	return data
+}
+
+// contextWindow returns the context window of the session: the limit if set, or that of
+// the first of the models that is known
+func contextWindow(limit int, models ...string) int {
+	if limit > 0 {
+		return limit
+	}
+	for _, model := range models {
+		if window := usage.ContextWindow(model); window > 0 {
+			return window
+		}
+	}
+	return 0
+}
+
+// burnSegment formats the burn rate and time to compaction, such as 🔥 1.2k/min ⏱ 14m
+func burnSegment(rate, minutes float64, accessible bool) string {
+	if rate <= 0 {
+		return ""
+	}
+	segment := fmt.Sprintf("%s%s/min", statusLabel(accessible, "🔥 ", "burn "), compactNumber(int(rate)))
+	if minutes > 0 {
+		segment += fmt.Sprintf(" %s%s", statusLabel(accessible, "⏱ ", "compaction in "), formatMinutes(minutes))
+	}
+	return segment
+}
+
+// formatMinutes formats a duration in minutes as 14m or 2h05m
+func formatMinutes(minutes float64) string {
+	m := int(math.Ceil(minutes))
+	if m < 60 {
+		return fmt.Sprintf("%dm", m)
+	}
+	return fmt.Sprintf("%dh%02dm", m/60, m%60)
+}
+
+// scanTranscript reads the usage of a transcript, only reading the lines appended
+// since the last run when a cache file is given
+func scanTranscript(path, cachePath string) (*usage.SessionUsage, error) {
+	if cachePath == "" {
+		return usage.ScanFile(path)
+	}
+	cache := usage.LoadScanCache(cachePath)
+	session, err := cache.Scan(path)
+	if err != nil {
+		return nil, err
+	}
+	// A cache that cannot be saved only costs a full read next time
+	_ = cache.Save()
+	return session, nil
+}
+
+// usesAny reports whether the format refers to any of the fields
+func usesAny(format string, fields ...string) bool {
+	for _, field := range fields {
+		if strings.Contains(format, field) {
+			return true
+		}
+	}
+	return false
+}
+
+// compactNumber formats a count with a k or M suffix, such as 12.3k
+func compactNumber(n int) string {
+	switch {
+	case n >= 1_000_000:
+		return fmt.Sprintf("%.1fM", float64(n)/1_000_000)
+	case n >= 1_000:
+		return fmt.Sprintf("%.1fk", float64(n)/1_000)
+	default:
+		return fmt.Sprintf("%d", n)
+	}
+}
+
+// fetchSessionStatus queries the companion server for the state of a session
+func fetchSessionStatus(ctx context.Context, client *http.Client, baseURL, token, sessionID string) (server.SessionStatus, error) {
+	var status server.SessionStatus
+	u := fmt.Sprintf("%s/api/sessions/%s/status", baseURL, url.PathEscape(sessionID))
+	req, err := http.NewRequestWithContext(ctx, "GET", u, nil)
+	if err != nil {
+		return status, err
+	}
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return status, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return status, fmt.Errorf("unexpected status: %s", resp.Status)
+	}
+	err = json.NewDecoder(resp.Body).Decode(&status)
+	return status, err
+}
+
+// formatStatusSegment formats the companion state as a status line segment
+func formatStatusSegment(status server.SessionStatus, accessible bool) string {
+	var parts []string
+	switch status.Voice {
+	case "on":
+		parts = append(parts, statusLabel(accessible, "🔊", "voice on"))
+	case "muted":
+		parts = append(parts, statusLabel(accessible, "🔇", "muted"))
+	}
+	if status.Queued > 0 {
+		parts = append(parts, fmt.Sprintf("%s%d", statusLabel(accessible, "⏳", "queued "), status.Queued))
+	}
+	if status.PermissionPending {
+		parts = append(parts, statusLabel(accessible, "🔐 permission", "permission pending"))
+	}
+	return strings.Join(parts, " ")
+}
+
+// statusLabel returns the emoji, or the text label in accessible mode
+func statusLabel(accessible bool, emoji, label string) string {
+	if accessible {
+		return label
+	}
+	return emoji
+}
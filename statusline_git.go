@@ -0,0 +1,151 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// gitStatusTimeout bounds the git command, so a slow repository cannot stall the status line
+const gitStatusTimeout = 200 * time.Millisecond
+
+// gitState is the state of the repository containing the current directory
+type gitState struct {
+	Branch string
+	Ahead  int
+	Behind int
+	Dirty  bool
+}
+
+// readGitState asks git for the branch, upstream counts and dirty state of the
+// repository containing dir. When git is not available or too slow, only the
+// branch is read from the repository files.
+func readGitState(dir string) gitState {
+	ctx, cancel := context.WithTimeout(context.Background(), gitStatusTimeout)
+	defer cancel()
+	cmd := exec.CommandContext(ctx, "git", "-C", dir, "status", "--porcelain=v2", "--branch", "--untracked-files=normal")
+	out, err := cmd.Output()
+	if err != nil {
+		return gitState{Branch: gitBranch(dir)}
+	}
+	return parseGitStatus(out)
+}
+
+// parseGitStatus parses the output of git status --porcelain=v2 --branch
+func parseGitStatus(out []byte) gitState {
+	var state gitState
+	var oid string
+	scanner := bufio.NewScanner(bytes.NewReader(out))
+	for scanner.Scan() {
+		line := scanner.Text()
+		header, ok := strings.CutPrefix(line, "# ")
+		if !ok {
+			// Every other line is a changed or untracked file
+			if line != "" {
+				state.Dirty = true
+			}
+			continue
+		}
+		key, value, _ := strings.Cut(header, " ")
+		switch key {
+		case "branch.oid":
+			oid = value
+		case "branch.head":
+			if value != "(detached)" {
+				state.Branch = value
+			}
+		case "branch.ab":
+			for _, field := range strings.Fields(value) {
+				n, err := strconv.Atoi(field[1:])
+				if err != nil {
+					continue
+				}
+				switch field[0] {
+				case '+':
+					state.Ahead = n
+				case '-':
+					state.Behind = n
+				}
+			}
+		}
+	}
+	if state.Branch == "" && len(oid) >= 7 && oid != "(initial)" {
+		state.Branch = oid[:7]
+	}
+	return state
+}
+
+// segment formats the state as main*↑1↓2: green when clean, yellow with
+// uncommitted changes and red when behind the upstream branch
+func (g gitState) segment(colored bool) string {
+	if g.Branch == "" {
+		return ""
+	}
+	text := g.Branch
+	if g.Dirty {
+		text += "*"
+	}
+	if g.Ahead > 0 {
+		text += fmt.Sprintf("↑%d", g.Ahead)
+	}
+	if g.Behind > 0 {
+		text += fmt.Sprintf("↓%d", g.Behind)
+	}
+	color := "green"
+	switch {
+	case g.Behind > 0:
+		color = "red"
+	case g.Dirty:
+		color = "yellow"
+	}
+	return colorize(colored, color, text)
+}
+
+// gitBranch returns the branch checked out in the repository containing dir,
+// the short commit hash for a detached HEAD, or "" outside a repository
+func gitBranch(dir string) string {
+	for {
+		gitPath := filepath.Join(dir, ".git")
+		if info, err := os.Stat(gitPath); err == nil {
+			if !info.IsDir() {
+				// Worktrees and submodules have a .git file pointing to the git directory
+				data, err := os.ReadFile(gitPath)
+				if err != nil {
+					return ""
+				}
+				gitDir, ok := strings.CutPrefix(strings.TrimSpace(string(data)), "gitdir: ")
+				if !ok {
+					return ""
+				}
+				if !filepath.IsAbs(gitDir) {
+					gitDir = filepath.Join(dir, gitDir)
+				}
+				gitPath = gitDir
+			}
+			head, err := os.ReadFile(filepath.Join(gitPath, "HEAD"))
+			if err != nil {
+				return ""
+			}
+			ref := strings.TrimSpace(string(head))
+			if branch, ok := strings.CutPrefix(ref, "ref: refs/heads/"); ok {
+				return branch
+			}
+			if len(ref) > 7 {
+				return ref[:7]
+			}
+			return ref
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return ""
+		}
+		dir = parent
+	}
+}
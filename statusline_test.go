@@ -0,0 +1,237 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+)
+
+func TestNewStatusLineData(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "app")
+	if err := os.MkdirAll(filepath.Join(dir, ".git"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	// Not a repository git can read, so the branch comes from HEAD whether or not git is installed
+	if err := os.WriteFile(filepath.Join(dir, ".git", "HEAD"), []byte("ref: refs/heads/main\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	// The context grows from 20k to 40k tokens in 10 minutes
+	transcript := filepath.Join(t.TempDir(), "session1.jsonl")
+	lines := `{"type":"assistant","uuid":"a1","requestId":"req1","timestamp":"2025-01-26T10:00:00Z","message":{"id":"msg1","model":"claude-sonnet-4-20250514","usage":{"input_tokens":20000}}}
+{"type":"assistant","uuid":"a2","requestId":"req2","timestamp":"2025-01-26T10:10:00Z","message":{"id":"msg2","model":"claude-sonnet-4-20250514","usage":{"input_tokens":40000}}}
+`
+	if err := os.WriteFile(transcript, []byte(lines), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	input := statusLineInput{SessionID: "s1", TranscriptPath: transcript}
+	input.Model.ID, input.Model.DisplayName = "claude-sonnet-4-20250514", "Sonnet 4"
+	input.Workspace.CurrentDir = dir
+	withCWD := input
+	withCWD.Workspace.CurrentDir, withCWD.CWD = "", dir
+	withCost := input
+	withCost.Cost.TotalCostUSD = 1.5
+	missing := input
+	missing.TranscriptPath = filepath.Join(t.TempDir(), "missing.jsonl")
+
+	base := statusLineData{Model: "Sonnet 4", ModelID: "claude-sonnet-4-20250514", Dir: "app", Path: dir, SessionID: "s1", Companion: "🔊", Command: "~/src"}
+	tests := []struct {
+		name  string
+		input statusLineInput
+		opts  statusLineOptions
+		want  func(d *statusLineData)
+	}{
+		{name: "input only", input: input, opts: statusLineOptions{format: "{{.Model}} {{.Dir}}"}},
+		{name: "current directory", input: withCWD, opts: statusLineOptions{format: "{{.Dir}}"}},
+		{
+			name:  "git",
+			input: input,
+			opts:  statusLineOptions{format: "{{.Git}}", colored: true},
+			want: func(d *statusLineData) {
+				d.Branch, d.Git = "main", "\033[32mmain\033[0m"
+			},
+		},
+		{
+			name:  "transcript",
+			input: input,
+			opts:  statusLineOptions{format: "{{.Percent}} {{.Burn}} {{.Cost}}", compactAt: 80},
+			want: func(d *statusLineData) {
+				// 20% of 200k tokens, compacted at 160k in (160k - 40k) / 2k per minute
+				d.Tokens, d.Percent, d.Cost = 40000, 20, 0.18
+				d.BurnRate, d.CompactIn, d.Burn = 2000, 60, "🔥 2.0k/min ⏱ 1h00m"
+			},
+		},
+		{
+			name:  "context limit",
+			input: input,
+			opts:  statusLineOptions{format: "{{.Percent}} {{.Burn}}", compactAt: 80, contextLimit: 100000, accessible: true},
+			want: func(d *statusLineData) {
+				d.Tokens, d.Percent, d.Cost = 40000, 40, 0.18
+				d.BurnRate, d.CompactIn, d.Burn = 2000, 20, "burn 2.0k/min compaction in 20m"
+			},
+		},
+		{
+			name:  "cost from Claude Code",
+			input: withCost,
+			opts:  statusLineOptions{format: "{{.Cost}}"},
+			want: func(d *statusLineData) {
+				d.Cost = 1.5
+			},
+		},
+		{name: "missing transcript", input: missing, opts: statusLineOptions{format: "{{.Tokens}} {{.Cost}}"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			want := base
+			if tt.want != nil {
+				tt.want(&want)
+			}
+			got := newStatusLineData(tt.input, tt.opts, "~/src", "🔊")
+			if diff := cmp.Diff(want, got, cmpopts.EquateApprox(0, 1e-9)); diff != "" {
+				t.Errorf("newStatusLineData() mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestRunStatusLine_Format(t *testing.T) {
+	input, err := os.CreateTemp(t.TempDir(), "input")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := input.WriteString(`{"session_id":"s1","model":{"display_name":"Sonnet 4"}}`); err != nil {
+		t.Fatal(err)
+	}
+	stdin := os.Stdin
+	defer func() { os.Stdin = stdin }()
+
+	tests := []struct {
+		name   string
+		format string
+		want   int
+	}{
+		{name: "valid", format: "{{.Model}}", want: 0},
+		{name: "unparsable", format: "{{.Model", want: 2},
+		{name: "unknown field", format: "{{.Nope}}", want: 1},
+		{name: "wrong argument", format: `{{compact .Model}}`, want: 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := input.Seek(0, 0); err != nil {
+				t.Fatal(err)
+			}
+			os.Stdin = input
+			if got := runStatusLine([]string{"--format", tt.format, "--cache", ""}); got != tt.want {
+				t.Errorf("runStatusLine(--format %q) = %d, want %d", tt.format, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseGitStatus(t *testing.T) {
+	tests := []struct {
+		name string
+		out  string
+		want gitState
+	}{
+		{name: "empty", out: "", want: gitState{}},
+		{
+			name: "clean",
+			out:  "# branch.oid 1234567890abcdef\n# branch.head main\n# branch.upstream origin/main\n# branch.ab +0 -0\n",
+			want: gitState{Branch: "main"},
+		},
+		{
+			name: "ahead and behind",
+			out:  "# branch.oid 1234567890abcdef\n# branch.head feature/x\n# branch.upstream origin/feature/x\n# branch.ab +3 -2\n",
+			want: gitState{Branch: "feature/x", Ahead: 3, Behind: 2},
+		},
+		{
+			name: "changed file",
+			out:  "# branch.oid 1234567890abcdef\n# branch.head main\n1 .M N... 100644 100644 100644 abc abc main.go\n",
+			want: gitState{Branch: "main", Dirty: true},
+		},
+		{
+			name: "untracked file",
+			out:  "# branch.oid 1234567890abcdef\n# branch.head main\n? notes.txt\n",
+			want: gitState{Branch: "main", Dirty: true},
+		},
+		{
+			name: "no upstream",
+			out:  "# branch.oid 1234567890abcdef\n# branch.head topic\n",
+			want: gitState{Branch: "topic"},
+		},
+		{
+			name: "detached",
+			out:  "# branch.oid 1234567890abcdef\n# branch.head (detached)\n",
+			want: gitState{Branch: "1234567"},
+		},
+		{
+			name: "initial commit",
+			out:  "# branch.oid (initial)\n# branch.head main\n",
+			want: gitState{Branch: "main"},
+		},
+		{
+			name: "detached without a commit",
+			out:  "# branch.oid (initial)\n# branch.head (detached)\n",
+			want: gitState{},
+		},
+		{
+			name: "malformed counts",
+			out:  "# branch.oid 1234567890abcdef\n# branch.head main\n# branch.ab +x -1\n",
+			want: gitState{Branch: "main", Behind: 1},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if diff := cmp.Diff(tt.want, parseGitStatus([]byte(tt.out))); diff != "" {
+				t.Errorf("parseGitStatus() mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestGitState_Segment(t *testing.T) {
+	tests := []struct {
+		name    string
+		state   gitState
+		colored bool
+		want    string
+	}{
+		{name: "outside a repository", state: gitState{}, colored: true, want: ""},
+		{name: "clean", state: gitState{Branch: "main"}, want: "main"},
+		{name: "dirty", state: gitState{Branch: "main", Dirty: true}, want: "main*"},
+		{name: "ahead and behind", state: gitState{Branch: "main", Dirty: true, Ahead: 1, Behind: 2}, want: "main*↑1↓2"},
+		{name: "clean in green", state: gitState{Branch: "main", Ahead: 1}, colored: true, want: "\033[32mmain↑1\033[0m"},
+		{name: "dirty in yellow", state: gitState{Branch: "main", Dirty: true}, colored: true, want: "\033[33mmain*\033[0m"},
+		{name: "behind in red", state: gitState{Branch: "main", Dirty: true, Behind: 1}, colored: true, want: "\033[31mmain*↓1\033[0m"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.state.segment(tt.colored); got != tt.want {
+				t.Errorf("segment(%v) = %q, want %q", tt.colored, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCompactNumber(t *testing.T) {
+	tests := []struct {
+		n    int
+		want string
+	}{
+		{n: 0, want: "0"},
+		{n: 999, want: "999"},
+		{n: 1000, want: "1.0k"},
+		{n: 12345, want: "12.3k"},
+		{n: 1_000_000, want: "1.0M"},
+		{n: 2_560_000, want: "2.6M"},
+	}
+	for _, tt := range tests {
+		if got := compactNumber(tt.n); got != tt.want {
+			t.Errorf("compactNumber(%d) = %q, want %q", tt.n, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,43 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+
+	"golang.org/x/term"
+)
+
+// defaultTerminalWidth is used when the terminal width cannot be determined
+const defaultTerminalWidth = 80
+
+// terminalWidth returns the current width of the terminal attached to stdout
+func terminalWidth() int {
+	if width, _, err := term.GetSize(int(os.Stdout.Fd())); err == nil && width > 0 {
+		return width
+	}
+	if columns, err := strconv.Atoi(os.Getenv("COLUMNS")); err == nil && columns > 0 {
+		return columns
+	}
+	return defaultTerminalWidth
+}
+
+// stdoutIsTerminal reports whether stdout is a terminal rather than a file or pipe
+func stdoutIsTerminal() bool {
+	return term.IsTerminal(int(os.Stdout.Fd()))
+}
+
+// parseTerminalMode resolves an auto, always or never flag value, auto meaning
+// whether stdout is a terminal
+func parseTerminalMode(value string) (bool, error) {
+	switch value {
+	case "auto":
+		return stdoutIsTerminal(), nil
+	case "always":
+		return true, nil
+	case "never":
+		return false, nil
+	default:
+		return false, fmt.Errorf("%q (expected auto, always or never)", value)
+	}
+}
@@ -0,0 +1,220 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/kazegusuri/claude-companion/logger"
+	"github.com/kazegusuri/claude-companion/narrator"
+	"github.com/kazegusuri/claude-companion/speech"
+	"github.com/spf13/pflag"
+)
+
+// runTTS dispatches the tts subcommands
+func runTTS(args []string) int {
+	if len(args) == 0 || args[0] != "test" {
+		fmt.Fprintln(os.Stderr, "Usage: claude-companion tts test [--text TEXT]... [--file phrases.txt]")
+		return 2
+	}
+	return runTTSTest(args[1:])
+}
+
+// runTTSTest runs phrases through the normalize, synthesize and play pipeline and reports each step
+func runTTSTest(args []string) int {
+	fs := pflag.NewFlagSet("tts test", pflag.ContinueOnError)
+	var texts []string
+	var file string
+	var voicevoxURL string
+	var voiceSpeakerID int
+	var useAI bool
+	var openaiAPIKey string
+	var noPlay bool
+	var katakana bool
+	var audioPlayer string
+	var userDictionaryPath string
+	fs.StringArrayVar(&texts, "text", nil, "Phrase to speak (can be repeated)")
+	fs.StringVar(&file, "file", "", "File with one phrase per line (lines starting with # are ignored)")
+	fs.StringVar(&voicevoxURL, "voicevox-url", "http://localhost:50021", "VOICEVOX server URL")
+	fs.IntVar(&voiceSpeakerID, "voice-speaker", 1, "VOICEVOX speaker ID")
+	fs.BoolVar(&useAI, "ai", false, "Translate English phrases with OpenAI as the voice narrator does")
+	fs.StringVar(&openaiAPIKey, "openai-key", os.Getenv("OPENAI_API_KEY"), "OpenAI API key (can also use OPENAI_API_KEY env var)")
+	fs.BoolVar(&noPlay, "no-play", false, "Synthesize without playing the audio")
+	fs.BoolVar(&katakana, "katakana", false, "Read English words as katakana as --voice-katakana does")
+	fs.StringVar(&userDictionaryPath, "user-dictionary", "", "Read phrases with the readings of this dictionary as --user-dictionary does")
+	fs.StringVar(&audioPlayer, "audio-player", "native", "Audio player: native, mpv, ffplay, aplay, paplay or a shell command reading WAV from stdin or {file}")
+	if err := fs.Parse(args); err != nil {
+		if err == pflag.ErrHelp {
+			return 0
+		}
+		return 2
+	}
+
+	phrases := texts
+	if file != "" {
+		filePhrases, err := readPhrases(file)
+		if err != nil {
+			logger.LogError("Failed to read phrases: %v", err)
+			return 1
+		}
+		phrases = append(phrases, filePhrases...)
+	}
+	if len(phrases) == 0 {
+		logger.LogError("No phrases given. Use --text or --file.")
+		return 2
+	}
+
+	synthesizer := speech.NewVoiceVox(voicevoxURL, voiceSpeakerID)
+	if !synthesizer.IsAvailable() {
+		logger.LogError("VOICEVOX server is not available at %s. Please make sure VOICEVOX is running.", voicevoxURL)
+		return 1
+	}
+
+	pipeline := &ttsPipeline{
+		translator:  narrator.NewCombinedTranslator(openaiAPIKey, useAI),
+		normalizer:  narrator.NewTextNormalizer(),
+		synthesizer: synthesizer,
+	}
+	pipeline.normalizer.SetKatakana(katakana)
+	if userDictionaryPath != "" {
+		dict, err := narrator.LoadUserDictionary(userDictionaryPath)
+		if err == nil {
+			err = pipeline.normalizer.SetUserDictionary(dict)
+		}
+		if err != nil {
+			logger.LogError("%v", err)
+			return 2
+		}
+	}
+	if !noPlay {
+		player, err := speech.NewPlayer(audioPlayer)
+		if err != nil {
+			logger.LogError("%v", err)
+			return 2
+		}
+		pipeline.player = player
+	}
+
+	failed := 0
+	for i, phrase := range phrases {
+		result := pipeline.run(context.Background(), phrase)
+		printTTSResult(i+1, result)
+		if result.Err != nil {
+			failed++
+		}
+	}
+
+	fmt.Printf("\n%d phrases, %d failed\n", len(phrases), failed)
+	if failed > 0 {
+		return 1
+	}
+	return 0
+}
+
+// readPhrases reads one phrase per line, skipping blank lines and comments
+func readPhrases(path string) ([]string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var phrases []string
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		phrases = append(phrases, line)
+	}
+	return phrases, scanner.Err()
+}
+
+// ttsPipeline is the text to speech pipeline used by the voice narrator
+type ttsPipeline struct {
+	translator  *narrator.CombinedTranslator
+	normalizer  *narrator.TextNormalizer
+	synthesizer speech.Synthesizer
+	player      speech.Player // nil to skip playback
+}
+
+// ttsResult is the outcome of running a phrase through the pipeline
+type ttsResult struct {
+	Input         string
+	Translated    string
+	Normalized    string
+	SynthesisTime time.Duration
+	AudioDuration time.Duration
+	AudioFormat   *speech.WAVFormat
+	PlayTime      time.Duration
+	Err           error
+}
+
+// run translates, normalizes, synthesizes and plays a phrase
+func (p *ttsPipeline) run(ctx context.Context, phrase string) ttsResult {
+	result := ttsResult{Input: phrase}
+
+	translateCtx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	result.Translated, _ = p.translator.Translate(translateCtx, phrase)
+	cancel()
+	result.Normalized = p.normalizer.Normalize(result.Translated)
+
+	synthCtx, cancel := context.WithTimeout(ctx, 15*time.Second)
+	start := time.Now()
+	audioData, err := p.synthesizer.Synthesize(synthCtx, result.Normalized)
+	cancel()
+	result.SynthesisTime = time.Since(start)
+	if err != nil {
+		result.Err = fmt.Errorf("synthesize: %w", err)
+		return result
+	}
+
+	if format, err := speech.ParseWAV(audioData); err == nil {
+		result.AudioDuration = format.Duration
+		result.AudioFormat = format
+	}
+
+	if p.player != nil {
+		meta := &speech.AudioMeta{
+			OriginalText:   result.Translated,
+			NormalizedText: result.Normalized,
+			Duration:       result.AudioDuration,
+			Format:         result.AudioFormat,
+		}
+		start = time.Now()
+		if err := p.player.Play(audioData, meta); err != nil {
+			result.Err = fmt.Errorf("play: %w", err)
+		}
+		result.PlayTime = time.Since(start)
+	}
+
+	return result
+}
+
+// printTTSResult prints the steps of a phrase
+func printTTSResult(index int, r ttsResult) {
+	fmt.Printf("[%d] input:      %s\n", index, r.Input)
+	if r.Translated != r.Input {
+		fmt.Printf("    translated: %s\n", r.Translated)
+	}
+	if r.Normalized != r.Translated {
+		fmt.Printf("    normalized: %s\n", r.Normalized)
+	} else {
+		fmt.Printf("    normalized: (unchanged)\n")
+	}
+	fmt.Printf("    synthesis:  %s", r.SynthesisTime.Round(time.Millisecond))
+	if r.AudioDuration > 0 {
+		fmt.Printf(", audio %s (%s)", r.AudioDuration.Round(time.Millisecond), r.AudioFormat)
+	}
+	fmt.Println()
+	if r.PlayTime > 0 {
+		fmt.Printf("    playback:   %s\n", r.PlayTime.Round(time.Millisecond))
+	}
+	if r.Err != nil {
+		fmt.Printf("    error:      %v\n", r.Err)
+	}
+}
@@ -0,0 +1,144 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/kazegusuri/claude-companion/narrator"
+	"github.com/kazegusuri/claude-companion/speech"
+)
+
+func TestReadPhrases(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "phrases.txt")
+	content := "# Greetings\nこんにちは\n\n  Build succeeded  \n\t# indented comment\nテストが完了しました"
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := readPhrases(path)
+	if err != nil {
+		t.Fatalf("readPhrases() error = %v", err)
+	}
+	if diff := cmp.Diff([]string{"こんにちは", "Build succeeded", "テストが完了しました"}, got); diff != "" {
+		t.Errorf("readPhrases() mismatch (-want +got):\n%s", diff)
+	}
+
+	if _, err := readPhrases(filepath.Join(t.TempDir(), "missing.txt")); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("readPhrases(missing) error = %v, want not exist", err)
+	}
+}
+
+// fakeSynthesizer returns audio or an error and records the texts it synthesized
+type fakeSynthesizer struct {
+	audio []byte
+	err   error
+	texts []string
+}
+
+func (s *fakeSynthesizer) Synthesize(ctx context.Context, text string) ([]byte, error) {
+	s.texts = append(s.texts, text)
+	return s.audio, s.err
+}
+
+func (s *fakeSynthesizer) IsAvailable() bool { return true }
+
+func (s *fakeSynthesizer) SetVoiceParameters(speed, pitch, volume, intonation float64) {}
+
+// fakePlayer returns an error and records what it played
+type fakePlayer struct {
+	err    error
+	played [][]byte
+	metas  []*speech.AudioMeta
+}
+
+func (p *fakePlayer) Play(audioData []byte, meta *speech.AudioMeta) error {
+	p.played = append(p.played, audioData)
+	p.metas = append(p.metas, meta)
+	return p.err
+}
+
+func (p *fakePlayer) TestPlay() error { return nil }
+
+func TestTTSPipeline_Run(t *testing.T) {
+	wav := speech.GetSilentWAV()
+	format, err := speech.ParseWAV(wav)
+	if err != nil {
+		t.Fatal(err)
+	}
+	synthesisErr := errors.New("voicevox is down")
+	playErr := errors.New("no audio device")
+
+	tests := []struct {
+		name        string
+		synthesizer *fakeSynthesizer
+		player      *fakePlayer // nil to skip playback
+		wantFormat  *speech.WAVFormat
+		wantPlayed  bool
+		wantErr     error
+	}{
+		{name: "played", synthesizer: &fakeSynthesizer{audio: wav}, player: &fakePlayer{}, wantFormat: format, wantPlayed: true},
+		{name: "without a player", synthesizer: &fakeSynthesizer{audio: wav}, wantFormat: format},
+		{name: "not a WAV", synthesizer: &fakeSynthesizer{audio: []byte("mp3")}, player: &fakePlayer{}, wantPlayed: true},
+		{name: "synthesis fails", synthesizer: &fakeSynthesizer{err: synthesisErr}, player: &fakePlayer{}, wantErr: synthesisErr},
+		{name: "playback fails", synthesizer: &fakeSynthesizer{audio: wav}, player: &fakePlayer{err: playErr}, wantFormat: format, wantPlayed: true, wantErr: playErr},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pipeline := &ttsPipeline{
+				translator:  narrator.NewCombinedTranslator("", false),
+				normalizer:  narrator.NewTextNormalizer(),
+				synthesizer: tt.synthesizer,
+			}
+			if tt.player != nil {
+				pipeline.player = tt.player
+			}
+
+			const phrase = "ビルドが完了しました"
+			result := pipeline.run(context.Background(), phrase)
+			if result.Input != phrase || result.Translated != phrase {
+				t.Errorf("input = %q, translated = %q; want %q for both", result.Input, result.Translated, phrase)
+			}
+			if diff := cmp.Diff([]string{result.Normalized}, tt.synthesizer.texts); diff != "" {
+				t.Errorf("synthesized texts mismatch (-want +got):\n%s", diff)
+			}
+			if diff := cmp.Diff(tt.wantFormat, result.AudioFormat); diff != "" {
+				t.Errorf("audio format mismatch (-want +got):\n%s", diff)
+			}
+			if tt.wantFormat != nil && result.AudioDuration != tt.wantFormat.Duration {
+				t.Errorf("audio duration = %v, want %v", result.AudioDuration, tt.wantFormat.Duration)
+			}
+			if !errors.Is(result.Err, tt.wantErr) {
+				t.Errorf("error = %v, want %v", result.Err, tt.wantErr)
+			}
+
+			if tt.player == nil {
+				return
+			}
+			if !tt.wantPlayed {
+				if len(tt.player.played) != 0 {
+					t.Errorf("played %d times, want none", len(tt.player.played))
+				}
+				return
+			}
+			if len(tt.player.played) != 1 {
+				t.Fatalf("played %d times, want once", len(tt.player.played))
+			}
+			if string(tt.player.played[0]) != string(tt.synthesizer.audio) {
+				t.Errorf("played audio differs from the synthesized audio")
+			}
+			wantMeta := &speech.AudioMeta{
+				OriginalText:   result.Translated,
+				NormalizedText: result.Normalized,
+				Duration:       result.AudioDuration,
+				Format:         result.AudioFormat,
+			}
+			if diff := cmp.Diff(wantMeta, tt.player.metas[0]); diff != "" {
+				t.Errorf("audio meta mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,147 @@
+package usage
+
+import (
+	"fmt"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/kazegusuri/claude-companion/event"
+)
+
+// Limit is a per-session usage ceiling; zero fields are not enforced
+type Limit struct {
+	Cost   float64 // Estimated cost in USD
+	Tokens int64   // Total tokens, including cache reads and writes
+}
+
+// Enabled reports whether any ceiling is set
+func (l Limit) Enabled() bool {
+	return l.Cost > 0 || l.Tokens > 0
+}
+
+// Alert describes a session that exceeded its limit
+type Alert struct {
+	Project string
+	Session string
+	Cost    float64
+	Tokens  int64
+	Limit   Limit
+	Time    time.Time
+}
+
+// Reason returns a short description of the exceeded ceiling
+func (a Alert) Reason() string {
+	if a.Limit.Cost > 0 && a.Cost >= a.Limit.Cost {
+		return fmt.Sprintf("estimated cost $%.2f reached the limit of $%.2f", a.Cost, a.Limit.Cost)
+	}
+	return fmt.Sprintf("%d tokens reached the limit of %d", a.Tokens, a.Limit.Tokens)
+}
+
+// Guardrail tracks the usage of each session and reports sessions that exceed a limit.
+// Each session is reported once. Its usage is also the usage that session budgets
+// are counted against, through Add.
+type Guardrail struct {
+	limit    Limit
+	roots    []string
+	onExceed func(Alert)
+
+	mu       sync.Mutex
+	sessions map[string]*SessionUsage // key: project/session
+	alerted  map[string]bool
+}
+
+// NewGuardrail creates a guardrail that calls onExceed when a session exceeds the limit
+func NewGuardrail(limit Limit, onExceed func(Alert)) *Guardrail {
+	return &Guardrail{
+		limit:    limit,
+		onExceed: onExceed,
+		sessions: make(map[string]*SessionUsage),
+		alerted:  make(map[string]bool),
+	}
+}
+
+// SetProjectsRoots sets the projects roots used to load the usage a session had
+// before the companion started watching it
+func (g *Guardrail) SetProjectsRoots(roots []string) {
+	g.roots = roots
+}
+
+// HandleEvent implements event.EventSink
+func (g *Guardrail) HandleEvent(ev event.Event, formatted string) {
+	if alert, ok := g.Observe(ev); ok && g.onExceed != nil {
+		g.onExceed(alert)
+	}
+}
+
+// Observe adds the usage of an event and returns an alert when its session first exceeds the limit
+func (g *Guardrail) Observe(ev event.Event) (Alert, bool) {
+	msg, ok := ev.(*event.AssistantMessage)
+	if !ok || msg.IsSidechain || msg.Session == nil || !g.limit.Enabled() {
+		return Alert{}, false
+	}
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	key := msg.Session.Project + "/" + msg.Session.Session
+	if g.alerted[key] {
+		return Alert{}, false
+	}
+	s := g.session(msg.Session)
+	s.AddMessage(msg)
+
+	alert := Alert{
+		Project: s.Project,
+		Session: s.Session,
+		Cost:    s.Cost(),
+		Tokens:  s.Models.Tokens().Total(),
+		Limit:   g.limit,
+		Time:    time.Now(),
+	}
+	if (g.limit.Cost > 0 && alert.Cost >= g.limit.Cost) || (g.limit.Tokens > 0 && alert.Tokens >= g.limit.Tokens) {
+		g.alerted[key] = true
+		return alert, true
+	}
+	return Alert{}, false
+}
+
+// Add adds the usage of an assistant message to its session and returns the tokens
+// and estimated cost the session used so far, including the usage recorded before
+// the companion started. Subagent messages are not counted, as for the limit.
+// It is the event.SessionUsageFunc of session budgets.
+func (g *Guardrail) Add(msg *event.AssistantMessage) (int64, float64) {
+	if msg.Session == nil {
+		return 0, 0
+	}
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	s := g.session(msg.Session)
+	if !msg.IsSidechain {
+		s.AddMessage(msg)
+	}
+	return s.Models.Tokens().Total(), s.Cost()
+}
+
+// session returns the usage of a session, loading it on first use
+func (g *Guardrail) session(session *event.Session) *SessionUsage {
+	key := session.Project + "/" + session.Session
+	s, ok := g.sessions[key]
+	if !ok {
+		s = g.loadSession(session.Project, session.Session)
+		g.sessions[key] = s
+	}
+	return s
+}
+
+// loadSession loads the usage already recorded in the session transcript
+func (g *Guardrail) loadSession(project, session string) *SessionUsage {
+	for _, root := range g.roots {
+		if s, err := ScanFile(filepath.Join(root, project, session+".jsonl")); err == nil {
+			return s
+		}
+	}
+	return NewSessionUsage(project, session)
+}
@@ -0,0 +1,149 @@
+package usage
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/kazegusuri/claude-companion/event"
+)
+
+func newAssistantMessage(project, session, id string, u event.Usage) *event.AssistantMessage {
+	msg := &event.AssistantMessage{RequestID: "req-" + id}
+	msg.Session = &event.Session{Project: project, Session: session}
+	msg.Message.ID = id
+	msg.Message.Model = "claude-sonnet-4-20250514"
+	msg.Message.Usage = u
+	return msg
+}
+
+func TestGuardrail_Observe(t *testing.T) {
+	tests := []struct {
+		name       string
+		limit      Limit
+		messages   []*event.AssistantMessage
+		wantAlerts int
+	}{
+		{
+			name:  "token limit",
+			limit: Limit{Tokens: 1000},
+			messages: []*event.AssistantMessage{
+				newAssistantMessage("p", "s1", "m1", event.Usage{InputTokens: 400, OutputTokens: 100}),
+				newAssistantMessage("p", "s1", "m2", event.Usage{InputTokens: 400, OutputTokens: 100}),
+				newAssistantMessage("p", "s1", "m3", event.Usage{InputTokens: 400, OutputTokens: 100}),
+			},
+			wantAlerts: 1,
+		},
+		{
+			name:  "cost limit",
+			limit: Limit{Cost: 1},
+			messages: []*event.AssistantMessage{
+				// 100k output tokens on sonnet cost $1.50
+				newAssistantMessage("p", "s1", "m1", event.Usage{OutputTokens: 100_000}),
+			},
+			wantAlerts: 1,
+		},
+		{
+			name:  "duplicate messages are counted once",
+			limit: Limit{Tokens: 1000},
+			messages: []*event.AssistantMessage{
+				newAssistantMessage("p", "s1", "m1", event.Usage{InputTokens: 600}),
+				newAssistantMessage("p", "s1", "m1", event.Usage{InputTokens: 600}),
+			},
+			wantAlerts: 0,
+		},
+		{
+			name:  "sessions are tracked separately",
+			limit: Limit{Tokens: 1000},
+			messages: []*event.AssistantMessage{
+				newAssistantMessage("p", "s1", "m1", event.Usage{InputTokens: 600}),
+				newAssistantMessage("p", "s2", "m2", event.Usage{InputTokens: 600}),
+			},
+			wantAlerts: 0,
+		},
+		{
+			name: "disabled",
+			messages: []*event.AssistantMessage{
+				newAssistantMessage("p", "s1", "m1", event.Usage{InputTokens: 1_000_000}),
+			},
+			wantAlerts: 0,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var alerts []Alert
+			g := NewGuardrail(tt.limit, func(a Alert) { alerts = append(alerts, a) })
+			for _, msg := range tt.messages {
+				g.HandleEvent(msg, "")
+			}
+			if len(alerts) != tt.wantAlerts {
+				t.Fatalf("got %d alerts, want %d: %+v", len(alerts), tt.wantAlerts, alerts)
+			}
+		})
+	}
+}
+
+func TestGuardrail_LoadsExistingUsage(t *testing.T) {
+	root := t.TempDir()
+	dir := filepath.Join(root, "myproject")
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	line := `{"type":"assistant","uuid":"a1","requestId":"req-m1","timestamp":"2025-01-26T10:00:01Z","message":{"id":"m1","model":"claude-sonnet-4-20250514","content":[],"usage":{"input_tokens":900,"output_tokens":0}}}` + "\n"
+	if err := os.WriteFile(filepath.Join(dir, "session1.jsonl"), []byte(line), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	g := NewGuardrail(Limit{Tokens: 1000}, nil)
+	g.SetProjectsRoots([]string{t.TempDir(), root})
+
+	// The message already in the transcript must not be counted twice
+	if _, ok := g.Observe(newAssistantMessage("myproject", "session1", "m1", event.Usage{InputTokens: 900})); ok {
+		t.Fatal("unexpected alert for a message already in the transcript")
+	}
+	alert, ok := g.Observe(newAssistantMessage("myproject", "session1", "m2", event.Usage{InputTokens: 200}))
+	if !ok {
+		t.Fatal("expected an alert")
+	}
+	if alert.Tokens != 1100 || alert.Project != "myproject" || alert.Session != "session1" {
+		t.Errorf("unexpected alert: %+v", alert)
+	}
+}
+
+func TestGuardrail_Add(t *testing.T) {
+	root := t.TempDir()
+	dir := filepath.Join(root, "myproject")
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	line := `{"type":"assistant","uuid":"a1","requestId":"req-m1","timestamp":"2025-01-26T10:00:01Z","message":{"id":"m1","model":"claude-sonnet-4-20250514","content":[],"usage":{"input_tokens":900,"output_tokens":0}}}` + "\n"
+	if err := os.WriteFile(filepath.Join(dir, "session1.jsonl"), []byte(line), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	g := NewGuardrail(Limit{Tokens: 1000}, nil)
+	g.SetProjectsRoots([]string{root})
+
+	// Usage from before startup is included, and messages are counted once
+	if tokens, _ := g.Add(newAssistantMessage("myproject", "session1", "m1", event.Usage{InputTokens: 900})); tokens != 900 {
+		t.Errorf("Add() tokens = %d, want 900", tokens)
+	}
+	m2 := newAssistantMessage("myproject", "session1", "m2", event.Usage{InputTokens: 200})
+	if tokens, cost := g.Add(m2); tokens != 1100 || cost <= 0 {
+		t.Errorf("Add() = %d, %g, want 1100 tokens and a cost", tokens, cost)
+	}
+	// Observe shares the usage, and the session is still tracked after its alert
+	if _, ok := g.Observe(m2); !ok {
+		t.Error("Observe() did not alert for a session Add counted over the limit")
+	}
+	if tokens, _ := g.Add(newAssistantMessage("myproject", "session1", "m3", event.Usage{InputTokens: 100})); tokens != 1200 {
+		t.Errorf("Add() after the alert = %d, want 1200", tokens)
+	}
+
+	subagent := newAssistantMessage("myproject", "session1", "m4", event.Usage{InputTokens: 100})
+	subagent.IsSidechain = true
+	if tokens, _ := g.Add(subagent); tokens != 1200 {
+		t.Errorf("Add() of a subagent message = %d, want 1200", tokens)
+	}
+}
@@ -0,0 +1,81 @@
+package usage
+
+import (
+	"strings"
+)
+
+// Pricing represents the price of a model in USD per million tokens
+type Pricing struct {
+	Input         float64
+	Output        float64
+	CacheWrite    float64
+	CacheRead     float64
+	ContextWindow int
+}
+
+// modelPricing maps model families to their pricing; the first matching entry wins
+var modelPricing = []struct {
+	match   []string
+	pricing Pricing
+}{
+	{match: []string{"opus-4"}, pricing: Pricing{Input: 15, Output: 75, CacheWrite: 18.75, CacheRead: 1.5, ContextWindow: 200000}},
+	{match: []string{"sonnet-4"}, pricing: Pricing{Input: 3, Output: 15, CacheWrite: 3.75, CacheRead: 0.3, ContextWindow: 200000}},
+	{match: []string{"3-7-sonnet", "3-5-sonnet"}, pricing: Pricing{Input: 3, Output: 15, CacheWrite: 3.75, CacheRead: 0.3, ContextWindow: 200000}},
+	{match: []string{"3-5-haiku", "haiku-3-5"}, pricing: Pricing{Input: 0.8, Output: 4, CacheWrite: 1, CacheRead: 0.08, ContextWindow: 200000}},
+	{match: []string{"3-haiku"}, pricing: Pricing{Input: 0.25, Output: 1.25, CacheWrite: 0.3, CacheRead: 0.03, ContextWindow: 200000}},
+	{match: []string{"3-opus"}, pricing: Pricing{Input: 15, Output: 75, CacheWrite: 18.75, CacheRead: 1.5, ContextWindow: 200000}},
+	// Generic families for unknown versions
+	{match: []string{"opus"}, pricing: Pricing{Input: 15, Output: 75, CacheWrite: 18.75, CacheRead: 1.5, ContextWindow: 200000}},
+	{match: []string{"sonnet"}, pricing: Pricing{Input: 3, Output: 15, CacheWrite: 3.75, CacheRead: 0.3, ContextWindow: 200000}},
+	{match: []string{"haiku"}, pricing: Pricing{Input: 0.8, Output: 4, CacheWrite: 1, CacheRead: 0.08, ContextWindow: 200000}},
+}
+
+// modelNameReplacer turns display names such as "Opus 4.1" or "Claude 3.5 Haiku"
+// into the form of model IDs
+var modelNameReplacer = strings.NewReplacer(" ", "-", ".", "-")
+
+// PricingForModel returns the pricing for a model ID or display name
+func PricingForModel(model string) (Pricing, bool) {
+	normalized := modelNameReplacer.Replace(strings.ToLower(model))
+	for _, entry := range modelPricing {
+		for _, m := range entry.match {
+			if strings.Contains(normalized, m) {
+				return entry.pricing, true
+			}
+		}
+	}
+	return Pricing{}, false
+}
+
+// ContextWindow returns the context window of a model ID or display name in tokens,
+// or 0 if the model is unknown. Models run with the 1M token context, such as
+// "claude-sonnet-4-20250514[1m]" or "Sonnet 4 (1M context)", get 1M.
+func ContextWindow(model string) int {
+	lower := strings.ToLower(model)
+	if strings.Contains(lower, "[1m]") || strings.Contains(lower, "1m context") {
+		return 1_000_000
+	}
+	pricing, ok := PricingForModel(model)
+	if !ok {
+		return 0
+	}
+	return pricing.ContextWindow
+}
+
+// Cost returns the estimated cost in USD of the given token counts
+func (p Pricing) Cost(t Tokens) float64 {
+	const perMillion = 1_000_000.0
+	return float64(t.InputTokens)*p.Input/perMillion +
+		float64(t.OutputTokens)*p.Output/perMillion +
+		float64(t.CacheCreationInputTokens)*p.CacheWrite/perMillion +
+		float64(t.CacheReadInputTokens)*p.CacheRead/perMillion
+}
+
+// EstimateCost returns the estimated cost in USD for a model, or 0 if the model is unknown
+func EstimateCost(model string, t Tokens) float64 {
+	pricing, ok := PricingForModel(model)
+	if !ok {
+		return 0
+	}
+	return pricing.Cost(t)
+}
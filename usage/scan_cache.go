@@ -0,0 +1,118 @@
+package usage
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+)
+
+// maxScanCacheEntries bounds the transcripts kept in a scan cache
+const maxScanCacheEntries = 100
+
+// scanCacheEntry is the usage of a transcript as of the offset it was read up to
+type scanCacheEntry struct {
+	Offset int64         `json:"offset"`
+	Usage  *SessionUsage `json:"usage"`
+	Seen   []string      `json:"seen"` // Message keys already counted
+	Used   time.Time     `json:"used"`
+}
+
+// ScanCache keeps the usage of transcripts with the offset they were read up to,
+// so scanning a transcript again only reads the lines appended since
+type ScanCache struct {
+	path    string
+	entries map[string]*scanCacheEntry // key: transcript path
+	now     func() time.Time
+}
+
+// LoadScanCache loads a scan cache file. A missing or unreadable cache is empty.
+func LoadScanCache(path string) *ScanCache {
+	c := &ScanCache{
+		path:    path,
+		entries: make(map[string]*scanCacheEntry),
+		now:     time.Now,
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return c
+	}
+	if err := json.Unmarshal(data, &c.entries); err != nil {
+		c.entries = make(map[string]*scanCacheEntry)
+	}
+	return c
+}
+
+// Scan returns the usage of a transcript, reading only the lines appended since
+// the last scan. A transcript that shrank is read again from the start.
+func (c *ScanCache) Scan(path string) (*SessionUsage, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to stat file: %w", err)
+	}
+
+	entry, ok := c.entries[path]
+	if ok && entry.Usage != nil && entry.Offset <= info.Size() {
+		entry.Usage.Path = path
+		entry.Usage.restore(entry.Seen)
+	} else {
+		entry = &scanCacheEntry{Usage: newFileUsage(path)}
+	}
+	entry.Offset, err = entry.Usage.ScanFrom(entry.Offset)
+	if err != nil {
+		return nil, err
+	}
+	c.put(path, entry)
+	return entry.Usage, nil
+}
+
+// put stores an entry, evicting the least recently used ones over the limit
+func (c *ScanCache) put(path string, entry *scanCacheEntry) {
+	entry.Used = c.now()
+	entry.Seen = entry.Usage.seenKeys()
+	c.entries[path] = entry
+	if len(c.entries) <= maxScanCacheEntries {
+		return
+	}
+	paths := make([]string, 0, len(c.entries))
+	for p := range c.entries {
+		paths = append(paths, p)
+	}
+	sort.Slice(paths, func(i, j int) bool {
+		return c.entries[paths[i]].Used.Before(c.entries[paths[j]].Used)
+	})
+	for _, p := range paths[:len(paths)-maxScanCacheEntries] {
+		delete(c.entries, p)
+	}
+}
+
+// Save writes the cache to its file
+func (c *ScanCache) Save() error {
+	data, err := json.Marshal(c.entries)
+	if err != nil {
+		return fmt.Errorf("failed to encode scan cache: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(c.path), 0o755); err != nil {
+		return fmt.Errorf("failed to create scan cache directory: %w", err)
+	}
+	// Status lines of several sessions run at once, so each writes its own
+	// temporary file and renames it; the last one wins
+	tmp, err := os.CreateTemp(filepath.Dir(c.path), filepath.Base(c.path)+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("failed to write scan cache: %w", err)
+	}
+	_, err = tmp.Write(data)
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Rename(tmp.Name(), c.path)
+	}
+	if err != nil {
+		os.Remove(tmp.Name())
+		return fmt.Errorf("failed to write scan cache: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,82 @@
+package usage
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestScanCache(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "myproject", "session1.jsonl")
+	cachePath := filepath.Join(dir, "cache", "scan.json")
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	line1 := `{"type":"assistant","uuid":"a1","requestId":"req1","timestamp":"2025-01-26T10:00:01Z","message":{"id":"msg1","model":"claude-sonnet-4-20250514","content":[{"type":"text","text":"hello"}],"usage":{"input_tokens":100,"output_tokens":10,"cache_read_input_tokens":1000,"cache_creation_input_tokens":50}}}` + "\n"
+	line2 := `{"type":"assistant","uuid":"a2","requestId":"req1","timestamp":"2025-01-26T10:00:02Z","message":{"id":"msg1","model":"claude-sonnet-4-20250514","content":[{"type":"tool_use","id":"t1","name":"Bash"}],"usage":{"input_tokens":100,"output_tokens":10,"cache_read_input_tokens":1000,"cache_creation_input_tokens":50}}}` + "\n"
+	line3 := `{"type":"assistant","uuid":"a3","requestId":"req2","timestamp":"2025-01-26T10:00:05Z","message":{"id":"msg2","model":"claude-sonnet-4-20250514","content":[{"type":"text","text":"done"}],"usage":{"input_tokens":5,"output_tokens":20,"cache_read_input_tokens":2000,"cache_creation_input_tokens":0}}}` + "\n"
+
+	// The second line is still being written
+	if err := os.WriteFile(path, []byte(line1+line2[:40]), 0644); err != nil {
+		t.Fatal(err)
+	}
+	cache := LoadScanCache(cachePath)
+	session, err := cache.Scan(path)
+	if err != nil {
+		t.Fatalf("Scan() error = %v", err)
+	}
+	if session.Messages != 1 {
+		t.Errorf("Messages = %d, want 1", session.Messages)
+	}
+	if got := cache.entries[path].Offset; got != int64(len(line1)) {
+		t.Errorf("Offset = %d, want %d (end of the last complete line)", got, len(line1))
+	}
+	if err := cache.Save(); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+
+	// A new run only reads the appended lines; the duplicate of msg1 is not counted again
+	if err := os.WriteFile(path, []byte(line1+line2+line3), 0644); err != nil {
+		t.Fatal(err)
+	}
+	cache = LoadScanCache(cachePath)
+	session, err = cache.Scan(path)
+	if err != nil {
+		t.Fatalf("Scan() error = %v", err)
+	}
+	if session.Messages != 2 {
+		t.Errorf("Messages = %d, want 2", session.Messages)
+	}
+	if got := session.ContextTokens(); got != 2005 {
+		t.Errorf("ContextTokens() = %d, want 2005", got)
+	}
+	want := Tokens{InputTokens: 105, OutputTokens: 30, CacheReadInputTokens: 3000, CacheCreationInputTokens: 50}
+	if got := session.Models.Tokens(); got != want {
+		t.Errorf("Tokens = %+v, want %+v", got, want)
+	}
+
+	// A transcript that shrank is read again from the start
+	if err := os.WriteFile(path, []byte(line3), 0644); err != nil {
+		t.Fatal(err)
+	}
+	session, err = cache.Scan(path)
+	if err != nil {
+		t.Fatalf("Scan() error = %v", err)
+	}
+	if session.Messages != 1 || session.ContextTokens() != 2005 {
+		t.Errorf("after truncation: Messages = %d, ContextTokens() = %d, want 1 and 2005", session.Messages, session.ContextTokens())
+	}
+}
+
+func TestLoadScanCache_Corrupt(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "scan.json")
+	if err := os.WriteFile(path, []byte("{not json"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	cache := LoadScanCache(path)
+	if len(cache.entries) != 0 {
+		t.Errorf("entries = %d, want 0 for a corrupt cache", len(cache.entries))
+	}
+}
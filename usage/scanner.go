@@ -0,0 +1,112 @@
+package usage
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/kazegusuri/claude-companion/event"
+)
+
+// maxLineSize is the maximum size of a transcript line
+const maxLineSize = 10 * 1024 * 1024
+
+// ScanFile reads a session transcript and returns its token usage
+func ScanFile(path string) (*SessionUsage, error) {
+	session := newFileUsage(path)
+	if _, err := session.ScanFrom(0); err != nil {
+		return nil, err
+	}
+	return session, nil
+}
+
+// newFileUsage creates an empty usage for a transcript, labeled by its project directory and file name
+func newFileUsage(path string) *SessionUsage {
+	session := NewSessionUsage(filepath.Base(filepath.Dir(path)), event.SessionName(path))
+	session.Path = path
+	return session
+}
+
+// ScanFrom adds the usage in the session's transcript from offset on and returns
+// the offset after the last complete line. A trailing line still being written is
+// counted but read again by the next scan; deduplication keeps it from counting twice.
+func (s *SessionUsage) ScanFrom(offset int64) (int64, error) {
+	file, err := os.Open(s.Path)
+	if err != nil {
+		return offset, fmt.Errorf("failed to open file: %w", err)
+	}
+	defer file.Close()
+	if _, err := file.Seek(offset, io.SeekStart); err != nil {
+		return offset, fmt.Errorf("failed to seek file: %w", err)
+	}
+
+	parser := event.NewParserWithPath(s.Path)
+	reader := bufio.NewReaderSize(file, 64*1024)
+	for {
+		line, err := reader.ReadString('\n')
+		if len(line) > maxLineSize {
+			return offset, fmt.Errorf("error reading file: line too long")
+		}
+		complete := strings.HasSuffix(line, "\n")
+		// Only assistant messages carry usage; skip other lines without a full parse
+		if strings.Contains(line, `"usage"`) {
+			if ev, err := parser.Parse(strings.TrimRight(line, "\r\n")); err == nil {
+				if msg, ok := ev.(*event.AssistantMessage); ok {
+					s.AddMessage(msg)
+				}
+			}
+		}
+		if complete {
+			offset += int64(len(line))
+		}
+		if err == io.EOF {
+			return offset, nil
+		}
+		if err != nil {
+			return offset, fmt.Errorf("error reading file: %w", err)
+		}
+	}
+}
+
+// ScanRoot scans all session transcripts under the projects root
+func ScanRoot(root string, filter func(project, session string) bool) ([]*SessionUsage, error) {
+	root, err := ExpandHome(root)
+	if err != nil {
+		return nil, err
+	}
+
+	files, err := filepath.Glob(filepath.Join(root, "*", "*.jsonl"))
+	if err != nil {
+		return nil, err
+	}
+
+	var sessions []*SessionUsage
+	for _, path := range files {
+		project := filepath.Base(filepath.Dir(path))
+		session := strings.TrimSuffix(filepath.Base(path), ".jsonl")
+		if filter != nil && !filter(project, session) {
+			continue
+		}
+		usage, err := ScanFile(path)
+		if err != nil {
+			return nil, err
+		}
+		sessions = append(sessions, usage)
+	}
+	return sessions, nil
+}
+
+// ExpandHome expands a leading ~/ to the user's home directory
+func ExpandHome(path string) (string, error) {
+	if strings.HasPrefix(path, "~/") {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return "", err
+		}
+		return filepath.Join(home, path[2:]), nil
+	}
+	return path, nil
+}
@@ -0,0 +1,239 @@
+package usage
+
+import (
+	"sort"
+	"time"
+
+	"github.com/kazegusuri/claude-companion/event"
+)
+
+// Tokens holds token counts
+type Tokens struct {
+	InputTokens              int64 `json:"inputTokens"`
+	OutputTokens             int64 `json:"outputTokens"`
+	CacheReadInputTokens     int64 `json:"cacheReadInputTokens"`
+	CacheCreationInputTokens int64 `json:"cacheCreationInputTokens"`
+}
+
+// Add adds token usage of an assistant message
+func (t *Tokens) Add(u event.Usage) {
+	t.InputTokens += int64(u.InputTokens)
+	t.OutputTokens += int64(u.OutputTokens)
+	t.CacheReadInputTokens += int64(u.CacheReadInputTokens)
+	t.CacheCreationInputTokens += int64(u.CacheCreationInputTokens)
+}
+
+// Merge adds another token count
+func (t *Tokens) Merge(o Tokens) {
+	t.InputTokens += o.InputTokens
+	t.OutputTokens += o.OutputTokens
+	t.CacheReadInputTokens += o.CacheReadInputTokens
+	t.CacheCreationInputTokens += o.CacheCreationInputTokens
+}
+
+// Total returns the sum of all token counts
+func (t Tokens) Total() int64 {
+	return t.InputTokens + t.OutputTokens + t.CacheReadInputTokens + t.CacheCreationInputTokens
+}
+
+// ModelUsage holds token usage per model
+type ModelUsage map[string]*Tokens
+
+// Add adds token usage for a model
+func (m ModelUsage) Add(model string, u event.Usage) {
+	t, ok := m[model]
+	if !ok {
+		t = &Tokens{}
+		m[model] = t
+	}
+	t.Add(u)
+}
+
+// Merge adds another model usage
+func (m ModelUsage) Merge(o ModelUsage) {
+	for model, tokens := range o {
+		t, ok := m[model]
+		if !ok {
+			t = &Tokens{}
+			m[model] = t
+		}
+		t.Merge(*tokens)
+	}
+}
+
+// Tokens returns the token counts across all models
+func (m ModelUsage) Tokens() Tokens {
+	var total Tokens
+	for _, t := range m {
+		total.Merge(*t)
+	}
+	return total
+}
+
+// Cost returns the estimated cost across all models
+func (m ModelUsage) Cost() float64 {
+	cost := 0.0
+	for model, t := range m {
+		cost += EstimateCost(model, *t)
+	}
+	return cost
+}
+
+// Models returns the model names sorted
+func (m ModelUsage) Models() []string {
+	models := make([]string, 0, len(m))
+	for model := range m {
+		models = append(models, model)
+	}
+	sort.Strings(models)
+	return models
+}
+
+// maxRecentUsage bounds the context sizes kept for the burn rate
+const maxRecentUsage = 50
+
+// UsagePoint is the size of the context after an assistant message
+type UsagePoint struct {
+	Time          time.Time `json:"time"`
+	ContextTokens int       `json:"contextTokens"`
+}
+
+// SessionUsage holds token usage of a single session transcript
+type SessionUsage struct {
+	Project   string
+	Session   string
+	Path      string
+	Models    ModelUsage
+	Daily     map[string]ModelUsage // key: date in YYYY-MM-DD (local time)
+	Messages  int
+	FirstSeen time.Time
+	LastSeen  time.Time
+	// LastUsage is the usage reported by the latest assistant message
+	LastUsage event.Usage
+	LastModel string
+	// Recent is the context size after each of the latest assistant messages, oldest first
+	Recent []UsagePoint
+
+	seen map[string]struct{}
+}
+
+// NewSessionUsage creates an empty session usage
+func NewSessionUsage(project, session string) *SessionUsage {
+	return &SessionUsage{
+		Project: project,
+		Session: session,
+		Models:  make(ModelUsage),
+		Daily:   make(map[string]ModelUsage),
+		seen:    make(map[string]struct{}),
+	}
+}
+
+// AddMessage adds the usage of an assistant message.
+// Claude Code writes one line per content block with the same message, so
+// messages are deduplicated by message ID and request ID.
+func (s *SessionUsage) AddMessage(msg *event.AssistantMessage) bool {
+	if msg.Message.Usage == (event.Usage{}) {
+		return false
+	}
+	if msg.Message.ID != "" {
+		key := msg.Message.ID + ":" + msg.RequestID
+		if _, ok := s.seen[key]; ok {
+			return false
+		}
+		s.seen[key] = struct{}{}
+	}
+
+	model := msg.Message.Model
+	if model == "" {
+		model = "unknown"
+	}
+	s.Models.Add(model, msg.Message.Usage)
+
+	if !msg.Timestamp.IsZero() {
+		day := msg.Timestamp.Local().Format("2006-01-02")
+		daily, ok := s.Daily[day]
+		if !ok {
+			daily = make(ModelUsage)
+			s.Daily[day] = daily
+		}
+		daily.Add(model, msg.Message.Usage)
+
+		if s.FirstSeen.IsZero() || msg.Timestamp.Before(s.FirstSeen) {
+			s.FirstSeen = msg.Timestamp
+		}
+		if msg.Timestamp.After(s.LastSeen) {
+			s.LastSeen = msg.Timestamp
+		}
+	}
+
+	s.Messages++
+	s.LastUsage = msg.Message.Usage
+	s.LastModel = model
+	if !msg.Timestamp.IsZero() {
+		s.Recent = append(s.Recent, UsagePoint{Time: msg.Timestamp, ContextTokens: s.ContextTokens()})
+		if len(s.Recent) > maxRecentUsage {
+			s.Recent = s.Recent[len(s.Recent)-maxRecentUsage:]
+		}
+	}
+	return true
+}
+
+// seenKeys returns the keys of the messages already counted
+func (s *SessionUsage) seenKeys() []string {
+	keys := make([]string, 0, len(s.seen))
+	for key := range s.seen {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// restore rebuilds the state a decoded usage lacks: the counted message keys and nil maps
+func (s *SessionUsage) restore(seen []string) {
+	s.seen = make(map[string]struct{}, len(seen))
+	for _, key := range seen {
+		s.seen[key] = struct{}{}
+	}
+	if s.Models == nil {
+		s.Models = make(ModelUsage)
+	}
+	if s.Daily == nil {
+		s.Daily = make(map[string]ModelUsage)
+	}
+}
+
+// Cost returns the estimated cost of the session
+func (s *SessionUsage) Cost() float64 {
+	return s.Models.Cost()
+}
+
+// ContextTokens returns the number of tokens in the context as of the latest assistant message
+func (s *SessionUsage) ContextTokens() int {
+	return s.LastUsage.InputTokens + s.LastUsage.CacheReadInputTokens + s.LastUsage.CacheCreationInputTokens
+}
+
+// BurnRate returns how fast the context grew over the last n assistant messages in
+// tokens per minute, or 0 when it cannot tell. Growth before the context last shrank,
+// such as by a compaction, is not counted.
+func (s *SessionUsage) BurnRate(n int) float64 {
+	points := s.Recent
+	if n > 0 && len(points) > n {
+		points = points[len(points)-n:]
+	}
+	start := 0
+	for i := 1; i < len(points); i++ {
+		if points[i].ContextTokens < points[i-1].ContextTokens {
+			start = i
+		}
+	}
+	points = points[start:]
+	if len(points) < 2 {
+		return 0
+	}
+	first, last := points[0], points[len(points)-1]
+	minutes := last.Time.Sub(first.Time).Minutes()
+	if minutes <= 0 || last.ContextTokens <= first.ContextTokens {
+		return 0
+	}
+	return float64(last.ContextTokens-first.ContextTokens) / minutes
+}
@@ -0,0 +1,154 @@
+package usage
+
+import (
+	"math"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestPricingForModel(t *testing.T) {
+	tests := []struct {
+		model     string
+		wantInput float64
+		wantFound bool
+	}{
+		{model: "claude-opus-4-1-20250805", wantInput: 15, wantFound: true},
+		{model: "claude-sonnet-4-20250514", wantInput: 3, wantFound: true},
+		{model: "claude-3-5-haiku-20241022", wantInput: 0.8, wantFound: true},
+		{model: "claude-3-haiku-20240307", wantInput: 0.25, wantFound: true},
+		{model: "Opus 4.1", wantInput: 15, wantFound: true},
+		{model: "Claude 3.5 Haiku", wantInput: 0.8, wantFound: true},
+		{model: "<synthetic>", wantFound: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.model, func(t *testing.T) {
+			pricing, found := PricingForModel(tt.model)
+			if found != tt.wantFound {
+				t.Fatalf("PricingForModel(%q) found = %v, want %v", tt.model, found, tt.wantFound)
+			}
+			if pricing.Input != tt.wantInput {
+				t.Errorf("PricingForModel(%q).Input = %v, want %v", tt.model, pricing.Input, tt.wantInput)
+			}
+		})
+	}
+}
+
+func TestContextWindow(t *testing.T) {
+	tests := []struct {
+		model string
+		want  int
+	}{
+		{model: "claude-opus-4-1-20250805", want: 200000},
+		{model: "Sonnet 4", want: 200000},
+		{model: "claude-sonnet-4-20250514[1m]", want: 1_000_000},
+		{model: "Sonnet 4 (1M context)", want: 1_000_000},
+		{model: "claude-3-5-haiku-20241022", want: 200000},
+		{model: "<synthetic>", want: 0},
+	}
+
+	for _, tt := range tests {
+		if got := ContextWindow(tt.model); got != tt.want {
+			t.Errorf("ContextWindow(%q) = %d, want %d", tt.model, got, tt.want)
+		}
+	}
+}
+
+func TestEstimateCost(t *testing.T) {
+	tokens := Tokens{
+		InputTokens:              1_000_000,
+		OutputTokens:             1_000_000,
+		CacheReadInputTokens:     1_000_000,
+		CacheCreationInputTokens: 1_000_000,
+	}
+	got := EstimateCost("claude-sonnet-4-20250514", tokens)
+	want := 3 + 15 + 0.3 + 3.75
+	if math.Abs(got-want) > 1e-9 {
+		t.Errorf("EstimateCost() = %v, want %v", got, want)
+	}
+}
+
+func TestScanFile(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "myproject")
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(dir, "session1.jsonl")
+
+	lines := `{"type":"user","uuid":"u1","timestamp":"2025-01-26T10:00:00Z","message":{"role":"user","content":"hi"}}
+{"type":"assistant","uuid":"a1","requestId":"req1","timestamp":"2025-01-26T10:00:01Z","message":{"id":"msg1","model":"claude-sonnet-4-20250514","content":[{"type":"text","text":"hello"}],"usage":{"input_tokens":100,"output_tokens":10,"cache_read_input_tokens":1000,"cache_creation_input_tokens":50}}}
+{"type":"assistant","uuid":"a2","requestId":"req1","timestamp":"2025-01-26T10:00:02Z","message":{"id":"msg1","model":"claude-sonnet-4-20250514","content":[{"type":"tool_use","id":"t1","name":"Bash"}],"usage":{"input_tokens":100,"output_tokens":10,"cache_read_input_tokens":1000,"cache_creation_input_tokens":50}}}
+{"type":"assistant","uuid":"a3","requestId":"req2","timestamp":"2025-01-26T10:00:05Z","message":{"id":"msg2","model":"claude-sonnet-4-20250514","content":[{"type":"text","text":"done"}],"usage":{"input_tokens":5,"output_tokens":20,"cache_read_input_tokens":2000,"cache_creation_input_tokens":0}}}
+not json
+`
+	if err := os.WriteFile(path, []byte(lines), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	session, err := ScanFile(path)
+	if err != nil {
+		t.Fatalf("ScanFile() error = %v", err)
+	}
+
+	if session.Project != "myproject" || session.Session != "session1" {
+		t.Errorf("unexpected session labels: %s/%s", session.Project, session.Session)
+	}
+	if session.Messages != 2 {
+		t.Errorf("Messages = %d, want 2 (duplicate message should be ignored)", session.Messages)
+	}
+
+	tokens := session.Models.Tokens()
+	want := Tokens{InputTokens: 105, OutputTokens: 30, CacheReadInputTokens: 3000, CacheCreationInputTokens: 50}
+	if tokens != want {
+		t.Errorf("Tokens = %+v, want %+v", tokens, want)
+	}
+	if got := session.ContextTokens(); got != 2005 {
+		t.Errorf("ContextTokens() = %d, want 2005", got)
+	}
+	if len(session.Daily) != 1 {
+		t.Errorf("Daily has %d entries, want 1", len(session.Daily))
+	}
+
+	sessions, err := ScanRoot(filepath.Dir(dir), func(project, session string) bool { return project == "myproject" })
+	if err != nil {
+		t.Fatalf("ScanRoot() error = %v", err)
+	}
+	if len(sessions) != 1 {
+		t.Errorf("ScanRoot() returned %d sessions, want 1", len(sessions))
+	}
+}
+
+func TestSessionUsage_BurnRate(t *testing.T) {
+	base := time.Date(2025, 1, 26, 10, 0, 0, 0, time.UTC)
+	points := func(tokens ...int) []UsagePoint {
+		var p []UsagePoint
+		for i, n := range tokens {
+			p = append(p, UsagePoint{Time: base.Add(time.Duration(i) * time.Minute), ContextTokens: n})
+		}
+		return p
+	}
+
+	tests := []struct {
+		name   string
+		recent []UsagePoint
+		n      int
+		want   float64
+	}{
+		{name: "no messages", want: 0},
+		{name: "single message", recent: points(1000), want: 0},
+		{name: "steady growth", recent: points(1000, 2000, 3000), want: 1000},
+		{name: "last n only", recent: points(1000, 5000, 6000, 7000), n: 3, want: 1000},
+		{name: "after compaction", recent: points(1000, 90000, 20000, 22000), want: 2000},
+		{name: "shrinking", recent: points(3000, 2000), want: 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := &SessionUsage{Recent: tt.recent}
+			if got := s.BurnRate(tt.n); math.Abs(got-tt.want) > 1e-9 {
+				t.Errorf("BurnRate(%d) = %v, want %v", tt.n, got, tt.want)
+			}
+		})
+	}
+}